@@ -7,6 +7,7 @@ import (
 	"os/exec"
 	"os/signal"
 	"runtime"
+	"strings"
 	"syscall"
 	"time"
 
@@ -16,16 +17,19 @@ import (
 	"gym-door-bridge/internal/config"
 	"gym-door-bridge/internal/logging"
 	"gym-door-bridge/internal/pairing"
-	"gym-door-bridge/internal/service/windows"
+	"gym-door-bridge/internal/service/linux"
 	"gym-door-bridge/internal/service/macos"
 
+	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
-	"golang.org/x/sys/windows/svc"
 )
 
 var (
 	configFile string
 	logLevel   string
+	
+	// Set at build time with -ldflags "-X main.version=..."
+	version = "dev"
 )
 
 var rootCmd = &cobra.Command{
@@ -38,7 +42,7 @@ format and securely forwards them to our cloud system.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		// Check if running on Windows and as a service
 		if runtime.GOOS == "windows" {
-			isService, err := svc.IsWindowsService()
+			isService, err := isWindowsService()
 			if err != nil {
 				fmt.Fprintf(os.Stderr, "Failed to determine if running as service: %v\n", err)
 				os.Exit(1)
@@ -62,9 +66,11 @@ func init() {
 	
 	// Add platform-specific service commands
 	if runtime.GOOS == "windows" {
-		windows.AddServiceCommands(rootCmd)
+		addWindowsServiceCommands(rootCmd)
 	} else if runtime.GOOS == "darwin" {
 		macos.AddServiceCommands(rootCmd)
+	} else if runtime.GOOS == "linux" {
+		linux.AddServiceCommands(rootCmd)
 	}
 	
 	// Add cross-platform installer commands
@@ -82,67 +88,6 @@ func main() {
 	}
 }
 
-// runAsWindowsService runs the application as a Windows service
-func runAsWindowsService() {
-	// Load service configuration from registry
-	serviceConfig, err := windows.LoadServiceConfig()
-	if err != nil {
-		// Log to Windows event log if possible
-		fmt.Fprintf(os.Stderr, "Failed to load service configuration: %v\n", err)
-		os.Exit(1)
-	}
-	
-	// Override config file if specified in service config
-	if serviceConfig.ConfigPath != "" {
-		configFile = serviceConfig.ConfigPath
-	}
-	
-	// Override log level if specified in service config
-	if serviceConfig.LogLevel != "" {
-		logLevel = serviceConfig.LogLevel
-	}
-	
-	// Change working directory to service working directory
-	if serviceConfig.WorkingDir != "" {
-		if err := os.Chdir(serviceConfig.WorkingDir); err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to change working directory: %v\n", err)
-			os.Exit(1)
-		}
-	}
-	
-	// Load application configuration
-	cfg, err := config.Load(configFile)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to load configuration: %v\n", err)
-		os.Exit(1)
-	}
-	
-	// For Windows service, try to populate device credentials from auth manager if missing from config
-	if cfg.DeviceID == "" || cfg.DeviceKey == "" {
-		if authManager, err := auth.NewAuthManager(); err == nil {
-			if err := authManager.Initialize(); err == nil {
-				if authManager.IsAuthenticated() {
-					if deviceID, deviceKey, err := authManager.GetCredentials(); err == nil {
-						if cfg.DeviceID == "" {
-							cfg.DeviceID = deviceID
-						}
-						if cfg.DeviceKey == "" {
-							cfg.DeviceKey = deviceKey
-						}
-					}
-				}
-			}
-		}
-	}
-	
-	// Run as Windows service
-	err = windows.RunService(cfg, bridgeMain, false)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Service execution failed: %v\n", err)
-		os.Exit(1)
-	}
-}
-
 // runAsConsole runs the application as a console application
 func runAsConsole() {
 	// Initialize logging
@@ -154,30 +99,18 @@ func runAsConsole() {
 		logger.WithError(err).Fatal("Failed to load configuration")
 	}
 	
+	// Move a plain-text device key from the config file into the credential store
+	migrateStoredCredentials(cfg, logger)
+	
 	// Try to populate device credentials from auth manager if missing from config
-	if cfg.DeviceID == "" || cfg.DeviceKey == "" {
-		if authManager, err := auth.NewAuthManager(); err == nil {
-			if err := authManager.Initialize(); err == nil {
-				if authManager.IsAuthenticated() {
-					if deviceID, deviceKey, err := authManager.GetCredentials(); err == nil {
-						if cfg.DeviceID == "" {
-							cfg.DeviceID = deviceID
-						}
-						if cfg.DeviceKey == "" {
-							cfg.DeviceKey = deviceKey
-						}
-					}
-				}
-			}
-		}
-	}
+	loadStoredCredentials(cfg)
 	
-	logger.WithField("config", cfg).Info("Bridge starting up")
+	logger.WithField("config", cfg.Redacted()).Info("Bridge starting up")
 	
 	// Check platform-specific execution
 	if runtime.GOOS == "windows" {
 		// Run in debug mode on Windows (allows Ctrl+C handling)
-		err = windows.RunService(cfg, bridgeMain, true)
+		err = runWindowsDebugService(cfg)
 		if err != nil {
 			logger.WithError(err).Fatal("Failed to run bridge")
 		}
@@ -187,6 +120,12 @@ func runAsConsole() {
 		if err != nil {
 			logger.WithError(err).Fatal("Failed to run bridge as daemon")
 		}
+	} else if runtime.GOOS == "linux" && linux.IsSystemdService() {
+		// Running as systemd service
+		err = linux.RunService(cfg, newSystemdBridge)
+		if err != nil {
+			logger.WithError(err).Fatal("Failed to run bridge as service")
+		}
 	} else {
 		// Run directly on other platforms or when not running as daemon
 		ctx, cancel := context.WithCancel(context.Background())
@@ -223,20 +162,11 @@ func runAsConsole() {
 func bridgeMain(ctx context.Context, cfg *config.Config) error {
 	logger := logging.Initialize(logLevel)
 	
-	logger.WithField("config", cfg).Info("Bridge main function starting")
-	
-	// Create bridge manager with version and device ID
-	manager, err := bridge.NewManager(cfg,
-		bridge.WithVersion("1.0.0"), // TODO: Get from build info
-		bridge.WithDeviceID(cfg.DeviceID),
-	)
+	manager, err := newBridgeManager(cfg)
 	if err != nil {
-		logger.WithError(err).Error("Failed to create bridge manager")
-		return fmt.Errorf("failed to create bridge manager: %w", err)
+		return err
 	}
 	
-	logger.Info("Gym Door Access Bridge initialized successfully")
-	
 	// Start the bridge manager
 	if err := manager.Start(ctx); err != nil {
 		logger.WithError(err).Error("Bridge manager stopped with error")
@@ -247,6 +177,37 @@ func bridgeMain(ctx context.Context, cfg *config.Config) error {
 	return nil
 }
 
+// newBridgeManager creates the bridge manager with the version, device ID and
+// configuration file
+func newBridgeManager(cfg *config.Config) (*bridge.Manager, error) {
+	logger := logging.Initialize(logLevel)
+	
+	logger.WithField("config", cfg.Redacted()).Info("Bridge main function starting")
+	
+	manager, err := bridge.NewManager(cfg,
+		bridge.WithVersion(version),
+		bridge.WithDeviceID(cfg.DeviceID),
+		bridge.WithConfigFile(configFile),
+	)
+	if err != nil {
+		logger.WithError(err).Error("Failed to create bridge manager")
+		return nil, fmt.Errorf("failed to create bridge manager: %w", err)
+	}
+	
+	logger.Info("Gym Door Access Bridge initialized successfully")
+	return manager, nil
+}
+
+// newSystemdBridge creates the bridge run by the systemd service, which
+// reports its readiness and liveness and reloads its configuration on SIGHUP
+func newSystemdBridge(cfg *config.Config) (linux.Bridge, error) {
+	manager, err := newBridgeManager(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return manager, nil
+}
+
 // addCrossPlatformInstallerCommands adds cross-platform installer commands
 // TODO: Re-enable when installer compilation issues are fixed
 /*
@@ -447,9 +408,10 @@ The pair code is provided by your gym management platform.`,
 				os.Exit(1)
 			}
 			
-			// Update configuration with device credentials
+			// Update configuration with the device ID; the pairing manager keeps
+			// the key in the credential store rather than in the config file
 			cfg.DeviceID = pairResp.DeviceID
-			cfg.DeviceKey = pairResp.DeviceKey
+			cfg.DeviceKey = ""
 			
 			// Update installation metadata
 			cfg.SetInstallationMethod("paired", "user", pairCode, "manual", "")
@@ -569,6 +531,7 @@ This removes the secure connection and device credentials.`,
 				fmt.Fprintf(os.Stderr, "Failed to load configuration: %v\n", err)
 				os.Exit(1)
 			}
+			loadStoredCredentials(cfg)
 			
 			fmt.Println("🔗 Gym Door Bridge Status")
 			fmt.Println("========================")
@@ -637,6 +600,7 @@ This removes the secure connection and device credentials.`,
 				fmt.Fprintf(os.Stderr, "Failed to load configuration: %v\n", err)
 				os.Exit(1)
 			}
+			loadStoredCredentials(cfg)
 			
 			if !cfg.IsPaired() {
 				fmt.Println("❌ Bridge is not paired. Use 'gym-door-bridge pair' first.")
@@ -687,6 +651,7 @@ This removes the secure connection and device credentials.`,
 				fmt.Fprintf(os.Stderr, "Failed to load configuration: %v\n", err)
 				os.Exit(1)
 			}
+			loadStoredCredentials(cfg)
 			
 			if !cfg.IsPaired() {
 				fmt.Println("❌ Bridge is not paired. Use 'gym-door-bridge pair' first.")
@@ -785,9 +750,54 @@ func isServiceRunning() bool {
 	}
 	
 	// Check if service is running
-	return string(output) != "" && 
-		   (string(output) != "" && 
-		    (len(output) > 0))
+	return strings.Contains(string(output), "RUNNING")
+}
+
+// migrateStoredCredentials moves a plain-text device key from the config file
+// into the credential store
+func migrateStoredCredentials(cfg *config.Config, logger *logrus.Logger) {
+	if cfg.DeviceKey == "" {
+		return
+	}
+	
+	authManager, err := auth.NewAuthManager()
+	if err != nil {
+		return
+	}
+	if err := authManager.Initialize(); err != nil {
+		return
+	}
+	
+	if migrated, err := authManager.MigrateConfigCredentials(cfg); err != nil {
+		logger.WithError(err).Warn("Failed to move device key from config file into credential store")
+	} else if migrated {
+		logger.WithField("config", cfg.FileUsed()).Info("Moved device key from config file into credential store")
+	}
+}
+
+// loadStoredCredentials fills device credentials missing from the config file
+// from the credential store, where pairing keeps the device key
+func loadStoredCredentials(cfg *config.Config) {
+	if cfg.DeviceID != "" && cfg.DeviceKey != "" {
+		return
+	}
+	
+	authManager, err := auth.NewAuthManager()
+	if err != nil {
+		return
+	}
+	if err := authManager.Initialize(); err != nil || !authManager.IsAuthenticated() {
+		return
+	}
+	
+	if deviceID, deviceKey, err := authManager.GetCredentials(); err == nil {
+		if cfg.DeviceID == "" {
+			cfg.DeviceID = deviceID
+		}
+		if cfg.DeviceKey == "" {
+			cfg.DeviceKey = deviceKey
+		}
+	}
 }
 
 // testConnectivity tests connectivity to the platform
@@ -48,6 +48,7 @@ func runPairCommand(cmd *cobra.Command, args []string) error {
 	logger.WithField("server_url", cfg.ServerURL).Info("Starting device pairing")
 
 	// Check if device is already paired
+	loadStoredCredentials(cfg)
 	if cfg.IsPaired() {
 		return fmt.Errorf("device is already paired (device_id: %s). Use 'unpair' command to unpair first", cfg.DeviceID)
 	}
@@ -120,11 +121,12 @@ func updateConfigWithPairing(cfg *config.Config, pairResp *client.PairResponse,
 		// We know the device ID, now we need to get the key from secure storage
 		// Since the auth manager interface doesn't expose GetDeviceKey directly,
 		// we need to use the stored credentials from the credential manager
-		deviceID, deviceKey, err := getStoredCredentials(authManager)
+		deviceID, _, err := getStoredCredentials(authManager)
 		if err != nil {
 			return fmt.Errorf("failed to retrieve stored device key: %w", err)
 		}
-		cfg.DeviceKey = deviceKey
+		// The key stays in secure storage rather than in plain text in the config file
+		cfg.DeviceKey = ""
 
 		// Verify device ID matches
 		if deviceID != pairResp.DeviceID {
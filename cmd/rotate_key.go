@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"gym-door-bridge/internal/auth"
+	"gym-door-bridge/internal/config"
+	"gym-door-bridge/internal/database"
+	"gym-door-bridge/internal/logging"
+
+	"github.com/spf13/cobra"
+)
+
+var rotateKeyCmd = &cobra.Command{
+	Use:   "rotate-key",
+	Short: "Rotate the database encryption key",
+	Long: `Generate a new data-encryption key and re-encrypt every encrypted value in the
+database with it. The key is kept in the platform credential store.
+
+Stop the bridge service first. If the rotation is interrupted, run the command
+again or start the bridge; either one finishes re-encrypting the database.`,
+	RunE: runRotateKeyCommand,
+}
+
+func init() {
+	rootCmd.AddCommand(rotateKeyCmd)
+}
+
+func runRotateKeyCommand(cmd *cobra.Command, args []string) error {
+	// Initialize logging
+	logger := logging.Initialize(logLevel)
+
+	// Load configuration
+	cfg, err := config.Load(configFile)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	store, err := auth.NewSecretStore()
+	if err != nil {
+		return fmt.Errorf("failed to open credential store: %w", err)
+	}
+
+	db, err := database.OpenWithKeyStore(database.Config{
+		DatabasePath:    cfg.DatabasePath,
+		PerformanceTier: database.PerformanceTier(cfg.Tier),
+	}, store)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	// Stop between batches on Ctrl+C; the next run picks up where this one stopped
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	// Finish an interrupted rotation before starting a new one
+	pending, err := db.CompleteKeyRotation(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to complete previous key rotation: %w", err)
+	}
+	if pending.Reencrypted > 0 {
+		fmt.Printf("Finished previous rotation: %d values re-encrypted\n", pending.Reencrypted)
+	}
+
+	previousKeyID := db.ActiveKeyID()
+	fmt.Printf("Rotating database key in %s\n", cfg.DatabasePath)
+
+	result, err := db.RotateKey(ctx)
+	if err != nil {
+		return fmt.Errorf("key rotation failed: %w", err)
+	}
+
+	logger.WithField("key_id", db.ActiveKeyID()).Info("Database encryption key rotated")
+
+	fmt.Println("✓ Database key rotated successfully!")
+	fmt.Printf("Key: %d -> %d\n", previousKeyID, db.ActiveKeyID())
+	fmt.Printf("Values re-encrypted: %d\n", result.Reencrypted)
+	if result.Failed > 0 {
+		fmt.Printf("Warning: %d values could not be decrypted and were left unchanged\n", result.Failed)
+	}
+	fmt.Println()
+	fmt.Println("The previous key is removed the next time the bridge starts.")
+
+	return nil
+}
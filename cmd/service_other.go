@@ -0,0 +1,32 @@
+//go:build !windows
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+
+	"gym-door-bridge/internal/config"
+
+	"github.com/spf13/cobra"
+)
+
+// isWindowsService always reports false outside Windows
+func isWindowsService() (bool, error) {
+	return false, nil
+}
+
+// addWindowsServiceCommands is a no-op outside Windows
+func addWindowsServiceCommands(rootCmd *cobra.Command) {}
+
+// runAsWindowsService is never reached outside Windows
+func runAsWindowsService() {
+	fmt.Fprintf(os.Stderr, "Windows service mode is not supported on %s\n", runtime.GOOS)
+	os.Exit(1)
+}
+
+// runWindowsDebugService is never reached outside Windows
+func runWindowsDebugService(cfg *config.Config) error {
+	return fmt.Errorf("Windows service mode is not supported on %s", runtime.GOOS)
+}
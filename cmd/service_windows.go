@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"gym-door-bridge/internal/config"
+	"gym-door-bridge/internal/logging"
+	"gym-door-bridge/internal/service/windows"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/sys/windows/svc"
+)
+
+// isWindowsService reports whether the service control manager started the process
+func isWindowsService() (bool, error) {
+	return svc.IsWindowsService()
+}
+
+// addWindowsServiceCommands adds the Windows service management commands
+func addWindowsServiceCommands(rootCmd *cobra.Command) {
+	windows.AddServiceCommands(rootCmd)
+}
+
+// runAsWindowsService runs the application as a Windows service
+func runAsWindowsService() {
+	// Load service configuration from registry
+	serviceConfig, err := windows.LoadServiceConfig()
+	if err != nil {
+		// Log to Windows event log if possible
+		fmt.Fprintf(os.Stderr, "Failed to load service configuration: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Override config file if specified in service config
+	if serviceConfig.ConfigPath != "" {
+		configFile = serviceConfig.ConfigPath
+	}
+
+	// Override log level if specified in service config
+	if serviceConfig.LogLevel != "" {
+		logLevel = serviceConfig.LogLevel
+	}
+
+	// Change working directory to service working directory
+	if serviceConfig.WorkingDir != "" {
+		if err := os.Chdir(serviceConfig.WorkingDir); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to change working directory: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	// Load application configuration
+	cfg, err := config.Load(configFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load configuration: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Move a plain-text device key from the config file into the credential store
+	migrateStoredCredentials(cfg, logging.Initialize(logLevel))
+
+	// For Windows service, try to populate device credentials from auth manager if missing from config
+	loadStoredCredentials(cfg)
+
+	// Run as Windows service
+	err = windows.RunService(cfg, bridgeMain, false)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Service execution failed: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// runWindowsDebugService runs the bridge in service debug mode, which allows
+// Ctrl+C handling from a console
+func runWindowsDebugService(cfg *config.Config) error {
+	return windows.RunService(cfg, bridgeMain, true)
+}
@@ -0,0 +1,112 @@
+package access
+
+import (
+	"context"
+	"time"
+
+	"gym-door-bridge/internal/database"
+	"gym-door-bridge/internal/types"
+)
+
+// Reason codes attached to every access decision
+const (
+	ReasonGranted             = "granted"
+	ReasonUnknownMember       = "unknown_member"        // No external user mapping for the credential
+	ReasonNoEntitlement       = "no_entitlement"        // Mapped member has no cached entitlement
+	ReasonMembershipInactive  = "membership_inactive"   // Membership is suspended or cancelled
+	ReasonMembershipExpired   = "membership_expired"    // Membership expiry has passed
+	ReasonOutsideAllowedHours = "outside_allowed_hours" // Event falls outside the member's access windows
+	ReasonDeviceDenied        = "device_denied"         // Hardware already rejected the credential
+	ReasonDecisionError       = "decision_error"        // Local cache could not be read
+	ReasonLockdown            = "lockdown"              // Doors are in lockdown, every unlock is blocked
+	ReasonAntiPassback        = "anti_passback"         // Member is already inside (entry) or outside (exit) the zone
+	ReasonNotSynced           = "not_synced"            // Entitlement cache has not been synced from the platform yet
+)
+
+// Config holds configuration for the access-decision engine
+type Config struct {
+	AllowUnknownMembers bool               `json:"allowUnknownMembers"` // Grant access when no entitlement is cached
+	ExpiryGracePeriod   time.Duration      `json:"expiryGracePeriod"`
+	UnlockOnExit        bool               `json:"unlockOnExit"`
+	UnlockDurationMs    int                `json:"unlockDurationMs"`
+	MaxEventAge         time.Duration      `json:"maxEventAge"` // Older events never unlock the door, zero disables the check
+	Timezone            string             `json:"timezone"`    // IANA timezone for allowed hours, empty means local time
+	AntiPassback        AntiPassbackConfig `json:"antiPassback"`
+}
+
+// DefaultConfig returns the default access-decision configuration
+func DefaultConfig() Config {
+	return Config{
+		AllowUnknownMembers: false,
+		ExpiryGracePeriod:   0,
+		UnlockOnExit:        true,
+		UnlockDurationMs:    3000,
+		MaxEventAge:         5 * time.Second,
+		Timezone:            "",
+		AntiPassback: AntiPassbackConfig{
+			Mode: AntiPassbackOff,
+		},
+	}
+}
+
+// Decision is the allow/deny verdict for a single hardware event
+type Decision struct {
+	Allowed               bool      `json:"allowed"`
+	Reason                string    `json:"reason"`
+	InternalUserID        string    `json:"internalUserId,omitempty"`
+	Unlocked              bool      `json:"unlocked"`
+	AntiPassbackViolation bool      `json:"antiPassbackViolation,omitempty"` // Set in soft and hard anti-passback mode
+	Stale                 bool      `json:"stale,omitempty"`                 // Event was older than the maximum event age
+	EvaluatedAt           time.Time `json:"evaluatedAt"`
+}
+
+// EntitlementStore defines the database methods needed by the access engine
+type EntitlementStore interface {
+	ResolveExternalUserID(externalUserID string) (string, error)
+	GetMemberEntitlement(internalUserID string) (*database.MemberEntitlement, error)
+}
+
+// SyncStateStore is implemented by entitlement stores that record when the cache
+// was last synced. Engines backed by one make no decisions before the first sync.
+type SyncStateStore interface {
+	GetConfig(key string) (string, error)
+}
+
+// DoorUnlocker defines the door control method needed by the access engine
+type DoorUnlocker interface {
+	UnlockDoor(ctx context.Context, adapterName string, durationMs int) error
+}
+
+// DoorIDUnlocker is implemented by door unlockers that can release a specific door.
+// When the unlocker supports it, events carrying a door ID unlock that door.
+type DoorIDUnlocker interface {
+	UnlockDoorByID(ctx context.Context, doorID string, durationMs int) error
+}
+
+// LockdownChecker is implemented by door unlockers that support a site lockdown.
+// While a lockdown is active every event is denied.
+type LockdownChecker interface {
+	InLockdown() bool
+}
+
+// Decider makes local allow/deny decisions for hardware events
+type Decider interface {
+	// Decide evaluates an event against the local entitlement cache without side effects
+	Decide(ctx context.Context, event types.RawHardwareEvent) Decision
+
+	// HandleEvent evaluates an event and unlocks the door when access is granted
+	HandleEvent(ctx context.Context, event types.RawHardwareEvent) Decision
+
+	// GetStats returns access decision statistics
+	GetStats() Stats
+}
+
+// Stats contains statistics about local access decisions
+type Stats struct {
+	TotalAllowed           int64            `json:"totalAllowed"`
+	TotalDenied            int64            `json:"totalDenied"`
+	UnlockFailures         int64            `json:"unlockFailures"`
+	AntiPassbackViolations int64            `json:"antiPassbackViolations"`
+	DeniedByReason         map[string]int64 `json:"deniedByReason"`
+	LastDecisionAt         int64            `json:"lastDecisionAt"` // Unix timestamp
+}
@@ -0,0 +1,207 @@
+package access
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"gym-door-bridge/internal/database"
+	"gym-door-bridge/internal/types"
+)
+
+// Anti-passback modes
+const (
+	AntiPassbackOff  = "off"
+	AntiPassbackSoft = "soft" // Allow the event but flag the violation
+	AntiPassbackHard = "hard" // Deny the event
+)
+
+// AntiPassbackStateUnknown is reported for members with no state in a zone, or
+// whose state predates the last reset
+const AntiPassbackStateUnknown = "unknown"
+
+// AntiPassbackConfig configures anti-passback enforcement
+type AntiPassbackConfig struct {
+	Mode      string `json:"mode"`      // off, soft or hard
+	ResetTime string `json:"resetTime"` // Daily "HH:MM" after which every member's state is forgotten, empty never
+}
+
+// AntiPassbackStore persists each member's in/out state per zone
+type AntiPassbackStore interface {
+	GetAntiPassbackState(internalUserID, zone string) (*database.AntiPassbackState, error)
+	SetAntiPassbackState(state *database.AntiPassbackState) error
+	ListAntiPassbackStates(internalUserID string) ([]database.AntiPassbackState, error)
+	ResetAntiPassbackStates(internalUserID string) (int64, error)
+}
+
+// ZoneResolver is implemented by door unlockers that know which zone a door
+// leads into. Anti-passback tracks members per zone; doors without a zone share
+// one site-wide zone.
+type ZoneResolver interface {
+	ZoneForDoor(doorID string) string
+}
+
+// AntiPassbackStatus is a member's effective state in a zone
+type AntiPassbackStatus struct {
+	Zone      string    `json:"zone"`
+	State     string    `json:"state"` // in, out or unknown
+	DoorID    string    `json:"doorId,omitempty"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// validateAntiPassback checks the anti-passback configuration
+func validateAntiPassback(config AntiPassbackConfig) error {
+	switch config.Mode {
+	case "", AntiPassbackOff, AntiPassbackSoft, AntiPassbackHard:
+	default:
+		return fmt.Errorf("invalid anti-passback mode %q", config.Mode)
+	}
+	if config.ResetTime != "" {
+		if _, err := parseClock(config.ResetTime); err != nil {
+			return fmt.Errorf("invalid anti-passback reset time: %w", err)
+		}
+	}
+	return nil
+}
+
+// antiPassbackEnabled reports whether anti-passback is enforced
+func (e *Engine) antiPassbackEnabled() bool {
+	return e.antiPassback != nil
+}
+
+// zoneFor returns the anti-passback zone of a door
+func (e *Engine) zoneFor(doorID string) string {
+	if doorID == "" {
+		return ""
+	}
+	if zones, ok := e.unlocker.(ZoneResolver); ok {
+		return zones.ZoneForDoor(doorID)
+	}
+	return ""
+}
+
+// checkAntiPassback reports whether a granted entry or exit contradicts the
+// member's recorded state in the door's zone
+func (e *Engine) checkAntiPassback(event types.RawHardwareEvent, internalUserID string, at time.Time) bool {
+	if event.EventType != types.EventTypeEntry && event.EventType != types.EventTypeExit {
+		return false
+	}
+
+	zone := e.zoneFor(event.DoorID)
+	state, err := e.antiPassback.GetAntiPassbackState(internalUserID, zone)
+	if err != nil {
+		// Unknown state must not lock a member out
+		e.logger.WithError(err).WithField("internal_user_id", internalUserID).Warn("Failed to read anti-passback state")
+		return false
+	}
+
+	switch e.effectiveState(state, at) {
+	case database.AntiPassbackIn:
+		return event.EventType == types.EventTypeEntry
+	case database.AntiPassbackOut:
+		return event.EventType == types.EventTypeExit
+	default:
+		return false
+	}
+}
+
+// recordPassage stores the member's new state after a granted entry or exit
+func (e *Engine) recordPassage(event types.RawHardwareEvent, internalUserID string, at time.Time) {
+	state := database.AntiPassbackIn
+	switch event.EventType {
+	case types.EventTypeEntry:
+	case types.EventTypeExit:
+		state = database.AntiPassbackOut
+	default:
+		return
+	}
+
+	err := e.antiPassback.SetAntiPassbackState(&database.AntiPassbackState{
+		InternalUserID: internalUserID,
+		Zone:           e.zoneFor(event.DoorID),
+		State:          state,
+		DoorID:         event.DoorID,
+		UpdatedAt:      at,
+	})
+	if err != nil {
+		e.logger.WithError(err).WithFields(logrus.Fields{
+			"internal_user_id": internalUserID,
+			"door_id":          event.DoorID,
+		}).Error("Failed to record anti-passback state")
+	}
+}
+
+// effectiveState returns a stored state, or unknown when there is none or it
+// was set before the last reset
+func (e *Engine) effectiveState(state *database.AntiPassbackState, at time.Time) string {
+	if state == nil {
+		return AntiPassbackStateUnknown
+	}
+	if reset := e.lastAntiPassbackReset(at); !reset.IsZero() && state.UpdatedAt.Before(reset) {
+		return AntiPassbackStateUnknown
+	}
+	return state.State
+}
+
+// lastAntiPassbackReset returns the most recent daily reset at or before t, or
+// the zero time when no reset time is configured
+func (e *Engine) lastAntiPassbackReset(t time.Time) time.Time {
+	if e.config.AntiPassback.ResetTime == "" {
+		return time.Time{}
+	}
+	minutes, err := parseClock(e.config.AntiPassback.ResetTime)
+	if err != nil {
+		return time.Time{}
+	}
+
+	local := t.In(e.location)
+	reset := time.Date(local.Year(), local.Month(), local.Day(), minutes/60, minutes%60, 0, 0, e.location)
+	if reset.After(local) {
+		reset = time.Date(local.Year(), local.Month(), local.Day()-1, minutes/60, minutes%60, 0, 0, e.location)
+	}
+	return reset
+}
+
+// AntiPassbackStates returns a member's effective state in every zone they have passed through
+func (e *Engine) AntiPassbackStates(internalUserID string) ([]AntiPassbackStatus, error) {
+	if !e.antiPassbackEnabled() {
+		return nil, fmt.Errorf("anti-passback is not enabled")
+	}
+
+	states, err := e.antiPassback.ListAntiPassbackStates(internalUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	statuses := make([]AntiPassbackStatus, 0, len(states))
+	for i := range states {
+		statuses = append(statuses, AntiPassbackStatus{
+			Zone:      states[i].Zone,
+			State:     e.effectiveState(&states[i], now),
+			DoorID:    states[i].DoorID,
+			UpdatedAt: states[i].UpdatedAt,
+		})
+	}
+	return statuses, nil
+}
+
+// ResetAntiPassback forgets a member's state in every zone, or every member's
+// state when internalUserID is empty, so their next entry or exit is allowed
+func (e *Engine) ResetAntiPassback(internalUserID string) (int64, error) {
+	if !e.antiPassbackEnabled() {
+		return 0, fmt.Errorf("anti-passback is not enabled")
+	}
+
+	removed, err := e.antiPassback.ResetAntiPassbackStates(internalUserID)
+	if err != nil {
+		return 0, err
+	}
+
+	e.logger.WithFields(logrus.Fields{
+		"internal_user_id": internalUserID,
+		"removed":          removed,
+	}).Info("Anti-passback state reset")
+	return removed, nil
+}
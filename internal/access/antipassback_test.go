@@ -0,0 +1,230 @@
+package access
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"gym-door-bridge/internal/database"
+	"gym-door-bridge/internal/types"
+)
+
+// antiPassbackStore is an in-memory store that also persists anti-passback states
+type antiPassbackStore struct {
+	*mockStore
+	states map[string]database.AntiPassbackState // By user and zone
+}
+
+func newAntiPassbackStore() *antiPassbackStore {
+	return &antiPassbackStore{mockStore: newTestStore(), states: make(map[string]database.AntiPassbackState)}
+}
+
+func (s *antiPassbackStore) GetAntiPassbackState(internalUserID, zone string) (*database.AntiPassbackState, error) {
+	state, ok := s.states[internalUserID+"/"+zone]
+	if !ok {
+		return nil, nil
+	}
+	return &state, nil
+}
+
+func (s *antiPassbackStore) SetAntiPassbackState(state *database.AntiPassbackState) error {
+	s.states[state.InternalUserID+"/"+state.Zone] = *state
+	return nil
+}
+
+func (s *antiPassbackStore) ListAntiPassbackStates(internalUserID string) ([]database.AntiPassbackState, error) {
+	var states []database.AntiPassbackState
+	for _, state := range s.states {
+		if state.InternalUserID == internalUserID {
+			states = append(states, state)
+		}
+	}
+	return states, nil
+}
+
+func (s *antiPassbackStore) ResetAntiPassbackStates(internalUserID string) (int64, error) {
+	var removed int64
+	for key, state := range s.states {
+		if internalUserID == "" || state.InternalUserID == internalUserID {
+			delete(s.states, key)
+			removed++
+		}
+	}
+	return removed, nil
+}
+
+// zoneUnlocker unlocks doors by ID and knows their zones
+type zoneUnlocker struct {
+	mockUnlocker
+	zones map[string]string
+}
+
+func (z *zoneUnlocker) UnlockDoorByID(ctx context.Context, doorID string, durationMs int) error {
+	return z.UnlockDoor(ctx, "", durationMs)
+}
+
+func (z *zoneUnlocker) ZoneForDoor(doorID string) string {
+	return z.zones[doorID]
+}
+
+func newAntiPassbackEngine(t *testing.T, mode string) (*Engine, *antiPassbackStore, *zoneUnlocker) {
+	t.Helper()
+
+	config := DefaultConfig()
+	config.Timezone = "UTC"
+	config.AntiPassback = AntiPassbackConfig{Mode: mode, ResetTime: "03:00"}
+
+	store := newAntiPassbackStore()
+	unlocker := &zoneUnlocker{zones: map[string]string{"front": "gym", "turnstile": "gym", "pool": "pool"}}
+	engine, err := NewEngine(config, store, unlocker, newTestLogger())
+	if err != nil {
+		t.Fatalf("NewEngine() error = %v", err)
+	}
+	pinClock(engine)
+	return engine, store, unlocker
+}
+
+func passage(eventType, doorID string, at time.Time) types.RawHardwareEvent {
+	return types.RawHardwareEvent{ExternalUserID: "fp_active", EventType: eventType, DoorID: doorID, Timestamp: at}
+}
+
+func TestAntiPassback_Hard(t *testing.T) {
+	ctx := context.Background()
+	engine, store, unlocker := newAntiPassbackEngine(t, AntiPassbackHard)
+	now := time.Date(2025, 6, 4, 18, 0, 0, 0, time.UTC)
+
+	decision := engine.HandleEvent(ctx, passage(types.EventTypeEntry, "front", now))
+	if !decision.Allowed || decision.AntiPassbackViolation {
+		t.Fatalf("expected first entry to be granted, got %+v", decision)
+	}
+	if state := store.states["user_active/gym"]; state.State != database.AntiPassbackIn || state.DoorID != "front" {
+		t.Errorf("expected member inside the gym zone, got %+v", state)
+	}
+
+	// The card is passed back through the turnstile
+	decision = engine.HandleEvent(ctx, passage(types.EventTypeEntry, "turnstile", now.Add(time.Minute)))
+	if decision.Allowed || decision.Reason != ReasonAntiPassback || !decision.AntiPassbackViolation {
+		t.Errorf("expected anti-passback denial, got %+v", decision)
+	}
+	if unlocker.calls != 1 {
+		t.Errorf("expected the denied entry not to unlock, got %d unlocks", unlocker.calls)
+	}
+
+	// Other zones are tracked separately
+	if decision = engine.HandleEvent(ctx, passage(types.EventTypeEntry, "pool", now.Add(2*time.Minute))); !decision.Allowed {
+		t.Errorf("expected entry to another zone to be granted, got %+v", decision)
+	}
+
+	if decision = engine.HandleEvent(ctx, passage(types.EventTypeExit, "front", now.Add(time.Hour))); !decision.Allowed {
+		t.Errorf("expected exit to be granted, got %+v", decision)
+	}
+	if decision = engine.HandleEvent(ctx, passage(types.EventTypeExit, "front", now.Add(time.Hour+time.Minute))); decision.Allowed {
+		t.Errorf("expected a second exit to be denied, got %+v", decision)
+	}
+	if decision = engine.HandleEvent(ctx, passage(types.EventTypeEntry, "front", now.Add(2*time.Hour))); !decision.Allowed {
+		t.Errorf("expected re-entry after exit to be granted, got %+v", decision)
+	}
+
+	stats := engine.GetStats()
+	if stats.AntiPassbackViolations != 2 || stats.DeniedByReason[ReasonAntiPassback] != 2 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestAntiPassback_Soft(t *testing.T) {
+	ctx := context.Background()
+	engine, _, unlocker := newAntiPassbackEngine(t, AntiPassbackSoft)
+	now := time.Date(2025, 6, 4, 18, 0, 0, 0, time.UTC)
+
+	engine.HandleEvent(ctx, passage(types.EventTypeEntry, "front", now))
+	decision := engine.HandleEvent(ctx, passage(types.EventTypeEntry, "front", now.Add(time.Minute)))
+	if !decision.Allowed || decision.Reason != ReasonGranted || !decision.AntiPassbackViolation || !decision.Unlocked {
+		t.Errorf("expected flagged grant, got %+v", decision)
+	}
+	if unlocker.calls != 2 {
+		t.Errorf("expected both entries to unlock, got %d unlocks", unlocker.calls)
+	}
+	if stats := engine.GetStats(); stats.AntiPassbackViolations != 1 || stats.TotalDenied != 0 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestAntiPassback_DailyReset(t *testing.T) {
+	ctx := context.Background()
+	engine, _, _ := newAntiPassbackEngine(t, AntiPassbackHard)
+	evening := time.Date(2025, 6, 4, 20, 0, 0, 0, time.UTC)
+
+	engine.HandleEvent(ctx, passage(types.EventTypeEntry, "front", evening))
+
+	// Before the 03:00 reset the member is still inside
+	if decision := engine.Decide(ctx, passage(types.EventTypeEntry, "front", evening.Add(6*time.Hour))); decision.Allowed {
+		t.Errorf("expected denial before the reset, got %+v", decision)
+	}
+	// After it their state is forgotten
+	if decision := engine.Decide(ctx, passage(types.EventTypeEntry, "front", evening.Add(8*time.Hour))); !decision.Allowed {
+		t.Errorf("expected entry after the reset, got %+v", decision)
+	}
+}
+
+func TestAntiPassback_UnlockFailureKeepsState(t *testing.T) {
+	ctx := context.Background()
+	engine, store, unlocker := newAntiPassbackEngine(t, AntiPassbackHard)
+	unlocker.err = errors.New("relay offline")
+
+	engine.HandleEvent(ctx, passage(types.EventTypeEntry, "front", time.Date(2025, 6, 4, 18, 0, 0, 0, time.UTC)))
+	if len(store.states) != 0 {
+		t.Errorf("expected no state when the door did not open, got %+v", store.states)
+	}
+}
+
+func TestAntiPassback_StatesAndReset(t *testing.T) {
+	ctx := context.Background()
+	engine, _, _ := newAntiPassbackEngine(t, AntiPassbackHard)
+	now := time.Now()
+
+	engine.HandleEvent(ctx, passage(types.EventTypeEntry, "front", now))
+
+	states, err := engine.AntiPassbackStates("user_active")
+	if err != nil {
+		t.Fatalf("AntiPassbackStates() error = %v", err)
+	}
+	if len(states) != 1 || states[0].Zone != "gym" || states[0].State != database.AntiPassbackIn {
+		t.Errorf("unexpected states: %+v", states)
+	}
+
+	removed, err := engine.ResetAntiPassback("user_active")
+	if err != nil || removed != 1 {
+		t.Errorf("expected 1 state reset, got %d (%v)", removed, err)
+	}
+	if decision := engine.Decide(ctx, passage(types.EventTypeEntry, "front", now)); !decision.Allowed {
+		t.Errorf("expected entry after a manual reset, got %+v", decision)
+	}
+}
+
+func TestAntiPassback_Config(t *testing.T) {
+	config := DefaultConfig()
+	config.AntiPassback.Mode = "strict"
+	if _, err := NewEngine(config, newAntiPassbackStore(), &mockUnlocker{}, newTestLogger()); err == nil {
+		t.Errorf("expected error for an invalid mode")
+	}
+
+	config.AntiPassback = AntiPassbackConfig{Mode: AntiPassbackHard, ResetTime: "25:00"}
+	if _, err := NewEngine(config, newAntiPassbackStore(), &mockUnlocker{}, newTestLogger()); err == nil {
+		t.Errorf("expected error for an invalid reset time")
+	}
+
+	// The store must be able to persist states
+	config.AntiPassback.ResetTime = ""
+	if _, err := NewEngine(config, newTestStore(), &mockUnlocker{}, newTestLogger()); err == nil {
+		t.Errorf("expected error for a store without anti-passback support")
+	}
+
+	engine, err := NewEngine(DefaultConfig(), newTestStore(), &mockUnlocker{}, newTestLogger())
+	if err != nil {
+		t.Fatalf("NewEngine() error = %v", err)
+	}
+	if _, err := engine.AntiPassbackStates("user_active"); err == nil {
+		t.Errorf("expected error while anti-passback is off")
+	}
+}
@@ -0,0 +1,309 @@
+package access
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gym-door-bridge/internal/database"
+	"gym-door-bridge/internal/logging"
+	"gym-door-bridge/internal/types"
+)
+
+// Engine makes allow/deny decisions from the local entitlement cache
+// so the door keeps working while the platform is unreachable
+type Engine struct {
+	config   Config
+	location *time.Location
+	store    EntitlementStore
+	unlocker DoorUnlocker
+	logger   *logrus.Entry
+	stats    Stats
+	mutex    sync.RWMutex
+
+	// antiPassback is nil when anti-passback is off
+	antiPassback AntiPassbackStore
+
+	// synced latches once the entitlement cache has been synced
+	synced atomic.Bool
+	now    func() time.Time
+}
+
+// NewEngine creates a new access-decision engine
+func NewEngine(config Config, store EntitlementStore, unlocker DoorUnlocker, logger *logrus.Logger) (*Engine, error) {
+	if store == nil {
+		return nil, fmt.Errorf("entitlement store is required")
+	}
+
+	location := time.Local
+	if config.Timezone != "" {
+		loc, err := time.LoadLocation(config.Timezone)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load timezone %s: %w", config.Timezone, err)
+		}
+		location = loc
+	}
+
+	if config.UnlockDurationMs <= 0 {
+		config.UnlockDurationMs = DefaultConfig().UnlockDurationMs
+	}
+
+	if err := validateAntiPassback(config.AntiPassback); err != nil {
+		return nil, err
+	}
+	var antiPassback AntiPassbackStore
+	if config.AntiPassback.Mode == AntiPassbackSoft || config.AntiPassback.Mode == AntiPassbackHard {
+		var ok bool
+		if antiPassback, ok = store.(AntiPassbackStore); !ok {
+			return nil, fmt.Errorf("anti-passback requires a store that persists member states")
+		}
+	}
+
+	return &Engine{
+		config:   config,
+		location: location,
+		store:    store,
+		unlocker: unlocker,
+		logger:   logging.NewServiceLogger(logger, "access-engine"),
+		stats:    Stats{DeniedByReason: make(map[string]int64)},
+
+		antiPassback: antiPassback,
+		now:          time.Now,
+	}, nil
+}
+
+// Decide evaluates an event against the local entitlement cache without side effects
+func (e *Engine) Decide(ctx context.Context, event types.RawHardwareEvent) Decision {
+	evaluatedAt := event.Timestamp
+	if evaluatedAt.IsZero() {
+		evaluatedAt = e.now()
+	}
+
+	decision := Decision{EvaluatedAt: evaluatedAt}
+
+	// The hardware already rejected the credential, nothing to override
+	if event.EventType == types.EventTypeDenied {
+		decision.Reason = ReasonDeviceDenied
+		return decision
+	}
+
+	if lockdown, ok := e.unlocker.(LockdownChecker); ok && lockdown.InLockdown() {
+		decision.Reason = ReasonLockdown
+		return decision
+	}
+
+	// An empty cache would deny every member, leave the verdict to the hardware
+	if !e.cacheSynced() {
+		decision.Reason = ReasonNotSynced
+		return decision
+	}
+
+	internalUserID, err := e.store.ResolveExternalUserID(event.ExternalUserID)
+	if err != nil {
+		e.logger.WithError(err).WithField("external_user_id", event.ExternalUserID).Error("Failed to resolve member for access decision")
+		decision.Reason = ReasonDecisionError
+		return decision
+	}
+	if internalUserID == "" {
+		return e.unknownMemberDecision(decision, ReasonUnknownMember)
+	}
+	decision.InternalUserID = internalUserID
+
+	entitlement, err := e.store.GetMemberEntitlement(internalUserID)
+	if err != nil {
+		e.logger.WithError(err).WithField("internal_user_id", internalUserID).Error("Failed to load member entitlement")
+		decision.Reason = ReasonDecisionError
+		return decision
+	}
+	if entitlement == nil {
+		return e.unknownMemberDecision(decision, ReasonNoEntitlement)
+	}
+
+	decision.Reason = e.evaluateEntitlement(entitlement, evaluatedAt)
+	decision.Allowed = decision.Reason == ReasonGranted
+
+	// Only members who may pass are checked against their in/out state
+	if decision.Allowed && e.antiPassbackEnabled() && e.checkAntiPassback(event, internalUserID, evaluatedAt) {
+		decision.AntiPassbackViolation = true
+		if e.config.AntiPassback.Mode == AntiPassbackHard {
+			decision.Allowed = false
+			decision.Reason = ReasonAntiPassback
+		}
+	}
+	return decision
+}
+
+// unlock releases the door the event happened at, or the default door
+func (e *Engine) unlock(ctx context.Context, event types.RawHardwareEvent) error {
+	if event.DoorID != "" {
+		if doors, ok := e.unlocker.(DoorIDUnlocker); ok {
+			// A zero duration lets the door's own unlock duration apply
+			return doors.UnlockDoorByID(ctx, event.DoorID, 0)
+		}
+	}
+	return e.unlocker.UnlockDoor(ctx, "", e.config.UnlockDurationMs)
+}
+
+// HandleEvent evaluates an event and unlocks the door when access is granted
+func (e *Engine) HandleEvent(ctx context.Context, event types.RawHardwareEvent) Decision {
+	decision := e.Decide(ctx, event)
+
+	// A buffered or replayed event must not open the door long after the fact
+	decision.Stale = e.isStale(event)
+	if decision.Stale {
+		e.logger.WithFields(logrus.Fields{
+			"external_user_id": event.ExternalUserID,
+			"event_time":       event.Timestamp,
+		}).Warn("Ignoring stale event for door unlock")
+	}
+
+	if decision.Allowed && !decision.Stale && e.shouldUnlock(event.EventType) && e.unlocker != nil {
+		if err := e.unlock(ctx, event); err != nil {
+			e.logger.WithError(err).WithFields(logrus.Fields{
+				"external_user_id": event.ExternalUserID,
+				"internal_user_id": decision.InternalUserID,
+			}).Error("Access granted but door unlock failed")
+			e.recordUnlockFailure()
+		} else {
+			decision.Unlocked = true
+		}
+	}
+
+	// The member passed unless the door failed to open for them
+	passed := decision.Allowed && !decision.Stale && (decision.Unlocked || e.unlocker == nil || !e.shouldUnlock(event.EventType))
+	if passed && e.antiPassbackEnabled() && decision.InternalUserID != "" {
+		e.recordPassage(event, decision.InternalUserID, decision.EvaluatedAt)
+	}
+
+	e.recordDecision(decision)
+
+	e.logger.WithFields(logrus.Fields{
+		"external_user_id": event.ExternalUserID,
+		"internal_user_id": decision.InternalUserID,
+		"event_type":       event.EventType,
+		"allowed":          decision.Allowed,
+		"reason":           decision.Reason,
+		"unlocked":         decision.Unlocked,
+		"anti_passback":    decision.AntiPassbackViolation,
+		"stale":            decision.Stale,
+	}).Info("Access decision made")
+
+	return decision
+}
+
+// GetStats returns access decision statistics
+func (e *Engine) GetStats() Stats {
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
+
+	stats := e.stats
+	stats.DeniedByReason = make(map[string]int64, len(e.stats.DeniedByReason))
+	for reason, count := range e.stats.DeniedByReason {
+		stats.DeniedByReason[reason] = count
+	}
+	return stats
+}
+
+// evaluateEntitlement returns the reason code for a cached entitlement at the given time
+func (e *Engine) evaluateEntitlement(entitlement *database.MemberEntitlement, at time.Time) string {
+	switch entitlement.Status {
+	case database.MembershipStatusActive:
+	case database.MembershipStatusExpired:
+		return ReasonMembershipExpired
+	default:
+		return ReasonMembershipInactive
+	}
+
+	if entitlement.MembershipExpiresAt != nil &&
+		at.After(entitlement.MembershipExpiresAt.Add(e.config.ExpiryGracePeriod)) {
+		return ReasonMembershipExpired
+	}
+
+	windows, err := ParseAllowedHours(entitlement.AllowedHours)
+	if err != nil {
+		// A corrupt schedule must not lock an active member out
+		e.logger.WithError(err).WithField("internal_user_id", entitlement.InternalUserID).Warn("Ignoring invalid allowed hours")
+		return ReasonGranted
+	}
+	if !WithinAllowedHours(windows, at.In(e.location)) {
+		return ReasonOutsideAllowedHours
+	}
+
+	return ReasonGranted
+}
+
+// unknownMemberDecision applies the configured policy for members missing from the cache
+func (e *Engine) unknownMemberDecision(decision Decision, reason string) Decision {
+	decision.Reason = reason
+	if e.config.AllowUnknownMembers {
+		decision.Allowed = true
+	}
+	return decision
+}
+
+// cacheSynced reports whether the entitlement cache has been synced at least once.
+// Stores that do not track syncs are assumed to be filled.
+func (e *Engine) cacheSynced() bool {
+	if e.synced.Load() {
+		return true
+	}
+
+	syncState, ok := e.store.(SyncStateStore)
+	if !ok {
+		return true
+	}
+	if lastSync, err := syncState.GetConfig(LastSyncConfigKey); err != nil || lastSync == "" {
+		return false
+	}
+
+	e.synced.Store(true)
+	return true
+}
+
+// isStale reports whether an event is too old to act on
+func (e *Engine) isStale(event types.RawHardwareEvent) bool {
+	if e.config.MaxEventAge <= 0 || event.Timestamp.IsZero() {
+		return false
+	}
+	return e.now().Sub(event.Timestamp) > e.config.MaxEventAge
+}
+
+// shouldUnlock reports whether a granted event of this type should open the door
+func (e *Engine) shouldUnlock(eventType string) bool {
+	switch eventType {
+	case types.EventTypeEntry:
+		return true
+	case types.EventTypeExit:
+		return e.config.UnlockOnExit
+	default:
+		return false
+	}
+}
+
+// recordDecision updates decision statistics
+func (e *Engine) recordDecision(decision Decision) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	if decision.Allowed {
+		e.stats.TotalAllowed++
+	} else {
+		e.stats.TotalDenied++
+		e.stats.DeniedByReason[decision.Reason]++
+	}
+	if decision.AntiPassbackViolation {
+		e.stats.AntiPassbackViolations++
+	}
+	e.stats.LastDecisionAt = e.now().Unix()
+}
+
+// recordUnlockFailure increments the unlock failure counter
+func (e *Engine) recordUnlockFailure() {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	e.stats.UnlockFailures++
+}
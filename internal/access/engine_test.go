@@ -0,0 +1,542 @@
+package access
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gym-door-bridge/internal/database"
+	"gym-door-bridge/internal/types"
+)
+
+// mockStore is an in-memory EntitlementStore for testing
+type mockStore struct {
+	mappings     map[string]string
+	entitlements map[string]*database.MemberEntitlement
+	err          error
+}
+
+func (m *mockStore) ResolveExternalUserID(externalUserID string) (string, error) {
+	if m.err != nil {
+		return "", m.err
+	}
+	return m.mappings[externalUserID], nil
+}
+
+func (m *mockStore) GetMemberEntitlement(internalUserID string) (*database.MemberEntitlement, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return m.entitlements[internalUserID], nil
+}
+
+// mockUnlocker records unlock calls
+type mockUnlocker struct {
+	calls      int
+	durationMs int
+	err        error
+}
+
+func (m *mockUnlocker) UnlockDoor(ctx context.Context, adapterName string, durationMs int) error {
+	m.calls++
+	m.durationMs = durationMs
+	return m.err
+}
+
+func newTestLogger() *logrus.Logger {
+	logger := logrus.New()
+	logger.SetLevel(logrus.ErrorLevel) // Reduce noise in tests
+	return logger
+}
+
+// pinClock stands the engine clock at the start of the day the test events
+// happen on, so none of them count as stale
+func pinClock(engine *Engine) {
+	engine.now = func() time.Time { return time.Date(2025, 6, 4, 0, 0, 0, 0, time.UTC) }
+}
+
+func newTestStore() *mockStore {
+	expired := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	future := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	return &mockStore{
+		mappings: map[string]string{
+			"fp_active":    "user_active",
+			"fp_suspended": "user_suspended",
+			"fp_expired":   "user_expired",
+			"fp_lapsed":    "user_lapsed",
+			"fp_hours":     "user_hours",
+			"fp_nocache":   "user_nocache",
+		},
+		entitlements: map[string]*database.MemberEntitlement{
+			"user_active": {
+				InternalUserID:      "user_active",
+				Status:              database.MembershipStatusActive,
+				MembershipExpiresAt: &future,
+			},
+			"user_suspended": {
+				InternalUserID: "user_suspended",
+				Status:         database.MembershipStatusSuspended,
+			},
+			"user_expired": {
+				InternalUserID: "user_expired",
+				Status:         database.MembershipStatusExpired,
+			},
+			"user_lapsed": {
+				InternalUserID:      "user_lapsed",
+				Status:              database.MembershipStatusActive,
+				MembershipExpiresAt: &expired,
+			},
+			"user_hours": {
+				InternalUserID: "user_hours",
+				Status:         database.MembershipStatusActive,
+				AllowedHours:   `[{"days":[1,2,3,4,5],"start":"06:00","end":"10:00"}]`,
+			},
+		},
+	}
+}
+
+func TestEngine_Decide(t *testing.T) {
+	config := DefaultConfig()
+	config.Timezone = "UTC"
+
+	engine, err := NewEngine(config, newTestStore(), &mockUnlocker{}, newTestLogger())
+	if err != nil {
+		t.Fatalf("NewEngine() error = %v", err)
+	}
+
+	// Wednesday 2025-06-04
+	morning := time.Date(2025, 6, 4, 8, 0, 0, 0, time.UTC)
+	evening := time.Date(2025, 6, 4, 20, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		event       types.RawHardwareEvent
+		wantAllowed bool
+		wantReason  string
+	}{
+		{
+			name:        "active member",
+			event:       types.RawHardwareEvent{ExternalUserID: "fp_active", EventType: types.EventTypeEntry, Timestamp: morning},
+			wantAllowed: true,
+			wantReason:  ReasonGranted,
+		},
+		{
+			name:        "unknown credential",
+			event:       types.RawHardwareEvent{ExternalUserID: "fp_stranger", EventType: types.EventTypeEntry, Timestamp: morning},
+			wantAllowed: false,
+			wantReason:  ReasonUnknownMember,
+		},
+		{
+			name:        "mapped member without cached entitlement",
+			event:       types.RawHardwareEvent{ExternalUserID: "fp_nocache", EventType: types.EventTypeEntry, Timestamp: morning},
+			wantAllowed: false,
+			wantReason:  ReasonNoEntitlement,
+		},
+		{
+			name:        "suspended member",
+			event:       types.RawHardwareEvent{ExternalUserID: "fp_suspended", EventType: types.EventTypeEntry, Timestamp: morning},
+			wantAllowed: false,
+			wantReason:  ReasonMembershipInactive,
+		},
+		{
+			name:        "expired status",
+			event:       types.RawHardwareEvent{ExternalUserID: "fp_expired", EventType: types.EventTypeEntry, Timestamp: morning},
+			wantAllowed: false,
+			wantReason:  ReasonMembershipExpired,
+		},
+		{
+			name:        "active status past expiry date",
+			event:       types.RawHardwareEvent{ExternalUserID: "fp_lapsed", EventType: types.EventTypeEntry, Timestamp: morning},
+			wantAllowed: false,
+			wantReason:  ReasonMembershipExpired,
+		},
+		{
+			name:        "inside allowed hours",
+			event:       types.RawHardwareEvent{ExternalUserID: "fp_hours", EventType: types.EventTypeEntry, Timestamp: morning},
+			wantAllowed: true,
+			wantReason:  ReasonGranted,
+		},
+		{
+			name:        "outside allowed hours",
+			event:       types.RawHardwareEvent{ExternalUserID: "fp_hours", EventType: types.EventTypeEntry, Timestamp: evening},
+			wantAllowed: false,
+			wantReason:  ReasonOutsideAllowedHours,
+		},
+		{
+			name:        "hardware denied event",
+			event:       types.RawHardwareEvent{ExternalUserID: "fp_active", EventType: types.EventTypeDenied, Timestamp: morning},
+			wantAllowed: false,
+			wantReason:  ReasonDeviceDenied,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decision := engine.Decide(context.Background(), tt.event)
+
+			if decision.Allowed != tt.wantAllowed {
+				t.Errorf("Decide() allowed = %v, want %v", decision.Allowed, tt.wantAllowed)
+			}
+			if decision.Reason != tt.wantReason {
+				t.Errorf("Decide() reason = %s, want %s", decision.Reason, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestEngine_Decide_GracePeriodAndUnknownPolicy(t *testing.T) {
+	config := DefaultConfig()
+	config.ExpiryGracePeriod = 7 * 24 * time.Hour
+	config.AllowUnknownMembers = true
+
+	store := newTestStore()
+	lapsedAt := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	store.entitlements["user_lapsed"].MembershipExpiresAt = &lapsedAt
+
+	engine, err := NewEngine(config, store, nil, newTestLogger())
+	if err != nil {
+		t.Fatalf("NewEngine() error = %v", err)
+	}
+
+	withinGrace := types.RawHardwareEvent{ExternalUserID: "fp_lapsed", EventType: types.EventTypeEntry, Timestamp: lapsedAt.Add(3 * 24 * time.Hour)}
+	if decision := engine.Decide(context.Background(), withinGrace); !decision.Allowed {
+		t.Errorf("expected access within grace period, got reason %s", decision.Reason)
+	}
+
+	afterGrace := types.RawHardwareEvent{ExternalUserID: "fp_lapsed", EventType: types.EventTypeEntry, Timestamp: lapsedAt.Add(8 * 24 * time.Hour)}
+	if decision := engine.Decide(context.Background(), afterGrace); decision.Allowed {
+		t.Errorf("expected access to be denied after grace period")
+	}
+
+	unknown := types.RawHardwareEvent{ExternalUserID: "fp_stranger", EventType: types.EventTypeEntry, Timestamp: lapsedAt}
+	decision := engine.Decide(context.Background(), unknown)
+	if !decision.Allowed || decision.Reason != ReasonUnknownMember {
+		t.Errorf("expected unknown member to be allowed with reason %s, got allowed=%v reason=%s", ReasonUnknownMember, decision.Allowed, decision.Reason)
+	}
+}
+
+func TestEngine_Decide_StoreError(t *testing.T) {
+	store := &mockStore{err: errors.New("database locked")}
+
+	engine, err := NewEngine(DefaultConfig(), store, nil, newTestLogger())
+	if err != nil {
+		t.Fatalf("NewEngine() error = %v", err)
+	}
+
+	decision := engine.Decide(context.Background(), types.RawHardwareEvent{ExternalUserID: "fp_active", EventType: types.EventTypeEntry})
+	if decision.Allowed {
+		t.Errorf("expected access to be denied on store error")
+	}
+	if decision.Reason != ReasonDecisionError {
+		t.Errorf("expected reason %s, got %s", ReasonDecisionError, decision.Reason)
+	}
+}
+
+func TestEngine_HandleEvent(t *testing.T) {
+	now := time.Date(2025, 6, 4, 8, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		unlockOnExit bool
+		event        types.RawHardwareEvent
+		unlockErr    error
+		wantCalls    int
+		wantUnlocked bool
+	}{
+		{
+			name:         "granted entry unlocks",
+			event:        types.RawHardwareEvent{ExternalUserID: "fp_active", EventType: types.EventTypeEntry, Timestamp: now},
+			wantCalls:    1,
+			wantUnlocked: true,
+		},
+		{
+			name:         "denied entry does not unlock",
+			event:        types.RawHardwareEvent{ExternalUserID: "fp_suspended", EventType: types.EventTypeEntry, Timestamp: now},
+			wantCalls:    0,
+			wantUnlocked: false,
+		},
+		{
+			name:         "exit unlocks when configured",
+			unlockOnExit: true,
+			event:        types.RawHardwareEvent{ExternalUserID: "fp_active", EventType: types.EventTypeExit, Timestamp: now},
+			wantCalls:    1,
+			wantUnlocked: true,
+		},
+		{
+			name:         "exit does not unlock when disabled",
+			unlockOnExit: false,
+			event:        types.RawHardwareEvent{ExternalUserID: "fp_active", EventType: types.EventTypeExit, Timestamp: now},
+			wantCalls:    0,
+			wantUnlocked: false,
+		},
+		{
+			name:         "unlock failure keeps decision",
+			event:        types.RawHardwareEvent{ExternalUserID: "fp_active", EventType: types.EventTypeEntry, Timestamp: now},
+			unlockErr:    errors.New("no active adapters"),
+			wantCalls:    1,
+			wantUnlocked: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			config.UnlockOnExit = tt.unlockOnExit
+			config.UnlockDurationMs = 5000
+
+			unlocker := &mockUnlocker{err: tt.unlockErr}
+			engine, err := NewEngine(config, newTestStore(), unlocker, newTestLogger())
+			if err != nil {
+				t.Fatalf("NewEngine() error = %v", err)
+			}
+			pinClock(engine)
+
+			decision := engine.HandleEvent(context.Background(), tt.event)
+
+			if unlocker.calls != tt.wantCalls {
+				t.Errorf("expected %d unlock calls, got %d", tt.wantCalls, unlocker.calls)
+			}
+			if tt.wantCalls > 0 && unlocker.durationMs != 5000 {
+				t.Errorf("expected unlock duration 5000, got %d", unlocker.durationMs)
+			}
+			if decision.Unlocked != tt.wantUnlocked {
+				t.Errorf("expected unlocked = %v, got %v", tt.wantUnlocked, decision.Unlocked)
+			}
+		})
+	}
+}
+
+// mockDoorUnlocker records unlocks of specific doors
+type mockDoorUnlocker struct {
+	mockUnlocker
+	doorIDs []string
+}
+
+func (m *mockDoorUnlocker) UnlockDoorByID(ctx context.Context, doorID string, durationMs int) error {
+	m.doorIDs = append(m.doorIDs, doorID)
+	m.durationMs = durationMs
+	return m.err
+}
+
+func TestEngine_HandleEventUnlocksEventDoor(t *testing.T) {
+	now := time.Date(2025, 6, 4, 8, 0, 0, 0, time.UTC)
+	config := DefaultConfig()
+	config.UnlockDurationMs = 5000
+
+	unlocker := &mockDoorUnlocker{}
+	engine, err := NewEngine(config, newTestStore(), unlocker, newTestLogger())
+	if err != nil {
+		t.Fatalf("NewEngine() error = %v", err)
+	}
+	pinClock(engine)
+
+	decision := engine.HandleEvent(context.Background(), types.RawHardwareEvent{ExternalUserID: "fp_active", EventType: types.EventTypeEntry, Timestamp: now, DoorID: "back"})
+	if !decision.Unlocked || len(unlocker.doorIDs) != 1 || unlocker.doorIDs[0] != "back" {
+		t.Errorf("expected door 'back' to be unlocked, got %v (unlocked = %v)", unlocker.doorIDs, decision.Unlocked)
+	}
+	if unlocker.durationMs != 0 {
+		t.Errorf("expected the door's own duration to apply, got %d", unlocker.durationMs)
+	}
+
+	// Events without a door fall back to the default door
+	engine.HandleEvent(context.Background(), types.RawHardwareEvent{ExternalUserID: "fp_active", EventType: types.EventTypeEntry, Timestamp: now})
+	if unlocker.calls != 1 || unlocker.durationMs != 5000 {
+		t.Errorf("expected one default unlock of 5000ms, got %d calls (%dms)", unlocker.calls, unlocker.durationMs)
+	}
+}
+
+type lockdownUnlocker struct {
+	mockUnlocker
+	lockdown bool
+}
+
+func (m *lockdownUnlocker) InLockdown() bool { return m.lockdown }
+
+func TestEngine_HandleEventDuringLockdown(t *testing.T) {
+	now := time.Date(2025, 6, 4, 8, 0, 0, 0, time.UTC)
+	unlocker := &lockdownUnlocker{lockdown: true}
+	engine, err := NewEngine(DefaultConfig(), newTestStore(), unlocker, newTestLogger())
+	if err != nil {
+		t.Fatalf("NewEngine() error = %v", err)
+	}
+	pinClock(engine)
+
+	decision := engine.HandleEvent(context.Background(), types.RawHardwareEvent{ExternalUserID: "fp_active", EventType: types.EventTypeEntry, Timestamp: now})
+	if decision.Allowed || decision.Reason != ReasonLockdown || unlocker.calls != 0 {
+		t.Errorf("expected lockdown denial without unlock, got %+v (%d unlocks)", decision, unlocker.calls)
+	}
+
+	unlocker.lockdown = false
+	decision = engine.HandleEvent(context.Background(), types.RawHardwareEvent{ExternalUserID: "fp_active", EventType: types.EventTypeEntry, Timestamp: now})
+	if !decision.Allowed || unlocker.calls != 1 {
+		t.Errorf("expected access after the lockdown ends, got %+v", decision)
+	}
+}
+
+func TestEngine_HandleEventIgnoresStaleEvents(t *testing.T) {
+	config := DefaultConfig()
+	config.AntiPassback = AntiPassbackConfig{Mode: AntiPassbackHard}
+
+	store := newAntiPassbackStore()
+	unlocker := &mockUnlocker{}
+	engine, err := NewEngine(config, store, unlocker, newTestLogger())
+	if err != nil {
+		t.Fatalf("NewEngine() error = %v", err)
+	}
+
+	// Replayed from the device log well after the member walked through
+	event := types.RawHardwareEvent{ExternalUserID: "fp_active", EventType: types.EventTypeEntry, Timestamp: time.Now().Add(-time.Minute)}
+	decision := engine.HandleEvent(context.Background(), event)
+	if !decision.Allowed || !decision.Stale || decision.Unlocked || unlocker.calls != 0 {
+		t.Errorf("expected a granted stale event without unlock, got %+v (%d unlocks)", decision, unlocker.calls)
+	}
+	if len(store.states) != 0 {
+		t.Errorf("expected no anti-passback state for a stale event, got %v", store.states)
+	}
+
+	event.Timestamp = time.Now()
+	if decision = engine.HandleEvent(context.Background(), event); decision.Stale || !decision.Unlocked {
+		t.Errorf("expected a live event to unlock, got %+v", decision)
+	}
+}
+
+func TestEngine_DecideEventWithoutTimestamp(t *testing.T) {
+	engine, err := NewEngine(DefaultConfig(), newTestStore(), nil, newTestLogger())
+	if err != nil {
+		t.Fatalf("NewEngine() error = %v", err)
+	}
+	pinClock(engine)
+
+	// Events the hardware did not stamp are evaluated at the engine's clock
+	decision := engine.Decide(context.Background(), types.RawHardwareEvent{ExternalUserID: "fp_active", EventType: types.EventTypeEntry})
+	if !decision.EvaluatedAt.Equal(engine.now()) {
+		t.Errorf("expected evaluation at %v, got %v", engine.now(), decision.EvaluatedAt)
+	}
+	if !decision.Allowed || decision.Stale {
+		t.Errorf("expected an unstamped event to be granted and not stale, got %+v", decision)
+	}
+}
+
+// syncStateStore is a mockStore that records the last entitlement sync
+type syncStateStore struct {
+	*mockStore
+	lastSync string
+}
+
+func (s *syncStateStore) GetConfig(key string) (string, error) {
+	if key != LastSyncConfigKey || s.lastSync == "" {
+		return "", errors.New("not found")
+	}
+	return s.lastSync, nil
+}
+
+func TestEngine_DecideBeforeFirstSync(t *testing.T) {
+	store := &syncStateStore{mockStore: newTestStore()}
+	unlocker := &mockUnlocker{}
+	engine, err := NewEngine(DefaultConfig(), store, unlocker, newTestLogger())
+	if err != nil {
+		t.Fatalf("NewEngine() error = %v", err)
+	}
+
+	event := types.RawHardwareEvent{ExternalUserID: "fp_active", EventType: types.EventTypeEntry, Timestamp: time.Now()}
+	decision := engine.HandleEvent(context.Background(), event)
+	if decision.Allowed || decision.Reason != ReasonNotSynced || unlocker.calls != 0 {
+		t.Errorf("expected no verdict before the first sync, got %+v (%d unlocks)", decision, unlocker.calls)
+	}
+
+	store.lastSync = time.Now().UTC().Format(time.RFC3339)
+	if decision = engine.HandleEvent(context.Background(), event); !decision.Allowed || unlocker.calls != 1 {
+		t.Errorf("expected access once the cache is synced, got %+v", decision)
+	}
+}
+
+func TestEngine_GetStats(t *testing.T) {
+	engine, err := NewEngine(DefaultConfig(), newTestStore(), &mockUnlocker{err: errors.New("offline")}, newTestLogger())
+	if err != nil {
+		t.Fatalf("NewEngine() error = %v", err)
+	}
+	pinClock(engine)
+
+	now := time.Date(2025, 6, 4, 8, 0, 0, 0, time.UTC)
+	engine.HandleEvent(context.Background(), types.RawHardwareEvent{ExternalUserID: "fp_active", EventType: types.EventTypeEntry, Timestamp: now})
+	engine.HandleEvent(context.Background(), types.RawHardwareEvent{ExternalUserID: "fp_suspended", EventType: types.EventTypeEntry, Timestamp: now})
+	engine.HandleEvent(context.Background(), types.RawHardwareEvent{ExternalUserID: "fp_stranger", EventType: types.EventTypeEntry, Timestamp: now})
+
+	stats := engine.GetStats()
+	if stats.TotalAllowed != 1 {
+		t.Errorf("expected 1 allowed, got %d", stats.TotalAllowed)
+	}
+	if stats.TotalDenied != 2 {
+		t.Errorf("expected 2 denied, got %d", stats.TotalDenied)
+	}
+	if stats.UnlockFailures != 1 {
+		t.Errorf("expected 1 unlock failure, got %d", stats.UnlockFailures)
+	}
+	if stats.DeniedByReason[ReasonMembershipInactive] != 1 || stats.DeniedByReason[ReasonUnknownMember] != 1 {
+		t.Errorf("unexpected denial breakdown: %v", stats.DeniedByReason)
+	}
+}
+
+func TestNewEngine_InvalidTimezone(t *testing.T) {
+	config := DefaultConfig()
+	config.Timezone = "Mars/Olympus_Mons"
+
+	if _, err := NewEngine(config, newTestStore(), nil, newTestLogger()); err == nil {
+		t.Errorf("expected error for invalid timezone")
+	}
+}
+
+func TestWithinAllowedHours(t *testing.T) {
+	windows, err := ParseAllowedHours(`[
+		{"days":[1,2,3,4,5],"start":"06:00","end":"22:00"},
+		{"days":[6],"start":"22:00","end":"02:00"}
+	]`)
+	if err != nil {
+		t.Fatalf("ParseAllowedHours() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		at   time.Time
+		want bool
+	}{
+		{"weekday inside", time.Date(2025, 6, 4, 12, 0, 0, 0, time.UTC), true},
+		{"weekday before opening", time.Date(2025, 6, 4, 5, 59, 0, 0, time.UTC), false},
+		{"weekday at closing", time.Date(2025, 6, 4, 22, 0, 0, 0, time.UTC), false},
+		{"saturday late window", time.Date(2025, 6, 7, 23, 0, 0, 0, time.UTC), true},
+		{"sunday early part of saturday window", time.Date(2025, 6, 8, 1, 30, 0, 0, time.UTC), true},
+		{"sunday after window", time.Date(2025, 6, 8, 2, 0, 0, 0, time.UTC), false},
+		{"saturday daytime", time.Date(2025, 6, 7, 12, 0, 0, 0, time.UTC), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := WithinAllowedHours(windows, tt.at); got != tt.want {
+				t.Errorf("WithinAllowedHours() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if !WithinAllowedHours(nil, time.Now()) {
+		t.Errorf("expected empty window list to allow any time")
+	}
+}
+
+func TestParseAllowedHours_Invalid(t *testing.T) {
+	invalid := []string{
+		`not json`,
+		`[{"days":[],"start":"06:00","end":"22:00"}]`,
+		`[{"days":[7],"start":"06:00","end":"22:00"}]`,
+		`[{"days":[1],"start":"6am","end":"22:00"}]`,
+		`[{"days":[1],"start":"06:00","end":"25:00"}]`,
+	}
+
+	for _, value := range invalid {
+		if _, err := ParseAllowedHours(value); err == nil {
+			t.Errorf("expected error for %s", value)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package access
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// AccessWindow is a weekly window during which a member may enter
+type AccessWindow struct {
+	Days  []int  `json:"days"`  // 0 = Sunday ... 6 = Saturday
+	Start string `json:"start"` // "HH:MM"
+	End   string `json:"end"`   // "HH:MM", may be earlier than Start for windows crossing midnight
+}
+
+// ParseAllowedHours parses the JSON encoded access windows stored with an entitlement
+// An empty string means the member may enter at any time
+func ParseAllowedHours(allowedHours string) ([]AccessWindow, error) {
+	if allowedHours == "" {
+		return nil, nil
+	}
+
+	var windows []AccessWindow
+	if err := json.Unmarshal([]byte(allowedHours), &windows); err != nil {
+		return nil, fmt.Errorf("failed to parse allowed hours: %w", err)
+	}
+
+	for i, window := range windows {
+		if err := window.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid access window %d: %w", i, err)
+		}
+	}
+
+	return windows, nil
+}
+
+// WithinAllowedHours reports whether t falls inside any of the access windows
+// A nil or empty window list allows any time
+func WithinAllowedHours(windows []AccessWindow, t time.Time) bool {
+	if len(windows) == 0 {
+		return true
+	}
+
+	minute := t.Hour()*60 + t.Minute()
+	weekday := int(t.Weekday())
+	previousDay := (weekday + 6) % 7
+
+	for _, window := range windows {
+		start, _ := parseClock(window.Start)
+		end, _ := parseClock(window.End)
+
+		if start <= end {
+			if window.hasDay(weekday) && minute >= start && minute < end {
+				return true
+			}
+			continue
+		}
+
+		// Window crosses midnight: the late part belongs to the listed day,
+		// the early part to the day after it
+		if window.hasDay(weekday) && minute >= start {
+			return true
+		}
+		if window.hasDay(previousDay) && minute < end {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Validate checks that the access window is well formed
+func (w AccessWindow) Validate() error {
+	if len(w.Days) == 0 {
+		return fmt.Errorf("days cannot be empty")
+	}
+	for _, day := range w.Days {
+		if day < 0 || day > 6 {
+			return fmt.Errorf("day must be between 0 and 6, got %d", day)
+		}
+	}
+	if _, err := parseClock(w.Start); err != nil {
+		return fmt.Errorf("invalid start: %w", err)
+	}
+	if _, err := parseClock(w.End); err != nil {
+		return fmt.Errorf("invalid end: %w", err)
+	}
+	return nil
+}
+
+// hasDay reports whether the window applies to the given weekday
+func (w AccessWindow) hasDay(day int) bool {
+	for _, d := range w.Days {
+		if d == day {
+			return true
+		}
+	}
+	return false
+}
+
+// parseClock converts an "HH:MM" string to minutes since midnight
+// "24:00" is accepted as the end of the day
+func parseClock(value string) (int, error) {
+	var hour, minute int
+	if _, err := fmt.Sscanf(value, "%d:%d", &hour, &minute); err != nil {
+		return 0, fmt.Errorf("expected HH:MM, got %q", value)
+	}
+	if hour == 24 && minute == 0 {
+		return 24 * 60, nil
+	}
+	if hour < 0 || hour > 23 || minute < 0 || minute > 59 {
+		return 0, fmt.Errorf("time out of range: %q", value)
+	}
+	return hour*60 + minute, nil
+}
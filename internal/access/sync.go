@@ -0,0 +1,179 @@
+package access
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gym-door-bridge/internal/client"
+	"gym-door-bridge/internal/database"
+	"gym-door-bridge/internal/logging"
+)
+
+// LastSyncConfigKey is the device_config key holding the last successful entitlement sync time
+const LastSyncConfigKey = "entitlements_last_sync"
+
+// EntitlementSource defines the cloud client method needed by the syncer
+type EntitlementSource interface {
+	GetMemberEntitlements(ctx context.Context) (*client.MemberEntitlementsResponse, error)
+}
+
+// SyncStore defines the database methods needed by the syncer
+type SyncStore interface {
+	ReplaceMemberEntitlements(entitlements []database.MemberEntitlement) error
+	SetConfig(key, value string) error
+}
+
+// SyncStats contains statistics about entitlement synchronization
+type SyncStats struct {
+	TotalSyncs    int64  `json:"totalSyncs"`
+	TotalFailures int64  `json:"totalFailures"`
+	LastSyncAt    int64  `json:"lastSyncAt"` // Unix timestamp
+	LastSyncCount int    `json:"lastSyncCount"`
+	LastError     string `json:"lastError,omitempty"`
+}
+
+// Syncer periodically refreshes the local entitlement cache from the platform
+type Syncer struct {
+	source   EntitlementSource
+	store    SyncStore
+	interval time.Duration
+	logger   *logrus.Entry
+	stats    SyncStats
+	mutex    sync.RWMutex
+}
+
+// NewSyncer creates a new entitlement syncer
+func NewSyncer(source EntitlementSource, store SyncStore, interval time.Duration, logger *logrus.Logger) *Syncer {
+	if interval <= 0 {
+		interval = 5 * time.Minute
+	}
+
+	return &Syncer{
+		source:   source,
+		store:    store,
+		interval: interval,
+		logger:   logging.NewServiceLogger(logger, "entitlement-sync"),
+	}
+}
+
+// Start runs an initial sync and then syncs periodically until the context is cancelled
+func (s *Syncer) Start(ctx context.Context) {
+	s.logger.WithField("interval", s.interval).Info("Starting entitlement sync")
+
+	if err := s.Sync(ctx); err != nil {
+		s.logger.WithError(err).Warn("Initial entitlement sync failed, using cached entitlements")
+	}
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.logger.Info("Entitlement sync stopped")
+			return
+		case <-ticker.C:
+			if err := s.Sync(ctx); err != nil {
+				s.logger.WithError(err).Warn("Entitlement sync failed, using cached entitlements")
+			}
+		}
+	}
+}
+
+// Sync fetches a full entitlement snapshot and replaces the local cache
+// The existing cache is left untouched if the fetch or conversion fails
+func (s *Syncer) Sync(ctx context.Context) error {
+	resp, err := s.source.GetMemberEntitlements(ctx)
+	if err != nil {
+		s.recordFailure(err)
+		return fmt.Errorf("failed to fetch member entitlements: %w", err)
+	}
+
+	entitlements, err := convertEntitlements(resp.Entitlements)
+	if err != nil {
+		s.recordFailure(err)
+		return fmt.Errorf("failed to convert member entitlements: %w", err)
+	}
+
+	if err := s.store.ReplaceMemberEntitlements(entitlements); err != nil {
+		s.recordFailure(err)
+		return fmt.Errorf("failed to store member entitlements: %w", err)
+	}
+
+	now := time.Now()
+	if err := s.store.SetConfig(LastSyncConfigKey, now.UTC().Format(time.RFC3339)); err != nil {
+		s.logger.WithError(err).Warn("Failed to record entitlement sync time")
+	}
+
+	s.mutex.Lock()
+	s.stats.TotalSyncs++
+	s.stats.LastSyncAt = now.Unix()
+	s.stats.LastSyncCount = len(entitlements)
+	s.stats.LastError = ""
+	s.mutex.Unlock()
+
+	s.logger.WithField("count", len(entitlements)).Info("Member entitlements synchronized")
+	return nil
+}
+
+// GetStats returns entitlement synchronization statistics
+func (s *Syncer) GetStats() SyncStats {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return s.stats
+}
+
+// recordFailure updates failure statistics
+func (s *Syncer) recordFailure(err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.stats.TotalFailures++
+	s.stats.LastError = err.Error()
+}
+
+// convertEntitlements converts cloud entitlements to their cached database form
+func convertEntitlements(entitlements []client.MemberEntitlement) ([]database.MemberEntitlement, error) {
+	converted := make([]database.MemberEntitlement, 0, len(entitlements))
+
+	for _, entitlement := range entitlements {
+		cached := database.MemberEntitlement{
+			InternalUserID: entitlement.InternalUserID,
+			Status:         entitlement.Status,
+		}
+
+		if entitlement.MembershipExpiresAt != "" {
+			expiresAt, err := time.Parse(time.RFC3339, entitlement.MembershipExpiresAt)
+			if err != nil {
+				return nil, fmt.Errorf("invalid membership expiry for %s: %w", entitlement.InternalUserID, err)
+			}
+			expiresAt = expiresAt.UTC()
+			cached.MembershipExpiresAt = &expiresAt
+		}
+
+		if len(entitlement.AllowedHours) > 0 {
+			windows := make([]AccessWindow, 0, len(entitlement.AllowedHours))
+			for _, window := range entitlement.AllowedHours {
+				windows = append(windows, AccessWindow{Days: window.Days, Start: window.Start, End: window.End})
+			}
+
+			allowedHours, err := json.Marshal(windows)
+			if err != nil {
+				return nil, fmt.Errorf("failed to encode allowed hours for %s: %w", entitlement.InternalUserID, err)
+			}
+			if _, err := ParseAllowedHours(string(allowedHours)); err != nil {
+				return nil, fmt.Errorf("invalid allowed hours for %s: %w", entitlement.InternalUserID, err)
+			}
+			cached.AllowedHours = string(allowedHours)
+		}
+
+		converted = append(converted, cached)
+	}
+
+	return converted, nil
+}
@@ -0,0 +1,139 @@
+package access
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"gym-door-bridge/internal/client"
+	"gym-door-bridge/internal/database"
+)
+
+// mockSource returns a fixed entitlement snapshot
+type mockSource struct {
+	resp *client.MemberEntitlementsResponse
+	err  error
+}
+
+func (m *mockSource) GetMemberEntitlements(ctx context.Context) (*client.MemberEntitlementsResponse, error) {
+	return m.resp, m.err
+}
+
+// mockSyncStore records replaced entitlements and config writes
+type mockSyncStore struct {
+	entitlements []database.MemberEntitlement
+	replaced     bool
+	config       map[string]string
+}
+
+func (m *mockSyncStore) ReplaceMemberEntitlements(entitlements []database.MemberEntitlement) error {
+	m.entitlements = entitlements
+	m.replaced = true
+	return nil
+}
+
+func (m *mockSyncStore) SetConfig(key, value string) error {
+	if m.config == nil {
+		m.config = make(map[string]string)
+	}
+	m.config[key] = value
+	return nil
+}
+
+func TestSyncer_Sync(t *testing.T) {
+	source := &mockSource{
+		resp: &client.MemberEntitlementsResponse{
+			Entitlements: []client.MemberEntitlement{
+				{
+					InternalUserID:      "user_1",
+					Status:              "active",
+					MembershipExpiresAt: "2030-01-01T00:00:00Z",
+					AllowedHours: []client.AccessWindow{
+						{Days: []int{1, 2, 3}, Start: "06:00", End: "22:00"},
+					},
+				},
+				{
+					InternalUserID: "user_2",
+					Status:         "suspended",
+				},
+			},
+		},
+	}
+	store := &mockSyncStore{}
+
+	syncer := NewSyncer(source, store, 0, newTestLogger())
+	if err := syncer.Sync(context.Background()); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+
+	if len(store.entitlements) != 2 {
+		t.Fatalf("expected 2 entitlements, got %d", len(store.entitlements))
+	}
+
+	first := store.entitlements[0]
+	if first.MembershipExpiresAt == nil || first.MembershipExpiresAt.Year() != 2030 {
+		t.Errorf("expected expiry in 2030, got %v", first.MembershipExpiresAt)
+	}
+	windows, err := ParseAllowedHours(first.AllowedHours)
+	if err != nil || len(windows) != 1 {
+		t.Errorf("expected one stored access window, got %v (err %v)", windows, err)
+	}
+
+	if store.entitlements[1].AllowedHours != "" {
+		t.Errorf("expected no allowed hours for member without windows")
+	}
+
+	if store.config[LastSyncConfigKey] == "" {
+		t.Errorf("expected last sync time to be recorded")
+	}
+
+	stats := syncer.GetStats()
+	if stats.TotalSyncs != 1 || stats.LastSyncCount != 2 {
+		t.Errorf("unexpected sync stats: %+v", stats)
+	}
+}
+
+func TestSyncer_Sync_KeepsCacheOnFailure(t *testing.T) {
+	tests := []struct {
+		name   string
+		source *mockSource
+	}{
+		{
+			name:   "platform unreachable",
+			source: &mockSource{err: errors.New("connection refused")},
+		},
+		{
+			name: "invalid expiry",
+			source: &mockSource{resp: &client.MemberEntitlementsResponse{
+				Entitlements: []client.MemberEntitlement{
+					{InternalUserID: "user_1", Status: "active", MembershipExpiresAt: "tomorrow"},
+				},
+			}},
+		},
+		{
+			name: "invalid access window",
+			source: &mockSource{resp: &client.MemberEntitlementsResponse{
+				Entitlements: []client.MemberEntitlement{
+					{InternalUserID: "user_1", Status: "active", AllowedHours: []client.AccessWindow{{Days: []int{9}, Start: "06:00", End: "22:00"}}},
+				},
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &mockSyncStore{}
+			syncer := NewSyncer(tt.source, store, 0, newTestLogger())
+
+			if err := syncer.Sync(context.Background()); err == nil {
+				t.Fatalf("expected Sync() to fail")
+			}
+			if store.replaced {
+				t.Errorf("expected cache to be left untouched")
+			}
+			if stats := syncer.GetStats(); stats.TotalFailures != 1 || stats.LastError == "" {
+				t.Errorf("unexpected failure stats: %+v", stats)
+			}
+		})
+	}
+}
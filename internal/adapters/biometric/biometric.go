@@ -1,38 +1,55 @@
 package biometric
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
-	"net/http"
+	"log/slog"
+	"sync"
 	"time"
 
 	"gym-door-bridge/internal/types"
-	"github.com/sirupsen/logrus"
 )
 
-// BiometricAdapter handles biometric device integration (ESSL, ZKTeco, etc.)
+// maxSeenRecords bounds the set of reported records kept for de-duplication
+const maxSeenRecords = 10000
+
+// clockSyncInterval is how often the terminal clock is set from the bridge's.
+// Events are stamped by the terminal, so a drifting clock would make the access
+// engine treat fresh punches as stale or replayed ones as fresh.
+const clockSyncInterval = time.Hour
+
+// BiometricAdapter implements the HardwareAdapter interface for biometric terminals (ESSL, ZKTeco, etc.)
+// Attendance records are reported through the registered event callback so they follow the
+// normal processor and queue path like events from any other adapter
 type BiometricAdapter struct {
-	name           string
-	logger         *logrus.Logger
-	eventCallback  types.EventCallback
-	config         *Config
-	device         BiometricDevice
-	platformClient *PlatformClient
-	isRunning      bool
-	stopChan       chan struct{}
+	name          string
+	config        types.AdapterConfig
+	bioConfig     *Config
+	status        types.AdapterStatus
+	eventCallback types.EventCallback
+	device        BiometricDevice
+	isListening   bool
+	cancel        context.CancelFunc
+	done          chan struct{}
+	mutex         sync.RWMutex
+	deviceMutex   sync.Mutex // Serializes access to the device connection
+	logger        *slog.Logger
+
+	seenRecords    map[string]time.Time // Records already reported, keyed by user and timestamp
+	realtimeActive bool                 // Whether events are currently pushed by the device
+	lastAlarm      *RealtimeEvent
+	lastClockSync  time.Time // When the device clock was last set, guarded by deviceMutex
 }
 
 // Config holds biometric device configuration
 type Config struct {
-	DeviceType     string            `json:"device_type"`     // essl, zkteco, realtime, anviz
-	Connection     string            `json:"connection"`      // tcp, serial, usb
-	DeviceConfig   map[string]string `json:"device_config"`   // device-specific config
-	SyncInterval   int               `json:"sync_interval"`   // seconds between polling
-	PlatformURL    string            `json:"platform_url"`    // platform API URL
-	DeviceID       string            `json:"device_id"`       // bridge device ID
-	DeviceKey      string            `json:"device_key"`      // bridge device key
+	DeviceType   string            `json:"device_type"`   // essl, zkteco, realtime, simulator
+	Connection   string            `json:"connection"`    // tcp, serial, usb
+	DeviceConfig map[string]string `json:"device_config"` // device-specific config
+	SyncInterval int               `json:"sync_interval"` // seconds between polling
+	Realtime     bool              `json:"realtime"`      // use pushed events when the device supports them
+	Keepalive    int               `json:"keepalive"`     // seconds of idle time before a keepalive is sent
 }
 
 // BiometricDevice interface for different biometric hardware
@@ -41,167 +58,337 @@ type BiometricDevice interface {
 	Disconnect() error
 	IsConnected() bool
 	GetStatus() string
-	
+
 	// User management
 	EnrollUser(platformUserID string, deviceUserID int, name string) error
 	DeleteUser(deviceUserID int) error
 	GetUsers() ([]DeviceUser, error)
-	
+
 	// Attendance polling
 	GetNewAttendanceRecords() ([]AttendanceRecord, error)
 	ClearAttendanceRecords() error
-	
+
+	// Door control
+	UnlockDoor(duration time.Duration) error
+
 	// Device info
 	GetDeviceInfo() (*DeviceInfo, error)
 	GetDeviceTime() (time.Time, error)
 	SetDeviceTime(t time.Time) error
 }
 
+// UserWriter is implemented by devices that can write a complete user record.
+// It is used to provision members from the platform roster onto the terminal.
+type UserWriter interface {
+	// WriteUser creates the user or replaces the user with the same device user ID
+	WriteUser(user DeviceUser) error
+}
+
+// TemplateTransfer is implemented by devices that can read and write enrolled
+// fingerprint templates, so members enrolled on one terminal can use the others
+type TemplateTransfer interface {
+	// GetTemplates reads every fingerprint template stored on the device
+	GetTemplates() ([]FingerprintTemplate, error)
+	// WriteTemplate stores a template for a user that already exists on the device
+	WriteTemplate(template FingerprintTemplate) error
+}
+
+// AttendanceLogDrainer is implemented by devices that can read and clear the
+// attendance log as one step, so no punch recorded in between is lost
+type AttendanceLogDrainer interface {
+	// DrainAttendanceRecords returns the records stored since the last call and clears the log
+	DrainAttendanceRecords() ([]AttendanceRecord, error)
+}
+
+// RealtimeEventSource is implemented by devices that can push events over an open session.
+// When available the adapter holds the connection open and only polls to fill gaps after a reconnect
+type RealtimeEventSource interface {
+	// RegisterRealtimeEvents subscribes the current session to event pushes
+	RegisterRealtimeEvents() error
+	// ReadRealtimeEvent waits up to timeout for a pushed event, returning nil if none arrived
+	ReadRealtimeEvent(timeout time.Duration) (*RealtimeEvent, error)
+	// Keepalive exercises the session so idle connections are detected and kept open
+	Keepalive() error
+}
+
+// RealtimeEventKind identifies the type of a pushed device event
+type RealtimeEventKind string
+
+// Realtime event kinds
+const (
+	RealtimeAttendance   RealtimeEventKind = "attendance"
+	RealtimeVerifyFailed RealtimeEventKind = "verify_failed"
+	RealtimeDoorAlarm    RealtimeEventKind = "door_alarm"
+)
+
+// RealtimeEvent is an event pushed by the device as it happens
+type RealtimeEvent struct {
+	Kind      RealtimeEventKind `json:"kind"`
+	Record    AttendanceRecord  `json:"record,omitempty"`     // Set for attendance events
+	AlarmCode int               `json:"alarm_code,omitempty"` // Set for door alarms
+	Timestamp time.Time         `json:"timestamp"`
+}
+
 // DeviceUser represents a user stored on the biometric device
 type DeviceUser struct {
 	DeviceUserID   int    `json:"device_user_id"`
 	PlatformUserID string `json:"platform_user_id"`
 	Name           string `json:"name"`
-	Privilege      int    `json:"privilege"`      // User privilege level
-	Password       string `json:"password"`       // Optional password
-	CardNumber     string `json:"card_number"`    // Optional card number
+	Privilege      int    `json:"privilege"`   // User privilege level
+	Password       string `json:"password"`    // Optional password
+	CardNumber     string `json:"card_number"` // Optional card number
+	Disabled       bool   `json:"disabled"`    // The terminal refuses to verify the user
+}
+
+// FingerprintTemplate is an enrolled fingerprint as stored on the terminal. The
+// template data is biometric information and is never serialized.
+type FingerprintTemplate struct {
+	DeviceUserID int    `json:"device_user_id"`
+	FingerIndex  int    `json:"finger_index"` // 0-9
+	Flag         int    `json:"flag"`         // 1 = valid, 3 = duress finger
+	Data         []byte `json:"-"`
+}
+
+// User privilege levels common to ZKTeco-based terminals
+const (
+	PrivilegeUser  = 0
+	PrivilegeAdmin = 14
+)
+
+// ExternalUserID returns the external user ID reported in events for a device user
+func ExternalUserID(deviceUserID int) string {
+	return fmt.Sprintf("device_%d", deviceUserID)
 }
 
 // AttendanceRecord represents an attendance record from the device
 type AttendanceRecord struct {
 	DeviceUserID int       `json:"device_user_id"`
 	Timestamp    time.Time `json:"timestamp"`
-	Status       int       `json:"status"`        // 0=check-in, 1=check-out, etc.
-	VerifyMode   int       `json:"verify_mode"`   // 1=fingerprint, 2=password, 3=card
-	WorkCode     int       `json:"work_code"`     // Optional work code
+	Status       int       `json:"status"`      // 0=check-in, 1=check-out, etc.
+	VerifyMode   int       `json:"verify_mode"` // 1=fingerprint, 2=password, 3=card
+	WorkCode     int       `json:"work_code"`   // Optional work code
 }
 
 // DeviceInfo contains device information
 type DeviceInfo struct {
-	SerialNumber   string `json:"serial_number"`
-	DeviceModel    string `json:"device_model"`
-	FirmwareVer    string `json:"firmware_version"`
-	UserCount      int    `json:"user_count"`
-	AttendanceCount int   `json:"attendance_count"`
-	FingerCapacity int    `json:"finger_capacity"`
-}
-
-// PlatformClient handles API calls to the platform
-type PlatformClient struct {
-	baseURL   string
-	deviceID  string
-	deviceKey string
-	client    *http.Client
-	logger    *logrus.Logger
-}
-
-// NewBiometricAdapter creates a new biometric adapter
-func NewBiometricAdapter(name string, config map[string]interface{}, logger *logrus.Logger) (*BiometricAdapter, error) {
-	// Parse configuration
-	configBytes, err := json.Marshal(config)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal config: %w", err)
+	SerialNumber    string `json:"serial_number"`
+	DeviceModel     string `json:"device_model"`
+	FirmwareVer     string `json:"firmware_version"`
+	UserCount       int    `json:"user_count"`
+	AttendanceCount int    `json:"attendance_count"`
+	FingerCapacity  int    `json:"finger_capacity"`
+}
+
+// NewBiometricAdapter creates a new biometric adapter instance
+func NewBiometricAdapter(logger *slog.Logger) *BiometricAdapter {
+	return &BiometricAdapter{
+		name:   "biometric",
+		logger: logger,
+		status: types.AdapterStatus{
+			Name:      "biometric",
+			Status:    types.StatusDisabled,
+			UpdatedAt: time.Now(),
+		},
 	}
+}
 
-	var bioConfig Config
-	if err := json.Unmarshal(configBytes, &bioConfig); err != nil {
-		return nil, fmt.Errorf("failed to parse biometric config: %w", err)
+// Name returns the adapter name
+func (b *BiometricAdapter) Name() string {
+	return b.name
+}
+
+// Initialize sets up the biometric adapter with configuration
+func (b *BiometricAdapter) Initialize(ctx context.Context, config types.AdapterConfig) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.config = config
+	if config.Name != "" {
+		// Adapters of the same type are told apart by their configured name
+		b.name = config.Name
+		b.status.Name = config.Name
 	}
+	b.status.Status = types.StatusInitializing
+	b.status.UpdatedAt = time.Now()
 
-	// Create device based on type
-	var device BiometricDevice
-	switch bioConfig.DeviceType {
-	case "essl":
-		device = NewESSLDevice(bioConfig.DeviceConfig, logger)
-	case "zkteco":
-		device = NewZKTecoDevice(bioConfig.DeviceConfig, logger)
-	case "realtime":
-		device = NewRealtimeDevice(bioConfig.DeviceConfig, logger)
-	case "simulator":
-		device = NewSimulatorDevice(bioConfig.DeviceConfig, logger)
-	default:
-		return nil, fmt.Errorf("unsupported device type: %s", bioConfig.DeviceType)
+	bioConfig, err := parseConfig(config.Settings)
+	if err != nil {
+		b.setError(err.Error())
+		return fmt.Errorf("failed to parse biometric config: %w", err)
 	}
 
-	// Create platform client
-	platformClient := &PlatformClient{
-		baseURL:   bioConfig.PlatformURL,
-		deviceID:  bioConfig.DeviceID,
-		deviceKey: bioConfig.DeviceKey,
-		client:    &http.Client{Timeout: 30 * time.Second},
-		logger:    logger,
+	device, err := newDevice(bioConfig, b.logger)
+	if err != nil {
+		b.setError(err.Error())
+		return err
 	}
 
-	return &BiometricAdapter{
-		name:           name,
-		logger:         logger,
-		config:         &bioConfig,
-		device:         device,
-		platformClient: platformClient,
-		stopChan:       make(chan struct{}),
-	}, nil
-}
+	b.bioConfig = bioConfig
+	b.device = device
+	b.status.Status = types.StatusActive
+	b.status.ErrorMessage = ""
+	b.status.UpdatedAt = time.Now()
 
-// Name returns the adapter name
-func (b *BiometricAdapter) Name() string {
-	return b.name
+	b.logger.Info("Biometric adapter initialized",
+		"name", b.name,
+		"deviceType", bioConfig.DeviceType,
+		"connection", bioConfig.Connection,
+		"syncInterval", bioConfig.SyncInterval)
+
+	return nil
 }
 
-// Start begins biometric device operations
-func (b *BiometricAdapter) Start(ctx context.Context, eventCallback types.EventCallback) error {
-	b.eventCallback = eventCallback
-	b.isRunning = true
+// StartListening connects to the device and begins reporting attendance records
+func (b *BiometricAdapter) StartListening(ctx context.Context) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
 
-	// Connect to device
-	if err := b.device.Connect(); err != nil {
-		return fmt.Errorf("failed to connect to device: %w", err)
+	if b.isListening {
+		return fmt.Errorf("biometric adapter is already listening")
 	}
 
-	b.logger.Info("Biometric adapter started")
+	if b.eventCallback == nil {
+		return fmt.Errorf("no event callback registered")
+	}
 
-	// Start attendance polling loop
-	go b.attendancePollingLoop(ctx)
+	if b.device == nil {
+		return fmt.Errorf("biometric adapter is not initialized")
+	}
+
+	listenCtx, cancel := context.WithCancel(ctx)
+	b.cancel = cancel
+	b.done = make(chan struct{})
+	b.isListening = true
 
+	// The listening loop connects on its first pass and reconnects whenever the device drops
+	if source, ok := b.device.(RealtimeEventSource); ok && b.bioConfig.Realtime {
+		go b.realtimeEventLoop(listenCtx, b.done, source)
+	} else {
+		go b.attendancePollingLoop(listenCtx, b.done)
+	}
+
+	b.logger.Info("Biometric adapter started listening", "name", b.name)
 	return nil
 }
 
-// Stop stops the biometric adapter
-func (b *BiometricAdapter) Stop() error {
-	if !b.isRunning {
-		return nil
+// StopListening stops polling and disconnects from the device
+func (b *BiometricAdapter) StopListening(ctx context.Context) error {
+	b.mutex.Lock()
+	if !b.isListening {
+		b.mutex.Unlock()
+		return nil // Already stopped
 	}
 
-	b.isRunning = false
-	close(b.stopChan)
+	b.isListening = false
+	cancel := b.cancel
+	done := b.done
+	b.mutex.Unlock()
+
+	cancel()
+	select {
+	case <-done:
+	case <-ctx.Done():
+	}
 
-	// Disconnect from device
+	b.deviceMutex.Lock()
 	if err := b.device.Disconnect(); err != nil {
-		b.logger.WithError(err).Error("Error disconnecting from device")
+		b.logger.Error("Error disconnecting from biometric device", "error", err)
 	}
+	b.deviceMutex.Unlock()
 
-	b.logger.Info("Biometric adapter stopped")
+	b.mutex.Lock()
+	b.status.UpdatedAt = time.Now()
+	b.mutex.Unlock()
+
+	b.logger.Info("Biometric adapter stopped listening", "name", b.name)
 	return nil
 }
 
-// IsRunning returns whether the adapter is running
-func (b *BiometricAdapter) IsRunning() bool {
-	return b.isRunning
+// UnlockDoor triggers the door relay wired to the biometric terminal
+func (b *BiometricAdapter) UnlockDoor(ctx context.Context, durationMs int) error {
+	b.mutex.RLock()
+	device := b.device
+	bioConfig := b.bioConfig
+	b.mutex.RUnlock()
+
+	if device == nil {
+		return fmt.Errorf("biometric adapter is not initialized")
+	}
+	if !deviceUnlocks(bioConfig.DeviceType) {
+		return fmt.Errorf("door unlock not supported by %s devices", bioConfig.DeviceType)
+	}
+
+	b.deviceMutex.Lock()
+	defer b.deviceMutex.Unlock()
+
+	if !device.IsConnected() {
+		return fmt.Errorf("biometric device is not connected")
+	}
+
+	if err := device.UnlockDoor(time.Duration(durationMs) * time.Millisecond); err != nil {
+		return fmt.Errorf("biometric door unlock failed: %w", err)
+	}
+
+	b.logger.Info("Door unlocked via biometric adapter",
+		"adapter", b.name,
+		"durationMs", durationMs)
+
+	return nil
 }
 
 // GetStatus returns the current adapter status
-func (b *BiometricAdapter) GetStatus() map[string]interface{} {
+func (b *BiometricAdapter) GetStatus() types.AdapterStatus {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+	return b.status
+}
+
+// OnEvent registers a callback for hardware events
+func (b *BiometricAdapter) OnEvent(callback types.EventCallback) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.eventCallback = callback
+}
+
+// IsHealthy returns true if the device was reachable on the last poll
+func (b *BiometricAdapter) IsHealthy() bool {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	return b.device != nil && b.status.Status == types.StatusActive
+}
+
+// GetDeviceStatus returns detailed information about the biometric device
+func (b *BiometricAdapter) GetDeviceStatus() map[string]interface{} {
+	b.mutex.RLock()
+	device := b.device
 	status := map[string]interface{}{
-		"name":         b.name,
-		"running":      b.isRunning,
-		"device_type":  b.config.DeviceType,
-		"connection":   b.config.Connection,
-		"connected":    b.device.IsConnected(),
-		"device_status": b.device.GetStatus(),
+		"name":      b.name,
+		"listening": b.isListening,
+		"realtime":  b.realtimeActive,
+	}
+	if b.lastAlarm != nil {
+		status["last_alarm"] = b.lastAlarm
+	}
+	if b.bioConfig != nil {
+		status["device_type"] = b.bioConfig.DeviceType
+		status["connection"] = b.bioConfig.Connection
 	}
+	b.mutex.RUnlock()
+
+	if device == nil {
+		return status
+	}
+
+	b.deviceMutex.Lock()
+	defer b.deviceMutex.Unlock()
+
+	status["connected"] = device.IsConnected()
+	status["device_status"] = device.GetStatus()
 
 	// Add device info if connected
-	if b.device.IsConnected() {
-		if info, err := b.device.GetDeviceInfo(); err == nil {
+	if device.IsConnected() {
+		if info, err := device.GetDeviceInfo(); err == nil {
 			status["device_info"] = info
 		}
 	}
@@ -211,31 +398,138 @@ func (b *BiometricAdapter) GetStatus() map[string]interface{} {
 
 // EnrollUser enrolls a user on the biometric device
 func (b *BiometricAdapter) EnrollUser(platformUserID string, deviceUserID int, name string) error {
-	b.logger.WithFields(logrus.Fields{
-		"platform_user_id": platformUserID,
-		"device_user_id":   deviceUserID,
-		"name":             name,
-	}).Info("Enrolling user on biometric device")
+	b.mutex.RLock()
+	device := b.device
+	b.mutex.RUnlock()
+
+	if device == nil {
+		return fmt.Errorf("biometric adapter is not initialized")
+	}
 
-	if err := b.device.EnrollUser(platformUserID, deviceUserID, name); err != nil {
+	b.logger.Info("Enrolling user on biometric device",
+		"platformUserId", platformUserID,
+		"deviceUserId", deviceUserID,
+		"name", name)
+
+	b.deviceMutex.Lock()
+	defer b.deviceMutex.Unlock()
+
+	if err := device.EnrollUser(platformUserID, deviceUserID, name); err != nil {
 		return fmt.Errorf("device enrollment failed: %w", err)
 	}
 
-	b.logger.Info("User enrolled successfully on biometric device")
+	b.logger.Info("User enrolled successfully on biometric device", "deviceUserId", deviceUserID)
+	return nil
+}
+
+// GetUsers lists the users stored on the biometric device
+func (b *BiometricAdapter) GetUsers() ([]DeviceUser, error) {
+	var users []DeviceUser
+	err := b.withDevice(func(device BiometricDevice) error {
+		var err error
+		users, err = device.GetUsers()
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get device users: %w", err)
+	}
+	return users, nil
+}
+
+// WriteUser creates or replaces a user record on the biometric device
+func (b *BiometricAdapter) WriteUser(user DeviceUser) error {
+	err := b.withDevice(func(device BiometricDevice) error {
+		writer, ok := device.(UserWriter)
+		if !ok {
+			return fmt.Errorf("%s devices do not support writing user records", b.bioConfig.DeviceType)
+		}
+		return writer.WriteUser(user)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write device user %d: %w", user.DeviceUserID, err)
+	}
 	return nil
 }
 
+// DeleteUser removes a user and their fingerprint templates from the biometric device
+func (b *BiometricAdapter) DeleteUser(deviceUserID int) error {
+	err := b.withDevice(func(device BiometricDevice) error {
+		return device.DeleteUser(deviceUserID)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete device user %d: %w", deviceUserID, err)
+	}
+	return nil
+}
+
+// GetTemplates reads the fingerprint templates stored on the biometric device
+func (b *BiometricAdapter) GetTemplates() ([]FingerprintTemplate, error) {
+	var templates []FingerprintTemplate
+	err := b.withDevice(func(device BiometricDevice) error {
+		transfer, ok := device.(TemplateTransfer)
+		if !ok {
+			return fmt.Errorf("%s devices do not support template transfer", b.bioConfig.DeviceType)
+		}
+		var err error
+		templates, err = transfer.GetTemplates()
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fingerprint templates: %w", err)
+	}
+	return templates, nil
+}
+
+// WriteTemplate stores a fingerprint template for an existing device user
+func (b *BiometricAdapter) WriteTemplate(template FingerprintTemplate) error {
+	err := b.withDevice(func(device BiometricDevice) error {
+		transfer, ok := device.(TemplateTransfer)
+		if !ok {
+			return fmt.Errorf("%s devices do not support template transfer", b.bioConfig.DeviceType)
+		}
+		return transfer.WriteTemplate(template)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write template for device user %d finger %d: %w", template.DeviceUserID, template.FingerIndex, err)
+	}
+	return nil
+}
+
+// withDevice runs fn with exclusive access to the device, connecting first if needed
+func (b *BiometricAdapter) withDevice(fn func(device BiometricDevice) error) error {
+	b.mutex.RLock()
+	device := b.device
+	b.mutex.RUnlock()
+
+	if device == nil {
+		return fmt.Errorf("biometric adapter is not initialized")
+	}
+
+	b.deviceMutex.Lock()
+	defer b.deviceMutex.Unlock()
+
+	if !device.IsConnected() {
+		if err := device.Connect(); err != nil {
+			return fmt.Errorf("device not connected: %w", err)
+		}
+	}
+	return fn(device)
+}
+
 // attendancePollingLoop continuously polls for new attendance records
-func (b *BiometricAdapter) attendancePollingLoop(ctx context.Context) {
-	ticker := time.NewTicker(time.Duration(b.config.SyncInterval) * time.Second)
+func (b *BiometricAdapter) attendancePollingLoop(ctx context.Context, done chan struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(time.Duration(b.bioConfig.SyncInterval) * time.Second)
 	defer ticker.Stop()
 
+	// Connect and drain any records stored while the bridge was offline
+	b.pollAttendanceRecords()
+
 	for {
 		select {
 		case <-ctx.Done():
 			return
-		case <-b.stopChan:
-			return
 		case <-ticker.C:
 			b.pollAttendanceRecords()
 		}
@@ -244,14 +538,12 @@ func (b *BiometricAdapter) attendancePollingLoop(ctx context.Context) {
 
 // pollAttendanceRecords polls the device for new attendance records
 func (b *BiometricAdapter) pollAttendanceRecords() {
-	if !b.device.IsConnected() {
-		b.logger.Warn("Device not connected, skipping attendance poll")
-		return
-	}
+	b.deviceMutex.Lock()
+	records, err := b.fetchAttendanceRecords()
+	b.deviceMutex.Unlock()
 
-	records, err := b.device.GetNewAttendanceRecords()
 	if err != nil {
-		b.logger.WithError(err).Error("Failed to get attendance records")
+		b.logger.Error("Failed to get attendance records", "name", b.name, "error", err)
 		return
 	}
 
@@ -259,136 +551,276 @@ func (b *BiometricAdapter) pollAttendanceRecords() {
 		return // No new records
 	}
 
-	b.logger.WithField("count", len(records)).Info("Processing new attendance records")
+	b.logger.Info("Processing new attendance records", "count", len(records))
 
 	for _, record := range records {
 		b.processAttendanceRecord(record)
 	}
+}
+
+// fetchAttendanceRecords reads and clears pending records, reconnecting if needed
+// Must be called with deviceMutex held
+func (b *BiometricAdapter) fetchAttendanceRecords() ([]AttendanceRecord, error) {
+	if !b.device.IsConnected() {
+		if err := b.device.Connect(); err != nil {
+			b.updateHealth(fmt.Errorf("failed to connect to device: %w", err))
+			return nil, fmt.Errorf("device not connected: %w", err)
+		}
+		b.logger.Info("Connected to biometric device", "name", b.name)
+		b.lastClockSync = time.Time{}
+	}
+	b.syncDeviceClock()
+
+	if drainer, ok := b.device.(AttendanceLogDrainer); ok {
+		records, err := drainer.DrainAttendanceRecords()
+		b.updateHealth(err)
+		return records, err
+	}
+
+	records, err := b.device.GetNewAttendanceRecords()
+	b.updateHealth(err)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(records) > 0 {
+		// Clear processed records from device
+		if err := b.device.ClearAttendanceRecords(); err != nil {
+			b.logger.Warn("Failed to clear attendance records from device", "error", err)
+		}
+	}
+
+	return records, nil
+}
+
+// syncDeviceClock sets the device clock from the bridge's on the first call
+// after a connect and every clockSyncInterval after that
+// Must be called with deviceMutex held
+func (b *BiometricAdapter) syncDeviceClock() {
+	if !b.lastClockSync.IsZero() && time.Since(b.lastClockSync) < clockSyncInterval {
+		return
+	}
 
-	// Clear processed records from device
-	if err := b.device.ClearAttendanceRecords(); err != nil {
-		b.logger.WithError(err).Warn("Failed to clear attendance records from device")
+	// Failures are retried at the next interval rather than on every poll
+	b.lastClockSync = time.Now()
+	if err := b.device.SetDeviceTime(b.lastClockSync); err != nil {
+		b.logger.Warn("Failed to set biometric device time", "name", b.name, "error", err)
 	}
 }
 
-// processAttendanceRecord processes a single attendance record
+// processAttendanceRecord converts an attendance record into a hardware event
 func (b *BiometricAdapter) processAttendanceRecord(record AttendanceRecord) {
-	// Convert device user ID to platform user ID
-	platformUserID, err := b.resolvePlatformUserID(record.DeviceUserID)
-	if err != nil {
-		b.logger.WithError(err).WithField("device_user_id", record.DeviceUserID).Error("Failed to resolve platform user ID")
+	event := b.recordToEvent(record)
+
+	b.mutex.Lock()
+	isNew := b.markRecordSeen(record)
+	b.mutex.Unlock()
+
+	if !isNew {
+		b.logger.Debug("Skipping attendance record already reported",
+			"deviceUserId", record.DeviceUserID,
+			"timestamp", record.Timestamp)
 		return
 	}
 
+	b.emitEvent(event)
+
+	b.logger.Debug("Attendance record processed",
+		"deviceUserId", record.DeviceUserID,
+		"eventType", event.EventType,
+		"timestamp", record.Timestamp)
+}
+
+// recordToEvent maps a device attendance record to a raw hardware event
+func (b *BiometricAdapter) recordToEvent(record AttendanceRecord) types.RawHardwareEvent {
 	// Determine event type based on status
 	eventType := types.EventTypeEntry
 	if record.Status == 1 {
 		eventType = types.EventTypeExit
 	}
 
-	// Create hardware event
-	event := types.RawHardwareEvent{
-		ExternalUserID: fmt.Sprintf("device_%d", record.DeviceUserID),
+	return types.RawHardwareEvent{
+		ExternalUserID: ExternalUserID(record.DeviceUserID),
 		Timestamp:      record.Timestamp,
 		EventType:      eventType,
+		VerifyMode:     verifyModeName(record.VerifyMode),
 		RawData: map[string]interface{}{
-			"device_user_id":    record.DeviceUserID,
-			"platform_user_id":  platformUserID,
-			"verify_mode":       record.VerifyMode,
-			"work_code":         record.WorkCode,
-			"device_type":       b.config.DeviceType,
-			"adapter_name":      b.name,
+			"biometric":      true,
+			"device_user_id": record.DeviceUserID,
+			"verify_mode":    record.VerifyMode,
+			"work_code":      record.WorkCode,
+			"status":         record.Status,
+			"device_type":    b.bioConfig.DeviceType,
+			"adapter_name":   b.name,
 		},
 	}
+}
 
-	// Send to platform via check-in API
-	if err := b.platformClient.SubmitCheckin(platformUserID, eventType, record.Timestamp); err != nil {
-		b.logger.WithError(err).Error("Failed to submit check-in to platform")
-		return
+// verifyModeName maps a device verify mode code to a types.VerifyMode constant
+func verifyModeName(mode int) string {
+	switch mode {
+	case 1:
+		return types.VerifyModeFingerprint
+	case 2:
+		return types.VerifyModePassword
+	case 3:
+		return types.VerifyModeCard
+	case 15:
+		return types.VerifyModeFace
+	default:
+		return ""
 	}
+}
 
-	// Send event to callback
-	if b.eventCallback != nil {
-		b.eventCallback(event)
+// markRecordSeen records an attendance record as reported and returns false if it already was.
+// Pushed records are still in the device log, so the reconciliation poll would otherwise report them twice.
+// Must be called with mutex held
+func (b *BiometricAdapter) markRecordSeen(record AttendanceRecord) bool {
+	if b.seenRecords == nil {
+		b.seenRecords = make(map[string]time.Time)
 	}
 
-	b.logger.WithFields(logrus.Fields{
-		"platform_user_id": platformUserID,
-		"device_user_id":   record.DeviceUserID,
-		"event_type":       eventType,
-		"timestamp":        record.Timestamp,
-	}).Info("Attendance record processed successfully")
-}
-
-// resolvePlatformUserID converts device user ID to platform user ID
-func (b *BiometricAdapter) resolvePlatformUserID(deviceUserID int) (string, error) {
-	// Get users from device
-	users, err := b.device.GetUsers()
-	if err != nil {
-		return "", fmt.Errorf("failed to get users from device: %w", err)
+	key := fmt.Sprintf("%d:%d", record.DeviceUserID, record.Timestamp.Unix())
+	if _, seen := b.seenRecords[key]; seen {
+		return false
 	}
 
-	// Find user by device user ID
-	for _, user := range users {
-		if user.DeviceUserID == deviceUserID {
-			return user.PlatformUserID, nil
+	if len(b.seenRecords) >= maxSeenRecords {
+		// Records older than a day are rejected by the processor anyway
+		cutoff := time.Now().Add(-24 * time.Hour)
+		for k, timestamp := range b.seenRecords {
+			if timestamp.Before(cutoff) {
+				delete(b.seenRecords, k)
+			}
+		}
+		if len(b.seenRecords) >= maxSeenRecords {
+			b.seenRecords = make(map[string]time.Time)
 		}
 	}
 
-	return "", fmt.Errorf("platform user ID not found for device user ID %d", deviceUserID)
+	b.seenRecords[key] = record.Timestamp
+	return true
 }
 
-// SubmitCheckin submits a check-in event to the platform
-func (pc *PlatformClient) SubmitCheckin(userID string, eventType string, timestamp time.Time) error {
-	url := fmt.Sprintf("%s/api/v1/checkin", pc.baseURL)
-	
-	payload := map[string]interface{}{
-		"memberId":   userID,
-		"authMethod": "FINGERPRINT",
-		"eventType":  eventType,
-		"timestamp":  timestamp.Format(time.RFC3339),
+// updateHealth records the outcome of the latest device operation in the adapter status
+func (b *BiometricAdapter) updateHealth(err error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if err != nil {
+		b.setError(err.Error())
+		return
 	}
 
-	return pc.makeRequest("POST", url, payload, nil)
+	b.status.Status = types.StatusActive
+	b.status.ErrorMessage = ""
+	b.status.UpdatedAt = time.Now()
 }
 
-// makeRequest makes an HTTP request to the platform
-func (pc *PlatformClient) makeRequest(method, url string, payload interface{}, result interface{}) error {
-	var body []byte
-	var err error
+// setError records an error status; must be called with mutex held
+func (b *BiometricAdapter) setError(message string) {
+	b.status.Status = types.StatusError
+	b.status.ErrorMessage = message
+	b.status.UpdatedAt = time.Now()
+}
 
-	if payload != nil {
-		body, err = json.Marshal(payload)
-		if err != nil {
-			return fmt.Errorf("failed to marshal payload: %w", err)
-		}
+// parseConfig converts adapter settings into a biometric configuration
+func parseConfig(settings map[string]interface{}) (*Config, error) {
+	bioConfig := &Config{
+		DeviceType:   "simulator",
+		Connection:   "tcp",
+		DeviceConfig: make(map[string]string),
+		SyncInterval: 10,
+		Realtime:     true,
+		Keepalive:    30,
 	}
 
-	req, err := http.NewRequest(method, url, bytes.NewReader(body))
-	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+	if settings == nil {
+		return bioConfig, nil
 	}
 
-	// Add authentication headers
-	req.Header.Set("X-Device-ID", pc.deviceID)
-	req.Header.Set("X-Device-Key", pc.deviceKey)
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := pc.client.Do(req)
-	if err != nil {
-		return fmt.Errorf("request failed: %w", err)
+	if deviceType, ok := settings["device_type"].(string); ok && deviceType != "" {
+		bioConfig.DeviceType = deviceType
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode >= 400 {
-		return fmt.Errorf("API error: %d %s", resp.StatusCode, resp.Status)
+	if connection, ok := settings["connection"].(string); ok && connection != "" {
+		bioConfig.Connection = connection
+	}
+	if syncInterval, ok := toInt(settings["sync_interval"]); ok {
+		if syncInterval <= 0 {
+			return nil, fmt.Errorf("sync_interval must be positive")
+		}
+		bioConfig.SyncInterval = syncInterval
+	}
+	if realtime, ok := settings["realtime"].(bool); ok {
+		bioConfig.Realtime = realtime
+	}
+	if keepalive, ok := toInt(settings["keepalive"]); ok {
+		if keepalive <= 0 {
+			return nil, fmt.Errorf("keepalive must be positive")
+		}
+		bioConfig.Keepalive = keepalive
 	}
 
-	if result != nil {
-		if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
-			return fmt.Errorf("failed to decode response: %w", err)
+	// Device config values may arrive as numbers from YAML or JSON
+	if deviceConfig, ok := settings["device_config"].(map[string]interface{}); ok {
+		for key, value := range deviceConfig {
+			switch v := value.(type) {
+			case string:
+				bioConfig.DeviceConfig[key] = v
+			case nil:
+			default:
+				encoded, err := json.Marshal(v)
+				if err != nil {
+					return nil, fmt.Errorf("invalid device_config value for %s: %w", key, err)
+				}
+				bioConfig.DeviceConfig[key] = string(encoded)
+			}
 		}
 	}
 
-	return nil
-}
\ No newline at end of file
+	return bioConfig, nil
+}
+
+// UnlocksDoor reports whether a biometric adapter with the given settings can
+// unlock the door wired to its terminal. Settings that do not parse are left to
+// Initialize to report.
+func UnlocksDoor(settings map[string]interface{}) bool {
+	bioConfig, err := parseConfig(settings)
+	return err != nil || deviceUnlocks(bioConfig.DeviceType)
+}
+
+// deviceUnlocks reports whether the driver for a device type can drive the
+// terminal's door output; doors read by other devices need a relay
+func deviceUnlocks(deviceType string) bool {
+	return deviceType != "realtime"
+}
+
+// newDevice creates the device driver for the configured device type
+func newDevice(bioConfig *Config, logger *slog.Logger) (BiometricDevice, error) {
+	switch bioConfig.DeviceType {
+	case "essl":
+		return NewESSLDevice(bioConfig.DeviceConfig, logger), nil
+	case "zkteco":
+		return NewZKTecoDevice(bioConfig.DeviceConfig, logger), nil
+	case "realtime":
+		return NewRealtimeDevice(bioConfig.DeviceConfig, logger), nil
+	case "simulator":
+		return NewSimulatorDevice(bioConfig.DeviceConfig, logger), nil
+	default:
+		return nil, fmt.Errorf("unsupported device type: %s", bioConfig.DeviceType)
+	}
+}
+
+// toInt converts a numeric setting to int
+func toInt(value interface{}) (int, bool) {
+	switch v := value.(type) {
+	case int:
+		return v, true
+	case int64:
+		return int(v), true
+	case float64:
+		return int(v), true
+	default:
+		return 0, false
+	}
+}
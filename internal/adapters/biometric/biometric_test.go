@@ -0,0 +1,404 @@
+package biometric
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"gym-door-bridge/internal/types"
+)
+
+// fakeDevice is an in-memory BiometricDevice for adapter tests
+type fakeDevice struct {
+	mu          sync.Mutex
+	connected   bool
+	connectErr  error
+	records     []AttendanceRecord
+	cleared     int
+	unlocks     []time.Duration
+	connectCall int
+	clockSets   []time.Time
+}
+
+func (f *fakeDevice) Connect() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.connectCall++
+	if f.connectErr != nil {
+		return f.connectErr
+	}
+	f.connected = true
+	return nil
+}
+
+func (f *fakeDevice) Disconnect() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.connected = false
+	return nil
+}
+
+func (f *fakeDevice) IsConnected() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.connected
+}
+
+func (f *fakeDevice) GetStatus() string { return "fake" }
+
+func (f *fakeDevice) EnrollUser(platformUserID string, deviceUserID int, name string) error {
+	return nil
+}
+
+func (f *fakeDevice) DeleteUser(deviceUserID int) error { return nil }
+
+func (f *fakeDevice) GetUsers() ([]DeviceUser, error) { return nil, nil }
+
+func (f *fakeDevice) GetNewAttendanceRecords() ([]AttendanceRecord, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	records := f.records
+	f.records = nil
+	return records, nil
+}
+
+func (f *fakeDevice) ClearAttendanceRecords() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.cleared++
+	return nil
+}
+
+func (f *fakeDevice) UnlockDoor(duration time.Duration) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.unlocks = append(f.unlocks, duration)
+	return nil
+}
+
+func (f *fakeDevice) GetDeviceInfo() (*DeviceInfo, error) { return &DeviceInfo{}, nil }
+
+func (f *fakeDevice) GetDeviceTime() (time.Time, error) { return time.Now(), nil }
+
+func (f *fakeDevice) SetDeviceTime(t time.Time) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.clockSets = append(f.clockSets, t)
+	return nil
+}
+
+func newTestLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+}
+
+func TestBiometricAdapter_Initialize(t *testing.T) {
+	tests := []struct {
+		name         string
+		settings     map[string]interface{}
+		expectError  bool
+		expectType   string
+		expectConfig map[string]string
+	}{
+		{
+			name:       "defaults to simulator",
+			settings:   nil,
+			expectType: "simulator",
+		},
+		{
+			name: "essl with numeric device config",
+			settings: map[string]interface{}{
+				"device_type":   "essl",
+				"sync_interval": 5.0,
+				"device_config": map[string]interface{}{
+					"ip_address": "192.168.1.201",
+					"port":       4370.0,
+				},
+			},
+			expectType:   "essl",
+			expectConfig: map[string]string{"ip_address": "192.168.1.201", "port": "4370"},
+		},
+		{
+			name: "unsupported device type",
+			settings: map[string]interface{}{
+				"device_type": "anviz",
+			},
+			expectError: true,
+		},
+		{
+			name: "invalid sync interval",
+			settings: map[string]interface{}{
+				"device_type":   "simulator",
+				"sync_interval": 0.0,
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			adapter := NewBiometricAdapter(newTestLogger())
+			err := adapter.Initialize(context.Background(), types.AdapterConfig{
+				Name:     "biometric",
+				Enabled:  true,
+				Settings: tt.settings,
+			})
+
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("expected error but got none")
+				}
+				if adapter.GetStatus().Status != types.StatusError {
+					t.Errorf("expected error status, got %s", adapter.GetStatus().Status)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if adapter.bioConfig.DeviceType != tt.expectType {
+				t.Errorf("expected device type %s, got %s", tt.expectType, adapter.bioConfig.DeviceType)
+			}
+			for key, value := range tt.expectConfig {
+				if adapter.bioConfig.DeviceConfig[key] != value {
+					t.Errorf("expected device_config %s=%s, got %s", key, value, adapter.bioConfig.DeviceConfig[key])
+				}
+			}
+			if adapter.GetStatus().Status != types.StatusActive {
+				t.Errorf("expected active status, got %s", adapter.GetStatus().Status)
+			}
+		})
+	}
+}
+
+func TestBiometricAdapter_EventsFlowThroughCallback(t *testing.T) {
+	adapter := NewBiometricAdapter(newTestLogger())
+	if err := adapter.Initialize(context.Background(), types.AdapterConfig{Name: "biometric", Enabled: true}); err != nil {
+		t.Fatalf("failed to initialize adapter: %v", err)
+	}
+
+	recordTime := time.Date(2025, 6, 4, 8, 0, 0, 0, time.UTC)
+	device := &fakeDevice{
+		records: []AttendanceRecord{
+			{DeviceUserID: 42, Timestamp: recordTime, Status: 0, VerifyMode: 1},
+			{DeviceUserID: 7, Timestamp: recordTime.Add(time.Minute), Status: 1, VerifyMode: 3},
+		},
+	}
+	adapter.device = device
+
+	events := make(chan types.RawHardwareEvent, 10)
+	adapter.OnEvent(func(event types.RawHardwareEvent) {
+		events <- event
+	})
+
+	if err := adapter.StartListening(context.Background()); err != nil {
+		t.Fatalf("failed to start listening: %v", err)
+	}
+	defer adapter.StopListening(context.Background())
+
+	var received []types.RawHardwareEvent
+	timeout := time.After(2 * time.Second)
+	for len(received) < 2 {
+		select {
+		case event := <-events:
+			received = append(received, event)
+		case <-timeout:
+			t.Fatalf("timed out waiting for events, got %d", len(received))
+		}
+	}
+
+	if received[0].ExternalUserID != "device_42" || received[0].EventType != types.EventTypeEntry {
+		t.Errorf("unexpected first event: %+v", received[0])
+	}
+	if !received[0].Timestamp.Equal(recordTime) {
+		t.Errorf("expected device timestamp to be preserved, got %v", received[0].Timestamp)
+	}
+	if received[1].ExternalUserID != "device_7" || received[1].EventType != types.EventTypeExit {
+		t.Errorf("unexpected second event: %+v", received[1])
+	}
+	if received[1].RawData["verify_mode"] != 3 {
+		t.Errorf("expected verify_mode in raw data, got %v", received[1].RawData["verify_mode"])
+	}
+
+	device.mu.Lock()
+	cleared := device.cleared
+	device.mu.Unlock()
+	if cleared != 1 {
+		t.Errorf("expected records to be cleared once, got %d", cleared)
+	}
+
+	if !adapter.IsHealthy() {
+		t.Errorf("expected adapter to be healthy")
+	}
+}
+
+func TestBiometricAdapter_UnlockDoor(t *testing.T) {
+	adapter := NewBiometricAdapter(newTestLogger())
+	if err := adapter.Initialize(context.Background(), types.AdapterConfig{Name: "biometric", Enabled: true}); err != nil {
+		t.Fatalf("failed to initialize adapter: %v", err)
+	}
+
+	device := &fakeDevice{}
+	adapter.device = device
+
+	if err := adapter.UnlockDoor(context.Background(), 3000); err == nil {
+		t.Errorf("expected error when device is not connected")
+	}
+
+	device.Connect()
+	if err := adapter.UnlockDoor(context.Background(), 3000); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(device.unlocks) != 1 || device.unlocks[0] != 3*time.Second {
+		t.Errorf("expected one 3s unlock, got %v", device.unlocks)
+	}
+
+	// Realtime terminals cannot drive their door output
+	realtime := NewBiometricAdapter(newTestLogger())
+	settings := map[string]interface{}{"device_type": "realtime"}
+	if err := realtime.Initialize(context.Background(), types.AdapterConfig{Name: "biometric", Enabled: true, Settings: settings}); err != nil {
+		t.Fatalf("failed to initialize adapter: %v", err)
+	}
+	realtime.device = &fakeDevice{connected: true}
+	if err := realtime.UnlockDoor(context.Background(), 3000); err == nil {
+		t.Errorf("expected error unlocking through a realtime device")
+	}
+	if UnlocksDoor(settings) || !UnlocksDoor(map[string]interface{}{"device_type": "zkteco"}) {
+		t.Errorf("expected only realtime devices to be reported as unable to unlock")
+	}
+}
+
+func TestBiometricAdapter_UnreachableDevice(t *testing.T) {
+	adapter := NewBiometricAdapter(newTestLogger())
+	if err := adapter.Initialize(context.Background(), types.AdapterConfig{Name: "biometric", Enabled: true}); err != nil {
+		t.Fatalf("failed to initialize adapter: %v", err)
+	}
+
+	device := &fakeDevice{connectErr: fmt.Errorf("connection refused")}
+	adapter.device = device
+	adapter.OnEvent(func(event types.RawHardwareEvent) {})
+
+	// Listening must succeed so the adapter can reconnect once the terminal is back
+	if err := adapter.StartListening(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer adapter.StopListening(context.Background())
+
+	deadline := time.Now().Add(2 * time.Second)
+	for adapter.IsHealthy() && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if adapter.IsHealthy() {
+		t.Errorf("expected adapter to be unhealthy while device is unreachable")
+	}
+	if adapter.GetStatus().ErrorMessage == "" {
+		t.Errorf("expected error message to be recorded")
+	}
+}
+
+func TestBiometricAdapter_SyncsDeviceClock(t *testing.T) {
+	adapter := NewBiometricAdapter(newTestLogger())
+	if err := adapter.Initialize(context.Background(), types.AdapterConfig{Name: "biometric", Enabled: true}); err != nil {
+		t.Fatalf("failed to initialize adapter: %v", err)
+	}
+
+	device := &fakeDevice{}
+	adapter.device = device
+
+	poll := func() int {
+		t.Helper()
+		adapter.deviceMutex.Lock()
+		defer adapter.deviceMutex.Unlock()
+		if _, err := adapter.fetchAttendanceRecords(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return len(device.clockSets)
+	}
+
+	if sets := poll(); sets != 1 {
+		t.Fatalf("expected the clock to be set on connect, got %d sets", sets)
+	}
+	if sets := poll(); sets != 1 {
+		t.Errorf("expected no clock set before the interval elapsed, got %d sets", sets)
+	}
+
+	adapter.lastClockSync = adapter.lastClockSync.Add(-clockSyncInterval)
+	if sets := poll(); sets != 2 {
+		t.Errorf("expected the clock to be set once the interval elapsed, got %d sets", sets)
+	}
+
+	// A terminal that reconnects may have been power cycled and lost its time
+	device.Disconnect()
+	if sets := poll(); sets != 3 {
+		t.Errorf("expected the clock to be set on reconnect, got %d sets", sets)
+	}
+	if drift := time.Since(device.clockSets[2]); drift < 0 || drift > time.Second {
+		t.Errorf("expected the clock to be set to the bridge's time, got %v", device.clockSets[2])
+	}
+}
+
+func TestBiometricAdapter_StartListeningRequiresCallback(t *testing.T) {
+	adapter := NewBiometricAdapter(newTestLogger())
+	if err := adapter.Initialize(context.Background(), types.AdapterConfig{Name: "biometric", Enabled: true}); err != nil {
+		t.Fatalf("failed to initialize adapter: %v", err)
+	}
+
+	if err := adapter.StartListening(context.Background()); err == nil {
+		t.Errorf("expected error without event callback")
+	}
+}
+
+func TestBiometricAdapter_RecordVerifyMode(t *testing.T) {
+	adapter := &BiometricAdapter{name: "zk", bioConfig: &Config{DeviceType: "zkteco"}}
+
+	tests := map[int]string{
+		1:  types.VerifyModeFingerprint,
+		2:  types.VerifyModePassword,
+		3:  types.VerifyModeCard,
+		15: types.VerifyModeFace,
+		99: "",
+	}
+	for mode, want := range tests {
+		event := adapter.recordToEvent(AttendanceRecord{DeviceUserID: 7, Timestamp: time.Now(), VerifyMode: mode})
+		if event.VerifyMode != want {
+			t.Errorf("verify mode %d: expected %q, got %q", mode, want, event.VerifyMode)
+		}
+	}
+}
+
+func TestBiometricAdapter_DoorAlarmEvents(t *testing.T) {
+	adapter := &BiometricAdapter{
+		name:      "zk",
+		bioConfig: &Config{DeviceType: "zkteco"},
+		logger:    newTestLogger(),
+	}
+
+	var events []types.RawHardwareEvent
+	adapter.eventCallback = func(event types.RawHardwareEvent) {
+		events = append(events, event)
+	}
+
+	now := time.Now()
+	adapter.processRealtimeEvent(RealtimeEvent{Kind: RealtimeDoorAlarm, AlarmCode: alarmDoorOpenedUnexpectedly, Timestamp: now})
+	adapter.processRealtimeEvent(RealtimeEvent{Kind: RealtimeDoorAlarm, AlarmCode: alarmDoorNotClosed, Timestamp: now})
+	adapter.processRealtimeEvent(RealtimeEvent{Kind: RealtimeDoorAlarm, AlarmCode: 55, Timestamp: now})
+
+	if len(events) != 2 {
+		t.Fatalf("expected 2 door alarm events, got %+v", events)
+	}
+	if events[0].EventType != types.EventTypeDoorForcedOpen || events[1].EventType != types.EventTypeDoorHeldOpen {
+		t.Errorf("unexpected alarm event types: %s, %s", events[0].EventType, events[1].EventType)
+	}
+	if events[0].ExternalUserID != "" || events[0].RawData["alarm_code"] != alarmDoorOpenedUnexpectedly {
+		t.Errorf("unexpected alarm event: %+v", events[0])
+	}
+	if adapter.lastAlarm == nil || adapter.lastAlarm.AlarmCode != 55 {
+		t.Errorf("expected every alarm to be recorded, got %+v", adapter.lastAlarm)
+	}
+}
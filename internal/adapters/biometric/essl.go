@@ -3,93 +3,117 @@ package biometric
 import (
 	"encoding/binary"
 	"fmt"
+	"log/slog"
 	"net"
 	"strconv"
 	"time"
-
-	"github.com/sirupsen/logrus"
 )
 
 // ESSLDevice handles ESSL biometric devices
 type ESSLDevice struct {
-	config     map[string]string
-	logger     *logrus.Logger
-	conn       net.Conn
-	connected  bool
-	ipAddress  string
-	port       int
-	deviceID   int
-	password   string
-	sessionID  int
-	replyID    int
+	config    map[string]string
+	logger    *slog.Logger
+	conn      net.Conn
+	connected bool
+	ipAddress string
+	port      int
+	deviceID  int
+	password  string
+	sessionID int
+	replyID   int
 }
 
 // ESSL Protocol Constants
 const (
-	CMD_CONNECT    = 1000
-	CMD_EXIT       = 1001
-	CMD_ENABLEDEVICE = 1002
+	CMD_CONNECT       = 1000
+	CMD_EXIT          = 1001
+	CMD_ENABLEDEVICE  = 1002
 	CMD_DISABLEDEVICE = 1003
-	CMD_ACK_OK     = 2000
-	CMD_ACK_ERROR  = 2001
-	CMD_ACK_DATA   = 2002
-	CMD_ACK_RETRY  = 2003
-	CMD_ACK_REPEAT = 2004
-	CMD_ACK_UNAUTH = 2005
-	
-	CMD_USER_WRQ      = 8
-	CMD_USERTEMP_RRQ  = 9
-	CMD_USERTEMP_WRQ  = 10
-	CMD_OPTIONS_RRQ   = 11
-	CMD_ATTLOG_RRQ    = 13
-	CMD_CLEAR_DATA    = 14
-	CMD_CLEAR_ATTLOG  = 15
-	CMD_DELETE_USER   = 18
+	CMD_ACK_OK        = 2000
+	CMD_ACK_ERROR     = 2001
+	CMD_ACK_DATA      = 2002
+	CMD_ACK_RETRY     = 2003
+	CMD_ACK_REPEAT    = 2004
+	CMD_ACK_UNAUTH    = 2005
+
+	CMD_DB_RRQ          = 7
+	CMD_USER_WRQ        = 8
+	CMD_USERTEMP_RRQ    = 9
+	CMD_USERTEMP_WRQ    = 10
+	CMD_OPTIONS_RRQ     = 11
+	CMD_ATTLOG_RRQ      = 13
+	CMD_CLEAR_DATA      = 14
+	CMD_CLEAR_ATTLOG    = 15
+	CMD_DELETE_USER     = 18
 	CMD_DELETE_USERTEMP = 19
-	CMD_CLEAR_ADMIN   = 20
-	CMD_USERGRP_RRQ   = 21
-	CMD_USERGRP_WRQ   = 22
-	CMD_USERTZ_RRQ    = 23
-	CMD_USERTZ_WRQ    = 24
-	CMD_GRPTZ_RRQ     = 25
-	CMD_GRPTZ_WRQ     = 26
-	CMD_TZ_RRQ        = 27
-	CMD_TZ_WRQ        = 28
-	CMD_ULG_RRQ       = 29
-	CMD_ULG_WRQ       = 30
-	CMD_UNLOCK        = 31
-	CMD_CLEAR_ACC     = 32
-	CMD_CLEAR_OPLOG   = 33
-	CMD_OPLOG_RRQ     = 34
-	CMD_GET_FREE_SIZES = 50
-	CMD_ENABLE_CLOCK  = 57
-	CMD_STARTVERIFY   = 60
-	CMD_STARTENROLL   = 61
-	CMD_CANCELCAPTURE = 62
-	CMD_STATE_RRQ     = 64
-	CMD_WRITE_LCD     = 66
-	CMD_CLEAR_LCD     = 67
-	CMD_GET_PINWIDTH  = 69
-	CMD_SMS_WRQ       = 70
-	CMD_SMS_RRQ       = 71
-	CMD_DELETE_SMS    = 72
-	CMD_UDATA_WRQ     = 73
-	CMD_DELETE_UDATA  = 74
-	CMD_DOORSTATE_RRQ = 75
-	CMD_WRITE_MIFARE  = 76
-	CMD_EMPTY_MIFARE  = 78
-	CMD_VERIFY_WRQ    = 79
-	CMD_VERIFY_RRQ    = 80
-	CMD_TMP_WRITE     = 87
+	CMD_CLEAR_ADMIN     = 20
+	CMD_USERGRP_RRQ     = 21
+	CMD_USERGRP_WRQ     = 22
+	CMD_USERTZ_RRQ      = 23
+	CMD_USERTZ_WRQ      = 24
+	CMD_GRPTZ_RRQ       = 25
+	CMD_GRPTZ_WRQ       = 26
+	CMD_TZ_RRQ          = 27
+	CMD_TZ_WRQ          = 28
+	CMD_ULG_RRQ         = 29
+	CMD_ULG_WRQ         = 30
+	CMD_UNLOCK          = 31
+	CMD_CLEAR_ACC       = 32
+	CMD_CLEAR_OPLOG     = 33
+	CMD_OPLOG_RRQ       = 34
+	CMD_GET_FREE_SIZES  = 50
+	CMD_ENABLE_CLOCK    = 57
+	CMD_STARTVERIFY     = 60
+	CMD_STARTENROLL     = 61
+	CMD_CANCELCAPTURE   = 62
+	CMD_STATE_RRQ       = 64
+	CMD_WRITE_LCD       = 66
+	CMD_CLEAR_LCD       = 67
+	CMD_GET_PINWIDTH    = 69
+	CMD_SMS_WRQ         = 70
+	CMD_SMS_RRQ         = 71
+	CMD_DELETE_SMS      = 72
+	CMD_UDATA_WRQ       = 73
+	CMD_DELETE_UDATA    = 74
+	CMD_DOORSTATE_RRQ   = 75
+	CMD_WRITE_MIFARE    = 76
+	CMD_EMPTY_MIFARE    = 78
+	CMD_VERIFY_WRQ      = 79
+	CMD_VERIFY_RRQ      = 80
+	CMD_TMP_WRITE       = 87
 	CMD_CHECKSUM_BUFFER = 119
-	CMD_DEL_FPTMP     = 134
-	CMD_GET_TIME      = 201
-	CMD_SET_TIME      = 202
-	CMD_REG_EVENT     = 500
+	CMD_DEL_FPTMP       = 134
+	CMD_GET_TIME        = 201
+	CMD_SET_TIME        = 202
+	CMD_REG_EVENT       = 500
+
+	CMD_REFRESHDATA    = 1013
+	CMD_GET_VERSION    = 1100
+	CMD_AUTH           = 1102
+	CMD_PREPARE_DATA   = 1500
+	CMD_DATA           = 1501
+	CMD_FREE_DATA      = 1502
+	CMD_PREPARE_BUFFER = 1503
+	CMD_READ_BUFFER    = 1504
+
+	FCT_ATTLOG    = 1
+	FCT_FINGERTMP = 2
+	FCT_USER      = 5
+
+	// Real-time event flags for CMD_REG_EVENT
+	EF_ATTLOG       = 1
+	EF_FINGER       = 2
+	EF_ENROLLUSER   = 4
+	EF_ENROLLFINGER = 8
+	EF_BUTTON       = 16
+	EF_UNLOCK       = 32
+	EF_VERIFY       = 128
+	EF_FPFTR        = 256
+	EF_ALARM        = 512
 )
 
 // NewESSLDevice creates a new ESSL device
-func NewESSLDevice(config map[string]string, logger *logrus.Logger) *ESSLDevice {
+func NewESSLDevice(config map[string]string, logger *slog.Logger) *ESSLDevice {
 	ipAddress := config["ip_address"]
 	if ipAddress == "" {
 		ipAddress = "192.168.1.100"
@@ -125,11 +149,7 @@ func NewESSLDevice(config map[string]string, logger *logrus.Logger) *ESSLDevice
 
 // Connect connects to the ESSL device
 func (e *ESSLDevice) Connect() error {
-	e.logger.WithFields(logrus.Fields{
-		"ip":        e.ipAddress,
-		"port":      e.port,
-		"device_id": e.deviceID,
-	}).Info("Connecting to ESSL device")
+	e.logger.Info("Connecting to ESSL device", "ip", e.ipAddress, "port", e.port, "deviceId", e.deviceID)
 
 	// Establish TCP connection
 	conn, err := net.DialTimeout("tcp", fmt.Sprintf("%s:%d", e.ipAddress, e.port), 10*time.Second)
@@ -159,7 +179,7 @@ func (e *ESSLDevice) Connect() error {
 	}
 
 	e.sessionID = int(response.SessionID)
-	e.logger.WithField("session_id", e.sessionID).Info("Connected to ESSL device successfully")
+	e.logger.Info("Connected to ESSL device successfully", "sessionId", e.sessionID)
 
 	return nil
 }
@@ -202,11 +222,7 @@ func (e *ESSLDevice) EnrollUser(platformUserID string, deviceUserID int, name st
 		return fmt.Errorf("device not connected")
 	}
 
-	e.logger.WithFields(logrus.Fields{
-		"platform_user_id": platformUserID,
-		"device_user_id":   deviceUserID,
-		"name":             name,
-	}).Info("Enrolling user on ESSL device")
+	e.logger.Info("Enrolling user on ESSL device", "platformUserId", platformUserID, "deviceUserId", deviceUserID, "name", name)
 
 	// TODO: Implement ESSL user enrollment protocol
 	// This involves sending user data and fingerprint templates
@@ -238,7 +254,7 @@ func (e *ESSLDevice) DeleteUser(deviceUserID int) error {
 		return fmt.Errorf("delete user command failed with response: %d", response.Command)
 	}
 
-	e.logger.WithField("device_user_id", deviceUserID).Info("User deleted from ESSL device")
+	e.logger.Info("User deleted from ESSL device", "deviceUserId", deviceUserID)
 	return nil
 }
 
@@ -281,8 +297,8 @@ func (e *ESSLDevice) GetNewAttendanceRecords() ([]AttendanceRecord, error) {
 
 	// Parse attendance records from response data
 	records := e.parseAttendanceRecords(response.Data)
-	
-	e.logger.WithField("count", len(records)).Info("Retrieved attendance records from ESSL device")
+
+	e.logger.Info("Retrieved attendance records from ESSL device", "count", len(records))
 	return records, nil
 }
 
@@ -309,6 +325,85 @@ func (e *ESSLDevice) ClearAttendanceRecords() error {
 	return nil
 }
 
+// DrainAttendanceRecords reads and clears the attendance log with the device
+// disabled, so no member can punch between the read and the clear
+func (e *ESSLDevice) DrainAttendanceRecords() ([]AttendanceRecord, error) {
+	if !e.connected {
+		return nil, fmt.Errorf("device not connected")
+	}
+
+	if err := e.setEnabled(false); err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := e.setEnabled(true); err != nil {
+			e.logger.Error("Failed to re-enable ESSL device after reading attendance", "error", err)
+		}
+	}()
+
+	records, err := e.GetNewAttendanceRecords()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(records) > 0 {
+		if err := e.ClearAttendanceRecords(); err != nil {
+			e.logger.Warn("Failed to clear attendance records from ESSL device", "error", err)
+		}
+	}
+	return records, nil
+}
+
+// setEnabled enables or disables user verification on the device
+func (e *ESSLDevice) setEnabled(enabled bool) error {
+	var command uint16 = CMD_DISABLEDEVICE
+	action := "disable device"
+	if enabled {
+		command, action = CMD_ENABLEDEVICE, "enable device"
+	}
+
+	if err := e.sendCommand(command, []byte{}); err != nil {
+		return fmt.Errorf("failed to send %s command: %w", action, err)
+	}
+
+	response, err := e.readResponse()
+	if err != nil {
+		return fmt.Errorf("failed to read %s response: %w", action, err)
+	}
+
+	if response.Command != CMD_ACK_OK {
+		return fmt.Errorf("%s command failed with response: %d", action, response.Command)
+	}
+	return nil
+}
+
+// UnlockDoor energizes the door relay for the given duration
+func (e *ESSLDevice) UnlockDoor(duration time.Duration) error {
+	if !e.connected {
+		return fmt.Errorf("device not connected")
+	}
+
+	// Unlock duration is expressed in units of 100ms
+	data := make([]byte, 4)
+	binary.LittleEndian.PutUint32(data, uint32(duration/(100*time.Millisecond)))
+
+	if err := e.sendCommand(CMD_UNLOCK, data); err != nil {
+		return fmt.Errorf("failed to send unlock command: %w", err)
+	}
+
+	response, err := e.readResponse()
+	if err != nil {
+		return fmt.Errorf("failed to read unlock response: %w", err)
+	}
+
+	if response.Command != CMD_ACK_OK {
+		return fmt.Errorf("unlock command failed with response: %d", response.Command)
+	}
+
+	e.logger.Info("Door unlocked via ESSL device", "duration", duration)
+	return nil
+}
+
 // GetDeviceInfo gets device information
 func (e *ESSLDevice) GetDeviceInfo() (*DeviceInfo, error) {
 	if !e.connected {
@@ -377,7 +472,7 @@ func (e *ESSLDevice) SetDeviceTime(t time.Time) error {
 		return fmt.Errorf("set time command failed with response: %d", response.Command)
 	}
 
-	e.logger.WithField("time", t).Info("Device time updated")
+	e.logger.Info("Device time updated", "time", t)
 	return nil
 }
 
@@ -404,7 +499,7 @@ func (e *ESSLDevice) sendCommand(command uint16, data []byte) error {
 	binary.LittleEndian.PutUint16(packet[2:4], 0) // Checksum (calculated later)
 	binary.LittleEndian.PutUint16(packet[4:6], uint16(e.sessionID))
 	binary.LittleEndian.PutUint16(packet[6:8], uint16(e.replyID))
-	
+
 	if len(data) > 0 {
 		copy(packet[8:], data)
 	}
@@ -506,4 +601,4 @@ func (e *ESSLDevice) parseAttendanceRecords(data []byte) []AttendanceRecord {
 	}
 
 	return records
-}
\ No newline at end of file
+}
@@ -0,0 +1,217 @@
+package biometric
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gym-door-bridge/internal/types"
+)
+
+const (
+	// realtimeReadWindow bounds how long the event reader holds the device before
+	// letting unlock and enrollment commands through
+	realtimeReadWindow = 200 * time.Millisecond
+
+	minReconnectBackoff = time.Second
+	maxReconnectBackoff = time.Minute
+)
+
+// Door sensor alarm codes pushed by ZKTeco-protocol devices
+const (
+	alarmDoorOpenedUnexpectedly = 1 // Door opened without a verification or exit button press
+	alarmDoorNotClosed          = 4 // Door left open past the sensor delay
+)
+
+// realtimeEventLoop holds a persistent session with the device and reports pushed events as they arrive.
+// Attendance is polled only after each (re)connect to fill gaps left while the session was down.
+func (b *BiometricAdapter) realtimeEventLoop(ctx context.Context, done chan struct{}, source RealtimeEventSource) {
+	defer close(done)
+	defer b.setRealtimeActive(false)
+
+	keepalive := time.Duration(b.bioConfig.Keepalive) * time.Second
+	backoff := minReconnectBackoff
+	lastActivity := time.Now()
+
+	for {
+		if ctx.Err() != nil {
+			return
+		}
+
+		if !b.isRealtimeActive() {
+			if err := b.startRealtimeSession(source); err != nil {
+				b.logger.Error("Failed to start real-time session",
+					"name", b.name,
+					"error", err,
+					"retryIn", backoff)
+
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(backoff):
+				}
+
+				backoff *= 2
+				if backoff > maxReconnectBackoff {
+					backoff = maxReconnectBackoff
+				}
+				continue
+			}
+
+			backoff = minReconnectBackoff
+			lastActivity = time.Now()
+
+			// Reconcile records stored on the device while no session was open
+			b.pollAttendanceRecords()
+			continue
+		}
+
+		b.deviceMutex.Lock()
+		event, err := source.ReadRealtimeEvent(realtimeReadWindow)
+		if err == nil && event == nil && time.Since(lastActivity) >= keepalive {
+			err = source.Keepalive()
+			lastActivity = time.Now()
+		}
+		if err == nil && event == nil {
+			b.syncDeviceClock()
+		}
+		b.deviceMutex.Unlock()
+
+		if err != nil {
+			b.logger.Warn("Real-time session lost, reconnecting", "name", b.name, "error", err)
+			b.endRealtimeSession(err)
+			continue
+		}
+
+		if event != nil {
+			lastActivity = time.Now()
+			b.processRealtimeEvent(*event)
+		}
+	}
+}
+
+// startRealtimeSession connects to the device if needed and subscribes to event pushes
+func (b *BiometricAdapter) startRealtimeSession(source RealtimeEventSource) error {
+	b.deviceMutex.Lock()
+	defer b.deviceMutex.Unlock()
+
+	if !b.device.IsConnected() {
+		if err := b.device.Connect(); err != nil {
+			err = fmt.Errorf("failed to connect to device: %w", err)
+			b.updateHealth(err)
+			return err
+		}
+		b.lastClockSync = time.Time{}
+	}
+
+	if err := source.RegisterRealtimeEvents(); err != nil {
+		err = fmt.Errorf("failed to register for real-time events: %w", err)
+		b.updateHealth(err)
+		b.device.Disconnect()
+		return err
+	}
+
+	b.updateHealth(nil)
+	b.setRealtimeActive(true)
+
+	b.logger.Info("Real-time event session started", "name", b.name)
+	return nil
+}
+
+// endRealtimeSession drops the current session so the loop reconnects
+func (b *BiometricAdapter) endRealtimeSession(cause error) {
+	b.setRealtimeActive(false)
+	b.updateHealth(cause)
+
+	b.deviceMutex.Lock()
+	defer b.deviceMutex.Unlock()
+
+	if err := b.device.Disconnect(); err != nil {
+		b.logger.Debug("Error disconnecting from biometric device", "error", err)
+	}
+}
+
+// processRealtimeEvent reports a pushed device event
+func (b *BiometricAdapter) processRealtimeEvent(event RealtimeEvent) {
+	switch event.Kind {
+	case RealtimeAttendance:
+		b.processAttendanceRecord(event.Record)
+	case RealtimeVerifyFailed:
+		b.emitEvent(types.RawHardwareEvent{
+			ExternalUserID: "device_unknown",
+			Timestamp:      event.Timestamp,
+			EventType:      types.EventTypeDenied,
+			RawData: map[string]interface{}{
+				"biometric":    true,
+				"reason":       "verification_failed",
+				"device_type":  b.bioConfig.DeviceType,
+				"adapter_name": b.name,
+			},
+		})
+	case RealtimeDoorAlarm:
+		b.mutex.Lock()
+		b.lastAlarm = &event
+		b.mutex.Unlock()
+
+		b.logger.Warn("Door alarm reported by biometric device",
+			"name", b.name,
+			"alarmCode", event.AlarmCode,
+			"timestamp", event.Timestamp)
+
+		// Alarms about the door itself are reported like those of the door sensor
+		eventType, ok := doorAlarmEventType(event.AlarmCode)
+		if !ok {
+			return
+		}
+		b.emitEvent(types.RawHardwareEvent{
+			Timestamp: event.Timestamp,
+			EventType: eventType,
+			RawData: map[string]interface{}{
+				"biometric":    true,
+				"alarm_code":   event.AlarmCode,
+				"device_type":  b.bioConfig.DeviceType,
+				"adapter_name": b.name,
+			},
+		})
+	}
+}
+
+// doorAlarmEventType maps a device alarm code to a door alarm event type.
+// Tamper, duress and other alarms have no door event type.
+func doorAlarmEventType(alarmCode int) (string, bool) {
+	switch alarmCode {
+	case alarmDoorOpenedUnexpectedly:
+		return types.EventTypeDoorForcedOpen, true
+	case alarmDoorNotClosed:
+		return types.EventTypeDoorHeldOpen, true
+	default:
+		return "", false
+	}
+}
+
+// emitEvent delivers an event to the registered callback
+func (b *BiometricAdapter) emitEvent(event types.RawHardwareEvent) {
+	b.mutex.Lock()
+	callback := b.eventCallback
+	b.status.LastEvent = time.Now()
+	b.status.UpdatedAt = time.Now()
+	b.mutex.Unlock()
+
+	if callback != nil {
+		callback(event)
+	}
+}
+
+// setRealtimeActive records whether pushed events are currently being received
+func (b *BiometricAdapter) setRealtimeActive(active bool) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.realtimeActive = active
+}
+
+// isRealtimeActive returns whether a real-time session is open
+func (b *BiometricAdapter) isRealtimeActive() bool {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+	return b.realtimeActive
+}
@@ -2,22 +2,21 @@ package biometric
 
 import (
 	"fmt"
+	"log/slog"
 	"time"
-
-	"github.com/sirupsen/logrus"
 )
 
 // RealtimeDevice handles Realtime biometric devices
 type RealtimeDevice struct {
 	config    map[string]string
-	logger    *logrus.Logger
+	logger    *slog.Logger
 	connected bool
 	ipAddress string
 	port      int
 }
 
 // NewRealtimeDevice creates a new Realtime device
-func NewRealtimeDevice(config map[string]string, logger *logrus.Logger) *RealtimeDevice {
+func NewRealtimeDevice(config map[string]string, logger *slog.Logger) *RealtimeDevice {
 	// TODO: Parse Realtime-specific configuration
 	return &RealtimeDevice{
 		config:    config,
@@ -105,6 +104,17 @@ func (r *RealtimeDevice) ClearAttendanceRecords() error {
 	return nil
 }
 
+// UnlockDoor energizes the door relay for the given duration
+func (r *RealtimeDevice) UnlockDoor(duration time.Duration) error {
+	if !r.connected {
+		return fmt.Errorf("device not connected")
+	}
+
+	// The door output of Realtime terminals is not supported, doors they read
+	// are unlocked through a relay (see UnlocksDoor)
+	return fmt.Errorf("door unlock not supported by Realtime devices")
+}
+
 // GetDeviceInfo gets device information
 func (r *RealtimeDevice) GetDeviceInfo() (*DeviceInfo, error) {
 	if !r.connected {
@@ -139,7 +149,7 @@ func (r *RealtimeDevice) SetDeviceTime(t time.Time) error {
 	}
 
 	// TODO: Implement Realtime set time
-	r.logger.WithField("time", t).Info("Realtime device time updated (placeholder)")
+	r.logger.Info("Realtime device time updated (placeholder)", "time", t)
 	return nil
 }
 
@@ -157,4 +167,4 @@ func (r *RealtimeDevice) SetDeviceTime(t time.Time) error {
 // - Multi-modal authentication (fingerprint, face, card, password)
 // - Real-time event push notifications
 // - Web-based configuration interface
-// - Support for multiple communication protocols
\ No newline at end of file
+// - Support for multiple communication protocols
@@ -2,39 +2,45 @@ package biometric
 
 import (
 	"fmt"
+	"log/slog"
 	"math/rand"
+	"sync"
 	"time"
-
-	"github.com/sirupsen/logrus"
 )
 
 // SimulatorDevice simulates a biometric device for testing
 type SimulatorDevice struct {
-	config           map[string]string
-	logger           *logrus.Logger
-	connected        bool
-	users            []DeviceUser
+	config            map[string]string
+	logger            *slog.Logger
+	connected         bool
+	users             []DeviceUser
+	templates         []FingerprintTemplate
 	attendanceRecords []AttendanceRecord
-	lastPoll         time.Time
+	lastPoll          time.Time
+	stop              chan struct{} // Stops the attendance generator on disconnect
+	mu                sync.Mutex    // Guards the device state shared with the attendance generator
 }
 
 // NewSimulatorDevice creates a new simulator device
-func NewSimulatorDevice(config map[string]string, logger *logrus.Logger) *SimulatorDevice {
+func NewSimulatorDevice(config map[string]string, logger *slog.Logger) *SimulatorDevice {
 	return &SimulatorDevice{
-		config:    config,
-		logger:    logger,
-		connected: false,
-		users:     []DeviceUser{},
+		config:            config,
+		logger:            logger,
+		connected:         false,
+		users:             []DeviceUser{},
 		attendanceRecords: []AttendanceRecord{},
-		lastPoll:  time.Now(),
+		lastPoll:          time.Now(),
 	}
 }
 
 // Connect connects to the simulator device
 func (s *SimulatorDevice) Connect() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	s.connected = true
 	s.logger.Info("Biometric simulator connected")
-	
+
 	// Pre-populate with some test users
 	s.users = []DeviceUser{
 		{
@@ -50,29 +56,43 @@ func (s *SimulatorDevice) Connect() error {
 			Privilege:      0,
 		},
 	}
-	
+
 	// Start generating random attendance records
-	go s.generateRandomAttendance()
-	
+	s.stop = make(chan struct{})
+	go s.generateRandomAttendance(s.stop)
+
 	return nil
 }
 
 // Disconnect disconnects from the simulator device
 func (s *SimulatorDevice) Disconnect() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	s.connected = false
+	if s.stop != nil {
+		close(s.stop)
+		s.stop = nil
+	}
 	s.logger.Info("Biometric simulator disconnected")
 	return nil
 }
 
 // IsConnected returns whether the device is connected
 func (s *SimulatorDevice) IsConnected() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	return s.connected
 }
 
 // GetStatus returns the device status
 func (s *SimulatorDevice) GetStatus() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	if s.connected {
-		return fmt.Sprintf("Simulator Connected - %d users, %d pending records", 
+		return fmt.Sprintf("Simulator Connected - %d users, %d pending records",
 			len(s.users), len(s.attendanceRecords))
 	}
 	return "Simulator Disconnected"
@@ -80,6 +100,9 @@ func (s *SimulatorDevice) GetStatus() string {
 
 // EnrollUser enrolls a user on the simulator device
 func (s *SimulatorDevice) EnrollUser(platformUserID string, deviceUserID int, name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	if !s.connected {
 		return fmt.Errorf("device not connected")
 	}
@@ -100,18 +123,39 @@ func (s *SimulatorDevice) EnrollUser(platformUserID string, deviceUserID int, na
 	}
 
 	s.users = append(s.users, user)
-	
-	s.logger.WithFields(logrus.Fields{
-		"platform_user_id": platformUserID,
-		"device_user_id":   deviceUserID,
-		"name":             name,
-	}).Info("User enrolled on simulator device")
 
+	s.logger.Info("User enrolled on simulator device", "platformUserId", platformUserID, "deviceUserId", deviceUserID, "name", name)
+
+	return nil
+}
+
+// WriteUser creates or replaces a user on the simulator device
+func (s *SimulatorDevice) WriteUser(user DeviceUser) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.connected {
+		return fmt.Errorf("device not connected")
+	}
+
+	for i, existing := range s.users {
+		if existing.DeviceUserID == user.DeviceUserID {
+			s.users[i] = user
+			s.logger.Info("User updated on simulator device", "deviceUserId", user.DeviceUserID, "disabled", user.Disabled)
+			return nil
+		}
+	}
+
+	s.users = append(s.users, user)
+	s.logger.Info("User written to simulator device", "platformUserId", user.PlatformUserID, "deviceUserId", user.DeviceUserID, "name", user.Name)
 	return nil
 }
 
 // DeleteUser deletes a user from the simulator device
 func (s *SimulatorDevice) DeleteUser(deviceUserID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	if !s.connected {
 		return fmt.Errorf("device not connected")
 	}
@@ -120,7 +164,8 @@ func (s *SimulatorDevice) DeleteUser(deviceUserID int) error {
 	for i, user := range s.users {
 		if user.DeviceUserID == deviceUserID {
 			s.users = append(s.users[:i], s.users[i+1:]...)
-			s.logger.WithField("device_user_id", deviceUserID).Info("User deleted from simulator device")
+			s.deleteTemplates(deviceUserID)
+			s.logger.Info("User deleted from simulator device", "deviceUserId", deviceUserID)
 			return nil
 		}
 	}
@@ -130,15 +175,72 @@ func (s *SimulatorDevice) DeleteUser(deviceUserID int) error {
 
 // GetUsers gets all users from the simulator device
 func (s *SimulatorDevice) GetUsers() ([]DeviceUser, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.connected {
+		return nil, fmt.Errorf("device not connected")
+	}
+
+	return append([]DeviceUser(nil), s.users...), nil
+}
+
+// GetTemplates gets the fingerprint templates stored on the simulator device
+func (s *SimulatorDevice) GetTemplates() ([]FingerprintTemplate, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	if !s.connected {
 		return nil, fmt.Errorf("device not connected")
 	}
 
-	return s.users, nil
+	return append([]FingerprintTemplate(nil), s.templates...), nil
+}
+
+// WriteTemplate stores a fingerprint template for a user on the simulator device
+func (s *SimulatorDevice) WriteTemplate(template FingerprintTemplate) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.connected {
+		return fmt.Errorf("device not connected")
+	}
+
+	for _, user := range s.users {
+		if user.DeviceUserID != template.DeviceUserID {
+			continue
+		}
+
+		for i, existing := range s.templates {
+			if existing.DeviceUserID == template.DeviceUserID && existing.FingerIndex == template.FingerIndex {
+				s.templates[i] = template
+				return nil
+			}
+		}
+		s.templates = append(s.templates, template)
+		s.logger.Info("Fingerprint template written to simulator device", "deviceUserId", template.DeviceUserID, "finger", template.FingerIndex)
+		return nil
+	}
+
+	return fmt.Errorf("user with device ID %d not found", template.DeviceUserID)
+}
+
+// deleteTemplates removes the templates of a deleted user
+func (s *SimulatorDevice) deleteTemplates(deviceUserID int) {
+	kept := s.templates[:0]
+	for _, template := range s.templates {
+		if template.DeviceUserID != deviceUserID {
+			kept = append(kept, template)
+		}
+	}
+	s.templates = kept
 }
 
 // GetNewAttendanceRecords gets new attendance records from the simulator device
 func (s *SimulatorDevice) GetNewAttendanceRecords() ([]AttendanceRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	if !s.connected {
 		return nil, fmt.Errorf("device not connected")
 	}
@@ -149,7 +251,7 @@ func (s *SimulatorDevice) GetNewAttendanceRecords() ([]AttendanceRecord, error)
 	s.attendanceRecords = []AttendanceRecord{}
 
 	if len(records) > 0 {
-		s.logger.WithField("count", len(records)).Info("Retrieved attendance records from simulator")
+		s.logger.Info("Retrieved attendance records from simulator", "count", len(records))
 	}
 
 	return records, nil
@@ -157,6 +259,9 @@ func (s *SimulatorDevice) GetNewAttendanceRecords() ([]AttendanceRecord, error)
 
 // ClearAttendanceRecords clears attendance records from the simulator device
 func (s *SimulatorDevice) ClearAttendanceRecords() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	if !s.connected {
 		return fmt.Errorf("device not connected")
 	}
@@ -166,8 +271,24 @@ func (s *SimulatorDevice) ClearAttendanceRecords() error {
 	return nil
 }
 
+// UnlockDoor simulates energizing the door relay
+func (s *SimulatorDevice) UnlockDoor(duration time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.connected {
+		return fmt.Errorf("device not connected")
+	}
+
+	s.logger.Info("Simulated door unlock on biometric device", "duration", duration)
+	return nil
+}
+
 // GetDeviceInfo gets device information
 func (s *SimulatorDevice) GetDeviceInfo() (*DeviceInfo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	if !s.connected {
 		return nil, fmt.Errorf("device not connected")
 	}
@@ -184,6 +305,9 @@ func (s *SimulatorDevice) GetDeviceInfo() (*DeviceInfo, error) {
 
 // GetDeviceTime gets the device time
 func (s *SimulatorDevice) GetDeviceTime() (time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	if !s.connected {
 		return time.Time{}, fmt.Errorf("device not connected")
 	}
@@ -193,55 +317,57 @@ func (s *SimulatorDevice) GetDeviceTime() (time.Time, error) {
 
 // SetDeviceTime sets the device time
 func (s *SimulatorDevice) SetDeviceTime(t time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	if !s.connected {
 		return fmt.Errorf("device not connected")
 	}
 
-	s.logger.WithField("time", t).Info("Simulator device time updated")
+	s.logger.Info("Simulator device time updated", "time", t)
 	return nil
 }
 
 // generateRandomAttendance generates random attendance records for testing
-func (s *SimulatorDevice) generateRandomAttendance() {
+func (s *SimulatorDevice) generateRandomAttendance(stop chan struct{}) {
 	ticker := time.NewTicker(30 * time.Second) // Generate record every 30 seconds
 	defer ticker.Stop()
 
 	for {
-		if !s.connected {
-			return
-		}
-
 		select {
+		case <-stop:
+			return
 		case <-ticker.C:
-			if len(s.users) == 0 {
-				continue
-			}
-
 			// 20% chance to generate an attendance record
-			if rand.Float32() > 0.2 {
-				continue
+			if rand.Float32() <= 0.2 {
+				s.addRandomAttendance()
 			}
+		}
+	}
+}
 
-			// Pick random user
-			user := s.users[rand.Intn(len(s.users))]
+// addRandomAttendance records an attendance punch for a random user
+func (s *SimulatorDevice) addRandomAttendance() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-			// Generate random attendance record
-			record := AttendanceRecord{
-				DeviceUserID: user.DeviceUserID,
-				Timestamp:    time.Now(),
-				Status:       rand.Intn(2), // 0=check-in, 1=check-out
-				VerifyMode:   1,            // 1=fingerprint
-				WorkCode:     0,
-			}
+	if len(s.users) == 0 {
+		return
+	}
 
-			s.attendanceRecords = append(s.attendanceRecords, record)
+	// Pick random user
+	user := s.users[rand.Intn(len(s.users))]
 
-			s.logger.WithFields(logrus.Fields{
-				"device_user_id": record.DeviceUserID,
-				"user_name":      user.Name,
-				"status":         record.Status,
-				"timestamp":      record.Timestamp,
-			}).Info("Generated simulated attendance record")
-		}
+	// Generate random attendance record
+	record := AttendanceRecord{
+		DeviceUserID: user.DeviceUserID,
+		Timestamp:    time.Now(),
+		Status:       rand.Intn(2), // 0=check-in, 1=check-out
+		VerifyMode:   1,            // 1=fingerprint
+		WorkCode:     0,
 	}
-}
\ No newline at end of file
+
+	s.attendanceRecords = append(s.attendanceRecords, record)
+
+	s.logger.Info("Generated simulated attendance record", "deviceUserId", record.DeviceUserID, "userName", user.Name, "status", record.Status, "timestamp", record.Timestamp)
+}
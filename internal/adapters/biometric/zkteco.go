@@ -1,44 +1,211 @@
 package biometric
 
 import (
+	"bytes"
+	"encoding/binary"
+	"errors"
 	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"strconv"
+	"strings"
 	"time"
+)
+
+// ZK protocol framing
+const (
+	zkTCPMagic1     = 0x5050
+	zkTCPMagic2     = 0x7d82
+	zkHeaderSize    = 8
+	zkTCPTopSize    = 8
+	zkMaxPacketSize = 64 * 1024 * 1024 // Sanity limit for a single TCP frame
+	zkTCPChunkSize  = 0xFFC0
+	zkUDPChunkSize  = 16 * 1024
+	zkUSHRTMax      = 65535
+
+	zkPrivilegeDisabled = 1 // Low privilege bit marks a user the terminal refuses to verify
 
-	"github.com/sirupsen/logrus"
+	zkTemplateHeaderSize = 6    // Size, storage slot, finger index and flag before each template
+	zkSendChunkSize      = 1024 // Largest data packet accepted when uploading a buffer
+
+	zkRealtimeEvents   = EF_ATTLOG | EF_VERIFY | EF_ALARM
+	zkMaxPendingEvents = 1000
 )
 
-// ZKTecoDevice handles ZKTeco biometric devices
+// errZKIdle is returned when no packet arrived within the wait period
+var errZKIdle = errors.New("no packet received")
+
+// ZKTecoDevice handles ZKTeco biometric devices (K40, F18, SpeedFace, etc.)
+// over the ZK binary protocol on TCP or UDP port 4370
 type ZKTecoDevice struct {
 	config    map[string]string
-	logger    *logrus.Logger
+	logger    *slog.Logger
 	connected bool
 	ipAddress string
 	port      int
 	password  string
+	protocol  string // tcp or udp
+	timeout   time.Duration
+	location  *time.Location
+
+	conn      net.Conn
+	sessionID uint16
+	replyID   uint16
+	chunkSize int
+
+	userPacketSize int // 28 for older firmware, 72 for newer
+	recordsRead    int // Attendance records already returned since the last clear
+	serialNumber   string
+
+	pendingEvents []*RealtimeEvent // Pushes received while waiting for a command reply
+}
+
+// zkPacket represents a single ZK protocol packet
+type zkPacket struct {
+	Command   uint16
+	Checksum  uint16
+	SessionID uint16
+	ReplyID   uint16
+	Data      []byte
+}
+
+// zkSizes holds the storage counters reported by CMD_GET_FREE_SIZES
+type zkSizes struct {
+	Users      int
+	Fingers    int
+	Records    int
+	FingersCap int
+	UsersCap   int
+	RecordsCap int
+}
+
+// zkUser is a user record as stored on the terminal
+type zkUser struct {
+	UID       uint16 // Internal storage slot
+	UserID    string // Enrollment number (PIN) shown on the terminal
+	Name      string
+	Privilege int
+	Password  string
+	Card      uint32
+	GroupID   string
 }
 
 // NewZKTecoDevice creates a new ZKTeco device
-func NewZKTecoDevice(config map[string]string, logger *logrus.Logger) *ZKTecoDevice {
-	// TODO: Parse ZKTeco-specific configuration
+func NewZKTecoDevice(config map[string]string, logger *slog.Logger) *ZKTecoDevice {
+	ipAddress := config["ip_address"]
+	if ipAddress == "" {
+		ipAddress = "192.168.1.201"
+	}
+
+	port, _ := strconv.Atoi(config["port"])
+	if port == 0 {
+		port = 4370 // ZKTeco default port
+	}
+
+	password := config["password"]
+	if password == "" {
+		password = "0"
+	}
+
+	protocol := strings.ToLower(config["protocol"])
+	if protocol != "udp" {
+		protocol = "tcp"
+	}
+
+	timeout := 10 * time.Second
+	if seconds, err := strconv.Atoi(config["timeout"]); err == nil && seconds > 0 {
+		timeout = time.Duration(seconds) * time.Second
+	}
+
+	location := time.Local
+	if tz := config["timezone"]; tz != "" {
+		if loc, err := time.LoadLocation(tz); err == nil {
+			location = loc
+		} else {
+			logger.Warn("Invalid ZKTeco timezone, using local time", "timezone", tz, "error", err)
+		}
+	}
+
+	chunkSize := zkTCPChunkSize
+	if protocol == "udp" {
+		chunkSize = zkUDPChunkSize
+	}
+
 	return &ZKTecoDevice{
 		config:    config,
 		logger:    logger,
 		connected: false,
+		ipAddress: ipAddress,
+		port:      port,
+		password:  password,
+		protocol:  protocol,
+		timeout:   timeout,
+		location:  location,
+		chunkSize: chunkSize,
 	}
 }
 
-// Connect connects to the ZKTeco device
+// Connect opens a session with the terminal, authenticating with the comm key if required
 func (z *ZKTecoDevice) Connect() error {
-	// TODO: Implement ZKTeco connection protocol
+	z.logger.Info("Connecting to ZKTeco device", "ip", z.ipAddress, "port", z.port, "protocol", z.protocol)
+
+	conn, err := net.DialTimeout(z.protocol, net.JoinHostPort(z.ipAddress, strconv.Itoa(z.port)), z.timeout)
+	if err != nil {
+		return fmt.Errorf("failed to connect to ZKTeco device: %w", err)
+	}
+
+	z.conn = conn
+	z.sessionID = 0
+	z.replyID = zkUSHRTMax - 1 // First packet goes out with reply ID 0
+
+	response, err := z.sendCommand(CMD_CONNECT, nil)
+	if err != nil {
+		z.closeConn()
+		return fmt.Errorf("failed to send connect command: %w", err)
+	}
+
+	z.sessionID = response.SessionID
+
+	if response.Command == CMD_ACK_UNAUTH {
+		commKey, err := strconv.ParseUint(z.password, 10, 32)
+		if err != nil {
+			z.closeConn()
+			return fmt.Errorf("invalid ZKTeco comm key: %w", err)
+		}
+
+		response, err = z.sendCommand(CMD_AUTH, makeCommKey(uint32(commKey), z.sessionID, 50))
+		if err != nil {
+			z.closeConn()
+			return fmt.Errorf("failed to send auth command: %w", err)
+		}
+		if response.Command != CMD_ACK_OK {
+			z.closeConn()
+			return fmt.Errorf("ZKTeco authentication failed with response: %d", response.Command)
+		}
+	} else if response.Command != CMD_ACK_OK {
+		z.closeConn()
+		return fmt.Errorf("connect command failed with response: %d", response.Command)
+	}
+
 	z.connected = true
-	z.logger.Info("ZKTeco device connected (placeholder)")
+	z.logger.Info("Connected to ZKTeco device successfully", "sessionId", z.sessionID)
+
 	return nil
 }
 
-// Disconnect disconnects from the ZKTeco device
+// Disconnect ends the session and closes the connection
 func (z *ZKTecoDevice) Disconnect() error {
-	z.connected = false
+	if !z.connected {
+		return nil
+	}
+
+	if _, err := z.sendCommand(CMD_EXIT, nil); err != nil {
+		z.logger.Debug("ZKTeco exit command failed", "error", err)
+	}
+	z.closeConn()
 	z.logger.Info("ZKTeco device disconnected")
+
 	return nil
 }
 
@@ -50,20 +217,93 @@ func (z *ZKTecoDevice) IsConnected() bool {
 // GetStatus returns the device status
 func (z *ZKTecoDevice) GetStatus() string {
 	if z.connected {
-		return "ZKTeco Connected (placeholder)"
+		return fmt.Sprintf("Connected to %s:%d via %s (Session: %d)", z.ipAddress, z.port, z.protocol, z.sessionID)
 	}
 	return "ZKTeco Disconnected"
 }
 
-// EnrollUser enrolls a user on the ZKTeco device
+// EnrollUser writes a user record to the terminal; fingerprints are enrolled on the device itself
 func (z *ZKTecoDevice) EnrollUser(platformUserID string, deviceUserID int, name string) error {
 	if !z.connected {
 		return fmt.Errorf("device not connected")
 	}
 
-	// TODO: Implement ZKTeco user enrollment
-	z.logger.Info("ZKTeco user enrollment not yet implemented")
-	return fmt.Errorf("ZKTeco user enrollment not yet implemented")
+	uid, err := z.storeUser(deviceUserID, func(user *zkUser) {
+		user.Name = name
+	})
+	if err != nil {
+		return err
+	}
+
+	z.logger.Info("User enrolled on ZKTeco device", "platformUserId", platformUserID, "deviceUserId", deviceUserID, "uid", uid)
+	return nil
+}
+
+// WriteUser creates or replaces a user record with its card number, privilege
+// and enabled state. Fingerprint templates already on the device are kept.
+func (z *ZKTecoDevice) WriteUser(deviceUser DeviceUser) error {
+	if !z.connected {
+		return fmt.Errorf("device not connected")
+	}
+
+	var card uint64
+	if deviceUser.CardNumber != "" {
+		var err error
+		card, err = strconv.ParseUint(deviceUser.CardNumber, 10, 32)
+		if err != nil {
+			return fmt.Errorf("card number must be numeric on ZKTeco devices: %w", err)
+		}
+	}
+
+	uid, err := z.storeUser(deviceUser.DeviceUserID, func(user *zkUser) {
+		user.Name = deviceUser.Name
+		user.Card = uint32(card)
+		user.Privilege = deviceUser.Privilege &^ zkPrivilegeDisabled
+		if deviceUser.Disabled {
+			user.Privilege |= zkPrivilegeDisabled
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	z.logger.Info("User written to ZKTeco device", "platformUserId", deviceUser.PlatformUserID, "deviceUserId", deviceUser.DeviceUserID, "uid", uid, "disabled", deviceUser.Disabled)
+	return nil
+}
+
+// storeUser writes the user with the given enrollment number after applying the
+// changes, reusing the storage slot and settings of an existing user
+func (z *ZKTecoDevice) storeUser(deviceUserID int, apply func(user *zkUser)) (uint16, error) {
+	users, err := z.readUsers()
+	if err != nil {
+		return 0, fmt.Errorf("failed to read existing users: %w", err)
+	}
+
+	userID := strconv.Itoa(deviceUserID)
+	user := zkUser{UserID: userID}
+
+	// Reuse the storage slot if the enrollment number already exists
+	var maxUID uint16
+	for _, existing := range users {
+		if existing.UserID == userID {
+			user = existing
+		}
+		if existing.UID > maxUID {
+			maxUID = existing.UID
+		}
+	}
+	if user.UID == 0 {
+		if maxUID == zkUSHRTMax {
+			return 0, fmt.Errorf("no free user slot on device")
+		}
+		user.UID = maxUID + 1
+	}
+
+	apply(&user)
+	if err := z.writeUser(user); err != nil {
+		return 0, err
+	}
+	return user.UID, nil
 }
 
 // DeleteUser deletes a user from the ZKTeco device
@@ -72,8 +312,31 @@ func (z *ZKTecoDevice) DeleteUser(deviceUserID int) error {
 		return fmt.Errorf("device not connected")
 	}
 
-	// TODO: Implement ZKTeco user deletion
-	return fmt.Errorf("ZKTeco user deletion not yet implemented")
+	users, err := z.readUsers()
+	if err != nil {
+		return fmt.Errorf("failed to read existing users: %w", err)
+	}
+
+	userID := strconv.Itoa(deviceUserID)
+	for _, user := range users {
+		if user.UserID != userID {
+			continue
+		}
+
+		data := make([]byte, 2)
+		binary.LittleEndian.PutUint16(data, user.UID)
+		if err := z.expectOK(CMD_DELETE_USER, data, "delete user"); err != nil {
+			return err
+		}
+		if err := z.refreshData(); err != nil {
+			return err
+		}
+
+		z.logger.Info("User deleted from ZKTeco device", "deviceUserId", deviceUserID, "uid", user.UID)
+		return nil
+	}
+
+	return fmt.Errorf("user with device ID %d not found", deviceUserID)
 }
 
 // GetUsers gets all users from the ZKTeco device
@@ -82,18 +345,172 @@ func (z *ZKTecoDevice) GetUsers() ([]DeviceUser, error) {
 		return nil, fmt.Errorf("device not connected")
 	}
 
-	// TODO: Implement ZKTeco get users
-	return []DeviceUser{}, fmt.Errorf("ZKTeco get users not yet implemented")
+	users, err := z.readUsers()
+	if err != nil {
+		return nil, err
+	}
+
+	deviceUsers := make([]DeviceUser, 0, len(users))
+	for _, user := range users {
+		deviceUserID, err := strconv.Atoi(user.UserID)
+		if err != nil {
+			z.logger.Warn("Skipping ZKTeco user with non-numeric enrollment number", "userId", user.UserID, "uid", user.UID)
+			continue
+		}
+
+		card := ""
+		if user.Card != 0 {
+			card = strconv.FormatUint(uint64(user.Card), 10)
+		}
+
+		deviceUsers = append(deviceUsers, DeviceUser{
+			DeviceUserID: deviceUserID,
+			Name:         user.Name,
+			Privilege:    user.Privilege &^ zkPrivilegeDisabled,
+			Password:     user.Password,
+			CardNumber:   card,
+			Disabled:     user.Privilege&zkPrivilegeDisabled != 0,
+		})
+	}
+
+	return deviceUsers, nil
+}
+
+// GetTemplates reads every fingerprint template stored on the terminal
+func (z *ZKTecoDevice) GetTemplates() ([]FingerprintTemplate, error) {
+	if !z.connected {
+		return nil, fmt.Errorf("device not connected")
+	}
+
+	sizes, err := z.readSizes()
+	if err != nil {
+		return nil, err
+	}
+	if sizes.Fingers == 0 {
+		return nil, nil
+	}
+
+	// Templates only carry the storage slot, so resolve enrollment numbers up front
+	users, err := z.readUsers()
+	if err != nil {
+		return nil, err
+	}
+	usersByUID := make(map[uint16]string, len(users))
+	for _, user := range users {
+		usersByUID[user.UID] = user.UserID
+	}
+
+	data, err := z.readWithBuffer(CMD_DB_RRQ, FCT_FINGERTMP, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read templates: %w", err)
+	}
+	if len(data) < 4 {
+		return nil, fmt.Errorf("template data too short: %d bytes", len(data))
+	}
+
+	totalSize := int(binary.LittleEndian.Uint32(data[:4]))
+	data = data[4:]
+	if totalSize > len(data) {
+		return nil, fmt.Errorf("template data truncated: expected %d bytes, got %d", totalSize, len(data))
+	}
+
+	templates := make([]FingerprintTemplate, 0, sizes.Fingers)
+	for offset := 0; offset+zkTemplateHeaderSize <= totalSize; {
+		size := int(binary.LittleEndian.Uint16(data[offset : offset+2]))
+		if size <= zkTemplateHeaderSize || offset+size > totalSize {
+			return nil, fmt.Errorf("invalid template record size %d at offset %d", size, offset)
+		}
+
+		uid := binary.LittleEndian.Uint16(data[offset+2 : offset+4])
+		deviceUserID, err := strconv.Atoi(usersByUID[uid])
+		if err != nil {
+			z.logger.Warn("Skipping template without a numeric enrollment number", "uid", uid)
+			offset += size
+			continue
+		}
+
+		templates = append(templates, FingerprintTemplate{
+			DeviceUserID: deviceUserID,
+			FingerIndex:  int(data[offset+4]),
+			Flag:         int(data[offset+5]),
+			Data:         append([]byte(nil), data[offset+zkTemplateHeaderSize:offset+size]...),
+		})
+		offset += size
+	}
+
+	return templates, nil
+}
+
+// WriteTemplate uploads a fingerprint template for an existing user, replacing
+// the template already stored for the same finger
+func (z *ZKTecoDevice) WriteTemplate(template FingerprintTemplate) error {
+	if !z.connected {
+		return fmt.Errorf("device not connected")
+	}
+	if template.FingerIndex < 0 || template.FingerIndex > 9 {
+		return fmt.Errorf("invalid finger index: %d", template.FingerIndex)
+	}
+	if len(template.Data) == 0 || len(template.Data) > zkUSHRTMax {
+		return fmt.Errorf("invalid template size: %d bytes", len(template.Data))
+	}
+
+	users, err := z.readUsers()
+	if err != nil {
+		return fmt.Errorf("failed to read existing users: %w", err)
+	}
+
+	userID := strconv.Itoa(template.DeviceUserID)
+	for _, user := range users {
+		if user.UserID != userID {
+			continue
+		}
+
+		if err := z.sendWithBuffer(template.Data); err != nil {
+			return err
+		}
+
+		request := make([]byte, 6)
+		binary.LittleEndian.PutUint16(request[0:2], user.UID)
+		request[2] = byte(template.FingerIndex)
+		request[3] = byte(template.Flag)
+		binary.LittleEndian.PutUint16(request[4:6], uint16(len(template.Data)))
+		if err := z.expectOK(CMD_TMP_WRITE, request, "write template"); err != nil {
+			return err
+		}
+		if err := z.refreshData(); err != nil {
+			return err
+		}
+
+		z.logger.Info("Fingerprint template written to ZKTeco device", "deviceUserId", template.DeviceUserID, "finger", template.FingerIndex, "uid", user.UID)
+		return nil
+	}
+
+	return fmt.Errorf("user with device ID %d not found", template.DeviceUserID)
 }
 
-// GetNewAttendanceRecords gets new attendance records from the ZKTeco device
+// GetNewAttendanceRecords returns attendance records stored since the last call or clear
 func (z *ZKTecoDevice) GetNewAttendanceRecords() ([]AttendanceRecord, error) {
 	if !z.connected {
 		return nil, fmt.Errorf("device not connected")
 	}
 
-	// TODO: Implement ZKTeco attendance record retrieval
-	return []AttendanceRecord{}, nil
+	records, err := z.readAttendance()
+	if err != nil {
+		return nil, err
+	}
+
+	// The log was cleared on the device by someone else; start over
+	if len(records) < z.recordsRead {
+		z.recordsRead = 0
+	}
+
+	newRecords := records[z.recordsRead:]
+	z.recordsRead = len(records)
+
+	if len(newRecords) > 0 {
+		z.logger.Info("Retrieved attendance records from ZKTeco device", "count", len(newRecords))
+	}
+	return newRecords, nil
 }
 
 // ClearAttendanceRecords clears attendance records from the ZKTeco device
@@ -102,7 +519,60 @@ func (z *ZKTecoDevice) ClearAttendanceRecords() error {
 		return fmt.Errorf("device not connected")
 	}
 
-	// TODO: Implement ZKTeco clear attendance records
+	if err := z.expectOK(CMD_CLEAR_ATTLOG, nil, "clear attendance log"); err != nil {
+		return err
+	}
+
+	z.recordsRead = 0
+	z.logger.Info("Cleared attendance records from ZKTeco device")
+	return nil
+}
+
+// DrainAttendanceRecords reads new attendance records and clears the log with the
+// device disabled, so no member can punch between the read and the clear
+func (z *ZKTecoDevice) DrainAttendanceRecords() ([]AttendanceRecord, error) {
+	if !z.connected {
+		return nil, fmt.Errorf("device not connected")
+	}
+
+	if err := z.expectOK(CMD_DISABLEDEVICE, nil, "disable device"); err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := z.expectOK(CMD_ENABLEDEVICE, nil, "enable device"); err != nil {
+			z.logger.Error("Failed to re-enable ZKTeco device after reading attendance", "error", err)
+		}
+	}()
+
+	records, err := z.GetNewAttendanceRecords()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(records) > 0 {
+		// Records left on the device are skipped by the read position next time
+		if err := z.ClearAttendanceRecords(); err != nil {
+			z.logger.Warn("Failed to clear attendance records from ZKTeco device", "error", err)
+		}
+	}
+	return records, nil
+}
+
+// UnlockDoor energizes the door relay for the given duration
+func (z *ZKTecoDevice) UnlockDoor(duration time.Duration) error {
+	if !z.connected {
+		return fmt.Errorf("device not connected")
+	}
+
+	// Unlock duration is expressed in units of 100ms
+	data := make([]byte, 4)
+	binary.LittleEndian.PutUint32(data, uint32(duration/(100*time.Millisecond)))
+
+	if err := z.expectOK(CMD_UNLOCK, data, "unlock"); err != nil {
+		return err
+	}
+
+	z.logger.Info("Door unlocked via ZKTeco device", "duration", duration)
 	return nil
 }
 
@@ -112,14 +582,36 @@ func (z *ZKTecoDevice) GetDeviceInfo() (*DeviceInfo, error) {
 		return nil, fmt.Errorf("device not connected")
 	}
 
-	// TODO: Implement ZKTeco device info retrieval
+	sizes, err := z.readSizes()
+	if err != nil {
+		return nil, err
+	}
+
+	if z.serialNumber == "" {
+		if serial, err := z.readOption("~SerialNumber"); err == nil {
+			z.serialNumber = serial
+		} else {
+			z.logger.Debug("Failed to read ZKTeco serial number", "error", err)
+		}
+	}
+
+	model, err := z.readOption("~DeviceName")
+	if err != nil || model == "" {
+		model = "ZKTeco Device"
+	}
+
+	firmware := "Unknown"
+	if response, err := z.sendCommand(CMD_GET_VERSION, nil); err == nil && response.Command == CMD_ACK_OK {
+		firmware = trimNull(response.Data)
+	}
+
 	return &DeviceInfo{
-		SerialNumber:    "ZK-001",
-		DeviceModel:     "ZKTeco Device",
-		FirmwareVer:     "Unknown",
-		UserCount:       0,
-		AttendanceCount: 0,
-		FingerCapacity:  1000,
+		SerialNumber:    z.serialNumber,
+		DeviceModel:     model,
+		FirmwareVer:     firmware,
+		UserCount:       sizes.Users,
+		AttendanceCount: sizes.Records,
+		FingerCapacity:  sizes.FingersCap,
 	}, nil
 }
 
@@ -129,8 +621,16 @@ func (z *ZKTecoDevice) GetDeviceTime() (time.Time, error) {
 		return time.Time{}, fmt.Errorf("device not connected")
 	}
 
-	// TODO: Implement ZKTeco get time
-	return time.Now(), nil
+	response, err := z.sendCommand(CMD_GET_TIME, nil)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to send get time command: %w", err)
+	}
+
+	if response.Command != CMD_ACK_OK || len(response.Data) < 4 {
+		return time.Time{}, fmt.Errorf("get time command failed with response: %d", response.Command)
+	}
+
+	return decodeZKTime(binary.LittleEndian.Uint32(response.Data[:4]), z.location), nil
 }
 
 // SetDeviceTime sets the device time
@@ -139,24 +639,791 @@ func (z *ZKTecoDevice) SetDeviceTime(t time.Time) error {
 		return fmt.Errorf("device not connected")
 	}
 
-	// TODO: Implement ZKTeco set time
-	z.logger.WithField("time", t).Info("ZKTeco device time updated (placeholder)")
+	data := make([]byte, 4)
+	binary.LittleEndian.PutUint32(data, encodeZKTime(t.In(z.location)))
+
+	if err := z.expectOK(CMD_SET_TIME, data, "set time"); err != nil {
+		return err
+	}
+
+	z.logger.Info("ZKTeco device time updated", "time", t)
+	return nil
+}
+
+// RegisterRealtimeEvents subscribes the session to attendance, verification and alarm pushes
+func (z *ZKTecoDevice) RegisterRealtimeEvents() error {
+	if !z.connected {
+		return fmt.Errorf("device not connected")
+	}
+
+	data := make([]byte, 4)
+	binary.LittleEndian.PutUint32(data, zkRealtimeEvents)
+
+	if err := z.expectOK(CMD_REG_EVENT, data, "register events"); err != nil {
+		return err
+	}
+
+	z.logger.Info("Registered for ZKTeco real-time events", "flags", zkRealtimeEvents)
+	return nil
+}
+
+// ReadRealtimeEvent waits up to timeout for the next pushed event
+func (z *ZKTecoDevice) ReadRealtimeEvent(timeout time.Duration) (*RealtimeEvent, error) {
+	if !z.connected {
+		return nil, fmt.Errorf("device not connected")
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		if len(z.pendingEvents) > 0 {
+			event := z.pendingEvents[0]
+			z.pendingEvents = z.pendingEvents[1:]
+			return event, nil
+		}
+
+		wait := time.Until(deadline)
+		if wait <= 0 {
+			return nil, nil
+		}
+
+		packet, err := z.readPacketWithin(wait)
+		if err == errZKIdle {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		// Late replies to earlier commands are of no interest here
+		if packet.Command == CMD_REG_EVENT {
+			z.queueRealtimeEvents(packet)
+		}
+	}
+}
+
+// Keepalive checks the session is still alive by reading the device clock
+func (z *ZKTecoDevice) Keepalive() error {
+	if !z.connected {
+		return fmt.Errorf("device not connected")
+	}
+
+	return z.expectOK(CMD_GET_TIME, nil, "keepalive")
+}
+
+// queueRealtimeEvents acknowledges a pushed event packet and queues the events it carries
+func (z *ZKTecoDevice) queueRealtimeEvents(packet *zkPacket) {
+	// The device expects every push to be acknowledged before it sends the next one
+	ack := encodeZKPacket(CMD_ACK_OK, z.sessionID, packet.ReplyID, nil)
+	if z.protocol == "tcp" {
+		ack = wrapZKTCP(ack)
+	}
+	z.conn.SetWriteDeadline(time.Now().Add(z.timeout))
+	if _, err := z.conn.Write(ack); err != nil {
+		z.logger.Warn("Failed to acknowledge ZKTeco event", "error", err)
+	}
+
+	// The event flag is carried in the session field of pushed packets
+	events := parseZKRealtimeEvents(packet.SessionID, packet.Data, z.location)
+	for _, event := range events {
+		if len(z.pendingEvents) >= zkMaxPendingEvents {
+			// Attendance is recovered by the reconciliation poll; drop the oldest push
+			z.logger.Warn("ZKTeco event queue full, dropping oldest event")
+			z.pendingEvents = z.pendingEvents[1:]
+		}
+		z.pendingEvents = append(z.pendingEvents, event)
+	}
+}
+
+// readUsers reads the user table from the terminal
+func (z *ZKTecoDevice) readUsers() ([]zkUser, error) {
+	sizes, err := z.readSizes()
+	if err != nil {
+		return nil, err
+	}
+	if sizes.Users == 0 {
+		return nil, nil
+	}
+
+	data, err := z.readWithBuffer(CMD_USERTEMP_RRQ, FCT_USER, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read users: %w", err)
+	}
+	if len(data) < 4 {
+		return nil, fmt.Errorf("user data too short: %d bytes", len(data))
+	}
+
+	totalSize := int(binary.LittleEndian.Uint32(data[:4]))
+	data = data[4:]
+	if totalSize > len(data) {
+		return nil, fmt.Errorf("user data truncated: expected %d bytes, got %d", totalSize, len(data))
+	}
+
+	packetSize := totalSize / sizes.Users
+	if packetSize != 28 && packetSize != 72 {
+		return nil, fmt.Errorf("unsupported user record size: %d", packetSize)
+	}
+	z.userPacketSize = packetSize
+
+	users := make([]zkUser, 0, sizes.Users)
+	for offset := 0; offset+packetSize <= totalSize; offset += packetSize {
+		users = append(users, parseZKUser(data[offset:offset+packetSize]))
+	}
+
+	return users, nil
+}
+
+// writeUser writes a single user record in the terminal's record format
+func (z *ZKTecoDevice) writeUser(user zkUser) error {
+	var data []byte
+	if z.userPacketSize == 28 {
+		userID, err := strconv.ParseUint(user.UserID, 10, 32)
+		if err != nil {
+			return fmt.Errorf("enrollment number must be numeric on this firmware: %w", err)
+		}
+		groupID, _ := strconv.Atoi(user.GroupID)
+
+		data = make([]byte, 28)
+		binary.LittleEndian.PutUint16(data[0:2], user.UID)
+		data[2] = byte(user.Privilege)
+		copy(data[3:8], user.Password)
+		copy(data[8:16], user.Name)
+		binary.LittleEndian.PutUint32(data[16:20], user.Card)
+		data[21] = byte(groupID)
+		binary.LittleEndian.PutUint16(data[22:24], 0) // Timezone
+		binary.LittleEndian.PutUint32(data[24:28], uint32(userID))
+	} else {
+		groupID := user.GroupID
+		if groupID == "" {
+			groupID = "1"
+		}
+
+		data = make([]byte, 72)
+		binary.LittleEndian.PutUint16(data[0:2], user.UID)
+		data[2] = byte(user.Privilege)
+		copy(data[3:11], user.Password)
+		copy(data[11:35], user.Name)
+		binary.LittleEndian.PutUint32(data[35:39], user.Card)
+		copy(data[40:47], groupID)
+		copy(data[48:72], user.UserID)
+	}
+
+	if err := z.expectOK(CMD_USER_WRQ, data, "write user"); err != nil {
+		return err
+	}
+	return z.refreshData()
+}
+
+// readAttendance reads the full attendance log from the terminal
+func (z *ZKTecoDevice) readAttendance() ([]AttendanceRecord, error) {
+	sizes, err := z.readSizes()
+	if err != nil {
+		return nil, err
+	}
+	if sizes.Records == 0 {
+		return nil, nil
+	}
+
+	// Short records only carry the storage slot, so resolve enrollment numbers up front
+	var usersByUID map[uint16]string
+
+	data, err := z.readWithBuffer(CMD_ATTLOG_RRQ, 0, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read attendance log: %w", err)
+	}
+	if len(data) < 4 {
+		return nil, fmt.Errorf("attendance data too short: %d bytes", len(data))
+	}
+
+	totalSize := int(binary.LittleEndian.Uint32(data[:4]))
+	data = data[4:]
+	if totalSize > len(data) {
+		return nil, fmt.Errorf("attendance data truncated: expected %d bytes, got %d", totalSize, len(data))
+	}
+
+	recordSize := totalSize / sizes.Records
+	if recordSize != 8 && recordSize != 16 && recordSize != 40 {
+		return nil, fmt.Errorf("unsupported attendance record size: %d", recordSize)
+	}
+
+	if recordSize == 8 {
+		users, err := z.readUsers()
+		if err != nil {
+			return nil, err
+		}
+		usersByUID = make(map[uint16]string, len(users))
+		for _, user := range users {
+			usersByUID[user.UID] = user.UserID
+		}
+	}
+
+	records := make([]AttendanceRecord, 0, sizes.Records)
+	for offset := 0; offset+recordSize <= totalSize; offset += recordSize {
+		userID, record := parseZKAttendance(data[offset:offset+recordSize], z.location)
+		if recordSize == 8 {
+			uid := binary.LittleEndian.Uint16(data[offset : offset+2])
+			if resolved, ok := usersByUID[uid]; ok {
+				userID = resolved
+			}
+		}
+
+		deviceUserID, err := strconv.Atoi(userID)
+		if err != nil {
+			z.logger.Warn("Skipping attendance record with non-numeric enrollment number", "userId", userID)
+			continue
+		}
+		record.DeviceUserID = deviceUserID
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+// readSizes reads storage counters from the terminal
+func (z *ZKTecoDevice) readSizes() (*zkSizes, error) {
+	response, err := z.sendCommand(CMD_GET_FREE_SIZES, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send get sizes command: %w", err)
+	}
+	if response.Command != CMD_ACK_OK || len(response.Data) < 80 {
+		return nil, fmt.Errorf("get sizes command failed with response: %d", response.Command)
+	}
+
+	field := func(i int) int {
+		return int(int32(binary.LittleEndian.Uint32(response.Data[i*4 : i*4+4])))
+	}
+
+	return &zkSizes{
+		Users:      field(4),
+		Fingers:    field(6),
+		Records:    field(8),
+		FingersCap: field(14),
+		UsersCap:   field(15),
+		RecordsCap: field(16),
+	}, nil
+}
+
+// readOption reads a "~Key=Value" device option
+func (z *ZKTecoDevice) readOption(key string) (string, error) {
+	response, err := z.sendCommand(CMD_OPTIONS_RRQ, append([]byte(key), 0))
+	if err != nil {
+		return "", fmt.Errorf("failed to read option %s: %w", key, err)
+	}
+	if response.Command != CMD_ACK_OK {
+		return "", fmt.Errorf("read option %s failed with response: %d", key, response.Command)
+	}
+
+	value := trimNull(response.Data)
+	if idx := strings.Index(value, "="); idx >= 0 {
+		value = value[idx+1:]
+	}
+	return value, nil
+}
+
+// refreshData asks the terminal to reload its user tables after a write
+func (z *ZKTecoDevice) refreshData() error {
+	return z.expectOK(CMD_REFRESHDATA, nil, "refresh data")
+}
+
+// readWithBuffer reads a large table using the prepare/read/free buffer sequence
+func (z *ZKTecoDevice) readWithBuffer(command uint16, fct, ext uint32) ([]byte, error) {
+	request := make([]byte, 11)
+	request[0] = 1
+	binary.LittleEndian.PutUint16(request[1:3], command)
+	binary.LittleEndian.PutUint32(request[3:7], fct)
+	binary.LittleEndian.PutUint32(request[7:11], ext)
+
+	response, err := z.sendCommand(CMD_PREPARE_BUFFER, request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare buffer: %w", err)
+	}
+
+	switch response.Command {
+	case CMD_DATA:
+		// Small tables are returned inline
+		return response.Data, nil
+	case CMD_ACK_OK:
+	default:
+		return nil, fmt.Errorf("prepare buffer failed with response: %d", response.Command)
+	}
+
+	if len(response.Data) < 5 {
+		return nil, fmt.Errorf("prepare buffer response too short: %d bytes", len(response.Data))
+	}
+	size := int(binary.LittleEndian.Uint32(response.Data[1:5]))
+
+	data := make([]byte, 0, size)
+	for start := 0; start < size; start += z.chunkSize {
+		chunkSize := z.chunkSize
+		if remaining := size - start; remaining < chunkSize {
+			chunkSize = remaining
+		}
+
+		chunk, err := z.readChunk(start, chunkSize)
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, chunk...)
+	}
+
+	if err := z.expectOK(CMD_FREE_DATA, nil, "free data"); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// sendWithBuffer uploads data to the terminal's receive buffer for a following write command
+func (z *ZKTecoDevice) sendWithBuffer(data []byte) error {
+	if err := z.expectOK(CMD_FREE_DATA, nil, "free data"); err != nil {
+		return err
+	}
+
+	size := make([]byte, 4)
+	binary.LittleEndian.PutUint32(size, uint32(len(data)))
+	if err := z.expectOK(CMD_PREPARE_DATA, size, "prepare data"); err != nil {
+		return err
+	}
+
+	for start := 0; start < len(data); start += zkSendChunkSize {
+		end := start + zkSendChunkSize
+		if end > len(data) {
+			end = len(data)
+		}
+		if err := z.expectOK(CMD_DATA, data[start:end], "send data"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// readChunk reads a single chunk of a prepared buffer
+func (z *ZKTecoDevice) readChunk(start, size int) ([]byte, error) {
+	request := make([]byte, 8)
+	binary.LittleEndian.PutUint32(request[0:4], uint32(start))
+	binary.LittleEndian.PutUint32(request[4:8], uint32(size))
+
+	response, err := z.sendCommand(CMD_READ_BUFFER, request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read buffer chunk at %d: %w", start, err)
+	}
+
+	switch response.Command {
+	case CMD_DATA:
+		return response.Data, nil
+	case CMD_PREPARE_DATA:
+		// The chunk follows as one or more data packets terminated by an ACK
+		if len(response.Data) < 4 {
+			return nil, fmt.Errorf("prepare data response too short")
+		}
+		expected := int(binary.LittleEndian.Uint32(response.Data[:4]))
+
+		chunk := make([]byte, 0, expected)
+		for {
+			packet, err := z.readPacket()
+			if err != nil {
+				return nil, fmt.Errorf("failed to read chunk data: %w", err)
+			}
+
+			switch packet.Command {
+			case CMD_DATA:
+				chunk = append(chunk, packet.Data...)
+			case CMD_ACK_OK:
+				if len(chunk) != expected {
+					return nil, fmt.Errorf("chunk size mismatch: expected %d bytes, got %d", expected, len(chunk))
+				}
+				return chunk, nil
+			default:
+				return nil, fmt.Errorf("unexpected packet %d while reading chunk", packet.Command)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("read buffer failed with response: %d", response.Command)
+	}
+}
+
+// expectOK sends a command and requires an ACK_OK response
+func (z *ZKTecoDevice) expectOK(command uint16, data []byte, action string) error {
+	response, err := z.sendCommand(command, data)
+	if err != nil {
+		return fmt.Errorf("failed to send %s command: %w", action, err)
+	}
+	if response.Command != CMD_ACK_OK {
+		return fmt.Errorf("%s command failed with response: %d", action, response.Command)
+	}
 	return nil
 }
 
-// Note: ZKTeco devices typically use their own proprietary protocol
-// Popular libraries for ZKTeco integration:
-// - pyzk (Python)
-// - zklib (Node.js)
-// - Custom TCP/UDP protocol implementation
-//
-// Common ZKTeco models and their protocols:
-// - F18, F19: TCP/IP with proprietary protocol
-// - SpeedFace: Advanced TCP/IP protocol
-// - K40, K50: Standard ZKTeco protocol
-//
-// Integration steps:
-// 1. Establish TCP connection to device
-// 2. Send authentication command
-// 3. Use specific commands for user management and attendance retrieval
-// 4. Handle device-specific data formats
\ No newline at end of file
+// sendCommand sends a command and reads its response
+func (z *ZKTecoDevice) sendCommand(command uint16, data []byte) (*zkPacket, error) {
+	if z.conn == nil {
+		return nil, fmt.Errorf("connection not established")
+	}
+
+	z.replyID++
+	if z.replyID >= zkUSHRTMax {
+		z.replyID -= zkUSHRTMax
+	}
+
+	packet := encodeZKPacket(command, z.sessionID, z.replyID, data)
+	if z.protocol == "tcp" {
+		packet = wrapZKTCP(packet)
+	}
+
+	z.conn.SetWriteDeadline(time.Now().Add(z.timeout))
+	if _, err := z.conn.Write(packet); err != nil {
+		z.handleConnError()
+		return nil, err
+	}
+
+	for {
+		response, err := z.readPacket()
+		if err != nil {
+			return nil, err
+		}
+
+		// Real-time event pushes are not replies to this command; keep them for the event reader
+		if response.Command == CMD_REG_EVENT {
+			z.queueRealtimeEvents(response)
+			continue
+		}
+
+		return response, nil
+	}
+}
+
+// readPacket reads a single packet from the connection
+func (z *ZKTecoDevice) readPacket() (*zkPacket, error) {
+	packet, err := z.readPacketWithin(z.timeout)
+	if err == errZKIdle {
+		z.handleConnError()
+		return nil, fmt.Errorf("timed out waiting for device response")
+	}
+	return packet, err
+}
+
+// readPacketWithin waits up to wait for a packet to start arriving.
+// It returns errZKIdle without dropping the session if nothing arrived.
+func (z *ZKTecoDevice) readPacketWithin(wait time.Duration) (*zkPacket, error) {
+	if z.conn == nil {
+		return nil, fmt.Errorf("connection not established")
+	}
+
+	z.conn.SetReadDeadline(time.Now().Add(wait))
+
+	var payload []byte
+	if z.protocol == "tcp" {
+		top := make([]byte, zkTCPTopSize)
+		n, err := io.ReadFull(z.conn, top)
+		if err != nil {
+			if n == 0 && isTimeout(err) {
+				return nil, errZKIdle
+			}
+			z.handleConnError()
+			return nil, fmt.Errorf("failed to read packet header: %w", err)
+		}
+		if binary.LittleEndian.Uint16(top[0:2]) != zkTCPMagic1 || binary.LittleEndian.Uint16(top[2:4]) != zkTCPMagic2 {
+			z.handleConnError()
+			return nil, fmt.Errorf("invalid ZK TCP frame header")
+		}
+
+		length := binary.LittleEndian.Uint32(top[4:8])
+		if length < zkHeaderSize || length > zkMaxPacketSize {
+			z.handleConnError()
+			return nil, fmt.Errorf("invalid ZK packet length: %d", length)
+		}
+
+		// The rest of a frame that has started must arrive within the normal timeout
+		z.conn.SetReadDeadline(time.Now().Add(z.timeout))
+		payload = make([]byte, length)
+		if _, err := io.ReadFull(z.conn, payload); err != nil {
+			z.handleConnError()
+			return nil, fmt.Errorf("failed to read packet body: %w", err)
+		}
+	} else {
+		buf := make([]byte, z.chunkSize+zkHeaderSize+1024)
+		n, err := z.conn.Read(buf)
+		if err != nil {
+			if isTimeout(err) {
+				return nil, errZKIdle
+			}
+			z.handleConnError()
+			return nil, fmt.Errorf("failed to read packet: %w", err)
+		}
+		payload = buf[:n]
+	}
+
+	return decodeZKPacket(payload)
+}
+
+// handleConnError drops the session after a transport failure so the adapter reconnects
+func (z *ZKTecoDevice) handleConnError() {
+	if z.connected {
+		z.logger.Warn("ZKTeco connection lost", "ip", z.ipAddress)
+	}
+	z.closeConn()
+}
+
+// closeConn closes the underlying connection and resets session state
+func (z *ZKTecoDevice) closeConn() {
+	if z.conn != nil {
+		z.conn.Close()
+		z.conn = nil
+	}
+	z.connected = false
+	z.sessionID = 0
+}
+
+// encodeZKPacket builds a ZK packet with a valid checksum
+func encodeZKPacket(command, sessionID, replyID uint16, data []byte) []byte {
+	packet := make([]byte, zkHeaderSize+len(data))
+	binary.LittleEndian.PutUint16(packet[0:2], command)
+	binary.LittleEndian.PutUint16(packet[4:6], sessionID)
+	binary.LittleEndian.PutUint16(packet[6:8], replyID)
+	copy(packet[zkHeaderSize:], data)
+
+	binary.LittleEndian.PutUint16(packet[2:4], zkChecksum(packet))
+	return packet
+}
+
+// decodeZKPacket parses a ZK packet
+func decodeZKPacket(payload []byte) (*zkPacket, error) {
+	if len(payload) < zkHeaderSize {
+		return nil, fmt.Errorf("ZK packet too short: %d bytes", len(payload))
+	}
+
+	return &zkPacket{
+		Command:   binary.LittleEndian.Uint16(payload[0:2]),
+		Checksum:  binary.LittleEndian.Uint16(payload[2:4]),
+		SessionID: binary.LittleEndian.Uint16(payload[4:6]),
+		ReplyID:   binary.LittleEndian.Uint16(payload[6:8]),
+		Data:      payload[zkHeaderSize:],
+	}, nil
+}
+
+// wrapZKTCP prefixes a packet with the TCP frame header
+func wrapZKTCP(packet []byte) []byte {
+	frame := make([]byte, zkTCPTopSize+len(packet))
+	binary.LittleEndian.PutUint16(frame[0:2], zkTCPMagic1)
+	binary.LittleEndian.PutUint16(frame[2:4], zkTCPMagic2)
+	binary.LittleEndian.PutUint32(frame[4:8], uint32(len(packet)))
+	copy(frame[zkTCPTopSize:], packet)
+	return frame
+}
+
+// zkChecksum computes the ones-complement checksum over a packet with a zeroed checksum field
+func zkChecksum(packet []byte) uint16 {
+	var sum uint32
+	for i := 0; i+1 < len(packet); i += 2 {
+		if i == 2 {
+			continue // Checksum field
+		}
+		sum += uint32(binary.LittleEndian.Uint16(packet[i : i+2]))
+		if sum > zkUSHRTMax {
+			sum -= zkUSHRTMax
+		}
+	}
+	if len(packet)%2 == 1 {
+		sum += uint32(packet[len(packet)-1])
+	}
+	for sum > zkUSHRTMax {
+		sum -= zkUSHRTMax
+	}
+
+	// Ones-complement as a signed value, folded back into range the way the firmware does
+	checksum := -int64(sum) - 1
+	for checksum < 0 {
+		checksum += zkUSHRTMax
+	}
+	return uint16(checksum)
+}
+
+// makeCommKey derives the CMD_AUTH payload from the numeric comm key and session
+func makeCommKey(key uint32, sessionID uint16, ticks byte) []byte {
+	// Reverse the bit order of the key
+	var k uint32
+	for i := 0; i < 32; i++ {
+		k <<= 1
+		if key&(1<<uint(i)) != 0 {
+			k |= 1
+		}
+	}
+	k += uint32(sessionID)
+
+	b := make([]byte, 4)
+	binary.LittleEndian.PutUint32(b, k)
+	b[0] ^= 'Z'
+	b[1] ^= 'K'
+	b[2] ^= 'S'
+	b[3] ^= 'O'
+
+	// Swap the two 16-bit halves
+	b[0], b[1], b[2], b[3] = b[2], b[3], b[0], b[1]
+
+	return []byte{b[0] ^ ticks, b[1] ^ ticks, ticks, b[3] ^ ticks}
+}
+
+// decodeZKTime converts the packed ZK timestamp into a time
+func decodeZKTime(t uint32, location *time.Location) time.Time {
+	second := int(t % 60)
+	t /= 60
+	minute := int(t % 60)
+	t /= 60
+	hour := int(t % 24)
+	t /= 24
+	day := int(t%31) + 1
+	t /= 31
+	month := time.Month(t%12 + 1)
+	t /= 12
+	year := int(t) + 2000
+
+	return time.Date(year, month, day, hour, minute, second, 0, location)
+}
+
+// encodeZKTime packs a time into the ZK timestamp format
+func encodeZKTime(t time.Time) uint32 {
+	days := ((t.Year()%100)*12*31 + (int(t.Month())-1)*31 + t.Day() - 1)
+	return uint32(days*24*60*60 + (t.Hour()*60+t.Minute())*60 + t.Second())
+}
+
+// parseZKUser parses a 28 or 72 byte user record
+func parseZKUser(data []byte) zkUser {
+	user := zkUser{
+		UID:       binary.LittleEndian.Uint16(data[0:2]),
+		Privilege: int(data[2]),
+	}
+
+	if len(data) == 28 {
+		user.Password = trimNull(data[3:8])
+		user.Name = trimNull(data[8:16])
+		user.Card = binary.LittleEndian.Uint32(data[16:20])
+		user.GroupID = strconv.Itoa(int(data[21]))
+		user.UserID = strconv.FormatUint(uint64(binary.LittleEndian.Uint32(data[24:28])), 10)
+	} else {
+		user.Password = trimNull(data[3:11])
+		user.Name = trimNull(data[11:35])
+		user.Card = binary.LittleEndian.Uint32(data[35:39])
+		user.GroupID = trimNull(data[40:47])
+		user.UserID = trimNull(data[48:72])
+	}
+
+	if user.Name == "" {
+		user.Name = "NN-" + user.UserID
+	}
+
+	return user
+}
+
+// parseZKAttendance parses an 8, 16 or 40 byte attendance record
+// For 8 byte records the returned user ID is the storage slot and must be resolved by the caller
+func parseZKAttendance(data []byte, location *time.Location) (string, AttendanceRecord) {
+	var userID string
+	var record AttendanceRecord
+
+	switch len(data) {
+	case 8:
+		userID = strconv.Itoa(int(binary.LittleEndian.Uint16(data[0:2])))
+		record.VerifyMode = int(data[2])
+		record.Timestamp = decodeZKTime(binary.LittleEndian.Uint32(data[3:7]), location)
+		record.Status = int(data[7])
+	case 16:
+		userID = strconv.FormatUint(uint64(binary.LittleEndian.Uint32(data[0:4])), 10)
+		record.Timestamp = decodeZKTime(binary.LittleEndian.Uint32(data[4:8]), location)
+		record.VerifyMode = int(data[8])
+		record.Status = int(data[9])
+		record.WorkCode = int(binary.LittleEndian.Uint32(data[12:16]))
+	default:
+		userID = trimNull(data[2:26])
+		record.VerifyMode = int(data[26])
+		record.Timestamp = decodeZKTime(binary.LittleEndian.Uint32(data[27:31]), location)
+		record.Status = int(data[31])
+	}
+
+	return userID, record
+}
+
+// parseZKRealtimeEvents parses the payload of a pushed event packet.
+// Attendance pushes may carry several records back to back.
+func parseZKRealtimeEvents(flag uint16, data []byte, location *time.Location) []*RealtimeEvent {
+	var events []*RealtimeEvent
+
+	switch flag {
+	case EF_ATTLOG:
+		for len(data) >= 10 {
+			var size, timeOffset int
+			var userID string
+
+			switch {
+			case len(data) == 10 || len(data) == 14:
+				size, timeOffset = len(data), 2
+				userID = strconv.Itoa(int(binary.LittleEndian.Uint16(data[0:2])))
+			case len(data) == 12:
+				size, timeOffset = 12, 4
+				userID = strconv.FormatUint(uint64(binary.LittleEndian.Uint32(data[0:4])), 10)
+			case len(data) == 32 || len(data) == 36 || len(data) == 37:
+				size, timeOffset = len(data), 24
+				userID = trimNull(data[0:24])
+			case len(data) >= 52:
+				size, timeOffset = 52, 24
+				userID = trimNull(data[0:24])
+			default:
+				return events
+			}
+
+			record := data[:size]
+			data = data[size:]
+
+			deviceUserID, err := strconv.Atoi(userID)
+			if err != nil {
+				continue
+			}
+
+			timestamp := decodeZKEventTime(record[timeOffset+2:timeOffset+8], location)
+			events = append(events, &RealtimeEvent{
+				Kind: RealtimeAttendance,
+				Record: AttendanceRecord{
+					DeviceUserID: deviceUserID,
+					Timestamp:    timestamp,
+					VerifyMode:   int(record[timeOffset]),
+					Status:       int(record[timeOffset+1]),
+				},
+				Timestamp: timestamp,
+			})
+		}
+	case EF_VERIFY:
+		// Successful verifications are followed by an attendance push; only failures matter here
+		if len(data) >= 4 && int32(binary.LittleEndian.Uint32(data[0:4])) <= 0 {
+			events = append(events, &RealtimeEvent{Kind: RealtimeVerifyFailed, Timestamp: time.Now()})
+		}
+	case EF_ALARM:
+		code := 0
+		if len(data) >= 4 {
+			code = int(binary.LittleEndian.Uint32(data[0:4]))
+		} else if len(data) > 0 {
+			code = int(data[0])
+		}
+		events = append(events, &RealtimeEvent{Kind: RealtimeDoorAlarm, AlarmCode: code, Timestamp: time.Now()})
+	}
+
+	return events
+}
+
+// decodeZKEventTime converts the 6 byte year/month/day/hour/minute/second time used in pushes
+func decodeZKEventTime(data []byte, location *time.Location) time.Time {
+	return time.Date(2000+int(data[0]), time.Month(data[1]), int(data[2]),
+		int(data[3]), int(data[4]), int(data[5]), 0, location)
+}
+
+// isTimeout reports whether err is a network timeout
+func isTimeout(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// trimNull converts a null-padded byte field to a string
+func trimNull(data []byte) string {
+	if idx := bytes.IndexByte(data, 0); idx >= 0 {
+		data = data[:idx]
+	}
+	return strings.TrimSpace(string(data))
+}
@@ -0,0 +1,950 @@
+package biometric
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"gym-door-bridge/internal/types"
+)
+
+// loadZKFixture reads a hex dump of a captured ZK response payload from testdata
+func loadZKFixture(t *testing.T, name string) []byte {
+	t.Helper()
+
+	raw, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("failed to read fixture %s: %v", name, err)
+	}
+
+	data, err := hex.DecodeString(strings.Join(strings.Fields(string(raw)), ""))
+	if err != nil {
+		t.Fatalf("failed to decode fixture %s: %v", name, err)
+	}
+	return data
+}
+
+// fakeZKServer replays captured ZK terminal responses over TCP or UDP
+type fakeZKServer struct {
+	t        *testing.T
+	protocol string
+	commKey  uint32
+
+	tcpListener net.Listener
+	udpConn     net.PacketConn
+
+	mu             sync.Mutex
+	sessionID      uint16
+	authenticated  bool
+	inlineLimit    int // Buffers up to this size are returned directly as CMD_DATA
+	dataPacketSize int // Size of CMD_DATA packets following CMD_PREPARE_DATA
+	sizes          []byte
+	users          []byte
+	attlog         []byte
+	templates      []byte
+	upload         []byte
+	templateWrites [][]byte
+	deviceTime     uint32
+	badChecksums   int
+	unlocks        []uint32
+	userWrites     [][]byte
+	deletes        []uint16
+	refreshes      int
+	clears         int
+	disabled       bool
+	enabledClears  int // Log clears while the device accepted punches
+	chunkReads     int
+	prepared       []byte
+	eventFlags     uint32
+	eventAcks      int
+	keepalives     int
+	connections    int
+	activeConn     net.Conn
+}
+
+func newFakeZKServer(t *testing.T, protocol string, commKey uint32) *fakeZKServer {
+	t.Helper()
+
+	s := &fakeZKServer{
+		t:              t,
+		protocol:       protocol,
+		commKey:        commKey,
+		sessionID:      0x1a2b,
+		inlineLimit:    1024,
+		dataPacketSize: 64,
+		sizes:          loadZKFixture(t, "zk_free_sizes.hex"),
+		users:          loadZKFixture(t, "zk_users_72.hex"),
+		attlog:         loadZKFixture(t, "zk_attlog_40.hex"),
+		deviceTime:     encodeZKTime(time.Date(2025, 6, 4, 9, 0, 0, 0, time.UTC)),
+	}
+
+	if protocol == "udp" {
+		conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("failed to listen: %v", err)
+		}
+		s.udpConn = conn
+		go s.serveUDP()
+	} else {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("failed to listen: %v", err)
+		}
+		s.tcpListener = listener
+		go s.serveTCP()
+	}
+
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *fakeZKServer) Addr() (string, string) {
+	var addr net.Addr
+	if s.udpConn != nil {
+		addr = s.udpConn.LocalAddr()
+	} else {
+		addr = s.tcpListener.Addr()
+	}
+	host, port, _ := net.SplitHostPort(addr.String())
+	return host, port
+}
+
+func (s *fakeZKServer) Close() {
+	if s.tcpListener != nil {
+		s.tcpListener.Close()
+	}
+	if s.udpConn != nil {
+		s.udpConn.Close()
+	}
+}
+
+func (s *fakeZKServer) serveTCP() {
+	for {
+		conn, err := s.tcpListener.Accept()
+		if err != nil {
+			return
+		}
+
+		s.mu.Lock()
+		s.connections++
+		s.activeConn = conn
+		s.mu.Unlock()
+
+		go func(conn net.Conn) {
+			defer conn.Close()
+			for {
+				top := make([]byte, zkTCPTopSize)
+				if _, err := io.ReadFull(conn, top); err != nil {
+					return
+				}
+				payload := make([]byte, binary.LittleEndian.Uint32(top[4:8]))
+				if _, err := io.ReadFull(conn, payload); err != nil {
+					return
+				}
+
+				for _, response := range s.handle(payload) {
+					if _, err := conn.Write(wrapZKTCP(response)); err != nil {
+						return
+					}
+				}
+			}
+		}(conn)
+	}
+}
+
+func (s *fakeZKServer) serveUDP() {
+	buf := make([]byte, 64*1024)
+	for {
+		n, addr, err := s.udpConn.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+
+		payload := append([]byte(nil), buf[:n]...)
+		for _, response := range s.handle(payload) {
+			if _, err := s.udpConn.WriteTo(response, addr); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// handle processes a single request packet and returns the response packets
+func (s *fakeZKServer) handle(payload []byte) [][]byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	request, err := decodeZKPacket(payload)
+	if err != nil {
+		return nil
+	}
+	if zkChecksum(payload) != request.Checksum {
+		s.badChecksums++
+	}
+
+	reply := func(command uint16, data []byte) []byte {
+		return encodeZKPacket(command, s.sessionID, request.ReplyID, data)
+	}
+
+	switch request.Command {
+	case CMD_ACK_OK:
+		// Acknowledgement of a pushed event
+		s.eventAcks++
+		return nil
+	case CMD_CONNECT:
+		s.eventFlags = 0
+		s.authenticated = s.commKey == 0
+		if !s.authenticated {
+			return [][]byte{reply(CMD_ACK_UNAUTH, nil)}
+		}
+		return [][]byte{reply(CMD_ACK_OK, nil)}
+	case CMD_AUTH:
+		if len(request.Data) == 4 && string(makeCommKey(s.commKey, s.sessionID, request.Data[2])) == string(request.Data) {
+			s.authenticated = true
+			return [][]byte{reply(CMD_ACK_OK, nil)}
+		}
+		return [][]byte{reply(CMD_ACK_UNAUTH, nil)}
+	}
+
+	if !s.authenticated {
+		return [][]byte{reply(CMD_ACK_UNAUTH, nil)}
+	}
+
+	switch request.Command {
+	case CMD_EXIT, CMD_FREE_DATA:
+		return [][]byte{reply(CMD_ACK_OK, nil)}
+	case CMD_GET_FREE_SIZES:
+		return [][]byte{reply(CMD_ACK_OK, s.sizes)}
+	case CMD_GET_VERSION:
+		return [][]byte{reply(CMD_ACK_OK, []byte("Ver 6.60 Apr 28 2017\x00"))}
+	case CMD_OPTIONS_RRQ:
+		switch trimNull(request.Data) {
+		case "~SerialNumber":
+			return [][]byte{reply(CMD_ACK_OK, []byte("~SerialNumber=CKJL201960123\x00"))}
+		case "~DeviceName":
+			return [][]byte{reply(CMD_ACK_OK, []byte("~DeviceName=SpeedFace-V5L\x00"))}
+		}
+		return [][]byte{reply(CMD_ACK_ERROR, nil)}
+	case CMD_PREPARE_BUFFER:
+		var buffer []byte
+		switch binary.LittleEndian.Uint16(request.Data[1:3]) {
+		case CMD_ATTLOG_RRQ:
+			buffer = s.attlog
+		case CMD_USERTEMP_RRQ:
+			buffer = s.users
+		case CMD_DB_RRQ:
+			buffer = s.templates
+		default:
+			return [][]byte{reply(CMD_ACK_ERROR, nil)}
+		}
+
+		s.prepared = buffer
+		if len(buffer) <= s.inlineLimit {
+			return [][]byte{reply(CMD_DATA, buffer)}
+		}
+
+		data := make([]byte, 9)
+		binary.LittleEndian.PutUint32(data[1:5], uint32(len(buffer)))
+		return [][]byte{reply(CMD_ACK_OK, data)}
+	case CMD_READ_BUFFER:
+		start := int(binary.LittleEndian.Uint32(request.Data[0:4]))
+		size := int(binary.LittleEndian.Uint32(request.Data[4:8]))
+		s.chunkReads++
+
+		chunk := s.prepared[start : start+size]
+		header := make([]byte, 4)
+		binary.LittleEndian.PutUint32(header, uint32(len(chunk)))
+
+		responses := [][]byte{reply(CMD_PREPARE_DATA, header)}
+		for offset := 0; offset < len(chunk); offset += s.dataPacketSize {
+			end := offset + s.dataPacketSize
+			if end > len(chunk) {
+				end = len(chunk)
+			}
+			responses = append(responses, reply(CMD_DATA, chunk[offset:end]))
+		}
+		return append(responses, reply(CMD_ACK_OK, nil))
+	case CMD_PREPARE_DATA:
+		s.upload = nil
+		return [][]byte{reply(CMD_ACK_OK, nil)}
+	case CMD_DATA:
+		s.upload = append(s.upload, request.Data...)
+		return [][]byte{reply(CMD_ACK_OK, nil)}
+	case CMD_TMP_WRITE:
+		s.templateWrites = append(s.templateWrites, append(append([]byte(nil), request.Data...), s.upload...))
+		return [][]byte{reply(CMD_ACK_OK, nil)}
+	case CMD_UNLOCK:
+		s.unlocks = append(s.unlocks, binary.LittleEndian.Uint32(request.Data))
+		return [][]byte{reply(CMD_ACK_OK, nil)}
+	case CMD_REG_EVENT:
+		s.eventFlags = binary.LittleEndian.Uint32(request.Data)
+		return [][]byte{reply(CMD_ACK_OK, nil)}
+	case CMD_GET_TIME:
+		s.keepalives++
+		data := make([]byte, 4)
+		binary.LittleEndian.PutUint32(data, s.deviceTime)
+		return [][]byte{reply(CMD_ACK_OK, data)}
+	case CMD_SET_TIME:
+		s.deviceTime = binary.LittleEndian.Uint32(request.Data)
+		return [][]byte{reply(CMD_ACK_OK, nil)}
+	case CMD_USER_WRQ:
+		s.userWrites = append(s.userWrites, append([]byte(nil), request.Data...))
+		return [][]byte{reply(CMD_ACK_OK, nil)}
+	case CMD_DELETE_USER:
+		s.deletes = append(s.deletes, binary.LittleEndian.Uint16(request.Data))
+		return [][]byte{reply(CMD_ACK_OK, nil)}
+	case CMD_REFRESHDATA:
+		s.refreshes++
+		return [][]byte{reply(CMD_ACK_OK, nil)}
+	case CMD_DISABLEDEVICE:
+		s.disabled = true
+		return [][]byte{reply(CMD_ACK_OK, nil)}
+	case CMD_ENABLEDEVICE:
+		s.disabled = false
+		return [][]byte{reply(CMD_ACK_OK, nil)}
+	case CMD_CLEAR_ATTLOG:
+		s.clears++
+		if !s.disabled {
+			s.enabledClears++
+		}
+		s.attlog = []byte{0, 0, 0, 0}
+		binary.LittleEndian.PutUint32(s.sizes[32:36], 0)
+		return [][]byte{reply(CMD_ACK_OK, nil)}
+	}
+
+	return [][]byte{reply(CMD_ACK_ERROR, nil)}
+}
+
+// push sends a real-time event to the connected TCP client
+func (s *fakeZKServer) push(flag uint16, data []byte) {
+	s.mu.Lock()
+	conn := s.activeConn
+	s.mu.Unlock()
+
+	if conn == nil {
+		s.t.Fatalf("no client connected")
+	}
+	packet := encodeZKPacket(CMD_REG_EVENT, flag, 0, data)
+	if _, err := conn.Write(wrapZKTCP(packet)); err != nil {
+		s.t.Fatalf("failed to push event: %v", err)
+	}
+}
+
+// appendAttendance stores a 40 byte record in the attendance log as the terminal does for each punch
+func (s *fakeZKServer) appendAttendance(uid uint16, userID string, verifyMode byte, t time.Time, punch byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record := make([]byte, 40)
+	binary.LittleEndian.PutUint16(record[0:2], uid)
+	copy(record[2:26], userID)
+	record[26] = verifyMode
+	binary.LittleEndian.PutUint32(record[27:31], encodeZKTime(t))
+	record[31] = punch
+
+	s.attlog = append(s.attlog, record...)
+	binary.LittleEndian.PutUint32(s.attlog[0:4], uint32(len(s.attlog)-4))
+	count := binary.LittleEndian.Uint32(s.sizes[32:36])
+	binary.LittleEndian.PutUint32(s.sizes[32:36], count+1)
+}
+
+// dropConnection closes the active client connection as a network outage would
+func (s *fakeZKServer) dropConnection() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.activeConn != nil {
+		s.activeConn.Close()
+		s.activeConn = nil
+	}
+}
+
+// zkAttendancePush builds a 32 byte EF_ATTLOG payload
+func zkAttendancePush(userID string, verifyMode, punch byte, t time.Time) []byte {
+	data := make([]byte, 32)
+	copy(data[0:24], userID)
+	data[24] = verifyMode
+	data[25] = punch
+	copy(data[26:32], []byte{byte(t.Year() - 2000), byte(t.Month()), byte(t.Day()), byte(t.Hour()), byte(t.Minute()), byte(t.Second())})
+	return data
+}
+
+func newTestZKDevice(t *testing.T, server *fakeZKServer, password string) *ZKTecoDevice {
+	t.Helper()
+
+	host, port := server.Addr()
+	device := NewZKTecoDevice(map[string]string{
+		"ip_address": host,
+		"port":       port,
+		"protocol":   server.protocol,
+		"password":   password,
+		"timeout":    "2",
+		"timezone":   "UTC",
+	}, newTestLogger())
+
+	if err := device.Connect(); err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	t.Cleanup(func() { device.Disconnect() })
+
+	return device
+}
+
+func TestZKChecksum(t *testing.T) {
+	// Known checksum for CMD_CONNECT with a zero session and reply ID 0xFFFE
+	packet := encodeZKPacket(CMD_CONNECT, 0, zkUSHRTMax-1, nil)
+	if got := hex.EncodeToString(packet); got != "e80317fc0000feff" {
+		t.Errorf("unexpected packet encoding: %s", got)
+	}
+
+	decoded, err := decodeZKPacket(packet)
+	if err != nil {
+		t.Fatalf("failed to decode packet: %v", err)
+	}
+	if decoded.Command != CMD_CONNECT || decoded.ReplyID != zkUSHRTMax-1 {
+		t.Errorf("unexpected decoded packet: %+v", decoded)
+	}
+}
+
+func TestZKMakeCommKey(t *testing.T) {
+	if got := hex.EncodeToString(makeCommKey(123456, 0x1a2b, 50)); got != "267f32e3" {
+		t.Errorf("unexpected comm key: %s", got)
+	}
+}
+
+func TestZKTimeRoundTrip(t *testing.T) {
+	original := time.Date(2025, 6, 4, 6, 12, 45, 0, time.UTC)
+	if decoded := decodeZKTime(encodeZKTime(original), time.UTC); !decoded.Equal(original) {
+		t.Errorf("expected %v, got %v", original, decoded)
+	}
+}
+
+func TestZKTecoDevice_ReadAttendanceAndUsers(t *testing.T) {
+	for _, protocol := range []string{"tcp", "udp"} {
+		t.Run(protocol, func(t *testing.T) {
+			server := newFakeZKServer(t, protocol, 0)
+			device := newTestZKDevice(t, server, "")
+
+			users, err := device.GetUsers()
+			if err != nil {
+				t.Fatalf("failed to get users: %v", err)
+			}
+			if len(users) != 3 {
+				t.Fatalf("expected 3 users, got %d", len(users))
+			}
+			if users[1].DeviceUserID != 1001 || users[1].Name != "Ravi Kumar" || users[1].CardNumber != "4521983" {
+				t.Errorf("unexpected user: %+v", users[1])
+			}
+			if users[0].Privilege != 14 {
+				t.Errorf("expected admin privilege, got %d", users[0].Privilege)
+			}
+
+			records, err := device.GetNewAttendanceRecords()
+			if err != nil {
+				t.Fatalf("failed to get attendance records: %v", err)
+			}
+			if len(records) != 3 {
+				t.Fatalf("expected 3 records, got %d", len(records))
+			}
+
+			expected := time.Date(2025, 6, 4, 6, 12, 45, 0, time.UTC)
+			if records[0].DeviceUserID != 1001 || !records[0].Timestamp.Equal(expected) || records[0].VerifyMode != 1 {
+				t.Errorf("unexpected first record: %+v", records[0])
+			}
+			if records[1].DeviceUserID != 1002 || records[1].VerifyMode != 15 {
+				t.Errorf("unexpected second record: %+v", records[1])
+			}
+			if records[2].Status != 1 {
+				t.Errorf("expected check-out punch on third record, got %d", records[2].Status)
+			}
+
+			// Records already returned are not reported again
+			records, err = device.GetNewAttendanceRecords()
+			if err != nil {
+				t.Fatalf("failed to get attendance records: %v", err)
+			}
+			if len(records) != 0 {
+				t.Errorf("expected no new records, got %d", len(records))
+			}
+
+			if err := device.ClearAttendanceRecords(); err != nil {
+				t.Fatalf("failed to clear records: %v", err)
+			}
+
+			server.mu.Lock()
+			defer server.mu.Unlock()
+			if server.clears != 1 {
+				t.Errorf("expected one clear, got %d", server.clears)
+			}
+			if server.badChecksums != 0 {
+				t.Errorf("server saw %d packets with bad checksums", server.badChecksums)
+			}
+		})
+	}
+}
+
+func TestZKTecoDevice_DrainAttendanceRecords(t *testing.T) {
+	server := newFakeZKServer(t, "tcp", 0)
+	device := newTestZKDevice(t, server, "")
+
+	records, err := device.DrainAttendanceRecords()
+	if err != nil {
+		t.Fatalf("failed to drain attendance records: %v", err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("expected 3 records, got %d", len(records))
+	}
+
+	// An empty log is not cleared again
+	if records, err = device.DrainAttendanceRecords(); err != nil || len(records) != 0 {
+		t.Fatalf("expected no new records, got %d (%v)", len(records), err)
+	}
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	if server.clears != 1 || server.enabledClears != 0 {
+		t.Errorf("expected one clear while disabled, got %d clears (%d enabled)", server.clears, server.enabledClears)
+	}
+	if server.disabled {
+		t.Errorf("expected the device to be enabled again")
+	}
+}
+
+func TestZKTecoDevice_ChunkedBufferRead(t *testing.T) {
+	server := newFakeZKServer(t, "tcp", 0)
+	server.inlineLimit = 0
+	server.dataPacketSize = 24
+
+	device := newTestZKDevice(t, server, "")
+	device.chunkSize = 50 // Force several READ_BUFFER round trips
+
+	records, err := device.readAttendance()
+	if err != nil {
+		t.Fatalf("failed to read attendance: %v", err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("expected 3 records, got %d", len(records))
+	}
+	if records[2].DeviceUserID != 1001 || records[2].Timestamp.Hour() != 7 {
+		t.Errorf("unexpected last record: %+v", records[2])
+	}
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	if server.chunkReads != 3 {
+		t.Errorf("expected 3 chunk reads, got %d", server.chunkReads)
+	}
+}
+
+func TestZKTecoDevice_CommKeyAuth(t *testing.T) {
+	server := newFakeZKServer(t, "tcp", 123456)
+	host, port := server.Addr()
+
+	wrongKey := NewZKTecoDevice(map[string]string{
+		"ip_address": host,
+		"port":       port,
+		"password":   "654321",
+		"timeout":    "2",
+	}, newTestLogger())
+	if err := wrongKey.Connect(); err == nil {
+		t.Fatalf("expected authentication failure with wrong comm key")
+	}
+	if wrongKey.IsConnected() {
+		t.Errorf("device should not report connected after failed auth")
+	}
+
+	device := newTestZKDevice(t, server, "123456")
+	if !device.IsConnected() {
+		t.Fatalf("expected device to be connected")
+	}
+	if device.sessionID != 0x1a2b {
+		t.Errorf("expected session ID from server, got %#x", device.sessionID)
+	}
+
+	info, err := device.GetDeviceInfo()
+	if err != nil {
+		t.Fatalf("failed to get device info: %v", err)
+	}
+	if info.SerialNumber != "CKJL201960123" || info.DeviceModel != "SpeedFace-V5L" || info.FirmwareVer != "Ver 6.60 Apr 28 2017" {
+		t.Errorf("unexpected device info: %+v", info)
+	}
+	if info.UserCount != 3 || info.AttendanceCount != 3 || info.FingerCapacity != 3000 {
+		t.Errorf("unexpected device counters: %+v", info)
+	}
+}
+
+func TestZKTecoDevice_Commands(t *testing.T) {
+	server := newFakeZKServer(t, "tcp", 0)
+	device := newTestZKDevice(t, server, "")
+
+	if err := device.UnlockDoor(3 * time.Second); err != nil {
+		t.Fatalf("failed to unlock door: %v", err)
+	}
+
+	deviceTime, err := device.GetDeviceTime()
+	if err != nil {
+		t.Fatalf("failed to get device time: %v", err)
+	}
+	if !deviceTime.Equal(time.Date(2025, 6, 4, 9, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected device time: %v", deviceTime)
+	}
+
+	newTime := time.Date(2025, 12, 31, 23, 59, 58, 0, time.UTC)
+	if err := device.SetDeviceTime(newTime); err != nil {
+		t.Fatalf("failed to set device time: %v", err)
+	}
+
+	// Existing enrollment number keeps its storage slot and card
+	if err := device.EnrollUser("platform-1001", 1001, "Ravi K"); err != nil {
+		t.Fatalf("failed to enroll existing user: %v", err)
+	}
+	if err := device.EnrollUser("platform-2001", 2001, "New Member"); err != nil {
+		t.Fatalf("failed to enroll new user: %v", err)
+	}
+	if err := device.DeleteUser(1002); err != nil {
+		t.Fatalf("failed to delete user: %v", err)
+	}
+	if err := device.DeleteUser(9999); err == nil {
+		t.Errorf("expected error deleting unknown user")
+	}
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	if len(server.unlocks) != 1 || server.unlocks[0] != 30 {
+		t.Errorf("expected unlock of 30 x 100ms, got %v", server.unlocks)
+	}
+	if decodeZKTime(server.deviceTime, time.UTC) != newTime {
+		t.Errorf("device time not updated, got %v", decodeZKTime(server.deviceTime, time.UTC))
+	}
+
+	if len(server.userWrites) != 2 {
+		t.Fatalf("expected 2 user writes, got %d", len(server.userWrites))
+	}
+	existing := parseZKUser(server.userWrites[0])
+	if existing.UID != 2 || existing.UserID != "1001" || existing.Name != "Ravi K" || existing.Card != 4521983 {
+		t.Errorf("unexpected write for existing user: %+v", existing)
+	}
+	added := parseZKUser(server.userWrites[1])
+	if added.UID != 4 || added.UserID != "2001" || added.Name != "New Member" {
+		t.Errorf("unexpected write for new user: %+v", added)
+	}
+
+	if len(server.deletes) != 1 || server.deletes[0] != 3 {
+		t.Errorf("expected delete of uid 3, got %v", server.deletes)
+	}
+	if server.refreshes != 3 {
+		t.Errorf("expected 3 refreshes, got %d", server.refreshes)
+	}
+}
+
+func TestZKTecoDevice_WriteUser(t *testing.T) {
+	server := newFakeZKServer(t, "tcp", 0)
+	device := newTestZKDevice(t, server, "")
+
+	// Existing user keeps its slot; the disabled flag is stored in the privilege
+	if err := device.WriteUser(DeviceUser{DeviceUserID: 1001, Name: "Ravi K", CardNumber: "777", Disabled: true}); err != nil {
+		t.Fatalf("failed to write existing user: %v", err)
+	}
+	if err := device.WriteUser(DeviceUser{DeviceUserID: 3001, Name: "New Member", Privilege: PrivilegeUser}); err != nil {
+		t.Fatalf("failed to write new user: %v", err)
+	}
+	if err := device.WriteUser(DeviceUser{DeviceUserID: 3002, CardNumber: "A-12"}); err == nil {
+		t.Errorf("expected error writing non-numeric card")
+	}
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	if len(server.userWrites) != 2 {
+		t.Fatalf("expected 2 user writes, got %d", len(server.userWrites))
+	}
+	existing := parseZKUser(server.userWrites[0])
+	if existing.UID != 2 || existing.Card != 777 || existing.Privilege != zkPrivilegeDisabled {
+		t.Errorf("unexpected write for existing user: %+v", existing)
+	}
+	added := parseZKUser(server.userWrites[1])
+	if added.UID != 4 || added.UserID != "3001" || added.Privilege != PrivilegeUser {
+		t.Errorf("unexpected write for new user: %+v", added)
+	}
+}
+
+// zkTemplateTable builds a fingerprint template table as returned for CMD_DB_RRQ
+func zkTemplateTable(templates ...[]byte) []byte {
+	var table []byte
+	for _, template := range templates {
+		table = append(table, template...)
+	}
+	size := make([]byte, 4)
+	binary.LittleEndian.PutUint32(size, uint32(len(table)))
+	return append(size, table...)
+}
+
+func zkTemplateRecord(uid uint16, finger, flag byte, data string) []byte {
+	record := make([]byte, zkTemplateHeaderSize)
+	binary.LittleEndian.PutUint16(record[0:2], uint16(zkTemplateHeaderSize+len(data)))
+	binary.LittleEndian.PutUint16(record[2:4], uid)
+	record[4] = finger
+	record[5] = flag
+	return append(record, data...)
+}
+
+func TestZKTecoDevice_Templates(t *testing.T) {
+	server := newFakeZKServer(t, "tcp", 0)
+	server.templates = zkTemplateTable(
+		zkTemplateRecord(2, 0, 1, "template-a"),
+		zkTemplateRecord(99, 1, 1, "orphan"),
+		zkTemplateRecord(3, 6, 3, "template-b"),
+	)
+	device := newTestZKDevice(t, server, "")
+
+	templates, err := device.GetTemplates()
+	if err != nil {
+		t.Fatalf("failed to get templates: %v", err)
+	}
+	if len(templates) != 2 {
+		t.Fatalf("expected 2 templates, got %d", len(templates))
+	}
+	if templates[0].DeviceUserID != 1001 || templates[0].FingerIndex != 0 || string(templates[0].Data) != "template-a" {
+		t.Errorf("unexpected first template: %+v", templates[0])
+	}
+	if templates[1].DeviceUserID != 1002 || templates[1].FingerIndex != 6 || templates[1].Flag != 3 {
+		t.Errorf("unexpected second template: %+v", templates[1])
+	}
+
+	data := bytes.Repeat([]byte{0xa5}, zkSendChunkSize+100)
+	if err := device.WriteTemplate(FingerprintTemplate{DeviceUserID: 1001, FingerIndex: 5, Flag: 1, Data: data}); err != nil {
+		t.Fatalf("failed to write template: %v", err)
+	}
+	if err := device.WriteTemplate(FingerprintTemplate{DeviceUserID: 9999, FingerIndex: 1, Data: data}); err == nil {
+		t.Errorf("expected error writing template for unknown user")
+	}
+	if err := device.WriteTemplate(FingerprintTemplate{DeviceUserID: 1001, FingerIndex: 10, Data: data}); err == nil {
+		t.Errorf("expected error writing invalid finger index")
+	}
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	if len(server.templateWrites) != 1 {
+		t.Fatalf("expected 1 template write, got %d", len(server.templateWrites))
+	}
+	write := server.templateWrites[0]
+	if binary.LittleEndian.Uint16(write[0:2]) != 2 || write[2] != 5 || write[3] != 1 || int(binary.LittleEndian.Uint16(write[4:6])) != len(data) {
+		t.Errorf("unexpected template write request: %v", write[:6])
+	}
+	if !bytes.Equal(write[6:], data) {
+		t.Errorf("uploaded template does not match, got %d bytes", len(write[6:]))
+	}
+}
+
+func TestZKTecoDevice_NotConnected(t *testing.T) {
+	device := NewZKTecoDevice(map[string]string{"port": strconv.Itoa(1)}, newTestLogger())
+
+	if _, err := device.GetUsers(); err == nil {
+		t.Errorf("expected error when not connected")
+	}
+	if err := device.UnlockDoor(time.Second); err == nil {
+		t.Errorf("expected error when not connected")
+	}
+}
+
+func TestParseZKRealtimeEvents(t *testing.T) {
+	punchTime := time.Date(2025, 6, 4, 18, 5, 30, 0, time.UTC)
+
+	events := parseZKRealtimeEvents(EF_ATTLOG, zkAttendancePush("1001", 1, 0, punchTime), time.UTC)
+	if len(events) != 1 {
+		t.Fatalf("expected 1 event, got %d", len(events))
+	}
+	if events[0].Kind != RealtimeAttendance || events[0].Record.DeviceUserID != 1001 || !events[0].Record.Timestamp.Equal(punchTime) {
+		t.Errorf("unexpected attendance event: %+v", events[0])
+	}
+
+	// Older firmware sends a numeric user ID in a 10 byte record
+	short := []byte{0xe9, 0x03, 15, 1, 25, 6, 4, 18, 5, 30}
+	events = parseZKRealtimeEvents(EF_ATTLOG, short, time.UTC)
+	if len(events) != 1 || events[0].Record.DeviceUserID != 1001 || events[0].Record.VerifyMode != 15 || events[0].Record.Status != 1 {
+		t.Errorf("unexpected short attendance event: %+v", events)
+	}
+
+	// Newer firmware batches 52 byte records
+	batch := make([]byte, 104)
+	copy(batch, zkAttendancePush("1001", 1, 0, punchTime))
+	copy(batch[52:], zkAttendancePush("1002", 1, 1, punchTime.Add(time.Second)))
+	events = parseZKRealtimeEvents(EF_ATTLOG, batch, time.UTC)
+	if len(events) != 2 || events[1].Record.DeviceUserID != 1002 || events[1].Record.Status != 1 {
+		t.Errorf("unexpected batched attendance events: %+v", events)
+	}
+
+	failed := []byte{0xff, 0xff, 0xff, 0xff}
+	events = parseZKRealtimeEvents(EF_VERIFY, failed, time.UTC)
+	if len(events) != 1 || events[0].Kind != RealtimeVerifyFailed {
+		t.Errorf("expected failed verification event, got %+v", events)
+	}
+	if events = parseZKRealtimeEvents(EF_VERIFY, []byte{2, 0, 0, 0}, time.UTC); len(events) != 0 {
+		t.Errorf("successful verification should not produce an event, got %+v", events)
+	}
+
+	events = parseZKRealtimeEvents(EF_ALARM, []byte{0x3a, 0, 0, 0}, time.UTC)
+	if len(events) != 1 || events[0].Kind != RealtimeDoorAlarm || events[0].AlarmCode != 0x3a {
+		t.Errorf("unexpected alarm event: %+v", events)
+	}
+}
+
+func TestZKTecoDevice_RealtimeEvents(t *testing.T) {
+	server := newFakeZKServer(t, "tcp", 0)
+	device := newTestZKDevice(t, server, "")
+
+	if err := device.RegisterRealtimeEvents(); err != nil {
+		t.Fatalf("failed to register events: %v", err)
+	}
+
+	event, err := device.ReadRealtimeEvent(50 * time.Millisecond)
+	if err != nil || event != nil {
+		t.Fatalf("expected idle read, got %+v, %v", event, err)
+	}
+	if !device.IsConnected() {
+		t.Fatalf("idle read must not drop the session")
+	}
+
+	punchTime := time.Date(2025, 6, 4, 18, 5, 30, 0, time.UTC)
+	server.push(EF_ATTLOG, zkAttendancePush("1002", 1, 0, punchTime))
+
+	event, err = device.ReadRealtimeEvent(time.Second)
+	if err != nil {
+		t.Fatalf("failed to read event: %v", err)
+	}
+	if event == nil || event.Record.DeviceUserID != 1002 {
+		t.Fatalf("unexpected event: %+v", event)
+	}
+
+	// Pushes arriving while a command is in flight are kept for the reader
+	server.push(EF_ALARM, []byte{0x3a, 0, 0, 0})
+	if err := device.Keepalive(); err != nil {
+		t.Fatalf("keepalive failed: %v", err)
+	}
+	event, err = device.ReadRealtimeEvent(time.Second)
+	if err != nil || event == nil || event.Kind != RealtimeDoorAlarm {
+		t.Fatalf("expected queued alarm event, got %+v, %v", event, err)
+	}
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	if server.eventFlags != zkRealtimeEvents {
+		t.Errorf("expected event flags %d, got %d", zkRealtimeEvents, server.eventFlags)
+	}
+	if server.eventAcks != 2 {
+		t.Errorf("expected 2 event acknowledgements, got %d", server.eventAcks)
+	}
+}
+
+func TestBiometricAdapter_RealtimePushAndReconnect(t *testing.T) {
+	server := newFakeZKServer(t, "tcp", 0)
+	host, port := server.Addr()
+
+	adapter := NewBiometricAdapter(newTestLogger())
+	err := adapter.Initialize(context.Background(), types.AdapterConfig{
+		Name:    "biometric",
+		Enabled: true,
+		Settings: map[string]interface{}{
+			"device_type":   "zkteco",
+			"sync_interval": 3600.0, // Polling must not be what delivers pushed events
+			"keepalive":     1.0,
+			"device_config": map[string]interface{}{
+				"ip_address": host,
+				"port":       port,
+				"timeout":    2.0,
+				"timezone":   "UTC",
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to initialize adapter: %v", err)
+	}
+
+	events := make(chan types.RawHardwareEvent, 10)
+	adapter.OnEvent(func(event types.RawHardwareEvent) {
+		events <- event
+	})
+
+	if err := adapter.StartListening(context.Background()); err != nil {
+		t.Fatalf("failed to start listening: %v", err)
+	}
+	defer adapter.StopListening(context.Background())
+
+	waitForEvents := func(count int) []types.RawHardwareEvent {
+		t.Helper()
+		var received []types.RawHardwareEvent
+		timeout := time.After(3 * time.Second)
+		for len(received) < count {
+			select {
+			case event := <-events:
+				received = append(received, event)
+			case <-timeout:
+				t.Fatalf("timed out waiting for %d events, got %d", count, len(received))
+			}
+		}
+		return received
+	}
+
+	waitForSession := func(connections int) {
+		t.Helper()
+		deadline := time.Now().Add(3 * time.Second)
+		for time.Now().Before(deadline) {
+			server.mu.Lock()
+			ready := server.connections >= connections && server.eventFlags != 0
+			server.mu.Unlock()
+			if ready && adapter.isRealtimeActive() {
+				return
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		t.Fatalf("real-time session %d was not established", connections)
+	}
+
+	// Records stored while the bridge was offline are reconciled on connect
+	waitForEvents(3)
+	waitForSession(1)
+
+	punchTime := time.Now().UTC().Truncate(time.Second)
+	server.appendAttendance(3, "1002", 1, punchTime, 0)
+	server.push(EF_ATTLOG, zkAttendancePush("1002", 1, 0, punchTime))
+
+	received := waitForEvents(1)
+	if received[0].ExternalUserID != "device_1002" || !received[0].Timestamp.Equal(punchTime) {
+		t.Errorf("unexpected pushed event: %+v", received[0])
+	}
+
+	// After an outage the reconciliation poll must not report the pushed record again
+	server.dropConnection()
+	waitForSession(2)
+
+	server.push(EF_VERIFY, []byte{0xff, 0xff, 0xff, 0xff})
+	received = waitForEvents(1)
+	if received[0].EventType != types.EventTypeDenied {
+		t.Errorf("expected denied event after reconnect, got %+v", received[0])
+	}
+
+	select {
+	case event := <-events:
+		t.Errorf("unexpected duplicate event: %+v", event)
+	case <-time.After(1500 * time.Millisecond):
+	}
+
+	server.mu.Lock()
+	keepalives := server.keepalives
+	server.mu.Unlock()
+	if keepalives == 0 {
+		t.Errorf("expected keepalives on an idle session")
+	}
+}
@@ -52,6 +52,11 @@ func (f *FingerprintAdapter) Initialize(ctx context.Context, config types.Adapte
 	defer f.mutex.Unlock()
 
 	f.config = config
+	if config.Name != "" {
+		// Adapters of the same type are told apart by their configured name
+		f.name = config.Name
+		f.status.Name = config.Name
+	}
 	f.status.Status = types.StatusInitializing
 	f.status.UpdatedAt = time.Now()
 
@@ -214,6 +219,7 @@ func (f *FingerprintAdapter) processRawScanData(rawData []byte) (*types.RawHardw
 		ExternalUserID: "fp_placeholder", // Would be extracted from rawData
 		Timestamp:      time.Now(),
 		EventType:      types.EventTypeEntry, // Would be determined from scan result
+		VerifyMode:     types.VerifyModeFingerprint,
 		RawData: map[string]interface{}{
 			"fingerprint": true,
 			"protocol":    f.protocol,
@@ -0,0 +1,32 @@
+package gpio
+
+import "time"
+
+// Line is a single line requested from a GPIO chip
+type Line interface {
+	// Value reads the current level of the line
+	Value() (bool, error)
+	// Set drives an output line high or low
+	Set(high bool) error
+	// Close releases the line back to the kernel
+	Close() error
+}
+
+// Bias selects the internal pull resistor of an input line
+type Bias string
+
+const (
+	BiasDefault  Bias = ""          // Leave the line as configured by the board
+	BiasPullUp   Bias = "pull_up"   // Contacts switching to ground
+	BiasPullDown Bias = "pull_down" // Contacts switching to the supply
+	BiasDisabled Bias = "disabled"  // Externally pulled lines
+)
+
+// InputOptions configures a line requested as an input
+type InputOptions struct {
+	Bias     Bias
+	Debounce time.Duration // Kernel debounce period, 0 disables debouncing
+}
+
+// Consumer is the label the kernel shows for lines held by the bridge
+const Consumer = "gym-door-bridge"
@@ -0,0 +1,194 @@
+//go:build linux
+
+package gpio
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// GPIO character device uAPI v2 (linux/gpio.h)
+const (
+	v2LinesMax        = 64
+	maxNameSize       = 32
+	v2LineNumAttrsMax = 10
+
+	v2LineFlagInput        = 1 << 2
+	v2LineFlagOutput       = 1 << 3
+	v2LineFlagBiasPullUp   = 1 << 8
+	v2LineFlagBiasPullDown = 1 << 9
+	v2LineFlagBiasDisabled = 1 << 10
+
+	v2LineAttrIDOutputValues = 2
+	v2LineAttrIDDebounce     = 3
+
+	// _IOWR(0xB4, 0x07, struct gpio_v2_line_request) and _IOWR(0xB4, 0x0E/0x0F, struct gpio_v2_line_values)
+	v2GetLineIoctl       = 0xC250B407
+	v2LineGetValuesIoctl = 0xC010B40E
+	v2LineSetValuesIoctl = 0xC010B40F
+)
+
+type v2LineAttribute struct {
+	ID      uint32
+	Padding uint32
+	Value   uint64 // flags, values or debounce period depending on ID
+}
+
+type v2LineConfigAttribute struct {
+	Attr v2LineAttribute
+	Mask uint64
+}
+
+type v2LineConfig struct {
+	Flags    uint64
+	NumAttrs uint32
+	Padding  [5]uint32
+	Attrs    [v2LineNumAttrsMax]v2LineConfigAttribute
+}
+
+type v2LineRequest struct {
+	Offsets         [v2LinesMax]uint32
+	Consumer        [maxNameSize]byte
+	Config          v2LineConfig
+	NumLines        uint32
+	EventBufferSize uint32
+	Padding         [5]uint32
+	Fd              int32
+}
+
+type v2LineValues struct {
+	Bits uint64
+	Mask uint64
+}
+
+// ioctl issues an ioctl against a GPIO chip or line; replaced in tests by a fake gpiochip
+var ioctl = func(fd uintptr, req uintptr, arg unsafe.Pointer) error {
+	if _, _, errno := unix.Syscall(unix.SYS_IOCTL, fd, req, uintptr(arg)); errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+// lineHandle is a line requested through the character device
+type lineHandle struct {
+	mutex sync.Mutex
+	file  *os.File
+}
+
+// RequestOutput requests a line as an output, already driven to initial so the
+// output does not glitch while the bridge starts
+func RequestOutput(chip string, offset int, initial bool) (Line, error) {
+	var config v2LineConfig
+	config.Flags = v2LineFlagOutput
+	config.NumAttrs = 1
+	config.Attrs[0].Attr.ID = v2LineAttrIDOutputValues
+	config.Attrs[0].Attr.Value = bits(initial)
+	config.Attrs[0].Mask = 1
+
+	return requestLine(chip, offset, config)
+}
+
+// RequestInput requests a line as an input
+func RequestInput(chip string, offset int, options InputOptions) (Line, error) {
+	var config v2LineConfig
+	config.Flags = v2LineFlagInput
+
+	switch options.Bias {
+	case BiasDefault:
+	case BiasPullUp:
+		config.Flags |= v2LineFlagBiasPullUp
+	case BiasPullDown:
+		config.Flags |= v2LineFlagBiasPullDown
+	case BiasDisabled:
+		config.Flags |= v2LineFlagBiasDisabled
+	default:
+		return nil, fmt.Errorf("unknown GPIO bias: %s", options.Bias)
+	}
+
+	if options.Debounce > 0 {
+		config.NumAttrs = 1
+		config.Attrs[0].Attr.ID = v2LineAttrIDDebounce
+		config.Attrs[0].Attr.Value = uint64(options.Debounce.Microseconds())
+		config.Attrs[0].Mask = 1
+	}
+
+	return requestLine(chip, offset, config)
+}
+
+func requestLine(chipPath string, offset int, config v2LineConfig) (Line, error) {
+	chip, err := os.OpenFile(chipPath, os.O_RDWR|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open GPIO chip %s: %w", chipPath, err)
+	}
+	// The requested line stays valid after the chip is closed
+	defer chip.Close()
+
+	var req v2LineRequest
+	req.Offsets[0] = uint32(offset)
+	req.NumLines = 1
+	copy(req.Consumer[:maxNameSize-1], Consumer)
+	req.Config = config
+
+	if err := ioctl(chip.Fd(), v2GetLineIoctl, unsafe.Pointer(&req)); err != nil {
+		return nil, fmt.Errorf("failed to request GPIO line %d on %s: %w", offset, chipPath, err)
+	}
+
+	file := os.NewFile(uintptr(req.Fd), fmt.Sprintf("%s:%d", chipPath, offset))
+	return &lineHandle{file: file}, nil
+}
+
+// Value reads the current level of the line
+func (l *lineHandle) Value() (bool, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.file == nil {
+		return false, fmt.Errorf("GPIO line is closed")
+	}
+
+	values := v2LineValues{Mask: 1}
+	if err := ioctl(l.file.Fd(), v2LineGetValuesIoctl, unsafe.Pointer(&values)); err != nil {
+		return false, fmt.Errorf("failed to read GPIO line %s: %w", l.file.Name(), err)
+	}
+	return values.Bits&1 != 0, nil
+}
+
+// Set drives the line high or low
+func (l *lineHandle) Set(high bool) error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.file == nil {
+		return fmt.Errorf("GPIO line is closed")
+	}
+
+	values := v2LineValues{Bits: bits(high), Mask: 1}
+	if err := ioctl(l.file.Fd(), v2LineSetValuesIoctl, unsafe.Pointer(&values)); err != nil {
+		return fmt.Errorf("failed to set GPIO line %s: %w", l.file.Name(), err)
+	}
+	return nil
+}
+
+// Close releases the line back to the kernel
+func (l *lineHandle) Close() error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.file == nil {
+		return nil
+	}
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
+
+func bits(high bool) uint64 {
+	if high {
+		return 1
+	}
+	return 0
+}
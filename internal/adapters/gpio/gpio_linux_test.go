@@ -0,0 +1,202 @@
+//go:build linux
+
+package gpio
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// fakeChip stands in for the kernel side of a GPIO chip by intercepting ioctls
+type fakeChip struct {
+	t        *testing.T
+	mutex    sync.Mutex
+	numLines uint32
+	lines    map[uintptr]uint32 // Line fd to offset
+	values   map[uint32][]bool  // Every value driven on each offset, starting with the initial value
+	inputs   map[uint32]bool    // Level seen on input lines
+	consumer string
+	config   v2LineConfig
+}
+
+func newFakeChip(t *testing.T) (*fakeChip, string) {
+	t.Helper()
+
+	chipPath := filepath.Join(t.TempDir(), "gpiochip0")
+	if err := os.WriteFile(chipPath, nil, 0600); err != nil {
+		t.Fatalf("failed to create fake gpiochip: %v", err)
+	}
+
+	chip := &fakeChip{
+		t:        t,
+		numLines: 32,
+		lines:    make(map[uintptr]uint32),
+		values:   make(map[uint32][]bool),
+		inputs:   make(map[uint32]bool),
+	}
+
+	original := ioctl
+	ioctl = chip.ioctl
+	t.Cleanup(func() { ioctl = original })
+
+	return chip, chipPath
+}
+
+func (c *fakeChip) ioctl(fd uintptr, req uintptr, arg unsafe.Pointer) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	switch req {
+	case v2GetLineIoctl:
+		request := (*v2LineRequest)(arg)
+		offset := request.Offsets[0]
+		if request.NumLines != 1 || offset >= c.numLines {
+			return unix.EINVAL
+		}
+
+		c.consumer = string(bytes.TrimRight(request.Consumer[:], "\x00"))
+		c.config = request.Config
+		if request.Config.Flags&v2LineFlagOutput != 0 {
+			initial := false
+			if request.Config.NumAttrs == 1 && request.Config.Attrs[0].Attr.ID == v2LineAttrIDOutputValues {
+				initial = request.Config.Attrs[0].Attr.Value&request.Config.Attrs[0].Mask != 0
+			}
+			c.values[offset] = append(c.values[offset], initial)
+		}
+
+		// Hand back a real descriptor so the line can be closed
+		lineFd, err := unix.Open(os.DevNull, unix.O_RDWR|unix.O_CLOEXEC, 0)
+		if err != nil {
+			c.t.Errorf("failed to open line descriptor: %v", err)
+			return unix.EIO
+		}
+		c.lines[uintptr(lineFd)] = offset
+		request.Fd = int32(lineFd)
+		return nil
+
+	case v2LineSetValuesIoctl:
+		offset, ok := c.lines[fd]
+		if !ok {
+			return unix.EBADF
+		}
+		values := (*v2LineValues)(arg)
+		if values.Mask != 1 {
+			return unix.EINVAL
+		}
+		c.values[offset] = append(c.values[offset], values.Bits&1 != 0)
+		return nil
+
+	case v2LineGetValuesIoctl:
+		offset, ok := c.lines[fd]
+		if !ok {
+			return unix.EBADF
+		}
+		values := (*v2LineValues)(arg)
+		values.Bits = bits(c.inputs[offset]) & values.Mask
+		return nil
+
+	default:
+		return unix.ENOTTY
+	}
+}
+
+func (c *fakeChip) lineValues(offset uint32) []bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return append([]bool(nil), c.values[offset]...)
+}
+
+func (c *fakeChip) setInput(offset uint32, high bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.inputs[offset] = high
+}
+
+func TestStructLayout(t *testing.T) {
+	// Sizes are encoded in the ioctl numbers and must match linux/gpio.h
+	if size := unsafe.Sizeof(v2LineRequest{}); size != 592 {
+		t.Errorf("gpio_v2_line_request is %d bytes, expected 592", size)
+	}
+	if size := unsafe.Sizeof(v2LineValues{}); size != 16 {
+		t.Errorf("gpio_v2_line_values is %d bytes, expected 16", size)
+	}
+	if size := unsafe.Sizeof(v2LineConfig{}); size != 272 {
+		t.Errorf("gpio_v2_line_config is %d bytes, expected 272", size)
+	}
+}
+
+func TestRequestOutput(t *testing.T) {
+	chip, chipPath := newFakeChip(t)
+
+	line, err := RequestOutput(chipPath, 17, true)
+	if err != nil {
+		t.Fatalf("failed to request output: %v", err)
+	}
+	if chip.consumer != Consumer || chip.config.Flags != v2LineFlagOutput {
+		t.Errorf("unexpected line request: consumer %q flags %#x", chip.consumer, chip.config.Flags)
+	}
+
+	if err := line.Set(false); err != nil {
+		t.Fatalf("failed to set line: %v", err)
+	}
+	if err := line.Close(); err != nil {
+		t.Fatalf("failed to close line: %v", err)
+	}
+	if err := line.Set(true); err == nil {
+		t.Errorf("expected error setting a closed line")
+	}
+
+	values := chip.lineValues(17)
+	if len(values) != 2 || !values[0] || values[1] {
+		t.Errorf("expected line values [true false], got %v", values)
+	}
+}
+
+func TestRequestInput(t *testing.T) {
+	chip, chipPath := newFakeChip(t)
+
+	line, err := RequestInput(chipPath, 4, InputOptions{Bias: BiasPullUp, Debounce: 20 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("failed to request input: %v", err)
+	}
+	defer line.Close()
+
+	if chip.config.Flags != v2LineFlagInput|v2LineFlagBiasPullUp {
+		t.Errorf("unexpected flags %#x", chip.config.Flags)
+	}
+	if chip.config.NumAttrs != 1 || chip.config.Attrs[0].Attr.ID != v2LineAttrIDDebounce || chip.config.Attrs[0].Attr.Value != 20000 {
+		t.Errorf("expected a 20ms debounce attribute, got %+v", chip.config.Attrs[0])
+	}
+
+	for _, level := range []bool{true, false} {
+		chip.setInput(4, level)
+		value, err := line.Value()
+		if err != nil {
+			t.Fatalf("failed to read line: %v", err)
+		}
+		if value != level {
+			t.Errorf("expected %v, got %v", level, value)
+		}
+	}
+}
+
+func TestRequestErrors(t *testing.T) {
+	_, chipPath := newFakeChip(t)
+
+	if _, err := RequestOutput(chipPath, 64, false); err == nil {
+		t.Errorf("expected error for a line the chip does not have")
+	}
+	if _, err := RequestInput(filepath.Join(t.TempDir(), "missing"), 1, InputOptions{}); err == nil {
+		t.Errorf("expected error for a missing chip")
+	}
+	if _, err := RequestInput(chipPath, 1, InputOptions{Bias: "floating"}); err == nil {
+		t.Errorf("expected error for an unknown bias")
+	}
+}
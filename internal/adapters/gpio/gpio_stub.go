@@ -0,0 +1,18 @@
+//go:build !linux
+
+package gpio
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// RequestOutput is not implemented on this platform
+func RequestOutput(chip string, offset int, initial bool) (Line, error) {
+	return nil, fmt.Errorf("GPIO is not supported on %s", runtime.GOOS)
+}
+
+// RequestInput is not implemented on this platform
+func RequestInput(chip string, offset int, options InputOptions) (Line, error) {
+	return nil, fmt.Errorf("GPIO is not supported on %s", runtime.GOOS)
+}
@@ -0,0 +1,159 @@
+package input
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gym-door-bridge/internal/adapters/gpio"
+	"gym-door-bridge/internal/adapters/modbus"
+)
+
+// Input drivers
+const (
+	DriverGPIOChip = "gpiochip" // Linux GPIO character device (/dev/gpiochipN)
+	DriverModbus   = "modbus"   // Discrete input on a Modbus-TCP I/O module
+)
+
+const (
+	defaultTimeout  = 2 * time.Second
+	defaultGPIOChip = "/dev/gpiochip0"
+)
+
+// Config describes a single digital input such as a door contact or exit button
+type Config struct {
+	Driver    string
+	ActiveLow bool // The input is asserted when the line reads low
+	Timeout   time.Duration
+
+	// gpiochip
+	Chip       string
+	Line       int
+	Bias       gpio.Bias
+	DebounceMs int
+
+	// modbus
+	Address string
+	UnitID  int
+	Input   int
+}
+
+// Input reads a digital input
+type Input interface {
+	// Active reports whether the input is asserted, with ActiveLow applied
+	Active(ctx context.Context) (bool, error)
+	// Close releases the input
+	Close() error
+}
+
+// requestGPIOInput requests an input line; replaced in tests
+var requestGPIOInput = gpio.RequestInput
+
+// Open opens the configured input
+func Open(config Config) (Input, error) {
+	config = config.withDefaults()
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	switch config.Driver {
+	case DriverGPIOChip:
+		line, err := requestGPIOInput(config.Chip, config.Line, gpio.InputOptions{
+			Bias:     config.Bias,
+			Debounce: time.Duration(config.DebounceMs) * time.Millisecond,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return &gpioInput{line: line, activeLow: config.ActiveLow}, nil
+	case DriverModbus:
+		return &modbusInput{
+			client:    modbus.NewClient(config.Address, byte(config.UnitID), config.Timeout),
+			input:     uint16(config.Input),
+			activeLow: config.ActiveLow,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown input driver: %s", config.Driver)
+	}
+}
+
+func (c Config) withDefaults() Config {
+	if c.Timeout <= 0 {
+		c.Timeout = defaultTimeout
+	}
+	if c.Driver == DriverGPIOChip && c.Chip == "" {
+		c.Chip = defaultGPIOChip
+	}
+	return c
+}
+
+// Validate checks the settings required by the configured driver
+func (c Config) Validate() error {
+	switch c.Driver {
+	case DriverGPIOChip:
+		if c.Line < 0 {
+			return fmt.Errorf("invalid GPIO line: %d", c.Line)
+		}
+		switch c.Bias {
+		case gpio.BiasDefault, gpio.BiasPullUp, gpio.BiasPullDown, gpio.BiasDisabled:
+		default:
+			return fmt.Errorf("bias must be one of: pull_up, pull_down, disabled")
+		}
+		if c.DebounceMs < 0 {
+			return fmt.Errorf("debounceMs must not be negative")
+		}
+	case DriverModbus:
+		if c.Address == "" {
+			return fmt.Errorf("address is required for modbus input")
+		}
+		if c.Input < 0 || c.Input > 0xFFFF {
+			return fmt.Errorf("invalid modbus input: %d", c.Input)
+		}
+		if c.UnitID < 0 || c.UnitID > 0xFF {
+			return fmt.Errorf("invalid modbus unit ID: %d", c.UnitID)
+		}
+	case "":
+		return fmt.Errorf("input driver is required")
+	default:
+		return fmt.Errorf("unknown input driver: %s", c.Driver)
+	}
+	return nil
+}
+
+// gpioInput reads a GPIO line
+type gpioInput struct {
+	line      gpio.Line
+	activeLow bool
+}
+
+func (g *gpioInput) Active(ctx context.Context) (bool, error) {
+	high, err := g.line.Value()
+	if err != nil {
+		return false, err
+	}
+	return high != g.activeLow, nil
+}
+
+func (g *gpioInput) Close() error {
+	return g.line.Close()
+}
+
+// modbusInput reads a discrete input of a Modbus-TCP module
+type modbusInput struct {
+	client    *modbus.Client
+	input     uint16
+	activeLow bool
+}
+
+func (m *modbusInput) Active(ctx context.Context) (bool, error) {
+	on, err := m.client.ReadDiscreteInput(ctx, m.input)
+	if err != nil {
+		return false, err
+	}
+	return on != m.activeLow, nil
+}
+
+// Close is a no-op; connections are not kept open between reads
+func (m *modbusInput) Close() error {
+	return nil
+}
@@ -0,0 +1,96 @@
+package input
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"gym-door-bridge/internal/adapters/gpio"
+)
+
+// fakeLine is a GPIO line whose level the test controls
+type fakeLine struct {
+	high   bool
+	closed bool
+}
+
+func (l *fakeLine) Value() (bool, error) {
+	if l.closed {
+		return false, errors.New("line closed")
+	}
+	return l.high, nil
+}
+
+func (l *fakeLine) Set(high bool) error { return errors.New("input line") }
+
+func (l *fakeLine) Close() error {
+	l.closed = true
+	return nil
+}
+
+func TestOpen_GPIOActiveLow(t *testing.T) {
+	line := &fakeLine{high: true}
+	var options gpio.InputOptions
+	original := requestGPIOInput
+	requestGPIOInput = func(chip string, offset int, opts gpio.InputOptions) (gpio.Line, error) {
+		options = opts
+		return line, nil
+	}
+	defer func() { requestGPIOInput = original }()
+
+	in, err := Open(Config{Driver: DriverGPIOChip, Line: 5, ActiveLow: true, Bias: gpio.BiasPullUp, DebounceMs: 20})
+	if err != nil {
+		t.Fatalf("failed to open input: %v", err)
+	}
+	if options.Bias != gpio.BiasPullUp || options.Debounce.Milliseconds() != 20 {
+		t.Errorf("unexpected line options: %+v", options)
+	}
+
+	// Pulled up and switched to ground: high is idle
+	active, err := in.Active(context.Background())
+	if err != nil || active {
+		t.Errorf("expected inactive input, got %v (%v)", active, err)
+	}
+	line.high = false
+	if active, _ := in.Active(context.Background()); !active {
+		t.Errorf("expected active input when the line is low")
+	}
+
+	if err := in.Close(); err != nil || !line.closed {
+		t.Errorf("expected the line to be released")
+	}
+}
+
+func TestFromSettings(t *testing.T) {
+	config, err := FromSettings(map[string]interface{}{
+		"driver":     "gpiochip",
+		"line":       22.0,
+		"bias":       "pull_up",
+		"activelow":  true,
+		"debounceMs": 30,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if config.Chip != defaultGPIOChip || config.Line != 22 || config.Bias != gpio.BiasPullUp || !config.ActiveLow || config.DebounceMs != 30 {
+		t.Errorf("unexpected config: %+v", config)
+	}
+
+	config, err = FromSettings(map[string]interface{}{"driver": "modbus", "address": "10.0.0.5", "input": 3.0})
+	if err != nil || config.Input != 3 {
+		t.Errorf("unexpected modbus config: %+v (%v)", config, err)
+	}
+
+	invalid := []map[string]interface{}{
+		{},
+		{"driver": "dip-switch"},
+		{"driver": "gpiochip", "bias": "floating"},
+		{"driver": "modbus"},
+		{"driver": "modbus", "address": "10.0.0.5", "input": 70000.0},
+	}
+	for _, settings := range invalid {
+		if _, err := FromSettings(settings); err == nil {
+			t.Errorf("expected error for settings %v", settings)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package input
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"gym-door-bridge/internal/adapters/gpio"
+)
+
+// FromSettings builds an input configuration from a settings map, such as the
+// contact or exit button entry of a door
+func FromSettings(settings map[string]interface{}) (Config, error) {
+	var config Config
+	if len(settings) == 0 {
+		return config, fmt.Errorf("input settings are empty")
+	}
+
+	config.Driver = stringSetting(settings, "driver")
+	config.ActiveLow, _ = lookup(settings, "activeLow").(bool)
+	config.Timeout = time.Duration(intSetting(settings, "timeoutMs")) * time.Millisecond
+
+	config.Chip = stringSetting(settings, "chip")
+	config.Line = intSetting(settings, "line")
+	config.Bias = gpio.Bias(stringSetting(settings, "bias"))
+	config.DebounceMs = intSetting(settings, "debounceMs")
+
+	config.Address = stringSetting(settings, "address")
+	config.UnitID = intSetting(settings, "unitId")
+	config.Input = intSetting(settings, "input")
+
+	config = config.withDefaults()
+	if err := config.Validate(); err != nil {
+		return config, err
+	}
+	return config, nil
+}
+
+// lookup finds a key regardless of case, as viper lowercases keys from config files
+func lookup(settings map[string]interface{}, key string) interface{} {
+	if value, ok := settings[key]; ok {
+		return value
+	}
+	for k, value := range settings {
+		if strings.EqualFold(k, key) {
+			return value
+		}
+	}
+	return nil
+}
+
+func stringSetting(settings map[string]interface{}, key string) string {
+	value, _ := lookup(settings, key).(string)
+	return value
+}
+
+// intSetting accepts JSON numbers as well as YAML integers
+func intSetting(settings map[string]interface{}, key string) int {
+	switch value := lookup(settings, key).(type) {
+	case float64:
+		return int(value)
+	case int:
+		return value
+	case int64:
+		return int(value)
+	default:
+		return 0
+	}
+}
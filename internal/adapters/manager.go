@@ -4,9 +4,12 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"reflect"
+	"sort"
 	"sync"
 	"time"
 
+	"gym-door-bridge/internal/adapters/biometric"
 	"gym-door-bridge/internal/adapters/fingerprint"
 	"gym-door-bridge/internal/adapters/rfid"
 	"gym-door-bridge/internal/adapters/simulator"
@@ -14,17 +17,38 @@ import (
 	"gym-door-bridge/internal/types"
 )
 
-// AdapterManager manages the lifecycle of hardware adapters
+// AdapterManager manages the lifecycle of hardware adapters. Adapters are keyed
+// by name, so several adapters of one type run as separate instances.
 type AdapterManager struct {
 	adapters      map[string]HardwareAdapter
 	configs       map[string]types.AdapterConfig
 	eventCallback types.EventCallback
 	logger        *slog.Logger
 	mutex         sync.RWMutex
+	reconcileMu   sync.Mutex // Serializes reconciles
 	ctx           context.Context
 	cancel        context.CancelFunc
 }
 
+// ReconcileResult lists what a reconcile changed
+type ReconcileResult struct {
+	Added     []string          `json:"added,omitempty"`
+	Removed   []string          `json:"removed,omitempty"`
+	Restarted []string          `json:"restarted,omitempty"`
+	Unchanged []string          `json:"unchanged,omitempty"`
+	Failed    map[string]string `json:"failed,omitempty"` // Adapter name to error
+}
+
+// Changed reports whether any adapter was added, removed or restarted
+func (r *ReconcileResult) Changed() bool {
+	return len(r.Added) > 0 || len(r.Removed) > 0 || len(r.Restarted) > 0 || len(r.Failed) > 0
+}
+
+// Started returns the adapters started by the reconcile, added or restarted
+func (r *ReconcileResult) Started() []string {
+	return append(append([]string{}, r.Added...), r.Restarted...)
+}
+
 // AdapterFactory is a function that creates a new adapter instance
 type AdapterFactory func(*slog.Logger) HardwareAdapter
 
@@ -34,6 +58,7 @@ var registeredAdapters = map[string]AdapterFactory{
 	"webhook":     func(logger *slog.Logger) HardwareAdapter { return webhook.NewWebhookAdapter(logger) },
 	"fingerprint": func(logger *slog.Logger) HardwareAdapter { return fingerprint.NewFingerprintAdapter(logger) },
 	"rfid":        func(logger *slog.Logger) HardwareAdapter { return rfid.NewRFIDAdapter(logger) },
+	"biometric":   func(logger *slog.Logger) HardwareAdapter { return biometric.NewBiometricAdapter(logger) },
 }
 
 // NewAdapterManager creates a new adapter manager instance
@@ -89,9 +114,9 @@ func (am *AdapterManager) LoadAdapters(configs []types.AdapterConfig) error {
 // loadAdapter loads a single adapter based on configuration
 func (am *AdapterManager) loadAdapter(config types.AdapterConfig) error {
 	// Check if adapter type is registered
-	factory, exists := registeredAdapters[config.Name]
+	factory, exists := registeredAdapters[config.AdapterType()]
 	if !exists {
-		return fmt.Errorf("unknown adapter type: %s", config.Name)
+		return fmt.Errorf("unknown adapter type: %s", config.AdapterType())
 	}
 
 	// Skip disabled adapters
@@ -110,14 +135,14 @@ func (am *AdapterManager) loadAdapter(config types.AdapterConfig) error {
 
 	// Register event callback if set
 	if am.eventCallback != nil {
-		adapter.OnEvent(am.eventCallback)
+		adapter.OnEvent(withAdapterName(config.Name, am.eventCallback))
 	}
 
 	// Store adapter and config
 	am.adapters[config.Name] = adapter
 	am.configs[config.Name] = config
 
-	am.logger.Info("Adapter loaded successfully", "name", config.Name)
+	am.logger.Info("Adapter loaded successfully", "name", config.Name, "type", config.AdapterType())
 	return nil
 }
 
@@ -253,8 +278,18 @@ func (am *AdapterManager) OnEvent(callback types.EventCallback) {
 	am.eventCallback = callback
 	
 	// Register callback with all existing adapters
-	for _, adapter := range am.adapters {
-		adapter.OnEvent(callback)
+	for name, adapter := range am.adapters {
+		adapter.OnEvent(withAdapterName(name, callback))
+	}
+}
+
+// withAdapterName wraps a callback so every event records the adapter it came from
+func withAdapterName(name string, callback types.EventCallback) types.EventCallback {
+	return func(event types.RawHardwareEvent) {
+		if event.AdapterName == "" {
+			event.AdapterName = name
+		}
+		callback(event)
 	}
 }
 
@@ -325,6 +360,150 @@ func (am *AdapterManager) ReloadAdapter(config types.AdapterConfig) error {
 	return nil
 }
 
+// Reconcile brings the running adapters in line with a new configuration:
+// adapters that are no longer enabled are stopped and removed, newly enabled
+// adapters are started, and only adapters whose settings changed are
+// restarted. The event callback carries over to the new adapters. Replaced
+// adapters are stopped without holding the manager lock, so events they are
+// still delivering reach the callback. An adapter that fails to start is
+// reported in the result and retried by the next reconcile.
+func (am *AdapterManager) Reconcile(configs []types.AdapterConfig) (*ReconcileResult, error) {
+	am.reconcileMu.Lock()
+	defer am.reconcileMu.Unlock()
+	
+	result := &ReconcileResult{Failed: make(map[string]string)}
+	desired := make(map[string]types.AdapterConfig, len(configs))
+	var order []string
+	for _, config := range configs {
+		if !config.Enabled {
+			continue
+		}
+		if _, duplicate := desired[config.Name]; !duplicate {
+			order = append(order, config.Name)
+		}
+		desired[config.Name] = config
+	}
+	
+	// Detach adapters that are dropped or need new settings
+	am.mutex.Lock()
+	var running []string
+	for name := range am.adapters {
+		running = append(running, name)
+	}
+	sort.Strings(running)
+	
+	stopping := make(map[string]HardwareAdapter)
+	for _, name := range running {
+		config, keep := desired[name]
+		current := am.configs[name]
+		if keep && current.AdapterType() == config.AdapterType() && reflect.DeepEqual(current.Settings, config.Settings) {
+			am.configs[name] = config
+			result.Unchanged = append(result.Unchanged, name)
+			continue
+		}
+		stopping[name] = am.adapters[name]
+		delete(am.adapters, name)
+		delete(am.configs, name)
+		if keep {
+			result.Restarted = append(result.Restarted, name)
+		} else {
+			result.Removed = append(result.Removed, name)
+		}
+	}
+	am.mutex.Unlock()
+	
+	for _, name := range running {
+		adapter, ok := stopping[name]
+		if !ok {
+			continue
+		}
+		if err := adapter.StopListening(am.ctx); err != nil {
+			am.logger.Error("Failed to stop adapter", "name", name, "error", err)
+		}
+	}
+	
+	// Start new and changed adapters; the old instance is stopped by now, so
+	// a restarted adapter can take over its device
+	for _, name := range order {
+		if _, restarting := stopping[name]; !restarting {
+			if _, running := am.GetAdapter(name); running {
+				continue
+			}
+			result.Added = append(result.Added, name)
+		}
+		if err := am.startAdapter(desired[name]); err != nil {
+			am.logger.Error("Failed to start adapter", "name", name, "error", err)
+			result.Failed[name] = err.Error()
+		}
+	}
+	
+	am.logger.Info("Adapters reconciled",
+		"added", result.Added,
+		"removed", result.Removed,
+		"restarted", result.Restarted,
+		"failed", len(result.Failed))
+	
+	if len(result.Failed) > 0 {
+		return result, fmt.Errorf("failed to start %d adapters: %v", len(result.Failed), result.Failed)
+	}
+	return result, nil
+}
+
+// startAdapter creates, initializes and starts an adapter and adds it to the
+// manager once it is listening
+func (am *AdapterManager) startAdapter(config types.AdapterConfig) error {
+	factory, exists := registeredAdapters[config.AdapterType()]
+	if !exists {
+		return fmt.Errorf("unknown adapter type: %s", config.AdapterType())
+	}
+	
+	adapter := factory(am.logger)
+	if err := adapter.Initialize(am.ctx, config); err != nil {
+		return fmt.Errorf("failed to initialize adapter %s: %w", config.Name, err)
+	}
+	
+	am.mutex.RLock()
+	callback := am.eventCallback
+	am.mutex.RUnlock()
+	if callback != nil {
+		adapter.OnEvent(withAdapterName(config.Name, callback))
+	}
+	
+	if err := adapter.StartListening(am.ctx); err != nil {
+		return fmt.Errorf("failed to start adapter %s: %w", config.Name, err)
+	}
+	
+	am.mutex.Lock()
+	am.adapters[config.Name] = adapter
+	am.configs[config.Name] = config
+	am.mutex.Unlock()
+	
+	am.logger.Info("Adapter started", "name", config.Name, "type", config.AdapterType())
+	return nil
+}
+
+// RemoveAdapter stops an adapter and removes it from the manager
+func (am *AdapterManager) RemoveAdapter(name string) error {
+	am.mutex.Lock()
+	defer am.mutex.Unlock()
+	
+	adapter, exists := am.adapters[name]
+	if !exists {
+		return fmt.Errorf("adapter %s not found", name)
+	}
+	
+	if err := adapter.StopListening(am.ctx); err != nil {
+		am.logger.Error("Failed to stop removed adapter",
+			"name", name,
+			"error", err)
+	}
+	delete(am.adapters, name)
+	delete(am.configs, name)
+	
+	am.logger.Info("Adapter removed", "name", name)
+	return nil
+}
+
 // MonitorHealth periodically checks adapter health and logs status
 func (am *AdapterManager) MonitorHealth(interval time.Duration) {
 	ticker := time.NewTicker(interval)
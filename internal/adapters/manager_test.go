@@ -1,6 +1,9 @@
 package adapters
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"log/slog"
 	"os"
 	"testing"
@@ -236,6 +239,28 @@ func TestAdapterManager_ReloadAdapter(t *testing.T) {
 	}
 }
 
+func TestAdapterManager_RemoveAdapter(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	manager := NewAdapterManager(logger)
+	defer manager.Shutdown()
+	manager.OnEvent(func(event types.RawHardwareEvent) {})
+
+	err := manager.LoadAdapters([]types.AdapterConfig{{Name: "simulator", Enabled: true}})
+	if err != nil {
+		t.Fatalf("failed to load adapter: %v", err)
+	}
+
+	if err := manager.RemoveAdapter("simulator"); err != nil {
+		t.Fatalf("failed to remove adapter: %v", err)
+	}
+	if _, exists := manager.GetAdapter("simulator"); exists {
+		t.Error("expected adapter to be gone after removal")
+	}
+	if err := manager.RemoveAdapter("simulator"); err == nil {
+		t.Error("expected error removing an adapter that is not loaded")
+	}
+}
+
 func TestAdapterManager_EventCallback(t *testing.T) {
 	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
 	manager := NewAdapterManager(logger)
@@ -279,6 +304,14 @@ func TestAdapterManager_EventCallback(t *testing.T) {
 		t.Error("expected to receive at least one event")
 	}
 
+	// Events record the adapter they came from
+	for _, event := range receivedEvents {
+		if event.AdapterName != "simulator" {
+			t.Errorf("expected adapter name 'simulator', got '%s'", event.AdapterName)
+			break
+		}
+	}
+
 	// Verify event structure
 	if len(receivedEvents) > 0 {
 		event := receivedEvents[0]
@@ -320,7 +353,7 @@ func TestAdapterManager_UnknownAdapterType(t *testing.T) {
 func TestGetRegisteredAdapterTypes(t *testing.T) {
 	types := GetRegisteredAdapterTypes()
 	
-	expectedTypes := []string{"simulator", "webhook", "fingerprint", "rfid"}
+	expectedTypes := []string{"simulator", "webhook", "fingerprint", "rfid", "biometric"}
 	if len(types) != len(expectedTypes) {
 		t.Errorf("expected %d adapter types, got %d", len(expectedTypes), len(types))
 	}
@@ -367,3 +400,143 @@ func TestRegisterAdapter(t *testing.T) {
 		t.Error("expected custom adapter to be registered")
 	}
 }
+
+// reconcileAdapter records its lifecycle and emits an event when stopped, like
+// an adapter flushing a buffered event on shutdown
+type reconcileAdapter struct {
+	name     string
+	settings map[string]interface{}
+	callback types.EventCallback
+	running  bool
+}
+
+func (a *reconcileAdapter) Name() string { return a.name }
+
+func (a *reconcileAdapter) Initialize(ctx context.Context, config types.AdapterConfig) error {
+	if config.Settings["fail"] == true {
+		return errors.New("device unreachable")
+	}
+	a.settings = config.Settings
+	return nil
+}
+
+func (a *reconcileAdapter) StartListening(ctx context.Context) error {
+	a.running = true
+	return nil
+}
+
+func (a *reconcileAdapter) StopListening(ctx context.Context) error {
+	if a.running && a.callback != nil {
+		a.callback(types.RawHardwareEvent{ExternalUserID: "in-flight", EventType: types.EventTypeEntry})
+	}
+	a.running = false
+	return nil
+}
+
+func (a *reconcileAdapter) UnlockDoor(ctx context.Context, durationMs int) error { return nil }
+
+func (a *reconcileAdapter) GetStatus() types.AdapterStatus {
+	return types.AdapterStatus{Name: a.name}
+}
+
+func (a *reconcileAdapter) OnEvent(callback types.EventCallback) { a.callback = callback }
+
+func (a *reconcileAdapter) IsHealthy() bool { return a.running }
+
+func TestAdapterManager_Reconcile(t *testing.T) {
+	originalRegistry := make(map[string]AdapterFactory)
+	for name, factory := range registeredAdapters {
+		originalRegistry[name] = factory
+	}
+	defer func() {
+		registeredAdapters = originalRegistry
+	}()
+	for _, name := range []string{"door-a", "door-b", "door-c"} {
+		name := name
+		RegisterAdapter(name, func(logger *slog.Logger) HardwareAdapter {
+			return &reconcileAdapter{name: name}
+		})
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	manager := NewAdapterManager(logger)
+	defer manager.Shutdown()
+
+	var events []types.RawHardwareEvent
+	manager.OnEvent(func(event types.RawHardwareEvent) {
+		events = append(events, event)
+	})
+
+	initial := []types.AdapterConfig{
+		{Name: "door-a", Enabled: true, Settings: map[string]interface{}{"port": 1}},
+		{Name: "door-b", Enabled: true, Settings: map[string]interface{}{"port": 2}},
+	}
+	result, err := manager.Reconcile(initial)
+	if err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	if fmt.Sprint(result.Added) != "[door-a door-b]" || !result.Changed() {
+		t.Errorf("expected both adapters added, got %+v", result)
+	}
+	unchangedAdapter, _ := manager.GetAdapter("door-a")
+
+	// Disable door-b and add door-c; door-a keeps running
+	result, err = manager.Reconcile([]types.AdapterConfig{
+		initial[0],
+		{Name: "door-b", Enabled: false, Settings: map[string]interface{}{"port": 2}},
+		{Name: "door-c", Enabled: true},
+	})
+	if err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	if fmt.Sprint(result.Added, result.Removed, result.Unchanged, result.Restarted) != "[door-c] [door-b] [door-a] []" {
+		t.Errorf("unexpected reconcile result: %+v", result)
+	}
+	if adapter, _ := manager.GetAdapter("door-a"); adapter != unchangedAdapter {
+		t.Error("an adapter with unchanged settings should keep running")
+	}
+	if _, exists := manager.GetAdapter("door-b"); exists {
+		t.Error("a disabled adapter should be removed")
+	}
+
+	// The event door-b delivered while stopping reached the callback
+	if len(events) != 1 || events[0].AdapterName != "door-b" {
+		t.Fatalf("expected the in-flight event of door-b, got %+v", events)
+	}
+
+	// Changing door-a's settings restarts only door-a, with the same callback
+	result, err = manager.Reconcile([]types.AdapterConfig{
+		{Name: "door-a", Enabled: true, Settings: map[string]interface{}{"port": 10}},
+		{Name: "door-c", Enabled: true},
+	})
+	if err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	if fmt.Sprint(result.Restarted, result.Unchanged) != "[door-a] [door-c]" {
+		t.Errorf("unexpected reconcile result: %+v", result)
+	}
+	restarted, _ := manager.GetAdapter("door-a")
+	if restarted == unchangedAdapter || restarted.(*reconcileAdapter).settings["port"] != 10 {
+		t.Error("door-a should be replaced by an adapter with the new settings")
+	}
+	restarted.(*reconcileAdapter).callback(types.RawHardwareEvent{ExternalUserID: "member"})
+	if len(events) != 3 || events[2].AdapterName != "door-a" {
+		t.Errorf("expected the restarted adapter to deliver events, got %+v", events)
+	}
+
+	// An adapter that fails to start is reported and retried next time
+	failing := []types.AdapterConfig{
+		{Name: "door-a", Enabled: true, Settings: map[string]interface{}{"port": 10}},
+		{Name: "door-c", Enabled: true, Settings: map[string]interface{}{"fail": true}},
+	}
+	result, err = manager.Reconcile(failing)
+	if err == nil || result.Failed["door-c"] == "" {
+		t.Fatalf("expected door-c to fail, got %+v (%v)", result, err)
+	}
+	if _, exists := manager.GetAdapter("door-c"); exists {
+		t.Error("a failed adapter should not be registered")
+	}
+	if _, err := manager.Reconcile(failing); err == nil {
+		t.Error("expected the failed adapter to be retried")
+	}
+}
@@ -0,0 +1,149 @@
+package modbus
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+// Modbus function codes and values used by the door I/O modules
+const (
+	FuncReadDiscreteInputs = 0x02
+	FuncWriteSingleCoil    = 0x05
+	ExceptionFlag          = 0x80
+
+	CoilOn  = 0xFF00
+	CoilOff = 0x0000
+
+	DefaultPort = "502"
+)
+
+// Client talks to a Modbus-TCP module. Each request uses a fresh connection since
+// cheap modules drop idle clients.
+type Client struct {
+	mutex         sync.Mutex
+	address       string
+	unitID        byte
+	timeout       time.Duration
+	transactionID uint16
+}
+
+// NewClient creates a client for the module at address; the default port is used
+// when the address does not name one
+func NewClient(address string, unitID byte, timeout time.Duration) *Client {
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		address = net.JoinHostPort(address, DefaultPort)
+	}
+	return &Client{address: address, unitID: unitID, timeout: timeout}
+}
+
+// Address returns the module address including the port
+func (c *Client) Address() string {
+	return c.address
+}
+
+// WriteSingleCoil switches a coil on or off
+func (c *Client) WriteSingleCoil(ctx context.Context, coil uint16, on bool) error {
+	value := uint16(CoilOff)
+	if on {
+		value = CoilOn
+	}
+
+	request := make([]byte, 5)
+	request[0] = FuncWriteSingleCoil
+	binary.BigEndian.PutUint16(request[1:3], coil)
+	binary.BigEndian.PutUint16(request[3:5], value)
+
+	response, err := c.transact(ctx, request)
+	if err != nil {
+		return err
+	}
+	// A successful write echoes the request
+	if len(response) != 5 || binary.BigEndian.Uint16(response[1:3]) != coil || binary.BigEndian.Uint16(response[3:5]) != value {
+		return fmt.Errorf("unexpected modbus response: %x", response)
+	}
+	return nil
+}
+
+// ReadDiscreteInput reads the state of a single discrete input
+func (c *Client) ReadDiscreteInput(ctx context.Context, input uint16) (bool, error) {
+	request := make([]byte, 5)
+	request[0] = FuncReadDiscreteInputs
+	binary.BigEndian.PutUint16(request[1:3], input)
+	binary.BigEndian.PutUint16(request[3:5], 1) // Quantity
+
+	response, err := c.transact(ctx, request)
+	if err != nil {
+		return false, err
+	}
+	// Function code, byte count, then the input bits starting at the least significant bit
+	if len(response) != 3 || response[1] != 1 {
+		return false, fmt.Errorf("unexpected modbus response: %x", response)
+	}
+	return response[2]&1 != 0, nil
+}
+
+// transact sends a request PDU and returns the response PDU, turning exception
+// responses into errors
+func (c *Client) transact(ctx context.Context, pdu []byte) ([]byte, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	dialer := net.Dialer{Timeout: c.timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", c.address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to modbus module %s: %w", c.address, err)
+	}
+	defer conn.Close()
+
+	deadline := time.Now().Add(c.timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	conn.SetDeadline(deadline)
+
+	c.transactionID++
+	if _, err := conn.Write(Frame(c.transactionID, c.unitID, pdu)); err != nil {
+		return nil, fmt.Errorf("failed to send modbus request: %w", err)
+	}
+
+	// MBAP header, then the PDU whose length the header gives
+	header := make([]byte, 7)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return nil, fmt.Errorf("failed to read modbus response: %w", err)
+	}
+	length := binary.BigEndian.Uint16(header[4:6])
+	if length < 3 || length > 256 {
+		return nil, fmt.Errorf("invalid modbus response length: %d", length)
+	}
+	response := make([]byte, length-1)
+	if _, err := io.ReadFull(conn, response); err != nil {
+		return nil, fmt.Errorf("failed to read modbus response: %w", err)
+	}
+
+	if binary.BigEndian.Uint16(header[0:2]) != c.transactionID {
+		return nil, fmt.Errorf("modbus response transaction mismatch")
+	}
+	if response[0] == pdu[0]|ExceptionFlag {
+		return nil, fmt.Errorf("modbus module returned exception code %d", response[1])
+	}
+	if response[0] != pdu[0] {
+		return nil, fmt.Errorf("unexpected modbus response: %x", response)
+	}
+	return response, nil
+}
+
+// Frame wraps a PDU in a Modbus-TCP MBAP header
+func Frame(transactionID uint16, unitID byte, pdu []byte) []byte {
+	frame := make([]byte, 7+len(pdu))
+	binary.BigEndian.PutUint16(frame[0:2], transactionID)
+	binary.BigEndian.PutUint16(frame[2:4], 0) // Protocol identifier
+	binary.BigEndian.PutUint16(frame[4:6], uint16(len(pdu)+1))
+	frame[6] = unitID
+	copy(frame[7:], pdu)
+	return frame
+}
@@ -0,0 +1,122 @@
+package modbus
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeModule is a local TCP stand-in for a Modbus-TCP I/O module
+type fakeModule struct {
+	listener net.Listener
+	mutex    sync.Mutex
+	coils    map[uint16]bool
+	inputs   map[uint16]bool
+}
+
+func newFakeModule(t *testing.T) *fakeModule {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	module := &fakeModule{listener: listener, coils: make(map[uint16]bool), inputs: make(map[uint16]bool)}
+	go module.serve()
+	return module
+}
+
+func (m *fakeModule) serve() {
+	for {
+		conn, err := m.listener.Accept()
+		if err != nil {
+			return
+		}
+		go m.handle(conn)
+	}
+}
+
+func (m *fakeModule) handle(conn net.Conn) {
+	defer conn.Close()
+
+	frame := make([]byte, 12)
+	if _, err := io.ReadFull(conn, frame); err != nil {
+		return
+	}
+	address := binary.BigEndian.Uint16(frame[8:10])
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	var pdu []byte
+	switch {
+	case address >= 100:
+		pdu = []byte{frame[7] | ExceptionFlag, 0x02} // Illegal data address
+	case frame[7] == FuncWriteSingleCoil:
+		m.coils[address] = binary.BigEndian.Uint16(frame[10:12]) == CoilOn
+		pdu = frame[7:12]
+	case frame[7] == FuncReadDiscreteInputs:
+		var bits byte
+		if m.inputs[address] {
+			bits = 1
+		}
+		pdu = []byte{FuncReadDiscreteInputs, 1, bits}
+	default:
+		pdu = []byte{frame[7] | ExceptionFlag, 0x01} // Illegal function
+	}
+	conn.Write(Frame(binary.BigEndian.Uint16(frame[0:2]), frame[6], pdu))
+}
+
+func TestClient_WriteSingleCoil(t *testing.T) {
+	module := newFakeModule(t)
+	client := NewClient(module.listener.Addr().String(), 1, time.Second)
+
+	if err := client.WriteSingleCoil(context.Background(), 3, true); err != nil {
+		t.Fatalf("failed to write coil: %v", err)
+	}
+	module.mutex.Lock()
+	on := module.coils[3]
+	module.mutex.Unlock()
+	if !on {
+		t.Errorf("expected coil 3 to be on")
+	}
+
+	if err := client.WriteSingleCoil(context.Background(), 100, true); err == nil {
+		t.Errorf("expected error for an exception response")
+	}
+}
+
+func TestClient_ReadDiscreteInput(t *testing.T) {
+	module := newFakeModule(t)
+	client := NewClient(module.listener.Addr().String(), 1, time.Second)
+
+	module.mutex.Lock()
+	module.inputs[2] = true
+	module.mutex.Unlock()
+
+	for input, expected := range map[uint16]bool{2: true, 5: false} {
+		value, err := client.ReadDiscreteInput(context.Background(), input)
+		if err != nil {
+			t.Fatalf("failed to read input %d: %v", input, err)
+		}
+		if value != expected {
+			t.Errorf("expected input %d to be %v, got %v", input, expected, value)
+		}
+	}
+
+	if _, err := client.ReadDiscreteInput(context.Background(), 100); err == nil {
+		t.Errorf("expected error for an exception response")
+	}
+}
+
+func TestNewClient_DefaultPort(t *testing.T) {
+	if address := NewClient("10.0.0.5", 1, time.Second).Address(); address != "10.0.0.5:502" {
+		t.Errorf("expected default port, got %s", address)
+	}
+}
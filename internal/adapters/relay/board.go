@@ -0,0 +1,86 @@
+package relay
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+
+	"gym-door-bridge/internal/adapters/serial"
+)
+
+const (
+	lcusHeader = 0xA0
+
+	// USBRelay HID boards take a 9 byte feature report: report ID, command, channel
+	hidReportSize = 9
+	hidCommandOn  = 0xFF
+	hidCommandOff = 0xFD
+)
+
+// openSerialPort opens the board's serial port; replaced in tests
+var openSerialPort = serial.Open
+
+// lcusDriver switches a channel on an LCUS/CH340 USB serial relay board
+type lcusDriver struct {
+	mutex   sync.Mutex
+	port    io.ReadWriteCloser
+	channel byte
+}
+
+func openLCUS(config Config) (Driver, error) {
+	port, err := openSerialPort(config.DevicePath, config.BaudRate)
+	if err != nil {
+		return nil, err
+	}
+	return &lcusDriver{port: port, channel: byte(config.Channel)}, nil
+}
+
+// lcusCommand builds the 4 byte LCUS command: header, channel, state, checksum
+func lcusCommand(channel byte, on bool) []byte {
+	var state byte
+	if on {
+		state = 1
+	}
+	return []byte{lcusHeader, channel, state, lcusHeader + channel + state}
+}
+
+// Set closes (high) or opens the relay contact
+func (l *lcusDriver) Set(ctx context.Context, high bool) error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.port == nil {
+		return fmt.Errorf("relay board is closed")
+	}
+
+	// The boards do not acknowledge commands
+	if _, err := l.port.Write(lcusCommand(l.channel, high)); err != nil {
+		return fmt.Errorf("failed to write relay command: %w", err)
+	}
+	return nil
+}
+
+// Close closes the serial port
+func (l *lcusDriver) Close() error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.port == nil {
+		return nil
+	}
+	err := l.port.Close()
+	l.port = nil
+	return err
+}
+
+// hidRelayReport builds the feature report switching one channel of a USBRelay HID board
+func hidRelayReport(channel byte, on bool) []byte {
+	report := make([]byte, hidReportSize)
+	report[1] = hidCommandOff
+	if on {
+		report[1] = hidCommandOn
+	}
+	report[2] = channel
+	return report
+}
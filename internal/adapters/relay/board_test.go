@@ -0,0 +1,87 @@
+package relay
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"testing"
+)
+
+// fakeSerialPort captures bytes written to a relay board
+type fakeSerialPort struct {
+	bytes.Buffer
+	closed bool
+}
+
+func (p *fakeSerialPort) Close() error {
+	p.closed = true
+	return nil
+}
+
+func TestLCUSCommand(t *testing.T) {
+	tests := []struct {
+		channel  byte
+		on       bool
+		expected []byte
+	}{
+		{1, true, []byte{0xA0, 0x01, 0x01, 0xA2}},
+		{1, false, []byte{0xA0, 0x01, 0x00, 0xA1}},
+		{2, true, []byte{0xA0, 0x02, 0x01, 0xA3}},
+		{4, false, []byte{0xA0, 0x04, 0x00, 0xA4}},
+	}
+
+	for _, tt := range tests {
+		if command := lcusCommand(tt.channel, tt.on); !bytes.Equal(command, tt.expected) {
+			t.Errorf("channel %d on=%v: expected % X, got % X", tt.channel, tt.on, tt.expected, command)
+		}
+	}
+}
+
+func TestHIDRelayReport(t *testing.T) {
+	if report := hidRelayReport(2, true); !bytes.Equal(report, []byte{0, 0xFF, 2, 0, 0, 0, 0, 0, 0}) {
+		t.Errorf("unexpected on report: % X", report)
+	}
+	if report := hidRelayReport(1, false); !bytes.Equal(report, []byte{0, 0xFD, 1, 0, 0, 0, 0, 0, 0}) {
+		t.Errorf("unexpected off report: % X", report)
+	}
+}
+
+func TestRelay_LCUSBoard(t *testing.T) {
+	port := &fakeSerialPort{}
+	var openedPath string
+	var openedBaud int
+
+	original := openSerialPort
+	openSerialPort = func(path string, baudRate int) (io.ReadWriteCloser, error) {
+		openedPath, openedBaud = path, baudRate
+		return port, nil
+	}
+	defer func() { openSerialPort = original }()
+
+	r, err := New(Config{Driver: DriverSerial, DevicePath: "/dev/ttyUSB1", Channel: 2}, testLogger())
+	if err != nil {
+		t.Fatalf("failed to open serial relay: %v", err)
+	}
+	if openedPath != "/dev/ttyUSB1" || openedBaud != defaultBaudRate {
+		t.Errorf("unexpected port opened: %s at %d", openedPath, openedBaud)
+	}
+
+	r.Pulse(context.Background(), 10000)
+	r.Close()
+
+	expected := append(append(lcusCommand(2, false), lcusCommand(2, true)...), lcusCommand(2, false)...)
+	if !bytes.Equal(port.Bytes(), expected) {
+		t.Errorf("expected commands % X, got % X", expected, port.Bytes())
+	}
+	if !port.closed {
+		t.Errorf("expected serial port to be closed")
+	}
+
+	openSerialPort = func(path string, baudRate int) (io.ReadWriteCloser, error) {
+		return nil, fmt.Errorf("no such device")
+	}
+	if _, err := New(Config{Driver: DriverSerial, DevicePath: "/dev/ttyUSB9"}, testLogger()); err == nil {
+		t.Errorf("expected error when the board is missing")
+	}
+}
@@ -0,0 +1,35 @@
+package relay
+
+import (
+	"context"
+
+	"gym-door-bridge/internal/adapters/gpio"
+)
+
+// requestGPIOOutput requests the relay's GPIO line; replaced in tests
+var requestGPIOOutput = gpio.RequestOutput
+
+// gpioDriver drives a single line requested from a GPIO chip
+type gpioDriver struct {
+	line gpio.Line
+}
+
+// openGPIO requests the configured line as an output, already driven to initial
+// so the lock does not glitch open while the bridge starts
+func openGPIO(config Config, initial bool) (Driver, error) {
+	line, err := requestGPIOOutput(config.Chip, config.Line, initial)
+	if err != nil {
+		return nil, err
+	}
+	return &gpioDriver{line: line}, nil
+}
+
+// Set drives the line high or low
+func (g *gpioDriver) Set(ctx context.Context, high bool) error {
+	return g.line.Set(high)
+}
+
+// Close releases the line back to the kernel
+func (g *gpioDriver) Close() error {
+	return g.line.Close()
+}
@@ -0,0 +1,103 @@
+package relay
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"gym-door-bridge/internal/adapters/gpio"
+)
+
+// fakeGPIOLine records every level driven on a requested line, starting with the initial value
+type fakeGPIOLine struct {
+	mutex  sync.Mutex
+	values []bool
+	closed bool
+}
+
+func (l *fakeGPIOLine) Value() (bool, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return l.values[len(l.values)-1], nil
+}
+
+func (l *fakeGPIOLine) Set(high bool) error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if l.closed {
+		return errors.New("line closed")
+	}
+	l.values = append(l.values, high)
+	return nil
+}
+
+func (l *fakeGPIOLine) Close() error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.closed = true
+	return nil
+}
+
+// stubGPIO replaces line requests with fake lines for the duration of a test
+func stubGPIO(t *testing.T) map[int]*fakeGPIOLine {
+	t.Helper()
+
+	lines := make(map[int]*fakeGPIOLine)
+	original := requestGPIOOutput
+	requestGPIOOutput = func(chip string, offset int, initial bool) (gpio.Line, error) {
+		if offset >= 32 {
+			return nil, errors.New("no such line")
+		}
+		line := &fakeGPIOLine{values: []bool{initial}}
+		lines[offset] = line
+		return line, nil
+	}
+	t.Cleanup(func() { requestGPIOOutput = original })
+
+	return lines
+}
+
+func TestRelay_GPIOChip(t *testing.T) {
+	lines := stubGPIO(t)
+
+	r, err := New(Config{
+		Driver:    DriverGPIOChip,
+		Line:      17,
+		ActiveLow: true,
+	}, testLogger())
+	if err != nil {
+		t.Fatalf("failed to open GPIO relay: %v", err)
+	}
+
+	// Active-low fail-secure: held high while locked, driven low to unlock
+	if err := r.Pulse(context.Background(), 10000); err != nil {
+		t.Fatalf("failed to pulse relay: %v", err)
+	}
+	if err := r.Close(); err != nil {
+		t.Fatalf("failed to close relay: %v", err)
+	}
+
+	line := lines[17]
+	if line == nil || !line.closed {
+		t.Fatalf("expected line 17 to be requested and released")
+	}
+	expected := []bool{true, true, false, true}
+	if len(line.values) != len(expected) {
+		t.Fatalf("expected line values %v, got %v", expected, line.values)
+	}
+	for i := range expected {
+		if line.values[i] != expected[i] {
+			t.Errorf("expected line values %v, got %v", expected, line.values)
+			break
+		}
+	}
+}
+
+func TestRelay_GPIOChipErrors(t *testing.T) {
+	stubGPIO(t)
+
+	if _, err := New(Config{Driver: DriverGPIOChip, Line: 64}, testLogger()); err == nil {
+		t.Errorf("expected error for a line the chip does not have")
+	}
+}
@@ -0,0 +1,60 @@
+//go:build linux
+
+package relay
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// HIDIOCSFEATURE(len) for a hidReportSize report: _IOC(_IOC_WRITE|_IOC_READ, 'H', 0x06, len)
+const hidIocSFeature = 0xC0000000 | hidReportSize<<16 | 'H'<<8 | 0x06
+
+// hidDriver switches a channel on a USBRelay HID board through hidraw
+type hidDriver struct {
+	mutex   sync.Mutex
+	device  *os.File
+	channel byte
+}
+
+func openHID(config Config) (Driver, error) {
+	device, err := os.OpenFile(config.DevicePath, os.O_RDWR|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open HID relay %s: %w", config.DevicePath, err)
+	}
+	return &hidDriver{device: device, channel: byte(config.Channel)}, nil
+}
+
+// Set closes (high) or opens the relay contact
+func (h *hidDriver) Set(ctx context.Context, high bool) error {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if h.device == nil {
+		return fmt.Errorf("HID relay is closed")
+	}
+
+	report := hidRelayReport(h.channel, high)
+	if _, _, errno := unix.Syscall(unix.SYS_IOCTL, h.device.Fd(), hidIocSFeature, uintptr(unsafe.Pointer(&report[0]))); errno != 0 {
+		return fmt.Errorf("failed to send HID relay report: %w", errno)
+	}
+	return nil
+}
+
+// Close closes the hidraw device
+func (h *hidDriver) Close() error {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if h.device == nil {
+		return nil
+	}
+	err := h.device.Close()
+	h.device = nil
+	return err
+}
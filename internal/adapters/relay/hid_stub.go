@@ -0,0 +1,13 @@
+//go:build !linux && !windows
+
+package relay
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// openHID is not implemented on this platform
+func openHID(config Config) (Driver, error) {
+	return nil, fmt.Errorf("HID relays are not supported on %s", runtime.GOOS)
+}
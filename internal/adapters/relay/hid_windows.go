@@ -0,0 +1,69 @@
+//go:build windows
+
+package relay
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var (
+	hid               = windows.NewLazySystemDLL("hid.dll")
+	procHidSetFeature = hid.NewProc("HidD_SetFeature")
+)
+
+// hidDriver switches a channel on a USBRelay HID board through the HID class driver
+type hidDriver struct {
+	mutex   sync.Mutex
+	handle  windows.Handle
+	closed  bool
+	channel byte
+}
+
+// openHID opens the board by its device interface path (\\?\hid#vid_16c0&pid_05df#...)
+func openHID(config Config) (Driver, error) {
+	name, err := windows.UTF16PtrFromString(config.DevicePath)
+	if err != nil {
+		return nil, fmt.Errorf("invalid HID device path %s: %w", config.DevicePath, err)
+	}
+
+	handle, err := windows.CreateFile(name, windows.GENERIC_READ|windows.GENERIC_WRITE,
+		windows.FILE_SHARE_READ|windows.FILE_SHARE_WRITE, nil, windows.OPEN_EXISTING, 0, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open HID relay %s: %w", config.DevicePath, err)
+	}
+
+	return &hidDriver{handle: handle, channel: byte(config.Channel)}, nil
+}
+
+// Set closes (high) or opens the relay contact
+func (h *hidDriver) Set(ctx context.Context, high bool) error {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if h.closed {
+		return fmt.Errorf("HID relay is closed")
+	}
+
+	report := hidRelayReport(h.channel, high)
+	if r, _, err := procHidSetFeature.Call(uintptr(h.handle), uintptr(unsafe.Pointer(&report[0])), uintptr(len(report))); r == 0 {
+		return fmt.Errorf("failed to send HID relay report: %w", err)
+	}
+	return nil
+}
+
+// Close closes the device handle
+func (h *hidDriver) Close() error {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if h.closed {
+		return nil
+	}
+	h.closed = true
+	return windows.CloseHandle(h.handle)
+}
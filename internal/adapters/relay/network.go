@@ -0,0 +1,81 @@
+package relay
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	"gym-door-bridge/internal/adapters/modbus"
+)
+
+// httpDriver switches a network relay module by requesting its on and off URLs
+type httpDriver struct {
+	client *http.Client
+	onURL  string
+	offURL string
+	method string
+}
+
+func newHTTPDriver(config Config) *httpDriver {
+	return &httpDriver{
+		client: &http.Client{Timeout: config.Timeout},
+		onURL:  config.OnURL,
+		offURL: config.OffURL,
+		method: config.Method,
+	}
+}
+
+// Set requests the on URL (high) or the off URL
+func (h *httpDriver) Set(ctx context.Context, high bool) error {
+	url := h.offURL
+	if high {
+		url = h.onURL
+	}
+
+	req, err := http.NewRequestWithContext(ctx, h.method, url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create relay request: %w", err)
+	}
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("relay request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("relay module returned HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Close drops idle keep-alive connections to the module
+func (h *httpDriver) Close() error {
+	h.client.CloseIdleConnections()
+	return nil
+}
+
+// modbusDriver switches a coil on a Modbus-TCP relay module
+type modbusDriver struct {
+	client *modbus.Client
+	coil   uint16
+}
+
+func newModbusDriver(config Config) *modbusDriver {
+	return &modbusDriver{
+		client: modbus.NewClient(config.Address, byte(config.UnitID), config.Timeout),
+		coil:   uint16(config.Coil),
+	}
+}
+
+// Set writes the coil on (high) or off
+func (m *modbusDriver) Set(ctx context.Context, high bool) error {
+	return m.client.WriteSingleCoil(ctx, m.coil, high)
+}
+
+// Close is a no-op; connections are not kept open between commands
+func (m *modbusDriver) Close() error {
+	return nil
+}
@@ -0,0 +1,182 @@
+package relay
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"gym-door-bridge/internal/adapters/modbus"
+)
+
+// fakeModbusModule is a local TCP stand-in for a Modbus-TCP relay module
+type fakeModbusModule struct {
+	listener  net.Listener
+	mutex     sync.Mutex
+	coils     map[uint16][]bool
+	unitID    byte
+	exception byte // Exception code returned instead of echoing writes, if set
+}
+
+func newFakeModbusModule(t *testing.T) *fakeModbusModule {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	module := &fakeModbusModule{listener: listener, coils: make(map[uint16][]bool), unitID: 1}
+	go module.serve()
+	return module
+}
+
+func (m *fakeModbusModule) serve() {
+	for {
+		conn, err := m.listener.Accept()
+		if err != nil {
+			return
+		}
+		go m.handle(conn)
+	}
+}
+
+func (m *fakeModbusModule) handle(conn net.Conn) {
+	defer conn.Close()
+
+	for {
+		frame := make([]byte, 12)
+		if _, err := io.ReadFull(conn, frame); err != nil {
+			return
+		}
+		if binary.BigEndian.Uint16(frame[2:4]) != 0 || binary.BigEndian.Uint16(frame[4:6]) != 6 ||
+			frame[6] != m.unitID || frame[7] != modbus.FuncWriteSingleCoil {
+			return
+		}
+
+		coil := binary.BigEndian.Uint16(frame[8:10])
+		value := binary.BigEndian.Uint16(frame[10:12])
+
+		m.mutex.Lock()
+		exception := m.exception
+		if exception == 0 {
+			m.coils[coil] = append(m.coils[coil], value == modbus.CoilOn)
+		}
+		m.mutex.Unlock()
+
+		response := frame
+		if exception != 0 {
+			response = append(frame[:4:4], 0, 3, m.unitID, modbus.FuncWriteSingleCoil|modbus.ExceptionFlag, exception)
+		}
+		conn.Write(response)
+	}
+}
+
+func (m *fakeModbusModule) coilValues(coil uint16) []bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return append([]bool(nil), m.coils[coil]...)
+}
+
+func TestRelay_Modbus(t *testing.T) {
+	module := newFakeModbusModule(t)
+
+	r, err := New(Config{
+		Driver:  DriverModbus,
+		Address: module.listener.Addr().String(),
+		Coil:    3,
+		Mode:    ModeFailSafe,
+	}, testLogger())
+	if err != nil {
+		t.Fatalf("failed to open modbus relay: %v", err)
+	}
+
+	if err := r.Pulse(context.Background(), 10000); err != nil {
+		t.Fatalf("failed to pulse relay: %v", err)
+	}
+	if err := r.Close(); err != nil {
+		t.Fatalf("failed to close relay: %v", err)
+	}
+
+	// Fail-safe: coil held on while locked, switched off to unlock
+	values := module.coilValues(3)
+	if len(values) != 3 || !values[0] || values[1] || !values[2] {
+		t.Errorf("unexpected coil writes: %v", values)
+	}
+}
+
+func TestRelay_ModbusErrors(t *testing.T) {
+	module := newFakeModbusModule(t)
+	module.exception = 2 // Illegal data address
+
+	if _, err := New(Config{Driver: DriverModbus, Address: module.listener.Addr().String(), Coil: 99}, testLogger()); err == nil {
+		t.Errorf("expected error for a modbus exception response")
+	}
+
+	module.exception = 0
+	module.unitID = 7
+	if _, err := New(Config{Driver: DriverModbus, Address: module.listener.Addr().String(), UnitID: 1}, testLogger()); err == nil {
+		t.Errorf("expected error when the module drops a request for another unit")
+	}
+
+	addr := module.listener.Addr().String()
+	module.listener.Close()
+	if _, err := New(Config{Driver: DriverModbus, Address: addr}, testLogger()); err == nil {
+		t.Errorf("expected error for an unreachable module")
+	}
+}
+
+func TestRelay_HTTP(t *testing.T) {
+	var mutex sync.Mutex
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		requests = append(requests, r.Method+" "+r.URL.RequestURI())
+		mutex.Unlock()
+
+		if r.URL.Query().Get("turn") == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte(`{"ison":true}`))
+	}))
+	defer server.Close()
+
+	r, err := New(Config{
+		Driver: DriverHTTP,
+		OnURL:  server.URL + "/relay/0?turn=on",
+		OffURL: server.URL + "/relay/0?turn=off",
+		Method: http.MethodPost,
+	}, testLogger())
+	if err != nil {
+		t.Fatalf("failed to open http relay: %v", err)
+	}
+
+	if err := r.Pulse(context.Background(), 10000); err != nil {
+		t.Fatalf("failed to pulse relay: %v", err)
+	}
+	r.Close()
+
+	expected := []string{"POST /relay/0?turn=off", "POST /relay/0?turn=on", "POST /relay/0?turn=off"}
+	mutex.Lock()
+	got := append([]string(nil), requests...)
+	mutex.Unlock()
+	if len(got) != len(expected) {
+		t.Fatalf("expected requests %v, got %v", expected, got)
+	}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Errorf("expected requests %v, got %v", expected, got)
+			break
+		}
+	}
+
+	if _, err := New(Config{Driver: DriverHTTP, OnURL: server.URL + "/on", OffURL: server.URL + "/off"}, testLogger()); err == nil {
+		t.Errorf("expected error when the module rejects the request")
+	}
+}
@@ -0,0 +1,409 @@
+package relay
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Mode describes what the lock does when its relay output loses power
+type Mode string
+
+const (
+	// ModeFailSecure is for locks that stay locked without power, such as electric strikes.
+	// The relay is energized to unlock.
+	ModeFailSecure Mode = "fail_secure"
+
+	// ModeFailSafe is for locks that release without power, such as maglocks.
+	// The relay is energized to hold the door locked and released to unlock.
+	ModeFailSafe Mode = "fail_safe"
+)
+
+// Supported relay drivers
+const (
+	DriverGPIOChip = "gpiochip" // Linux GPIO character device (/dev/gpiochipN)
+	DriverSerial   = "serial"   // LCUS/CH340 USB serial relay boards
+	DriverHID      = "hid"      // USB-HID relay boards (USBRelay command set)
+	DriverHTTP     = "http"     // Network relay modules switched by HTTP requests
+	DriverModbus   = "modbus"   // Network relay modules switched by Modbus-TCP coil writes
+)
+
+const (
+	defaultPulseMs  = 3000
+	defaultTimeout  = 2 * time.Second
+	defaultGPIOChip = "/dev/gpiochip0"
+	defaultBaudRate = 9600
+	relockTimeout   = 5 * time.Second
+)
+
+// Config describes a single relay output driving a door lock
+type Config struct {
+	Driver    string
+	PulseMs   int  // Unlock duration used when a caller does not give one
+	ActiveLow bool // The relay is energized by driving the output low (or sending the off command)
+	Mode      Mode
+	Timeout   time.Duration // Bound on a single switch operation for serial and network drivers
+
+	// gpiochip
+	Chip string
+	Line int
+
+	// serial and hid relay boards
+	DevicePath string
+	BaudRate   int
+	Channel    int
+
+	// http
+	OnURL  string
+	OffURL string
+	Method string
+
+	// modbus
+	Address string
+	UnitID  int
+	Coil    int
+}
+
+// Driver switches one physical relay output
+type Driver interface {
+	// Set drives the output high (closes the relay contact) or low
+	Set(ctx context.Context, high bool) error
+
+	// Close releases the output
+	Close() error
+}
+
+// Relay drives a door lock through a relay output, taking care of pulse timing,
+// output polarity and fail-safe/fail-secure wiring
+type Relay struct {
+	config   Config
+	driver   Driver
+	logger   *slog.Logger
+	mutex    sync.Mutex
+	unlocked bool
+	held     bool // Unlocked until Lock, as for scheduled free access
+	relockAt time.Time
+	timer    *time.Timer
+	closed   bool
+	lastErr  error
+}
+
+// New opens the configured driver and locks the door
+func New(config Config, logger *slog.Logger) (*Relay, error) {
+	config = config.withDefaults()
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	driver, err := openDriver(config, config.outputLevel(false))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s relay: %w", config.Driver, err)
+	}
+
+	return NewWithDriver(config, driver, logger)
+}
+
+// NewWithDriver wraps an already opened driver and locks the door
+func NewWithDriver(config Config, driver Driver, logger *slog.Logger) (*Relay, error) {
+	config = config.withDefaults()
+
+	r := &Relay{
+		config: config,
+		driver: driver,
+		logger: logger,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), relockTimeout)
+	defer cancel()
+
+	if err := driver.Set(ctx, config.outputLevel(false)); err != nil {
+		driver.Close()
+		return nil, fmt.Errorf("failed to lock door relay: %w", err)
+	}
+
+	return r, nil
+}
+
+// Pulse unlocks the door and relocks it after durationMs. Pulsing an unlocked
+// door extends the unlock rather than relocking early.
+func (r *Relay) Pulse(ctx context.Context, durationMs int) error {
+	if durationMs <= 0 {
+		durationMs = r.config.PulseMs
+	}
+	duration := time.Duration(durationMs) * time.Millisecond
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.closed {
+		return fmt.Errorf("relay is closed")
+	}
+
+	// A held door stays unlocked; the pulse must not relock it
+	if r.held {
+		return nil
+	}
+
+	if !r.unlocked {
+		if err := r.set(ctx, true); err != nil {
+			return fmt.Errorf("failed to unlock door relay: %w", err)
+		}
+		r.unlocked = true
+	}
+
+	relockAt := time.Now().Add(duration)
+	if relockAt.After(r.relockAt) {
+		r.relockAt = relockAt
+		if r.timer != nil {
+			r.timer.Stop()
+		}
+		r.timer = time.AfterFunc(duration, r.relock)
+	}
+
+	r.logger.Info("Door relay unlocked",
+		"driver", r.config.Driver,
+		"durationMs", durationMs,
+		"mode", r.config.Mode)
+
+	return nil
+}
+
+// Unlock holds the door unlocked until Lock is called, cancelling any pending
+// relock. Pulses while the door is held leave it unlocked.
+func (r *Relay) Unlock(ctx context.Context) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.closed {
+		return fmt.Errorf("relay is closed")
+	}
+
+	if !r.unlocked {
+		if err := r.set(ctx, true); err != nil {
+			return fmt.Errorf("failed to unlock door relay: %w", err)
+		}
+		r.unlocked = true
+	}
+
+	if r.timer != nil {
+		r.timer.Stop()
+		r.timer = nil
+	}
+	r.relockAt = time.Time{}
+	r.held = true
+
+	r.logger.Info("Door relay held unlocked", "driver", r.config.Driver, "mode", r.config.Mode)
+	return nil
+}
+
+// Lock relocks the door immediately, cancelling any pending pulse
+func (r *Relay) Lock(ctx context.Context) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.closed {
+		return fmt.Errorf("relay is closed")
+	}
+
+	return r.lock(ctx)
+}
+
+// IsUnlocked returns true while a pulse is in progress or the door is held unlocked
+func (r *Relay) IsUnlocked() bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.unlocked
+}
+
+// Status returns the relay state for status reporting
+func (r *Relay) Status() map[string]interface{} {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	status := map[string]interface{}{
+		"driver":    r.config.Driver,
+		"mode":      string(r.config.Mode),
+		"activeLow": r.config.ActiveLow,
+		"unlocked":  r.unlocked,
+		"held":      r.held,
+	}
+	if r.unlocked && !r.held {
+		status["relockAt"] = r.relockAt
+	}
+	if r.lastErr != nil {
+		status["error"] = r.lastErr.Error()
+	}
+	return status
+}
+
+// Close relocks the door and releases the output. What the lock does after the
+// output is released is down to the hardware and its fail-safe/fail-secure wiring.
+func (r *Relay) Close() error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.closed {
+		return nil
+	}
+	r.closed = true
+
+	ctx, cancel := context.WithTimeout(context.Background(), relockTimeout)
+	defer cancel()
+
+	lockErr := r.lock(ctx)
+	if err := r.driver.Close(); err != nil {
+		return fmt.Errorf("failed to close relay: %w", err)
+	}
+	return lockErr
+}
+
+// relock is called by the pulse timer
+func (r *Relay) relock() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.closed || !r.unlocked || r.held || time.Now().Before(r.relockAt) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), relockTimeout)
+	defer cancel()
+
+	if err := r.lock(ctx); err != nil {
+		r.logger.Error("Failed to relock door relay", "driver", r.config.Driver, "error", err)
+		return
+	}
+
+	r.logger.Info("Door relay relocked", "driver", r.config.Driver)
+}
+
+// lock drives the output to the locked state; the caller holds the mutex
+func (r *Relay) lock(ctx context.Context) error {
+	if r.timer != nil {
+		r.timer.Stop()
+		r.timer = nil
+	}
+	r.relockAt = time.Time{}
+
+	if err := r.set(ctx, false); err != nil {
+		return fmt.Errorf("failed to lock door relay: %w", err)
+	}
+	r.unlocked = false
+	r.held = false
+	return nil
+}
+
+// set switches the output for the given door state; the caller holds the mutex
+func (r *Relay) set(ctx context.Context, unlocked bool) error {
+	err := r.driver.Set(ctx, r.config.outputLevel(unlocked))
+	r.lastErr = err
+	return err
+}
+
+// outputLevel maps a door state to the physical output level
+func (c Config) outputLevel(unlocked bool) bool {
+	// Fail-secure locks are energized to unlock, fail-safe locks are energized to stay locked
+	energized := unlocked
+	if c.Mode == ModeFailSafe {
+		energized = !unlocked
+	}
+	return energized != c.ActiveLow
+}
+
+// withDefaults fills in unset fields
+func (c Config) withDefaults() Config {
+	if c.PulseMs <= 0 {
+		c.PulseMs = defaultPulseMs
+	}
+	if c.Mode == "" {
+		c.Mode = ModeFailSecure
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = defaultTimeout
+	}
+
+	switch c.Driver {
+	case DriverGPIOChip:
+		if c.Chip == "" {
+			c.Chip = defaultGPIOChip
+		}
+	case DriverSerial, DriverHID:
+		if c.BaudRate <= 0 {
+			c.BaudRate = defaultBaudRate
+		}
+		if c.Channel <= 0 {
+			c.Channel = 1
+		}
+	case DriverHTTP:
+		if c.Method == "" {
+			c.Method = "GET"
+		}
+	case DriverModbus:
+		if c.UnitID <= 0 {
+			c.UnitID = 1
+		}
+	}
+
+	return c
+}
+
+// Validate checks the configuration for the selected driver
+func (c Config) Validate() error {
+	if c.Mode != ModeFailSecure && c.Mode != ModeFailSafe {
+		return fmt.Errorf("invalid relay mode: %s (must be %s or %s)", c.Mode, ModeFailSecure, ModeFailSafe)
+	}
+
+	switch c.Driver {
+	case DriverGPIOChip:
+		if c.Line < 0 {
+			return fmt.Errorf("invalid GPIO line: %d", c.Line)
+		}
+	case DriverSerial, DriverHID:
+		if c.DevicePath == "" {
+			return fmt.Errorf("devicePath is required for %s relay", c.Driver)
+		}
+		if c.Channel > 255 {
+			return fmt.Errorf("invalid relay channel: %d", c.Channel)
+		}
+	case DriverHTTP:
+		if c.OnURL == "" || c.OffURL == "" {
+			return fmt.Errorf("onUrl and offUrl are required for http relay")
+		}
+	case DriverModbus:
+		if c.Address == "" {
+			return fmt.Errorf("address is required for modbus relay")
+		}
+		if c.Coil < 0 || c.Coil > 0xFFFF {
+			return fmt.Errorf("invalid modbus coil: %d", c.Coil)
+		}
+		if c.UnitID > 255 {
+			return fmt.Errorf("invalid modbus unit ID: %d", c.UnitID)
+		}
+	case "":
+		return fmt.Errorf("relay driver is required")
+	default:
+		return fmt.Errorf("unsupported relay driver: %s", c.Driver)
+	}
+
+	return nil
+}
+
+// openDriver opens the output for the configured driver, driving it to initial where the hardware allows
+func openDriver(config Config, initial bool) (Driver, error) {
+	switch config.Driver {
+	case DriverGPIOChip:
+		return openGPIO(config, initial)
+	case DriverSerial:
+		return openLCUS(config)
+	case DriverHID:
+		return openHID(config)
+	case DriverHTTP:
+		return newHTTPDriver(config), nil
+	case DriverModbus:
+		return newModbusDriver(config), nil
+	default:
+		return nil, fmt.Errorf("unsupported relay driver: %s", config.Driver)
+	}
+}
@@ -0,0 +1,290 @@
+package relay
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeDriver records every level the relay drives
+type fakeDriver struct {
+	mutex  sync.Mutex
+	levels []bool
+	fail   bool
+	closed bool
+}
+
+func (f *fakeDriver) Set(ctx context.Context, high bool) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if f.fail {
+		return fmt.Errorf("output failure")
+	}
+	f.levels = append(f.levels, high)
+	return nil
+}
+
+func (f *fakeDriver) Close() error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.closed = true
+	return nil
+}
+
+func (f *fakeDriver) history() []bool {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return append([]bool(nil), f.levels...)
+}
+
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+}
+
+func waitForLevels(t *testing.T, driver *fakeDriver, count int) []bool {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if levels := driver.history(); len(levels) >= count {
+			return levels
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("expected %d output changes, got %v", count, driver.history())
+	return nil
+}
+
+func TestConfig_OutputLevel(t *testing.T) {
+	tests := []struct {
+		mode        Mode
+		activeLow   bool
+		lockedLevel bool
+		unlockLevel bool
+	}{
+		{ModeFailSecure, false, false, true},
+		{ModeFailSecure, true, true, false},
+		{ModeFailSafe, false, true, false},
+		{ModeFailSafe, true, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s activeLow=%v", tt.mode, tt.activeLow), func(t *testing.T) {
+			config := Config{Mode: tt.mode, ActiveLow: tt.activeLow}
+			if level := config.outputLevel(false); level != tt.lockedLevel {
+				t.Errorf("expected locked level %v, got %v", tt.lockedLevel, level)
+			}
+			if level := config.outputLevel(true); level != tt.unlockLevel {
+				t.Errorf("expected unlocked level %v, got %v", tt.unlockLevel, level)
+			}
+		})
+	}
+}
+
+func TestRelay_PulseRelocks(t *testing.T) {
+	driver := &fakeDriver{}
+	r, err := NewWithDriver(Config{Driver: "fake", Mode: ModeFailSafe}, driver, testLogger())
+	if err != nil {
+		t.Fatalf("failed to create relay: %v", err)
+	}
+	defer r.Close()
+
+	// A fail-safe lock is held energized while locked
+	if levels := driver.history(); len(levels) != 1 || !levels[0] {
+		t.Fatalf("expected relay to start energized, got %v", levels)
+	}
+
+	if err := r.Pulse(context.Background(), 50); err != nil {
+		t.Fatalf("failed to pulse relay: %v", err)
+	}
+	if !r.IsUnlocked() {
+		t.Errorf("expected relay to be unlocked during pulse")
+	}
+
+	levels := waitForLevels(t, driver, 3)
+	if levels[1] || !levels[2] {
+		t.Errorf("expected release then re-energize, got %v", levels)
+	}
+	if r.IsUnlocked() {
+		t.Errorf("expected relay to relock after pulse")
+	}
+}
+
+func TestRelay_PulseExtends(t *testing.T) {
+	driver := &fakeDriver{}
+	r, err := NewWithDriver(Config{Driver: "fake"}, driver, testLogger())
+	if err != nil {
+		t.Fatalf("failed to create relay: %v", err)
+	}
+	defer r.Close()
+
+	r.Pulse(context.Background(), 100)
+	time.Sleep(50 * time.Millisecond)
+	r.Pulse(context.Background(), 200)
+
+	// The first pulse's relock time has passed but the second pulse is still running
+	time.Sleep(100 * time.Millisecond)
+	if !r.IsUnlocked() {
+		t.Errorf("expected second pulse to extend the unlock")
+	}
+	if levels := driver.history(); len(levels) != 2 {
+		t.Errorf("expected a single unlock without relocking, got %v", levels)
+	}
+
+	waitForLevels(t, driver, 3)
+	if r.IsUnlocked() {
+		t.Errorf("expected relay to relock after the extended pulse")
+	}
+}
+
+func TestRelay_LockAndClose(t *testing.T) {
+	driver := &fakeDriver{}
+	r, err := NewWithDriver(Config{Driver: "fake", PulseMs: 10000}, driver, testLogger())
+	if err != nil {
+		t.Fatalf("failed to create relay: %v", err)
+	}
+
+	if err := r.Pulse(context.Background(), 0); err != nil {
+		t.Fatalf("failed to pulse relay: %v", err)
+	}
+	if err := r.Lock(context.Background()); err != nil {
+		t.Fatalf("failed to lock relay: %v", err)
+	}
+	if r.IsUnlocked() {
+		t.Errorf("expected relay to be locked")
+	}
+
+	r.Pulse(context.Background(), 0)
+	if err := r.Close(); err != nil {
+		t.Fatalf("failed to close relay: %v", err)
+	}
+
+	levels := driver.history()
+	if !driver.closed || levels[len(levels)-1] {
+		t.Errorf("expected close to relock and release the output, got %v", levels)
+	}
+	if err := r.Pulse(context.Background(), 0); err == nil {
+		t.Errorf("expected error pulsing a closed relay")
+	}
+}
+
+func TestRelay_UnlockHolds(t *testing.T) {
+	driver := &fakeDriver{}
+	r, err := NewWithDriver(Config{Driver: "fake"}, driver, testLogger())
+	if err != nil {
+		t.Fatalf("failed to create relay: %v", err)
+	}
+	defer r.Close()
+
+	// A pending pulse must not relock a held door
+	r.Pulse(context.Background(), 30)
+	if err := r.Unlock(context.Background()); err != nil {
+		t.Fatalf("failed to hold relay unlocked: %v", err)
+	}
+	r.Pulse(context.Background(), 30)
+	time.Sleep(80 * time.Millisecond)
+	if !r.IsUnlocked() {
+		t.Errorf("expected relay to stay unlocked while held")
+	}
+	if held, _ := r.Status()["held"].(bool); !held {
+		t.Errorf("expected status to report the hold")
+	}
+	if levels := driver.history(); len(levels) != 2 {
+		t.Errorf("expected a single unlock, got %v", levels)
+	}
+
+	if err := r.Lock(context.Background()); err != nil {
+		t.Fatalf("failed to lock relay: %v", err)
+	}
+	if r.IsUnlocked() {
+		t.Errorf("expected relay to be locked")
+	}
+
+	// Pulses time out again once the hold has ended
+	r.Pulse(context.Background(), 30)
+	waitForLevels(t, driver, 5)
+	if r.IsUnlocked() {
+		t.Errorf("expected relay to relock after the pulse")
+	}
+}
+
+func TestRelay_DriverFailure(t *testing.T) {
+	driver := &fakeDriver{fail: true}
+	if _, err := NewWithDriver(Config{Driver: "fake"}, driver, testLogger()); err == nil {
+		t.Fatalf("expected error when the output cannot be locked")
+	}
+	if !driver.closed {
+		t.Errorf("expected driver to be closed after a failed start")
+	}
+
+	driver = &fakeDriver{}
+	r, err := NewWithDriver(Config{Driver: "fake"}, driver, testLogger())
+	if err != nil {
+		t.Fatalf("failed to create relay: %v", err)
+	}
+	defer r.Close()
+
+	driver.fail = true
+	if err := r.Pulse(context.Background(), 100); err == nil {
+		t.Errorf("expected pulse to fail")
+	}
+	if r.IsUnlocked() {
+		t.Errorf("expected relay to stay locked after a failed pulse")
+	}
+	if _, ok := r.Status()["error"]; !ok {
+		t.Errorf("expected status to report the output error")
+	}
+}
+
+func TestFromSettings(t *testing.T) {
+	config, err := FromSettings(map[string]interface{}{
+		"driver":    "gpiochip",
+		"line":      17.0,
+		"activeLow": true,
+		"mode":      "fail_safe",
+		"pulseMs":   5000,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if config.Chip != defaultGPIOChip || config.Line != 17 || !config.ActiveLow || config.Mode != ModeFailSafe || config.PulseMs != 5000 {
+		t.Errorf("unexpected config: %+v", config)
+	}
+
+	// Keys read through viper arrive lowercased
+	config, err = FromSettings(map[string]interface{}{
+		"driver": "http",
+		"onurl":  "http://relay/on",
+		"offurl": "http://relay/off",
+	})
+	if err != nil || config.OnURL != "http://relay/on" || config.OffURL != "http://relay/off" {
+		t.Errorf("expected lowercased keys to be accepted, got %+v (%v)", config, err)
+	}
+
+	invalid := []map[string]interface{}{
+		{},
+		{"driver": "carrier-pigeon"},
+		{"driver": "gpiochip", "mode": "fail_open"},
+		{"driver": "serial"},
+		{"driver": "http", "onUrl": "http://relay/on"},
+		{"driver": "modbus"},
+		{"driver": "modbus", "address": "10.0.0.5", "coil": 70000.0},
+	}
+	for _, settings := range invalid {
+		if _, err := FromSettings(settings); err == nil {
+			t.Errorf("expected error for settings %v", settings)
+		}
+	}
+
+	nested, ok := SettingsMap(map[string]interface{}{
+		"relay": map[interface{}]interface{}{"driver": "modbus", "address": "10.0.0.5"},
+	}, "relay")
+	if !ok || nested["driver"] != "modbus" {
+		t.Errorf("expected nested YAML map to be converted, got %v", nested)
+	}
+}
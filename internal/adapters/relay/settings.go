@@ -0,0 +1,95 @@
+package relay
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// FromSettings builds a relay configuration from an adapter settings map, such as
+// the "relay" entry of an adapter's settings or the door_relay config section
+func FromSettings(settings map[string]interface{}) (Config, error) {
+	var config Config
+	if len(settings) == 0 {
+		return config, fmt.Errorf("relay settings are empty")
+	}
+
+	config.Driver = stringSetting(settings, "driver")
+	config.PulseMs = intSetting(settings, "pulseMs")
+	config.ActiveLow, _ = setting(settings, "activeLow").(bool)
+	config.Mode = Mode(stringSetting(settings, "mode"))
+	config.Timeout = time.Duration(intSetting(settings, "timeoutMs")) * time.Millisecond
+
+	config.Chip = stringSetting(settings, "chip")
+	config.Line = intSetting(settings, "line")
+
+	config.DevicePath = stringSetting(settings, "devicePath")
+	config.BaudRate = intSetting(settings, "baudRate")
+	config.Channel = intSetting(settings, "channel")
+
+	config.OnURL = stringSetting(settings, "onUrl")
+	config.OffURL = stringSetting(settings, "offUrl")
+	config.Method = stringSetting(settings, "method")
+
+	config.Address = stringSetting(settings, "address")
+	config.UnitID = intSetting(settings, "unitId")
+	config.Coil = intSetting(settings, "coil")
+
+	config = config.withDefaults()
+	if err := config.Validate(); err != nil {
+		return config, err
+	}
+	return config, nil
+}
+
+// SettingsMap returns the nested settings map stored under key, if any
+func SettingsMap(settings map[string]interface{}, key string) (map[string]interface{}, bool) {
+	switch value := settings[key].(type) {
+	case map[string]interface{}:
+		return value, len(value) > 0
+	case map[interface{}]interface{}:
+		// YAML decoders produce untyped keys for nested maps
+		converted := make(map[string]interface{}, len(value))
+		for k, v := range value {
+			if name, ok := k.(string); ok {
+				converted[name] = v
+			}
+		}
+		return converted, len(converted) > 0
+	default:
+		return nil, false
+	}
+}
+
+// setting looks up a key, falling back to a case-insensitive match since viper
+// lowercases map keys read from the config file
+func setting(settings map[string]interface{}, key string) interface{} {
+	if value, ok := settings[key]; ok {
+		return value
+	}
+	for k, value := range settings {
+		if strings.EqualFold(k, key) {
+			return value
+		}
+	}
+	return nil
+}
+
+func stringSetting(settings map[string]interface{}, key string) string {
+	value, _ := setting(settings, key).(string)
+	return value
+}
+
+// intSetting accepts JSON numbers (float64) as well as integers from YAML
+func intSetting(settings map[string]interface{}, key string) int {
+	switch value := setting(settings, key).(type) {
+	case float64:
+		return int(value)
+	case int:
+		return value
+	case int64:
+		return int(value)
+	default:
+		return 0
+	}
+}
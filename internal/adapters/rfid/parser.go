@@ -0,0 +1,220 @@
+package rfid
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Card data formats recognized by the parser
+const (
+	FormatWiegand26 = "wiegand26"
+	FormatWiegand34 = "wiegand34"
+	FormatWiegand37 = "wiegand37"
+	FormatHexUID    = "hex"
+	FormatEM4100    = "em4100"
+	FormatBinaryUID = "binary"
+)
+
+// Framing bytes used by EM4100 serial readers (RDM6300 and compatibles)
+const (
+	frameSTX = 0x02
+	frameETX = 0x03
+)
+
+// CardRead is a decoded card presentation
+type CardRead struct {
+	Format       string `json:"format"`
+	CardID       string `json:"cardId"`                 // Identifier reported as the external user ID
+	FacilityCode int    `json:"facilityCode,omitempty"` // Wiegand only
+	CardNumber   uint64 `json:"cardNumber,omitempty"`   // Wiegand and EM4100
+	Raw          string `json:"raw"`                    // Frame as received, hex encoded when not printable
+}
+
+// wiegandLayout describes the field positions of a Wiegand frame
+type wiegandLayout struct {
+	format       string
+	facilityBits [2]int // [start, end) bit positions
+	cardBits     [2]int
+	evenParity   [2]int // Bits covered by the leading even parity bit
+	oddParity    [2]int // Bits covered by the trailing odd parity bit
+}
+
+var wiegandLayouts = map[int]wiegandLayout{
+	// H10301: P FFFFFFFF CCCCCCCCCCCCCCCC P
+	26: {FormatWiegand26, [2]int{1, 9}, [2]int{9, 25}, [2]int{1, 13}, [2]int{13, 25}},
+	// 34 bit: P FFFFFFFFFFFFFFFF CCCCCCCCCCCCCCCC P
+	34: {FormatWiegand34, [2]int{1, 17}, [2]int{17, 33}, [2]int{1, 17}, [2]int{17, 33}},
+	// H10304: P FFFFFFFFFFFFFFFF CCCCCCCCCCCCCCCCCCC P
+	37: {FormatWiegand37, [2]int{1, 17}, [2]int{17, 36}, [2]int{1, 19}, [2]int{18, 36}},
+}
+
+var (
+	// Wiegand converter output such as "W26:0x1A2B3C4" or "W34 2ABCDEF12"
+	wiegandHexPattern = regexp.MustCompile(`^W(26|34|37)[:= ]?\s*(?:0[xX])?([0-9A-Fa-f]+)$`)
+	// Wiegand converter output as a bit string
+	wiegandBitsPattern = regexp.MustCompile(`^[01]{26}$|^[01]{34}$|^[01]{37}$`)
+	// Raw UID output, optionally separated such as "04:A1:B2:C3"
+	hexUIDPattern = regexp.MustCompile(`^(?:0[xX])?[0-9A-Fa-f]{8,20}$`)
+)
+
+// ParseCardData decodes a single frame produced by a serial reader
+func ParseCardData(frame []byte) (*CardRead, error) {
+	if len(frame) > 0 && frame[0] == frameSTX {
+		return parseEM4100(frame)
+	}
+
+	line := strings.TrimSpace(string(frame))
+	if line == "" {
+		return nil, fmt.Errorf("empty card frame")
+	}
+
+	if !isPrintable(frame) {
+		// Some readers send the UID as raw bytes
+		uid := strings.ToUpper(hex.EncodeToString(frame))
+		return &CardRead{Format: FormatBinaryUID, CardID: uid, Raw: uid}, nil
+	}
+
+	if match := wiegandHexPattern.FindStringSubmatch(line); match != nil {
+		length, _ := strconv.Atoi(match[1])
+		value, err := strconv.ParseUint(match[2], 16, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid Wiegand value %q: %w", match[2], err)
+		}
+		return decodeWiegand(value, length, line)
+	}
+
+	if wiegandBitsPattern.MatchString(line) {
+		value, _ := strconv.ParseUint(line, 2, 64)
+		return decodeWiegand(value, len(line), line)
+	}
+
+	uid := strings.NewReplacer(":", "", "-", "", " ", "").Replace(line)
+	if hexUIDPattern.MatchString(uid) {
+		uid = strings.ToUpper(strings.TrimPrefix(strings.TrimPrefix(uid, "0x"), "0X"))
+		if len(uid)%2 != 0 {
+			return nil, fmt.Errorf("hex UID has odd length: %s", uid)
+		}
+		return &CardRead{Format: FormatHexUID, CardID: uid, Raw: line}, nil
+	}
+
+	return nil, fmt.Errorf("unrecognized card frame: %q", line)
+}
+
+// decodeWiegand extracts facility code and card number from a raw Wiegand frame and checks parity
+func decodeWiegand(value uint64, length int, raw string) (*CardRead, error) {
+	layout, ok := wiegandLayouts[length]
+	if !ok {
+		return nil, fmt.Errorf("unsupported Wiegand length: %d", length)
+	}
+	if length < 64 && value>>uint(length) != 0 {
+		return nil, fmt.Errorf("value does not fit in %d bits", length)
+	}
+
+	// Bit 0 is the first bit transmitted, i.e. the most significant bit of the value
+	bit := func(i int) uint64 {
+		return (value >> uint(length-1-i)) & 1
+	}
+	field := func(bits [2]int) uint64 {
+		var result uint64
+		for i := bits[0]; i < bits[1]; i++ {
+			result = result<<1 | bit(i)
+		}
+		return result
+	}
+	ones := func(bits [2]int) int {
+		count := 0
+		for i := bits[0]; i < bits[1]; i++ {
+			count += int(bit(i))
+		}
+		return count
+	}
+
+	if (ones(layout.evenParity)+int(bit(0)))%2 != 0 {
+		return nil, fmt.Errorf("%s even parity check failed", layout.format)
+	}
+	if (ones(layout.oddParity)+int(bit(length-1)))%2 != 1 {
+		return nil, fmt.Errorf("%s odd parity check failed", layout.format)
+	}
+
+	facility := int(field(layout.facilityBits))
+	card := field(layout.cardBits)
+
+	return &CardRead{
+		Format:       layout.format,
+		CardID:       fmt.Sprintf("%d:%d", facility, card),
+		FacilityCode: facility,
+		CardNumber:   card,
+		Raw:          raw,
+	}, nil
+}
+
+// parseEM4100 decodes an STX + 10 hex data + 2 hex checksum + ETX frame
+func parseEM4100(frame []byte) (*CardRead, error) {
+	frame = bytes.TrimRight(frame, "\r\n")
+	if len(frame) != 14 || frame[0] != frameSTX || frame[13] != frameETX {
+		return nil, fmt.Errorf("malformed EM4100 frame of %d bytes", len(frame))
+	}
+
+	payload := string(frame[1:13])
+	data, err := hex.DecodeString(payload)
+	if err != nil {
+		return nil, fmt.Errorf("invalid EM4100 frame data: %w", err)
+	}
+
+	var checksum byte
+	for _, b := range data[:5] {
+		checksum ^= b
+	}
+	if checksum != data[5] {
+		return nil, fmt.Errorf("EM4100 checksum mismatch: expected %02X, got %02X", checksum, data[5])
+	}
+
+	// The number printed on EM cards is the 32-bit ID below the version byte
+	number := uint64(data[1])<<24 | uint64(data[2])<<16 | uint64(data[3])<<8 | uint64(data[4])
+
+	return &CardRead{
+		Format:     FormatEM4100,
+		CardID:     fmt.Sprintf("%010d", number),
+		CardNumber: number,
+		Raw:        strings.ToUpper(payload),
+	}, nil
+}
+
+// splitFrames is a bufio.SplitFunc that yields newline terminated lines and STX/ETX frames
+func splitFrames(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	// Skip line terminators left between frames
+	start := 0
+	for start < len(data) && (data[start] == '\r' || data[start] == '\n') {
+		start++
+	}
+	if start == len(data) {
+		return start, nil, nil
+	}
+
+	if data[start] == frameSTX {
+		if end := bytes.IndexByte(data[start:], frameETX); end >= 0 {
+			return start + end + 1, data[start : start+end+1], nil
+		}
+	} else if end := bytes.IndexAny(data[start:], "\r\n\x02"); end >= 0 {
+		return start + end, data[start : start+end], nil
+	}
+
+	if atEOF {
+		return len(data), data[start:], nil
+	}
+	return start, nil, nil
+}
+
+// isPrintable reports whether a frame is printable ASCII text
+func isPrintable(frame []byte) bool {
+	for _, b := range frame {
+		if (b < 0x20 || b > 0x7e) && b != '\r' && b != '\n' && b != '\t' {
+			return false
+		}
+	}
+	return true
+}
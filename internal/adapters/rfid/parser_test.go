@@ -0,0 +1,168 @@
+package rfid
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+)
+
+func TestParseCardData(t *testing.T) {
+	tests := []struct {
+		name         string
+		frame        string
+		expectError  bool
+		expectFormat string
+		expectCardID string
+		expectFC     int
+		expectNumber uint64
+	}{
+		{
+			name:         "wiegand 26 hex",
+			frame:        "W26:0x2F623AE",
+			expectFormat: FormatWiegand26,
+			expectCardID: "123:4567",
+			expectFC:     123,
+			expectNumber: 4567,
+		},
+		{
+			name:         "wiegand 26 bit string",
+			frame:        "10111101100010001110101110\r",
+			expectFormat: FormatWiegand26,
+			expectCardID: "123:4567",
+			expectFC:     123,
+			expectNumber: 4567,
+		},
+		{
+			name:        "wiegand 26 bad even parity",
+			frame:       "00111101100010001110101110",
+			expectError: true,
+		},
+		{
+			name:        "wiegand 26 bad odd parity",
+			frame:       "W26 2F623AF",
+			expectError: true,
+		},
+		{
+			name:         "wiegand 34",
+			frame:        "W34 209A5BBAA",
+			expectFormat: FormatWiegand34,
+			expectCardID: "1234:56789",
+			expectFC:     1234,
+			expectNumber: 56789,
+		},
+		{
+			name:         "wiegand 37",
+			frame:        "W37=110E1C9572",
+			expectFormat: FormatWiegand37,
+			expectCardID: "4321:412345",
+			expectFC:     4321,
+			expectNumber: 412345,
+		},
+		{
+			name:        "wiegand value too wide",
+			frame:       "W26:0xFFFFFFFF",
+			expectError: true,
+		},
+		{
+			name:         "hex uid",
+			frame:        "04a1b2c3d4e580",
+			expectFormat: FormatHexUID,
+			expectCardID: "04A1B2C3D4E580",
+		},
+		{
+			name:         "separated hex uid",
+			frame:        "DE:AD:BE:EF",
+			expectFormat: FormatHexUID,
+			expectCardID: "DEADBEEF",
+		},
+		{
+			name:        "odd length hex uid",
+			frame:       "ABCDEF123",
+			expectError: true,
+		},
+		{
+			name:         "em4100 frame",
+			frame:        "\x020B00BC614E98\x03",
+			expectFormat: FormatEM4100,
+			expectCardID: "0012345678",
+			expectNumber: 12345678,
+		},
+		{
+			name:        "em4100 bad checksum",
+			frame:       "\x020B00BC614E99\x03",
+			expectError: true,
+		},
+		{
+			name:        "em4100 truncated",
+			frame:       "\x020B00BC61\x03",
+			expectError: true,
+		},
+		{
+			name:         "binary uid",
+			frame:        "\xaa\xbb\xcc\xdd",
+			expectFormat: FormatBinaryUID,
+			expectCardID: "AABBCCDD",
+		},
+		{
+			name:        "garbage",
+			frame:       "hello reader",
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			card, err := ParseCardData([]byte(tt.frame))
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("expected error but got %+v", card)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if card.Format != tt.expectFormat {
+				t.Errorf("expected format %s, got %s", tt.expectFormat, card.Format)
+			}
+			if card.CardID != tt.expectCardID {
+				t.Errorf("expected card ID %s, got %s", tt.expectCardID, card.CardID)
+			}
+			if card.FacilityCode != tt.expectFC {
+				t.Errorf("expected facility code %d, got %d", tt.expectFC, card.FacilityCode)
+			}
+			if card.CardNumber != tt.expectNumber {
+				t.Errorf("expected card number %d, got %d", tt.expectNumber, card.CardNumber)
+			}
+		})
+	}
+}
+
+func TestSplitFrames(t *testing.T) {
+	input := "W26:0x2F623AE\r\n\x020B00BC614E98\x03\x020B00BC614E98\x03\n04A1B2C3\npartial"
+
+	scanner := bufio.NewScanner(strings.NewReader(input))
+	scanner.Split(splitFrames)
+
+	var frames []string
+	for scanner.Scan() {
+		frames = append(frames, scanner.Text())
+	}
+
+	expected := []string{
+		"W26:0x2F623AE",
+		"\x020B00BC614E98\x03",
+		"\x020B00BC614E98\x03",
+		"04A1B2C3",
+		"partial",
+	}
+	if len(frames) != len(expected) {
+		t.Fatalf("expected %d frames, got %d: %q", len(expected), len(frames), frames)
+	}
+	for i := range expected {
+		if frames[i] != expected[i] {
+			t.Errorf("frame %d: expected %q, got %q", i, expected[i], frames[i])
+		}
+	}
+}
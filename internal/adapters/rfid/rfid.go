@@ -1,18 +1,29 @@
 package rfid
 
 import (
+	"bufio"
 	"context"
 	"fmt"
+	"io"
 	"log/slog"
 	"sync"
 	"time"
 
+	"gym-door-bridge/internal/adapters/relay"
+	"gym-door-bridge/internal/adapters/serial"
 	"gym-door-bridge/internal/types"
 )
 
+const (
+	// maxFrameSize bounds a single line or frame read from the reader
+	maxFrameSize = 256
 
+	minReopenBackoff = time.Second
+	maxReopenBackoff = 30 * time.Second
+)
 
-// RFIDAdapter implements the HardwareAdapter interface for RFID card readers
+// RFIDAdapter implements the HardwareAdapter interface for serial and USB RFID card readers,
+// including Wiegand to serial converter boxes
 type RFIDAdapter struct {
 	name          string
 	config        types.AdapterConfig
@@ -25,6 +36,18 @@ type RFIDAdapter struct {
 	baudRate      int
 	frequency     string
 	cardTypes     []string
+	debounce      time.Duration // Repeated reads of the same card within this window are ignored
+
+	port      io.ReadWriteCloser
+	cancel    context.CancelFunc
+	done      chan struct{}
+	lastCard  string
+	lastRead  time.Time
+	openPort  func(path string, baudRate int) (io.ReadWriteCloser, error)
+	reconnect bool // Set while the read loop is waiting to reopen the port
+
+	relayConfig *relay.Config // Door relay wired alongside the reader, if any
+	doorRelay   *relay.Relay
 }
 
 // NewRFIDAdapter creates a new RFID adapter instance
@@ -40,6 +63,8 @@ func NewRFIDAdapter(logger *slog.Logger) *RFIDAdapter {
 		baudRate:  9600,
 		frequency: "13.56MHz", // Default to HF RFID
 		cardTypes: []string{"mifare", "ntag"},
+		debounce:  1500 * time.Millisecond,
+		openPort:  serial.Open,
 	}
 }
 
@@ -54,9 +79,22 @@ func (r *RFIDAdapter) Initialize(ctx context.Context, config types.AdapterConfig
 	defer r.mutex.Unlock()
 
 	r.config = config
+	if config.Name != "" {
+		// Adapters of the same type are told apart by their configured name
+		r.name = config.Name
+		r.status.Name = config.Name
+	}
 	r.status.Status = types.StatusInitializing
 	r.status.UpdatedAt = time.Now()
 
+	// Reinitializing must not keep settings from a previous configuration
+	r.devicePath = ""
+	r.baudRate = 9600
+	r.frequency = "13.56MHz"
+	r.cardTypes = []string{"mifare", "ntag"}
+	r.debounce = 1500 * time.Millisecond
+	r.relayConfig = nil
+
 	// Parse configuration settings
 	if settings := config.Settings; settings != nil {
 		if devicePath, ok := settings["devicePath"].(string); ok {
@@ -68,6 +106,9 @@ func (r *RFIDAdapter) Initialize(ctx context.Context, config types.AdapterConfig
 		if frequency, ok := settings["frequency"].(string); ok {
 			r.frequency = frequency
 		}
+		if debounceMs, ok := settings["debounceMs"].(float64); ok {
+			r.debounce = time.Duration(debounceMs) * time.Millisecond
+		}
 		if cardTypes, ok := settings["cardTypes"].([]interface{}); ok {
 			r.cardTypes = make([]string, len(cardTypes))
 			for i, ct := range cardTypes {
@@ -93,14 +134,25 @@ func (r *RFIDAdapter) Initialize(ctx context.Context, config types.AdapterConfig
 		return fmt.Errorf("unsupported frequency: %s", r.frequency)
 	}
 
-	// TODO: Implement actual hardware initialization
-	// This would include:
-	// - Opening serial/USB connection to RFID reader
-	// - Configuring reader parameters (frequency, power, etc.)
-	// - Testing device connectivity
-	// - Loading device-specific drivers or libraries
-	// - Setting up card detection parameters
+	if r.baudRate <= 0 {
+		r.status.Status = types.StatusError
+		r.status.ErrorMessage = "invalid baudRate"
+		r.status.UpdatedAt = time.Now()
+		return fmt.Errorf("invalid baudRate: %d", r.baudRate)
+	}
+
+	if relaySettings, ok := relay.SettingsMap(config.Settings, "relay"); ok {
+		relayConfig, err := relay.FromSettings(relaySettings)
+		if err != nil {
+			r.status.Status = types.StatusError
+			r.status.ErrorMessage = "invalid relay configuration"
+			r.status.UpdatedAt = time.Now()
+			return fmt.Errorf("invalid relay configuration: %w", err)
+		}
+		r.relayConfig = &relayConfig
+	}
 
+	// The serial port is opened when listening starts so a missing reader does not block startup
 	r.status.Status = types.StatusActive
 	r.status.UpdatedAt = time.Now()
 	r.status.ErrorMessage = ""
@@ -115,7 +167,7 @@ func (r *RFIDAdapter) Initialize(ctx context.Context, config types.AdapterConfig
 	return nil
 }
 
-// StartListening begins listening for RFID card scan events
+// StartListening opens the reader's serial port and begins reading card scans
 func (r *RFIDAdapter) StartListening(ctx context.Context) error {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
@@ -128,47 +180,91 @@ func (r *RFIDAdapter) StartListening(ctx context.Context) error {
 		return fmt.Errorf("no event callback registered")
 	}
 
-	// TODO: Implement actual hardware communication
-	// This would include:
-	// - Starting communication with RFID reader
-	// - Setting up continuous card detection
-	// - Implementing protocol-specific message parsing
-	// - Handling different card types and formats
-	// - Managing read errors and retries
+	var doorRelay *relay.Relay
+	if r.relayConfig != nil {
+		var err error
+		doorRelay, err = relay.New(*r.relayConfig, r.logger)
+		if err != nil {
+			r.status.Status = types.StatusError
+			r.status.ErrorMessage = err.Error()
+			r.status.UpdatedAt = time.Now()
+			return fmt.Errorf("failed to open door relay: %w", err)
+		}
+	}
 
+	port, err := r.openPort(r.devicePath, r.baudRate)
+	if err != nil {
+		if doorRelay != nil {
+			doorRelay.Close()
+		}
+		r.status.Status = types.StatusError
+		r.status.ErrorMessage = err.Error()
+		r.status.UpdatedAt = time.Now()
+		return fmt.Errorf("failed to open RFID reader: %w", err)
+	}
+
+	listenCtx, cancel := context.WithCancel(ctx)
+	r.port = port
+	r.doorRelay = doorRelay
+	r.cancel = cancel
+	r.done = make(chan struct{})
 	r.isListening = true
 	r.status.Status = types.StatusActive
+	r.status.ErrorMessage = ""
 	r.status.UpdatedAt = time.Now()
 
-	r.logger.Info("RFID adapter started listening", "name", r.name)
-	
-	// For now, return an error indicating this is a framework implementation
-	return fmt.Errorf("RFID adapter is a framework implementation - actual hardware integration required")
+	go r.readLoop(listenCtx, port, r.done)
+
+	r.logger.Info("RFID adapter started listening",
+		"name", r.name,
+		"devicePath", r.devicePath,
+		"baudRate", r.baudRate)
+
+	return nil
 }
 
-// StopListening stops listening for RFID card scan events
+// StopListening stops reading and closes the serial port
 func (r *RFIDAdapter) StopListening(ctx context.Context) error {
 	r.mutex.Lock()
-	defer r.mutex.Unlock()
-
 	if !r.isListening {
+		r.mutex.Unlock()
 		return nil // Already stopped
 	}
 
-	// TODO: Implement actual hardware disconnection
-	// This would include:
-	// - Stopping card detection
-	// - Closing serial/USB connections
-	// - Cleaning up resources
-
 	r.isListening = false
+	cancel := r.cancel
+	done := r.done
+	port := r.port
+	r.port = nil
+	doorRelay := r.doorRelay
+	r.doorRelay = nil
+	r.mutex.Unlock()
+
+	// Closing the port unblocks the pending read
+	cancel()
+	if port != nil {
+		port.Close()
+	}
+	if doorRelay != nil {
+		if err := doorRelay.Close(); err != nil {
+			r.logger.Error("Failed to close door relay", "name", r.name, "error", err)
+		}
+	}
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+	}
+
+	r.mutex.Lock()
 	r.status.UpdatedAt = time.Now()
+	r.mutex.Unlock()
 
 	r.logger.Info("RFID adapter stopped listening", "name", r.name)
 	return nil
 }
 
-// UnlockDoor triggers door unlock via RFID reader (if supported)
+// UnlockDoor pulses the door relay configured alongside the reader; the reader itself has no door output
 func (r *RFIDAdapter) UnlockDoor(ctx context.Context, durationMs int) error {
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
@@ -177,19 +273,15 @@ func (r *RFIDAdapter) UnlockDoor(ctx context.Context, durationMs int) error {
 		return fmt.Errorf("RFID adapter is not active")
 	}
 
-	// TODO: Implement actual door unlock command
-	// This would include:
-	// - Sending unlock command to RFID reader
-	// - Handling device-specific unlock protocols
-	// - Managing unlock duration and automatic re-lock
-	// - Providing feedback (LED, beep, etc.)
-
 	r.logger.Info("Door unlock requested via RFID adapter",
 		"adapter", r.name,
 		"durationMs", durationMs)
 
-	// For now, return an error indicating this is a framework implementation
-	return fmt.Errorf("door unlock not implemented - framework implementation only")
+	if r.doorRelay == nil {
+		return fmt.Errorf("RFID reader has no door output and no relay is configured")
+	}
+
+	return r.doorRelay.Pulse(ctx, durationMs)
 }
 
 // GetStatus returns the current adapter status
@@ -210,39 +302,155 @@ func (r *RFIDAdapter) OnEvent(callback types.EventCallback) {
 func (r *RFIDAdapter) IsHealthy() bool {
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
-	
-	// TODO: Implement actual health check
-	// This would include:
-	// - Testing communication with RFID reader
-	// - Checking device status and error conditions
-	// - Verifying hardware connectivity
-	// - Testing card detection capability
-	
-	return r.status.Status == types.StatusActive
+
+	return r.status.Status == types.StatusActive && !r.reconnect
+}
+
+// readLoop reads frames from the reader until stopped, reopening the port if the reader is unplugged
+func (r *RFIDAdapter) readLoop(ctx context.Context, port io.ReadWriteCloser, done chan struct{}) {
+	defer close(done)
+
+	backoff := minReopenBackoff
+	for {
+		err := r.readFrames(port)
+		port.Close()
+
+		if ctx.Err() != nil {
+			return
+		}
+
+		r.logger.Error("RFID reader disconnected", "name", r.name, "devicePath", r.devicePath, "error", err)
+		r.setReconnecting(err)
+
+		// Wait for the reader to come back
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+
+			port, err = r.openPort(r.devicePath, r.baudRate)
+			if err == nil {
+				break
+			}
+
+			backoff *= 2
+			if backoff > maxReopenBackoff {
+				backoff = maxReopenBackoff
+			}
+		}
+
+		r.mutex.Lock()
+		if !r.isListening {
+			r.mutex.Unlock()
+			port.Close()
+			return
+		}
+		r.port = port
+		r.reconnect = false
+		r.status.Status = types.StatusActive
+		r.status.ErrorMessage = ""
+		r.status.UpdatedAt = time.Now()
+		r.mutex.Unlock()
+
+		backoff = minReopenBackoff
+		r.logger.Info("RFID reader reconnected", "name", r.name, "devicePath", r.devicePath)
+	}
 }
 
-// processRawCardData converts raw RFID card data to standardized event
-// This is a helper method that would be called by the actual hardware integration
+// readFrames scans frames from the port and reports card reads until the port fails
+func (r *RFIDAdapter) readFrames(port io.Reader) error {
+	scanner := bufio.NewScanner(port)
+	scanner.Buffer(make([]byte, maxFrameSize), maxFrameSize)
+	scanner.Split(splitFrames)
+
+	for scanner.Scan() {
+		frame := scanner.Bytes()
+		if len(frame) == 0 {
+			continue
+		}
+
+		event, err := r.processRawCardData(frame)
+		if err != nil {
+			r.logger.Warn("Discarding unreadable card frame", "name", r.name, "error", err)
+			continue
+		}
+
+		if r.isRepeatRead(event.ExternalUserID) {
+			continue
+		}
+
+		r.mutex.Lock()
+		callback := r.eventCallback
+		r.status.LastEvent = time.Now()
+		r.status.UpdatedAt = time.Now()
+		r.mutex.Unlock()
+
+		if callback != nil {
+			callback(*event)
+		}
+
+		r.logger.Debug("Card read processed", "name", r.name, "cardId", event.ExternalUserID, "format", event.RawData["cardType"])
+	}
+
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return io.EOF
+}
+
+// isRepeatRead reports whether the card was just read; readers repeat frames while a card stays in the field
+func (r *RFIDAdapter) isRepeatRead(cardID string) bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	now := time.Now()
+	repeat := cardID == r.lastCard && now.Sub(r.lastRead) < r.debounce
+	r.lastCard = cardID
+	r.lastRead = now
+	return repeat
+}
+
+// setReconnecting records that the reader is unavailable
+func (r *RFIDAdapter) setReconnecting(err error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.reconnect = true
+	r.port = nil
+	r.status.Status = types.StatusError
+	r.status.ErrorMessage = fmt.Sprintf("reader disconnected: %v", err)
+	r.status.UpdatedAt = time.Now()
+}
+
+// processRawCardData converts a raw frame from the reader into a standardized event
 func (r *RFIDAdapter) processRawCardData(rawData []byte) (*types.RawHardwareEvent, error) {
-	// TODO: Implement card data parsing
-	// This would include:
-	// - Parsing different card formats (Wiegand, etc.)
-	// - Extracting card ID/UID
-	// - Handling different card types
-	// - Converting to standardized event format
-
-	// Example implementation structure:
+	card, err := ParseCardData(rawData)
+	if err != nil {
+		return nil, err
+	}
+
+	data := map[string]interface{}{
+		"rfid":      true,
+		"frequency": r.frequency,
+		"cardType":  card.Format,
+		"cardId":    card.CardID,
+		"raw":       card.Raw,
+	}
+	if card.Format == FormatWiegand26 || card.Format == FormatWiegand34 || card.Format == FormatWiegand37 {
+		data["facilityCode"] = card.FacilityCode
+	}
+	if card.CardNumber != 0 {
+		data["cardNumber"] = card.CardNumber
+	}
+
 	event := &types.RawHardwareEvent{
-		ExternalUserID: "rfid_placeholder", // Would be extracted from rawData
+		ExternalUserID: card.CardID,
 		Timestamp:      time.Now(),
-		EventType:      types.EventTypeEntry, // Would be determined from card read result
-		RawData: map[string]interface{}{
-			"rfid":      true,
-			"frequency": r.frequency,
-			"cardType":  "mifare", // Would be detected from card data
-			"cardId":    "placeholder_id",
-			"rssi":      -45, // Signal strength if available
-		},
+		EventType:      types.EventTypeEntry, // Readers only report presentations; the access decision happens downstream
+		VerifyMode:     types.VerifyModeCard,
+		RawData:        data,
 	}
 
 	return event, nil
@@ -251,9 +459,9 @@ func (r *RFIDAdapter) processRawCardData(rawData []byte) (*types.RawHardwareEven
 // GetSupportedFrequencies returns a list of supported RFID frequencies
 func GetSupportedFrequencies() []string {
 	return []string{
-		"125kHz",   // LF RFID
-		"134.2kHz", // LF RFID (animal tags)
-		"13.56MHz", // HF RFID (NFC, Mifare)
+		"125kHz",     // LF RFID
+		"134.2kHz",   // LF RFID (animal tags)
+		"13.56MHz",   // HF RFID (NFC, Mifare)
 		"860-960MHz", // UHF RFID
 	}
 }
@@ -291,4 +499,4 @@ func ValidateCardType(cardType string) bool {
 		}
 	}
 	return false
-}
\ No newline at end of file
+}
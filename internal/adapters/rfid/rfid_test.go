@@ -117,10 +117,10 @@ func TestRFIDAdapter_StartStopListening(t *testing.T) {
 	// Register callback
 	adapter.OnEvent(func(event types.RawHardwareEvent) {})
 
-	// Start listening - should fail as this is framework implementation
+	// Start listening - should fail as no reader is attached
 	err = adapter.StartListening(context.Background())
 	if err == nil {
-		t.Error("expected error for framework implementation")
+		t.Error("expected error opening a missing reader")
 	}
 
 	// Stop listening should work even if not started
@@ -149,10 +149,10 @@ func TestRFIDAdapter_UnlockDoor(t *testing.T) {
 		t.Fatalf("failed to initialize adapter: %v", err)
 	}
 
-	// UnlockDoor should fail as this is framework implementation
+	// UnlockDoor should fail as no door relay is configured
 	err = adapter.UnlockDoor(context.Background(), 3000)
 	if err == nil {
-		t.Error("expected error for framework implementation")
+		t.Error("expected error for reader without door output")
 	}
 }
 
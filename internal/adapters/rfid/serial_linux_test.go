@@ -0,0 +1,239 @@
+//go:build linux
+
+package rfid
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/sys/unix"
+
+	"gym-door-bridge/internal/types"
+)
+
+// openPTY creates a pseudo terminal pair standing in for a USB serial reader.
+// Writes to the returned master appear on the slave device path.
+func openPTY(t *testing.T) (*os.File, string) {
+	t.Helper()
+
+	master, err := os.OpenFile("/dev/ptmx", os.O_RDWR|unix.O_NOCTTY, 0)
+	if err != nil {
+		t.Skipf("pseudo terminals unavailable: %v", err)
+	}
+	t.Cleanup(func() { master.Close() })
+
+	fd := int(master.Fd())
+	if err := unix.IoctlSetPointerInt(fd, unix.TIOCSPTLCK, 0); err != nil {
+		t.Fatalf("failed to unlock pty: %v", err)
+	}
+	n, err := unix.IoctlGetInt(fd, unix.TIOCGPTN)
+	if err != nil {
+		t.Fatalf("failed to get pty number: %v", err)
+	}
+
+	return master, fmt.Sprintf("/dev/pts/%d", n)
+}
+
+func newPTYAdapter(t *testing.T, devicePath string, events chan types.RawHardwareEvent) *RFIDAdapter {
+	return newPTYAdapterWithSettings(t, devicePath, events, nil)
+}
+
+func newPTYAdapterWithSettings(t *testing.T, devicePath string, events chan types.RawHardwareEvent, extra map[string]interface{}) *RFIDAdapter {
+	t.Helper()
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	adapter := NewRFIDAdapter(logger)
+
+	settings := map[string]interface{}{
+		"devicePath": devicePath,
+		"baudRate":   9600.0,
+		"frequency":  "125kHz",
+		"debounceMs": 500.0,
+	}
+	for key, value := range extra {
+		settings[key] = value
+	}
+
+	err := adapter.Initialize(context.Background(), types.AdapterConfig{
+		Name:     "rfid",
+		Enabled:  true,
+		Settings: settings,
+	})
+	if err != nil {
+		t.Fatalf("failed to initialize adapter: %v", err)
+	}
+
+	adapter.OnEvent(func(event types.RawHardwareEvent) {
+		events <- event
+	})
+	return adapter
+}
+
+func waitForCard(t *testing.T, events chan types.RawHardwareEvent) types.RawHardwareEvent {
+	t.Helper()
+
+	select {
+	case event := <-events:
+		return event
+	case <-time.After(2 * time.Second):
+		t.Fatalf("timed out waiting for card read")
+		return types.RawHardwareEvent{}
+	}
+}
+
+func TestRFIDAdapter_ReadLoopPTY(t *testing.T) {
+	master, slavePath := openPTY(t)
+	events := make(chan types.RawHardwareEvent, 10)
+	adapter := newPTYAdapter(t, slavePath, events)
+
+	if err := adapter.StartListening(context.Background()); err != nil {
+		t.Fatalf("failed to start listening: %v", err)
+	}
+	defer adapter.StopListening(context.Background())
+
+	// A Wiegand converter line, then an EM4100 reader repeating the same card
+	if _, err := master.Write([]byte("W26:0x2F623AE\r\n")); err != nil {
+		t.Fatalf("failed to write to pty: %v", err)
+	}
+	event := waitForCard(t, events)
+	if event.ExternalUserID != "123:4567" || event.RawData["cardType"] != FormatWiegand26 {
+		t.Errorf("unexpected Wiegand event: %+v", event)
+	}
+	if event.RawData["facilityCode"] != 123 {
+		t.Errorf("expected facility code in raw data, got %v", event.RawData["facilityCode"])
+	}
+
+	master.Write([]byte("\x020B00BC614E98\x03\x020B00BC614E98\x03\x020B00BC614E98\x03"))
+	event = waitForCard(t, events)
+	if event.ExternalUserID != "0012345678" || event.EventType != types.EventTypeEntry {
+		t.Errorf("unexpected EM4100 event: %+v", event)
+	}
+
+	// Corrupt frames are dropped without stopping the loop
+	master.Write([]byte("W26:0x2F623AF\n04A1B2C3D4E580\n"))
+	event = waitForCard(t, events)
+	if event.ExternalUserID != "04A1B2C3D4E580" {
+		t.Errorf("unexpected hex UID event: %+v", event)
+	}
+
+	select {
+	case extra := <-events:
+		t.Errorf("repeated frames should be debounced, got %+v", extra)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// The same card is reported again once the debounce window has passed
+	time.Sleep(600 * time.Millisecond)
+	master.Write([]byte("04A1B2C3D4E580\n"))
+	if event := waitForCard(t, events); event.ExternalUserID != "04A1B2C3D4E580" {
+		t.Errorf("unexpected event after debounce window: %+v", event)
+	}
+
+	if !adapter.IsHealthy() {
+		t.Errorf("expected adapter to be healthy")
+	}
+}
+
+func TestRFIDAdapter_StopListeningUnblocksRead(t *testing.T) {
+	_, slavePath := openPTY(t)
+	events := make(chan types.RawHardwareEvent, 1)
+	adapter := newPTYAdapter(t, slavePath, events)
+
+	if err := adapter.StartListening(context.Background()); err != nil {
+		t.Fatalf("failed to start listening: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	start := time.Now()
+	if err := adapter.StopListening(ctx); err != nil {
+		t.Fatalf("failed to stop listening: %v", err)
+	}
+	if ctx.Err() != nil || time.Since(start) > time.Second {
+		t.Errorf("read loop did not stop promptly")
+	}
+}
+
+func TestRFIDAdapter_ReaderUnplugged(t *testing.T) {
+	master, slavePath := openPTY(t)
+	events := make(chan types.RawHardwareEvent, 1)
+	adapter := newPTYAdapter(t, slavePath, events)
+
+	if err := adapter.StartListening(context.Background()); err != nil {
+		t.Fatalf("failed to start listening: %v", err)
+	}
+	defer adapter.StopListening(context.Background())
+
+	// Closing the master side makes reads on the slave fail like a removed USB reader
+	master.Close()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for adapter.IsHealthy() && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if adapter.IsHealthy() {
+		t.Errorf("expected adapter to be unhealthy after the reader was removed")
+	}
+	if adapter.GetStatus().ErrorMessage == "" {
+		t.Errorf("expected disconnect to be recorded in status")
+	}
+}
+
+func TestRFIDAdapter_UnlockDoorRelay(t *testing.T) {
+	_, slavePath := openPTY(t)
+
+	var mutex sync.Mutex
+	var switched []string
+	relayModule := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		switched = append(switched, r.URL.Path)
+		mutex.Unlock()
+	}))
+	defer relayModule.Close()
+
+	adapter := newPTYAdapterWithSettings(t, slavePath, make(chan types.RawHardwareEvent, 1), map[string]interface{}{
+		"relay": map[string]interface{}{
+			"driver": "http",
+			"onUrl":  relayModule.URL + "/on",
+			"offUrl": relayModule.URL + "/off",
+		},
+	})
+
+	if err := adapter.StartListening(context.Background()); err != nil {
+		t.Fatalf("failed to start listening: %v", err)
+	}
+	if err := adapter.UnlockDoor(context.Background(), 50); err != nil {
+		t.Fatalf("failed to unlock door: %v", err)
+	}
+
+	time.Sleep(150 * time.Millisecond)
+	if err := adapter.StopListening(context.Background()); err != nil {
+		t.Fatalf("failed to stop listening: %v", err)
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	expected := []string{"/off", "/on", "/off", "/off"}
+	if len(switched) != len(expected) {
+		t.Fatalf("expected relay requests %v, got %v", expected, switched)
+	}
+	for i := range expected {
+		if switched[i] != expected[i] {
+			t.Errorf("expected relay requests %v, got %v", expected, switched)
+			break
+		}
+	}
+
+	if err := adapter.UnlockDoor(context.Background(), 50); err == nil {
+		t.Errorf("expected unlock to fail once the relay is released")
+	}
+}
@@ -0,0 +1,51 @@
+//go:build linux
+
+package serial
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"golang.org/x/sys/unix"
+)
+
+// openPTY creates a pseudo terminal pair standing in for a USB serial device.
+// Writes to the returned master appear on the slave device path.
+func openPTY(t *testing.T) (*os.File, string) {
+	t.Helper()
+
+	master, err := os.OpenFile("/dev/ptmx", os.O_RDWR|unix.O_NOCTTY, 0)
+	if err != nil {
+		t.Skipf("pseudo terminals unavailable: %v", err)
+	}
+	t.Cleanup(func() { master.Close() })
+
+	fd := int(master.Fd())
+	if err := unix.IoctlSetPointerInt(fd, unix.TIOCSPTLCK, 0); err != nil {
+		t.Fatalf("failed to unlock pty: %v", err)
+	}
+	n, err := unix.IoctlGetInt(fd, unix.TIOCGPTN)
+	if err != nil {
+		t.Fatalf("failed to get pty number: %v", err)
+	}
+
+	return master, fmt.Sprintf("/dev/pts/%d", n)
+}
+
+func TestOpen_PTY(t *testing.T) {
+	_, slavePath := openPTY(t)
+
+	port, err := Open(slavePath, 115200)
+	if err != nil {
+		t.Fatalf("failed to open pty as serial port: %v", err)
+	}
+	port.Close()
+
+	if _, err := Open(slavePath, 12345); err == nil {
+		t.Errorf("expected error for unsupported baud rate")
+	}
+	if _, err := Open("/dev/null", 9600); err == nil {
+		t.Errorf("expected error for a device that is not a terminal")
+	}
+}
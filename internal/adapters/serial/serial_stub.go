@@ -0,0 +1,14 @@
+//go:build !linux && !windows
+
+package serial
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+)
+
+// Open is not implemented on this platform
+func Open(path string, baudRate int) (io.ReadWriteCloser, error) {
+	return nil, fmt.Errorf("serial ports are not supported on %s", runtime.GOOS)
+}
@@ -0,0 +1,141 @@
+//go:build windows
+
+package serial
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var (
+	kernel32         = windows.NewLazySystemDLL("kernel32.dll")
+	procGetCommState = kernel32.NewProc("GetCommState")
+	procSetCommState = kernel32.NewProc("SetCommState")
+)
+
+// dcb mirrors the Win32 DCB structure
+type dcb struct {
+	DCBlength  uint32
+	BaudRate   uint32
+	Flags      uint32
+	wReserved  uint16
+	XonLim     uint16
+	XoffLim    uint16
+	ByteSize   byte
+	Parity     byte
+	StopBits   byte
+	XonChar    byte
+	XoffChar   byte
+	ErrorChar  byte
+	EofChar    byte
+	EvtChar    byte
+	wReserved1 uint16
+}
+
+const (
+	dcbBinary           = 0x0001
+	dcbDtrControlEnable = 0x0010
+	dcbRtsControlEnable = 0x1000
+	noParity            = 0
+	oneStopBit          = 0
+	maxDWORD            = 0xFFFFFFFF
+	readPollTimeoutMs   = 500
+)
+
+// windowsSerialPort wraps a COM port handle; reads poll so Close can stop a blocked reader
+type windowsSerialPort struct {
+	handle windows.Handle
+	closed atomic.Bool
+	readMu sync.Mutex // Held during ReadFile so the handle is not closed under a pending read
+}
+
+// Open opens a COM port in 8N1 mode at the given baud rate
+func Open(path string, baudRate int) (io.ReadWriteCloser, error) {
+	// COM10 and above are only reachable through the device namespace
+	if !strings.HasPrefix(path, `\\.\`) {
+		path = `\\.\` + path
+	}
+
+	name, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid serial port name %s: %w", path, err)
+	}
+
+	handle, err := windows.CreateFile(name, windows.GENERIC_READ|windows.GENERIC_WRITE, 0, nil, windows.OPEN_EXISTING, 0, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open serial port %s: %w", path, err)
+	}
+
+	state := dcb{DCBlength: uint32(unsafe.Sizeof(dcb{}))}
+	if r, _, err := procGetCommState.Call(uintptr(handle), uintptr(unsafe.Pointer(&state))); r == 0 {
+		windows.CloseHandle(handle)
+		return nil, fmt.Errorf("%s is not a serial device: %w", path, err)
+	}
+
+	state.BaudRate = uint32(baudRate)
+	state.Flags = dcbBinary | dcbDtrControlEnable | dcbRtsControlEnable
+	state.ByteSize = 8
+	state.Parity = noParity
+	state.StopBits = oneStopBit
+	if r, _, err := procSetCommState.Call(uintptr(handle), uintptr(unsafe.Pointer(&state))); r == 0 {
+		windows.CloseHandle(handle)
+		return nil, fmt.Errorf("failed to configure serial port %s: %w", path, err)
+	}
+
+	// Return whatever is buffered, waiting at most readPollTimeoutMs for the first byte
+	timeouts := windows.CommTimeouts{
+		ReadIntervalTimeout:        maxDWORD,
+		ReadTotalTimeoutMultiplier: maxDWORD,
+		ReadTotalTimeoutConstant:   readPollTimeoutMs,
+	}
+	if err := windows.SetCommTimeouts(handle, &timeouts); err != nil {
+		windows.CloseHandle(handle)
+		return nil, fmt.Errorf("failed to set serial port timeouts %s: %w", path, err)
+	}
+
+	return &windowsSerialPort{handle: handle}, nil
+}
+
+// Read blocks until data arrives or the port is closed
+func (p *windowsSerialPort) Read(b []byte) (int, error) {
+	p.readMu.Lock()
+	defer p.readMu.Unlock()
+
+	for {
+		if p.closed.Load() {
+			return 0, io.EOF
+		}
+
+		var n uint32
+		if err := windows.ReadFile(p.handle, b, &n, nil); err != nil {
+			return int(n), err
+		}
+		if n > 0 {
+			return int(n), nil
+		}
+	}
+}
+
+// Write writes to the port
+func (p *windowsSerialPort) Write(b []byte) (int, error) {
+	var n uint32
+	err := windows.WriteFile(p.handle, b, &n, nil)
+	return int(n), err
+}
+
+// Close closes the port, unblocking any pending Read within the poll timeout
+func (p *windowsSerialPort) Close() error {
+	if p.closed.Swap(true) {
+		return nil
+	}
+
+	p.readMu.Lock()
+	defer p.readMu.Unlock()
+	return windows.CloseHandle(p.handle)
+}
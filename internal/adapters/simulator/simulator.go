@@ -57,6 +57,11 @@ func (s *SimulatorAdapter) Initialize(ctx context.Context, config types.AdapterC
 	defer s.mutex.Unlock()
 
 	s.config = config
+	if config.Name != "" {
+		// Adapters of the same type are told apart by their configured name
+		s.name = config.Name
+		s.status.Name = config.Name
+	}
 	s.status.Status = types.StatusInitializing
 	s.status.UpdatedAt = time.Now()
 
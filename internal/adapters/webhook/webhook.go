@@ -65,6 +65,11 @@ func (w *WebhookAdapter) Initialize(ctx context.Context, config types.AdapterCon
 	defer w.mutex.Unlock()
 
 	w.config = config
+	if config.Name != "" {
+		// Adapters of the same type are told apart by their configured name
+		w.name = config.Name
+		w.status.Name = config.Name
+	}
 	w.status.Status = types.StatusInitializing
 	w.status.UpdatedAt = time.Now()
 
@@ -0,0 +1,113 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gym-door-bridge/internal/config"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeAntiPassbackController keeps anti-passback states in memory by member
+type fakeAntiPassbackController struct {
+	MockDoorController
+	states map[string][]AntiPassbackState
+}
+
+func (f *fakeAntiPassbackController) GetAntiPassbackStates(internalUserID string) ([]AntiPassbackState, error) {
+	return f.states[internalUserID], nil
+}
+
+func (f *fakeAntiPassbackController) ResetAntiPassback(ctx context.Context, internalUserID string) (int64, error) {
+	var count int64
+	for member, states := range f.states {
+		if internalUserID == "" || member == internalUserID {
+			count += int64(len(states))
+			delete(f.states, member)
+		}
+	}
+	return count, nil
+}
+
+func setupAntiPassbackTestHandlers() (*Handlers, *fakeAntiPassbackController) {
+	cfg := config.DefaultConfig()
+	logger := logrus.New()
+	logger.SetLevel(logrus.ErrorLevel)
+
+	controller := &fakeAntiPassbackController{states: map[string][]AntiPassbackState{
+		"user_1": {{Zone: "gym", State: "in", DoorID: "front", UpdatedAt: time.Now()}},
+		"user_2": {{Zone: "gym", State: "out", DoorID: "front", UpdatedAt: time.Now()}, {Zone: "pool", State: "in", UpdatedAt: time.Now()}},
+	}}
+	handlers := NewHandlers(cfg, logger, &MockAdapterRegistry{}, controller, nil, nil, nil, nil, "test-version", "test-device-id")
+
+	return handlers, controller
+}
+
+func TestHandlers_MemberAntiPassback(t *testing.T) {
+	handlers, controller := setupAntiPassbackTestHandlers()
+
+	req := mux.SetURLVars(httptest.NewRequest("GET", "/api/v1/members/user_1/anti-passback", nil), map[string]string{"id": "user_1"})
+	w := httptest.NewRecorder()
+	handlers.GetMemberAntiPassback(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response AntiPassbackStateResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "user_1", response.MemberID)
+	if assert.Len(t, response.States, 1) {
+		assert.Equal(t, "in", response.States[0].State)
+	}
+
+	// Members without state get an empty list
+	req = mux.SetURLVars(httptest.NewRequest("GET", "/api/v1/members/user_3/anti-passback", nil), map[string]string{"id": "user_3"})
+	w = httptest.NewRecorder()
+	handlers.GetMemberAntiPassback(w, req)
+	assert.JSONEq(t, `[]`, string(mustField(t, w.Body.Bytes(), "states")))
+
+	req = mux.SetURLVars(httptest.NewRequest("DELETE", "/api/v1/members/user_1/anti-passback", nil), map[string]string{"id": "user_1"})
+	w = httptest.NewRecorder()
+	handlers.ResetMemberAntiPassback(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var reset AntiPassbackResetResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &reset))
+	assert.Equal(t, int64(1), reset.ResetCount)
+	assert.NotContains(t, controller.states, "user_1")
+	assert.Contains(t, controller.states, "user_2")
+
+	w = httptest.NewRecorder()
+	handlers.ResetAllAntiPassback(w, httptest.NewRequest("DELETE", "/api/v1/anti-passback", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &reset))
+	assert.Equal(t, int64(2), reset.ResetCount)
+	assert.Empty(t, controller.states)
+}
+
+func TestHandlers_AntiPassbackUnavailable(t *testing.T) {
+	handlers, _, _ := setupTestHandlers()
+
+	req := mux.SetURLVars(httptest.NewRequest("GET", "/api/v1/members/user_1/anti-passback", nil), map[string]string{"id": "user_1"})
+	w := httptest.NewRecorder()
+	handlers.GetMemberAntiPassback(w, req)
+	assert.Equal(t, http.StatusNotImplemented, w.Code)
+
+	w = httptest.NewRecorder()
+	handlers.ResetAllAntiPassback(w, httptest.NewRequest("DELETE", "/api/v1/anti-passback", nil))
+	assert.Equal(t, http.StatusNotImplemented, w.Code)
+}
+
+// mustField returns the raw JSON of a top-level field
+func mustField(t *testing.T, body []byte, field string) json.RawMessage {
+	t.Helper()
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		t.Fatalf("invalid JSON response: %v", err)
+	}
+	return fields[field]
+}
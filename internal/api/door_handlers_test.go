@@ -14,6 +14,7 @@ import (
 	"gym-door-bridge/internal/config"
 	"gym-door-bridge/internal/types"
 
+	"github.com/gorilla/mux"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -536,4 +537,188 @@ func TestGetInt64FromStats(t *testing.T) {
 			assert.Equal(t, tt.expected, result)
 		})
 	}
-}
\ No newline at end of file
+}
+// MockMultiDoorController is a mock door controller that also manages individual doors
+type MockMultiDoorController struct {
+	MockDoorController
+}
+
+func (m *MockMultiDoorController) ListDoors() []DoorInfo {
+	args := m.Called()
+	return args.Get(0).([]DoorInfo)
+}
+
+func (m *MockMultiDoorController) GetDoor(doorID string) *DoorInfo {
+	args := m.Called(doorID)
+	door, _ := args.Get(0).(*DoorInfo)
+	return door
+}
+
+func (m *MockMultiDoorController) UnlockDoorByID(ctx context.Context, doorID string, durationMs int) error {
+	args := m.Called(ctx, doorID, durationMs)
+	return args.Error(0)
+}
+
+func (m *MockMultiDoorController) LockDoorByID(ctx context.Context, doorID string) error {
+	args := m.Called(ctx, doorID)
+	return args.Error(0)
+}
+
+func setupMultiDoorTestHandlers() (*Handlers, *MockMultiDoorController) {
+	cfg := config.DefaultConfig()
+	logger := logrus.New()
+	logger.SetLevel(logrus.ErrorLevel)
+
+	mockDoorController := &MockMultiDoorController{}
+	handlers := NewHandlers(cfg, logger, &MockAdapterRegistry{}, mockDoorController, nil, nil, nil, nil, "test-version", "test-device-id")
+
+	return handlers, mockDoorController
+}
+
+func TestHandlers_DoorsByID(t *testing.T) {
+	front := &DoorInfo{ID: "front", Name: "Front Door", Zone: "lobby", Adapters: []string{"zkteco"}, IsLocked: true, Position: "closed", Relay: map[string]interface{}{"driver": "gpiochip"}}
+
+	tests := []struct {
+		name           string
+		method         string
+		doorID         string
+		action         string
+		body           string
+		setupMocks     func(*MockMultiDoorController)
+		expectedStatus int
+	}{
+		{
+			name:   "status",
+			method: "GET",
+			doorID: "front",
+			action: "status",
+			setupMocks: func(dc *MockMultiDoorController) {
+				dc.On("GetDoor", "front").Return(front)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "status of unknown door",
+			method: "GET",
+			doorID: "side",
+			action: "status",
+			setupMocks: func(dc *MockMultiDoorController) {
+				dc.On("GetDoor", "side").Return(nil)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:   "unlock with door default duration",
+			method: "POST",
+			doorID: "front",
+			action: "unlock",
+			setupMocks: func(dc *MockMultiDoorController) {
+				dc.On("GetDoor", "front").Return(front)
+				dc.On("UnlockDoorByID", mock.Anything, "front", 0).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "unlock with duration",
+			method: "POST",
+			doorID: "front",
+			action: "unlock",
+			body:   `{"durationMs": 5000}`,
+			setupMocks: func(dc *MockMultiDoorController) {
+				dc.On("GetDoor", "front").Return(front)
+				dc.On("UnlockDoorByID", mock.Anything, "front", 5000).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "unlock with invalid duration",
+			method:         "POST",
+			doorID:         "front",
+			action:         "unlock",
+			body:           `{"durationMs": 500}`,
+			setupMocks:     func(dc *MockMultiDoorController) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "unlock failure",
+			method: "POST",
+			doorID: "front",
+			action: "unlock",
+			setupMocks: func(dc *MockMultiDoorController) {
+				dc.On("GetDoor", "front").Return(front)
+				dc.On("UnlockDoorByID", mock.Anything, "front", 0).Return(fmt.Errorf("relay offline"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+		{
+			name:   "lock",
+			method: "POST",
+			doorID: "front",
+			action: "lock",
+			setupMocks: func(dc *MockMultiDoorController) {
+				dc.On("GetDoor", "front").Return(front)
+				dc.On("LockDoorByID", mock.Anything, "front").Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "lock unknown door",
+			method: "POST",
+			doorID: "side",
+			action: "lock",
+			setupMocks: func(dc *MockMultiDoorController) {
+				dc.On("GetDoor", "side").Return(nil)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handlers, mockDoorController := setupMultiDoorTestHandlers()
+			tt.setupMocks(mockDoorController)
+
+			req := httptest.NewRequest(tt.method, "/api/v1/doors/"+tt.doorID+"/"+tt.action, bytes.NewBufferString(tt.body))
+			req = mux.SetURLVars(req, map[string]string{"id": tt.doorID})
+			w := httptest.NewRecorder()
+
+			switch tt.action {
+			case "status":
+				handlers.DoorStatusByID(w, req)
+			case "unlock":
+				handlers.UnlockDoorByID(w, req)
+			case "lock":
+				handlers.LockDoorByID(w, req)
+			}
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK && tt.action == "status" {
+				var response DoorStatusResponse
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.Equal(t, "front", response.DoorID)
+				assert.Equal(t, DoorStatusLocked, response.Status)
+			}
+			mockDoorController.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandlers_ListDoors(t *testing.T) {
+	handlers, mockDoorController := setupMultiDoorTestHandlers()
+	mockDoorController.On("ListDoors").Return([]DoorInfo{{ID: "front"}, {ID: "back"}})
+
+	w := httptest.NewRecorder()
+	handlers.ListDoors(w, httptest.NewRequest("GET", "/api/v1/doors", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response DoorListResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 2, response.Total)
+	assert.Equal(t, "back", response.Doors[1].ID)
+
+	// Controllers without per-door support report the endpoints as unavailable
+	single, _, _ := setupTestHandlers()
+	w = httptest.NewRecorder()
+	single.ListDoors(w, httptest.NewRequest("GET", "/api/v1/doors", nil))
+	assert.Equal(t, http.StatusNotImplemented, w.Code)
+}
@@ -586,7 +586,7 @@ func TestEventQueryRequest_Validate(t *testing.T) {
 				EventType: "invalid",
 			},
 			expectError: true,
-			errorMsg:    "eventType must be one of: entry, exit, denied",
+			errorMsg:    "eventType must be one of: entry, exit, denied, door_forced_open, door_held_open",
 		},
 		{
 			name: "invalid sort field",
@@ -680,7 +680,7 @@ func TestEventClearRequest_Validate(t *testing.T) {
 				Confirm:   true,
 			},
 			expectError: true,
-			errorMsg:    "eventType must be one of: entry, exit, denied",
+			errorMsg:    "eventType must be one of: entry, exit, denied, door_forced_open, door_held_open",
 		},
 		{
 			name: "both onlySent and onlyFailed true",
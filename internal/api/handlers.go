@@ -30,6 +30,53 @@ type DoorController interface {
 	GetStats() map[string]interface{}
 }
 
+// MultiDoorController is implemented by door controllers that manage several doors.
+// The per-door endpoints are only available when the door controller implements it.
+type MultiDoorController interface {
+	ListDoors() []DoorInfo
+	GetDoor(doorID string) *DoorInfo
+	UnlockDoorByID(ctx context.Context, doorID string, durationMs int) error
+	LockDoorByID(ctx context.Context, doorID string) error
+}
+
+// DoorScheduleManager is implemented by door controllers that hold doors unlocked on
+// a schedule and support lockdown. The schedule, holiday and lockdown endpoints are
+// only available when the door controller implements it.
+type DoorScheduleManager interface {
+	ListSchedules() ([]DoorSchedule, error)
+	UpsertSchedule(ctx context.Context, schedule DoorSchedule) (*DoorSchedule, error)
+	DeleteSchedule(ctx context.Context, id string) (bool, error)
+	ListHolidays() ([]DoorHoliday, error)
+	UpsertHoliday(ctx context.Context, holiday DoorHoliday) (*DoorHoliday, error)
+	DeleteHoliday(ctx context.Context, id string) (bool, error)
+	GetLockdown() *DoorLockdown
+	SetLockdown(ctx context.Context, reason, activatedBy string) (*DoorLockdown, error)
+	ClearLockdown(ctx context.Context) error
+}
+
+// AntiPassbackManager is implemented by door controllers backed by the local access
+// engine with anti-passback enabled. The anti-passback endpoints are only
+// available when the door controller implements it.
+type AntiPassbackManager interface {
+	GetAntiPassbackStates(internalUserID string) ([]AntiPassbackState, error)
+	ResetAntiPassback(ctx context.Context, internalUserID string) (int64, error)
+}
+
+// UserProvisioner is implemented by adapter registries that provision roster
+// members onto biometric terminals. The provisioning endpoint is only available
+// when the adapter registry implements it.
+type UserProvisioner interface {
+	ReconcileUsers(ctx context.Context, dryRun bool) (*ProvisioningReport, error)
+}
+
+// TemplateManager is implemented by adapter registries that back up fingerprint
+// templates and copy them between terminals. The template endpoints are only
+// available when the site has consented to template backup.
+type TemplateManager interface {
+	SyncTemplates(ctx context.Context) (*TemplateSyncReport, error)
+	RestoreTemplates(ctx context.Context, adapterName string) (*TemplateSyncReport, error)
+}
+
 // HealthMonitor interface for health monitoring
 type HealthMonitor interface {
 	GetCurrentHealth() SystemHealth
@@ -254,6 +301,11 @@ func (h *Handlers) UnlockDoor(w http.ResponseWriter, r *http.Request) {
 		"clientIP":    getClientIP(r),
 	}).Info("Door unlock requested")
 	
+	if h.inLockdown() {
+		h.writeErrorResponseLegacy(w, "Doors are in lockdown", http.StatusLocked, "DOOR_LOCKDOWN", requestID)
+		return
+	}
+	
 	// Perform unlock operation
 	if err := h.doorController.UnlockDoor(ctx, req.Adapter, req.DurationMs); err != nil {
 		h.logger.WithError(err).WithField("requestId", requestID).Error("Failed to unlock door")
@@ -397,6 +449,742 @@ func (h *Handlers) DoorStatus(w http.ResponseWriter, r *http.Request) {
 	h.writeJSONResponse(w, response, http.StatusOK)
 }
 
+// multiDoorController returns the door controller's per-door interface, writing an
+// error response when the controller does not manage individual doors
+func (h *Handlers) multiDoorController(w http.ResponseWriter, requestID string) (MultiDoorController, bool) {
+	doors, ok := h.doorController.(MultiDoorController)
+	if !ok {
+		h.writeErrorResponseLegacy(w, "Per-door control is not available", http.StatusNotImplemented, "MULTI_DOOR_UNAVAILABLE", requestID)
+		return nil, false
+	}
+	return doors, true
+}
+
+// ListDoors handles GET /api/v1/doors
+func (h *Handlers) ListDoors(w http.ResponseWriter, r *http.Request) {
+	requestID := h.generateRequestID()
+	
+	doors, ok := h.multiDoorController(w, requestID)
+	if !ok {
+		return
+	}
+	
+	list := doors.ListDoors()
+	if list == nil {
+		list = []DoorInfo{}
+	}
+	
+	response := DoorListResponse{
+		Doors:     list,
+		Total:     len(list),
+		Timestamp: time.Now().UTC(),
+		RequestID: requestID,
+	}
+	
+	h.writeJSONResponse(w, response, http.StatusOK)
+}
+
+// DoorStatusByID handles GET /api/v1/doors/{id}/status
+func (h *Handlers) DoorStatusByID(w http.ResponseWriter, r *http.Request) {
+	doorID := mux.Vars(r)["id"]
+	requestID := h.generateRequestID()
+	
+	doors, ok := h.multiDoorController(w, requestID)
+	if !ok {
+		return
+	}
+	
+	door := doors.GetDoor(doorID)
+	if door == nil {
+		h.writeErrorResponseLegacy(w, fmt.Sprintf("Door '%s' not found", doorID), http.StatusNotFound, "DOOR_NOT_FOUND", requestID)
+		return
+	}
+	
+	status := DoorStatusLocked
+	if !door.IsLocked {
+		status = DoorStatusUnlocked
+	}
+	
+	response := DoorStatusResponse{
+		DoorID:         door.ID,
+		DoorName:       door.Name,
+		Zone:           door.Zone,
+		IsLocked:       door.IsLocked,
+		Status:         status,
+		Position:       door.Position,
+		LastUnlockTime: door.LastUnlockTime,
+		UnlockCount:    door.UnlockCount,
+		ActiveAdapters: door.Adapters,
+		Timestamp:      time.Now().UTC(),
+	}
+	
+	h.logger.WithFields(logrus.Fields{
+		"requestId": requestID,
+		"doorId":    doorID,
+		"status":    status,
+	}).Debug("Door status requested")
+	
+	h.writeJSONResponse(w, response, http.StatusOK)
+}
+
+// UnlockDoorByID handles POST /api/v1/doors/{id}/unlock
+func (h *Handlers) UnlockDoorByID(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	doorID := mux.Vars(r)["id"]
+	requestID := h.generateRequestID()
+	
+	doors, ok := h.multiDoorController(w, requestID)
+	if !ok {
+		return
+	}
+	
+	// The body is optional; without a duration the door's configured duration applies
+	var req DoorUnlockRequest
+	if r.ContentLength > 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			h.logger.WithError(err).Error("Failed to decode unlock request")
+			h.writeErrorResponseLegacy(w, "Invalid JSON in request body", http.StatusBadRequest, "INVALID_JSON", requestID)
+			return
+		}
+	}
+	if req.DurationMs != 0 {
+		if err := req.Validate(); err != nil {
+			h.writeErrorResponseLegacy(w, err.Error(), http.StatusBadRequest, "VALIDATION_ERROR", requestID)
+			return
+		}
+	}
+	
+	door := doors.GetDoor(doorID)
+	if door == nil {
+		h.writeErrorResponseLegacy(w, fmt.Sprintf("Door '%s' not found", doorID), http.StatusNotFound, "DOOR_NOT_FOUND", requestID)
+		return
+	}
+	
+	h.logger.WithFields(logrus.Fields{
+		"requestId":   requestID,
+		"doorId":      doorID,
+		"durationMs":  req.DurationMs,
+		"reason":      req.Reason,
+		"requestedBy": req.RequestedBy,
+		"clientIP":    getClientIP(r),
+	}).Info("Door unlock requested")
+	
+	if h.inLockdown() {
+		h.writeErrorResponseLegacy(w, "Doors are in lockdown", http.StatusLocked, "DOOR_LOCKDOWN", requestID)
+		return
+	}
+	
+	if err := doors.UnlockDoorByID(ctx, doorID, req.DurationMs); err != nil {
+		h.logger.WithError(err).WithField("requestId", requestID).Error("Failed to unlock door")
+		h.writeErrorResponseLegacy(w, fmt.Sprintf("Failed to unlock door: %v", err), http.StatusInternalServerError, "UNLOCK_FAILED", requestID)
+		return
+	}
+	
+	adapterName := "relay"
+	if door.Relay == nil && len(door.Adapters) > 0 {
+		adapterName = door.Adapters[0]
+	}
+	
+	response := DoorUnlockResponse{
+		Success:   true,
+		Message:   "Door unlocked successfully",
+		Duration:  req.DurationMs,
+		Adapter:   adapterName,
+		DoorID:    doorID,
+		Timestamp: time.Now().UTC(),
+		RequestID: requestID,
+	}
+	
+	h.writeJSONResponse(w, response, http.StatusOK)
+}
+
+// LockDoorByID handles POST /api/v1/doors/{id}/lock
+func (h *Handlers) LockDoorByID(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	doorID := mux.Vars(r)["id"]
+	requestID := h.generateRequestID()
+	
+	doors, ok := h.multiDoorController(w, requestID)
+	if !ok {
+		return
+	}
+	
+	if doors.GetDoor(doorID) == nil {
+		h.writeErrorResponseLegacy(w, fmt.Sprintf("Door '%s' not found", doorID), http.StatusNotFound, "DOOR_NOT_FOUND", requestID)
+		return
+	}
+	
+	h.logger.WithFields(logrus.Fields{
+		"requestId": requestID,
+		"doorId":    doorID,
+		"clientIP":  getClientIP(r),
+	}).Info("Door lock requested")
+	
+	if err := doors.LockDoorByID(ctx, doorID); err != nil {
+		h.logger.WithError(err).WithField("requestId", requestID).Error("Failed to lock door")
+		h.writeErrorResponseLegacy(w, fmt.Sprintf("Failed to lock door: %v", err), http.StatusInternalServerError, "LOCK_FAILED", requestID)
+		return
+	}
+	
+	response := DoorLockResponse{
+		Success:   true,
+		Message:   "Door locked",
+		Adapter:   "relay",
+		DoorID:    doorID,
+		Timestamp: time.Now().UTC(),
+		RequestID: requestID,
+	}
+	
+	h.writeJSONResponse(w, response, http.StatusOK)
+}
+
+// doorScheduleManager returns the door controller's schedule interface, writing an
+// error response when the controller does not support schedules
+func (h *Handlers) doorScheduleManager(w http.ResponseWriter, requestID string) (DoorScheduleManager, bool) {
+	schedules, ok := h.doorController.(DoorScheduleManager)
+	if !ok {
+		h.writeErrorResponseLegacy(w, "Door schedules are not available", http.StatusNotImplemented, "SCHEDULES_UNAVAILABLE", requestID)
+		return nil, false
+	}
+	return schedules, true
+}
+
+// inLockdown reports whether the door controller has an active lockdown
+func (h *Handlers) inLockdown() bool {
+	schedules, ok := h.doorController.(DoorScheduleManager)
+	return ok && schedules.GetLockdown() != nil
+}
+
+// ListDoorSchedules handles GET /api/v1/schedules
+func (h *Handlers) ListDoorSchedules(w http.ResponseWriter, r *http.Request) {
+	requestID := h.generateRequestID()
+	
+	schedules, ok := h.doorScheduleManager(w, requestID)
+	if !ok {
+		return
+	}
+	
+	list, err := schedules.ListSchedules()
+	if err != nil {
+		h.logger.WithError(err).WithField("requestId", requestID).Error("Failed to list door schedules")
+		h.writeErrorResponseLegacy(w, "Failed to list door schedules", http.StatusInternalServerError, "SCHEDULE_LIST_FAILED", requestID)
+		return
+	}
+	if list == nil {
+		list = []DoorSchedule{}
+	}
+	
+	response := DoorScheduleListResponse{
+		Schedules: list,
+		Total:     len(list),
+		Timestamp: time.Now().UTC(),
+		RequestID: requestID,
+	}
+	
+	h.writeJSONResponse(w, response, http.StatusOK)
+}
+
+// UpsertDoorSchedule handles PUT /api/v1/schedules/{id}
+func (h *Handlers) UpsertDoorSchedule(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	scheduleID := mux.Vars(r)["id"]
+	requestID := h.generateRequestID()
+	
+	schedules, ok := h.doorScheduleManager(w, requestID)
+	if !ok {
+		return
+	}
+	
+	var req DoorScheduleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.WithError(err).Error("Failed to decode schedule request")
+		h.writeErrorResponseLegacy(w, "Invalid JSON in request body", http.StatusBadRequest, "INVALID_JSON", requestID)
+		return
+	}
+	if err := req.Validate(); err != nil {
+		h.writeErrorResponseLegacy(w, err.Error(), http.StatusBadRequest, "VALIDATION_ERROR", requestID)
+		return
+	}
+	
+	// Reject doors this bridge does not serve
+	if doors, ok := h.doorController.(MultiDoorController); ok {
+		for _, doorID := range req.DoorIDs {
+			if doors.GetDoor(doorID) == nil {
+				h.writeErrorResponseLegacy(w, fmt.Sprintf("Door '%s' not found", doorID), http.StatusBadRequest, "VALIDATION_ERROR", requestID)
+				return
+			}
+		}
+	}
+	
+	schedule := DoorSchedule{
+		ID:      scheduleID,
+		Name:    req.Name,
+		DoorIDs: req.DoorIDs,
+		Windows: req.Windows,
+		Enabled: req.Enabled == nil || *req.Enabled,
+	}
+	
+	saved, err := schedules.UpsertSchedule(ctx, schedule)
+	if err != nil {
+		h.logger.WithError(err).WithField("requestId", requestID).Error("Failed to save door schedule")
+		h.writeErrorResponseLegacy(w, fmt.Sprintf("Failed to save door schedule: %v", err), http.StatusInternalServerError, "SCHEDULE_UPDATE_FAILED", requestID)
+		return
+	}
+	
+	h.logger.WithFields(logrus.Fields{
+		"requestId":  requestID,
+		"scheduleId": scheduleID,
+		"doorIds":    req.DoorIDs,
+		"enabled":    schedule.Enabled,
+		"clientIP":   getClientIP(r),
+	}).Info("Door schedule saved")
+	
+	h.writeJSONResponse(w, saved, http.StatusOK)
+}
+
+// DeleteDoorSchedule handles DELETE /api/v1/schedules/{id}
+func (h *Handlers) DeleteDoorSchedule(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	scheduleID := mux.Vars(r)["id"]
+	requestID := h.generateRequestID()
+	
+	schedules, ok := h.doorScheduleManager(w, requestID)
+	if !ok {
+		return
+	}
+	
+	deleted, err := schedules.DeleteSchedule(ctx, scheduleID)
+	if err != nil {
+		h.logger.WithError(err).WithField("requestId", requestID).Error("Failed to delete door schedule")
+		h.writeErrorResponseLegacy(w, fmt.Sprintf("Failed to delete door schedule: %v", err), http.StatusInternalServerError, "SCHEDULE_DELETE_FAILED", requestID)
+		return
+	}
+	if !deleted {
+		h.writeErrorResponseLegacy(w, fmt.Sprintf("Schedule '%s' not found", scheduleID), http.StatusNotFound, "SCHEDULE_NOT_FOUND", requestID)
+		return
+	}
+	
+	h.logger.WithFields(logrus.Fields{
+		"requestId":  requestID,
+		"scheduleId": scheduleID,
+		"clientIP":   getClientIP(r),
+	}).Info("Door schedule deleted")
+	
+	response := DoorScheduleDeleteResponse{
+		Success:   true,
+		Message:   "Schedule deleted",
+		ID:        scheduleID,
+		Timestamp: time.Now().UTC(),
+		RequestID: requestID,
+	}
+	
+	h.writeJSONResponse(w, response, http.StatusOK)
+}
+
+// ListDoorHolidays handles GET /api/v1/holidays
+func (h *Handlers) ListDoorHolidays(w http.ResponseWriter, r *http.Request) {
+	requestID := h.generateRequestID()
+	
+	schedules, ok := h.doorScheduleManager(w, requestID)
+	if !ok {
+		return
+	}
+	
+	list, err := schedules.ListHolidays()
+	if err != nil {
+		h.logger.WithError(err).WithField("requestId", requestID).Error("Failed to list holidays")
+		h.writeErrorResponseLegacy(w, "Failed to list holidays", http.StatusInternalServerError, "HOLIDAY_LIST_FAILED", requestID)
+		return
+	}
+	if list == nil {
+		list = []DoorHoliday{}
+	}
+	
+	response := DoorHolidayListResponse{
+		Holidays:  list,
+		Total:     len(list),
+		Timestamp: time.Now().UTC(),
+		RequestID: requestID,
+	}
+	
+	h.writeJSONResponse(w, response, http.StatusOK)
+}
+
+// UpsertDoorHoliday handles PUT /api/v1/holidays/{id}
+func (h *Handlers) UpsertDoorHoliday(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	holidayID := mux.Vars(r)["id"]
+	requestID := h.generateRequestID()
+	
+	schedules, ok := h.doorScheduleManager(w, requestID)
+	if !ok {
+		return
+	}
+	
+	var req DoorHolidayRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.WithError(err).Error("Failed to decode holiday request")
+		h.writeErrorResponseLegacy(w, "Invalid JSON in request body", http.StatusBadRequest, "INVALID_JSON", requestID)
+		return
+	}
+	if err := req.Validate(); err != nil {
+		h.writeErrorResponseLegacy(w, err.Error(), http.StatusBadRequest, "VALIDATION_ERROR", requestID)
+		return
+	}
+	
+	holiday := DoorHoliday{
+		ID:    holidayID,
+		Name:  req.Name,
+		Date:  req.Date,
+		Start: req.Start,
+		End:   req.End,
+	}
+	
+	saved, err := schedules.UpsertHoliday(ctx, holiday)
+	if err != nil {
+		h.logger.WithError(err).WithField("requestId", requestID).Error("Failed to save holiday")
+		h.writeErrorResponseLegacy(w, fmt.Sprintf("Failed to save holiday: %v", err), http.StatusInternalServerError, "HOLIDAY_UPDATE_FAILED", requestID)
+		return
+	}
+	
+	h.logger.WithFields(logrus.Fields{
+		"requestId": requestID,
+		"holidayId": holidayID,
+		"date":      req.Date,
+		"clientIP":  getClientIP(r),
+	}).Info("Holiday saved")
+	
+	h.writeJSONResponse(w, saved, http.StatusOK)
+}
+
+// DeleteDoorHoliday handles DELETE /api/v1/holidays/{id}
+func (h *Handlers) DeleteDoorHoliday(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	holidayID := mux.Vars(r)["id"]
+	requestID := h.generateRequestID()
+	
+	schedules, ok := h.doorScheduleManager(w, requestID)
+	if !ok {
+		return
+	}
+	
+	deleted, err := schedules.DeleteHoliday(ctx, holidayID)
+	if err != nil {
+		h.logger.WithError(err).WithField("requestId", requestID).Error("Failed to delete holiday")
+		h.writeErrorResponseLegacy(w, fmt.Sprintf("Failed to delete holiday: %v", err), http.StatusInternalServerError, "HOLIDAY_DELETE_FAILED", requestID)
+		return
+	}
+	if !deleted {
+		h.writeErrorResponseLegacy(w, fmt.Sprintf("Holiday '%s' not found", holidayID), http.StatusNotFound, "HOLIDAY_NOT_FOUND", requestID)
+		return
+	}
+	
+	h.logger.WithFields(logrus.Fields{
+		"requestId": requestID,
+		"holidayId": holidayID,
+		"clientIP":  getClientIP(r),
+	}).Info("Holiday deleted")
+	
+	response := DoorScheduleDeleteResponse{
+		Success:   true,
+		Message:   "Holiday deleted",
+		ID:        holidayID,
+		Timestamp: time.Now().UTC(),
+		RequestID: requestID,
+	}
+	
+	h.writeJSONResponse(w, response, http.StatusOK)
+}
+
+// GetDoorLockdown handles GET /api/v1/lockdown
+func (h *Handlers) GetDoorLockdown(w http.ResponseWriter, r *http.Request) {
+	requestID := h.generateRequestID()
+	
+	schedules, ok := h.doorScheduleManager(w, requestID)
+	if !ok {
+		return
+	}
+	
+	lockdown := schedules.GetLockdown()
+	response := DoorLockdownResponse{
+		Active:    lockdown != nil,
+		Lockdown:  lockdown,
+		Timestamp: time.Now().UTC(),
+		RequestID: requestID,
+	}
+	
+	h.writeJSONResponse(w, response, http.StatusOK)
+}
+
+// ActivateDoorLockdown handles POST /api/v1/lockdown
+func (h *Handlers) ActivateDoorLockdown(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	requestID := h.generateRequestID()
+	
+	schedules, ok := h.doorScheduleManager(w, requestID)
+	if !ok {
+		return
+	}
+	
+	var req DoorLockdownRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.WithError(err).Error("Failed to decode lockdown request")
+		h.writeErrorResponseLegacy(w, "Invalid JSON in request body", http.StatusBadRequest, "INVALID_JSON", requestID)
+		return
+	}
+	if err := req.Validate(); err != nil {
+		h.writeErrorResponseLegacy(w, err.Error(), http.StatusBadRequest, "VALIDATION_ERROR", requestID)
+		return
+	}
+	
+	h.logger.WithFields(logrus.Fields{
+		"requestId":   requestID,
+		"reason":      req.Reason,
+		"requestedBy": req.RequestedBy,
+		"clientIP":    getClientIP(r),
+	}).Warn("Door lockdown requested")
+	
+	lockdown, err := schedules.SetLockdown(ctx, req.Reason, req.RequestedBy)
+	if err != nil {
+		h.logger.WithError(err).WithField("requestId", requestID).Error("Failed to activate lockdown")
+		h.writeErrorResponseLegacy(w, fmt.Sprintf("Failed to activate lockdown: %v", err), http.StatusInternalServerError, "LOCKDOWN_FAILED", requestID)
+		return
+	}
+	
+	response := DoorLockdownResponse{
+		Active:    true,
+		Lockdown:  lockdown,
+		Timestamp: time.Now().UTC(),
+		RequestID: requestID,
+	}
+	
+	h.writeJSONResponse(w, response, http.StatusOK)
+}
+
+// ClearDoorLockdown handles DELETE /api/v1/lockdown
+func (h *Handlers) ClearDoorLockdown(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	requestID := h.generateRequestID()
+	
+	schedules, ok := h.doorScheduleManager(w, requestID)
+	if !ok {
+		return
+	}
+	
+	h.logger.WithFields(logrus.Fields{
+		"requestId": requestID,
+		"clientIP":  getClientIP(r),
+	}).Info("Door lockdown clear requested")
+	
+	if err := schedules.ClearLockdown(ctx); err != nil {
+		h.logger.WithError(err).WithField("requestId", requestID).Error("Failed to clear lockdown")
+		h.writeErrorResponseLegacy(w, fmt.Sprintf("Failed to clear lockdown: %v", err), http.StatusInternalServerError, "LOCKDOWN_CLEAR_FAILED", requestID)
+		return
+	}
+	
+	response := DoorLockdownResponse{
+		Active:    false,
+		Timestamp: time.Now().UTC(),
+		RequestID: requestID,
+	}
+	
+	h.writeJSONResponse(w, response, http.StatusOK)
+}
+
+// antiPassbackManager returns the door controller's anti-passback interface, writing
+// an error response when anti-passback is not available
+func (h *Handlers) antiPassbackManager(w http.ResponseWriter, requestID string) (AntiPassbackManager, bool) {
+	antiPassback, ok := h.doorController.(AntiPassbackManager)
+	if !ok {
+		h.writeErrorResponseLegacy(w, "Anti-passback is not available", http.StatusNotImplemented, "ANTI_PASSBACK_UNAVAILABLE", requestID)
+		return nil, false
+	}
+	return antiPassback, true
+}
+
+// GetMemberAntiPassback handles GET /api/v1/members/{id}/anti-passback
+func (h *Handlers) GetMemberAntiPassback(w http.ResponseWriter, r *http.Request) {
+	requestID := h.generateRequestID()
+	memberID := mux.Vars(r)["id"]
+	
+	antiPassback, ok := h.antiPassbackManager(w, requestID)
+	if !ok {
+		return
+	}
+	
+	states, err := antiPassback.GetAntiPassbackStates(memberID)
+	if err != nil {
+		h.logger.WithError(err).WithField("requestId", requestID).Error("Failed to get anti-passback state")
+		h.writeErrorResponseLegacy(w, fmt.Sprintf("Failed to get anti-passback state: %v", err), http.StatusInternalServerError, "ANTI_PASSBACK_FAILED", requestID)
+		return
+	}
+	if states == nil {
+		states = []AntiPassbackState{}
+	}
+	
+	response := AntiPassbackStateResponse{
+		MemberID:  memberID,
+		States:    states,
+		Timestamp: time.Now().UTC(),
+		RequestID: requestID,
+	}
+	
+	h.writeJSONResponse(w, response, http.StatusOK)
+}
+
+// ResetMemberAntiPassback handles DELETE /api/v1/members/{id}/anti-passback
+func (h *Handlers) ResetMemberAntiPassback(w http.ResponseWriter, r *http.Request) {
+	h.resetAntiPassback(w, r, mux.Vars(r)["id"])
+}
+
+// ResetAllAntiPassback handles DELETE /api/v1/anti-passback
+func (h *Handlers) ResetAllAntiPassback(w http.ResponseWriter, r *http.Request) {
+	h.resetAntiPassback(w, r, "")
+}
+
+// resetAntiPassback forgets a member's anti-passback state, or every member's
+// state when memberID is empty
+func (h *Handlers) resetAntiPassback(w http.ResponseWriter, r *http.Request, memberID string) {
+	ctx := r.Context()
+	requestID := h.generateRequestID()
+	
+	antiPassback, ok := h.antiPassbackManager(w, requestID)
+	if !ok {
+		return
+	}
+	
+	h.logger.WithFields(logrus.Fields{
+		"requestId": requestID,
+		"memberId":  memberID,
+		"clientIP":  getClientIP(r),
+	}).Info("Anti-passback reset requested")
+	
+	count, err := antiPassback.ResetAntiPassback(ctx, memberID)
+	if err != nil {
+		h.logger.WithError(err).WithField("requestId", requestID).Error("Failed to reset anti-passback state")
+		h.writeErrorResponseLegacy(w, fmt.Sprintf("Failed to reset anti-passback state: %v", err), http.StatusInternalServerError, "ANTI_PASSBACK_RESET_FAILED", requestID)
+		return
+	}
+	
+	message := fmt.Sprintf("Anti-passback state reset for member %s", memberID)
+	if memberID == "" {
+		message = "Anti-passback state reset for all members"
+	}
+	
+	response := AntiPassbackResetResponse{
+		Success:    true,
+		Message:    message,
+		MemberID:   memberID,
+		ResetCount: count,
+		Timestamp:  time.Now().UTC(),
+		RequestID:  requestID,
+	}
+	
+	h.writeJSONResponse(w, response, http.StatusOK)
+}
+
+// ReconcileUsers handles POST /api/v1/provisioning/reconcile
+func (h *Handlers) ReconcileUsers(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	requestID := h.generateRequestID()
+	
+	provisioner, ok := h.adapterRegistry.(UserProvisioner)
+	if !ok {
+		h.writeErrorResponseLegacy(w, "Terminal user provisioning is not available", http.StatusNotImplemented, "PROVISIONING_UNAVAILABLE", requestID)
+		return
+	}
+	
+	// The body is optional; without it the changes are applied
+	var req ProvisioningRequest
+	if r.ContentLength > 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			h.logger.WithError(err).Error("Failed to decode provisioning request")
+			h.writeErrorResponseLegacy(w, "Invalid JSON in request body", http.StatusBadRequest, "INVALID_JSON", requestID)
+			return
+		}
+	}
+	
+	h.logger.WithFields(logrus.Fields{
+		"requestId": requestID,
+		"dryRun":    req.DryRun,
+		"clientIP":  getClientIP(r),
+	}).Info("Terminal user reconciliation requested")
+	
+	report, err := provisioner.ReconcileUsers(ctx, req.DryRun)
+	if err != nil {
+		h.logger.WithError(err).WithField("requestId", requestID).Error("Failed to reconcile terminal users")
+		h.writeErrorResponseLegacy(w, fmt.Sprintf("Failed to reconcile terminal users: %v", err), http.StatusInternalServerError, "PROVISIONING_FAILED", requestID)
+		return
+	}
+	if report.Actions == nil {
+		report.Actions = []ProvisioningAction{}
+	}
+	report.RequestID = requestID
+	
+	h.writeJSONResponse(w, report, http.StatusOK)
+}
+
+// SyncTemplates handles POST /api/v1/templates/sync
+func (h *Handlers) SyncTemplates(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	requestID := h.generateRequestID()
+	
+	templates, ok := h.adapterRegistry.(TemplateManager)
+	if !ok {
+		h.writeErrorResponseLegacy(w, "Fingerprint template backup is not enabled for this site", http.StatusNotImplemented, "TEMPLATES_UNAVAILABLE", requestID)
+		return
+	}
+	
+	h.logger.WithFields(logrus.Fields{
+		"requestId": requestID,
+		"clientIP":  getClientIP(r),
+	}).Info("Fingerprint template sync requested")
+	
+	report, err := templates.SyncTemplates(ctx)
+	if err != nil {
+		h.logger.WithError(err).WithField("requestId", requestID).Error("Failed to sync fingerprint templates")
+		h.writeErrorResponseLegacy(w, fmt.Sprintf("Failed to sync fingerprint templates: %v", err), http.StatusInternalServerError, "TEMPLATE_SYNC_FAILED", requestID)
+		return
+	}
+	report.RequestID = requestID
+	
+	h.writeJSONResponse(w, report, http.StatusOK)
+}
+
+// RestoreTemplates handles POST /api/v1/adapters/{name}/templates/restore
+func (h *Handlers) RestoreTemplates(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	name := mux.Vars(r)["name"]
+	requestID := h.generateRequestID()
+	
+	templates, ok := h.adapterRegistry.(TemplateManager)
+	if !ok {
+		h.writeErrorResponseLegacy(w, "Fingerprint template backup is not enabled for this site", http.StatusNotImplemented, "TEMPLATES_UNAVAILABLE", requestID)
+		return
+	}
+	
+	if _, err := h.adapterRegistry.GetAdapter(name); err != nil {
+		h.writeErrorResponseLegacy(w, fmt.Sprintf("Adapter '%s' not found", name), http.StatusNotFound, "ADAPTER_NOT_FOUND", requestID)
+		return
+	}
+	
+	h.logger.WithFields(logrus.Fields{
+		"requestId":   requestID,
+		"adapterName": name,
+		"clientIP":    getClientIP(r),
+	}).Info("Fingerprint template restore requested")
+	
+	report, err := templates.RestoreTemplates(ctx, name)
+	if err != nil {
+		h.logger.WithError(err).WithField("requestId", requestID).Error("Failed to restore fingerprint templates")
+		h.writeErrorResponseLegacy(w, fmt.Sprintf("Failed to restore fingerprint templates: %v", err), http.StatusInternalServerError, "TEMPLATE_RESTORE_FAILED", requestID)
+		return
+	}
+	report.RequestID = requestID
+	
+	h.writeJSONResponse(w, report, http.StatusOK)
+}
+
 // DeviceStatus handles GET /api/v1/status
 func (h *Handlers) DeviceStatus(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -857,6 +1645,7 @@ func (h *Handlers) GetEvents(w http.ResponseWriter, r *http.Request) {
 	// Parse other parameters
 	req.EventType = r.URL.Query().Get("eventType")
 	req.UserID = r.URL.Query().Get("userId")
+	req.DoorID = r.URL.Query().Get("doorId")
 	req.SortBy = r.URL.Query().Get("sortBy")
 	req.SortOrder = r.URL.Query().Get("sortOrder")
 	
@@ -902,6 +1691,7 @@ func (h *Handlers) GetEvents(w http.ResponseWriter, r *http.Request) {
 		EndTime:     req.EndTime,
 		EventType:   req.EventType,
 		UserID:      req.UserID,
+		DoorID:      req.DoorID,
 		IsSimulated: req.IsSimulated,
 		SentStatus:  "all", // Default to all events
 		Limit:       req.Limit,
@@ -955,6 +1745,7 @@ func (h *Handlers) GetEvents(w http.ResponseWriter, r *http.Request) {
 			EventType:      event.Event.EventType,
 			IsSimulated:    event.Event.IsSimulated,
 			DeviceID:       event.Event.DeviceID,
+			DoorID:         event.Event.DoorID,
 			RawData:        event.Event.RawData,
 			CreatedAt:      event.CreatedAt,
 			SentAt:         event.SentAt,
@@ -1320,16 +2111,14 @@ func (h *Handlers) EnableAdapter(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	
-	// Check if adapter registry is available to verify adapter exists
-	if h.adapterRegistry != nil {
-		if _, err := h.adapterRegistry.GetAdapter(name); err != nil {
-			h.logger.WithError(err).WithFields(logrus.Fields{
-				"requestId":   requestID,
-				"adapterName": name,
-			}).Error("Adapter not found")
-			h.writeErrorResponseLegacy(w, fmt.Sprintf("Adapter '%s' not found", name), http.StatusNotFound, "ADAPTER_NOT_FOUND", requestID)
-			return
-		}
+	// Disabled adapters are not loaded, so check the adapter type exists
+	if !isRegisteredAdapterType(h.configManager.GetCurrentConfig().AdapterType(name)) {
+		h.logger.WithFields(logrus.Fields{
+			"requestId":   requestID,
+			"adapterName": name,
+		}).Error("Adapter not found")
+		h.writeErrorResponseLegacy(w, fmt.Sprintf("Adapter '%s' not found", name), http.StatusNotFound, "ADAPTER_NOT_FOUND", requestID)
+		return
 	}
 	
 	// Get current configuration
@@ -1499,6 +2288,16 @@ func (h *Handlers) DisableAdapter(w http.ResponseWriter, r *http.Request) {
 	h.writeJSONResponse(w, response, http.StatusOK)
 }
 
+// isRegisteredAdapterType reports whether the bridge supports an adapter type
+func isRegisteredAdapterType(name string) bool {
+	for _, adapterType := range adapters.GetRegisteredAdapterTypes() {
+		if adapterType == name {
+			return true
+		}
+	}
+	return false
+}
+
 // UpdateAdapterConfig handles PUT /api/v1/adapters/{name}/config
 func (h *Handlers) UpdateAdapterConfig(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
@@ -1533,16 +2332,14 @@ func (h *Handlers) UpdateAdapterConfig(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	
-	// Check if adapter registry is available to verify adapter exists
-	if h.adapterRegistry != nil {
-		if _, err := h.adapterRegistry.GetAdapter(name); err != nil {
-			h.logger.WithError(err).WithFields(logrus.Fields{
-				"requestId":   requestID,
-				"adapterName": name,
-			}).Error("Adapter not found")
-			h.writeErrorResponseLegacy(w, fmt.Sprintf("Adapter '%s' not found", name), http.StatusNotFound, "ADAPTER_NOT_FOUND", requestID)
-			return
-		}
+	// Disabled adapters are not loaded, so check the adapter type exists
+	if !isRegisteredAdapterType(config.AdapterType(name, req.Config)) {
+		h.logger.WithFields(logrus.Fields{
+			"requestId":   requestID,
+			"adapterName": name,
+		}).Error("Adapter not found")
+		h.writeErrorResponseLegacy(w, fmt.Sprintf("Adapter '%s' not found", name), http.StatusNotFound, "ADAPTER_NOT_FOUND", requestID)
+		return
 	}
 	
 	// Get current configuration
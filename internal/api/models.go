@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"net/http"
 	"time"
+
+	"gym-door-bridge/internal/access"
 )
 
 // DoorUnlockRequest represents a request to unlock the door
@@ -38,6 +40,7 @@ type DoorUnlockResponse struct {
 	Message   string    `json:"message"`
 	Duration  int       `json:"duration"`
 	Adapter   string    `json:"adapter"`
+	DoorID    string    `json:"doorId,omitempty"`
 	Timestamp time.Time `json:"timestamp"`
 	RequestID string    `json:"requestId,omitempty"`
 }
@@ -47,14 +50,19 @@ type DoorLockResponse struct {
 	Success   bool      `json:"success"`
 	Message   string    `json:"message"`
 	Adapter   string    `json:"adapter"`
+	DoorID    string    `json:"doorId,omitempty"`
 	Timestamp time.Time `json:"timestamp"`
 	RequestID string    `json:"requestId,omitempty"`
 }
 
 // DoorStatusResponse represents the current door status
 type DoorStatusResponse struct {
+	DoorID          string    `json:"doorId,omitempty"`
+	DoorName        string    `json:"doorName,omitempty"`
+	Zone            string    `json:"zone,omitempty"`
 	IsLocked        bool      `json:"isLocked"`
 	Status          string    `json:"status"` // "locked", "unlocked", "unknown"
+	Position        string    `json:"position,omitempty"` // "closed", "open", "held_open", "forced_open", "unknown"
 	LastUnlockTime  *time.Time `json:"lastUnlockTime,omitempty"`
 	LastLockTime    *time.Time `json:"lastLockTime,omitempty"`
 	UnlockCount     int64     `json:"unlockCount"`
@@ -62,6 +70,231 @@ type DoorStatusResponse struct {
 	Timestamp       time.Time `json:"timestamp"`
 }
 
+// DoorInfo describes a configured door and its current state
+type DoorInfo struct {
+	ID             string                 `json:"id"`
+	Name           string                 `json:"name"`
+	Zone           string                 `json:"zone,omitempty"`
+	Adapters       []string               `json:"adapters"`
+	IsLocked       bool                   `json:"isLocked"`
+	Position       string                 `json:"position"` // Door position from the contact sensor
+	FreeAccess     bool                   `json:"freeAccess"` // Held unlocked by a schedule
+	UnlockCount    int64                  `json:"unlockCount"`
+	FailureCount   int64                  `json:"failureCount"`
+	LastUnlockTime *time.Time             `json:"lastUnlockTime,omitempty"`
+	Relay          map[string]interface{} `json:"relay,omitempty"`
+}
+
+// DoorListResponse represents the list of configured doors
+type DoorListResponse struct {
+	Doors     []DoorInfo `json:"doors"`
+	Total     int        `json:"total"`
+	Timestamp time.Time  `json:"timestamp"`
+	RequestID string     `json:"requestId,omitempty"`
+}
+
+// DoorSchedule is a weekly free-access schedule; its doors are held unlocked
+// during its windows, in the site's timezone
+type DoorSchedule struct {
+	ID        string                `json:"id"`
+	Name      string                `json:"name"`
+	DoorIDs   []string              `json:"doorIds"` // Empty applies to every door
+	Windows   []access.AccessWindow `json:"windows"`
+	Enabled   bool                  `json:"enabled"`
+	CreatedAt time.Time             `json:"createdAt"`
+	UpdatedAt time.Time             `json:"updatedAt"`
+}
+
+// DoorScheduleRequest represents a request to create or update a door schedule
+type DoorScheduleRequest struct {
+	Name    string                `json:"name"`
+	DoorIDs []string              `json:"doorIds,omitempty"`
+	Windows []access.AccessWindow `json:"windows"`
+	Enabled *bool                 `json:"enabled,omitempty"` // Defaults to true
+}
+
+// Validate validates the door schedule request
+func (r *DoorScheduleRequest) Validate() error {
+	if len(r.Windows) == 0 {
+		return fmt.Errorf("windows must contain at least one window")
+	}
+	for i, window := range r.Windows {
+		if err := window.Validate(); err != nil {
+			return fmt.Errorf("windows[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// DoorScheduleListResponse represents the list of door schedules
+type DoorScheduleListResponse struct {
+	Schedules []DoorSchedule `json:"schedules"`
+	Total     int            `json:"total"`
+	Timestamp time.Time      `json:"timestamp"`
+	RequestID string         `json:"requestId,omitempty"`
+}
+
+// DoorScheduleDeleteResponse represents the response to deleting a schedule or holiday
+type DoorScheduleDeleteResponse struct {
+	Success   bool      `json:"success"`
+	Message   string    `json:"message"`
+	ID        string    `json:"id"`
+	Timestamp time.Time `json:"timestamp"`
+	RequestID string    `json:"requestId,omitempty"`
+}
+
+// DoorHoliday overrides the weekly schedules on one date. Without hours the
+// doors stay locked all day.
+type DoorHoliday struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Date      string    `json:"date"`            // YYYY-MM-DD in the site's timezone
+	Start     string    `json:"start,omitempty"` // HH:MM
+	End       string    `json:"end,omitempty"`   // HH:MM
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// DoorHolidayRequest represents a request to create or update a holiday
+type DoorHolidayRequest struct {
+	Name  string `json:"name"`
+	Date  string `json:"date"`
+	Start string `json:"start,omitempty"`
+	End   string `json:"end,omitempty"`
+}
+
+// Validate validates the holiday request
+func (r *DoorHolidayRequest) Validate() error {
+	if _, err := time.Parse("2006-01-02", r.Date); err != nil {
+		return fmt.Errorf("date must be in YYYY-MM-DD format")
+	}
+	if r.Start == "" && r.End == "" {
+		return nil
+	}
+
+	start, err := time.Parse("15:04", r.Start)
+	if err != nil {
+		return fmt.Errorf("start must be in HH:MM format")
+	}
+	end, err := time.Parse("15:04", r.End)
+	if err != nil {
+		return fmt.Errorf("end must be in HH:MM format")
+	}
+	if !start.Before(end) {
+		return fmt.Errorf("start must be before end")
+	}
+	return nil
+}
+
+// DoorHolidayListResponse represents the list of holidays
+type DoorHolidayListResponse struct {
+	Holidays  []DoorHoliday `json:"holidays"`
+	Total     int           `json:"total"`
+	Timestamp time.Time     `json:"timestamp"`
+	RequestID string        `json:"requestId,omitempty"`
+}
+
+// DoorLockdown describes an active lockdown
+type DoorLockdown struct {
+	Reason      string    `json:"reason"`
+	ActivatedBy string    `json:"activatedBy,omitempty"`
+	ActivatedAt time.Time `json:"activatedAt"`
+}
+
+// DoorLockdownRequest represents a request to activate a lockdown
+type DoorLockdownRequest struct {
+	Reason      string `json:"reason"`
+	RequestedBy string `json:"requestedBy,omitempty"`
+}
+
+// Validate validates the lockdown request
+func (r *DoorLockdownRequest) Validate() error {
+	if r.Reason == "" {
+		return fmt.Errorf("reason is required")
+	}
+	return nil
+}
+
+// DoorLockdownResponse reports the lockdown state
+type DoorLockdownResponse struct {
+	Active    bool          `json:"active"`
+	Lockdown  *DoorLockdown `json:"lockdown,omitempty"`
+	Timestamp time.Time     `json:"timestamp"`
+	RequestID string        `json:"requestId,omitempty"`
+}
+
+// AntiPassbackState represents a member's in/out state in an anti-passback zone
+type AntiPassbackState struct {
+	Zone      string    `json:"zone"`
+	State     string    `json:"state"` // in, out or unknown
+	DoorID    string    `json:"doorId,omitempty"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// AntiPassbackStateResponse represents the response for a member's anti-passback state
+type AntiPassbackStateResponse struct {
+	MemberID  string              `json:"memberId"`
+	States    []AntiPassbackState `json:"states"`
+	Timestamp time.Time           `json:"timestamp"`
+	RequestID string              `json:"requestId,omitempty"`
+}
+
+// AntiPassbackResetResponse represents the response for an anti-passback reset
+type AntiPassbackResetResponse struct {
+	Success    bool      `json:"success"`
+	Message    string    `json:"message"`
+	MemberID   string    `json:"memberId,omitempty"`
+	ResetCount int64     `json:"resetCount"`
+	Timestamp  time.Time `json:"timestamp"`
+	RequestID  string    `json:"requestId,omitempty"`
+}
+
+// ProvisioningRequest represents a request to reconcile terminal users with the roster
+type ProvisioningRequest struct {
+	DryRun bool `json:"dryRun"` // Report the planned changes without applying them
+}
+
+// ProvisioningAction represents a change to a terminal user
+type ProvisioningAction struct {
+	Terminal       string `json:"terminal"`
+	Type           string `json:"type"` // create, update, disable, delete
+	DeviceUserID   int    `json:"deviceUserId"`
+	InternalUserID string `json:"internalUserId,omitempty"`
+	Name           string `json:"name,omitempty"`
+	Reason         string `json:"reason"`
+	Error          string `json:"error,omitempty"`
+}
+
+// ProvisioningReport represents the outcome of a terminal user reconciliation
+type ProvisioningReport struct {
+	DryRun      bool                 `json:"dryRun"`
+	StartedAt   time.Time            `json:"startedAt"`
+	CompletedAt time.Time            `json:"completedAt"`
+	Members     int                  `json:"members"`
+	Terminals   int                  `json:"terminals"`
+	Actions     []ProvisioningAction `json:"actions"`
+	Applied     int                  `json:"applied"`
+	Failed      int                  `json:"failed"`
+	Unmanaged   int                  `json:"unmanaged"` // Terminal users not provisioned by the bridge
+	Errors      []string             `json:"errors,omitempty"`
+	RequestID   string               `json:"requestId,omitempty"`
+}
+
+// TemplateSyncReport represents the outcome of a fingerprint template sync or
+// restore. Template data is never included.
+type TemplateSyncReport struct {
+	StartedAt   time.Time `json:"startedAt"`
+	CompletedAt time.Time `json:"completedAt"`
+	Terminals   int       `json:"terminals"`
+	Stored      int       `json:"stored"`   // Templates held by the bridge
+	BackedUp    int       `json:"backedUp"` // Templates stored or updated from the terminals
+	Written     int       `json:"written"`  // Templates written to terminals missing them
+	Skipped     int       `json:"skipped"`  // Templates for users not on a terminal
+	Failed      int       `json:"failed"`
+	Errors      []string  `json:"errors,omitempty"`
+	RequestID   string    `json:"requestId,omitempty"`
+}
+
 // ErrorResponse represents a standardized error response
 type ErrorResponse struct {
 	Error     string            `json:"error"`
@@ -603,6 +836,7 @@ type EventQueryRequest struct {
 	EndTime      *time.Time `json:"endTime,omitempty"`
 	EventType    string     `json:"eventType,omitempty"`
 	UserID       string     `json:"userId,omitempty"`
+	DoorID       string     `json:"doorId,omitempty"`
 	IsSimulated  *bool      `json:"isSimulated,omitempty"`
 	Limit        int        `json:"limit,omitempty" validate:"max=1000"`
 	Offset       int        `json:"offset,omitempty"`
@@ -631,9 +865,10 @@ func (r *EventQueryRequest) Validate() error {
 	if r.EventType != "" {
 		validEventTypes := map[string]bool{
 			"entry": true, "exit": true, "denied": true,
+			"door_forced_open": true, "door_held_open": true,
 		}
 		if !validEventTypes[r.EventType] {
-			return fmt.Errorf("eventType must be one of: entry, exit, denied")
+			return fmt.Errorf("eventType must be one of: entry, exit, denied, door_forced_open, door_held_open")
 		}
 	}
 	
@@ -677,6 +912,7 @@ type EventResponse struct {
 	EventType      string                 `json:"eventType"`
 	IsSimulated    bool                   `json:"isSimulated"`
 	DeviceID       string                 `json:"deviceId"`
+	DoorID         string                 `json:"doorId,omitempty"`
 	RawData        map[string]interface{} `json:"rawData,omitempty"`
 	CreatedAt      time.Time              `json:"createdAt"`
 	SentAt         *time.Time             `json:"sentAt,omitempty"`
@@ -733,9 +969,10 @@ func (r *EventClearRequest) Validate() error {
 	if r.EventType != "" {
 		validEventTypes := map[string]bool{
 			"entry": true, "exit": true, "denied": true,
+			"door_forced_open": true, "door_held_open": true,
 		}
 		if !validEventTypes[r.EventType] {
-			return fmt.Errorf("eventType must be one of: entry, exit, denied")
+			return fmt.Errorf("eventType must be one of: entry, exit, denied, door_forced_open, door_held_open")
 		}
 	}
 	
@@ -918,6 +1155,7 @@ func (r *WebSocketEventRequest) Validate() error {
 		"door_unlock":     true,
 		"door_lock":       true,
 		"door_status":     true,
+		"door_alarm":      true,
 		"device_status":   true,
 		"config_change":   true,
 		"adapter_status":  true,
@@ -0,0 +1,74 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gym-door-bridge/internal/config"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeProvisioningRegistry records reconciliation requests
+type fakeProvisioningRegistry struct {
+	MockAdapterRegistry
+	dryRuns []bool
+}
+
+func (f *fakeProvisioningRegistry) ReconcileUsers(ctx context.Context, dryRun bool) (*ProvisioningReport, error) {
+	f.dryRuns = append(f.dryRuns, dryRun)
+	return &ProvisioningReport{
+		DryRun:    dryRun,
+		Terminals: 1,
+		Actions:   []ProvisioningAction{{Terminal: "front", Type: "create", DeviceUserID: 43, InternalUserID: "user_2", Reason: "missing on terminal"}},
+	}, nil
+}
+
+func TestHandlers_ReconcileUsers(t *testing.T) {
+	cfg := config.DefaultConfig()
+	logger := logrus.New()
+	logger.SetLevel(logrus.ErrorLevel)
+
+	registry := &fakeProvisioningRegistry{}
+	handlers := NewHandlers(cfg, logger, registry, &MockDoorController{}, nil, nil, nil, nil, "test-version", "test-device-id")
+
+	req := httptest.NewRequest("POST", "/api/v1/provisioning/reconcile", bytes.NewBufferString(`{"dryRun":true}`))
+	w := httptest.NewRecorder()
+	handlers.ReconcileUsers(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var report ProvisioningReport
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+	assert.True(t, report.DryRun)
+	assert.NotEmpty(t, report.RequestID)
+	if assert.Len(t, report.Actions, 1) {
+		assert.Equal(t, "create", report.Actions[0].Type)
+	}
+
+	// Without a body the changes are applied
+	w = httptest.NewRecorder()
+	handlers.ReconcileUsers(w, httptest.NewRequest("POST", "/api/v1/provisioning/reconcile", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []bool{true, false}, registry.dryRuns)
+
+	w = httptest.NewRecorder()
+	handlers.ReconcileUsers(w, httptest.NewRequest("POST", "/api/v1/provisioning/reconcile", bytes.NewBufferString(`{`)))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestHandlers_ReconcileUsersUnavailable(t *testing.T) {
+	cfg := config.DefaultConfig()
+	logger := logrus.New()
+	logger.SetLevel(logrus.ErrorLevel)
+	handlers := NewHandlers(cfg, logger, &MockAdapterRegistry{}, &MockDoorController{}, nil, nil, nil, nil, "test-version", "test-device-id")
+
+	w := httptest.NewRecorder()
+	handlers.ReconcileUsers(w, httptest.NewRequest("POST", "/api/v1/provisioning/reconcile", nil))
+	assert.Equal(t, http.StatusNotImplemented, w.Code)
+	assert.Contains(t, w.Body.String(), "PROVISIONING_UNAVAILABLE")
+}
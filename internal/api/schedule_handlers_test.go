@@ -0,0 +1,247 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gym-door-bridge/internal/access"
+	"gym-door-bridge/internal/config"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// fakeScheduleController is a multi-door controller that keeps schedules,
+// holidays and the lockdown in memory
+type fakeScheduleController struct {
+	MockMultiDoorController
+	schedules map[string]DoorSchedule
+	holidays  map[string]DoorHoliday
+	lockdown  *DoorLockdown
+}
+
+func (f *fakeScheduleController) ListSchedules() ([]DoorSchedule, error) {
+	var list []DoorSchedule
+	for _, schedule := range f.schedules {
+		list = append(list, schedule)
+	}
+	return list, nil
+}
+
+func (f *fakeScheduleController) UpsertSchedule(ctx context.Context, schedule DoorSchedule) (*DoorSchedule, error) {
+	f.schedules[schedule.ID] = schedule
+	return &schedule, nil
+}
+
+func (f *fakeScheduleController) DeleteSchedule(ctx context.Context, id string) (bool, error) {
+	_, ok := f.schedules[id]
+	delete(f.schedules, id)
+	return ok, nil
+}
+
+func (f *fakeScheduleController) ListHolidays() ([]DoorHoliday, error) {
+	var list []DoorHoliday
+	for _, holiday := range f.holidays {
+		list = append(list, holiday)
+	}
+	return list, nil
+}
+
+func (f *fakeScheduleController) UpsertHoliday(ctx context.Context, holiday DoorHoliday) (*DoorHoliday, error) {
+	f.holidays[holiday.ID] = holiday
+	return &holiday, nil
+}
+
+func (f *fakeScheduleController) DeleteHoliday(ctx context.Context, id string) (bool, error) {
+	_, ok := f.holidays[id]
+	delete(f.holidays, id)
+	return ok, nil
+}
+
+func (f *fakeScheduleController) GetLockdown() *DoorLockdown {
+	return f.lockdown
+}
+
+func (f *fakeScheduleController) SetLockdown(ctx context.Context, reason, activatedBy string) (*DoorLockdown, error) {
+	f.lockdown = &DoorLockdown{Reason: reason, ActivatedBy: activatedBy, ActivatedAt: time.Now()}
+	return f.lockdown, nil
+}
+
+func (f *fakeScheduleController) ClearLockdown(ctx context.Context) error {
+	f.lockdown = nil
+	return nil
+}
+
+func setupScheduleTestHandlers() (*Handlers, *fakeScheduleController) {
+	cfg := config.DefaultConfig()
+	logger := logrus.New()
+	logger.SetLevel(logrus.ErrorLevel)
+
+	controller := &fakeScheduleController{
+		schedules: make(map[string]DoorSchedule),
+		holidays:  make(map[string]DoorHoliday),
+	}
+	handlers := NewHandlers(cfg, logger, &MockAdapterRegistry{}, controller, nil, nil, nil, nil, "test-version", "test-device-id")
+
+	return handlers, controller
+}
+
+func TestDoorScheduleRequest_Validate(t *testing.T) {
+	valid := DoorScheduleRequest{Windows: []access.AccessWindow{{Days: []int{1, 2, 3}, Start: "06:00", End: "22:00"}}}
+	assert.NoError(t, valid.Validate())
+
+	empty := DoorScheduleRequest{}
+	assert.Error(t, empty.Validate())
+
+	badDay := DoorScheduleRequest{Windows: []access.AccessWindow{{Days: []int{7}, Start: "06:00", End: "22:00"}}}
+	assert.Error(t, badDay.Validate())
+}
+
+func TestDoorHolidayRequest_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		request DoorHolidayRequest
+		wantErr bool
+	}{
+		{name: "closed all day", request: DoorHolidayRequest{Date: "2026-12-25"}},
+		{name: "reduced hours", request: DoorHolidayRequest{Date: "2027-01-01", Start: "10:00", End: "14:00"}},
+		{name: "invalid date", request: DoorHolidayRequest{Date: "25/12/2026"}, wantErr: true},
+		{name: "missing end", request: DoorHolidayRequest{Date: "2026-12-25", Start: "10:00"}, wantErr: true},
+		{name: "start after end", request: DoorHolidayRequest{Date: "2026-12-25", Start: "14:00", End: "10:00"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.request.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestHandlers_DoorSchedules(t *testing.T) {
+	handlers, controller := setupScheduleTestHandlers()
+	controller.On("GetDoor", "front").Return(&DoorInfo{ID: "front"})
+	controller.On("GetDoor", "side").Return(nil)
+
+	put := func(id, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("PUT", "/api/v1/schedules/"+id, bytes.NewBufferString(body))
+		req = mux.SetURLVars(req, map[string]string{"id": id})
+		w := httptest.NewRecorder()
+		handlers.UpsertDoorSchedule(w, req)
+		return w
+	}
+
+	w := put("staffed", `{"name":"Staffed hours","doorIds":["front"],"windows":[{"days":[1,2,3,4,5],"start":"06:00","end":"22:00"}]}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, controller.schedules["staffed"].Enabled, "schedules are enabled by default")
+
+	assert.Equal(t, http.StatusBadRequest, put("empty", `{"name":"No windows"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, put("side", `{"doorIds":["side"],"windows":[{"days":[1],"start":"06:00","end":"22:00"}]}`).Code)
+	assert.Equal(t, http.StatusBadRequest, put("bad", `{`).Code)
+
+	w = httptest.NewRecorder()
+	handlers.ListDoorSchedules(w, httptest.NewRequest("GET", "/api/v1/schedules", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	var list DoorScheduleListResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	if assert.Equal(t, 1, list.Total) {
+		assert.Equal(t, "staffed", list.Schedules[0].ID)
+		assert.Equal(t, "22:00", list.Schedules[0].Windows[0].End)
+	}
+
+	remove := func(id string) int {
+		req := mux.SetURLVars(httptest.NewRequest("DELETE", "/api/v1/schedules/"+id, nil), map[string]string{"id": id})
+		w := httptest.NewRecorder()
+		handlers.DeleteDoorSchedule(w, req)
+		return w.Code
+	}
+	assert.Equal(t, http.StatusOK, remove("staffed"))
+	assert.Equal(t, http.StatusNotFound, remove("staffed"))
+}
+
+func TestHandlers_DoorHolidays(t *testing.T) {
+	handlers, controller := setupScheduleTestHandlers()
+
+	put := func(id, body string) int {
+		req := httptest.NewRequest("PUT", "/api/v1/holidays/"+id, bytes.NewBufferString(body))
+		req = mux.SetURLVars(req, map[string]string{"id": id})
+		w := httptest.NewRecorder()
+		handlers.UpsertDoorHoliday(w, req)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusOK, put("christmas", `{"name":"Christmas","date":"2026-12-25"}`))
+	assert.Equal(t, http.StatusBadRequest, put("bad", `{"date":"2026-12-25","start":"14:00","end":"10:00"}`))
+	assert.Equal(t, "2026-12-25", controller.holidays["christmas"].Date)
+
+	w := httptest.NewRecorder()
+	handlers.ListDoorHolidays(w, httptest.NewRequest("GET", "/api/v1/holidays", nil))
+	var list DoorHolidayListResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	assert.Equal(t, 1, list.Total)
+
+	req := mux.SetURLVars(httptest.NewRequest("DELETE", "/api/v1/holidays/christmas", nil), map[string]string{"id": "christmas"})
+	w = httptest.NewRecorder()
+	handlers.DeleteDoorHoliday(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, controller.holidays)
+}
+
+func TestHandlers_DoorLockdown(t *testing.T) {
+	handlers, controller := setupScheduleTestHandlers()
+
+	w := httptest.NewRecorder()
+	handlers.ActivateDoorLockdown(w, httptest.NewRequest("POST", "/api/v1/lockdown", bytes.NewBufferString(`{}`)))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	handlers.ActivateDoorLockdown(w, httptest.NewRequest("POST", "/api/v1/lockdown", bytes.NewBufferString(`{"reason":"incident","requestedBy":"admin"}`)))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	handlers.GetDoorLockdown(w, httptest.NewRequest("GET", "/api/v1/lockdown", nil))
+	var response DoorLockdownResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.True(t, response.Active)
+	assert.Equal(t, "incident", response.Lockdown.Reason)
+
+	// Unlocks are refused during a lockdown without reaching the controller
+	w = httptest.NewRecorder()
+	handlers.UnlockDoor(w, httptest.NewRequest("POST", "/api/v1/door/unlock", bytes.NewBufferString(`{"durationMs": 3000}`)))
+	assert.Equal(t, http.StatusLocked, w.Code)
+
+	controller.On("GetDoor", "front").Return(&DoorInfo{ID: "front"})
+	req := mux.SetURLVars(httptest.NewRequest("POST", "/api/v1/doors/front/unlock", nil), map[string]string{"id": "front"})
+	w = httptest.NewRecorder()
+	handlers.UnlockDoorByID(w, req)
+	assert.Equal(t, http.StatusLocked, w.Code)
+	controller.AssertNotCalled(t, "UnlockDoorByID", mock.Anything, mock.Anything, mock.Anything)
+
+	w = httptest.NewRecorder()
+	handlers.ClearDoorLockdown(w, httptest.NewRequest("DELETE", "/api/v1/lockdown", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Nil(t, controller.lockdown)
+}
+
+func TestHandlers_DoorSchedulesUnavailable(t *testing.T) {
+	handlers, _, _ := setupTestHandlers()
+
+	w := httptest.NewRecorder()
+	handlers.ListDoorSchedules(w, httptest.NewRequest("GET", "/api/v1/schedules", nil))
+	assert.Equal(t, http.StatusNotImplemented, w.Code)
+
+	w = httptest.NewRecorder()
+	handlers.GetDoorLockdown(w, httptest.NewRequest("GET", "/api/v1/lockdown", nil))
+	assert.Equal(t, http.StatusNotImplemented, w.Code)
+}
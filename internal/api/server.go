@@ -163,6 +163,11 @@ func (s *Server) Shutdown() error {
 	return nil
 }
 
+// BroadcastEvent pushes an event to all WebSocket clients
+func (s *Server) BroadcastEvent(eventType string, data interface{}) {
+	s.handlers.BroadcastEvent(eventType, data)
+}
+
 // setupMiddleware configures middleware for the router
 func (s *Server) setupMiddleware() {
 	// Enhanced request logging middleware (replaces basic logging)
@@ -206,6 +211,33 @@ func (s *Server) setupRoutes() {
 	protected.HandleFunc("/door/unlock", s.handlers.UnlockDoor).Methods("POST")
 	protected.HandleFunc("/door/lock", s.handlers.LockDoor).Methods("POST")
 	protected.HandleFunc("/door/status", s.handlers.DoorStatus).Methods("GET")
+	protected.HandleFunc("/doors", s.handlers.ListDoors).Methods("GET")
+	protected.HandleFunc("/doors/{id}/status", s.handlers.DoorStatusByID).Methods("GET")
+	protected.HandleFunc("/doors/{id}/unlock", s.handlers.UnlockDoorByID).Methods("POST")
+	protected.HandleFunc("/doors/{id}/lock", s.handlers.LockDoorByID).Methods("POST")
+	
+	// Door schedules, holidays and lockdown
+	protected.HandleFunc("/schedules", s.handlers.ListDoorSchedules).Methods("GET")
+	protected.HandleFunc("/schedules/{id}", s.handlers.UpsertDoorSchedule).Methods("PUT")
+	protected.HandleFunc("/schedules/{id}", s.handlers.DeleteDoorSchedule).Methods("DELETE")
+	protected.HandleFunc("/holidays", s.handlers.ListDoorHolidays).Methods("GET")
+	protected.HandleFunc("/holidays/{id}", s.handlers.UpsertDoorHoliday).Methods("PUT")
+	protected.HandleFunc("/holidays/{id}", s.handlers.DeleteDoorHoliday).Methods("DELETE")
+	protected.HandleFunc("/lockdown", s.handlers.GetDoorLockdown).Methods("GET")
+	protected.HandleFunc("/lockdown", s.handlers.ActivateDoorLockdown).Methods("POST")
+	protected.HandleFunc("/lockdown", s.handlers.ClearDoorLockdown).Methods("DELETE")
+	
+	// Anti-passback state
+	protected.HandleFunc("/members/{id}/anti-passback", s.handlers.GetMemberAntiPassback).Methods("GET")
+	protected.HandleFunc("/members/{id}/anti-passback", s.handlers.ResetMemberAntiPassback).Methods("DELETE")
+	protected.HandleFunc("/anti-passback", s.handlers.ResetAllAntiPassback).Methods("DELETE")
+	
+	// Terminal user provisioning
+	protected.HandleFunc("/provisioning/reconcile", s.handlers.ReconcileUsers).Methods("POST")
+	
+	// Fingerprint template backup and transfer
+	protected.HandleFunc("/templates/sync", s.handlers.SyncTemplates).Methods("POST")
+	protected.HandleFunc("/adapters/{name}/templates/restore", s.handlers.RestoreTemplates).Methods("POST")
 	
 	// Device status endpoints
 	protected.HandleFunc("/status", s.handlers.DeviceStatus).Methods("GET")
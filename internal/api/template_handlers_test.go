@@ -0,0 +1,77 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gym-door-bridge/internal/config"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeTemplateRegistry records template sync requests
+type fakeTemplateRegistry struct {
+	MockAdapterRegistry
+	restored []string
+}
+
+func (f *fakeTemplateRegistry) SyncTemplates(ctx context.Context) (*TemplateSyncReport, error) {
+	return &TemplateSyncReport{Terminals: 2, Stored: 3, BackedUp: 3, Written: 2}, nil
+}
+
+func (f *fakeTemplateRegistry) RestoreTemplates(ctx context.Context, adapterName string) (*TemplateSyncReport, error) {
+	f.restored = append(f.restored, adapterName)
+	return &TemplateSyncReport{Terminals: 2, Stored: 3, Written: 3}, nil
+}
+
+func TestHandlers_Templates(t *testing.T) {
+	cfg := config.DefaultConfig()
+	logger := logrus.New()
+	logger.SetLevel(logrus.ErrorLevel)
+
+	registry := &fakeTemplateRegistry{}
+	registry.On("GetAdapter", "front").Return(&MockHardwareAdapter{}, nil)
+	registry.On("GetAdapter", "missing").Return((*MockHardwareAdapter)(nil), errors.New("adapter not found"))
+	handlers := NewHandlers(cfg, logger, registry, &MockDoorController{}, nil, nil, nil, nil, "test-version", "test-device-id")
+
+	w := httptest.NewRecorder()
+	handlers.SyncTemplates(w, httptest.NewRequest("POST", "/api/v1/templates/sync", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var report TemplateSyncReport
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+	assert.Equal(t, 2, report.Written)
+	assert.NotEmpty(t, report.RequestID)
+
+	restore := func(name string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/api/v1/adapters/"+name+"/templates/restore", nil)
+		req = mux.SetURLVars(req, map[string]string{"name": name})
+		w := httptest.NewRecorder()
+		handlers.RestoreTemplates(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusOK, restore("front").Code)
+	w = restore("missing")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), "ADAPTER_NOT_FOUND")
+	assert.Equal(t, []string{"front"}, registry.restored)
+}
+
+func TestHandlers_TemplatesWithoutConsent(t *testing.T) {
+	cfg := config.DefaultConfig()
+	logger := logrus.New()
+	logger.SetLevel(logrus.ErrorLevel)
+	handlers := NewHandlers(cfg, logger, &MockAdapterRegistry{}, &MockDoorController{}, nil, nil, nil, nil, "test-version", "test-device-id")
+
+	w := httptest.NewRecorder()
+	handlers.SyncTemplates(w, httptest.NewRequest("POST", "/api/v1/templates/sync", nil))
+	assert.Equal(t, http.StatusNotImplemented, w.Code)
+	assert.Contains(t, w.Body.String(), "TEMPLATES_UNAVAILABLE")
+}
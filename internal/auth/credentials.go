@@ -1,5 +1,7 @@
 package auth
 
+import "fmt"
+
 // CredentialManager handles secure storage and retrieval of device credentials
 type CredentialManager interface {
 	StoreCredentials(deviceID, deviceKey string) error
@@ -13,6 +15,27 @@ func NewCredentialManager() (CredentialManager, error) {
 	return newPlatformCredentialManager()
 }
 
+// SecretStore handles secure storage of named secrets, such as the database
+// encryption keys
+type SecretStore interface {
+	StoreSecret(name string, secret []byte) error
+	GetSecret(name string) ([]byte, error) // Returns nil, nil when the secret doesn't exist
+}
+
+// NewSecretStore creates a secret store backed by the platform credential manager
+func NewSecretStore() (SecretStore, error) {
+	manager, err := newPlatformCredentialManager()
+	if err != nil {
+		return nil, err
+	}
+
+	store, ok := manager.(SecretStore)
+	if !ok {
+		return nil, fmt.Errorf("secret storage not supported on this platform")
+	}
+	return store, nil
+}
+
 // DeviceCredentials represents stored device credentials
 type DeviceCredentials struct {
 	DeviceID  string `json:"deviceId"`
@@ -3,7 +3,9 @@
 package auth
 
 import (
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os/exec"
 	"strings"
@@ -99,8 +101,52 @@ func (m *MacOSCredentialManager) HasCredentials() bool {
 
 	err := cmd.Run()
 	return err == nil
-}/
-/ newPlatformCredentialManager creates a macOS credential manager
+}
+
+// StoreSecret stores a named secret, such as a data-encryption key, in macOS
+// Keychain under its own account
+func (m *MacOSCredentialManager) StoreSecret(name string, secret []byte) error {
+	cmd := exec.Command("security", "add-generic-password",
+		"-s", m.serviceName,
+		"-a", name,
+		"-w", base64.StdEncoding.EncodeToString(secret),
+		"-U", // Update if exists
+	)
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to store secret %s in keychain: %w", name, err)
+	}
+
+	return nil
+}
+
+// GetSecret retrieves a named secret from macOS Keychain, returning nil when it
+// doesn't exist
+func (m *MacOSCredentialManager) GetSecret(name string) ([]byte, error) {
+	cmd := exec.Command("security", "find-generic-password",
+		"-s", m.serviceName,
+		"-a", name,
+		"-w", // Output password only
+	)
+
+	output, err := cmd.Output()
+	if err != nil {
+		// security exits with 44 when the item isn't in the keychain
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 44 {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to retrieve secret %s from keychain: %w", name, err)
+	}
+
+	secret, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(output)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode secret %s: %w", name, err)
+	}
+	return secret, nil
+}
+
+// newPlatformCredentialManager creates a macOS credential manager
 func newPlatformCredentialManager() (CredentialManager, error) {
 	return NewMacOSCredentialManager()
 }
\ No newline at end of file
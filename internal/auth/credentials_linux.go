@@ -0,0 +1,408 @@
+//go:build linux
+
+package auth
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	// linuxCredentialDir matches the data directory of the systemd service
+	linuxCredentialDir = "/var/lib/gym-door-bridge"
+
+	credentialFileVersion = 1
+	credentialSecretSize  = 32
+)
+
+// credentialFileAAD binds the encrypted file to its purpose
+var credentialFileAAD = []byte("gym-door-bridge device credentials")
+
+// LinuxCredentialManager stores device credentials in the Secret Service when a
+// session bus and secret-tool are available, and otherwise in an AES-GCM
+// encrypted file readable only by the bridge. The file key is derived from
+// /etc/machine-id and a random local secret, so a copied data directory can't
+// be decrypted on another machine.
+//
+// The kernel keyring is not used: its keys are discarded on reboot, which
+// would unpair the bridge.
+type LinuxCredentialManager struct {
+	serviceName    string
+	accountName    string
+	secretTool     string // Path of secret-tool, empty when the Secret Service is unavailable
+	dir            string
+	credPath       string
+	secretPath     string
+	machineIDPaths []string
+}
+
+// NewLinuxCredentialManager creates a new Linux credential manager
+func NewLinuxCredentialManager() (*LinuxCredentialManager, error) {
+	dir := getCredentialDir()
+
+	// Ensure directory exists
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create credentials directory: %w", err)
+	}
+
+	return newLinuxCredentialManager(dir, lookupSecretTool()), nil
+}
+
+// newLinuxCredentialManager creates a credential manager storing its files in dir
+func newLinuxCredentialManager(dir, secretTool string) *LinuxCredentialManager {
+	return &LinuxCredentialManager{
+		serviceName:    "GymDoorBridge",
+		accountName:    "device-credentials",
+		secretTool:     secretTool,
+		dir:            dir,
+		credPath:       filepath.Join(dir, "credentials.enc"),
+		secretPath:     filepath.Join(dir, "credentials.secret"),
+		machineIDPaths: []string{"/etc/machine-id", "/var/lib/dbus/machine-id"},
+	}
+}
+
+// getCredentialDir determines the directory for the encrypted credential file.
+// The service data directory is used when writable, otherwise the user's data
+// directory so the bridge can run unprivileged during development.
+func getCredentialDir() string {
+	if err := os.MkdirAll(linuxCredentialDir, 0700); err == nil && unix.Access(linuxCredentialDir, unix.W_OK) == nil {
+		return linuxCredentialDir
+	}
+
+	if dataHome := os.Getenv("XDG_DATA_HOME"); dataHome != "" {
+		return filepath.Join(dataHome, "gym-door-bridge")
+	}
+
+	if home, err := os.UserHomeDir(); err == nil {
+		return filepath.Join(home, ".local", "share", "gym-door-bridge")
+	}
+
+	// Final fallback to current directory
+	return "."
+}
+
+// lookupSecretTool returns the path of secret-tool when a Secret Service can be
+// reached over the session bus
+func lookupSecretTool() string {
+	if os.Getenv("DBUS_SESSION_BUS_ADDRESS") == "" {
+		return ""
+	}
+
+	path, err := exec.LookPath("secret-tool")
+	if err != nil {
+		return ""
+	}
+	return path
+}
+
+// StoreCredentials stores device credentials in the Secret Service, or in the
+// encrypted file when the Secret Service is unavailable or refuses the write
+func (l *LinuxCredentialManager) StoreCredentials(deviceID, deviceKey string) error {
+	creds := DeviceCredentials{
+		DeviceID:  deviceID,
+		DeviceKey: deviceKey,
+	}
+
+	// Marshal credentials to JSON
+	data, err := json.Marshal(creds)
+	if err != nil {
+		return fmt.Errorf("failed to marshal credentials: %w", err)
+	}
+
+	return l.store(l.accountName, l.credPath, data, credentialFileAAD)
+}
+
+// GetCredentials retrieves device credentials from the Secret Service or the
+// encrypted file
+func (l *LinuxCredentialManager) GetCredentials() (string, string, error) {
+	data, err := l.load(l.accountName, l.credPath, credentialFileAAD)
+	if err != nil {
+		return "", "", err
+	}
+
+	// Parse JSON credentials
+	var creds DeviceCredentials
+	if err := json.Unmarshal(data, &creds); err != nil {
+		return "", "", fmt.Errorf("failed to unmarshal credentials: %w", err)
+	}
+
+	return creds.DeviceID, creds.DeviceKey, nil
+}
+
+// DeleteCredentials removes stored credentials from the Secret Service and the
+// encrypted file. The local secret is kept for the next pairing.
+func (l *LinuxCredentialManager) DeleteCredentials() error {
+	if l.secretTool != "" {
+		// Ignore error if item doesn't exist
+		exec.Command(l.secretTool, "clear", "service", l.serviceName, "account", l.accountName).Run()
+	}
+
+	if err := os.Remove(l.credPath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove credential file: %w", err)
+	}
+
+	return nil
+}
+
+// HasCredentials checks if credentials are stored
+func (l *LinuxCredentialManager) HasCredentials() bool {
+	_, _, err := l.GetCredentials()
+	return err == nil
+}
+
+// StoreSecret stores a named secret, such as a data-encryption key, the same
+// way as the device credentials
+func (l *LinuxCredentialManager) StoreSecret(name string, secret []byte) error {
+	// secret-tool handles text, so binary secrets are base64 encoded
+	data := []byte(base64.StdEncoding.EncodeToString(secret))
+	return l.store(name, l.secretFilePath(name), data, secretFileAAD(name))
+}
+
+// GetSecret retrieves a named secret, returning nil when it doesn't exist
+func (l *LinuxCredentialManager) GetSecret(name string) ([]byte, error) {
+	data, err := l.load(name, l.secretFilePath(name), secretFileAAD(name))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := base64.StdEncoding.DecodeString(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode secret %s: %w", name, err)
+	}
+	return secret, nil
+}
+
+// secretFilePath returns the encrypted file holding a named secret
+func (l *LinuxCredentialManager) secretFilePath(name string) string {
+	return filepath.Join(l.dir, name+".enc")
+}
+
+// secretFileAAD binds an encrypted secret file to the secret's name
+func secretFileAAD(name string) []byte {
+	return []byte("gym-door-bridge secret " + name)
+}
+
+// store saves data under account in the Secret Service, or in the encrypted
+// file at path when the Secret Service is unavailable or refuses the write
+func (l *LinuxCredentialManager) store(account, path string, data, aad []byte) error {
+	if l.secretTool != "" {
+		if err := l.storeSecretService(account, data); err == nil {
+			// Don't leave an older copy behind in the file
+			if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("failed to remove credential file: %w", err)
+			}
+			return nil
+		}
+	}
+
+	return l.storeFile(path, data, aad)
+}
+
+// load reads the data stored under account, preferring the Secret Service
+func (l *LinuxCredentialManager) load(account, path string, aad []byte) ([]byte, error) {
+	if l.secretTool != "" {
+		output, err := exec.Command(l.secretTool, "lookup", "service", l.serviceName, "account", account).Output()
+		if err == nil && len(bytes.TrimSpace(output)) > 0 {
+			return bytes.TrimSpace(output), nil
+		}
+	}
+
+	return l.loadFile(path, aad)
+}
+
+// storeSecretService stores data in the Secret Service. The secret is passed
+// on stdin so it never appears in the process list.
+func (l *LinuxCredentialManager) storeSecretService(account string, data []byte) error {
+	cmd := exec.Command(l.secretTool, "store",
+		"--label=Gym Door Bridge "+account,
+		"service", l.serviceName,
+		"account", account,
+	)
+	cmd.Stdin = bytes.NewReader(data)
+
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to store %s in secret service: %w (output: %s)", account, err, strings.TrimSpace(string(output)))
+	}
+
+	return nil
+}
+
+// storeFile encrypts data into the file at path
+func (l *LinuxCredentialManager) storeFile(path string, data, aad []byte) error {
+	key, err := l.fileKey(true)
+	if err != nil {
+		return err
+	}
+
+	gcm, err := newCredentialCipher(key)
+	if err != nil {
+		return err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	// File layout: version byte, nonce, sealed credentials
+	sealed := append([]byte{credentialFileVersion}, nonce...)
+	sealed = gcm.Seal(sealed, nonce, data, aad)
+
+	if err := writePrivateFile(path, sealed); err != nil {
+		return fmt.Errorf("failed to write credential file: %w", err)
+	}
+
+	return nil
+}
+
+// loadFile decrypts the file at path
+func (l *LinuxCredentialManager) loadFile(path string, aad []byte) ([]byte, error) {
+	sealed, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read credential file: %w", err)
+	}
+
+	key, err := l.fileKey(false)
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := newCredentialCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(sealed) < 1+gcm.NonceSize() || sealed[0] != credentialFileVersion {
+		return nil, fmt.Errorf("unsupported credential file format")
+	}
+	nonce := sealed[1 : 1+gcm.NonceSize()]
+
+	data, err := gcm.Open(nil, nonce, sealed[1+gcm.NonceSize():], aad)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt credential file: %w", err)
+	}
+
+	return data, nil
+}
+
+// fileKey derives the credential file key from the local secret and the
+// machine ID, creating the secret on first use when create is set
+func (l *LinuxCredentialManager) fileKey(create bool) ([]byte, error) {
+	secret, err := os.ReadFile(l.secretPath)
+	if os.IsNotExist(err) && create {
+		secret = make([]byte, credentialSecretSize)
+		if _, err := rand.Read(secret); err != nil {
+			return nil, fmt.Errorf("failed to generate credential secret: %w", err)
+		}
+		if err := writePrivateFile(l.secretPath, secret); err != nil {
+			return nil, fmt.Errorf("failed to write credential secret: %w", err)
+		}
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read credential secret: %w", err)
+	}
+
+	if len(secret) != credentialSecretSize {
+		return nil, fmt.Errorf("invalid credential secret")
+	}
+
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte("gym-door-bridge credential file v1"))
+	mac.Write(l.machineID())
+	return mac.Sum(nil), nil
+}
+
+// machineID returns the systemd machine ID. Containers often have none, in
+// which case the key is derived from the local secret alone.
+func (l *LinuxCredentialManager) machineID() []byte {
+	for _, path := range l.machineIDPaths {
+		if data, err := os.ReadFile(path); err == nil {
+			if id := bytes.TrimSpace(data); len(id) > 0 {
+				return id
+			}
+		}
+	}
+	return nil
+}
+
+// newCredentialCipher creates the AES-256-GCM cipher for the credential file
+func newCredentialCipher(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GCM: %w", err)
+	}
+
+	return gcm, nil
+}
+
+// writePrivateFile atomically writes a file with 0600 permissions. When run as
+// root, for example by the pair command, the file is given to the owner of its
+// directory so the service user can still read it.
+func writePrivateFile(path string, data []byte) error {
+	dir := filepath.Dir(path)
+
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := tmp.Chmod(0600); err != nil {
+		tmp.Close()
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	if os.Geteuid() == 0 {
+		if info, err := os.Stat(dir); err == nil {
+			if stat, ok := info.Sys().(*syscall.Stat_t); ok && stat.Uid != 0 {
+				if err := os.Chown(tmp.Name(), int(stat.Uid), int(stat.Gid)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// newPlatformCredentialManager creates a Linux credential manager
+func newPlatformCredentialManager() (CredentialManager, error) {
+	return NewLinuxCredentialManager()
+}
@@ -0,0 +1,182 @@
+//go:build linux
+
+package auth
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func newTestLinuxCredentialManager(t *testing.T, secretTool string) *LinuxCredentialManager {
+	dir := t.TempDir()
+	machineID := filepath.Join(dir, "machine-id")
+	if err := os.WriteFile(machineID, []byte("0123456789abcdef0123456789abcdef\n"), 0644); err != nil {
+		t.Fatalf("Failed to write machine id: %v", err)
+	}
+
+	manager := newLinuxCredentialManager(dir, secretTool)
+	manager.machineIDPaths = []string{machineID}
+	return manager
+}
+
+func TestLinuxCredentialManager_File(t *testing.T) {
+	manager := newTestLinuxCredentialManager(t, "")
+
+	if manager.HasCredentials() {
+		t.Error("Expected no credentials initially")
+	}
+
+	if err := manager.StoreCredentials("dev_123", "secret-device-key"); err != nil {
+		t.Fatalf("StoreCredentials() error = %v", err)
+	}
+
+	deviceID, deviceKey, err := manager.GetCredentials()
+	if err != nil {
+		t.Fatalf("GetCredentials() error = %v", err)
+	}
+	if deviceID != "dev_123" || deviceKey != "secret-device-key" {
+		t.Errorf("Expected dev_123/secret-device-key, got %s/%s", deviceID, deviceKey)
+	}
+
+	for _, path := range []string{manager.credPath, manager.secretPath} {
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatalf("Failed to stat %s: %v", path, err)
+		}
+		if info.Mode().Perm() != 0600 {
+			t.Errorf("Expected %s to have 0600 permissions, got %v", path, info.Mode().Perm())
+		}
+	}
+
+	data, err := os.ReadFile(manager.credPath)
+	if err != nil {
+		t.Fatalf("Failed to read credential file: %v", err)
+	}
+	if bytes.Contains(data, []byte("secret-device-key")) || bytes.Contains(data, []byte("dev_123")) {
+		t.Error("Credential file should not contain plain-text credentials")
+	}
+
+	if err := manager.DeleteCredentials(); err != nil {
+		t.Fatalf("DeleteCredentials() error = %v", err)
+	}
+	if manager.HasCredentials() {
+		t.Error("Expected no credentials after deleting")
+	}
+	if _, err := os.Stat(manager.secretPath); err != nil {
+		t.Error("Local secret should be kept for the next pairing")
+	}
+}
+
+func TestLinuxCredentialManager_FileBoundToMachine(t *testing.T) {
+	manager := newTestLinuxCredentialManager(t, "")
+	if err := manager.StoreCredentials("dev_123", "secret-device-key"); err != nil {
+		t.Fatalf("StoreCredentials() error = %v", err)
+	}
+
+	// A copy of the data directory on another machine can't be decrypted
+	otherMachineID := filepath.Join(t.TempDir(), "machine-id")
+	if err := os.WriteFile(otherMachineID, []byte("fedcba9876543210fedcba9876543210\n"), 0644); err != nil {
+		t.Fatalf("Failed to write machine id: %v", err)
+	}
+	manager.machineIDPaths = []string{otherMachineID}
+
+	if _, _, err := manager.GetCredentials(); err == nil {
+		t.Error("Expected decryption to fail with a different machine id")
+	}
+}
+
+func TestLinuxCredentialManager_TamperedFile(t *testing.T) {
+	manager := newTestLinuxCredentialManager(t, "")
+	if err := manager.StoreCredentials("dev_123", "secret-device-key"); err != nil {
+		t.Fatalf("StoreCredentials() error = %v", err)
+	}
+
+	data, err := os.ReadFile(manager.credPath)
+	if err != nil {
+		t.Fatalf("Failed to read credential file: %v", err)
+	}
+	data[len(data)-1] ^= 0xff
+	if err := os.WriteFile(manager.credPath, data, 0600); err != nil {
+		t.Fatalf("Failed to write credential file: %v", err)
+	}
+
+	if manager.HasCredentials() {
+		t.Error("Expected tampered credentials to be rejected")
+	}
+}
+
+func TestLinuxCredentialManager_SecretService(t *testing.T) {
+	// Fake secret-tool keeping the secret in a file next to the script
+	dir := t.TempDir()
+	secretTool := filepath.Join(dir, "secret-tool")
+	script := `#!/bin/sh
+store="$(dirname "$0")/secret"
+case "$1" in
+store) cat > "$store" ;;
+lookup) [ -f "$store" ] && cat "$store" || exit 1 ;;
+clear) rm -f "$store" ;;
+esac
+`
+	if err := os.WriteFile(secretTool, []byte(script), 0755); err != nil {
+		t.Fatalf("Failed to write fake secret-tool: %v", err)
+	}
+
+	manager := newTestLinuxCredentialManager(t, secretTool)
+	if err := manager.StoreCredentials("dev_123", "secret-device-key"); err != nil {
+		t.Fatalf("StoreCredentials() error = %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "secret")); err != nil {
+		t.Fatalf("Expected credentials in the secret service: %v", err)
+	}
+	if _, err := os.Stat(manager.credPath); !os.IsNotExist(err) {
+		t.Error("Expected no credential file when the secret service is used")
+	}
+
+	deviceID, deviceKey, err := manager.GetCredentials()
+	if err != nil {
+		t.Fatalf("GetCredentials() error = %v", err)
+	}
+	if deviceID != "dev_123" || deviceKey != "secret-device-key" {
+		t.Errorf("Expected dev_123/secret-device-key, got %s/%s", deviceID, deviceKey)
+	}
+
+	if err := manager.DeleteCredentials(); err != nil {
+		t.Fatalf("DeleteCredentials() error = %v", err)
+	}
+	if manager.HasCredentials() {
+		t.Error("Expected no credentials after deleting")
+	}
+}
+
+func TestLinuxCredentialManager_Secret(t *testing.T) {
+	manager := newTestLinuxCredentialManager(t, "")
+
+	secret, err := manager.GetSecret("database-keys")
+	if err != nil || secret != nil {
+		t.Fatalf("Expected no secret initially, got %v (%v)", secret, err)
+	}
+
+	key := []byte{0x00, 0x01, 0xfe, 0xff}
+	if err := manager.StoreSecret("database-keys", key); err != nil {
+		t.Fatalf("StoreSecret() error = %v", err)
+	}
+
+	secret, err = manager.GetSecret("database-keys")
+	if err != nil {
+		t.Fatalf("GetSecret() error = %v", err)
+	}
+	if !bytes.Equal(secret, key) {
+		t.Errorf("Expected %v, got %v", key, secret)
+	}
+
+	// Secrets are kept apart from the device credentials
+	if manager.HasCredentials() {
+		t.Error("Storing a secret should not create credentials")
+	}
+	if secret, _ := manager.GetSecret("other"); secret != nil {
+		t.Errorf("Expected no secret named other, got %v", secret)
+	}
+}
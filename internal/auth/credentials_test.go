@@ -23,6 +23,13 @@ func TestNewCredentialManager(t *testing.T) {
 		if credManager == nil {
 			t.Error("Expected non-nil credential manager on macOS")
 		}
+	case "linux":
+		if err != nil {
+			t.Errorf("Expected no error on Linux, got: %v", err)
+		}
+		if credManager == nil {
+			t.Error("Expected non-nil credential manager on Linux")
+		}
 	default:
 		if err == nil {
 			t.Error("Expected error on unsupported platform")
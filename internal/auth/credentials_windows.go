@@ -122,6 +122,51 @@ func (w *WindowsCredentialManager) HasCredentials() bool {
 	return w.encryptedFileExists()
 }
 
+// StoreSecret stores a named secret, such as a data-encryption key, next to the
+// credentials file using Windows DPAPI
+func (w *WindowsCredentialManager) StoreSecret(name string, secret []byte) error {
+	encryptedData, err := w.encryptData(secret)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt secret %s: %w", name, err)
+	}
+
+	// Write to a temporary file first so an interrupted write keeps the old secret
+	path := w.secretPath(name)
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, encryptedData, 0600); err != nil {
+		return fmt.Errorf("failed to write secret %s: %w", name, err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to write secret %s: %w", name, err)
+	}
+
+	return nil
+}
+
+// GetSecret retrieves a named secret, returning nil when it doesn't exist
+func (w *WindowsCredentialManager) GetSecret(name string) ([]byte, error) {
+	encryptedData, err := os.ReadFile(w.secretPath(name))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read secret %s: %w", name, err)
+	}
+
+	secret, err := w.decryptData(encryptedData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt secret %s: %w", name, err)
+	}
+
+	return secret, nil
+}
+
+// secretPath returns the file holding a named secret
+func (w *WindowsCredentialManager) secretPath(name string) string {
+	return filepath.Join(filepath.Dir(w.credPath), name+".dat")
+}
+
 // encryptData encrypts data using Windows DPAPI
 func (w *WindowsCredentialManager) encryptData(data []byte) ([]byte, error) {
 	var inBlob dataBlob
@@ -0,0 +1,35 @@
+package auth
+
+import (
+	"fmt"
+
+	"gym-door-bridge/internal/config"
+)
+
+// MigrateConfigCredentials moves a device key kept in plain text in the
+// configuration file into the credential store and blanks it in the file. It
+// reports whether the file was changed.
+//
+// A key rotated after pairing is only written to the store, so when the store
+// already holds credentials for the same device they are kept and the stale
+// key in the file is dropped. cfg is updated to the credentials in effect.
+func (a *AuthManager) MigrateConfigCredentials(cfg *config.Config) (bool, error) {
+	if cfg.DeviceID == "" || cfg.DeviceKey == "" || cfg.FileUsed() == "" {
+		return false, nil
+	}
+
+	storedID, storedKey, err := a.credManager.GetCredentials()
+	if err == nil && storedID == cfg.DeviceID {
+		cfg.DeviceKey = storedKey
+		a.authenticator = NewHMACAuthenticator(storedID, storedKey)
+	} else if err := a.SetCredentials(cfg.DeviceID, cfg.DeviceKey); err != nil {
+		return false, err
+	}
+
+	removed, err := config.RemoveDeviceKey(cfg.FileUsed())
+	if err != nil {
+		return false, fmt.Errorf("failed to remove device key from config file: %w", err)
+	}
+
+	return removed, nil
+}
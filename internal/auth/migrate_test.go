@@ -0,0 +1,84 @@
+package auth
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gym-door-bridge/internal/config"
+)
+
+func writeTestConfig(t *testing.T, content string) *config.Config {
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(configFile, []byte(content), 0600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	cfg, err := config.Load(configFile)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	return cfg
+}
+
+func TestAuthManager_MigrateConfigCredentials(t *testing.T) {
+	cfg := writeTestConfig(t, "device_id: dev_123\ndevice_key: plain-key\n")
+	mock := NewMockCredentialManager()
+	authManager := &AuthManager{credManager: mock}
+
+	migrated, err := authManager.MigrateConfigCredentials(cfg)
+	if err != nil {
+		t.Fatalf("MigrateConfigCredentials() error = %v", err)
+	}
+	if !migrated {
+		t.Error("Expected the config file to be migrated")
+	}
+
+	deviceID, deviceKey, err := mock.GetCredentials()
+	if err != nil || deviceID != "dev_123" || deviceKey != "plain-key" {
+		t.Errorf("Expected stored dev_123/plain-key, got %s/%s (%v)", deviceID, deviceKey, err)
+	}
+	if !authManager.IsAuthenticated() {
+		t.Error("Expected auth manager to be authenticated after migration")
+	}
+
+	data, err := os.ReadFile(cfg.FileUsed())
+	if err != nil {
+		t.Fatalf("Failed to read config file: %v", err)
+	}
+	if strings.Contains(string(data), "plain-key") {
+		t.Error("Device key should be scrubbed from the config file")
+	}
+	if !strings.Contains(string(data), "dev_123") {
+		t.Error("Device ID should be kept in the config file")
+	}
+	if cfg.DeviceKey != "plain-key" {
+		t.Error("Device key should stay set for the running process")
+	}
+
+	// Nothing left to migrate
+	migrated, err = authManager.MigrateConfigCredentials(writeTestConfig(t, "device_id: dev_123\n"))
+	if err != nil || migrated {
+		t.Errorf("Expected no migration without a device key, got %v (%v)", migrated, err)
+	}
+}
+
+func TestAuthManager_MigrateConfigCredentialsKeepsRotatedKey(t *testing.T) {
+	cfg := writeTestConfig(t, "device_id: dev_123\ndevice_key: stale-key\n")
+	mock := NewMockCredentialManager()
+	mock.StoreCredentials("dev_123", "rotated-key")
+	authManager := &AuthManager{credManager: mock}
+
+	if _, err := authManager.MigrateConfigCredentials(cfg); err != nil {
+		t.Fatalf("MigrateConfigCredentials() error = %v", err)
+	}
+
+	_, deviceKey, _ := mock.GetCredentials()
+	if deviceKey != "rotated-key" {
+		t.Errorf("Expected the rotated key to be kept, got %s", deviceKey)
+	}
+	if cfg.DeviceKey != "rotated-key" {
+		t.Errorf("Expected config to use the rotated key, got %s", cfg.DeviceKey)
+	}
+}
@@ -0,0 +1,146 @@
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"gym-door-bridge/internal/commands"
+)
+
+// unlockDoorParams are the parameters of the unlock_door command
+type unlockDoorParams struct {
+	DoorID     string `json:"doorId"`     // Empty unlocks the default door
+	DurationMs int    `json:"durationMs"` // 0 uses the door's unlock duration
+}
+
+// restartAdapterParams are the parameters of the restart_adapter command
+type restartAdapterParams struct {
+	Name string `json:"name"`
+}
+
+// registerCommandHandlers routes remote commands to the bridge components
+func (m *Manager) registerCommandHandlers(dispatcher *commands.Dispatcher) {
+	dispatcher.Register(commands.TypeUnlockDoor, m.handleUnlockDoorCommand)
+	dispatcher.Register(commands.TypeReloadConfig, m.handleReloadConfigCommand)
+	dispatcher.Register(commands.TypeSyncMembers, m.handleSyncMembersCommand)
+	dispatcher.Register(commands.TypeRunDiagnostics, m.handleRunDiagnosticsCommand)
+	dispatcher.Register(commands.TypeRestartAdapter, m.handleRestartAdapterCommand)
+}
+
+// handleUnlockDoorCommand unlocks a door for a remote operator
+func (m *Manager) handleUnlockDoorCommand(ctx context.Context, raw json.RawMessage) (interface{}, error) {
+	var params unlockDoorParams
+	if err := decodeParams(raw, &params); err != nil {
+		return nil, err
+	}
+	if params.DurationMs < 0 {
+		return nil, fmt.Errorf("durationMs must not be negative")
+	}
+
+	var err error
+	if params.DoorID != "" {
+		err = m.doorController.UnlockDoorByID(ctx, params.DoorID, params.DurationMs)
+	} else {
+		err = m.doorController.UnlockDoor(ctx, "", params.DurationMs)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return params, nil
+}
+
+// handleReloadConfigCommand re-reads the configuration file, reconciles the
+// running adapters against it and reloads the door settings and schedules.
+// Other settings take effect on the next restart.
+func (m *Manager) handleReloadConfigCommand(ctx context.Context, raw json.RawMessage) (interface{}, error) {
+	result, err := m.reloadConfigFile(ctx, reconcileSourceCommand)
+	if err != nil {
+		return nil, err
+	}
+
+	reloaded := append([]string{}, result.ChangedAdapters...)
+	removed := append([]string{}, result.RemovedAdapters...)
+
+	m.logger.WithField("reloadedAdapters", reloaded).Info("Configuration reloaded by remote command")
+	return map[string]interface{}{
+		"reloadedAdapters":  reloaded,
+		"removedAdapters":   removed,
+		"restartRequired":   result.RestartRequired,
+		"schedulesReloaded": true,
+	}, nil
+}
+
+// handleSyncMembersCommand refreshes the member roster and the local entitlement cache immediately
+func (m *Manager) handleSyncMembersCommand(ctx context.Context, raw json.RawMessage) (interface{}, error) {
+	if m.entitlementSyncer == nil && m.rosterSyncer == nil {
+		return nil, fmt.Errorf("member sync is disabled")
+	}
+
+	result := make(map[string]interface{})
+	if m.rosterSyncer != nil {
+		if err := m.rosterSyncer.Sync(ctx); err != nil {
+			return nil, err
+		}
+		result["roster"] = m.rosterSyncer.GetStats()
+	}
+	if m.entitlementSyncer != nil {
+		if err := m.entitlementSyncer.Sync(ctx); err != nil {
+			return nil, err
+		}
+		result["entitlements"] = m.entitlementSyncer.GetStats()
+	}
+	return result, nil
+}
+
+// handleRunDiagnosticsCommand refreshes the health checks and reports the bridge status
+func (m *Manager) handleRunDiagnosticsCommand(ctx context.Context, raw json.RawMessage) (interface{}, error) {
+	if err := m.healthMonitor.UpdateHealth(ctx); err != nil {
+		m.logger.WithError(err).Warn("Failed to update health for diagnostics")
+	}
+	return m.GetStats(), nil
+}
+
+// handleRestartAdapterCommand stops and restarts an adapter with its configured settings
+func (m *Manager) handleRestartAdapterCommand(ctx context.Context, raw json.RawMessage) (interface{}, error) {
+	var params restartAdapterParams
+	if err := decodeParams(raw, &params); err != nil {
+		return nil, err
+	}
+	if params.Name == "" {
+		return nil, fmt.Errorf("adapter name is required")
+	}
+
+	m.mu.RLock()
+	adapterConfigs := m.config.GetAdapterConfigs()
+	m.mu.RUnlock()
+
+	for _, adapterConfig := range adapterConfigs {
+		if adapterConfig.Name != params.Name {
+			continue
+		}
+		if err := m.adapterManager.ReloadAdapter(adapterConfig); err != nil {
+			return nil, err
+		}
+
+		m.logger.WithField("adapter", params.Name).Info("Adapter restarted by remote command")
+		return map[string]interface{}{
+			"name":   params.Name,
+			"status": m.adapterManager.GetAdapterStatus()[params.Name],
+		}, nil
+	}
+
+	return nil, fmt.Errorf("adapter %s is not configured", params.Name)
+}
+
+// decodeParams unmarshals command parameters; missing parameters leave the defaults
+func decodeParams(raw json.RawMessage, params interface{}) error {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil
+	}
+	if err := json.Unmarshal(raw, params); err != nil {
+		return fmt.Errorf("invalid command parameters: %w", err)
+	}
+	return nil
+}
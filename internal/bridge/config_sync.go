@@ -0,0 +1,280 @@
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+
+	"gym-door-bridge/internal/access"
+	"gym-door-bridge/internal/client"
+	"gym-door-bridge/internal/config"
+	"gym-door-bridge/internal/configsync"
+	"gym-door-bridge/internal/database"
+	"gym-door-bridge/internal/door"
+)
+
+// restoreAppliedConfig overlays the platform config version in effect onto the
+// local configuration, before any component is created from it
+func (m *Manager) restoreAppliedConfig() {
+	version, err := configsync.Restore(m.database, m.config)
+	if err != nil {
+		m.logger.WithError(err).Warn("Failed to restore platform configuration, using the local configuration")
+		return
+	}
+	if version > 0 {
+		m.appliedConfigVersion = version
+		m.logger.WithField("version", version).Info("Restored platform configuration")
+	}
+}
+
+// newConfigSyncer creates the syncer applying the config versions the platform
+// announces in heartbeat responses
+func (m *Manager) newConfigSyncer(httpClient *client.HTTPClient) *configsync.Syncer {
+	syncConfig := configsync.Config{
+		History:        m.config.ConfigSync.History,
+		SettleTime:     time.Duration(m.config.ConfigSync.SettleTime) * time.Second,
+		AppliedVersion: m.appliedConfigVersion,
+	}
+	applier := &configApplier{manager: m, source: reconcileSourcePlatform}
+	return configsync.NewSyncer(httpClient, m.database, applier, syncConfig, m.logger)
+}
+
+// configApplier puts configurations into effect on the running bridge.
+// Adapters are reconciled, door settings are updated in place, and schedules
+// and holidays are replaced in the database. Relays, door inputs and the API
+// server are opened at startup, so changes to them are recorded in the
+// configuration and take effect on the next restart.
+type configApplier struct {
+	manager *Manager
+	source  string // Recorded in the audit log of adapter reconciles
+}
+
+func (a *configApplier) CurrentConfig() config.Config {
+	a.manager.mu.RLock()
+	defer a.manager.mu.RUnlock()
+
+	return *a.manager.config
+}
+
+func (a *configApplier) CurrentSchedules() ([]configsync.ScheduleSpec, []configsync.HolidaySpec, error) {
+	db := a.manager.database
+
+	scheduleRecords, err := db.ListDoorSchedules()
+	if err != nil {
+		return nil, nil, err
+	}
+	schedules := make([]configsync.ScheduleSpec, 0, len(scheduleRecords))
+	for _, record := range scheduleRecords {
+		windows, err := access.ParseAllowedHours(record.Windows)
+		if err != nil {
+			return nil, nil, fmt.Errorf("schedule %s: %w", record.ID, err)
+		}
+		schedules = append(schedules, configsync.ScheduleSpec{
+			ID:      record.ID,
+			Name:    record.Name,
+			DoorIDs: record.DoorIDs,
+			Windows: windows,
+			Enabled: record.Enabled,
+		})
+	}
+
+	holidayRecords, err := db.ListDoorHolidays()
+	if err != nil {
+		return nil, nil, err
+	}
+	holidays := make([]configsync.HolidaySpec, 0, len(holidayRecords))
+	for _, record := range holidayRecords {
+		holidays = append(holidays, configsync.HolidaySpec{
+			ID:    record.ID,
+			Name:  record.Name,
+			Date:  record.Date,
+			Start: record.StartTime,
+			End:   record.EndTime,
+		})
+	}
+
+	return schedules, holidays, nil
+}
+
+func (a *configApplier) Apply(ctx context.Context, cfg config.Config, schedules []configsync.ScheduleSpec, holidays []configsync.HolidaySpec) (*configsync.ApplyResult, error) {
+	m := a.manager
+	current := a.CurrentConfig()
+	result := &configsync.ApplyResult{}
+
+	// The adapter section is recorded even when an adapter fails to start, so
+	// that a rollback reconciles against what was attempted
+	reconciled, err := m.reconcileAdapters(a.source, &cfg)
+	result.ChangedAdapters = reconciled.Started()
+	result.RemovedAdapters = reconciled.Removed
+	m.mu.Lock()
+	m.config.EnabledAdapters = cfg.EnabledAdapters
+	m.config.AdapterConfigs = cfg.AdapterConfigs
+	m.mu.Unlock()
+	if err != nil {
+		return result, err
+	}
+
+	if err := a.applyDoors(current.GetDoorConfigs(), cfg.GetDoorConfigs(), result); err != nil {
+		return result, err
+	}
+	m.mu.Lock()
+	m.config.Doors = cfg.Doors
+	m.config.DoorRelay = cfg.DoorRelay
+	m.mu.Unlock()
+
+	if !reflect.DeepEqual(current.APIServer, cfg.APIServer) {
+		result.RestartRequired = append(result.RestartRequired, "api")
+		m.mu.Lock()
+		m.config.APIServer = cfg.APIServer
+		m.mu.Unlock()
+	}
+
+	if schedules != nil || holidays != nil {
+		if err := a.applySchedules(ctx, schedules, holidays); err != nil {
+			return result, err
+		}
+	}
+
+	return result, nil
+}
+
+func (a *configApplier) UnhealthyAdapters(names []string) []string {
+	var unhealthy []string
+	for _, name := range names {
+		adapter, ok := a.manager.adapterManager.GetAdapter(name)
+		if !ok || !adapter.IsHealthy() {
+			unhealthy = append(unhealthy, name)
+		}
+	}
+	return unhealthy
+}
+
+// applyDoors updates the settings of running doors in place. Adding or removing
+// doors and changing their relay or inputs needs a restart.
+func (a *configApplier) applyDoors(current, target []config.DoorConfig, result *configsync.ApplyResult) error {
+	if reflect.DeepEqual(current, target) {
+		return nil
+	}
+
+	sameDoors := len(current) == len(target)
+	for i := 0; sameDoors && i < len(current); i++ {
+		sameDoors = current[i].ID == target[i].ID
+	}
+	if !sameDoors {
+		result.RestartRequired = append(result.RestartRequired, "doors")
+		return nil
+	}
+
+	doors := make([]door.Door, 0, len(target))
+	for i, doorConfig := range target {
+		previous := current[i]
+		if !reflect.DeepEqual(previous.Relay, doorConfig.Relay) {
+			result.RestartRequired = append(result.RestartRequired, fmt.Sprintf("doors.%s.relay", doorConfig.ID))
+		}
+		if !reflect.DeepEqual(previous.Contact, doorConfig.Contact) {
+			result.RestartRequired = append(result.RestartRequired, fmt.Sprintf("doors.%s.contact", doorConfig.ID))
+		}
+		if !reflect.DeepEqual(previous.Rex, doorConfig.Rex) {
+			result.RestartRequired = append(result.RestartRequired, fmt.Sprintf("doors.%s.rex", doorConfig.ID))
+		}
+
+		doors = append(doors, door.Door{
+			ID:               doorConfig.ID,
+			Name:             doorConfig.Name,
+			Zone:             doorConfig.Zone,
+			Adapters:         doorConfig.Adapters,
+			UnlockDurationMs: doorConfig.UnlockDuration,
+			RexUnlocks:       doorConfig.RexUnlocks,
+			HeldOpenTimeout:  time.Duration(doorConfig.HeldOpenTimeout) * time.Second,
+		})
+		if err := a.manager.database.UpsertDoor(&database.Door{
+			ID:               doorConfig.ID,
+			Name:             doorConfig.Name,
+			Zone:             doorConfig.Zone,
+			Adapters:         doorConfig.Adapters,
+			Relay:            doorConfig.Relay,
+			UnlockDurationMs: doorConfig.UnlockDuration,
+		}); err != nil {
+			return fmt.Errorf("failed to store door %s: %w", doorConfig.ID, err)
+		}
+	}
+
+	return a.manager.doorController.UpdateDoors(doors)
+}
+
+// applySchedules replaces the stored free-access schedules and holidays and
+// reloads them into the door controller. Nil lists are left unchanged.
+func (a *configApplier) applySchedules(ctx context.Context, schedules []configsync.ScheduleSpec, holidays []configsync.HolidaySpec) error {
+	db := a.manager.database
+
+	if schedules != nil {
+		existing, err := db.ListDoorSchedules()
+		if err != nil {
+			return err
+		}
+		keep := make(map[string]bool, len(schedules))
+		for _, schedule := range schedules {
+			keep[schedule.ID] = true
+		}
+		for _, record := range existing {
+			if keep[record.ID] {
+				continue
+			}
+			if _, err := db.DeleteDoorSchedule(record.ID); err != nil {
+				return err
+			}
+		}
+
+		for _, schedule := range schedules {
+			windows, err := json.Marshal(schedule.Windows)
+			if err != nil {
+				return fmt.Errorf("failed to marshal schedule windows: %w", err)
+			}
+			if err := db.UpsertDoorSchedule(&database.DoorSchedule{
+				ID:      schedule.ID,
+				Name:    schedule.Name,
+				DoorIDs: schedule.DoorIDs,
+				Windows: string(windows),
+				Enabled: schedule.Enabled,
+			}); err != nil {
+				return err
+			}
+		}
+	}
+
+	if holidays != nil {
+		existing, err := db.ListDoorHolidays()
+		if err != nil {
+			return err
+		}
+		// Removed holidays go first, as a date may move to another holiday
+		keep := make(map[string]bool, len(holidays))
+		for _, holiday := range holidays {
+			keep[holiday.ID] = true
+		}
+		for _, record := range existing {
+			if keep[record.ID] {
+				continue
+			}
+			if _, err := db.DeleteDoorHoliday(record.ID); err != nil {
+				return err
+			}
+		}
+
+		for _, holiday := range holidays {
+			if err := db.UpsertDoorHoliday(&database.DoorHoliday{
+				ID:        holiday.ID,
+				Name:      holiday.Name,
+				Date:      holiday.Date,
+				StartTime: holiday.Start,
+				EndTime:   holiday.End,
+			}); err != nil {
+				return err
+			}
+		}
+	}
+
+	return a.manager.doorController.ReloadSchedules(ctx)
+}
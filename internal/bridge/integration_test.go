@@ -10,7 +10,11 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"gym-door-bridge/internal/access"
+	"gym-door-bridge/internal/adapters/biometric"
+	"gym-door-bridge/internal/api"
 	"gym-door-bridge/internal/config"
+	"gym-door-bridge/internal/configsync"
 	"gym-door-bridge/internal/database"
 )
 
@@ -170,6 +174,19 @@ func TestBridgeManagerComponentIntegration(t *testing.T) {
 		assert.Contains(t, healthy, "simulator")
 	})
 
+	// Test the readiness and liveness reported to the service manager
+	t.Run("Liveness", func(t *testing.T) {
+		select {
+		case <-manager.Ready():
+		default:
+			t.Fatal("started manager is not ready")
+		}
+
+		checkCtx, checkCancel := context.WithTimeout(ctx, 5*time.Second)
+		defer checkCancel()
+		assert.NoError(t, manager.CheckAlive(checkCtx))
+	})
+
 	// Test queue manager integration
 	t.Run("QueueManager", func(t *testing.T) {
 		assert.NotNil(t, manager.queueManager)
@@ -221,6 +238,56 @@ func TestBridgeManagerComponentIntegration(t *testing.T) {
 		assert.Contains(t, stats, "failureCount")
 	})
 
+	// Test applying a platform configuration to the running bridge
+	t.Run("ConfigApplier", func(t *testing.T) {
+		applier := &configApplier{manager: manager, source: reconcileSourceAPI}
+		doc := &configsync.Document{
+			Version: 1,
+			Adapters: []configsync.AdapterSpec{
+				{Name: "simulator", Settings: map[string]interface{}{"eventInterval": 10, "autoGenerate": true}},
+			},
+			Schedules: []configsync.ScheduleSpec{
+				{ID: "open-gym", Windows: []access.AccessWindow{{Days: []int{0, 1, 2, 3, 4, 5, 6}, Start: "06:00", End: "22:00"}}, Enabled: true},
+			},
+			API: &configsync.APISpec{Enabled: true, Port: 8099},
+		}
+		target := applier.CurrentConfig()
+		doc.ApplyTo(&target)
+
+		result, err := applier.Apply(ctx, target, doc.Schedules, doc.Holidays)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"simulator"}, result.ChangedAdapters)
+		assert.Equal(t, []string{"api"}, result.RestartRequired)
+		assert.Empty(t, applier.UnhealthyAdapters(result.ChangedAdapters))
+
+		schedules, holidays, err := applier.CurrentSchedules()
+		require.NoError(t, err)
+		assert.Len(t, schedules, 1)
+		assert.Empty(t, holidays)
+		assert.Equal(t, 10, manager.config.AdapterConfigs["simulator"]["eventInterval"])
+
+		// Applying the same configuration again changes nothing
+		result, err = applier.Apply(ctx, target, nil, nil)
+		require.NoError(t, err)
+		assert.Empty(t, result.ChangedAdapters)
+		assert.Empty(t, result.RestartRequired)
+	})
+
+	// Test adapter changes from the local API taking effect without a restart
+	t.Run("ConfigManagerWrapper", func(t *testing.T) {
+		wrapper := &configManagerWrapper{config: manager.config, manager: manager}
+
+		response, err := wrapper.UpdateConfig(&api.ConfigUpdateRequest{EnabledAdapters: []string{}})
+		require.NoError(t, err)
+		assert.False(t, response.RequiresRestart)
+		assert.NotContains(t, manager.adapterManager.GetAdapterStatus(), "simulator")
+
+		response, err = wrapper.UpdateConfig(&api.ConfigUpdateRequest{EnabledAdapters: []string{"simulator"}})
+		require.NoError(t, err)
+		assert.False(t, response.RequiresRestart)
+		assert.Contains(t, manager.adapterManager.GetHealthyAdapters(), "simulator")
+	})
+
 	// Stop manager
 	cancel()
 
@@ -342,6 +409,102 @@ func TestBridgeManagerLifecycle(t *testing.T) {
 	}
 }
 
+// TestBridgeManagerBiometricTerminals runs two terminals of the biometric
+// adapter type side by side, one at each door
+func TestBridgeManagerBiometricTerminals(t *testing.T) {
+	tempDB := createTempDB(t)
+	defer os.Remove(tempDB)
+
+	terminalSettings := func() map[string]interface{} {
+		return map[string]interface{}{"type": "biometric", "device_type": "simulator", "realtime": false}
+	}
+	cfg := &config.Config{
+		DeviceID:          "terminals-test",
+		ServerURL:         "https://api.test.com",
+		Tier:              "normal",
+		QueueMaxSize:      500,
+		HeartbeatInterval: 60,
+		UnlockDuration:    3000,
+		DatabasePath:      tempDB,
+		LogLevel:          "error",
+		EnabledAdapters:   []string{"front-terminal", "studio-terminal"},
+		AdapterConfigs: map[string]map[string]interface{}{
+			"front-terminal":  terminalSettings(),
+			"studio-terminal": terminalSettings(),
+		},
+		Doors: []config.DoorConfig{
+			{ID: "front", Adapters: []string{"front-terminal"}},
+			{ID: "studio", Adapters: []string{"studio-terminal"}},
+		},
+		TemplateBackup: config.TemplateBackupConfig{Consent: true, Interval: 3600},
+		APIServer: config.APIServerConfig{
+			Enabled: false,
+		},
+	}
+
+	manager, err := NewManager(cfg, WithVersion("terminals-test"))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	managerDone := make(chan error, 1)
+	go func() {
+		managerDone <- manager.Start(ctx)
+	}()
+
+	select {
+	case <-manager.Ready():
+	case <-time.After(10 * time.Second):
+		t.Fatal("manager did not start")
+	}
+
+	// Both terminals run as separate adapters under their own names
+	require.Eventually(t, func() bool {
+		return len(manager.adapterManager.GetHealthyAdapters()) == 2
+	}, 5*time.Second, 50*time.Millisecond, "both terminals should connect")
+
+	var names []string
+	for _, terminal := range (&adapterTerminals{manager.adapterManager}).Terminals() {
+		names = append(names, terminal.Name())
+	}
+	assert.Equal(t, []string{"front-terminal", "studio-terminal"}, names)
+
+	// Each door unlocks through its own terminal
+	require.NoError(t, manager.doorController.UnlockDoorByID(ctx, "front", 0))
+	require.NoError(t, manager.doorController.UnlockDoorByID(ctx, "studio", 0))
+
+	// A fingerprint enrolled at the front door is backed up and restored to the studio
+	front, ok := manager.adapterManager.GetAdapter("front-terminal")
+	require.True(t, ok)
+	studio, ok := manager.adapterManager.GetAdapter("studio-terminal")
+	require.True(t, ok)
+	frontTerminal := front.(*biometric.BiometricAdapter)
+	studioTerminal := studio.(*biometric.BiometricAdapter)
+
+	enrolled := biometric.FingerprintTemplate{DeviceUserID: 1, FingerIndex: 6, Flag: 1, Data: []byte("template-data")}
+	require.NoError(t, frontTerminal.WriteTemplate(enrolled))
+
+	report, err := manager.templateSyncer.Restore(ctx, "studio-terminal")
+	require.NoError(t, err)
+	assert.Equal(t, 2, report.Terminals)
+	assert.Equal(t, 1, report.Written)
+	assert.Empty(t, report.Errors)
+
+	templates, err := studioTerminal.GetTemplates()
+	require.NoError(t, err)
+	require.Len(t, templates, 1)
+	assert.Equal(t, enrolled.Data, templates[0].Data)
+
+	cancel()
+	select {
+	case err := <-managerDone:
+		assert.NoError(t, err)
+	case <-time.After(10 * time.Second):
+		t.Fatal("Manager did not stop within timeout")
+	}
+}
+
 // TestBridgeManagerErrorHandling tests error handling in the bridge manager
 func TestBridgeManagerErrorHandling(t *testing.T) {
 	t.Run("InvalidDatabasePath", func(t *testing.T) {
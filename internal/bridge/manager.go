@@ -2,29 +2,38 @@ package bridge
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log/slog"
-	"runtime"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/sirupsen/logrus"
 
+	"gym-door-bridge/internal/access"
 	"gym-door-bridge/internal/adapters"
+	"gym-door-bridge/internal/adapters/input"
+	"gym-door-bridge/internal/adapters/relay"
 	"gym-door-bridge/internal/api"
 	"gym-door-bridge/internal/auth"
 	"gym-door-bridge/internal/client"
+	"gym-door-bridge/internal/commands"
 	"gym-door-bridge/internal/config"
+	"gym-door-bridge/internal/configsync"
 	"gym-door-bridge/internal/database"
 	"gym-door-bridge/internal/door"
 	"gym-door-bridge/internal/health"
 	"gym-door-bridge/internal/logging"
+	"gym-door-bridge/internal/monitoring"
 	"gym-door-bridge/internal/processor"
+	"gym-door-bridge/internal/provisioning"
 	"gym-door-bridge/internal/queue"
-	"gym-door-bridge/internal/service/windows"
 	"gym-door-bridge/internal/telemetry"
 	"gym-door-bridge/internal/tier"
 	"gym-door-bridge/internal/types"
+	"gym-door-bridge/internal/updater"
 )
 
 // Manager coordinates all bridge components and services
@@ -39,24 +48,51 @@ type Manager struct {
 	queueManager    queue.QueueManager
 	tierDetector    *tier.Detector
 	healthMonitor   *health.HealthMonitor
+	heartbeatManager *health.HeartbeatManager
 	doorController  *door.DoorController
+	doorRelays      []*relay.Relay
+	doorInputs      []input.Input
+	monitoringSystem *monitoring.MonitoringSystem
 	eventProcessor  *processor.EventProcessorImpl
 	submissionService *client.SubmissionService
 	
+	// Local access decisions
+	accessEngine      *access.Engine
+	entitlementSyncer *access.Syncer
+	rosterSyncer      *client.RosterSyncer
+	provisioner       *provisioning.Reconciler
+	templateSyncer    *provisioning.TemplateSyncer
+	
+	// Remote commands from the platform
+	commandChannel *commands.Channel
+	
+	// Configuration managed from the platform
+	configSyncer         *configsync.Syncer
+	appliedConfigVersion int64
+	
+	// Staged self-updates
+	updater *updater.Updater
+	
 	// API server
 	apiServer       *api.Server
 	
+	// Audit trail of live configuration changes
+	auditLogger     *api.AuditLogger
+	
 	// Service health monitoring (Windows only)
-	serviceHealthMonitor *windows.ServiceHealthMonitor
+	serviceHealthMonitor serviceMonitor
 	
 	// Installation telemetry
 	installationTelemetry *telemetry.InstallationTelemetry
 	
 	// State
 	isRunning       bool
+	ready           chan struct{} // Closed once Start has brought the bridge up
+	readyOnce       sync.Once
 	startTime       time.Time
 	version         string
 	deviceID        string
+	configFile      string
 	
 	// Context for graceful shutdown
 	ctx             context.Context
@@ -80,6 +116,13 @@ func WithDeviceID(deviceID string) ManagerOption {
 	}
 }
 
+// WithConfigFile sets the configuration file re-read by the reload_config command
+func WithConfigFile(configFile string) ManagerOption {
+	return func(m *Manager) {
+		m.configFile = configFile
+	}
+}
+
 // NewManager creates a new bridge manager
 func NewManager(cfg *config.Config, opts ...ManagerOption) (*Manager, error) {
 	logger := logging.Initialize(cfg.LogLevel)
@@ -93,6 +136,7 @@ func NewManager(cfg *config.Config, opts ...ManagerOption) (*Manager, error) {
 		cancel:    cancel,
 		version:   "unknown",
 		deviceID:  cfg.DeviceID,
+		ready:     make(chan struct{}),
 	}
 	
 	// Apply options
@@ -109,24 +153,69 @@ func NewManager(cfg *config.Config, opts ...ManagerOption) (*Manager, error) {
 	return m, nil
 }
 
+// openDatabase opens the database with the device's data-encryption key from
+// the credential store, re-encrypting values left under an older key and
+// encrypting events queued before user IDs were encrypted
+func (m *Manager) openDatabase() (*database.DB, error) {
+	dbConfig := database.Config{
+		DatabasePath:    m.config.DatabasePath,
+		PerformanceTier: database.PerformanceTier(m.config.Tier),
+	}
+	
+	var db *database.DB
+	store, err := auth.NewSecretStore()
+	if err != nil {
+		m.logger.WithError(err).Warn("Credential store unavailable, database is encrypted with the shared legacy key")
+		if db, err = database.OpenWithLegacyKey(dbConfig); err != nil {
+			return nil, err
+		}
+	} else {
+		if db, err = database.OpenWithKeyStore(dbConfig, store); err != nil {
+			return nil, err
+		}
+		m.completeKeyRotation(db)
+	}
+	
+	// Values left in the clear stay readable, so the bridge can still run
+	updated, err := db.EncryptEventQueue(m.ctx)
+	if err != nil {
+		m.logger.WithError(err).Error("Failed to encrypt queued events")
+	} else if updated > 0 {
+		m.logger.WithField("events", updated).Info("Encrypted queued events")
+	}
+	
+	return db, nil
+}
+
+// completeKeyRotation re-encrypts values still using an older key
+func (m *Manager) completeKeyRotation(db *database.DB) {
+	result, err := db.CompleteKeyRotation(m.ctx)
+	if err != nil {
+		// Values under an older key stay readable, so the bridge can still run
+		m.logger.WithError(err).Error("Failed to re-encrypt database with the current key")
+		return
+	}
+	if result.Reencrypted > 0 || result.RetiredKeys > 0 {
+		m.logger.WithFields(logrus.Fields{
+			"reencrypted":  result.Reencrypted,
+			"retired_keys": result.RetiredKeys,
+			"key_id":       db.ActiveKeyID(),
+		}).Info("Database re-encrypted with the current key")
+	}
+	if result.Failed > 0 || result.Pending > 0 {
+		m.logger.WithFields(logrus.Fields{
+			"failed":  result.Failed,
+			"pending": result.Pending,
+		}).Warn("Some database values could not be re-encrypted")
+	}
+}
+
 // initializeComponents initializes all bridge components
 func (m *Manager) initializeComponents() error {
 	m.logger.Info("Initializing bridge components")
 	
 	// Initialize database
-	// Create a default encryption key if none provided
-	encryptionKey := make([]byte, 32) // 256-bit key for AES
-	if len(encryptionKey) == 32 {
-		// Use a simple key for now - in production this should be from config
-		copy(encryptionKey, []byte("bridge-default-encryption-key-32"))
-	}
-	
-	dbConfig := database.Config{
-		DatabasePath:    m.config.DatabasePath,
-		EncryptionKey:   encryptionKey,
-		PerformanceTier: database.PerformanceTier(m.config.Tier),
-	}
-	db, err := database.NewDB(dbConfig)
+	db, err := m.openDatabase()
 	if err != nil {
 		return fmt.Errorf("failed to initialize database: %w", err)
 	}
@@ -135,10 +224,20 @@ func (m *Manager) initializeComponents() error {
 	// Initialize queue manager
 	m.queueManager = queue.NewSQLiteQueueManager(db)
 	queueConfig := queue.GetTierConfig(database.PerformanceTier(m.config.Tier))
+	if m.config.QueueRetentionPolicy != "" {
+		queueConfig.RetentionPolicy = m.config.QueueRetentionPolicy
+	}
 	if err := m.queueManager.Initialize(m.ctx, queueConfig); err != nil {
 		return fmt.Errorf("failed to initialize queue manager: %w", err)
 	}
 	
+	m.auditLogger = api.NewAuditLogger(m.logger)
+	
+	// Configuration applied from the platform survives restarts
+	if m.config.ConfigSync.Enabled {
+		m.restoreAppliedConfig()
+	}
+	
 	// Initialize adapter manager
 	// Create a slog.Logger for the adapter manager
 	slogLogger := slog.New(slog.NewTextHandler(m.logger.Writer(), &slog.HandlerOptions{
@@ -191,6 +290,19 @@ func (m *Manager) initializeComponents() error {
 	
 	// Set up event callback for adapters
 	m.adapterManager.OnEvent(func(event types.RawHardwareEvent) {
+		// Attribute the event to the door its reader is mounted at
+		if event.DoorID == "" && m.doorController != nil {
+			event.DoorID = m.doorController.DoorForAdapter(event.AdapterName)
+		}
+		
+		// Decide locally first so the door keeps working while the platform is unreachable;
+		// door alarms reported by readers carry no member to decide on
+		var decision *access.Decision
+		if m.accessEngine != nil && !types.IsDoorAlarmEventType(event.EventType) {
+			d := m.accessEngine.HandleEvent(m.ctx, event)
+			decision = &d
+		}
+		
 		// Process the raw event through the processor for deduplication and validation
 		result, err := m.eventProcessor.ProcessEvent(m.ctx, event)
 		if err != nil {
@@ -207,6 +319,22 @@ func (m *Manager) initializeComponents() error {
 			return
 		}
 		
+		// Record the local verdict on the event submitted to the cloud
+		if decision != nil {
+			result.Event.AccessReason = decision.Reason
+			if decision.AntiPassbackViolation {
+				// Soft mode lets the member through; flag the event for review
+				if result.Event.RawData == nil {
+					result.Event.RawData = make(map[string]interface{})
+				}
+				result.Event.RawData["antiPassbackViolation"] = true
+			}
+			// Before the first entitlement sync every member looks unknown
+			if !decision.Allowed && decision.Reason != access.ReasonNotSynced {
+				result.Event.EventType = types.EventTypeDenied
+			}
+		}
+		
 		// Enqueue the processed standard event
 		if err := m.queueManager.Enqueue(m.ctx, result.Event); err != nil {
 			m.logger.WithError(err).Error("Failed to enqueue processed event")
@@ -237,15 +365,62 @@ func (m *Manager) initializeComponents() error {
 		health.WithDeviceID(m.deviceID),
 	)
 	
+	// Door alarms are raised through the monitoring alert pipeline. Metrics
+	// collection is not started, so no health monitor is needed.
+	m.monitoringSystem = monitoring.NewMonitoringSystem(
+		monitoring.DefaultMonitoringConfig(),
+		nil,
+		m.queueManager,
+		m.tierDetector,
+		monitoring.WithLogger(m.logger.WithField("component", "monitoring").Logger),
+		monitoring.WithDeviceID(m.deviceID),
+	)
+	
 	// Initialize door controller
+	doorOptions := []door.DoorControllerOption{
+		door.WithLogger(m.logger.WithField("component", "door").Logger),
+		door.WithAlarmHandler(m.handleDoorAlarm),
+		door.WithSchedules(db, m.config.Location()),
+	}
+	doors, err := m.initializeDoors(slogLogger)
+	if err != nil {
+		return err
+	}
+	doorOptions = append(doorOptions, door.WithDoors(doors))
+	
 	doorConfig := door.DefaultDoorControlConfig()
 	m.doorController = door.NewDoorController(
 		doorConfig,
 		m.config,
 		&adapterRegistryWrapper{m.adapterManager},
-		door.WithLogger(m.logger.WithField("component", "door").Logger),
+		doorOptions...,
 	)
 	
+	// Initialize access-decision engine
+	if m.config.AccessControl.Enabled {
+		accessConfig := access.Config{
+			AllowUnknownMembers: m.config.AccessControl.AllowUnknownMembers,
+			ExpiryGracePeriod:   time.Duration(m.config.AccessControl.ExpiryGracePeriod) * time.Second,
+			UnlockOnExit:        m.config.AccessControl.UnlockOnExit,
+			UnlockDurationMs:    m.config.UnlockDuration,
+			MaxEventAge:         time.Duration(m.config.AccessControl.MaxEventAge) * time.Second,
+			Timezone:            m.config.AccessControl.Timezone,
+			AntiPassback: access.AntiPassbackConfig{
+				Mode:      m.config.AccessControl.AntiPassback.Mode,
+				ResetTime: m.config.AccessControl.AntiPassback.ResetTime,
+			},
+		}
+		if accessConfig.Timezone == "" {
+			// Member allowed hours follow the site's clock unless configured otherwise
+			accessConfig.Timezone = m.config.Timezone
+		}
+		accessEngine, err := access.NewEngine(accessConfig, db, m.doorController, m.logger)
+		if err != nil {
+			return fmt.Errorf("failed to initialize access engine: %w", err)
+		}
+		m.accessEngine = accessEngine
+	}
+	
 	// Initialize submission service for offline event queuing
 	authManager, err := auth.NewAuthManager()
 	if err != nil {
@@ -260,8 +435,88 @@ func (m *Manager) initializeComponents() error {
 		return fmt.Errorf("failed to create HTTP client: %w", err)
 	}
 	checkinClient := client.NewCheckinClient(httpClient, m.logger)
+	
+	// Report device health, and events lost to a full queue, to the platform
+	if authManager.IsAuthenticated() {
+		heartbeatConfig := health.GetTierHeartbeatConfig(tier.Tier(m.config.Tier))
+		if m.config.HeartbeatInterval > 0 {
+			heartbeatConfig.Interval = time.Duration(m.config.HeartbeatInterval) * time.Second
+		}
+		heartbeatOptions := []health.HeartbeatManagerOption{
+			health.WithHeartbeatLogger(m.logger.WithField("component", "heartbeat").Logger),
+			health.WithEvictionStore(db),
+			health.WithDoorStates(&doorHeartbeatStates{m.doorController}),
+			health.WithPendingCommandsHandler(m.handlePendingCommands),
+			health.WithUpdateAvailableHandler(m.handleUpdateAvailable),
+		}
+		
+		// Apply the config versions the platform announces in heartbeat responses
+		if m.config.ConfigSync.Enabled {
+			m.configSyncer = m.newConfigSyncer(httpClient)
+			heartbeatOptions = append(heartbeatOptions,
+				health.WithConfigVersion(m.configSyncer.AppliedVersion),
+				health.WithConfigVersionHandler(m.configSyncer.Notify),
+			)
+		}
+		
+		m.heartbeatManager = health.NewHeartbeatManager(
+			heartbeatConfig,
+			httpClient,
+			m.healthMonitor,
+			heartbeatOptions...,
+		)
+	} else {
+		m.logger.Warn("Device is not paired; heartbeats are disabled")
+	}
+	
+	// Keep the local entitlement cache in sync for offline access decisions
+	if m.accessEngine != nil {
+		syncInterval := time.Duration(m.config.AccessControl.SyncInterval) * time.Second
+		m.entitlementSyncer = access.NewSyncer(httpClient, db, syncInterval, m.logger)
+	}
+	
+	// Pull the member roster so device user IDs resolve to platform members
+	if m.config.RosterSync.Enabled {
+		rosterInterval := time.Duration(m.config.RosterSync.Interval) * time.Second
+		m.rosterSyncer = client.NewRosterSyncer(httpClient, db, rosterInterval, m.config.RosterSync.PageSize, m.logger)
+	}
+	
+	// Push the roster down to biometric terminals
+	if m.config.Provisioning.Enabled {
+		provisioningConfig := provisioning.Config{
+			Interval:         time.Duration(m.config.Provisioning.Interval) * time.Second,
+			InactiveAction:   m.config.Provisioning.InactiveAction,
+			DefaultPrivilege: m.config.Provisioning.DefaultPrivilege,
+		}
+		m.provisioner = provisioning.NewReconciler(provisioningConfig, &adapterTerminals{m.adapterManager}, db, m.logger)
+	}
+	
+	// Fingerprint templates are only stored with the site's consent; withdrawing it removes them
+	if m.config.TemplateBackup.Consent {
+		templateInterval := time.Duration(m.config.TemplateBackup.Interval) * time.Second
+		m.templateSyncer = provisioning.NewTemplateSyncer(templateInterval, &adapterTerminals{m.adapterManager}, db, m.logger)
+	} else if purged, err := db.PurgeBiometricTemplates(); err != nil {
+		m.logger.WithError(err).Warn("Failed to purge stored fingerprint templates")
+	} else if purged > 0 {
+		m.logger.WithField("templates", purged).Info("Template backup consent withdrawn, purged stored fingerprint templates")
+	}
 	m.submissionService = client.NewSubmissionService(m.queueManager, checkinClient, m.logger)
 	
+	// Receive remote commands over an outbound channel, as the bridge is usually behind NAT
+	if m.config.CommandChannel.Enabled {
+		if authManager.IsAuthenticated() {
+			maxAge := time.Duration(m.config.CommandChannel.MaxCommandAge) * time.Second
+			dispatcher := commands.NewDispatcher(authManager, db, maxAge, m.logger)
+			m.registerCommandHandlers(dispatcher)
+			
+			channelConfig := commands.DefaultChannelConfig()
+			channelConfig.ServerURL = m.config.ServerURL
+			m.commandChannel = commands.NewChannel(channelConfig, dispatcher, httpClient, authManager, m.logger)
+		} else {
+			m.logger.Warn("Device is not paired; remote commands are disabled")
+		}
+	}
+	
 	// Configure submission service based on tier
 	submissionConfig := client.DefaultSubmissionConfig()
 	switch database.PerformanceTier(m.config.Tier) {
@@ -280,18 +535,25 @@ func (m *Manager) initializeComponents() error {
 	}
 	m.submissionService.SetConfig(submissionConfig)
 	
+	// Download updates in the background and install them in the maintenance window
+	if m.config.UpdatesEnabled {
+		var reporter updater.UpdateReporter
+		if authManager.IsAuthenticated() {
+			reporter = httpClient
+		}
+		if err := m.initializeUpdater(reporter); err != nil {
+			m.logger.WithError(err).Warn("Self-updates are disabled")
+		}
+	}
+	
 	// Initialize installation telemetry
 	m.installationTelemetry = telemetry.NewInstallationTelemetry(m.logger, m.config)
 
 	// Initialize service health monitor on Windows
-	if runtime.GOOS == "windows" {
-		healthConfig := windows.DefaultServiceHealthMonitorConfig()
-		serviceHealthMonitor, err := windows.NewServiceHealthMonitor(m.logger, healthConfig)
-		if err != nil {
-			m.logger.WithError(err).Warn("Failed to initialize service health monitor")
-		} else {
-			m.serviceHealthMonitor = serviceHealthMonitor
-		}
+	if serviceHealthMonitor, err := newServiceMonitor(m.logger); err != nil {
+		m.logger.WithError(err).Warn("Failed to initialize service health monitor")
+	} else if serviceHealthMonitor != nil {
+		m.serviceHealthMonitor = serviceHealthMonitor
 	}
 
 	// Initialize API server if enabled
@@ -310,12 +572,12 @@ func (m *Manager) initializeComponents() error {
 		m.apiServer = api.NewServer(
 			m.config,
 			serverConfig,
-			&adapterRegistryWrapper{m.adapterManager},
-			&doorControllerWrapper{m.doorController},
+			m.adapterRegistryAPI(),
+			m.doorControllerAPI(),
 			&healthMonitorWrapper{m.healthMonitor},
 			&queueManagerWrapper{m.queueManager},
 			&tierDetectorWrapper{m.tierDetector},
-			&configManagerWrapper{m.config},
+			&configManagerWrapper{config: m.config, manager: m},
 			m.version,
 			m.deviceID,
 		)
@@ -364,6 +626,58 @@ func (m *Manager) Start(ctx context.Context) error {
 		m.logger.Info("Starting periodic event submission service")
 		m.submissionService.StartPeriodicSubmission(m.ctx)
 	}()
+	
+	// Start heartbeats; the first one is sent with retries, so don't wait for it
+	if m.heartbeatManager != nil {
+		go func() {
+			if err := m.heartbeatManager.Start(m.ctx); err != nil {
+				m.logger.WithError(err).Error("Failed to start heartbeat manager")
+			}
+		}()
+	}
+	
+	// Start applying configuration from the platform
+	if m.configSyncer != nil {
+		go m.configSyncer.Start(m.ctx)
+	}
+	
+	// Apply edits to the configuration file without a restart
+	m.watchConfigFile()
+	
+	// Start entitlement sync for offline access decisions
+	if m.entitlementSyncer != nil {
+		go m.entitlementSyncer.Start(m.ctx)
+	}
+	
+	// Start roster sync for external user mappings
+	if m.rosterSyncer != nil {
+		go m.rosterSyncer.Start(m.ctx)
+	}
+	
+	// Start provisioning members on biometric terminals
+	if m.provisioner != nil {
+		go m.provisioner.Start(m.ctx)
+	}
+	
+	// Start backing up fingerprint templates and sharing them between terminals
+	if m.templateSyncer != nil {
+		go m.templateSyncer.Start(m.ctx)
+	}
+	
+	// Start receiving remote commands
+	if m.commandChannel != nil {
+		go m.commandChannel.Start(m.ctx)
+	}
+	
+	// Start checking for updates; an update installed before this start is
+	// health checked against the API server first
+	if m.updater != nil {
+		go func() {
+			if err := m.updater.Start(m.ctx); err != nil && err != context.Canceled {
+				m.logger.WithError(err).Error("Updater stopped with error")
+			}
+		}()
+	}
 
 	// Start service health monitor if available
 	if m.serviceHealthMonitor != nil {
@@ -395,6 +709,7 @@ func (m *Manager) Start(ctx context.Context) error {
 	}
 	
 	m.isRunning = true
+	m.readyOnce.Do(func() { close(m.ready) })
 	m.logger.Info("Bridge manager started successfully")
 
 	// Log installation status on startup
@@ -444,6 +759,16 @@ func (m *Manager) shutdown() error {
 		}
 	}
 
+	// Stop heartbeats
+	if m.heartbeatManager != nil {
+		stopCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		if err := m.heartbeatManager.Stop(stopCtx); err != nil {
+			m.logger.WithError(err).Error("Failed to stop heartbeat manager")
+			errors = append(errors, fmt.Errorf("heartbeat manager stop: %w", err))
+		}
+		cancel()
+	}
+	
 	// Stop API server
 	if m.apiServer != nil {
 		if err := m.apiServer.Shutdown(); err != nil {
@@ -460,6 +785,22 @@ func (m *Manager) shutdown() error {
 		}
 	}
 	
+	// Release the door contact and exit inputs
+	for _, doorInput := range m.doorInputs {
+		if err := doorInput.Close(); err != nil {
+			m.logger.WithError(err).Error("Failed to close door input")
+			errors = append(errors, fmt.Errorf("door input close: %w", err))
+		}
+	}
+	
+	// Relock and release the door relays
+	for _, doorRelay := range m.doorRelays {
+		if err := doorRelay.Close(); err != nil {
+			m.logger.WithError(err).Error("Failed to close door relay")
+			errors = append(errors, fmt.Errorf("door relay close: %w", err))
+		}
+	}
+	
 	// Stop adapters
 	if m.adapterManager != nil {
 		if err := m.adapterManager.StopAll(); err != nil {
@@ -509,6 +850,38 @@ func (m *Manager) IsRunning() bool {
 	return m.isRunning
 }
 
+// Ready returns a channel that is closed once the bridge manager has started
+func (m *Manager) Ready() <-chan struct{} {
+	return m.ready
+}
+
+// CheckAlive returns an error when the bridge is not running or does not
+// respond before ctx is done: the manager lock must be free to take, the
+// health monitor must be able to query the queue and adapters, and the door
+// controller must answer. The systemd watchdog restarts a bridge failing it.
+func (m *Manager) CheckAlive(ctx context.Context) error {
+	done := make(chan error, 1)
+	go func() {
+		if !m.IsRunning() {
+			done <- fmt.Errorf("bridge manager is not running")
+			return
+		}
+		if err := m.healthMonitor.UpdateHealth(ctx); err != nil {
+			done <- fmt.Errorf("health check failed: %w", err)
+			return
+		}
+		m.doorController.ListDoors()
+		done <- nil
+	}()
+	
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("bridge did not respond: %w", ctx.Err())
+	}
+}
+
 // GetUptime returns the uptime of the bridge manager
 func (m *Manager) GetUptime() time.Duration {
 	m.mu.RLock()
@@ -578,6 +951,34 @@ func (m *Manager) GetStats() map[string]interface{} {
 			stats["processor"] = m.eventProcessor.GetStats()
 		}
 		
+		if m.accessEngine != nil {
+			stats["access"] = m.accessEngine.GetStats()
+		}
+		
+		if m.entitlementSyncer != nil {
+			stats["entitlementSync"] = m.entitlementSyncer.GetStats()
+		}
+		
+		if m.rosterSyncer != nil {
+			stats["rosterSync"] = m.rosterSyncer.GetStats()
+		}
+		
+		if m.provisioner != nil {
+			stats["provisioning"] = m.provisioner.GetStats()
+		}
+		
+		if m.templateSyncer != nil {
+			stats["templateSync"] = m.templateSyncer.GetStats()
+		}
+		
+		if m.commandChannel != nil {
+			stats["commandChannel"] = m.commandChannel.GetStatus()
+		}
+		
+		if m.configSyncer != nil {
+			stats["configSync"] = m.configSyncer.GetStats()
+		}
+		
 		if m.tierDetector != nil {
 			stats["tier"] = m.tierDetector.GetCurrentTier()
 			stats["resources"] = m.tierDetector.GetCurrentResources()
@@ -604,6 +1005,134 @@ type adapterRegistryWrapper struct {
 	manager *adapters.AdapterManager
 }
 
+// adapterRegistryAPI returns the adapter registry exposed to the API, with
+// terminal user provisioning and template backup when they are enabled
+func (m *Manager) adapterRegistryAPI() api.AdapterRegistry {
+	wrapper := &adapterRegistryWrapper{m.adapterManager}
+	switch {
+	case m.provisioner != nil && m.templateSyncer != nil:
+		return &provisioningTemplateRegistryWrapper{
+			&provisioningRegistryWrapper{wrapper, m.provisioner},
+			&templateSyncWrapper{m.templateSyncer},
+		}
+	case m.provisioner != nil:
+		return &provisioningRegistryWrapper{wrapper, m.provisioner}
+	case m.templateSyncer != nil:
+		return &templateRegistryWrapper{wrapper, &templateSyncWrapper{m.templateSyncer}}
+	}
+	return wrapper
+}
+
+// templateRegistryWrapper adds fingerprint template backup to the adapter registry
+type templateRegistryWrapper struct {
+	*adapterRegistryWrapper
+	*templateSyncWrapper
+}
+
+// provisioningTemplateRegistryWrapper adds both terminal user provisioning and
+// fingerprint template backup to the adapter registry
+type provisioningTemplateRegistryWrapper struct {
+	*provisioningRegistryWrapper
+	*templateSyncWrapper
+}
+
+// templateSyncWrapper adapts the template syncer to the API template methods
+type templateSyncWrapper struct {
+	syncer *provisioning.TemplateSyncer
+}
+
+func (w *templateSyncWrapper) SyncTemplates(ctx context.Context) (*api.TemplateSyncReport, error) {
+	report, err := w.syncer.Sync(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return convertTemplateReport(report), nil
+}
+
+func (w *templateSyncWrapper) RestoreTemplates(ctx context.Context, adapterName string) (*api.TemplateSyncReport, error) {
+	report, err := w.syncer.Restore(ctx, adapterName)
+	if err != nil {
+		return nil, err
+	}
+	return convertTemplateReport(report), nil
+}
+
+func convertTemplateReport(report *provisioning.TemplateReport) *api.TemplateSyncReport {
+	return &api.TemplateSyncReport{
+		StartedAt:   report.StartedAt,
+		CompletedAt: report.CompletedAt,
+		Terminals:   report.Terminals,
+		Stored:      report.Stored,
+		BackedUp:    report.BackedUp,
+		Written:     report.Written,
+		Skipped:     report.Skipped,
+		Failed:      report.Failed,
+		Errors:      report.Errors,
+	}
+}
+
+// provisioningRegistryWrapper adds terminal user provisioning to the adapter
+// registry exposed to the API
+type provisioningRegistryWrapper struct {
+	*adapterRegistryWrapper
+	reconciler *provisioning.Reconciler
+}
+
+func (w *provisioningRegistryWrapper) ReconcileUsers(ctx context.Context, dryRun bool) (*api.ProvisioningReport, error) {
+	report, err := w.reconciler.Reconcile(ctx, dryRun)
+	if err != nil {
+		return nil, err
+	}
+
+	actions := make([]api.ProvisioningAction, len(report.Actions))
+	for i, action := range report.Actions {
+		actions[i] = api.ProvisioningAction{
+			Terminal:       action.Terminal,
+			Type:           action.Type,
+			DeviceUserID:   action.DeviceUserID,
+			InternalUserID: action.InternalUserID,
+			Name:           action.Name,
+			Reason:         action.Reason,
+			Error:          action.Error,
+		}
+	}
+
+	return &api.ProvisioningReport{
+		DryRun:      report.DryRun,
+		StartedAt:   report.StartedAt,
+		CompletedAt: report.CompletedAt,
+		Members:     report.Members,
+		Terminals:   report.Terminals,
+		Actions:     actions,
+		Applied:     report.Applied,
+		Failed:      report.Failed,
+		Unmanaged:   report.Unmanaged,
+		Errors:      report.Errors,
+	}, nil
+}
+
+// adapterTerminals lists the adapters that can have users provisioned
+type adapterTerminals struct {
+	manager *adapters.AdapterManager
+}
+
+func (t *adapterTerminals) Terminals() []provisioning.Terminal {
+	adaptersMap := t.manager.GetAllAdapters()
+	names := make([]string, 0, len(adaptersMap))
+	for name := range adaptersMap {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var terminals []provisioning.Terminal
+	for _, name := range names {
+		if terminal, ok := adaptersMap[name].(provisioning.Terminal); ok {
+			terminals = append(terminals, terminal)
+		}
+	}
+	return terminals
+}
+
 func (w *adapterRegistryWrapper) GetAllAdapters() []adapters.HardwareAdapter {
 	adaptersMap := w.manager.GetAllAdapters()
 	result := make([]adapters.HardwareAdapter, 0, len(adaptersMap))
@@ -640,9 +1169,263 @@ func (w *adapterRegistryWrapper) GetAdapterStatus(name string) (types.AdapterSta
 	return adapter.GetStatus(), nil
 }
 
+// initializeDoors opens the relay of each configured door and mirrors the doors into
+// the database so events and the cloud can refer to them by ID
+func (m *Manager) initializeDoors(logger *slog.Logger) ([]door.Door, error) {
+	doorConfigs := m.config.GetDoorConfigs()
+	doors := make([]door.Door, 0, len(doorConfigs))
+	records := make([]database.Door, 0, len(doorConfigs))
+	
+	for _, doorConfig := range doorConfigs {
+		d := door.Door{
+			ID:               doorConfig.ID,
+			Name:             doorConfig.Name,
+			Zone:             doorConfig.Zone,
+			Adapters:         doorConfig.Adapters,
+			UnlockDurationMs: doorConfig.UnlockDuration,
+			RexUnlocks:       doorConfig.RexUnlocks,
+			HeldOpenTimeout:  time.Duration(doorConfig.HeldOpenTimeout) * time.Second,
+		}
+		
+		if len(doorConfig.Contact) > 0 {
+			contact, err := m.openDoorInput(doorConfig.Contact)
+			if err != nil {
+				return nil, fmt.Errorf("failed to open contact input for door %s: %w", doorConfig.ID, err)
+			}
+			d.Contact = contact
+		}
+		if len(doorConfig.Rex) > 0 {
+			rex, err := m.openDoorInput(doorConfig.Rex)
+			if err != nil {
+				return nil, fmt.Errorf("failed to open exit input for door %s: %w", doorConfig.ID, err)
+			}
+			d.Rex = rex
+		}
+		
+		if len(doorConfig.Relay) > 0 {
+			relayConfig, err := relay.FromSettings(doorConfig.Relay)
+			if err != nil {
+				return nil, fmt.Errorf("invalid relay configuration for door %s: %w", doorConfig.ID, err)
+			}
+			doorRelay, err := relay.New(relayConfig, logger.With("door", doorConfig.ID))
+			if err != nil {
+				return nil, fmt.Errorf("failed to open relay for door %s: %w", doorConfig.ID, err)
+			}
+			m.doorRelays = append(m.doorRelays, doorRelay)
+			d.Relay = doorRelay
+		}
+		
+		doors = append(doors, d)
+		records = append(records, database.Door{
+			ID:               doorConfig.ID,
+			Name:             doorConfig.Name,
+			Zone:             doorConfig.Zone,
+			Adapters:         doorConfig.Adapters,
+			Relay:            doorConfig.Relay,
+			UnlockDurationMs: doorConfig.UnlockDuration,
+		})
+	}
+	
+	if err := m.database.ReplaceDoors(records); err != nil {
+		return nil, fmt.Errorf("failed to store doors: %w", err)
+	}
+	
+	m.logger.WithField("doors", len(doors)).Info("Doors configured")
+	return doors, nil
+}
+
+// openDoorInput opens a door contact or exit button input and keeps it for shutdown
+func (m *Manager) openDoorInput(settings map[string]interface{}) (input.Input, error) {
+	inputConfig, err := input.FromSettings(settings)
+	if err != nil {
+		return nil, err
+	}
+	doorInput, err := input.Open(inputConfig)
+	if err != nil {
+		return nil, err
+	}
+	m.doorInputs = append(m.doorInputs, doorInput)
+	return doorInput, nil
+}
+
+// initializeUpdater creates the self-updater. Updates are snapshotted with the
+// event queue, installed through the service manager and rolled back when the
+// local API reports the bridge unhealthy after the restart.
+func (m *Manager) initializeUpdater(reporter updater.UpdateReporter) error {
+	factory := updater.NewFactory(m.logger.WithField("component", "updater").Logger)
+	
+	healthChecker, err := factory.CreateHealthChecker(m.config)
+	if err != nil {
+		return fmt.Errorf("failed to create update health checker: %w", err)
+	}
+	queuePreserver, err := factory.CreateQueuePreserver(m.config)
+	if err != nil {
+		return fmt.Errorf("failed to create queue preserver: %w", err)
+	}
+	
+	opts := []updater.UpdaterOption{
+		updater.WithCurrentVersion(m.version),
+		updater.WithInstallGate(m.updateInstallGate),
+		updater.WithQueuePreserver(queuePreserver),
+		updater.WithHealthChecker(healthChecker),
+		updater.WithRestarter(updater.NewServiceRestarter()),
+	}
+	if reporter != nil {
+		opts = append(opts, updater.WithUpdateReporter(reporter))
+	}
+	
+	u, err := factory.CreateUpdater(m.config, opts...)
+	if err != nil {
+		return err
+	}
+	m.updater = u
+	return nil
+}
+
+// updateInstallGate blocks installing an update, and the restart that follows,
+// while a door is unlocked
+func (m *Manager) updateInstallGate() error {
+	for _, status := range m.doorController.ListDoors() {
+		if status.Unlocked {
+			return fmt.Errorf("door %s is unlocked", status.ID)
+		}
+	}
+	return nil
+}
+
+// handlePendingCommands fetches commands the platform reports as waiting, in
+// case the command channel is between reconnects
+func (m *Manager) handlePendingCommands(count int) {
+	if m.commandChannel == nil {
+		m.logger.WithField("count", count).Warn("Platform has pending commands but the command channel is disabled")
+		return
+	}
+	m.commandChannel.FetchPending(m.ctx)
+}
+
+// handleUpdateAvailable checks for the update the platform announced without
+// waiting for the update check interval
+func (m *Manager) handleUpdateAvailable() {
+	if m.updater == nil {
+		return
+	}
+	m.updater.CheckNow()
+}
+
+// handleDoorAlarm raises door alarms in the monitoring system, pushes them to
+// WebSocket clients and queues them as events for the platform
+func (m *Manager) handleDoorAlarm(alarm door.DoorAlarm) {
+	if m.apiServer != nil {
+		m.apiServer.BroadcastEvent("door_alarm", alarm)
+	}
+	
+	if alarm.Cleared {
+		m.monitoringSystem.ResolveDoorAlarms(m.ctx, alarm.DoorID)
+		return
+	}
+	
+	alertType := monitoring.AlertTypeDoorHeldOpen
+	if alarm.Type == types.EventTypeDoorForcedOpen {
+		alertType = monitoring.AlertTypeDoorForcedOpen
+	}
+	metadata := map[string]interface{}{
+		"zone":      alarm.Zone,
+		"opened_at": alarm.OpenedAt,
+		"open_for":  alarm.OpenFor.String(),
+	}
+	if err := m.monitoringSystem.RaiseDoorAlarm(m.ctx, alertType, alarm.DoorID, alarm.DoorName, metadata); err != nil {
+		m.logger.WithError(err).Error("Failed to raise door alarm")
+	}
+	
+	event := types.RawHardwareEvent{
+		Timestamp: alarm.Timestamp,
+		EventType: alarm.Type,
+		DoorID:    alarm.DoorID,
+		RawData: map[string]interface{}{
+			"zone":      alarm.Zone,
+			"openedAt":  alarm.OpenedAt,
+			"openForMs": alarm.OpenFor.Milliseconds(),
+		},
+	}
+	result, err := m.eventProcessor.ProcessEvent(m.ctx, event)
+	if err != nil {
+		m.logger.WithError(err).Error("Failed to process door alarm")
+		return
+	}
+	if !result.Processed {
+		m.logger.WithFields(logrus.Fields{
+			"door_id":    alarm.DoorID,
+			"event_type": alarm.Type,
+			"reason":     result.Reason,
+		}).Warn("Door alarm not processed")
+		return
+	}
+	if err := m.queueManager.Enqueue(m.ctx, result.Event); err != nil {
+		m.logger.WithError(err).Error("Failed to enqueue door alarm")
+	}
+}
+
+// doorControllerAPI returns the door controller adapted for the API server. With
+// local access control enabled it also exposes anti-passback state.
+func (m *Manager) doorControllerAPI() api.DoorController {
+	wrapper := &doorControllerWrapper{m.doorController, m.database}
+	if m.accessEngine != nil {
+		return &accessControllerWrapper{wrapper, m.accessEngine}
+	}
+	return wrapper
+}
+
+// accessControllerWrapper adds the access engine's anti-passback state to the
+// door controller exposed to the API
+type accessControllerWrapper struct {
+	*doorControllerWrapper
+	engine *access.Engine
+}
+
+func (w *accessControllerWrapper) GetAntiPassbackStates(internalUserID string) ([]api.AntiPassbackState, error) {
+	statuses, err := w.engine.AntiPassbackStates(internalUserID)
+	if err != nil {
+		return nil, err
+	}
+	states := make([]api.AntiPassbackState, len(statuses))
+	for i, status := range statuses {
+		states[i] = api.AntiPassbackState{
+			Zone:      status.Zone,
+			State:     status.State,
+			DoorID:    status.DoorID,
+			UpdatedAt: status.UpdatedAt,
+		}
+	}
+	return states, nil
+}
+
+func (w *accessControllerWrapper) ResetAntiPassback(ctx context.Context, internalUserID string) (int64, error) {
+	return w.engine.ResetAntiPassback(internalUserID)
+}
+
+// doorHeartbeatStates reports door states in heartbeats
+type doorHeartbeatStates struct {
+	controller *door.DoorController
+}
+
+func (w *doorHeartbeatStates) GetDoorStates() []client.DoorHeartbeat {
+	statuses := w.controller.ListDoors()
+	states := make([]client.DoorHeartbeat, len(statuses))
+	for i, status := range statuses {
+		states[i] = client.DoorHeartbeat{
+			ID:         status.ID,
+			State:      status.State,
+			Unlocked:   status.Unlocked,
+			FreeAccess: status.FreeAccess,
+		}
+	}
+	return states
+}
+
 // doorControllerWrapper adapts DoorController to API DoorController interface
 type doorControllerWrapper struct {
 	controller *door.DoorController
+	database   *database.DB
 }
 
 func (w *doorControllerWrapper) UnlockDoor(ctx context.Context, adapterName string, durationMs int) error {
@@ -653,6 +1436,197 @@ func (w *doorControllerWrapper) GetStats() map[string]interface{} {
 	return w.controller.GetStats()
 }
 
+func (w *doorControllerWrapper) ListDoors() []api.DoorInfo {
+	statuses := w.controller.ListDoors()
+	doors := make([]api.DoorInfo, len(statuses))
+	for i, status := range statuses {
+		doors[i] = convertDoorStatus(status)
+	}
+	return doors
+}
+
+func (w *doorControllerWrapper) GetDoor(doorID string) *api.DoorInfo {
+	status := w.controller.GetDoorStatus(doorID)
+	if status == nil {
+		return nil
+	}
+	info := convertDoorStatus(*status)
+	return &info
+}
+
+func (w *doorControllerWrapper) UnlockDoorByID(ctx context.Context, doorID string, durationMs int) error {
+	return w.controller.UnlockDoorByID(ctx, doorID, durationMs)
+}
+
+func (w *doorControllerWrapper) LockDoorByID(ctx context.Context, doorID string) error {
+	return w.controller.LockDoorByID(ctx, doorID)
+}
+
+func (w *doorControllerWrapper) ListSchedules() ([]api.DoorSchedule, error) {
+	records, err := w.database.ListDoorSchedules()
+	if err != nil {
+		return nil, err
+	}
+	schedules := make([]api.DoorSchedule, 0, len(records))
+	for _, record := range records {
+		windows, err := access.ParseAllowedHours(record.Windows)
+		if err != nil {
+			return nil, fmt.Errorf("schedule %s: %w", record.ID, err)
+		}
+		schedules = append(schedules, api.DoorSchedule{
+			ID:        record.ID,
+			Name:      record.Name,
+			DoorIDs:   record.DoorIDs,
+			Windows:   windows,
+			Enabled:   record.Enabled,
+			CreatedAt: record.CreatedAt,
+			UpdatedAt: record.UpdatedAt,
+		})
+	}
+	return schedules, nil
+}
+
+func (w *doorControllerWrapper) UpsertSchedule(ctx context.Context, schedule api.DoorSchedule) (*api.DoorSchedule, error) {
+	windows, err := json.Marshal(schedule.Windows)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal schedule windows: %w", err)
+	}
+	record := &database.DoorSchedule{
+		ID:      schedule.ID,
+		Name:    schedule.Name,
+		DoorIDs: schedule.DoorIDs,
+		Windows: string(windows),
+		Enabled: schedule.Enabled,
+	}
+	if err := w.database.UpsertDoorSchedule(record); err != nil {
+		return nil, err
+	}
+	if err := w.controller.ReloadSchedules(ctx); err != nil {
+		return nil, err
+	}
+
+	saved, err := w.database.GetDoorSchedule(schedule.ID)
+	if err != nil || saved == nil {
+		return &schedule, err
+	}
+	schedule.CreatedAt = saved.CreatedAt
+	schedule.UpdatedAt = saved.UpdatedAt
+	return &schedule, nil
+}
+
+func (w *doorControllerWrapper) DeleteSchedule(ctx context.Context, id string) (bool, error) {
+	deleted, err := w.database.DeleteDoorSchedule(id)
+	if err != nil || !deleted {
+		return deleted, err
+	}
+	return true, w.controller.ReloadSchedules(ctx)
+}
+
+func (w *doorControllerWrapper) ListHolidays() ([]api.DoorHoliday, error) {
+	records, err := w.database.ListDoorHolidays()
+	if err != nil {
+		return nil, err
+	}
+	holidays := make([]api.DoorHoliday, 0, len(records))
+	for _, record := range records {
+		holidays = append(holidays, convertDoorHoliday(record))
+	}
+	return holidays, nil
+}
+
+func (w *doorControllerWrapper) UpsertHoliday(ctx context.Context, holiday api.DoorHoliday) (*api.DoorHoliday, error) {
+	record := database.DoorHoliday{
+		ID:        holiday.ID,
+		Name:      holiday.Name,
+		Date:      holiday.Date,
+		StartTime: holiday.Start,
+		EndTime:   holiday.End,
+	}
+	if err := w.database.UpsertDoorHoliday(&record); err != nil {
+		return nil, err
+	}
+	if err := w.controller.ReloadSchedules(ctx); err != nil {
+		return nil, err
+	}
+	return &holiday, nil
+}
+
+func (w *doorControllerWrapper) DeleteHoliday(ctx context.Context, id string) (bool, error) {
+	deleted, err := w.database.DeleteDoorHoliday(id)
+	if err != nil || !deleted {
+		return deleted, err
+	}
+	return true, w.controller.ReloadSchedules(ctx)
+}
+
+func (w *doorControllerWrapper) GetLockdown() *api.DoorLockdown {
+	lockdown := w.controller.Lockdown()
+	if lockdown == nil {
+		return nil
+	}
+	return &api.DoorLockdown{
+		Reason:      lockdown.Reason,
+		ActivatedBy: lockdown.ActivatedBy,
+		ActivatedAt: lockdown.ActivatedAt,
+	}
+}
+
+func (w *doorControllerWrapper) SetLockdown(ctx context.Context, reason, activatedBy string) (*api.DoorLockdown, error) {
+	lockdown := &database.DoorLockdown{
+		Reason:      reason,
+		ActivatedBy: activatedBy,
+		ActivatedAt: time.Now().UTC(),
+	}
+	if err := w.database.SetDoorLockdown(lockdown); err != nil {
+		return nil, err
+	}
+	if err := w.controller.ReloadSchedules(ctx); err != nil {
+		return nil, err
+	}
+	return w.GetLockdown(), nil
+}
+
+func (w *doorControllerWrapper) ClearLockdown(ctx context.Context) error {
+	if err := w.database.ClearDoorLockdown(); err != nil {
+		return err
+	}
+	return w.controller.ReloadSchedules(ctx)
+}
+
+// convertDoorHoliday converts a stored holiday to the API representation
+func convertDoorHoliday(record database.DoorHoliday) api.DoorHoliday {
+	return api.DoorHoliday{
+		ID:        record.ID,
+		Name:      record.Name,
+		Date:      record.Date,
+		Start:     record.StartTime,
+		End:       record.EndTime,
+		CreatedAt: record.CreatedAt,
+		UpdatedAt: record.UpdatedAt,
+	}
+}
+
+// convertDoorStatus converts a door status to the API representation
+func convertDoorStatus(status door.DoorStatus) api.DoorInfo {
+	info := api.DoorInfo{
+		ID:           status.ID,
+		Name:         status.Name,
+		Zone:         status.Zone,
+		Adapters:     status.Adapters,
+		IsLocked:     !status.Unlocked,
+		Position:     status.State,
+		FreeAccess:   status.FreeAccess,
+		UnlockCount:  status.UnlockCount,
+		FailureCount: status.FailureCount,
+		Relay:        status.Relay,
+	}
+	if !status.LastUnlockTime.IsZero() {
+		lastUnlockTime := status.LastUnlockTime
+		info.LastUnlockTime = &lastUnlockTime
+	}
+	return info
+}
+
 // healthMonitorWrapper adapts HealthMonitor to API HealthMonitor interface
 type healthMonitorWrapper struct {
 	monitor *health.HealthMonitor
@@ -700,7 +1674,8 @@ func (w *healthMonitorWrapper) UpdateHealth(ctx context.Context) error {
 
 // configManagerWrapper adapts Config to ConfigManager interface
 type configManagerWrapper struct {
-	config *config.Config
+	config  *config.Config
+	manager *Manager
 }
 
 func (w *configManagerWrapper) GetCurrentConfig() *config.Config {
@@ -753,18 +1728,26 @@ func (w *configManagerWrapper) UpdateConfig(updates *api.ConfigUpdateRequest) (*
 		updatedFields = append(updatedFields, "logFile")
 	}
 	
-	// Update enabled adapters
-	if updates.EnabledAdapters != nil {
-		w.config.EnabledAdapters = updates.EnabledAdapters
-		updatedFields = append(updatedFields, "enabledAdapters")
-		requiresRestart = true
-	}
-	
-	// Update adapter configs
-	if updates.AdapterConfigs != nil {
-		w.config.AdapterConfigs = updates.AdapterConfigs
-		updatedFields = append(updatedFields, "adapterConfigs")
-		requiresRestart = true
+	// Update adapters; running adapters are reconciled against the change
+	if updates.EnabledAdapters != nil || updates.AdapterConfigs != nil {
+		target := *w.config
+		if updates.EnabledAdapters != nil {
+			target.EnabledAdapters = updates.EnabledAdapters
+			updatedFields = append(updatedFields, "enabledAdapters")
+		}
+		if updates.AdapterConfigs != nil {
+			target.AdapterConfigs = updates.AdapterConfigs
+			updatedFields = append(updatedFields, "adapterConfigs")
+		}
+		
+		applier := &configApplier{manager: w.manager, source: reconcileSourceAPI}
+		result, err := applier.Apply(w.manager.ctx, target, nil, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to apply adapter configuration: %w", err)
+		}
+		if len(result.RestartRequired) > 0 {
+			requiresRestart = true
+		}
 	}
 	
 	// Update updates enabled
@@ -808,13 +1791,35 @@ func (w *configManagerWrapper) UpdateConfig(updates *api.ConfigUpdateRequest) (*
 }
 
 func (w *configManagerWrapper) ReloadConfig(force bool) (*api.ConfigReloadResponse, error) {
-	// For now, we'll just return success without actually reloading
-	// In a full implementation, this would reload the config from file
+	result, err := w.manager.reloadConfigFile(w.manager.ctx, reconcileSourceAPI)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reload configuration: %w", err)
+	}
+	
+	changedFields := []string{}
+	for _, name := range result.ChangedAdapters {
+		changedFields = append(changedFields, "adapters."+name)
+	}
+	for _, name := range result.RemovedAdapters {
+		changedFields = append(changedFields, "adapters."+name)
+	}
+	changedFields = append(changedFields, result.RestartRequired...)
+	
+	message := "Configuration reloaded successfully"
+	if len(result.RestartRequired) > 0 {
+		message = fmt.Sprintf("Configuration reloaded; a restart is required to apply %s", strings.Join(result.RestartRequired, ", "))
+	}
+	
+	reloadedFrom := w.config.FileUsed()
+	if reloadedFrom == "" {
+		reloadedFrom = w.manager.configFile
+	}
+	
 	return &api.ConfigReloadResponse{
 		Success:       true,
-		Message:       "Configuration reloaded successfully",
-		ReloadedFrom:  "config.yaml",
-		ChangedFields: []string{},
+		Message:       message,
+		ReloadedFrom:  reloadedFrom,
+		ChangedFields: changedFields,
 		Timestamp:     time.Now(),
 	}, nil
 }
@@ -0,0 +1,131 @@
+package bridge
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+
+	"gym-door-bridge/internal/adapters"
+	"gym-door-bridge/internal/api"
+	"gym-door-bridge/internal/config"
+	"gym-door-bridge/internal/configsync"
+)
+
+// Sources of adapter reconciles, recorded in the audit log
+const (
+	reconcileSourceFile     = "config_file"
+	reconcileSourceCommand  = "remote_command"
+	reconcileSourceAPI      = "local_api"
+	reconcileSourcePlatform = "platform_config"
+	reconcileSourceSignal   = "reload_signal"
+)
+
+// reconcileAdapters brings the running adapters in line with cfg and records
+// an audit entry listing what changed
+func (m *Manager) reconcileAdapters(source string, cfg *config.Config) (*adapters.ReconcileResult, error) {
+	result, err := m.adapterManager.Reconcile(cfg.GetAdapterConfigs())
+
+	event := api.AuditEvent{
+		EventType: api.AuditEventConfigReload,
+		Severity:  api.AuditSeverityMedium,
+		Resource:  "adapters",
+		Action:    "reconcile",
+		Result:    "success",
+		Message:   fmt.Sprintf("Adapters reconciled from %s", source),
+		Details: map[string]interface{}{
+			"source":    source,
+			"added":     result.Added,
+			"removed":   result.Removed,
+			"restarted": result.Restarted,
+			"unchanged": result.Unchanged,
+		},
+	}
+	if err != nil {
+		event.Severity = api.AuditSeverityHigh
+		event.Result = "failure"
+		event.Details["failed"] = result.Failed
+	}
+	m.auditLogger.LogEvent(event)
+
+	return result, err
+}
+
+// reloadConfigFile re-reads the configuration file and applies its adapters,
+// doors and schedules to the running bridge. A platform config version in
+// effect still takes precedence over the file.
+func (m *Manager) reloadConfigFile(ctx context.Context, source string) (*configsync.ApplyResult, error) {
+	cfg, err := config.Load(m.configFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	if m.config.ConfigSync.Enabled {
+		if _, err := configsync.Restore(m.database, cfg); err != nil {
+			m.logger.WithError(err).Warn("Failed to overlay platform configuration on the reloaded file")
+		}
+	}
+
+	applier := &configApplier{manager: m, source: source}
+	result, err := applier.Apply(ctx, *cfg, nil, nil)
+	if err != nil {
+		return result, err
+	}
+
+	if err := m.doorController.ReloadSchedules(ctx); err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+// ReloadConfig re-reads the configuration file and applies it to the running
+// bridge, for example when the service manager asks for a reload
+func (m *Manager) ReloadConfig(ctx context.Context) error {
+	result, err := m.reloadConfigFile(ctx, reconcileSourceSignal)
+	if err != nil {
+		return err
+	}
+	m.logger.WithFields(logrus.Fields{
+		"changed_adapters": result.ChangedAdapters,
+		"removed_adapters": result.RemovedAdapters,
+		"restart_required": result.RestartRequired,
+	}).Info("Configuration reloaded")
+	return nil
+}
+
+// watchConfigFile applies edits to the configuration file without a restart
+func (m *Manager) watchConfigFile() {
+	configFile := m.config.FileUsed()
+	if configFile == "" {
+		configFile = m.configFile
+	}
+	if configFile == "" {
+		return
+	}
+
+	onChange := func() {
+		result, err := m.reloadConfigFile(m.ctx, reconcileSourceFile)
+		if err != nil {
+			m.logger.WithError(err).Warn("Failed to apply configuration file change")
+			return
+		}
+		m.logger.WithFields(logrus.Fields{
+			"changed_adapters": result.ChangedAdapters,
+			"removed_adapters": result.RemovedAdapters,
+			"restart_required": result.RestartRequired,
+		}).Info("Configuration file change applied")
+	}
+	onError := func(err error) {
+		m.logger.WithError(err).Warn("Configuration file watcher error")
+	}
+
+	if err := config.Watch(m.ctx, configFile, onChange, onError); err != nil {
+		m.logger.WithError(err).Warn("Configuration file changes require a restart")
+		return
+	}
+	m.logger.WithField("file", configFile).Info("Watching configuration file for changes")
+}
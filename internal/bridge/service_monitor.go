@@ -0,0 +1,8 @@
+package bridge
+
+// serviceMonitor watches the operating system service the bridge runs under
+type serviceMonitor interface {
+	Start() error
+	Stop() error
+	GetHealthSummary() map[string]interface{}
+}
@@ -0,0 +1,10 @@
+//go:build !windows
+
+package bridge
+
+import "github.com/sirupsen/logrus"
+
+// newServiceMonitor returns no monitor; service health is only watched on Windows
+func newServiceMonitor(logger *logrus.Logger) (serviceMonitor, error) {
+	return nil, nil
+}
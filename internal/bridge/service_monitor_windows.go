@@ -0,0 +1,16 @@
+package bridge
+
+import (
+	"gym-door-bridge/internal/service/windows"
+
+	"github.com/sirupsen/logrus"
+)
+
+// newServiceMonitor creates the Windows service health monitor
+func newServiceMonitor(logger *logrus.Logger) (serviceMonitor, error) {
+	monitor, err := windows.NewServiceHealthMonitor(logger, windows.DefaultServiceHealthMonitorConfig())
+	if err != nil {
+		return nil, err
+	}
+	return monitor, nil
+}
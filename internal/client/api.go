@@ -3,8 +3,12 @@ package client
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
+	"time"
 )
 
 // PairRequest represents a device pairing request
@@ -43,6 +47,7 @@ type CheckinEvent struct {
 	EventType      string `json:"eventType"`
 	IsSimulated    bool   `json:"isSimulated"`
 	DeviceID       string `json:"deviceId"`
+	DoorID         string `json:"doorId,omitempty"`
 }
 
 // CheckinRequest represents a batch of check-in events
@@ -50,13 +55,72 @@ type CheckinRequest struct {
 	Events []CheckinEvent `json:"events"`
 }
 
+// CheckinEventV2 represents a check-in event in the version 2 schema, which
+// adds what the bridge resolved and observed locally to the version 1 fields
+type CheckinEventV2 struct {
+	CheckinEvent
+	InternalUserID string                 `json:"internalUserId,omitempty"`
+	Adapter        string                 `json:"adapter,omitempty"`
+	VerifyMode     string                 `json:"verifyMode,omitempty"`
+	AccessReason   string                 `json:"accessReason,omitempty"`
+	RawData        map[string]interface{} `json:"rawData,omitempty"`
+}
+
+// CheckinRequestV2 represents a batch of check-in events in the version 2 schema
+type CheckinRequestV2 struct {
+	SchemaVersion int              `json:"schemaVersion"`
+	Events        []CheckinEventV2 `json:"events"`
+}
+
 // HeartbeatRequest represents a device heartbeat
 type HeartbeatRequest struct {
-	Status        string      `json:"status"`
-	Tier          string      `json:"tier"`
-	QueueDepth    int         `json:"queueDepth"`
-	LastEventTime string      `json:"lastEventTime,omitempty"`
-	SystemInfo    *SystemInfo `json:"systemInfo,omitempty"`
+	Status         string          `json:"status"`
+	Tier           string          `json:"tier"`
+	QueueDepth     int             `json:"queueDepth"`
+	LastEventTime  string          `json:"lastEventTime,omitempty"`
+	SystemInfo     *SystemInfo     `json:"systemInfo,omitempty"`
+	QueueEvictions []QueueEviction `json:"queueEvictions,omitempty"` // Events lost to a full queue since the last heartbeat
+	Version          string             `json:"version,omitempty"`
+	Adapters         []AdapterHeartbeat `json:"adapters,omitempty"`
+	Doors            []DoorHeartbeat    `json:"doors,omitempty"`
+	OldestPendingAge int64              `json:"oldestPendingAge,omitempty"` // Seconds since the oldest unsent event
+	ClockOffsetMs    *int64             `json:"clockOffsetMs,omitempty"`    // Platform clock minus bridge clock, measured on the previous heartbeat
+	ConfigVersion    int64              `json:"configVersion,omitempty"`    // Platform config version in effect, 0 when running on local configuration
+}
+
+// AdapterHeartbeat reports the status of a hardware adapter
+type AdapterHeartbeat struct {
+	Name      string `json:"name"`
+	Status    string `json:"status"` // "active", "error", "disabled" or "initializing"
+	LastEvent string `json:"lastEvent,omitempty"` // RFC3339 timestamp
+	Error     string `json:"error,omitempty"`
+}
+
+// DoorHeartbeat reports the state of a door
+type DoorHeartbeat struct {
+	ID         string `json:"id"`
+	State      string `json:"state"` // Door position: "closed", "open", "held_open", "forced_open" or "unknown"
+	Unlocked   bool   `json:"unlocked"`
+	FreeAccess bool   `json:"freeAccess,omitempty"` // Held unlocked by a schedule
+}
+
+// HeartbeatResponse is the optional reply to a heartbeat. Fields the platform
+// does not send are left at their zero value.
+type HeartbeatResponse struct {
+	DesiredConfigVersion int64  `json:"desiredConfigVersion,omitempty"` // Config version the bridge should be running
+	PendingCommands      int    `json:"pendingCommands,omitempty"`      // Commands waiting to be delivered to the bridge
+	UpdateAvailable      bool   `json:"updateAvailable,omitempty"`      // A bridge release is available
+	BackoffSeconds       int    `json:"backoffSeconds,omitempty"`       // Wait at least this long before the next heartbeat
+	ServerTime           string `json:"serverTime,omitempty"`           // RFC3339 timestamp when the platform handled the heartbeat
+}
+
+// QueueEviction reports how many unsent events of a priority class were
+// evicted from the full offline queue, and the time range they covered
+type QueueEviction struct {
+	PriorityClass string `json:"priorityClass"` // "security", "denied", "access" or "simulated"
+	EventCount    int    `json:"eventCount"`
+	OldestEvent   string `json:"oldestEvent"` // RFC3339 timestamp
+	NewestEvent   string `json:"newestEvent"` // RFC3339 timestamp
 }
 
 // SystemInfo contains system resource information
@@ -116,8 +180,8 @@ func (c *HTTPClient) SubmitCheckinEvents(ctx context.Context, events []CheckinEv
 	return nil
 }
 
-// SendHeartbeat sends a heartbeat to the cloud
-func (c *HTTPClient) SendHeartbeat(ctx context.Context, heartbeat *HeartbeatRequest) error {
+// SendHeartbeat sends a heartbeat to the cloud and returns the platform's reply
+func (c *HTTPClient) SendHeartbeat(ctx context.Context, heartbeat *HeartbeatRequest) (*HeartbeatResponse, error) {
 	req := &Request{
 		Method: http.MethodPost,
 		Path:   "/api/v1/devices/heartbeat",
@@ -125,13 +189,27 @@ func (c *HTTPClient) SendHeartbeat(ctx context.Context, heartbeat *HeartbeatRequ
 		RequireAuth: true,
 	}
 
-	_, err := c.Do(ctx, req)
+	resp, err := c.Do(ctx, req)
 	if err != nil {
-		return fmt.Errorf("heartbeat failed: %w", err)
+		return nil, fmt.Errorf("heartbeat failed: %w", err)
+	}
+
+	var heartbeatResp HeartbeatResponse
+	if len(resp.Body) > 0 {
+		if err := json.Unmarshal(resp.Body, &heartbeatResp); err != nil {
+			// The heartbeat was delivered; only the optional reply is unusable
+			c.logger.WithError(err).Warn("Failed to parse heartbeat response")
+		}
+	}
+	// Platforms that don't send their time still send a Date header
+	if heartbeatResp.ServerTime == "" {
+		if date, err := http.ParseTime(resp.Headers.Get("Date")); err == nil {
+			heartbeatResp.ServerTime = date.UTC().Format(time.RFC3339)
+		}
 	}
 
 	c.logger.Debug("Heartbeat sent successfully")
-	return nil
+	return &heartbeatResp, nil
 }
 
 // OpenDoor sends a door open command (for remote door control)
@@ -264,4 +342,284 @@ func (c *HTTPClient) SubmitEvents(ctx context.Context, events []CheckinEvent) er
 
 	c.logger.Info("Events submitted successfully", "count", len(events))
 	return nil
-}
\ No newline at end of file
+}
+// MemberEntitlement represents a member's access entitlement as published by the cloud
+type MemberEntitlement struct {
+	InternalUserID      string         `json:"internalUserId"`
+	Status              string         `json:"status"` // "active", "suspended", "expired", "cancelled"
+	MembershipExpiresAt string         `json:"membershipExpiresAt,omitempty"` // RFC3339 timestamp
+	AllowedHours        []AccessWindow `json:"allowedHours,omitempty"`        // Empty means any time
+}
+
+// AccessWindow represents a weekly window during which a member may enter
+type AccessWindow struct {
+	Days  []int  `json:"days"`  // 0 = Sunday ... 6 = Saturday
+	Start string `json:"start"` // "HH:MM"
+	End   string `json:"end"`   // "HH:MM"
+}
+
+// MemberEntitlementsResponse represents the full entitlement snapshot for the device
+type MemberEntitlementsResponse struct {
+	Entitlements []MemberEntitlement `json:"entitlements"`
+	GeneratedAt  string              `json:"generatedAt,omitempty"`
+}
+
+// GetMemberEntitlements retrieves the member entitlement snapshot for offline access decisions
+func (c *HTTPClient) GetMemberEntitlements(ctx context.Context) (*MemberEntitlementsResponse, error) {
+	req := &Request{
+		Method:      http.MethodGet,
+		Path:        "/api/v1/devices/entitlements",
+		RequireAuth: true,
+	}
+
+	resp, err := c.Do(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("entitlement retrieval failed: %w", err)
+	}
+
+	var entitlements MemberEntitlementsResponse
+	if err := json.Unmarshal(resp.Body, &entitlements); err != nil {
+		return nil, fmt.Errorf("failed to parse entitlements response: %w", err)
+	}
+
+	c.logger.WithField("count", len(entitlements.Entitlements)).Debug("Member entitlements retrieved successfully")
+	return &entitlements, nil
+}
+
+// CommandEnvelope is a command signed by the platform. The signature is
+// HMAC-SHA256(payload + timestamp + deviceId) with the device key, the same scheme
+// the bridge uses to sign its own requests.
+type CommandEnvelope struct {
+	Payload   json.RawMessage `json:"payload"`
+	Signature string          `json:"signature"`
+	Timestamp int64           `json:"timestamp"` // Unix seconds when the command was signed
+}
+
+// CommandAck acknowledges a command with its outcome
+type CommandAck struct {
+	CommandID   string          `json:"commandId"`
+	Status      string          `json:"status"` // "succeeded", "failed", "rejected"
+	Result      json.RawMessage `json:"result,omitempty"`
+	Error       string          `json:"error,omitempty"`
+	Duplicate   bool            `json:"duplicate,omitempty"` // The command was already processed; the original outcome is repeated
+	Retryable   bool            `json:"retryable,omitempty"` // The command was rejected without running and may be delivered again
+	CompletedAt string          `json:"completedAt"`         // RFC3339 timestamp
+}
+
+// PollCommandsResponse represents commands waiting for the device
+type PollCommandsResponse struct {
+	Commands []CommandEnvelope `json:"commands"`
+}
+
+// PollCommands waits up to the given duration for commands addressed to the device.
+// It is the fallback used when the command WebSocket cannot be established.
+func (c *HTTPClient) PollCommands(ctx context.Context, wait time.Duration) ([]CommandEnvelope, error) {
+	req := &Request{
+		Method:      http.MethodGet,
+		Path:        fmt.Sprintf("/api/v1/devices/commands?wait=%d", int(wait.Seconds())),
+		RequireAuth: true,
+	}
+
+	resp, err := c.Do(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("command poll failed: %w", err)
+	}
+	if resp.StatusCode == http.StatusNoContent || len(resp.Body) == 0 {
+		return nil, nil
+	}
+
+	var commands PollCommandsResponse
+	if err := json.Unmarshal(resp.Body, &commands); err != nil {
+		return nil, fmt.Errorf("failed to parse commands response: %w", err)
+	}
+
+	return commands.Commands, nil
+}
+
+// AckCommand reports the outcome of a command received by polling
+func (c *HTTPClient) AckCommand(ctx context.Context, ack *CommandAck) error {
+	if ack == nil || ack.CommandID == "" {
+		return fmt.Errorf("command ID is required")
+	}
+
+	req := &Request{
+		Method:      http.MethodPost,
+		Path:        "/api/v1/devices/commands/" + url.PathEscape(ack.CommandID) + "/ack",
+		Body:        ack,
+		RequireAuth: true,
+	}
+
+	if _, err := c.Do(ctx, req); err != nil {
+		return fmt.Errorf("command acknowledgement failed: %w", err)
+	}
+
+	return nil
+}
+
+// Config version outcomes reported to the platform
+const (
+	ConfigStatusApplied    = "applied"
+	ConfigStatusInvalid    = "invalid"
+	ConfigStatusRolledBack = "rolled_back"
+	ConfigStatusFailed     = "failed"
+)
+
+// ConfigFieldError describes a field of a config document that failed validation
+type ConfigFieldError struct {
+	Field   string `json:"field"` // Path of the field, e.g. "doors[1].relay"; empty for document-wide errors
+	Message string `json:"message"`
+}
+
+// ConfigReport reports the outcome of applying a desired config version
+type ConfigReport struct {
+	Version         int64              `json:"version"`
+	Status          string             `json:"status"`         // "applied", "invalid", "rolled_back" or "failed"
+	AppliedVersion  int64              `json:"appliedVersion"` // Version in effect after the attempt, 0 for the local configuration
+	Errors          []ConfigFieldError `json:"errors,omitempty"`
+	Error           string             `json:"error,omitempty"`
+	RestartRequired []string           `json:"restartRequired,omitempty"` // Settings that take effect on the next restart
+	Timestamp       string             `json:"timestamp"`                 // RFC3339 timestamp
+}
+
+// GetDesiredConfig retrieves the config document the bridge should be running.
+// The document is returned as received so it can be validated strictly.
+func (c *HTTPClient) GetDesiredConfig(ctx context.Context) (json.RawMessage, error) {
+	req := &Request{
+		Method:      http.MethodGet,
+		Path:        "/api/v1/devices/config/desired",
+		RequireAuth: true,
+	}
+
+	resp, err := c.Do(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("desired config retrieval failed: %w", err)
+	}
+	if len(resp.Body) == 0 {
+		return nil, fmt.Errorf("desired config response is empty")
+	}
+
+	return json.RawMessage(resp.Body), nil
+}
+
+// ReportConfig reports the outcome of applying a desired config version
+func (c *HTTPClient) ReportConfig(ctx context.Context, report *ConfigReport) error {
+	req := &Request{
+		Method:      http.MethodPost,
+		Path:        "/api/v1/devices/config/report",
+		Body:        report,
+		RequireAuth: true,
+	}
+
+	if _, err := c.Do(ctx, req); err != nil {
+		return fmt.Errorf("config report failed: %w", err)
+	}
+
+	return nil
+}
+
+// Self-update outcomes reported to the platform
+const (
+	UpdateStatusSucceeded  = "succeeded"
+	UpdateStatusRolledBack = "rolled_back"
+	UpdateStatusFailed     = "failed"
+)
+
+// UpdateReport reports the outcome of a self-update installed by the bridge
+type UpdateReport struct {
+	FromVersion string `json:"fromVersion"`
+	ToVersion   string `json:"toVersion"`
+	Channel     string `json:"channel,omitempty"`
+	Status      string `json:"status"` // "succeeded", "rolled_back" or "failed"
+	Error       string `json:"error,omitempty"`
+	InstalledAt string `json:"installedAt"` // RFC3339 timestamp
+	Timestamp   string `json:"timestamp"`   // RFC3339 timestamp
+}
+
+// ReportUpdate reports the outcome of a self-update
+func (c *HTTPClient) ReportUpdate(ctx context.Context, report *UpdateReport) error {
+	req := &Request{
+		Method:      http.MethodPost,
+		Path:        "/api/v1/devices/updates/report",
+		Body:        report,
+		RequireAuth: true,
+	}
+
+	if _, err := c.Do(ctx, req); err != nil {
+		return fmt.Errorf("update report failed: %w", err)
+	}
+
+	return nil
+}
+
+// ErrRosterCursorExpired is returned when the platform no longer accepts a roster
+// cursor and the roster has to be pulled again from the start
+var ErrRosterCursorExpired = errors.New("roster cursor expired")
+
+// RosterMember represents a member of the gym roster and the credentials the
+// devices know them by. Deleted members are sent as tombstones with only the ID.
+type RosterMember struct {
+	InternalUserID string   `json:"internalUserId"`
+	Name           string   `json:"name,omitempty"`
+	Status         string   `json:"status,omitempty"` // "active", "suspended", "expired", "cancelled"
+	CardNumbers    []string `json:"cardNumbers,omitempty"`
+	FingerprintIDs []string `json:"fingerprintIds,omitempty"`
+	DeviceUserIDs  []string `json:"deviceUserIds,omitempty"`
+	UpdatedAt      string   `json:"updatedAt,omitempty"` // RFC3339 timestamp
+	Deleted        bool     `json:"deleted,omitempty"`
+}
+
+// RosterPage represents one page of roster changes
+type RosterPage struct {
+	Members     []RosterMember `json:"members"`
+	NextCursor  string         `json:"nextCursor"` // Cursor for the next page, or for the next delta sync once HasMore is false
+	HasMore     bool           `json:"hasMore"`
+	ETag        string         `json:"-"`
+	NotModified bool           `json:"-"` // The roster has not changed since the ETag
+}
+
+// GetRosterPage retrieves a page of roster changes after the cursor. An empty
+// cursor starts a full pull. The ETag of the previous delta lets the platform
+// answer 304 when nothing changed.
+func (c *HTTPClient) GetRosterPage(ctx context.Context, cursor, etag string, limit int) (*RosterPage, error) {
+	query := url.Values{}
+	if cursor != "" {
+		query.Set("cursor", cursor)
+	}
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+
+	path := "/api/v1/devices/roster"
+	if encoded := query.Encode(); encoded != "" {
+		path += "?" + encoded
+	}
+
+	req := &Request{
+		Method:      http.MethodGet,
+		Path:        path,
+		RequireAuth: true,
+	}
+	if etag != "" {
+		req.Headers = map[string]string{"If-None-Match": etag}
+	}
+
+	resp, err := c.Do(ctx, req)
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusGone {
+			return nil, ErrRosterCursorExpired
+		}
+		return nil, fmt.Errorf("roster retrieval failed: %w", err)
+	}
+	if resp.StatusCode == http.StatusNotModified {
+		return &RosterPage{NextCursor: cursor, ETag: etag, NotModified: true}, nil
+	}
+
+	var page RosterPage
+	if err := json.Unmarshal(resp.Body, &page); err != nil {
+		return nil, fmt.Errorf("failed to parse roster response: %w", err)
+	}
+	page.ETag = resp.Headers.Get("ETag")
+
+	c.logger.WithField("count", len(page.Members)).Debug("Roster page retrieved successfully")
+	return &page, nil
+}
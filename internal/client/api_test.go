@@ -296,7 +296,7 @@ func TestHTTPClient_SendHeartbeat(t *testing.T) {
 			}
 
 			ctx := context.Background()
-			err = client.SendHeartbeat(ctx, tt.heartbeat)
+			_, err = client.SendHeartbeat(ctx, tt.heartbeat)
 
 			if (err != nil) != tt.wantErr {
 				t.Errorf("SendHeartbeat() error = %v, wantErr %v", err, tt.wantErr)
@@ -305,6 +305,65 @@ func TestHTTPClient_SendHeartbeat(t *testing.T) {
 	}
 }
 
+func TestHTTPClient_SendHeartbeat_Response(t *testing.T) {
+	logger := logging.Initialize("debug")
+	authManager := newMockAuthManager("test-device", "test-key")
+
+	tests := []struct {
+		name           string
+		serverResponse func(w http.ResponseWriter, r *http.Request)
+		want           HeartbeatResponse
+	}{
+		{
+			name: "platform reply",
+			serverResponse: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(`{"desiredConfigVersion": 7, "pendingCommands": 2, "updateAvailable": true, "backoffSeconds": 300, "serverTime": "2024-01-01T10:00:00.250Z"}`))
+			},
+			want: HeartbeatResponse{
+				DesiredConfigVersion: 7,
+				PendingCommands:      2,
+				UpdateAvailable:      true,
+				BackoffSeconds:       300,
+				ServerTime:           "2024-01-01T10:00:00.250Z",
+			},
+		},
+		{
+			name: "empty reply uses the Date header",
+			serverResponse: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Date", "Mon, 01 Jan 2024 10:00:00 GMT")
+				w.WriteHeader(http.StatusNoContent)
+			},
+			want: HeartbeatResponse{
+				ServerTime: "2024-01-01T10:00:00Z",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(tt.serverResponse))
+			defer server.Close()
+
+			cfg := &config.Config{
+				ServerURL: server.URL,
+			}
+			client, err := NewHTTPClient(cfg, authManager, logger)
+			if err != nil {
+				t.Fatalf("Failed to create client: %v", err)
+			}
+
+			resp, err := client.SendHeartbeat(context.Background(), &HeartbeatRequest{Status: "healthy", Tier: "normal"})
+			if err != nil {
+				t.Fatalf("SendHeartbeat() error = %v", err)
+			}
+			if *resp != tt.want {
+				t.Errorf("SendHeartbeat() = %+v, want %+v", *resp, tt.want)
+			}
+		})
+	}
+}
+
 func TestHTTPClient_OpenDoor(t *testing.T) {
 	logger := logging.Initialize("debug")
 	authManager := newMockAuthManager("test-device", "test-key")
@@ -445,6 +504,58 @@ func TestHTTPClient_GetDeviceConfig(t *testing.T) {
 	}
 }
 
+func TestHTTPClient_DesiredConfig(t *testing.T) {
+	logger := logging.Initialize("debug")
+	authManager := newMockAuthManager("test-device", "test-key")
+
+	document := `{"version":4,"doors":[{"id":"front"}]}`
+	var report ConfigReport
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v1/devices/config/desired":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(document))
+		case r.Method == http.MethodPost && r.URL.Path == "/api/v1/devices/config/report":
+			if err := json.NewDecoder(r.Body).Decode(&report); err != nil {
+				t.Errorf("Failed to decode config report: %v", err)
+			}
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewHTTPClient(&config.Config{ServerURL: server.URL}, authManager, logger)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	raw, err := client.GetDesiredConfig(context.Background())
+	if err != nil {
+		t.Fatalf("GetDesiredConfig() error = %v", err)
+	}
+	if string(raw) != document {
+		t.Errorf("GetDesiredConfig() = %s, want the document as received", raw)
+	}
+
+	err = client.ReportConfig(context.Background(), &ConfigReport{
+		Version:        4,
+		Status:         ConfigStatusInvalid,
+		AppliedVersion: 3,
+		Errors:         []ConfigFieldError{{Field: "doors[0].relay", Message: "unknown driver"}},
+		Timestamp:      "2024-01-01T10:00:00Z",
+	})
+	if err != nil {
+		t.Fatalf("ReportConfig() error = %v", err)
+	}
+	if report.Version != 4 || report.Status != ConfigStatusInvalid || report.AppliedVersion != 3 ||
+		len(report.Errors) != 1 || report.Errors[0].Field != "doors[0].relay" {
+		t.Errorf("Unexpected config report: %+v", report)
+	}
+}
+
 func TestHTTPClient_CheckConnectivity(t *testing.T) {
 	logger := logging.Initialize("debug")
 	authManager := newMockAuthManager("test-device", "test-key")
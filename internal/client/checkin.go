@@ -6,6 +6,7 @@ import (
 	"encoding/hex"
 	"fmt"
 	"net/http"
+	"sync"
 	"time"
 
 	"gym-door-bridge/internal/types"
@@ -20,6 +21,20 @@ type CheckinResponse struct {
 	ErrorMessage string   `json:"errorMessage,omitempty"`
 }
 
+// Check-in event schema versions
+const (
+	CheckinSchemaV1 = 1
+	CheckinSchemaV2 = 2
+)
+
+// checkinV2ContentType marks a version 2 check-in payload. Servers that only
+// know version 1 reject it with 415 Unsupported Media Type.
+const checkinV2ContentType = "application/vnd.gym-door-bridge.checkin+json; version=2"
+
+// checkinSchemaRecheckInterval is how long events are sent as version 1 after
+// the server rejected version 2, before trying version 2 again
+const checkinSchemaRecheckInterval = 24 * time.Hour
+
 // HTTPClientInterface defines the interface for HTTP client operations
 type HTTPClientInterface interface {
 	Do(ctx context.Context, req *Request) (*Response, error)
@@ -29,6 +44,9 @@ type HTTPClientInterface interface {
 type CheckinClient struct {
 	httpClient HTTPClientInterface
 	logger     *logrus.Logger
+
+	mu      sync.Mutex
+	v1Until time.Time // Send version 1 until then; zero to try version 2
 }
 
 // NewCheckinClient creates a new checkin client
@@ -58,37 +76,24 @@ func (c *CheckinClient) SubmitEvents(ctx context.Context, events []types.Standar
 		}
 	}
 
-	// Convert StandardEvents to CheckinEvents
-	checkinEvents := make([]CheckinEvent, len(eventsWithKeys))
-	for i, event := range eventsWithKeys {
-		checkinEvents[i] = CheckinEvent{
-			EventID:        event.EventID,
-			ExternalUserID: event.ExternalUserID,
-			Timestamp:      event.Timestamp.Format(time.RFC3339),
-			EventType:      event.EventType,
-			IsSimulated:    event.IsSimulated,
-			DeviceID:       event.DeviceID,
-		}
-	}
-
-	// Create request payload
-	request := CheckinRequest{
-		Events: checkinEvents,
-	}
+	schemaVersion := c.SchemaVersion()
 
 	c.logger.Info("Submitting events to checkin endpoint", 
 		"event_count", len(events),
-		"first_event_id", eventsWithKeys[0].EventID)
-
-	// Make HTTP request
-	httpReq := &Request{
-		Method:      http.MethodPost,
-		Path:        "/api/v1/checkin",
-		Body:        request,
-		RequireAuth: true,
+		"first_event_id", eventsWithKeys[0].EventID,
+		"schema_version", schemaVersion)
+
+	resp, err := c.httpClient.Do(ctx, buildCheckinRequest(eventsWithKeys, schemaVersion))
+	if err != nil && schemaVersion == CheckinSchemaV2 && resp != nil && resp.StatusCode == http.StatusUnsupportedMediaType {
+		// The server predates version 2, so resend the batch as version 1
+		c.logger.Warn("Server does not accept version 2 check-in events, falling back to version 1",
+			"recheck_after", checkinSchemaRecheckInterval)
+		c.mu.Lock()
+		c.v1Until = time.Now().Add(checkinSchemaRecheckInterval)
+		c.mu.Unlock()
+
+		resp, err = c.httpClient.Do(ctx, buildCheckinRequest(eventsWithKeys, CheckinSchemaV1))
 	}
-
-	resp, err := c.httpClient.Do(ctx, httpReq)
 	if err != nil {
 		c.logger.Error("Failed to submit events", "error", err)
 		return nil, fmt.Errorf("failed to submit events: %w", err)
@@ -114,6 +119,62 @@ func (c *CheckinClient) SubmitEvents(ctx context.Context, events []types.Standar
 	return &checkinResp, nil
 }
 
+// SchemaVersion returns the check-in schema version the next batch is sent with
+func (c *CheckinClient) SchemaVersion() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if time.Now().Before(c.v1Until) {
+		return CheckinSchemaV1
+	}
+	return CheckinSchemaV2
+}
+
+// buildCheckinRequest builds the check-in request for events in the given schema version
+func buildCheckinRequest(events []types.StandardEvent, schemaVersion int) *Request {
+	checkinEvents := make([]CheckinEvent, len(events))
+	for i, event := range events {
+		checkinEvents[i] = CheckinEvent{
+			EventID:        event.EventID,
+			ExternalUserID: event.ExternalUserID,
+			Timestamp:      event.Timestamp.Format(time.RFC3339),
+			EventType:      event.EventType,
+			IsSimulated:    event.IsSimulated,
+			DeviceID:       event.DeviceID,
+			DoorID:         event.DoorID,
+		}
+	}
+
+	if schemaVersion == CheckinSchemaV1 {
+		return &Request{
+			Method:      http.MethodPost,
+			Path:        "/api/v1/checkin",
+			Body:        CheckinRequest{Events: checkinEvents},
+			RequireAuth: true,
+		}
+	}
+
+	eventsV2 := make([]CheckinEventV2, len(events))
+	for i, event := range events {
+		eventsV2[i] = CheckinEventV2{
+			CheckinEvent:   checkinEvents[i],
+			InternalUserID: event.InternalUserID,
+			Adapter:        event.AdapterName,
+			VerifyMode:     event.VerifyMode,
+			AccessReason:   event.AccessReason,
+			RawData:        event.RawData,
+		}
+	}
+
+	return &Request{
+		Method:      http.MethodPost,
+		Path:        "/api/v1/checkin",
+		Body:        CheckinRequestV2{SchemaVersion: CheckinSchemaV2, Events: eventsV2},
+		Headers:     map[string]string{"Content-Type": checkinV2ContentType},
+		RequireAuth: true,
+	}
+}
+
 // SubmitSingleEvent submits a single event to the checkin endpoint
 func (c *CheckinClient) SubmitSingleEvent(ctx context.Context, event types.StandardEvent) (*CheckinResponse, error) {
 	return c.SubmitEvents(ctx, []types.StandardEvent{event})
@@ -151,7 +212,8 @@ func (c *CheckinClient) ValidateEvents(events []types.StandardEvent) error {
 
 // validateSingleEvent validates a single event
 func (c *CheckinClient) validateSingleEvent(event types.StandardEvent) error {
-	if event.ExternalUserID == "" {
+	// Door alarms are raised by the door itself, not by a member
+	if event.ExternalUserID == "" && !types.IsDoorAlarmEventType(event.EventType) {
 		return fmt.Errorf("externalUserId is required")
 	}
 
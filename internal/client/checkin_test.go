@@ -269,13 +269,128 @@ func TestCheckinClient_SubmitEvents_GeneratesIdempotencyKeys(t *testing.T) {
 	require.NotNil(t, capturedRequest)
 
 	// Verify that idempotency key was generated
-	checkinReq := capturedRequest.Body.(CheckinRequest)
+	checkinReq := capturedRequest.Body.(CheckinRequestV2)
 	assert.NotEmpty(t, checkinReq.Events[0].EventID)
 	assert.True(t, strings.HasPrefix(checkinReq.Events[0].EventID, "evt_"))
 
 	httpClient.AssertExpectations(t)
 }
 
+func TestCheckinClient_SubmitEvents_SchemaV2(t *testing.T) {
+	httpClient := &MockHTTPClient{}
+	logger := logrus.New()
+	client := NewCheckinClient(httpClient, logger)
+
+	events := []types.StandardEvent{
+		{
+			EventID:        "evt_123",
+			ExternalUserID: "user_123",
+			InternalUserID: "member_42",
+			Timestamp:      time.Now(),
+			EventType:      types.EventTypeEntry,
+			DeviceID:       "device_123",
+			DoorID:         "front",
+			AccessReason:   "allowed",
+			AdapterName:    "zkteco",
+			VerifyMode:     types.VerifyModeFingerprint,
+			RawData:        map[string]interface{}{"work_code": 0},
+		},
+	}
+
+	var capturedRequest *Request
+	httpClient.On("Do", mock.Anything, mock.MatchedBy(func(req *Request) bool {
+		capturedRequest = req
+		return true
+	})).Return(&Response{
+		StatusCode: 200,
+		Body:       []byte(`{"success": true, "processedIds": ["evt_123"]}`),
+	}, nil)
+
+	_, err := client.SubmitEvents(context.Background(), events)
+	require.NoError(t, err)
+	require.NotNil(t, capturedRequest)
+
+	assert.Equal(t, checkinV2ContentType, capturedRequest.Headers["Content-Type"])
+
+	// Check the fields as the platform sees them on the wire
+	body, err := json.Marshal(capturedRequest.Body)
+	require.NoError(t, err)
+
+	var payload struct {
+		SchemaVersion int                      `json:"schemaVersion"`
+		Events        []map[string]interface{} `json:"events"`
+	}
+	require.NoError(t, json.Unmarshal(body, &payload))
+	assert.Equal(t, CheckinSchemaV2, payload.SchemaVersion)
+	require.Len(t, payload.Events, 1)
+
+	event := payload.Events[0]
+	assert.Equal(t, "evt_123", event["eventId"])
+	assert.Equal(t, "user_123", event["externalUserId"])
+	assert.Equal(t, "member_42", event["internalUserId"])
+	assert.Equal(t, "front", event["doorId"])
+	assert.Equal(t, "zkteco", event["adapter"])
+	assert.Equal(t, "fingerprint", event["verifyMode"])
+	assert.Equal(t, "allowed", event["accessReason"])
+	assert.Equal(t, map[string]interface{}{"work_code": float64(0)}, event["rawData"])
+
+	httpClient.AssertExpectations(t)
+}
+
+func TestCheckinClient_SubmitEvents_FallsBackToV1(t *testing.T) {
+	httpClient := &MockHTTPClient{}
+	logger := logrus.New()
+	client := NewCheckinClient(httpClient, logger)
+
+	events := []types.StandardEvent{
+		{
+			EventID:        "evt_123",
+			ExternalUserID: "user_123",
+			InternalUserID: "member_42",
+			Timestamp:      time.Now(),
+			EventType:      types.EventTypeEntry,
+			DeviceID:       "device_123",
+		},
+	}
+
+	isV2 := func(req *Request) bool {
+		_, ok := req.Body.(CheckinRequestV2)
+		return ok
+	}
+	isV1 := func(req *Request) bool {
+		_, ok := req.Body.(CheckinRequest)
+		return ok && req.Headers["Content-Type"] == ""
+	}
+
+	// A server that predates version 2 rejects the media type
+	httpClient.On("Do", mock.Anything, mock.MatchedBy(isV2)).Return(&Response{
+		StatusCode: http.StatusUnsupportedMediaType,
+	}, fmt.Errorf("HTTP error 415")).Once()
+	httpClient.On("Do", mock.Anything, mock.MatchedBy(isV1)).Return(&Response{
+		StatusCode: 200,
+		Body:       []byte(`{"success": true, "processedIds": ["evt_123"]}`),
+	}, nil).Twice()
+
+	ctx := context.Background()
+	resp, err := client.SubmitEvents(ctx, events)
+	require.NoError(t, err)
+	assert.True(t, resp.Success)
+
+	// The next batch goes straight to version 1
+	assert.Equal(t, CheckinSchemaV1, client.SchemaVersion())
+	_, err = client.SubmitEvents(ctx, events)
+	require.NoError(t, err)
+
+	// Version 2 is tried again once the recheck interval has passed
+	client.mu.Lock()
+	assert.WithinDuration(t, time.Now().Add(checkinSchemaRecheckInterval), client.v1Until, time.Minute)
+	client.v1Until = time.Now().Add(-time.Second)
+	client.mu.Unlock()
+	assert.Equal(t, CheckinSchemaV2, client.SchemaVersion())
+
+	httpClient.AssertExpectations(t)
+}
+
 func TestCheckinClient_ValidateEvents(t *testing.T) {
 	httpClient := &MockHTTPClient{}
 	logger := logrus.New()
@@ -11,6 +11,7 @@ import (
 	"net"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
@@ -153,6 +154,12 @@ func (c *HTTPClient) Do(ctx context.Context, req *Request) (*Response, error) {
 		if err != nil {
 			lastErr = err
 			
+			// The platform is overloaded and said when to come back; retrying
+			// sooner only adds to its load
+			if retryAfter, ok := retryAfterDelay(resp, time.Now()); ok {
+				return resp, &BackoffError{RetryAfter: retryAfter, Err: err}
+			}
+			
 			// Check if we should retry
 			if !c.shouldRetry(err, resp) {
 				return resp, err
@@ -258,6 +265,47 @@ func (c *HTTPClient) doRequest(ctx context.Context, req *Request) (*Response, er
 	return resp, nil
 }
 
+// BackoffError is returned when the platform answers 429 or 503 with a
+// Retry-After header. The platform should not be contacted again for the same
+// purpose before RetryAfter has passed.
+type BackoffError struct {
+	RetryAfter time.Duration
+	Err        error
+}
+
+func (e *BackoffError) Error() string {
+	return fmt.Sprintf("%v (retry after %v)", e.Err, e.RetryAfter)
+}
+
+func (e *BackoffError) Unwrap() error {
+	return e.Err
+}
+
+// retryAfterDelay returns the delay requested by the Retry-After header of a
+// 429 or 503 response, given either in seconds or as an HTTP date
+func retryAfterDelay(resp *Response, now time.Time) (time.Duration, bool) {
+	if resp == nil || (resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusServiceUnavailable) {
+		return 0, false
+	}
+	value := strings.TrimSpace(resp.Headers.Get("Retry-After"))
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		if delay := date.Sub(now); delay > 0 {
+			return delay, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
 // shouldRetry determines if a request should be retried based on the error and response
 func (c *HTTPClient) shouldRetry(err error, resp *Response) bool {
 	// Don't retry context cancellation or timeout
@@ -2,6 +2,7 @@ package client
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -411,6 +412,81 @@ func TestHTTPClient_RetryLogic(t *testing.T) {
 	}
 }
 
+func TestHTTPClient_HonoursRetryAfter(t *testing.T) {
+	logger := logging.Initialize("debug")
+	authManager := newMockAuthManager("test-device", "test-key")
+	
+	attemptCount := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attemptCount++
+		w.Header().Set("Retry-After", "120")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{
+		ServerURL: server.URL,
+	}
+	client, err := NewHTTPClient(cfg, authManager, logger)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	client.maxRetries = 5
+	client.baseDelay = 1 * time.Millisecond
+
+	req := &Request{
+		Method:      http.MethodGet,
+		Path:        "/test",
+		RequireAuth: false,
+	}
+
+	_, err = client.Do(context.Background(), req)
+
+	var backoffErr *BackoffError
+	if !errors.As(err, &backoffErr) {
+		t.Fatalf("Expected BackoffError, got %v", err)
+	}
+	if backoffErr.RetryAfter != 120*time.Second {
+		t.Errorf("Expected retry after 2m0s, got %v", backoffErr.RetryAfter)
+	}
+	if attemptCount != 1 {
+		t.Errorf("Expected 1 attempt, got %d", attemptCount)
+	}
+}
+
+func TestRetryAfterDelay(t *testing.T) {
+	now := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	
+	tests := []struct {
+		name       string
+		statusCode int
+		retryAfter string
+		wantDelay  time.Duration
+		wantOK     bool
+	}{
+		{"seconds", 429, "30", 30 * time.Second, true},
+		{"http date", 503, "Mon, 01 Jan 2024 10:05:00 GMT", 5 * time.Minute, true},
+		{"date in the past", 503, "Mon, 01 Jan 2024 09:00:00 GMT", 0, true},
+		{"no header", 429, "", 0, false},
+		{"invalid header", 429, "soon", 0, false},
+		{"other status", 500, "30", 0, false},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &Response{StatusCode: tt.statusCode, Headers: http.Header{}}
+			if tt.retryAfter != "" {
+				resp.Headers.Set("Retry-After", tt.retryAfter)
+			}
+			
+			delay, ok := retryAfterDelay(resp, now)
+			if delay != tt.wantDelay || ok != tt.wantOK {
+				t.Errorf("retryAfterDelay() = %v, %v, want %v, %v", delay, ok, tt.wantDelay, tt.wantOK)
+			}
+		})
+	}
+}
+
 func TestHTTPClient_CustomHeaders(t *testing.T) {
 	logger := logging.Initialize("debug")
 	authManager := newMockAuthManager("test-device", "test-key")
@@ -70,7 +70,7 @@ func TestCheckinIntegration(t *testing.T) {
 		}
 
 		// Verify the request body contains our events
-		checkinReq, ok := req.Body.(CheckinRequest)
+		checkinReq, ok := req.Body.(CheckinRequestV2)
 		if !ok || len(checkinReq.Events) != 2 {
 			return false
 		}
@@ -134,7 +134,7 @@ func TestCheckinIntegration_IdempotencyKeyGeneration(t *testing.T) {
 	// Capture the generated event ID
 	var capturedEventID string
 	httpClient.On("Do", mock.Anything, mock.MatchedBy(func(req *Request) bool {
-		checkinReq, ok := req.Body.(CheckinRequest)
+		checkinReq, ok := req.Body.(CheckinRequestV2)
 		if ok && len(checkinReq.Events) == 1 {
 			capturedEventID = checkinReq.Events[0].EventID
 			return capturedEventID != "" // Should have generated an ID
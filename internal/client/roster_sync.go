@@ -0,0 +1,367 @@
+package client
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gym-door-bridge/internal/database"
+	"gym-door-bridge/internal/logging"
+)
+
+// RosterSyncStateKey is the device_config key holding the roster sync progress
+const RosterSyncStateKey = "roster_sync_state"
+
+// RosterSource defines the cloud client method needed by the roster syncer
+type RosterSource interface {
+	GetRosterPage(ctx context.Context, cursor, etag string, limit int) (*RosterPage, error)
+}
+
+// RosterStore defines the database methods needed by the roster syncer
+type RosterStore interface {
+	GetConfig(key string) (string, error)
+	SetConfig(key, value string) error
+	ApplyRosterMember(member database.RosterMember, syncedAt time.Time) (database.RosterApplyResult, error)
+	PurgeStaleRosterMappings(syncedBefore time.Time) (int64, error)
+}
+
+// RosterSyncState is the sync progress persisted after every page so that a
+// restart resumes where it stopped
+type RosterSyncState struct {
+	Cursor            string    `json:"cursor,omitempty"`
+	ETag              string    `json:"etag,omitempty"`
+	FullSync          bool      `json:"fullSync"` // A full pull is in progress
+	FullSyncStartedAt time.Time `json:"fullSyncStartedAt,omitempty"`
+	PagesFetched      int       `json:"pagesFetched"`   // Pages applied in the current pull
+	MembersApplied    int       `json:"membersApplied"` // Members applied in the current pull
+	LastFullSyncAt    time.Time `json:"lastFullSyncAt,omitempty"`
+	LastSyncAt        time.Time `json:"lastSyncAt,omitempty"`
+}
+
+// RosterSyncStats contains statistics about roster synchronization
+type RosterSyncStats struct {
+	TotalSyncs         int64  `json:"totalSyncs"`
+	TotalFailures      int64  `json:"totalFailures"`
+	LastSyncAt         int64  `json:"lastSyncAt"`     // Unix timestamp
+	LastFullSyncAt     int64  `json:"lastFullSyncAt"` // Unix timestamp
+	FullSyncInProgress bool   `json:"fullSyncInProgress"`
+	MembersApplied     int64  `json:"membersApplied"`
+	MembersSkipped     int64  `json:"membersSkipped"`
+	MappingsCreated    int64  `json:"mappingsCreated"`
+	MappingsUpdated    int64  `json:"mappingsUpdated"`
+	MappingsDeleted    int64  `json:"mappingsDeleted"`
+	LocalConflicts     int64  `json:"localConflicts"` // Local mappings kept over the platform
+	LastError          string `json:"lastError,omitempty"`
+}
+
+// RosterSyncer pulls the member roster from the platform into the external user
+// mappings. The first sync pulls the full roster; later syncs fetch the changes
+// since the last cursor.
+type RosterSyncer struct {
+	source   RosterSource
+	store    RosterStore
+	interval time.Duration
+	pageSize int
+	logger   *logrus.Entry
+	syncMu   sync.Mutex // Serializes syncs started by the ticker and by commands
+	stats    RosterSyncStats
+	mutex    sync.RWMutex
+}
+
+// NewRosterSyncer creates a new roster syncer
+func NewRosterSyncer(source RosterSource, store RosterStore, interval time.Duration, pageSize int, logger *logrus.Logger) *RosterSyncer {
+	if interval <= 0 {
+		interval = 5 * time.Minute
+	}
+	if pageSize <= 0 {
+		pageSize = 500
+	}
+
+	return &RosterSyncer{
+		source:   source,
+		store:    store,
+		interval: interval,
+		pageSize: pageSize,
+		logger:   logging.NewServiceLogger(logger, "roster-sync"),
+	}
+}
+
+// Start runs an initial sync and then syncs periodically until the context is cancelled
+func (s *RosterSyncer) Start(ctx context.Context) {
+	s.logger.WithField("interval", s.interval).Info("Starting roster sync")
+
+	if err := s.Sync(ctx); err != nil {
+		s.logger.WithError(err).Warn("Initial roster sync failed, using cached mappings")
+	}
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.logger.Info("Roster sync stopped")
+			return
+		case <-ticker.C:
+			if err := s.Sync(ctx); err != nil {
+				s.logger.WithError(err).Warn("Roster sync failed, using cached mappings")
+			}
+		}
+	}
+}
+
+// Sync fetches roster pages until the platform has no more changes. Progress is
+// saved after each page, so a failed sync continues from the last applied page.
+// When a full pull completes, platform mappings it did not include are removed.
+func (s *RosterSyncer) Sync(ctx context.Context) error {
+	s.syncMu.Lock()
+	defer s.syncMu.Unlock()
+
+	state, err := s.loadState()
+	if err != nil {
+		s.recordFailure(err)
+		return err
+	}
+	if state.FullSync && state.FullSyncStartedAt.IsZero() {
+		s.startFullSync(state)
+	}
+
+	restarted := false
+	for {
+		etag := ""
+		if !state.FullSync {
+			etag = state.ETag
+		}
+
+		page, err := s.source.GetRosterPage(ctx, state.Cursor, etag, s.pageSize)
+		if errors.Is(err, ErrRosterCursorExpired) && !restarted {
+			s.logger.Warn("Roster cursor expired, pulling the full roster")
+			s.startFullSync(state)
+			restarted = true
+			continue
+		}
+		if err != nil {
+			s.recordFailure(err)
+			return fmt.Errorf("failed to fetch roster: %w", err)
+		}
+
+		if page.NotModified {
+			break
+		}
+
+		if err := s.applyPage(state, page); err != nil {
+			s.recordFailure(err)
+			return err
+		}
+
+		if !page.HasMore {
+			state.ETag = page.ETag
+			break
+		}
+		if page.NextCursor == "" {
+			err := fmt.Errorf("roster page has more members but no cursor")
+			s.recordFailure(err)
+			return err
+		}
+		if err := s.saveState(state); err != nil {
+			s.recordFailure(err)
+			return err
+		}
+	}
+
+	now := time.Now()
+	if state.FullSync {
+		purged, err := s.store.PurgeStaleRosterMappings(state.FullSyncStartedAt)
+		if err != nil {
+			s.recordFailure(err)
+			return fmt.Errorf("failed to purge stale roster mappings: %w", err)
+		}
+
+		s.logger.WithFields(logrus.Fields{
+			"members": state.MembersApplied,
+			"pages":   state.PagesFetched,
+			"purged":  purged,
+		}).Info("Full roster pull completed")
+
+		state.FullSync = false
+		state.LastFullSyncAt = now
+
+		s.mutex.Lock()
+		s.stats.MappingsDeleted += purged
+		s.stats.LastFullSyncAt = now.Unix()
+		s.mutex.Unlock()
+	}
+	state.LastSyncAt = now
+	if err := s.saveState(state); err != nil {
+		s.recordFailure(err)
+		return err
+	}
+
+	s.mutex.Lock()
+	s.stats.TotalSyncs++
+	s.stats.LastSyncAt = now.Unix()
+	s.stats.FullSyncInProgress = false
+	s.stats.LastError = ""
+	s.mutex.Unlock()
+
+	return nil
+}
+
+// GetStats returns roster synchronization statistics
+func (s *RosterSyncer) GetStats() RosterSyncStats {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return s.stats
+}
+
+// applyPage applies the members of a page and advances the cursor
+func (s *RosterSyncer) applyPage(state *RosterSyncState, page *RosterPage) error {
+	syncedAt := time.Now()
+
+	var applied, skipped int64
+	var totals database.RosterApplyResult
+	for _, member := range page.Members {
+		converted, err := convertRosterMember(member)
+		if err != nil {
+			// A malformed record must not block the rest of the roster
+			s.logger.WithError(err).WithField("internalUserId", member.InternalUserID).Warn("Skipping invalid roster member")
+			skipped++
+			continue
+		}
+
+		result, err := s.store.ApplyRosterMember(converted, syncedAt)
+		if err != nil {
+			return fmt.Errorf("failed to apply roster member %s: %w", member.InternalUserID, err)
+		}
+		if result.Conflicts > 0 {
+			s.logger.WithField("internalUserId", member.InternalUserID).Warn("Kept newer local mapping over roster credential")
+		}
+
+		totals.Created += result.Created
+		totals.Updated += result.Updated
+		totals.Deleted += result.Deleted
+		totals.Conflicts += result.Conflicts
+		applied++
+	}
+
+	state.Cursor = page.NextCursor
+	state.PagesFetched++
+	state.MembersApplied += int(applied)
+
+	s.mutex.Lock()
+	s.stats.MembersApplied += applied
+	s.stats.MembersSkipped += skipped
+	s.stats.MappingsCreated += int64(totals.Created)
+	s.stats.MappingsUpdated += int64(totals.Updated)
+	s.stats.MappingsDeleted += int64(totals.Deleted)
+	s.stats.LocalConflicts += int64(totals.Conflicts)
+	s.mutex.Unlock()
+
+	return nil
+}
+
+// startFullSync resets the state to pull the roster from the start
+func (s *RosterSyncer) startFullSync(state *RosterSyncState) {
+	state.Cursor = ""
+	state.ETag = ""
+	state.FullSync = true
+	state.FullSyncStartedAt = time.Now()
+	state.PagesFetched = 0
+	state.MembersApplied = 0
+
+	s.mutex.Lock()
+	s.stats.FullSyncInProgress = true
+	s.mutex.Unlock()
+}
+
+// loadState reads the persisted sync progress; without one a full pull is needed
+func (s *RosterSyncer) loadState() (*RosterSyncState, error) {
+	value, err := s.store.GetConfig(RosterSyncStateKey)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return &RosterSyncState{FullSync: true}, nil
+		}
+		return nil, fmt.Errorf("failed to load roster sync state: %w", err)
+	}
+
+	var state RosterSyncState
+	if err := json.Unmarshal([]byte(value), &state); err != nil {
+		s.logger.WithError(err).Warn("Discarding unreadable roster sync state")
+		return &RosterSyncState{FullSync: true}, nil
+	}
+
+	if state.FullSync {
+		s.mutex.Lock()
+		s.stats.FullSyncInProgress = true
+		s.mutex.Unlock()
+	}
+
+	return &state, nil
+}
+
+// saveState persists the sync progress
+func (s *RosterSyncer) saveState(state *RosterSyncState) error {
+	value, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("failed to encode roster sync state: %w", err)
+	}
+	if err := s.store.SetConfig(RosterSyncStateKey, string(value)); err != nil {
+		return fmt.Errorf("failed to save roster sync state: %w", err)
+	}
+	return nil
+}
+
+// recordFailure updates failure statistics
+func (s *RosterSyncer) recordFailure(err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.stats.TotalFailures++
+	s.stats.LastError = err.Error()
+}
+
+// convertRosterMember converts a roster member to the form applied to the mappings.
+// Members without an update time never override local mappings.
+func convertRosterMember(member RosterMember) (database.RosterMember, error) {
+	converted := database.RosterMember{
+		InternalUserID: member.InternalUserID,
+		UserName:       member.Name,
+		Status:         member.Status,
+		Deleted:        member.Deleted,
+	}
+	if member.InternalUserID == "" {
+		return converted, fmt.Errorf("internal user ID is missing")
+	}
+
+	if member.UpdatedAt != "" {
+		updatedAt, err := time.Parse(time.RFC3339, member.UpdatedAt)
+		if err != nil {
+			return converted, fmt.Errorf("invalid update time: %w", err)
+		}
+		converted.UpdatedAt = updatedAt.UTC()
+	}
+
+	for _, credentials := range []struct {
+		ids            []string
+		credentialType string
+	}{
+		{member.CardNumbers, database.CredentialTypeCard},
+		{member.FingerprintIDs, database.CredentialTypeFingerprint},
+		{member.DeviceUserIDs, database.CredentialTypeDeviceUser},
+	} {
+		for _, id := range credentials.ids {
+			converted.Credentials = append(converted.Credentials, database.RosterCredential{
+				ExternalUserID: id,
+				Type:           credentials.credentialType,
+			})
+		}
+	}
+
+	return converted, nil
+}
@@ -0,0 +1,245 @@
+package client
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gym-door-bridge/internal/config"
+	"gym-door-bridge/internal/database"
+	"gym-door-bridge/internal/logging"
+)
+
+// fakeRosterSource serves roster pages keyed by cursor
+type fakeRosterSource struct {
+	pages    map[string]*RosterPage
+	failAt   map[string]error
+	requests []string
+	etags    []string
+}
+
+func (s *fakeRosterSource) GetRosterPage(ctx context.Context, cursor, etag string, limit int) (*RosterPage, error) {
+	s.requests = append(s.requests, cursor)
+	s.etags = append(s.etags, etag)
+	if err, ok := s.failAt[cursor]; ok {
+		delete(s.failAt, cursor)
+		return nil, err
+	}
+	if etag != "" && etag == s.pages[cursor].ETag {
+		return &RosterPage{NextCursor: cursor, ETag: etag, NotModified: true}, nil
+	}
+	page, ok := s.pages[cursor]
+	if !ok {
+		return nil, fmt.Errorf("unexpected cursor %q", cursor)
+	}
+	return page, nil
+}
+
+// memoryRosterStore keeps the sync state and applied members in memory
+type memoryRosterStore struct {
+	config      map[string]string
+	members     map[string]database.RosterMember
+	purgeBefore []time.Time
+}
+
+func newMemoryRosterStore() *memoryRosterStore {
+	return &memoryRosterStore{
+		config:  make(map[string]string),
+		members: make(map[string]database.RosterMember),
+	}
+}
+
+func (s *memoryRosterStore) GetConfig(key string) (string, error) {
+	value, ok := s.config[key]
+	if !ok {
+		return "", fmt.Errorf("failed to get config %s: %w", key, sql.ErrNoRows)
+	}
+	return value, nil
+}
+
+func (s *memoryRosterStore) SetConfig(key, value string) error {
+	s.config[key] = value
+	return nil
+}
+
+func (s *memoryRosterStore) ApplyRosterMember(member database.RosterMember, syncedAt time.Time) (database.RosterApplyResult, error) {
+	if member.Deleted {
+		delete(s.members, member.InternalUserID)
+		return database.RosterApplyResult{Deleted: 1}, nil
+	}
+	s.members[member.InternalUserID] = member
+	return database.RosterApplyResult{Created: len(member.Credentials)}, nil
+}
+
+func (s *memoryRosterStore) PurgeStaleRosterMappings(syncedBefore time.Time) (int64, error) {
+	s.purgeBefore = append(s.purgeBefore, syncedBefore)
+	return 0, nil
+}
+
+func (s *memoryRosterStore) state(t *testing.T) RosterSyncState {
+	t.Helper()
+	var state RosterSyncState
+	require.NoError(t, json.Unmarshal([]byte(s.config[RosterSyncStateKey]), &state))
+	return state
+}
+
+func testRosterPages() map[string]*RosterPage {
+	return map[string]*RosterPage{
+		"": {
+			Members: []RosterMember{
+				{InternalUserID: "user_1", Name: "Alex", Status: "active", CardNumbers: []string{"100"}, FingerprintIDs: []string{"7"}, UpdatedAt: "2026-01-02T10:00:00Z"},
+			},
+			NextCursor: "page_2",
+			HasMore:    true,
+		},
+		"page_2": {
+			Members: []RosterMember{
+				{InternalUserID: "user_2", Status: "active", DeviceUserIDs: []string{"42"}},
+				{InternalUserID: "user_3", UpdatedAt: "not a time"},
+			},
+			NextCursor: "delta_1",
+			ETag:       `"v1"`,
+		},
+		"delta_1": {
+			Members:    []RosterMember{{InternalUserID: "user_1", Deleted: true}},
+			NextCursor: "delta_2",
+			ETag:       `"v2"`,
+		},
+		"delta_2": {NextCursor: "delta_2", ETag: `"v2"`},
+	}
+}
+
+func TestRosterSyncer_FullThenDelta(t *testing.T) {
+	ctx := context.Background()
+	source := &fakeRosterSource{pages: testRosterPages()}
+	store := newMemoryRosterStore()
+	syncer := NewRosterSyncer(source, store, time.Minute, 100, logging.Initialize("error"))
+
+	require.NoError(t, syncer.Sync(ctx))
+	assert.Equal(t, []string{"", "page_2"}, source.requests)
+	require.Contains(t, store.members, "user_1")
+	assert.Equal(t, []database.RosterCredential{
+		{ExternalUserID: "100", Type: database.CredentialTypeCard},
+		{ExternalUserID: "7", Type: database.CredentialTypeFingerprint},
+	}, store.members["user_1"].Credentials)
+	assert.Equal(t, "Alex", store.members["user_1"].UserName)
+	assert.Equal(t, database.CredentialTypeDeviceUser, store.members["user_2"].Credentials[0].Type)
+	assert.NotContains(t, store.members, "user_3", "invalid members are skipped")
+
+	state := store.state(t)
+	assert.False(t, state.FullSync)
+	assert.Equal(t, "delta_1", state.Cursor)
+	assert.Equal(t, `"v1"`, state.ETag)
+	assert.Equal(t, 2, state.PagesFetched)
+	require.Len(t, store.purgeBefore, 1, "a completed full pull purges stale mappings")
+	assert.True(t, store.purgeBefore[0].Equal(state.FullSyncStartedAt))
+
+	// The delta removes the tombstoned member and sends the ETag of the last sync
+	require.NoError(t, syncer.Sync(ctx))
+	assert.NotContains(t, store.members, "user_1")
+	assert.Equal(t, `"v1"`, source.etags[2])
+	assert.Equal(t, "delta_2", store.state(t).Cursor)
+
+	// Nothing changed since the last delta
+	require.NoError(t, syncer.Sync(ctx))
+	assert.Equal(t, "delta_2", store.state(t).Cursor)
+	assert.Len(t, store.purgeBefore, 1, "deltas do not purge")
+
+	stats := syncer.GetStats()
+	assert.Equal(t, int64(3), stats.TotalSyncs)
+	assert.Equal(t, int64(3), stats.MembersApplied)
+	assert.Equal(t, int64(1), stats.MembersSkipped)
+	assert.False(t, stats.FullSyncInProgress)
+}
+
+func TestRosterSyncer_ResumesAfterRestart(t *testing.T) {
+	ctx := context.Background()
+	source := &fakeRosterSource{
+		pages:  testRosterPages(),
+		failAt: map[string]error{"page_2": errors.New("platform unavailable")},
+	}
+	store := newMemoryRosterStore()
+
+	err := NewRosterSyncer(source, store, time.Minute, 100, logging.Initialize("error")).Sync(ctx)
+	require.Error(t, err)
+	state := store.state(t)
+	assert.True(t, state.FullSync)
+	assert.Equal(t, "page_2", state.Cursor)
+	startedAt := state.FullSyncStartedAt
+
+	// A new syncer continues the interrupted full pull
+	require.NoError(t, NewRosterSyncer(source, store, time.Minute, 100, logging.Initialize("error")).Sync(ctx))
+	assert.Equal(t, []string{"", "page_2", "page_2"}, source.requests)
+	require.Len(t, store.purgeBefore, 1)
+	assert.True(t, store.purgeBefore[0].Equal(startedAt), "the purge covers the whole interrupted pull")
+	assert.Contains(t, store.members, "user_1")
+	assert.Contains(t, store.members, "user_2")
+}
+
+func TestRosterSyncer_CursorExpired(t *testing.T) {
+	ctx := context.Background()
+	source := &fakeRosterSource{
+		pages:  testRosterPages(),
+		failAt: map[string]error{"delta_1": ErrRosterCursorExpired},
+	}
+	store := newMemoryRosterStore()
+	syncer := NewRosterSyncer(source, store, time.Minute, 100, logging.Initialize("error"))
+
+	require.NoError(t, syncer.Sync(ctx))
+	require.NoError(t, syncer.Sync(ctx))
+	assert.Equal(t, []string{"", "page_2", "delta_1", "", "page_2"}, source.requests)
+	assert.Len(t, store.purgeBefore, 2, "the restarted full pull purges stale mappings")
+	assert.Equal(t, "delta_1", store.state(t).Cursor)
+}
+
+func TestHTTPClient_GetRosterPage(t *testing.T) {
+	logger := logging.Initialize("error")
+	authManager := newMockAuthManager("test-device", "test-key")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/devices/roster", r.URL.Path)
+		switch r.URL.Query().Get("cursor") {
+		case "":
+			assert.Equal(t, "50", r.URL.Query().Get("limit"))
+			w.Header().Set("ETag", `"v1"`)
+			json.NewEncoder(w).Encode(RosterPage{
+				Members:    []RosterMember{{InternalUserID: "user_1", CardNumbers: []string{"100"}}},
+				NextCursor: "delta_1",
+			})
+		case "delta_1":
+			assert.Equal(t, `"v1"`, r.Header.Get("If-None-Match"))
+			w.WriteHeader(http.StatusNotModified)
+		default:
+			w.WriteHeader(http.StatusGone)
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewHTTPClient(&config.Config{ServerURL: server.URL}, authManager, logger)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	page, err := client.GetRosterPage(ctx, "", "", 50)
+	require.NoError(t, err)
+	assert.Equal(t, `"v1"`, page.ETag)
+	assert.Equal(t, "delta_1", page.NextCursor)
+	require.Len(t, page.Members, 1)
+	assert.Equal(t, []string{"100"}, page.Members[0].CardNumbers)
+
+	page, err = client.GetRosterPage(ctx, "delta_1", `"v1"`, 50)
+	require.NoError(t, err)
+	assert.True(t, page.NotModified)
+	assert.Equal(t, "delta_1", page.NextCursor)
+
+	_, err = client.GetRosterPage(ctx, "expired", "", 50)
+	assert.ErrorIs(t, err, ErrRosterCursorExpired)
+}
@@ -0,0 +1,445 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/sirupsen/logrus"
+	"gym-door-bridge/internal/client"
+	"gym-door-bridge/internal/logging"
+)
+
+// Channel modes
+const (
+	ModeDisconnected = "disconnected"
+	ModeWebSocket    = "websocket"
+	ModePolling      = "polling"
+)
+
+// Stream message types
+const (
+	messageCommand = "command"
+	messageAck     = "ack"
+)
+
+// CommandSource is the cloud client used to long-poll for commands when the
+// WebSocket is unavailable
+type CommandSource interface {
+	PollCommands(ctx context.Context, wait time.Duration) ([]client.CommandEnvelope, error)
+	AckCommand(ctx context.Context, ack *client.CommandAck) error
+}
+
+// ChannelConfig holds configuration for the command channel
+type ChannelConfig struct {
+	ServerURL        string        // Platform base URL; the stream URL uses the matching ws or wss scheme
+	StreamPath       string        // WebSocket path on the platform
+	PollWait         time.Duration // How long each long-poll waits for commands
+	FallbackAfter    int           // Consecutive WebSocket failures before falling back to long-polling
+	WebSocketRetry   time.Duration // How long to long-poll before trying the WebSocket again
+	MinBackoff       time.Duration // Initial reconnect delay
+	MaxBackoff       time.Duration // Maximum reconnect delay
+	PingInterval     time.Duration // WebSocket keepalive interval
+	HandshakeTimeout time.Duration
+	WriteTimeout     time.Duration
+}
+
+// DefaultChannelConfig returns a channel configuration with sensible defaults
+func DefaultChannelConfig() ChannelConfig {
+	return ChannelConfig{
+		StreamPath:       "/api/v1/devices/commands/stream",
+		PollWait:         25 * time.Second, // Below the HTTP client timeout
+		FallbackAfter:    3,
+		WebSocketRetry:   5 * time.Minute,
+		MinBackoff:       time.Second,
+		MaxBackoff:       time.Minute,
+		PingInterval:     30 * time.Second,
+		HandshakeTimeout: 10 * time.Second,
+		WriteTimeout:     10 * time.Second,
+	}
+}
+
+// streamMessage is a frame exchanged over the command WebSocket
+type streamMessage struct {
+	Type      string             `json:"type"` // "command" or "ack"
+	Payload   json.RawMessage    `json:"payload,omitempty"`
+	Signature string             `json:"signature,omitempty"`
+	Timestamp int64              `json:"timestamp,omitempty"`
+	Ack       *client.CommandAck `json:"ack,omitempty"`
+}
+
+// Channel keeps an outbound connection to the platform over which signed commands
+// are received and acknowledged. Bridges behind NAT cannot accept inbound
+// connections, so the bridge holds a WebSocket open and falls back to long-polling
+// when the WebSocket cannot be established.
+type Channel struct {
+	config      ChannelConfig
+	dispatcher  *Dispatcher
+	source      CommandSource
+	authManager client.AuthManager
+	dialer      *websocket.Dialer
+	logger      *logrus.Entry
+	mode        string
+	connectedAt time.Time
+	fetching    bool
+	mutex       sync.RWMutex
+}
+
+// NewChannel creates a new command channel
+func NewChannel(config ChannelConfig, dispatcher *Dispatcher, source CommandSource, authManager client.AuthManager, logger *logrus.Logger) *Channel {
+	defaults := DefaultChannelConfig()
+	if config.StreamPath == "" {
+		config.StreamPath = defaults.StreamPath
+	}
+	if config.PollWait <= 0 {
+		config.PollWait = defaults.PollWait
+	}
+	if config.FallbackAfter <= 0 {
+		config.FallbackAfter = defaults.FallbackAfter
+	}
+	if config.WebSocketRetry <= 0 {
+		config.WebSocketRetry = defaults.WebSocketRetry
+	}
+	if config.MinBackoff <= 0 {
+		config.MinBackoff = defaults.MinBackoff
+	}
+	if config.MaxBackoff < config.MinBackoff {
+		config.MaxBackoff = config.MinBackoff
+	}
+	if config.PingInterval <= 0 {
+		config.PingInterval = defaults.PingInterval
+	}
+	if config.HandshakeTimeout <= 0 {
+		config.HandshakeTimeout = defaults.HandshakeTimeout
+	}
+	if config.WriteTimeout <= 0 {
+		config.WriteTimeout = defaults.WriteTimeout
+	}
+
+	return &Channel{
+		config:      config,
+		dispatcher:  dispatcher,
+		source:      source,
+		authManager: authManager,
+		dialer: &websocket.Dialer{
+			Proxy:            http.ProxyFromEnvironment,
+			HandshakeTimeout: config.HandshakeTimeout,
+		},
+		logger: logging.NewServiceLogger(logger, "command-channel"),
+		mode:   ModeDisconnected,
+	}
+}
+
+// Start keeps the channel connected until the context is cancelled
+func (c *Channel) Start(ctx context.Context) {
+	c.logger.WithField("url", c.streamURL()).Info("Starting command channel")
+
+	backoff := c.config.MinBackoff
+	failures := 0
+	for {
+		connected, err := c.runWebSocket(ctx)
+		if ctx.Err() != nil {
+			c.logger.Info("Command channel stopped")
+			return
+		}
+
+		if connected {
+			failures = 0
+			backoff = c.config.MinBackoff
+		} else {
+			failures++
+		}
+		if err != nil {
+			c.logger.WithError(err).WithField("failures", failures).Warn("Command stream unavailable")
+		}
+
+		if failures >= c.config.FallbackAfter {
+			c.logger.WithField("retry_websocket_in", c.config.WebSocketRetry).Info("Falling back to long-polling for commands")
+			c.poll(ctx, c.config.WebSocketRetry)
+			if ctx.Err() != nil {
+				c.logger.Info("Command channel stopped")
+				return
+			}
+			// Try the WebSocket once more before polling again
+			failures = c.config.FallbackAfter - 1
+			continue
+		}
+
+		if !sleepContext(ctx, c.jitter(backoff)) {
+			c.logger.Info("Command channel stopped")
+			return
+		}
+		backoff *= 2
+		if backoff > c.config.MaxBackoff {
+			backoff = c.config.MaxBackoff
+		}
+	}
+}
+
+// runWebSocket connects the command stream and serves it until it closes. It
+// reports whether the connection was established.
+func (c *Channel) runWebSocket(ctx context.Context) (bool, error) {
+	if !c.authManager.IsAuthenticated() {
+		return false, fmt.Errorf("device is not paired")
+	}
+
+	signature, timestamp, err := c.authManager.SignRequest(nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to sign stream request: %w", err)
+	}
+	header := http.Header{}
+	header.Set("X-Device-ID", c.authManager.GetDeviceID())
+	header.Set("X-Signature", signature)
+	header.Set("X-Timestamp", fmt.Sprintf("%d", timestamp))
+
+	conn, resp, err := c.dialer.DialContext(ctx, c.streamURL(), header)
+	if err != nil {
+		if resp != nil {
+			return false, fmt.Errorf("failed to connect command stream: %w (HTTP %d)", err, resp.StatusCode)
+		}
+		return false, fmt.Errorf("failed to connect command stream: %w", err)
+	}
+	defer conn.Close()
+
+	c.setMode(ModeWebSocket)
+	defer c.setMode(ModeDisconnected)
+	c.logger.Info("Command stream connected")
+
+	connCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var writeMutex sync.Mutex
+	write := func(messageType int, data []byte) error {
+		writeMutex.Lock()
+		defer writeMutex.Unlock()
+
+		conn.SetWriteDeadline(time.Now().Add(c.config.WriteTimeout))
+		return conn.WriteMessage(messageType, data)
+	}
+
+	// Keep the connection alive and close it when the context ends so that the read below returns
+	go func() {
+		ticker := time.NewTicker(c.config.PingInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-connCtx.Done():
+				write(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+				conn.Close()
+				return
+			case <-ticker.C:
+				if err := write(websocket.PingMessage, nil); err != nil {
+					c.logger.WithError(err).Debug("Failed to ping command stream")
+				}
+			}
+		}
+	}()
+
+	readTimeout := 2 * c.config.PingInterval
+	conn.SetReadDeadline(time.Now().Add(readTimeout))
+	conn.SetPongHandler(func(string) error {
+		conn.SetReadDeadline(time.Now().Add(readTimeout))
+		return nil
+	})
+
+	// Commands run concurrently so a slow sync does not hold up an unlock
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			if ctx.Err() != nil {
+				return true, nil
+			}
+			return true, fmt.Errorf("command stream closed: %w", err)
+		}
+		conn.SetReadDeadline(time.Now().Add(readTimeout))
+
+		var message streamMessage
+		if err := json.Unmarshal(data, &message); err != nil {
+			c.logger.WithError(err).Warn("Ignoring malformed command stream message")
+			continue
+		}
+		if message.Type != messageCommand {
+			c.logger.WithField("type", message.Type).Debug("Ignoring command stream message")
+			continue
+		}
+
+		envelope := client.CommandEnvelope{
+			Payload:   message.Payload,
+			Signature: message.Signature,
+			Timestamp: message.Timestamp,
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			ack := c.dispatcher.Dispatch(connCtx, envelope)
+			if ack.CommandID == "" {
+				return // Nothing to acknowledge
+			}
+			data, err := json.Marshal(streamMessage{Type: messageAck, Ack: ack})
+			if err != nil {
+				c.logger.WithError(err).Error("Failed to marshal command acknowledgement")
+				return
+			}
+			if err := write(websocket.TextMessage, data); err != nil {
+				// The platform redelivers unacknowledged commands; the outcome is recorded
+				c.logger.WithError(err).WithField("command_id", ack.CommandID).Warn("Failed to send command acknowledgement")
+			}
+		}()
+	}
+}
+
+// poll long-polls for commands for the given duration
+func (c *Channel) poll(ctx context.Context, duration time.Duration) {
+	c.setMode(ModePolling)
+	defer c.setMode(ModeDisconnected)
+
+	deadline := time.Now().Add(duration)
+	backoff := c.config.MinBackoff
+	for ctx.Err() == nil && time.Now().Before(deadline) {
+		started := time.Now()
+		envelopes, err := c.source.PollCommands(ctx, c.config.PollWait)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.logger.WithError(err).Warn("Command poll failed")
+			if !sleepContext(ctx, c.jitter(backoff)) {
+				return
+			}
+			backoff *= 2
+			if backoff > c.config.MaxBackoff {
+				backoff = c.config.MaxBackoff
+			}
+			continue
+		}
+		backoff = c.config.MinBackoff
+
+		c.dispatchPolled(ctx, envelopes)
+
+		// Do not spin when the platform answers without waiting
+		if len(envelopes) == 0 && time.Since(started) < c.config.MinBackoff {
+			if !sleepContext(ctx, c.config.MinBackoff) {
+				return
+			}
+		}
+	}
+}
+
+// dispatchPolled runs commands received by polling and acknowledges them
+func (c *Channel) dispatchPolled(ctx context.Context, envelopes []client.CommandEnvelope) {
+	for _, envelope := range envelopes {
+		ack := c.dispatcher.Dispatch(ctx, envelope)
+		if ack.CommandID == "" {
+			continue
+		}
+		if err := c.source.AckCommand(ctx, ack); err != nil {
+			c.logger.WithError(err).WithField("command_id", ack.CommandID).Warn("Failed to send command acknowledgement")
+		}
+	}
+}
+
+// FetchPending fetches and runs waiting commands in the background when the
+// channel is between connection attempts. The platform reports waiting commands
+// in heartbeat responses, so they are not held up by a long reconnect backoff.
+// Commands arrive by themselves while the channel is connected or polling.
+func (c *Channel) FetchPending(ctx context.Context) {
+	c.mutex.Lock()
+	if c.mode != ModeDisconnected || c.fetching {
+		c.mutex.Unlock()
+		return
+	}
+	c.fetching = true
+	c.mutex.Unlock()
+
+	go func() {
+		defer func() {
+			c.mutex.Lock()
+			c.fetching = false
+			c.mutex.Unlock()
+		}()
+
+		envelopes, err := c.source.PollCommands(ctx, 0)
+		if err != nil {
+			c.logger.WithError(err).Warn("Failed to fetch pending commands")
+			return
+		}
+		c.dispatchPolled(ctx, envelopes)
+	}()
+}
+
+// streamURL returns the WebSocket URL of the command stream
+func (c *Channel) streamURL() string {
+	base := strings.TrimSuffix(c.config.ServerURL, "/")
+	switch {
+	case strings.HasPrefix(base, "https://"):
+		base = "wss://" + strings.TrimPrefix(base, "https://")
+	case strings.HasPrefix(base, "http://"):
+		base = "ws://" + strings.TrimPrefix(base, "http://")
+	}
+	return base + c.config.StreamPath
+}
+
+// jitter spreads reconnects by up to 20% to avoid every bridge reconnecting at once
+func (c *Channel) jitter(delay time.Duration) time.Duration {
+	return delay + time.Duration(rand.Float64()*0.2*float64(delay))
+}
+
+func (c *Channel) setMode(mode string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.mode = mode
+	if mode == ModeDisconnected {
+		c.connectedAt = time.Time{}
+	} else {
+		c.connectedAt = time.Now()
+	}
+}
+
+// GetStatus returns the channel mode and command statistics
+func (c *Channel) GetStatus() map[string]interface{} {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	status := map[string]interface{}{
+		"mode":  c.mode,
+		"stats": c.dispatcher.GetStats(),
+	}
+	if !c.connectedAt.IsZero() {
+		status["connectedAt"] = c.connectedAt
+	}
+	return status
+}
+
+// Mode returns how the channel is currently receiving commands
+func (c *Channel) Mode() string {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	return c.mode
+}
+
+// sleepContext waits for the duration and reports false if the context ended first
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
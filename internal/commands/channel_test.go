@@ -0,0 +1,241 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gym-door-bridge/internal/auth"
+	"gym-door-bridge/internal/client"
+)
+
+// testAuthManager signs stream requests with the test device key
+type testAuthManager struct {
+	authenticator *auth.HMACAuthenticator
+}
+
+func newTestAuthManager() *testAuthManager {
+	return &testAuthManager{authenticator: auth.NewHMACAuthenticator(testDeviceID, testDeviceKey)}
+}
+
+func (a *testAuthManager) IsAuthenticated() bool { return true }
+
+func (a *testAuthManager) GetDeviceID() string { return testDeviceID }
+
+func (a *testAuthManager) SignRequest(body []byte) (string, int64, error) {
+	timestamp := time.Now().Unix()
+	signature, err := a.authenticator.SignRequest(body, timestamp)
+	return signature, timestamp, err
+}
+
+// fakeSource serves queued commands to long-polls and records acknowledgements
+type fakeSource struct {
+	mu       sync.Mutex
+	pending  []client.CommandEnvelope
+	acks     []*client.CommandAck
+	polls    int
+	pollErrs int // Number of polls to fail before serving commands
+}
+
+func (s *fakeSource) PollCommands(ctx context.Context, wait time.Duration) ([]client.CommandEnvelope, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.polls++
+	if s.pollErrs > 0 {
+		s.pollErrs--
+		return nil, errors.New("platform unavailable")
+	}
+	pending := s.pending
+	s.pending = nil
+	return pending, nil
+}
+
+func (s *fakeSource) AckCommand(ctx context.Context, ack *client.CommandAck) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.acks = append(s.acks, ack)
+	return nil
+}
+
+func (s *fakeSource) ackCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.acks)
+}
+
+func testChannelConfig(serverURL string) ChannelConfig {
+	config := DefaultChannelConfig()
+	config.ServerURL = serverURL
+	config.MinBackoff = 10 * time.Millisecond
+	config.MaxBackoff = 20 * time.Millisecond
+	config.PollWait = time.Second
+	return config
+}
+
+func TestChannel_WebSocket(t *testing.T) {
+	dispatcher, _ := newTestDispatcher()
+	unlocked := make(chan string, 1)
+	dispatcher.Register(TypeUnlockDoor, func(ctx context.Context, params json.RawMessage) (interface{}, error) {
+		var request struct {
+			DoorID string `json:"doorId"`
+		}
+		json.Unmarshal(params, &request)
+		unlocked <- request.DoorID
+		return nil, nil
+	})
+
+	acks := make(chan streamMessage, 1)
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/devices/commands/stream", r.URL.Path)
+		assert.Equal(t, testDeviceID, r.Header.Get("X-Device-ID"))
+		assert.NotEmpty(t, r.Header.Get("X-Signature"))
+
+		conn, err := upgrader.Upgrade(w, r, nil)
+		require.NoError(t, err)
+		defer conn.Close()
+
+		envelope := signCommand(t, Command{ID: "cmd_ws", Type: TypeUnlockDoor, Params: json.RawMessage(`{"doorId":"front"}`)}, time.Now())
+		require.NoError(t, conn.WriteJSON(streamMessage{
+			Type:      messageCommand,
+			Payload:   envelope.Payload,
+			Signature: envelope.Signature,
+			Timestamp: envelope.Timestamp,
+		}))
+
+		var ack streamMessage
+		if err := conn.ReadJSON(&ack); err == nil {
+			acks <- ack
+		}
+		// Hold the connection until the bridge closes it
+		conn.ReadMessage()
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	channel := NewChannel(testChannelConfig(server.URL), dispatcher, &fakeSource{}, newTestAuthManager(), newTestLogger())
+	done := make(chan struct{})
+	go func() {
+		channel.Start(ctx)
+		close(done)
+	}()
+
+	select {
+	case doorID := <-unlocked:
+		assert.Equal(t, "front", doorID)
+	case <-time.After(5 * time.Second):
+		t.Fatal("command was not executed")
+	}
+
+	select {
+	case ack := <-acks:
+		assert.Equal(t, messageAck, ack.Type)
+		require.NotNil(t, ack.Ack)
+		assert.Equal(t, "cmd_ws", ack.Ack.CommandID)
+		assert.Equal(t, StatusSucceeded, ack.Ack.Status)
+	case <-time.After(5 * time.Second):
+		t.Fatal("command was not acknowledged")
+	}
+	assert.Equal(t, ModeWebSocket, channel.Mode())
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("channel did not stop")
+	}
+	assert.Equal(t, ModeDisconnected, channel.Mode())
+}
+
+func TestChannel_FallsBackToPolling(t *testing.T) {
+	// The platform has no command stream
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	dispatcher, _ := newTestDispatcher()
+	synced := make(chan struct{}, 1)
+	dispatcher.Register(TypeSyncMembers, func(ctx context.Context, params json.RawMessage) (interface{}, error) {
+		synced <- struct{}{}
+		return map[string]int{"members": 12}, nil
+	})
+
+	source := &fakeSource{
+		pollErrs: 1,
+		pending:  []client.CommandEnvelope{signCommand(t, Command{ID: "cmd_poll", Type: TypeSyncMembers}, time.Now())},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	channel := NewChannel(testChannelConfig(server.URL), dispatcher, source, newTestAuthManager(), newTestLogger())
+	go channel.Start(ctx)
+
+	select {
+	case <-synced:
+	case <-time.After(5 * time.Second):
+		t.Fatal("polled command was not executed")
+	}
+	assert.Eventually(t, func() bool { return source.ackCount() == 1 }, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, ModePolling, channel.Mode())
+
+	source.mu.Lock()
+	defer source.mu.Unlock()
+	assert.Equal(t, "cmd_poll", source.acks[0].CommandID)
+	assert.JSONEq(t, `{"members":12}`, string(source.acks[0].Result))
+	assert.GreaterOrEqual(t, source.polls, 2, "a failed poll is retried")
+}
+
+func TestChannel_FetchPending(t *testing.T) {
+	dispatcher, _ := newTestDispatcher()
+	synced := make(chan struct{}, 1)
+	dispatcher.Register(TypeSyncMembers, func(ctx context.Context, params json.RawMessage) (interface{}, error) {
+		synced <- struct{}{}
+		return nil, nil
+	})
+
+	source := &fakeSource{
+		pending: []client.CommandEnvelope{signCommand(t, Command{ID: "cmd_pending", Type: TypeSyncMembers}, time.Now())},
+	}
+
+	// The channel is waiting to reconnect, so the pending command is fetched directly
+	channel := NewChannel(testChannelConfig("http://platform.invalid"), dispatcher, source, newTestAuthManager(), newTestLogger())
+	channel.FetchPending(context.Background())
+
+	select {
+	case <-synced:
+	case <-time.After(5 * time.Second):
+		t.Fatal("pending command was not executed")
+	}
+	assert.Eventually(t, func() bool { return source.ackCount() == 1 }, 5*time.Second, 10*time.Millisecond)
+
+	// Commands arrive by themselves while connected
+	channel.setMode(ModeWebSocket)
+	channel.FetchPending(context.Background())
+	time.Sleep(50 * time.Millisecond)
+
+	source.mu.Lock()
+	defer source.mu.Unlock()
+	assert.Equal(t, 1, source.polls)
+}
+
+func TestChannel_StreamURL(t *testing.T) {
+	tests := map[string]string{
+		"https://api.example.com": "wss://api.example.com/api/v1/devices/commands/stream",
+		"http://localhost:8080/":  "ws://localhost:8080/api/v1/devices/commands/stream",
+	}
+	for serverURL, want := range tests {
+		channel := NewChannel(ChannelConfig{ServerURL: serverURL}, nil, nil, nil, newTestLogger())
+		assert.Equal(t, want, channel.streamURL())
+	}
+}
@@ -0,0 +1,321 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gym-door-bridge/internal/client"
+	"gym-door-bridge/internal/database"
+	"gym-door-bridge/internal/logging"
+)
+
+// Command types accepted from the platform
+const (
+	TypeUnlockDoor     = "unlock_door"
+	TypeReloadConfig   = "reload_config"
+	TypeSyncMembers    = "sync_members"
+	TypeRunDiagnostics = "run_diagnostics"
+	TypeRestartAdapter = "restart_adapter"
+)
+
+// Acknowledgement statuses
+const (
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+	StatusRejected  = "rejected" // Not executed: malformed, unsigned, stale or unsupported
+)
+
+const (
+	// maxClockSkew is how far in the future a command timestamp may be
+	maxClockSkew = 30 * time.Second
+	// processedRetention is how long command outcomes are kept for redeliveries.
+	// It only needs to exceed the maximum command age, as older commands are
+	// rejected as stale before the store is consulted.
+	processedRetention = 24 * time.Hour
+	purgeInterval      = time.Hour
+)
+
+// Command is the signed payload of a command envelope
+type Command struct {
+	ID     string          `json:"id"`
+	Type   string          `json:"type"`
+	Params json.RawMessage `json:"params,omitempty"`
+}
+
+// Handler executes a command and returns a result that is marshalled to JSON in
+// the acknowledgement
+type Handler func(ctx context.Context, params json.RawMessage) (interface{}, error)
+
+// Verifier validates command signatures made with the device key
+type Verifier interface {
+	ValidateSignature(body []byte, timestamp int64, signature string) error
+}
+
+// Store records command outcomes so that redelivered commands are not executed twice
+type Store interface {
+	RecordProcessedCommand(command *database.ProcessedCommand) error
+	GetProcessedCommand(commandID string) (*database.ProcessedCommand, error)
+	PurgeProcessedCommands(before time.Time) (int64, error)
+}
+
+// Stats contains statistics about received commands
+type Stats struct {
+	TotalReceived   int64 `json:"totalReceived"`
+	TotalSucceeded  int64 `json:"totalSucceeded"`
+	TotalFailed     int64 `json:"totalFailed"`
+	TotalRejected   int64 `json:"totalRejected"`
+	TotalDuplicates int64 `json:"totalDuplicates"`
+	LastCommandAt   int64 `json:"lastCommandAt"` // Unix timestamp
+}
+
+// Dispatcher verifies signed commands, runs them through the registered handlers
+// exactly once and builds their acknowledgements
+type Dispatcher struct {
+	verifier  Verifier
+	store     Store
+	maxAge    time.Duration
+	handlers  map[string]Handler
+	inFlight  map[string]chan struct{} // Commands being executed, closed when done
+	lastPurge time.Time
+	stats     Stats
+	logger    *logrus.Entry
+	mutex     sync.Mutex
+}
+
+// NewDispatcher creates a new command dispatcher. Commands signed more than maxAge
+// ago are rejected as stale.
+func NewDispatcher(verifier Verifier, store Store, maxAge time.Duration, logger *logrus.Logger) *Dispatcher {
+	if maxAge <= 0 {
+		maxAge = time.Minute
+	}
+
+	return &Dispatcher{
+		verifier: verifier,
+		store:    store,
+		maxAge:   maxAge,
+		handlers: make(map[string]Handler),
+		inFlight: make(map[string]chan struct{}),
+		logger:   logging.NewServiceLogger(logger, "command-dispatcher"),
+	}
+}
+
+// Register sets the handler for a command type
+func (d *Dispatcher) Register(commandType string, handler Handler) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.handlers[commandType] = handler
+}
+
+// Dispatch verifies and executes a command envelope and returns its
+// acknowledgement. A command that was already processed is not executed again;
+// its original outcome is returned marked as a duplicate, and one whose outcome
+// cannot be looked up is refused as retryable. The acknowledgement has
+// no command ID when the payload could not be parsed.
+func (d *Dispatcher) Dispatch(ctx context.Context, envelope client.CommandEnvelope) *client.CommandAck {
+	d.mutex.Lock()
+	d.stats.TotalReceived++
+	d.stats.LastCommandAt = time.Now().Unix()
+	d.mutex.Unlock()
+
+	var command Command
+	if err := json.Unmarshal(envelope.Payload, &command); err != nil || command.ID == "" {
+		return d.reject(command, fmt.Errorf("malformed command payload"))
+	}
+
+	if err := d.verify(envelope); err != nil {
+		return d.reject(command, err)
+	}
+
+	d.mutex.Lock()
+	handler, ok := d.handlers[command.Type]
+	d.mutex.Unlock()
+	if !ok {
+		return d.reject(command, fmt.Errorf("unsupported command type %q", command.Type))
+	}
+
+	// Wait for a concurrent delivery of the same command to finish
+	for {
+		d.mutex.Lock()
+		done, running := d.inFlight[command.ID]
+		if !running {
+			d.inFlight[command.ID] = make(chan struct{})
+			d.mutex.Unlock()
+			break
+		}
+		d.mutex.Unlock()
+
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return d.retry(command, fmt.Errorf("command is already being processed"))
+		}
+	}
+	defer d.finish(command.ID)
+
+	// Without the lookup the command may already have run, so it is refused
+	// rather than risk unlocking a door twice
+	processed, err := d.store.GetProcessedCommand(command.ID)
+	if err != nil {
+		return d.retry(command, fmt.Errorf("failed to look up processed command: %w", err))
+	}
+	if processed != nil {
+		d.mutex.Lock()
+		d.stats.TotalDuplicates++
+		d.mutex.Unlock()
+
+		d.logger.WithFields(logrus.Fields{
+			"command_id": command.ID,
+			"type":       command.Type,
+		}).Info("Duplicate command acknowledged without executing it again")
+
+		ack := &client.CommandAck{
+			CommandID:   processed.CommandID,
+			Status:      processed.Status,
+			Error:       processed.Error,
+			Duplicate:   true,
+			CompletedAt: processed.ProcessedAt.UTC().Format(time.RFC3339),
+		}
+		if processed.Result != "" {
+			ack.Result = json.RawMessage(processed.Result)
+		}
+		return ack
+	}
+
+	return d.execute(ctx, command, handler)
+}
+
+// verify checks the command is fresh and signed with the device key
+func (d *Dispatcher) verify(envelope client.CommandEnvelope) error {
+	signedAt := time.Unix(envelope.Timestamp, 0)
+	age := time.Since(signedAt)
+	if age > d.maxAge {
+		return fmt.Errorf("stale command signed %s ago", age.Truncate(time.Second))
+	}
+	if age < -maxClockSkew {
+		return fmt.Errorf("command timestamp is in the future")
+	}
+
+	if err := d.verifier.ValidateSignature(envelope.Payload, envelope.Timestamp, envelope.Signature); err != nil {
+		return fmt.Errorf("invalid command signature: %w", err)
+	}
+
+	return nil
+}
+
+// execute runs a verified command and records its outcome
+func (d *Dispatcher) execute(ctx context.Context, command Command, handler Handler) *client.CommandAck {
+	logger := d.logger.WithFields(logrus.Fields{
+		"command_id": command.ID,
+		"type":       command.Type,
+	})
+	logger.Info("Executing remote command")
+
+	start := time.Now()
+	ack := &client.CommandAck{CommandID: command.ID, Status: StatusSucceeded}
+
+	result, err := handler(ctx, command.Params)
+	if err == nil && result != nil {
+		ack.Result, err = json.Marshal(result)
+		if err != nil {
+			err = fmt.Errorf("failed to marshal command result: %w", err)
+		}
+	}
+
+	completedAt := time.Now()
+	ack.CompletedAt = completedAt.UTC().Format(time.RFC3339)
+
+	d.mutex.Lock()
+	if err != nil {
+		ack.Status = StatusFailed
+		ack.Error = err.Error()
+		ack.Result = nil
+		d.stats.TotalFailed++
+	} else {
+		d.stats.TotalSucceeded++
+	}
+	d.mutex.Unlock()
+
+	if err != nil {
+		logger.WithError(err).Warn("Remote command failed")
+	} else {
+		logger.WithField("duration", completedAt.Sub(start)).Info("Remote command completed")
+	}
+
+	if err := d.store.RecordProcessedCommand(&database.ProcessedCommand{
+		CommandID:   command.ID,
+		CommandType: command.Type,
+		Status:      ack.Status,
+		Result:      string(ack.Result),
+		Error:       ack.Error,
+		ProcessedAt: completedAt,
+	}); err != nil {
+		logger.WithError(err).Error("Failed to record processed command")
+	}
+
+	d.purge(completedAt)
+	return ack
+}
+
+// reject builds the acknowledgement for a command that was not executed
+func (d *Dispatcher) reject(command Command, reason error) *client.CommandAck {
+	d.mutex.Lock()
+	d.stats.TotalRejected++
+	d.mutex.Unlock()
+
+	d.logger.WithError(reason).WithFields(logrus.Fields{
+		"command_id": command.ID,
+		"type":       command.Type,
+	}).Warn("Remote command rejected")
+
+	return &client.CommandAck{
+		CommandID:   command.ID,
+		Status:      StatusRejected,
+		Error:       reason.Error(),
+		CompletedAt: time.Now().UTC().Format(time.RFC3339),
+	}
+}
+
+// retry rejects a command that was not executed because of a transient
+// failure, so the platform delivers it again
+func (d *Dispatcher) retry(command Command, reason error) *client.CommandAck {
+	ack := d.reject(command, reason)
+	ack.Retryable = true
+	return ack
+}
+
+// finish releases commands waiting on a concurrent delivery
+func (d *Dispatcher) finish(commandID string) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	close(d.inFlight[commandID])
+	delete(d.inFlight, commandID)
+}
+
+// purge drops old command outcomes at most once per purge interval
+func (d *Dispatcher) purge(now time.Time) {
+	d.mutex.Lock()
+	if now.Sub(d.lastPurge) < purgeInterval {
+		d.mutex.Unlock()
+		return
+	}
+	d.lastPurge = now
+	d.mutex.Unlock()
+
+	if _, err := d.store.PurgeProcessedCommands(now.Add(-processedRetention)); err != nil {
+		d.logger.WithError(err).Warn("Failed to purge processed commands")
+	}
+}
+
+// GetStats returns command statistics
+func (d *Dispatcher) GetStats() Stats {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	return d.stats
+}
@@ -0,0 +1,233 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gym-door-bridge/internal/auth"
+	"gym-door-bridge/internal/client"
+	"gym-door-bridge/internal/database"
+)
+
+const (
+	testDeviceID  = "device_123"
+	testDeviceKey = "device-secret-key"
+)
+
+// memoryStore records processed commands in memory
+type memoryStore struct {
+	mu       sync.Mutex
+	commands map[string]database.ProcessedCommand
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{commands: make(map[string]database.ProcessedCommand)}
+}
+
+func (s *memoryStore) RecordProcessedCommand(command *database.ProcessedCommand) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.commands[command.CommandID] = *command
+	return nil
+}
+
+func (s *memoryStore) GetProcessedCommand(commandID string) (*database.ProcessedCommand, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	command, ok := s.commands[commandID]
+	if !ok {
+		return nil, nil
+	}
+	return &command, nil
+}
+
+func (s *memoryStore) PurgeProcessedCommands(before time.Time) (int64, error) {
+	return 0, nil
+}
+
+// failingStore is a store whose lookups fail, as when the database is locked
+type failingStore struct {
+	*memoryStore
+}
+
+func (s *failingStore) GetProcessedCommand(commandID string) (*database.ProcessedCommand, error) {
+	return nil, errors.New("database is locked")
+}
+
+func newTestLogger() *logrus.Logger {
+	logger := logrus.New()
+	logger.SetLevel(logrus.ErrorLevel)
+	return logger
+}
+
+func newTestDispatcher() (*Dispatcher, *memoryStore) {
+	store := newMemoryStore()
+	verifier := auth.NewHMACAuthenticator(testDeviceID, testDeviceKey)
+	return NewDispatcher(verifier, store, time.Minute, newTestLogger()), store
+}
+
+// signCommand builds an envelope signed with the test device key at the given time
+func signCommand(t *testing.T, command Command, signedAt time.Time) client.CommandEnvelope {
+	t.Helper()
+
+	payload, err := json.Marshal(command)
+	require.NoError(t, err)
+	signature, err := auth.NewHMACAuthenticator(testDeviceID, testDeviceKey).SignRequest(payload, signedAt.Unix())
+	require.NoError(t, err)
+
+	return client.CommandEnvelope{Payload: payload, Signature: signature, Timestamp: signedAt.Unix()}
+}
+
+func TestDispatcher_ExecutesOnce(t *testing.T) {
+	ctx := context.Background()
+	dispatcher, store := newTestDispatcher()
+
+	calls := 0
+	dispatcher.Register(TypeUnlockDoor, func(ctx context.Context, params json.RawMessage) (interface{}, error) {
+		calls++
+		var request struct {
+			DoorID string `json:"doorId"`
+		}
+		require.NoError(t, json.Unmarshal(params, &request))
+		return map[string]string{"doorId": request.DoorID}, nil
+	})
+
+	envelope := signCommand(t, Command{ID: "cmd_1", Type: TypeUnlockDoor, Params: json.RawMessage(`{"doorId":"front"}`)}, time.Now())
+
+	ack := dispatcher.Dispatch(ctx, envelope)
+	assert.Equal(t, "cmd_1", ack.CommandID)
+	assert.Equal(t, StatusSucceeded, ack.Status)
+	assert.JSONEq(t, `{"doorId":"front"}`, string(ack.Result))
+	assert.False(t, ack.Duplicate)
+	assert.Equal(t, StatusSucceeded, store.commands["cmd_1"].Status)
+
+	// A redelivery repeats the outcome without unlocking again
+	ack = dispatcher.Dispatch(ctx, envelope)
+	assert.Equal(t, StatusSucceeded, ack.Status)
+	assert.True(t, ack.Duplicate)
+	assert.JSONEq(t, `{"doorId":"front"}`, string(ack.Result))
+	assert.Equal(t, 1, calls)
+
+	stats := dispatcher.GetStats()
+	assert.Equal(t, int64(2), stats.TotalReceived)
+	assert.Equal(t, int64(1), stats.TotalSucceeded)
+	assert.Equal(t, int64(1), stats.TotalDuplicates)
+}
+
+func TestDispatcher_ConcurrentDeliveries(t *testing.T) {
+	ctx := context.Background()
+	dispatcher, _ := newTestDispatcher()
+
+	var mu sync.Mutex
+	calls := 0
+	release := make(chan struct{})
+	dispatcher.Register(TypeSyncMembers, func(ctx context.Context, params json.RawMessage) (interface{}, error) {
+		mu.Lock()
+		calls++
+		mu.Unlock()
+		<-release
+		return nil, nil
+	})
+
+	envelope := signCommand(t, Command{ID: "cmd_sync", Type: TypeSyncMembers}, time.Now())
+
+	acks := make(chan *ackResult, 2)
+	for i := 0; i < 2; i++ {
+		go func() { acks <- &ackResult{dispatcher.Dispatch(ctx, envelope)} }()
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+
+	first, second := <-acks, <-acks
+	assert.Equal(t, StatusSucceeded, first.ack.Status)
+	assert.Equal(t, StatusSucceeded, second.ack.Status)
+	assert.True(t, first.ack.Duplicate != second.ack.Duplicate, "exactly one delivery should execute")
+	assert.Equal(t, 1, calls)
+}
+
+type ackResult struct {
+	ack *client.CommandAck
+}
+
+func TestDispatcher_Rejects(t *testing.T) {
+	ctx := context.Background()
+	dispatcher, store := newTestDispatcher()
+	dispatcher.Register(TypeUnlockDoor, func(ctx context.Context, params json.RawMessage) (interface{}, error) {
+		t.Error("rejected commands must not be executed")
+		return nil, nil
+	})
+
+	now := time.Now()
+	stale := signCommand(t, Command{ID: "cmd_stale", Type: TypeUnlockDoor}, now.Add(-2*time.Minute))
+	future := signCommand(t, Command{ID: "cmd_future", Type: TypeUnlockDoor}, now.Add(2*time.Minute))
+	forged := signCommand(t, Command{ID: "cmd_forged", Type: TypeUnlockDoor}, now)
+	forged.Signature = "0000"
+	tampered := signCommand(t, Command{ID: "cmd_tampered", Type: TypeUnlockDoor}, now)
+	tampered.Payload = json.RawMessage(`{"id":"cmd_tampered","type":"unlock_door","params":{"doorId":"back"}}`)
+
+	tests := []struct {
+		name     string
+		envelope client.CommandEnvelope
+		wantID   string
+		wantErr  string
+	}{
+		{name: "stale", envelope: stale, wantID: "cmd_stale", wantErr: "stale"},
+		{name: "future", envelope: future, wantID: "cmd_future", wantErr: "future"},
+		{name: "forged", envelope: forged, wantID: "cmd_forged", wantErr: "signature"},
+		{name: "tampered", envelope: tampered, wantID: "cmd_tampered", wantErr: "signature"},
+		{name: "unsupported", envelope: signCommand(t, Command{ID: "cmd_reboot", Type: "reboot"}, now), wantID: "cmd_reboot", wantErr: "unsupported"},
+		{name: "malformed", envelope: client.CommandEnvelope{Payload: json.RawMessage(`not json`), Timestamp: now.Unix()}, wantErr: "malformed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ack := dispatcher.Dispatch(ctx, tt.envelope)
+			assert.Equal(t, StatusRejected, ack.Status)
+			assert.Equal(t, tt.wantID, ack.CommandID)
+			assert.Contains(t, ack.Error, tt.wantErr)
+		})
+	}
+
+	assert.Empty(t, store.commands, "rejections are not recorded")
+	assert.Equal(t, int64(len(tests)), dispatcher.GetStats().TotalRejected)
+}
+
+func TestDispatcher_HandlerFailure(t *testing.T) {
+	ctx := context.Background()
+	dispatcher, store := newTestDispatcher()
+	dispatcher.Register(TypeRestartAdapter, func(ctx context.Context, params json.RawMessage) (interface{}, error) {
+		return nil, errors.New("adapter not found")
+	})
+
+	ack := dispatcher.Dispatch(ctx, signCommand(t, Command{ID: "cmd_restart", Type: TypeRestartAdapter}, time.Now()))
+	assert.Equal(t, StatusFailed, ack.Status)
+	assert.Equal(t, "adapter not found", ack.Error)
+	assert.Equal(t, StatusFailed, store.commands["cmd_restart"].Status)
+	assert.Equal(t, int64(1), dispatcher.GetStats().TotalFailed)
+}
+
+func TestDispatcher_RefusesWhenStoreFails(t *testing.T) {
+	ctx := context.Background()
+	store := &failingStore{memoryStore: newMemoryStore()}
+	dispatcher := NewDispatcher(auth.NewHMACAuthenticator(testDeviceID, testDeviceKey), store, time.Minute, newTestLogger())
+	dispatcher.Register(TypeUnlockDoor, func(ctx context.Context, params json.RawMessage) (interface{}, error) {
+		t.Error("a command must not run when its processed state is unknown")
+		return nil, nil
+	})
+
+	ack := dispatcher.Dispatch(ctx, signCommand(t, Command{ID: "cmd_unlock", Type: TypeUnlockDoor}, time.Now()))
+	assert.Equal(t, "cmd_unlock", ack.CommandID)
+	assert.Equal(t, StatusRejected, ack.Status)
+	assert.True(t, ack.Retryable)
+	assert.Contains(t, ack.Error, "database is locked")
+	assert.Empty(t, store.commands, "the refusal is not recorded, so a redelivery can run")
+	assert.Equal(t, int64(1), dispatcher.GetStats().TotalRejected)
+}
@@ -6,7 +6,11 @@ import (
 	"path/filepath"
 	"strings"
 	"time"
+	_ "time/tzdata" // Windows hosts have no timezone database for time.LoadLocation
 
+	"gym-door-bridge/internal/adapters/biometric"
+	"gym-door-bridge/internal/adapters/input"
+	"gym-door-bridge/internal/adapters/relay"
 	"gym-door-bridge/internal/types"
 
 	"github.com/spf13/viper"
@@ -24,13 +28,28 @@ type Config struct {
 	// Performance tier configuration
 	Tier string `mapstructure:"tier"` // lite, normal, full
 
+	// IANA timezone of the site, used for door schedules; empty means local time
+	Timezone string `mapstructure:"timezone"`
+
 	// Queue configuration
 	QueueMaxSize      int `mapstructure:"queue_max_size"`
 	HeartbeatInterval int `mapstructure:"heartbeat_interval"` // seconds
 
+	// Events evicted first when the offline queue is full: "priority" (the
+	// default) keeps door alarms and denied entries longest, "fifo" evicts the
+	// oldest events
+	QueueRetentionPolicy string `mapstructure:"queue_retention_policy"`
+
 	// Door control configuration
 	UnlockDuration int `mapstructure:"unlock_duration"` // milliseconds
 
+	// Relay output wired to the door lock (driver, pulseMs, activeLow, mode and driver settings)
+	DoorRelay map[string]interface{} `mapstructure:"door_relay"`
+
+	// Doors served by this bridge; when empty a single "main" door is derived from
+	// unlock_duration, door_relay and the enabled adapters
+	Doors []DoorConfig `mapstructure:"doors"`
+
 	// Database configuration
 	DatabasePath string `mapstructure:"database_path"`
 
@@ -38,20 +57,50 @@ type Config struct {
 	LogLevel string `mapstructure:"log_level"`
 	LogFile  string `mapstructure:"log_file"`
 
-	// Adapter configuration
+	// Adapter configuration, keyed by adapter name; the "type" setting of an
+	// adapter whose name is not its type selects the adapter type
 	EnabledAdapters []string                          `mapstructure:"enabled_adapters"`
 	AdapterConfigs  map[string]map[string]interface{} `mapstructure:"adapter_configs"`
 
 	// Update configuration
-	UpdatesEnabled    bool   `mapstructure:"updates_enabled"`
-	UpdateManifestURL string `mapstructure:"update_manifest_url"`
-	UpdatePublicKey   string `mapstructure:"update_public_key"`
+	UpdatesEnabled      bool   `mapstructure:"updates_enabled"`
+	UpdateManifestURL   string `mapstructure:"update_manifest_url"`
+	UpdatePublicKey     string `mapstructure:"update_public_key"`
+	UpdateChannel       string `mapstructure:"update_channel"`        // stable, beta or pinned
+	UpdatePinnedVersion string `mapstructure:"update_pinned_version"` // Release installed on the pinned channel
+	UpdateCheckInterval int    `mapstructure:"update_check_interval"` // seconds, 0 checks daily
+
+	// Daily "HH:MM" window in the site timezone in which downloaded updates are
+	// installed; empty start and end install at any time
+	UpdateWindowStart string `mapstructure:"update_window_start"`
+	UpdateWindowEnd   string `mapstructure:"update_window_end"`
 
 	// API server configuration
 	APIServer APIServerConfig `mapstructure:"api_server"`
 
+	// Local access-decision configuration
+	AccessControl AccessControlConfig `mapstructure:"access_control"`
+
+	// Outbound channel receiving remote commands from the platform
+	CommandChannel CommandChannelConfig `mapstructure:"command_channel"`
+
+	// Member roster pulled from the platform into the external user mappings
+	RosterSync RosterSyncConfig `mapstructure:"roster_sync"`
+
+	// Roster members pushed down to biometric terminals
+	Provisioning ProvisioningConfig `mapstructure:"provisioning"`
+
+	// Fingerprint templates backed up and shared between biometric terminals
+	TemplateBackup TemplateBackupConfig `mapstructure:"template_backup"`
+
+	// Desired configuration published by the platform
+	ConfigSync ConfigSyncConfig `mapstructure:"config_sync"`
+
 	// Installation metadata
 	Installation InstallationMetadata `mapstructure:"installation"`
+
+	// Path of the file the configuration was read from, empty when no file was found
+	file string
 }
 
 // APIServerConfig holds API server specific configuration
@@ -114,6 +163,77 @@ type SecurityConfig struct {
 	ReferrerPolicy        string `mapstructure:"referrer_policy"`
 }
 
+// AccessControlConfig holds configuration for local offline access decisions
+type AccessControlConfig struct {
+	Enabled             bool               `mapstructure:"enabled"`
+	AllowUnknownMembers bool               `mapstructure:"allow_unknown_members"` // Grant access when a member has no cached entitlement
+	ExpiryGracePeriod   int                `mapstructure:"expiry_grace_period"`   // seconds
+	UnlockOnExit        bool               `mapstructure:"unlock_on_exit"`
+	MaxEventAge         int                `mapstructure:"max_event_age"` // seconds after which an event no longer unlocks the door
+	Timezone            string             `mapstructure:"timezone"`      // IANA timezone for allowed hours, empty means local time
+	SyncInterval        int                `mapstructure:"sync_interval"` // seconds
+	AntiPassback        AntiPassbackConfig `mapstructure:"anti_passback"`
+}
+
+// AntiPassbackConfig configures anti-passback: members must alternate entry and
+// exit in each zone
+type AntiPassbackConfig struct {
+	Mode      string `mapstructure:"mode"`       // off, soft (allow and flag) or hard (deny)
+	ResetTime string `mapstructure:"reset_time"` // Daily "HH:MM" in the site timezone when states are forgotten, empty never
+}
+
+// CommandChannelConfig holds configuration for the cloud command channel
+type CommandChannelConfig struct {
+	Enabled       bool `mapstructure:"enabled"`
+	MaxCommandAge int  `mapstructure:"max_command_age"` // seconds after which a signed command is rejected as stale
+}
+
+// RosterSyncConfig holds configuration for the member roster sync
+type RosterSyncConfig struct {
+	Enabled  bool `mapstructure:"enabled"`
+	Interval int  `mapstructure:"interval"`  // seconds between delta syncs
+	PageSize int  `mapstructure:"page_size"` // members requested per page
+}
+
+// ProvisioningConfig holds configuration for provisioning members on biometric terminals
+type ProvisioningConfig struct {
+	Enabled          bool   `mapstructure:"enabled"`
+	Interval         int    `mapstructure:"interval"`          // seconds between reconciliations
+	InactiveAction   string `mapstructure:"inactive_action"`   // disable or delete terminal users of inactive members
+	DefaultPrivilege int    `mapstructure:"default_privilege"` // privilege of newly created terminal users
+}
+
+// TemplateBackupConfig holds configuration for backing up fingerprint templates and
+// copying them between the biometric terminals of the site
+type TemplateBackupConfig struct {
+	Consent  bool `mapstructure:"consent"`  // the site has opted in to storing member templates
+	Interval int  `mapstructure:"interval"` // seconds between template syncs
+}
+
+// ConfigSyncConfig holds configuration for applying the configuration the platform publishes
+type ConfigSyncConfig struct {
+	Enabled    bool `mapstructure:"enabled"`
+	History    int  `mapstructure:"history"`     // config versions kept for rollback
+	SettleTime int  `mapstructure:"settle_time"` // seconds adapters get to become healthy before a change is rolled back
+}
+
+// DoorConfig describes a physical door, the reader adapters mounted at it and its lock output
+type DoorConfig struct {
+	ID              string                 `mapstructure:"id"`
+	Name            string                 `mapstructure:"name"`
+	Zone            string                 `mapstructure:"zone"`
+	Adapters        []string               `mapstructure:"adapters"`          // Reader adapters whose events belong to this door
+	Relay           map[string]interface{} `mapstructure:"relay"`             // Relay output settings, same format as door_relay
+	UnlockDuration  int                    `mapstructure:"unlock_duration"`   // milliseconds, 0 uses the global unlock_duration
+	Contact         map[string]interface{} `mapstructure:"contact"`           // Door position sensor input, active while the door is open
+	Rex             map[string]interface{} `mapstructure:"rex"`               // Request-to-exit button input
+	RexUnlocks      bool                   `mapstructure:"rex_unlocks"`       // Pressing the exit button also pulses the lock
+	HeldOpenTimeout int                    `mapstructure:"held_open_timeout"` // seconds before a held-open alarm, 0 disables
+}
+
+// DefaultDoorID is the ID of the door derived when no doors are configured
+const DefaultDoorID = "main"
+
 // InstallationMetadata holds information about how the bridge was installed
 type InstallationMetadata struct {
 	Method      string `mapstructure:"method"`       // "automated", "manual", "upgrade"
@@ -128,19 +248,27 @@ type InstallationMetadata struct {
 // DefaultConfig returns a configuration with default values
 func DefaultConfig() *Config {
 	return &Config{
-		ServerURL:         "https://repset.onezy.in",
-		Tier:              "normal",
-		QueueMaxSize:      10000,
-		HeartbeatInterval: 60,
-		UnlockDuration:    3000,
-		DatabasePath:      "./bridge.db",
-		LogLevel:          "info",
-		LogFile:           "",
-		EnabledAdapters:   []string{"simulator"},
-		AdapterConfigs:    make(map[string]map[string]interface{}),
-		UpdatesEnabled:    true,
-		UpdateManifestURL: "",
-		UpdatePublicKey:   "",
+		ServerURL:            "https://repset.onezy.in",
+		Tier:                 "normal",
+		Timezone:             "",
+		QueueMaxSize:         10000,
+		HeartbeatInterval:    60,
+		QueueRetentionPolicy: "priority",
+		UnlockDuration:       3000,
+		DoorRelay:            make(map[string]interface{}),
+		DatabasePath:         "./bridge.db",
+		LogLevel:             "info",
+		LogFile:              "",
+		EnabledAdapters:      []string{"simulator"},
+		AdapterConfigs:       make(map[string]map[string]interface{}),
+		UpdatesEnabled:       true,
+		UpdateManifestURL:    "",
+		UpdatePublicKey:      "",
+		UpdateChannel:        "stable",
+		UpdatePinnedVersion:  "",
+		UpdateCheckInterval:  21600,
+		UpdateWindowStart:    "02:00",
+		UpdateWindowEnd:      "05:00",
 		APIServer: APIServerConfig{
 			Enabled:      true,
 			Port:         8081,
@@ -187,6 +315,43 @@ func DefaultConfig() *Config {
 				ReferrerPolicy:        "strict-origin-when-cross-origin",
 			},
 		},
+		AccessControl: AccessControlConfig{
+			Enabled:             true,
+			AllowUnknownMembers: false,
+			ExpiryGracePeriod:   0,
+			UnlockOnExit:        true,
+			MaxEventAge:         5,
+			Timezone:            "",
+			SyncInterval:        300,
+			AntiPassback: AntiPassbackConfig{
+				Mode:      "off",
+				ResetTime: "03:00",
+			},
+		},
+		CommandChannel: CommandChannelConfig{
+			Enabled:       true,
+			MaxCommandAge: 60,
+		},
+		RosterSync: RosterSyncConfig{
+			Enabled:  true,
+			Interval: 300,
+			PageSize: 500,
+		},
+		Provisioning: ProvisioningConfig{
+			Enabled:          false,
+			Interval:         900,
+			InactiveAction:   "disable",
+			DefaultPrivilege: 0,
+		},
+		TemplateBackup: TemplateBackupConfig{
+			Consent:  false,
+			Interval: 3600,
+		},
+		ConfigSync: ConfigSyncConfig{
+			Enabled:    true,
+			History:    10,
+			SettleTime: 30,
+		},
 		Installation: InstallationMetadata{
 			Method:      "manual",
 			Version:     "",
@@ -238,6 +403,7 @@ func Load(configFile string) (*Config, error) {
 		}
 		// Config file not found is OK, we'll use defaults
 	}
+	cfg.file = v.ConfigFileUsed()
 
 	// Unmarshal into struct
 	if err := v.Unmarshal(cfg); err != nil {
@@ -256,9 +422,12 @@ func Load(configFile string) (*Config, error) {
 func setDefaults(v *viper.Viper, cfg *Config) {
 	v.SetDefault("server_url", cfg.ServerURL)
 	v.SetDefault("tier", cfg.Tier)
+	v.SetDefault("timezone", cfg.Timezone)
 	v.SetDefault("queue_max_size", cfg.QueueMaxSize)
+	v.SetDefault("queue_retention_policy", cfg.QueueRetentionPolicy)
 	v.SetDefault("heartbeat_interval", cfg.HeartbeatInterval)
 	v.SetDefault("unlock_duration", cfg.UnlockDuration)
+	v.SetDefault("door_relay", cfg.DoorRelay)
 	v.SetDefault("database_path", cfg.DatabasePath)
 	v.SetDefault("log_level", cfg.LogLevel)
 	v.SetDefault("log_file", cfg.LogFile)
@@ -267,6 +436,11 @@ func setDefaults(v *viper.Viper, cfg *Config) {
 	v.SetDefault("updates_enabled", cfg.UpdatesEnabled)
 	v.SetDefault("update_manifest_url", cfg.UpdateManifestURL)
 	v.SetDefault("update_public_key", cfg.UpdatePublicKey)
+	v.SetDefault("update_channel", cfg.UpdateChannel)
+	v.SetDefault("update_pinned_version", cfg.UpdatePinnedVersion)
+	v.SetDefault("update_check_interval", cfg.UpdateCheckInterval)
+	v.SetDefault("update_window_start", cfg.UpdateWindowStart)
+	v.SetDefault("update_window_end", cfg.UpdateWindowEnd)
 	v.SetDefault("api_server.enabled", cfg.APIServer.Enabled)
 	v.SetDefault("api_server.port", cfg.APIServer.Port)
 	v.SetDefault("api_server.host", cfg.APIServer.Host)
@@ -312,6 +486,41 @@ func setDefaults(v *viper.Viper, cfg *Config) {
 	v.SetDefault("api_server.security.xss_protection", cfg.APIServer.Security.XSSProtection)
 	v.SetDefault("api_server.security.referrer_policy", cfg.APIServer.Security.ReferrerPolicy)
 
+	// Access control defaults
+	v.SetDefault("access_control.enabled", cfg.AccessControl.Enabled)
+	v.SetDefault("access_control.allow_unknown_members", cfg.AccessControl.AllowUnknownMembers)
+	v.SetDefault("access_control.expiry_grace_period", cfg.AccessControl.ExpiryGracePeriod)
+	v.SetDefault("access_control.unlock_on_exit", cfg.AccessControl.UnlockOnExit)
+	v.SetDefault("access_control.max_event_age", cfg.AccessControl.MaxEventAge)
+	v.SetDefault("access_control.timezone", cfg.AccessControl.Timezone)
+	v.SetDefault("access_control.sync_interval", cfg.AccessControl.SyncInterval)
+	v.SetDefault("access_control.anti_passback.mode", cfg.AccessControl.AntiPassback.Mode)
+	v.SetDefault("access_control.anti_passback.reset_time", cfg.AccessControl.AntiPassback.ResetTime)
+
+	// Command channel defaults
+	v.SetDefault("command_channel.enabled", cfg.CommandChannel.Enabled)
+	v.SetDefault("command_channel.max_command_age", cfg.CommandChannel.MaxCommandAge)
+
+	// Roster sync defaults
+	v.SetDefault("roster_sync.enabled", cfg.RosterSync.Enabled)
+	v.SetDefault("roster_sync.interval", cfg.RosterSync.Interval)
+	v.SetDefault("roster_sync.page_size", cfg.RosterSync.PageSize)
+
+	// Provisioning defaults
+	v.SetDefault("provisioning.enabled", cfg.Provisioning.Enabled)
+	v.SetDefault("provisioning.interval", cfg.Provisioning.Interval)
+	v.SetDefault("provisioning.inactive_action", cfg.Provisioning.InactiveAction)
+	v.SetDefault("provisioning.default_privilege", cfg.Provisioning.DefaultPrivilege)
+
+	// Template backup defaults
+	v.SetDefault("template_backup.consent", cfg.TemplateBackup.Consent)
+	v.SetDefault("template_backup.interval", cfg.TemplateBackup.Interval)
+
+	// Config sync defaults
+	v.SetDefault("config_sync.enabled", cfg.ConfigSync.Enabled)
+	v.SetDefault("config_sync.history", cfg.ConfigSync.History)
+	v.SetDefault("config_sync.settle_time", cfg.ConfigSync.SettleTime)
+
 	// Installation metadata defaults
 	v.SetDefault("installation.method", cfg.Installation.Method)
 	v.SetDefault("installation.version", cfg.Installation.Version)
@@ -336,6 +545,10 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("queue_max_size must be positive")
 	}
 
+	if c.QueueRetentionPolicy != "" && c.QueueRetentionPolicy != "priority" && c.QueueRetentionPolicy != "fifo" {
+		return fmt.Errorf("queue_retention_policy must be one of: priority, fifo")
+	}
+
 	if c.HeartbeatInterval <= 0 {
 		return fmt.Errorf("heartbeat_interval must be positive")
 	}
@@ -344,6 +557,20 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("unlock_duration must be positive")
 	}
 
+	if len(c.DoorRelay) > 0 {
+		if _, err := relay.FromSettings(c.DoorRelay); err != nil {
+			return fmt.Errorf("door_relay is invalid: %w", err)
+		}
+	}
+
+	if err := c.validateDoors(); err != nil {
+		return err
+	}
+
+	if err := c.validateDoorUnlocks(); err != nil {
+		return err
+	}
+
 	if c.DatabasePath == "" {
 		return fmt.Errorf("database_path is required")
 	}
@@ -355,14 +582,302 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("log_level must be one of: debug, info, warn, error")
 	}
 
+	if c.AccessControl.ExpiryGracePeriod < 0 {
+		return fmt.Errorf("access_control.expiry_grace_period must not be negative")
+	}
+
+	if c.AccessControl.MaxEventAge < 0 {
+		return fmt.Errorf("access_control.max_event_age must not be negative")
+	}
+
+	if c.Timezone != "" {
+		if _, err := time.LoadLocation(c.Timezone); err != nil {
+			return fmt.Errorf("timezone is invalid: %w", err)
+		}
+	}
+
+	if c.AccessControl.Timezone != "" {
+		if _, err := time.LoadLocation(c.AccessControl.Timezone); err != nil {
+			return fmt.Errorf("access_control.timezone is invalid: %w", err)
+		}
+	}
+
+	switch c.AccessControl.AntiPassback.Mode {
+	case "", "off", "soft", "hard":
+	default:
+		return fmt.Errorf("access_control.anti_passback.mode must be one of: off, soft, hard")
+	}
+	if resetTime := c.AccessControl.AntiPassback.ResetTime; resetTime != "" {
+		if _, err := time.Parse("15:04", resetTime); err != nil {
+			return fmt.Errorf("access_control.anti_passback.reset_time must be in HH:MM format")
+		}
+	}
+
+	switch c.UpdateChannel {
+	case "", "stable", "beta":
+	case "pinned":
+		if c.UpdatePinnedVersion == "" {
+			return fmt.Errorf("update_pinned_version is required when update_channel is pinned")
+		}
+	default:
+		return fmt.Errorf("update_channel must be one of: stable, beta, pinned")
+	}
+	if c.UpdateCheckInterval < 0 {
+		return fmt.Errorf("update_check_interval must not be negative")
+	}
+	if (c.UpdateWindowStart == "") != (c.UpdateWindowEnd == "") {
+		return fmt.Errorf("update_window_start and update_window_end must be set together")
+	}
+	if c.UpdateWindowStart != "" {
+		if _, err := time.Parse("15:04", c.UpdateWindowStart); err != nil {
+			return fmt.Errorf("update_window_start must be in HH:MM format")
+		}
+		if _, err := time.Parse("15:04", c.UpdateWindowEnd); err != nil {
+			return fmt.Errorf("update_window_end must be in HH:MM format")
+		}
+		if c.UpdateWindowStart == c.UpdateWindowEnd {
+			return fmt.Errorf("update_window_start and update_window_end must differ")
+		}
+	}
+
+	if c.CommandChannel.MaxCommandAge <= 0 {
+		return fmt.Errorf("command_channel.max_command_age must be positive")
+	}
+
+	if c.RosterSync.Interval <= 0 {
+		return fmt.Errorf("roster_sync.interval must be positive")
+	}
+	if c.RosterSync.PageSize <= 0 || c.RosterSync.PageSize > 1000 {
+		return fmt.Errorf("roster_sync.page_size must be between 1 and 1000")
+	}
+
+	if c.Provisioning.Interval <= 0 {
+		return fmt.Errorf("provisioning.interval must be positive")
+	}
+	switch c.Provisioning.InactiveAction {
+	case "disable", "delete":
+	default:
+		return fmt.Errorf("provisioning.inactive_action must be one of: disable, delete")
+	}
+	if c.Provisioning.DefaultPrivilege < 0 || c.Provisioning.DefaultPrivilege > 255 {
+		return fmt.Errorf("provisioning.default_privilege must be between 0 and 255")
+	}
+
+	if c.TemplateBackup.Interval <= 0 {
+		return fmt.Errorf("template_backup.interval must be positive")
+	}
+
+	if c.ConfigSync.History < 1 {
+		return fmt.Errorf("config_sync.history must be at least 1")
+	}
+	if c.ConfigSync.SettleTime < 0 {
+		return fmt.Errorf("config_sync.settle_time must not be negative")
+	}
+
+	return nil
+}
+
+// validateDoors checks door IDs are unique and URL safe, adapters are bound to at most
+// one door and relay settings are valid
+func (c *Config) validateDoors() error {
+	if len(c.Doors) == 0 {
+		return nil
+	}
+	if len(c.DoorRelay) > 0 {
+		return fmt.Errorf("door_relay cannot be combined with doors; configure the relay on the door instead")
+	}
+
+	doorIDs := make(map[string]bool, len(c.Doors))
+	adapterDoors := make(map[string]string)
+	for i, door := range c.Doors {
+		if door.ID == "" {
+			return fmt.Errorf("doors[%d].id is required", i)
+		}
+		if !isValidDoorID(door.ID) {
+			return fmt.Errorf("doors[%d].id %q may only contain letters, digits, '-' and '_'", i, door.ID)
+		}
+		if doorIDs[door.ID] {
+			return fmt.Errorf("duplicate door id: %s", door.ID)
+		}
+		doorIDs[door.ID] = true
+
+		for _, adapterName := range door.Adapters {
+			if other, exists := adapterDoors[adapterName]; exists {
+				return fmt.Errorf("adapter %s is assigned to both door %s and door %s", adapterName, other, door.ID)
+			}
+			adapterDoors[adapterName] = door.ID
+		}
+
+		if door.UnlockDuration < 0 {
+			return fmt.Errorf("doors[%d].unlock_duration must not be negative", i)
+		}
+
+		if len(door.Relay) > 0 {
+			if _, err := relay.FromSettings(door.Relay); err != nil {
+				return fmt.Errorf("doors[%d].relay is invalid: %w", i, err)
+			}
+		}
+
+		if len(door.Contact) > 0 {
+			if _, err := input.FromSettings(door.Contact); err != nil {
+				return fmt.Errorf("doors[%d].contact is invalid: %w", i, err)
+			}
+		}
+		if len(door.Rex) > 0 {
+			if _, err := input.FromSettings(door.Rex); err != nil {
+				return fmt.Errorf("doors[%d].rex is invalid: %w", i, err)
+			}
+		} else if door.RexUnlocks {
+			return fmt.Errorf("doors[%d].rex_unlocks requires a rex input", i)
+		}
+		if door.HeldOpenTimeout < 0 {
+			return fmt.Errorf("doors[%d].held_open_timeout must not be negative", i)
+		}
+		if door.HeldOpenTimeout > 0 && len(door.Contact) == 0 {
+			return fmt.Errorf("doors[%d].held_open_timeout requires a contact input", i)
+		}
+	}
+
+	return nil
+}
+
+// validateDoorUnlocks checks doors without a relay are only read by adapters
+// that can unlock them, since the door controller unlocks through the first
+// healthy one
+func (c *Config) validateDoorUnlocks() error {
+	for _, door := range c.GetDoorConfigs() {
+		if len(door.Relay) > 0 {
+			continue
+		}
+		for _, adapterName := range door.Adapters {
+			if c.AdapterType(adapterName) == "biometric" && !biometric.UnlocksDoor(c.AdapterConfigs[adapterName]) {
+				return fmt.Errorf("door %s has no relay and adapter %s cannot unlock it; configure a relay for the door", door.ID, adapterName)
+			}
+		}
+	}
 	return nil
 }
 
+// isValidDoorID reports whether a door ID can be used as-is in API paths
+func isValidDoorID(id string) bool {
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// GetDoorConfigs returns the configured doors, or the single default door when none are
+// configured. Door unlock durations are resolved against the global unlock duration.
+func (c *Config) GetDoorConfigs() []DoorConfig {
+	if len(c.Doors) == 0 {
+		return []DoorConfig{{
+			ID:             DefaultDoorID,
+			Name:           "Main Door",
+			Adapters:       append([]string{}, c.EnabledAdapters...),
+			Relay:          c.DoorRelay,
+			UnlockDuration: c.UnlockDuration,
+		}}
+	}
+
+	doors := make([]DoorConfig, len(c.Doors))
+	for i, door := range c.Doors {
+		if door.Name == "" {
+			door.Name = door.ID
+		}
+		if door.UnlockDuration <= 0 {
+			door.UnlockDuration = c.UnlockDuration
+		}
+		doors[i] = door
+	}
+	return doors
+}
+
+// doorSettings converts the doors to plain maps so they are written with their YAML keys
+func (c *Config) doorSettings() []map[string]interface{} {
+	doors := make([]map[string]interface{}, 0, len(c.Doors))
+	for _, door := range c.Doors {
+		settings := map[string]interface{}{
+			"id":       door.ID,
+			"name":     door.Name,
+			"zone":     door.Zone,
+			"adapters": door.Adapters,
+		}
+		if len(door.Relay) > 0 {
+			settings["relay"] = door.Relay
+		}
+		if door.UnlockDuration > 0 {
+			settings["unlock_duration"] = door.UnlockDuration
+		}
+		if len(door.Contact) > 0 {
+			settings["contact"] = door.Contact
+		}
+		if len(door.Rex) > 0 {
+			settings["rex"] = door.Rex
+			settings["rex_unlocks"] = door.RexUnlocks
+		}
+		if door.HeldOpenTimeout > 0 {
+			settings["held_open_timeout"] = door.HeldOpenTimeout
+		}
+		doors = append(doors, settings)
+	}
+	return doors
+}
+
+// Location returns the site's timezone, or local time when none is configured
+func (c *Config) Location() *time.Location {
+	if c.Timezone != "" {
+		if loc, err := time.LoadLocation(c.Timezone); err == nil {
+			return loc
+		}
+	}
+	return time.Local
+}
+
 // IsPaired returns true if the device has been paired with the cloud
 func (c *Config) IsPaired() bool {
 	return c.DeviceID != "" && c.DeviceKey != ""
 }
 
+// Redacted returns a copy of the configuration with secrets blanked, for logging
+func (c *Config) Redacted() *Config {
+	redacted := *c
+	if redacted.DeviceKey != "" {
+		redacted.DeviceKey = "[REDACTED]"
+	}
+	if redacted.APIServer.Auth.HMACSecret != "" {
+		redacted.APIServer.Auth.HMACSecret = "[REDACTED]"
+	}
+	if redacted.APIServer.Auth.JWTSecret != "" {
+		redacted.APIServer.Auth.JWTSecret = "[REDACTED]"
+	}
+	if len(redacted.APIServer.Auth.APIKeys) > 0 {
+		redacted.APIServer.Auth.APIKeys = []string{"[REDACTED]"}
+	}
+	return &redacted
+}
+
+// AdapterTypeSetting is the adapter_configs key giving the type of an adapter
+// whose name is not its type, such as one of several biometric terminals
+const AdapterTypeSetting = "type"
+
+// AdapterType returns the type of an adapter from its settings; without a type
+// setting the adapter name is the type
+func AdapterType(name string, settings map[string]interface{}) string {
+	if adapterType, ok := settings[AdapterTypeSetting].(string); ok && adapterType != "" {
+		return adapterType
+	}
+	return name
+}
+
+// AdapterType returns the type of a configured adapter
+func (c *Config) AdapterType(name string) string {
+	return AdapterType(name, c.AdapterConfigs[name])
+}
+
 // GetAdapterConfigs converts the configuration to adapter configs
 func (c *Config) GetAdapterConfigs() []types.AdapterConfig {
 	var configs []types.AdapterConfig
@@ -370,6 +885,7 @@ func (c *Config) GetAdapterConfigs() []types.AdapterConfig {
 	for _, adapterName := range c.EnabledAdapters {
 		config := types.AdapterConfig{
 			Name:    adapterName,
+			Type:    c.AdapterType(adapterName),
 			Enabled: true,
 		}
 
@@ -402,9 +918,13 @@ func (c *Config) Save(configFile string) error {
 	v.Set("device_key", c.DeviceKey)
 	v.Set("server_url", c.ServerURL)
 	v.Set("tier", c.Tier)
+	v.Set("timezone", c.Timezone)
 	v.Set("queue_max_size", c.QueueMaxSize)
+	v.Set("queue_retention_policy", c.QueueRetentionPolicy)
 	v.Set("heartbeat_interval", c.HeartbeatInterval)
 	v.Set("unlock_duration", c.UnlockDuration)
+	v.Set("door_relay", c.DoorRelay)
+	v.Set("doors", c.doorSettings())
 	v.Set("database_path", c.DatabasePath)
 	v.Set("log_level", c.LogLevel)
 	v.Set("log_file", c.LogFile)
@@ -413,6 +933,11 @@ func (c *Config) Save(configFile string) error {
 	v.Set("updates_enabled", c.UpdatesEnabled)
 	v.Set("update_manifest_url", c.UpdateManifestURL)
 	v.Set("update_public_key", c.UpdatePublicKey)
+	v.Set("update_channel", c.UpdateChannel)
+	v.Set("update_pinned_version", c.UpdatePinnedVersion)
+	v.Set("update_check_interval", c.UpdateCheckInterval)
+	v.Set("update_window_start", c.UpdateWindowStart)
+	v.Set("update_window_end", c.UpdateWindowEnd)
 
 	// API Server configuration
 	v.Set("api_server.enabled", c.APIServer.Enabled)
@@ -460,6 +985,41 @@ func (c *Config) Save(configFile string) error {
 	v.Set("api_server.security.xss_protection", c.APIServer.Security.XSSProtection)
 	v.Set("api_server.security.referrer_policy", c.APIServer.Security.ReferrerPolicy)
 
+	// Access control configuration
+	v.Set("access_control.enabled", c.AccessControl.Enabled)
+	v.Set("access_control.allow_unknown_members", c.AccessControl.AllowUnknownMembers)
+	v.Set("access_control.expiry_grace_period", c.AccessControl.ExpiryGracePeriod)
+	v.Set("access_control.unlock_on_exit", c.AccessControl.UnlockOnExit)
+	v.Set("access_control.max_event_age", c.AccessControl.MaxEventAge)
+	v.Set("access_control.timezone", c.AccessControl.Timezone)
+	v.Set("access_control.sync_interval", c.AccessControl.SyncInterval)
+	v.Set("access_control.anti_passback.mode", c.AccessControl.AntiPassback.Mode)
+	v.Set("access_control.anti_passback.reset_time", c.AccessControl.AntiPassback.ResetTime)
+
+	// Command channel configuration
+	v.Set("command_channel.enabled", c.CommandChannel.Enabled)
+	v.Set("command_channel.max_command_age", c.CommandChannel.MaxCommandAge)
+
+	// Roster sync configuration
+	v.Set("roster_sync.enabled", c.RosterSync.Enabled)
+	v.Set("roster_sync.interval", c.RosterSync.Interval)
+	v.Set("roster_sync.page_size", c.RosterSync.PageSize)
+
+	// Provisioning configuration
+	v.Set("provisioning.enabled", c.Provisioning.Enabled)
+	v.Set("provisioning.interval", c.Provisioning.Interval)
+	v.Set("provisioning.inactive_action", c.Provisioning.InactiveAction)
+	v.Set("provisioning.default_privilege", c.Provisioning.DefaultPrivilege)
+
+	// Template backup configuration
+	v.Set("template_backup.consent", c.TemplateBackup.Consent)
+	v.Set("template_backup.interval", c.TemplateBackup.Interval)
+
+	// Config sync configuration
+	v.Set("config_sync.enabled", c.ConfigSync.Enabled)
+	v.Set("config_sync.history", c.ConfigSync.History)
+	v.Set("config_sync.settle_time", c.ConfigSync.SettleTime)
+
 	// Installation metadata
 	v.Set("installation.method", c.Installation.Method)
 	v.Set("installation.version", c.Installation.Version)
@@ -477,6 +1037,35 @@ func (c *Config) Save(configFile string) error {
 	return nil
 }
 
+// FileUsed returns the path of the file the configuration was loaded from, or
+// an empty string when it was built from defaults and the environment
+func (c *Config) FileUsed() string {
+	return c.file
+}
+
+// RemoveDeviceKey blanks the device_key field of a configuration file once the
+// key is held by a credential store, and reports whether the file held a key.
+// Other settings are left as written, so values from the environment are not
+// persisted.
+func RemoveDeviceKey(configFile string) (bool, error) {
+	v := viper.New()
+	v.SetConfigFile(configFile)
+	if err := v.ReadInConfig(); err != nil {
+		return false, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	if v.GetString("device_key") == "" {
+		return false, nil
+	}
+
+	v.Set("device_key", "")
+	if err := v.WriteConfig(); err != nil {
+		return false, fmt.Errorf("failed to write config file: %w", err)
+	}
+
+	return true, nil
+}
+
 // UpdateInstallationMetadata updates the installation metadata in the configuration
 func (c *Config) UpdateInstallationMetadata(metadata InstallationMetadata) error {
 	c.Installation = metadata
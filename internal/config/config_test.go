@@ -1,7 +1,11 @@
 package config
 
 import (
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestDefaultConfig(t *testing.T) {
@@ -48,6 +52,162 @@ func TestConfigValidation(t *testing.T) {
 	}
 }
 
+func TestSiteTimezone(t *testing.T) {
+	cfg := DefaultConfig()
+	if cfg.Location() != time.Local {
+		t.Error("Empty timezone should use local time")
+	}
+
+	cfg.Timezone = "Europe/London"
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Valid timezone should not return error: %v", err)
+	}
+	if cfg.Location().String() != "Europe/London" {
+		t.Errorf("Expected Europe/London, got %s", cfg.Location())
+	}
+
+	cfg.Timezone = "Mars/Olympus_Mons"
+	if err := cfg.Validate(); err == nil {
+		t.Error("Invalid timezone should return error")
+	}
+}
+
+func TestAntiPassbackValidation(t *testing.T) {
+	cfg := DefaultConfig()
+	if cfg.AccessControl.AntiPassback.Mode != "off" {
+		t.Errorf("Expected anti-passback off by default, got %q", cfg.AccessControl.AntiPassback.Mode)
+	}
+
+	cfg.AccessControl.AntiPassback.Mode = "hard"
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Hard mode should be valid: %v", err)
+	}
+
+	cfg.AccessControl.AntiPassback.Mode = "strict"
+	if err := cfg.Validate(); err == nil {
+		t.Error("Unknown anti-passback mode should return error")
+	}
+
+	cfg.AccessControl.AntiPassback.Mode = "soft"
+	cfg.AccessControl.AntiPassback.ResetTime = "3am"
+	if err := cfg.Validate(); err == nil {
+		t.Error("Invalid reset time should return error")
+	}
+}
+
+func TestUpdateValidation(t *testing.T) {
+	cfg := DefaultConfig()
+	if cfg.UpdateChannel != "stable" || cfg.UpdateWindowStart != "02:00" || cfg.UpdateWindowEnd != "05:00" {
+		t.Errorf("Unexpected update defaults: channel %q, window %q-%q", cfg.UpdateChannel, cfg.UpdateWindowStart, cfg.UpdateWindowEnd)
+	}
+
+	cfg.UpdateChannel = "pinned"
+	if err := cfg.Validate(); err == nil {
+		t.Error("Pinned channel without a version should return error")
+	}
+	cfg.UpdatePinnedVersion = "1.4.2"
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Pinned channel with a version should be valid: %v", err)
+	}
+
+	cfg.UpdateChannel = "nightly"
+	if err := cfg.Validate(); err == nil {
+		t.Error("Unknown update channel should return error")
+	}
+
+	cfg.UpdateChannel = "beta"
+	cfg.UpdateWindowEnd = ""
+	if err := cfg.Validate(); err == nil {
+		t.Error("Maintenance window without an end should return error")
+	}
+	cfg.UpdateWindowEnd = "5am"
+	if err := cfg.Validate(); err == nil {
+		t.Error("Invalid maintenance window end should return error")
+	}
+	cfg.UpdateWindowStart, cfg.UpdateWindowEnd = "", ""
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Updates without a maintenance window should be valid: %v", err)
+	}
+}
+
+func TestCommandChannelValidation(t *testing.T) {
+	cfg := DefaultConfig()
+	if !cfg.CommandChannel.Enabled || cfg.CommandChannel.MaxCommandAge != 60 {
+		t.Errorf("Unexpected command channel defaults: %+v", cfg.CommandChannel)
+	}
+
+	cfg.CommandChannel.MaxCommandAge = 0
+	if err := cfg.Validate(); err == nil {
+		t.Error("Zero max command age should return error")
+	}
+}
+
+func TestRosterSyncValidation(t *testing.T) {
+	cfg := DefaultConfig()
+	if !cfg.RosterSync.Enabled || cfg.RosterSync.Interval != 300 || cfg.RosterSync.PageSize != 500 {
+		t.Errorf("Unexpected roster sync defaults: %+v", cfg.RosterSync)
+	}
+
+	cfg.RosterSync.Interval = 0
+	if err := cfg.Validate(); err == nil {
+		t.Error("Zero roster sync interval should return error")
+	}
+
+	cfg = DefaultConfig()
+	cfg.RosterSync.PageSize = 5000
+	if err := cfg.Validate(); err == nil {
+		t.Error("Oversized roster page should return error")
+	}
+}
+
+func TestProvisioningValidation(t *testing.T) {
+	cfg := DefaultConfig()
+	if cfg.Provisioning.Enabled || cfg.Provisioning.InactiveAction != "disable" {
+		t.Errorf("Unexpected provisioning defaults: %+v", cfg.Provisioning)
+	}
+
+	cfg.Provisioning.InactiveAction = "ignore"
+	if err := cfg.Validate(); err == nil {
+		t.Error("Invalid inactive action should return error")
+	}
+
+	cfg = DefaultConfig()
+	cfg.Provisioning.Interval = 0
+	if err := cfg.Validate(); err == nil {
+		t.Error("Zero provisioning interval should return error")
+	}
+}
+
+func TestTemplateBackupValidation(t *testing.T) {
+	cfg := DefaultConfig()
+	if cfg.TemplateBackup.Consent {
+		t.Error("Template backup must be opt-in")
+	}
+
+	cfg.TemplateBackup.Interval = -1
+	if err := cfg.Validate(); err == nil {
+		t.Error("Negative template backup interval should return error")
+	}
+}
+
+func TestConfigSyncValidation(t *testing.T) {
+	cfg := DefaultConfig()
+	if !cfg.ConfigSync.Enabled || cfg.ConfigSync.History != 10 || cfg.ConfigSync.SettleTime != 30 {
+		t.Errorf("Unexpected config sync defaults: %+v", cfg.ConfigSync)
+	}
+
+	cfg.ConfigSync.History = 0
+	if err := cfg.Validate(); err == nil {
+		t.Error("Keeping no config versions should return error")
+	}
+
+	cfg = DefaultConfig()
+	cfg.ConfigSync.SettleTime = -1
+	if err := cfg.Validate(); err == nil {
+		t.Error("Negative settle time should return error")
+	}
+}
+
 func TestIsPaired(t *testing.T) {
 	cfg := DefaultConfig()
 	
@@ -75,4 +235,187 @@ func TestIsPaired(t *testing.T) {
 	if !cfg.IsPaired() {
 		t.Error("Config with both device ID and key should be paired")
 	}
-}
\ No newline at end of file
+}
+
+func TestRemoveDeviceKey(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	content := "device_id: dev_123\ndevice_key: secret-key\nserver_url: https://example.com\n"
+	if err := os.WriteFile(configFile, []byte(content), 0600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	cfg, err := Load(configFile)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if cfg.FileUsed() != configFile {
+		t.Errorf("Expected config file %s, got %s", configFile, cfg.FileUsed())
+	}
+
+	removed, err := RemoveDeviceKey(configFile)
+	if err != nil {
+		t.Fatalf("Failed to remove device key: %v", err)
+	}
+	if !removed {
+		t.Error("Expected the device key to be removed")
+	}
+
+	data, err := os.ReadFile(configFile)
+	if err != nil {
+		t.Fatalf("Failed to read config file: %v", err)
+	}
+	if strings.Contains(string(data), "secret-key") {
+		t.Error("Device key should be removed from the config file")
+	}
+
+	if removed, err := RemoveDeviceKey(configFile); err != nil || removed {
+		t.Errorf("Removing an absent device key should be a no-op: %v", err)
+	}
+
+	cfg, err = Load(configFile)
+	if err != nil {
+		t.Fatalf("Failed to reload config: %v", err)
+	}
+	if cfg.DeviceID != "dev_123" || cfg.ServerURL != "https://example.com" || cfg.DeviceKey != "" {
+		t.Errorf("Unexpected config after removing device key: %s %s %q", cfg.DeviceID, cfg.ServerURL, cfg.DeviceKey)
+	}
+
+	info, err := os.Stat(configFile)
+	if err != nil {
+		t.Fatalf("Failed to stat config file: %v", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("Config file permissions should be preserved, got %v", info.Mode().Perm())
+	}
+}
+
+func TestRedacted(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.DeviceID = "dev_123"
+	cfg.DeviceKey = "secret-key"
+	cfg.APIServer.Auth.APIKeys = []string{"api-key"}
+
+	redacted := cfg.Redacted()
+	if redacted.DeviceKey == "secret-key" || redacted.APIServer.Auth.APIKeys[0] == "api-key" {
+		t.Error("Secrets should be redacted")
+	}
+	if redacted.DeviceID != "dev_123" {
+		t.Error("Non-secret settings should be kept")
+	}
+	if cfg.DeviceKey != "secret-key" || cfg.APIServer.Auth.APIKeys[0] != "api-key" {
+		t.Error("Redacting should not modify the original configuration")
+	}
+}
+
+func TestDoorsValidation(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Doors = []DoorConfig{
+		{ID: "front", Adapters: []string{"zkteco"}},
+		{ID: "back-gate", Adapters: []string{"rfid"}, UnlockDuration: 5000},
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Valid doors should not return error: %v", err)
+	}
+
+	cfg.Doors[1].ID = "front"
+	if err := cfg.Validate(); err == nil {
+		t.Error("Duplicate door IDs should return error")
+	}
+
+	cfg.Doors[1].ID = "back/gate"
+	if err := cfg.Validate(); err == nil {
+		t.Error("Door ID with a slash should return error")
+	}
+
+	cfg.Doors[1].ID = "back"
+	cfg.Doors[1].Adapters = []string{"zkteco"}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Adapter bound to two doors should return error")
+	}
+
+	cfg.Doors[1].Adapters = nil
+	cfg.DoorRelay = map[string]interface{}{"driver": "http", "onUrl": "http://relay/on", "offUrl": "http://relay/off"}
+	if err := cfg.Validate(); err == nil {
+		t.Error("door_relay combined with doors should return error")
+	}
+}
+
+func TestDoorUnlockValidation(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.EnabledAdapters = []string{"biometric"}
+	cfg.AdapterConfigs = map[string]map[string]interface{}{
+		"biometric": {"device_type": "realtime"},
+	}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Realtime terminal as the only unlock path should return error")
+	}
+
+	cfg.DoorRelay = map[string]interface{}{"driver": "http", "onUrl": "http://relay/on", "offUrl": "http://relay/off"}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Realtime terminal with a door relay should not return error: %v", err)
+	}
+
+	cfg.DoorRelay = nil
+	cfg.AdapterConfigs["biometric"]["device_type"] = "zkteco"
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("ZKTeco terminal unlocking its door should not return error: %v", err)
+	}
+}
+
+func TestDoorInputsValidation(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Doors = []DoorConfig{{
+		ID:              "front",
+		Contact:         map[string]interface{}{"driver": "gpiochip", "line": 17, "bias": "pull_up", "activeLow": true},
+		Rex:             map[string]interface{}{"driver": "modbus", "address": "10.0.0.5", "input": 2},
+		RexUnlocks:      true,
+		HeldOpenTimeout: 30,
+	}}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Valid door inputs should not return error: %v", err)
+	}
+
+	cfg.Doors[0].Contact = map[string]interface{}{"driver": "gpiochip", "bias": "floating"}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Invalid contact input should return error")
+	}
+
+	cfg.Doors[0].Contact = nil
+	if err := cfg.Validate(); err == nil {
+		t.Error("held_open_timeout without a contact should return error")
+	}
+
+	cfg.Doors[0].HeldOpenTimeout = 0
+	cfg.Doors[0].Rex = nil
+	if err := cfg.Validate(); err == nil {
+		t.Error("rex_unlocks without a rex input should return error")
+	}
+}
+
+func TestGetDoorConfigs(t *testing.T) {
+	cfg := DefaultConfig()
+
+	// Without doors a single default door covers the enabled adapters
+	doors := cfg.GetDoorConfigs()
+	if len(doors) != 1 || doors[0].ID != DefaultDoorID {
+		t.Fatalf("Expected the default door, got %+v", doors)
+	}
+	if doors[0].UnlockDuration != cfg.UnlockDuration {
+		t.Errorf("Expected unlock duration %d, got %d", cfg.UnlockDuration, doors[0].UnlockDuration)
+	}
+	if len(doors[0].Adapters) != len(cfg.EnabledAdapters) {
+		t.Errorf("Expected adapters %v, got %v", cfg.EnabledAdapters, doors[0].Adapters)
+	}
+
+	cfg.Doors = []DoorConfig{{ID: "front", UnlockDuration: 7000}, {ID: "back"}}
+	doors = cfg.GetDoorConfigs()
+	if len(doors) != 2 {
+		t.Fatalf("Expected 2 doors, got %d", len(doors))
+	}
+	if doors[0].UnlockDuration != 7000 || doors[1].UnlockDuration != cfg.UnlockDuration {
+		t.Errorf("Unexpected unlock durations: %d, %d", doors[0].UnlockDuration, doors[1].UnlockDuration)
+	}
+	if doors[1].Name != "back" {
+		t.Errorf("Expected name to default to the ID, got %q", doors[1].Name)
+	}
+}
@@ -0,0 +1,64 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// watchDebounce coalesces the burst of events an editor produces when saving
+var watchDebounce = 500 * time.Millisecond
+
+// Watch calls onChange after the configuration file is written, until the
+// context is cancelled. The file's directory is watched rather than the file,
+// so editors that save by replacing the file are followed. Watcher errors are
+// passed to onError.
+func Watch(ctx context.Context, configFile string, onChange func(), onError func(error)) error {
+	path, err := filepath.Abs(configFile)
+	if err != nil {
+		return fmt.Errorf("failed to resolve config file path: %w", err)
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create config watcher: %w", err)
+	}
+	if err := watcher.Add(filepath.Dir(path)); err != nil {
+		watcher.Close()
+		return fmt.Errorf("failed to watch %s: %w", filepath.Dir(path), err)
+	}
+
+	debounce := watchDebounce
+	go func() {
+		defer watcher.Close()
+
+		var settled <-chan time.Time
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if filepath.Clean(event.Name) != path || !event.Has(fsnotify.Write) && !event.Has(fsnotify.Create) {
+					continue
+				}
+				settled = time.After(debounce)
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				onError(err)
+			case <-settled:
+				settled = nil
+				onChange()
+			}
+		}
+	}()
+
+	return nil
+}
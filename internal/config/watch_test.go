@@ -0,0 +1,70 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatch(t *testing.T) {
+	watchDebounce = 50 * time.Millisecond
+	defer func() { watchDebounce = 500 * time.Millisecond }()
+
+	dir := t.TempDir()
+	configFile := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(configFile, []byte("tier: normal\n"), 0600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	changes := make(chan struct{}, 10)
+	err := Watch(ctx, configFile, func() { changes <- struct{}{} }, func(err error) { t.Errorf("watch error: %v", err) })
+	if err != nil {
+		t.Fatalf("Watch() error = %v", err)
+	}
+
+	// Other files in the directory are ignored
+	if err := os.WriteFile(filepath.Join(dir, "bridge.db"), []byte("data"), 0600); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	select {
+	case <-changes:
+		t.Fatal("unexpected change for another file")
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	// A burst of writes is reported once
+	for _, tier := range []string{"lite", "full"} {
+		if err := os.WriteFile(configFile, []byte("tier: "+tier+"\n"), 0600); err != nil {
+			t.Fatalf("failed to write config: %v", err)
+		}
+	}
+	select {
+	case <-changes:
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for the config change")
+	}
+	select {
+	case <-changes:
+		t.Error("expected writes to be coalesced")
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	// Saving by replacing the file is followed
+	replacement := filepath.Join(dir, "config.yaml.tmp")
+	if err := os.WriteFile(replacement, []byte("tier: lite\n"), 0600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	if err := os.Rename(replacement, configFile); err != nil {
+		t.Fatalf("failed to replace config: %v", err)
+	}
+	select {
+	case <-changes:
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for the replaced config")
+	}
+}
@@ -0,0 +1,320 @@
+package configsync
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"gym-door-bridge/internal/access"
+	"gym-door-bridge/internal/adapters"
+	"gym-door-bridge/internal/config"
+)
+
+// Document is the desired configuration the platform publishes for a bridge.
+// A section that is left out or null is not managed by the platform and keeps
+// the local configuration; an empty list clears the section.
+type Document struct {
+	Version   int64          `json:"version"`
+	Adapters  []AdapterSpec  `json:"adapters"`
+	Doors     []DoorSpec     `json:"doors"`
+	Schedules []ScheduleSpec `json:"schedules"`
+	Holidays  []HolidaySpec  `json:"holidays"`
+	API       *APISpec       `json:"api"`
+}
+
+// AdapterSpec enables a hardware adapter with its settings. The type defaults
+// to the name, naming adapters lets several of one type run side by side.
+type AdapterSpec struct {
+	Name     string                 `json:"name"`
+	Type     string                 `json:"type"`
+	Settings map[string]interface{} `json:"settings"`
+}
+
+// DoorSpec describes a door, in the same terms as the doors of the local configuration
+type DoorSpec struct {
+	ID               string                 `json:"id"`
+	Name             string                 `json:"name"`
+	Zone             string                 `json:"zone"`
+	Adapters         []string               `json:"adapters"`
+	Relay            map[string]interface{} `json:"relay"`
+	UnlockDurationMs int                    `json:"unlockDurationMs"`
+	Contact          map[string]interface{} `json:"contact"`
+	Rex              map[string]interface{} `json:"rex"`
+	RexUnlocks       bool                   `json:"rexUnlocks"`
+	HeldOpenTimeout  int                    `json:"heldOpenTimeout"` // seconds
+}
+
+// ScheduleSpec is a free-access schedule holding doors unlocked in its windows
+type ScheduleSpec struct {
+	ID      string                `json:"id"`
+	Name    string                `json:"name"`
+	DoorIDs []string              `json:"doorIds"` // Empty applies to every door
+	Windows []access.AccessWindow `json:"windows"`
+	Enabled bool                  `json:"enabled"`
+}
+
+// HolidaySpec replaces the weekly schedules on a date. Without hours the doors
+// stay locked all day.
+type HolidaySpec struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Date  string `json:"date"`  // YYYY-MM-DD in the site's timezone
+	Start string `json:"start"` // HH:MM
+	End   string `json:"end"`   // HH:MM
+}
+
+// APISpec holds the local API server settings the platform manages. They take
+// effect on the next restart.
+type APISpec struct {
+	Enabled           bool     `json:"enabled"`
+	Port              int      `json:"port"`
+	Host              string   `json:"host"`
+	RequestsPerMinute int      `json:"requestsPerMinute"` // 0 keeps the local rate limit
+	AllowedOrigins    []string `json:"allowedOrigins"`    // CORS origins; null keeps the local origins
+	AllowedIPs        []string `json:"allowedIps"`        // null keeps the local allow list
+}
+
+// FieldError describes a field of a document that failed validation
+type FieldError struct {
+	Field   string // Path of the field, e.g. "doors[1].relay"; empty for document-wide errors
+	Message string
+}
+
+// Error implements the error interface
+func (e FieldError) Error() string {
+	if e.Field == "" {
+		return e.Message
+	}
+	return e.Field + ": " + e.Message
+}
+
+// ParseDocument decodes a config document. Unknown fields are rejected, so a
+// document written for a newer bridge is not half applied.
+func ParseDocument(data []byte) (*Document, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	var doc Document
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("invalid config document: %w", err)
+	}
+	if decoder.More() {
+		return nil, fmt.Errorf("invalid config document: unexpected data after the document")
+	}
+
+	return &doc, nil
+}
+
+// Validate checks the document against the running configuration and returns
+// every problem found. The configuration the document would produce must also
+// pass the local configuration's own validation.
+func (d *Document) Validate(base config.Config) []FieldError {
+	var errs []FieldError
+	add := func(field, format string, args ...interface{}) {
+		errs = append(errs, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if d.Version <= 0 {
+		add("version", "must be positive")
+	}
+
+	registered := make(map[string]bool)
+	for _, name := range adapters.GetRegisteredAdapterTypes() {
+		registered[name] = true
+	}
+	seenAdapters := make(map[string]bool, len(d.Adapters))
+	for i, adapter := range d.Adapters {
+		field := fmt.Sprintf("adapters[%d].name", i)
+		switch {
+		case adapter.Name == "":
+			add(field, "is required")
+		case seenAdapters[adapter.Name]:
+			add(field, "duplicate adapter %q", adapter.Name)
+		}
+		seenAdapters[adapter.Name] = true
+
+		adapterType := config.AdapterType(adapter.Name, adapter.settings())
+		if adapter.Name != "" && !registered[adapterType] {
+			if adapter.Type != "" {
+				field = fmt.Sprintf("adapters[%d].type", i)
+			}
+			add(field, "unknown adapter type %q", adapterType)
+		}
+	}
+
+	target := base
+	d.ApplyTo(&target)
+
+	enabled := make(map[string]bool, len(target.EnabledAdapters))
+	for _, name := range target.EnabledAdapters {
+		enabled[name] = true
+	}
+	for i, door := range d.Doors {
+		for j, name := range door.Adapters {
+			if !enabled[name] {
+				add(fmt.Sprintf("doors[%d].adapters[%d]", i, j), "adapter %q is not enabled", name)
+			}
+		}
+	}
+
+	doorIDs := make(map[string]bool)
+	for _, door := range target.GetDoorConfigs() {
+		doorIDs[door.ID] = true
+	}
+	seenSchedules := make(map[string]bool, len(d.Schedules))
+	for i, schedule := range d.Schedules {
+		field := fmt.Sprintf("schedules[%d]", i)
+		if schedule.ID == "" {
+			add(field+".id", "is required")
+		} else if seenSchedules[schedule.ID] {
+			add(field+".id", "duplicate schedule %q", schedule.ID)
+		}
+		seenSchedules[schedule.ID] = true
+
+		if len(schedule.Windows) == 0 {
+			add(field+".windows", "must contain at least one window")
+		}
+		for j, window := range schedule.Windows {
+			if err := window.Validate(); err != nil {
+				add(fmt.Sprintf("%s.windows[%d]", field, j), "%v", err)
+			}
+		}
+		for j, doorID := range schedule.DoorIDs {
+			if !doorIDs[doorID] {
+				add(fmt.Sprintf("%s.doorIds[%d]", field, j), "unknown door %q", doorID)
+			}
+		}
+	}
+
+	seenHolidays := make(map[string]bool, len(d.Holidays))
+	seenDates := make(map[string]bool, len(d.Holidays))
+	for i, holiday := range d.Holidays {
+		field := fmt.Sprintf("holidays[%d]", i)
+		if holiday.ID == "" {
+			add(field+".id", "is required")
+		} else if seenHolidays[holiday.ID] {
+			add(field+".id", "duplicate holiday %q", holiday.ID)
+		}
+		seenHolidays[holiday.ID] = true
+
+		if _, err := time.Parse("2006-01-02", holiday.Date); err != nil {
+			add(field+".date", "must be in YYYY-MM-DD format")
+		} else if seenDates[holiday.Date] {
+			add(field+".date", "another holiday is on %s", holiday.Date)
+		}
+		seenDates[holiday.Date] = true
+
+		if holiday.Start != "" || holiday.End != "" {
+			start, startErr := time.Parse("15:04", holiday.Start)
+			end, endErr := time.Parse("15:04", holiday.End)
+			switch {
+			case startErr != nil:
+				add(field+".start", "must be in HH:MM format")
+			case endErr != nil:
+				add(field+".end", "must be in HH:MM format")
+			case !start.Before(end):
+				add(field+".start", "must be before end")
+			}
+		}
+	}
+
+	if d.API != nil {
+		if d.API.Enabled && (d.API.Port < 1 || d.API.Port > 65535) {
+			add("api.port", "must be between 1 and 65535")
+		}
+		if d.API.RequestsPerMinute < 0 {
+			add("api.requestsPerMinute", "must not be negative")
+		}
+	}
+
+	if err := target.Validate(); err != nil {
+		add("", "%v", err)
+	}
+
+	return errs
+}
+
+// settings returns the adapter's settings as kept in the local configuration,
+// where the adapter type is one of the settings
+func (a AdapterSpec) settings() map[string]interface{} {
+	settings := make(map[string]interface{}, len(a.Settings)+1)
+	for key, value := range a.Settings {
+		settings[key] = value
+	}
+	if a.Type != "" {
+		settings[config.AdapterTypeSetting] = a.Type
+	}
+	return settings
+}
+
+// ApplyTo overlays the managed sections of the document onto a configuration.
+// Maps and slices of cfg are replaced rather than modified, so a shallow copy
+// of a configuration can be passed.
+func (d *Document) ApplyTo(cfg *config.Config) {
+	if d.Adapters != nil {
+		enabled := make([]string, 0, len(d.Adapters))
+		settings := make(map[string]map[string]interface{}, len(cfg.AdapterConfigs)+len(d.Adapters))
+		for name, adapterSettings := range cfg.AdapterConfigs {
+			settings[name] = adapterSettings
+		}
+		for _, adapter := range d.Adapters {
+			enabled = append(enabled, adapter.Name)
+			settings[adapter.Name] = adapter.settings()
+		}
+		cfg.EnabledAdapters = enabled
+		cfg.AdapterConfigs = settings
+	}
+
+	if d.Doors != nil {
+		doors := make([]config.DoorConfig, 0, len(d.Doors))
+		for _, door := range d.Doors {
+			doors = append(doors, config.DoorConfig{
+				ID:              door.ID,
+				Name:            door.Name,
+				Zone:            door.Zone,
+				Adapters:        door.Adapters,
+				Relay:           door.Relay,
+				UnlockDuration:  door.UnlockDurationMs,
+				Contact:         door.Contact,
+				Rex:             door.Rex,
+				RexUnlocks:      door.RexUnlocks,
+				HeldOpenTimeout: door.HeldOpenTimeout,
+			})
+		}
+		cfg.Doors = doors
+		if len(doors) > 0 {
+			// Each managed door carries its own relay
+			cfg.DoorRelay = make(map[string]interface{})
+		}
+	}
+
+	if d.API != nil {
+		cfg.APIServer.Enabled = d.API.Enabled
+		if d.API.Port > 0 {
+			cfg.APIServer.Port = d.API.Port
+		}
+		if d.API.Host != "" {
+			cfg.APIServer.Host = d.API.Host
+		}
+		if d.API.RequestsPerMinute > 0 {
+			cfg.APIServer.RateLimit.RequestsPerMin = d.API.RequestsPerMinute
+		}
+		if d.API.AllowedOrigins != nil {
+			cfg.APIServer.CORS.AllowedOrigins = d.API.AllowedOrigins
+		}
+		if d.API.AllowedIPs != nil {
+			cfg.APIServer.Auth.AllowedIPs = d.API.AllowedIPs
+		}
+	}
+}
+
+// joinFieldErrors summarizes validation errors in one line
+func joinFieldErrors(errs []FieldError) string {
+	messages := make([]string, 0, len(errs))
+	for _, err := range errs {
+		messages = append(messages, err.Error())
+	}
+	return strings.Join(messages, "; ")
+}
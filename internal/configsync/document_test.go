@@ -0,0 +1,166 @@
+package configsync
+
+import (
+	"strings"
+	"testing"
+
+	"gym-door-bridge/internal/config"
+)
+
+const validDocument = `{
+	"version": 7,
+	"adapters": [
+		{"name": "simulator", "settings": {"eventInterval": 5}},
+		{"name": "webhook", "settings": {"port": 8089}}
+	],
+	"doors": [
+		{"id": "front", "name": "Front Door", "adapters": ["simulator"], "relay": {"driver": "http", "onUrl": "http://relay/on", "offUrl": "http://relay/off"}},
+		{"id": "studio", "adapters": ["webhook"], "unlockDurationMs": 5000}
+	],
+	"schedules": [
+		{"id": "open-gym", "name": "Open gym", "doorIds": ["front"], "windows": [{"days": [1, 2, 3, 4, 5], "start": "06:00", "end": "22:00"}], "enabled": true}
+	],
+	"holidays": [
+		{"id": "new-year", "name": "New Year", "date": "2027-01-01"}
+	],
+	"api": {"enabled": true, "port": 8090, "allowedIps": ["10.0.0.0/8"]}
+}`
+
+func TestParseDocument(t *testing.T) {
+	doc, err := ParseDocument([]byte(validDocument))
+	if err != nil {
+		t.Fatalf("ParseDocument() error = %v", err)
+	}
+	if errs := doc.Validate(*config.DefaultConfig()); len(errs) > 0 {
+		t.Fatalf("expected a valid document, got %v", errs)
+	}
+
+	if _, err := ParseDocument([]byte(`{"version": 1, "printers": []}`)); err == nil {
+		t.Error("expected unknown sections to be rejected")
+	}
+	if _, err := ParseDocument([]byte(`{"version": 1} {"version": 2}`)); err == nil {
+		t.Error("expected trailing data to be rejected")
+	}
+}
+
+func TestDocument_Validate(t *testing.T) {
+	tests := []struct {
+		name   string
+		doc    string
+		fields []string
+	}{
+		{
+			name:   "missing version",
+			doc:    `{"adapters": [{"name": "simulator"}]}`,
+			fields: []string{"version"},
+		},
+		{
+			name:   "unknown and duplicate adapters",
+			doc:    `{"version": 2, "adapters": [{"name": "simulator"}, {"name": "teleporter"}, {"name": "simulator"}]}`,
+			fields: []string{"adapters[1].name", "adapters[2].name"},
+		},
+		{
+			name:   "adapter instances",
+			doc:    `{"version": 2, "adapters": [{"name": "front-terminal", "type": "biometric"}, {"name": "back-terminal", "type": "biometric"}, {"name": "gate", "type": "teleporter"}, {"name": "lobby"}]}`,
+			fields: []string{"adapters[2].type", "adapters[3].name"},
+		},
+		{
+			name:   "door reader not enabled",
+			doc:    `{"version": 2, "adapters": [{"name": "simulator"}], "doors": [{"id": "front", "adapters": ["rfid"]}]}`,
+			fields: []string{"doors[0].adapters[0]"},
+		},
+		{
+			name:   "invalid relay",
+			doc:    `{"version": 2, "doors": [{"id": "front", "relay": {"driver": "carrier-pigeon"}}]}`,
+			fields: []string{""},
+		},
+		{
+			name:   "bad schedule",
+			doc:    `{"version": 2, "schedules": [{"id": "a", "windows": [{"days": [9], "start": "06:00", "end": "07:00"}], "doorIds": ["back"]}, {"id": "a"}]}`,
+			fields: []string{"schedules[0].windows[0]", "schedules[0].doorIds[0]", "schedules[1].id", "schedules[1].windows"},
+		},
+		{
+			name:   "bad holidays",
+			doc:    `{"version": 2, "holidays": [{"id": "h1", "date": "01/01/2027"}, {"id": "h2", "date": "2027-12-25", "start": "18:00", "end": "09:00"}, {"id": "h3", "date": "2027-12-25", "start": "10:00"}]}`,
+			fields: []string{"holidays[0].date", "holidays[1].start", "holidays[2].date", "holidays[2].end"},
+		},
+		{
+			name:   "bad api port",
+			doc:    `{"version": 2, "api": {"enabled": true, "port": 70000}}`,
+			fields: []string{"api.port"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := ParseDocument([]byte(tt.doc))
+			if err != nil {
+				t.Fatalf("ParseDocument() error = %v", err)
+			}
+
+			errs := doc.Validate(*config.DefaultConfig())
+			var fields []string
+			for _, err := range errs {
+				fields = append(fields, err.Field)
+			}
+			if strings.Join(fields, ",") != strings.Join(tt.fields, ",") {
+				t.Errorf("expected errors on %v, got %v", tt.fields, errs)
+			}
+		})
+	}
+}
+
+func TestDocument_ApplyTo(t *testing.T) {
+	base := config.DefaultConfig()
+	base.DoorRelay = map[string]interface{}{"driver": "http", "onUrl": "http://old/on", "offUrl": "http://old/off"}
+	base.AdapterConfigs["rfid"] = map[string]interface{}{"port": "/dev/ttyUSB0"}
+
+	doc, err := ParseDocument([]byte(validDocument))
+	if err != nil {
+		t.Fatalf("ParseDocument() error = %v", err)
+	}
+
+	target := *base
+	doc.ApplyTo(&target)
+
+	if strings.Join(target.EnabledAdapters, ",") != "simulator,webhook" {
+		t.Errorf("unexpected enabled adapters: %v", target.EnabledAdapters)
+	}
+	if target.AdapterConfigs["webhook"]["port"] != float64(8089) {
+		t.Errorf("expected webhook settings from the document, got %v", target.AdapterConfigs["webhook"])
+	}
+	if target.AdapterType("simulator") != "simulator" {
+		t.Errorf("expected the adapter name to be its type, got %q", target.AdapterType("simulator"))
+	}
+	if _, kept := target.AdapterConfigs["rfid"]; !kept {
+		t.Error("settings of adapters the document does not enable should be kept")
+	}
+	if len(target.Doors) != 2 || target.Doors[1].UnlockDuration != 5000 || len(target.DoorRelay) != 0 {
+		t.Errorf("unexpected doors: %+v (door_relay %v)", target.Doors, target.DoorRelay)
+	}
+	if target.APIServer.Port != 8090 || len(target.APIServer.Auth.AllowedIPs) != 1 {
+		t.Errorf("unexpected api settings: %+v", target.APIServer)
+	}
+	if err := target.Validate(); err != nil {
+		t.Errorf("applied configuration should be valid: %v", err)
+	}
+
+	// The base configuration is left untouched
+	if len(base.EnabledAdapters) != 1 || len(base.Doors) != 0 || len(base.DoorRelay) == 0 {
+		t.Errorf("base configuration was modified: %+v", base)
+	}
+
+	// Adapter types are kept with the settings of the named adapter
+	terminals := *base
+	(&Document{Version: 8, Adapters: []AdapterSpec{{Name: "front-terminal", Type: "biometric", Settings: map[string]interface{}{"device_type": "zkteco"}}}}).ApplyTo(&terminals)
+	if terminals.AdapterType("front-terminal") != "biometric" || terminals.AdapterConfigs["front-terminal"]["device_type"] != "zkteco" {
+		t.Errorf("unexpected adapter settings: %v", terminals.AdapterConfigs["front-terminal"])
+	}
+
+	// Sections left out keep the local configuration
+	partial := *base
+	(&Document{Version: 8}).ApplyTo(&partial)
+	if partial.DoorRelay["onUrl"] != "http://old/on" || len(partial.EnabledAdapters) != 1 {
+		t.Errorf("unmanaged sections should be kept, got %+v", partial)
+	}
+}
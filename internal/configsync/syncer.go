@@ -0,0 +1,372 @@
+package configsync
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gym-door-bridge/internal/client"
+	"gym-door-bridge/internal/config"
+	"gym-door-bridge/internal/database"
+	"gym-door-bridge/internal/logging"
+)
+
+// Source defines the cloud client methods needed by the syncer
+type Source interface {
+	GetDesiredConfig(ctx context.Context) (json.RawMessage, error)
+	ReportConfig(ctx context.Context, report *client.ConfigReport) error
+}
+
+// Store defines the database methods needed by the syncer
+type Store interface {
+	SaveConfigVersion(version *database.ConfigVersion) error
+	GetConfigVersion(version int64) (*database.ConfigVersion, error)
+	GetAppliedConfigVersion() (*database.ConfigVersion, error)
+	PruneConfigVersions(keep int) (int64, error)
+}
+
+// ApplyResult describes what applying a configuration changed
+type ApplyResult struct {
+	ChangedAdapters []string // Adapters started or restarted with new settings
+	RemovedAdapters []string // Adapters stopped because they are no longer enabled
+	RestartRequired []string // Settings that only take effect on the next restart
+}
+
+// Applier puts configurations into effect on the running bridge
+type Applier interface {
+	// CurrentConfig returns a copy of the running configuration
+	CurrentConfig() config.Config
+	// CurrentSchedules returns the stored free-access schedules and holidays
+	CurrentSchedules() ([]ScheduleSpec, []HolidaySpec, error)
+	// Apply puts a configuration into effect. Nil schedules or holidays are left unchanged.
+	Apply(ctx context.Context, cfg config.Config, schedules []ScheduleSpec, holidays []HolidaySpec) (*ApplyResult, error)
+	// UnhealthyAdapters returns the named adapters that are not running healthily
+	UnhealthyAdapters(names []string) []string
+}
+
+// Config configures the syncer
+type Config struct {
+	History        int           // Config versions kept in the database
+	SettleTime     time.Duration // Time restarted adapters get to become healthy before rolling back
+	AppliedVersion int64         // Version restored at startup, 0 for the local configuration
+}
+
+// SyncStats contains statistics about config synchronization
+type SyncStats struct {
+	AppliedVersion  int64    `json:"appliedVersion"`
+	DesiredVersion  int64    `json:"desiredVersion"`
+	TotalApplied    int64    `json:"totalApplied"`
+	TotalRolledBack int64    `json:"totalRolledBack"`
+	TotalInvalid    int64    `json:"totalInvalid"`
+	TotalFailures   int64    `json:"totalFailures"`
+	LastSyncAt      int64    `json:"lastSyncAt"` // Unix timestamp
+	LastStatus      string   `json:"lastStatus,omitempty"`
+	LastError       string   `json:"lastError,omitempty"`
+	RestartRequired []string `json:"restartRequired,omitempty"`
+}
+
+// Syncer applies the configuration versions the platform announces in heartbeat
+// responses. Invalid documents are rejected before anything changes, and a
+// version whose adapters do not come up healthy is rolled back.
+type Syncer struct {
+	source  Source
+	store   Store
+	applier Applier
+	config  Config
+	logger  *logrus.Entry
+
+	notify chan struct{}
+	stats  SyncStats
+	mutex  sync.RWMutex
+}
+
+// NewSyncer creates a new config syncer
+func NewSyncer(source Source, store Store, applier Applier, cfg Config, logger *logrus.Logger) *Syncer {
+	if cfg.History < 1 {
+		cfg.History = 10
+	}
+	if cfg.SettleTime < 0 {
+		cfg.SettleTime = 0
+	}
+
+	return &Syncer{
+		source:  source,
+		store:   store,
+		applier: applier,
+		config:  cfg,
+		logger:  logging.NewServiceLogger(logger, "config-sync"),
+		notify:  make(chan struct{}, 1),
+		stats:   SyncStats{AppliedVersion: cfg.AppliedVersion},
+	}
+}
+
+// Notify records the config version the platform wants in effect. It never
+// blocks, so it can be called from the heartbeat loop.
+func (s *Syncer) Notify(version int64) {
+	s.mutex.Lock()
+	s.stats.DesiredVersion = version
+	pending := version != s.stats.AppliedVersion
+	s.mutex.Unlock()
+
+	if !pending {
+		return
+	}
+
+	select {
+	case s.notify <- struct{}{}:
+	default:
+		// A sync is already pending and will pick up the latest version
+	}
+}
+
+// AppliedVersion returns the config version in effect, 0 for the local configuration
+func (s *Syncer) AppliedVersion() int64 {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return s.stats.AppliedVersion
+}
+
+// Start applies announced config versions until the context is cancelled
+func (s *Syncer) Start(ctx context.Context) {
+	s.logger.WithField("applied_version", s.AppliedVersion()).Info("Starting config sync")
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.logger.Info("Config sync stopped")
+			return
+		case <-s.notify:
+			s.mutex.RLock()
+			desired := s.stats.DesiredVersion
+			s.mutex.RUnlock()
+
+			if err := s.Sync(ctx, desired); err != nil {
+				s.logger.WithError(err).WithField("version", desired).Warn("Config sync failed")
+			}
+		}
+	}
+}
+
+// Sync fetches the desired config document and applies it, unless that version
+// is already in effect or was rejected or rolled back before. The outcome is
+// recorded locally and reported to the platform.
+func (s *Syncer) Sync(ctx context.Context, version int64) error {
+	if version <= 0 || version == s.AppliedVersion() {
+		return nil
+	}
+	if s.alreadyAttempted(version) {
+		return nil
+	}
+
+	data, err := s.source.GetDesiredConfig(ctx)
+	if err != nil {
+		s.recordFailure(err)
+		return fmt.Errorf("failed to fetch desired config: %w", err)
+	}
+
+	record := &database.ConfigVersion{
+		Version:    version,
+		Document:   data,
+		ReceivedAt: time.Now(),
+	}
+
+	doc, err := ParseDocument(data)
+	if err != nil {
+		s.finish(ctx, record, database.ConfigVersionInvalid, []FieldError{{Message: err.Error()}}, nil)
+		return nil
+	}
+	if doc.Version > 0 && doc.Version != version {
+		// The platform published a newer version since the heartbeat
+		if doc.Version == s.AppliedVersion() || s.alreadyAttempted(doc.Version) {
+			return nil
+		}
+		record.Version = doc.Version
+	}
+
+	base := s.applier.CurrentConfig()
+	if errs := doc.Validate(base); len(errs) > 0 {
+		s.finish(ctx, record, database.ConfigVersionInvalid, errs, nil)
+		return nil
+	}
+
+	// Capture the running configuration so the version can be undone
+	previousSchedules, previousHolidays, err := s.applier.CurrentSchedules()
+	if err != nil {
+		s.recordFailure(err)
+		return fmt.Errorf("failed to read current schedules: %w", err)
+	}
+	if doc.Schedules == nil {
+		previousSchedules = nil
+	}
+	if doc.Holidays == nil {
+		previousHolidays = nil
+	}
+	rollback := func(reason error) error {
+		if _, err := s.applier.Apply(ctx, base, previousSchedules, previousHolidays); err != nil {
+			return fmt.Errorf("%v; rollback failed: %w", reason, err)
+		}
+		return reason
+	}
+
+	target := base
+	doc.ApplyTo(&target)
+
+	s.logger.WithField("version", record.Version).Info("Applying config version")
+	result, err := s.applier.Apply(ctx, target, doc.Schedules, doc.Holidays)
+	if err != nil {
+		err = rollback(fmt.Errorf("failed to apply config: %w", err))
+		s.finish(ctx, record, database.ConfigVersionFailed, []FieldError{{Message: err.Error()}}, nil)
+		return nil
+	}
+
+	if len(result.ChangedAdapters) > 0 && s.config.SettleTime > 0 {
+		select {
+		case <-ctx.Done():
+			err := rollback(fmt.Errorf("stopped before adapters settled: %w", ctx.Err()))
+			s.finish(context.Background(), record, database.ConfigVersionRolledBack, []FieldError{{Message: err.Error()}}, nil)
+			return ctx.Err()
+		case <-time.After(s.config.SettleTime):
+		}
+	}
+
+	if unhealthy := s.applier.UnhealthyAdapters(result.ChangedAdapters); len(unhealthy) > 0 {
+		err := rollback(fmt.Errorf("adapters unhealthy after applying config: %s", strings.Join(unhealthy, ", ")))
+		s.finish(ctx, record, database.ConfigVersionRolledBack, []FieldError{{Message: err.Error()}}, nil)
+		return nil
+	}
+
+	s.finish(ctx, record, database.ConfigVersionApplied, nil, result)
+	return nil
+}
+
+// GetStats returns config synchronization statistics
+func (s *Syncer) GetStats() SyncStats {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return s.stats
+}
+
+// alreadyAttempted reports whether a version was rejected or rolled back before.
+// Such versions are not retried; the platform has to publish a new version.
+func (s *Syncer) alreadyAttempted(version int64) bool {
+	record, err := s.store.GetConfigVersion(version)
+	if err != nil {
+		s.logger.WithError(err).WithField("version", version).Warn("Failed to look up config version")
+		return false
+	}
+	return record != nil && record.Status != database.ConfigVersionApplied
+}
+
+// finish records the outcome of a sync attempt, prunes old versions and reports
+// the outcome to the platform
+func (s *Syncer) finish(ctx context.Context, record *database.ConfigVersion, status string, errs []FieldError, result *ApplyResult) {
+	now := time.Now()
+	record.Status = status
+	record.Error = joinFieldErrors(errs)
+	if status == database.ConfigVersionApplied || status == database.ConfigVersionRolledBack {
+		record.AppliedAt = &now
+	}
+
+	logger := s.logger.WithFields(logrus.Fields{
+		"version": record.Version,
+		"status":  status,
+	})
+
+	s.mutex.Lock()
+	s.stats.LastSyncAt = now.Unix()
+	s.stats.LastStatus = status
+	s.stats.LastError = record.Error
+	switch status {
+	case database.ConfigVersionApplied:
+		s.stats.AppliedVersion = record.Version
+		s.stats.RestartRequired = result.RestartRequired
+		s.stats.TotalApplied++
+	case database.ConfigVersionInvalid:
+		s.stats.TotalInvalid++
+	case database.ConfigVersionRolledBack:
+		s.stats.TotalRolledBack++
+	default:
+		s.stats.TotalFailures++
+	}
+	appliedVersion := s.stats.AppliedVersion
+	s.mutex.Unlock()
+
+	if status == database.ConfigVersionApplied {
+		logger = logger.WithField("changed_adapters", result.ChangedAdapters)
+		if len(result.RestartRequired) > 0 {
+			logger.WithField("restart_required", result.RestartRequired).Warn("Config version applied; some settings take effect after a restart")
+		} else {
+			logger.Info("Config version applied")
+		}
+	} else {
+		logger.WithField("error", record.Error).Warn("Config version not applied")
+	}
+
+	if record.Version > 0 {
+		if err := s.store.SaveConfigVersion(record); err != nil {
+			logger.WithError(err).Error("Failed to record config version")
+		} else if _, err := s.store.PruneConfigVersions(s.config.History); err != nil {
+			logger.WithError(err).Warn("Failed to prune config versions")
+		}
+	}
+
+	report := &client.ConfigReport{
+		Version:        record.Version,
+		Status:         status,
+		AppliedVersion: appliedVersion,
+		Timestamp:      now.UTC().Format(time.RFC3339),
+	}
+	if status == database.ConfigVersionInvalid {
+		for _, fieldErr := range errs {
+			report.Errors = append(report.Errors, client.ConfigFieldError{Field: fieldErr.Field, Message: fieldErr.Message})
+		}
+	} else {
+		report.Error = record.Error
+	}
+	if result != nil {
+		report.RestartRequired = result.RestartRequired
+	}
+
+	if err := s.source.ReportConfig(ctx, report); err != nil {
+		logger.WithError(err).Warn("Failed to report config outcome")
+	}
+}
+
+// recordFailure updates failure statistics for attempts that could not complete
+func (s *Syncer) recordFailure(err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.stats.TotalFailures++
+	s.stats.LastError = err.Error()
+}
+
+// Restore overlays the config version in effect onto cfg at startup, so that
+// versions applied live survive a restart. It returns the version restored, or
+// 0 when the bridge runs on its local configuration.
+func Restore(store Store, cfg *config.Config) (int64, error) {
+	record, err := store.GetAppliedConfigVersion()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get applied config version: %w", err)
+	}
+	if record == nil {
+		return 0, nil
+	}
+
+	doc, err := ParseDocument(record.Document)
+	if err != nil {
+		return 0, fmt.Errorf("stored config version %d: %w", record.Version, err)
+	}
+	if errs := doc.Validate(*cfg); len(errs) > 0 {
+		return 0, fmt.Errorf("stored config version %d is no longer valid: %s", record.Version, joinFieldErrors(errs))
+	}
+
+	doc.ApplyTo(cfg)
+	return record.Version, nil
+}
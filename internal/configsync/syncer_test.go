@@ -0,0 +1,278 @@
+package configsync
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gym-door-bridge/internal/client"
+	"gym-door-bridge/internal/config"
+	"gym-door-bridge/internal/database"
+)
+
+// mockSource serves a fixed desired config and records reports
+type mockSource struct {
+	document string
+	err      error
+	fetches  int
+	reports  []*client.ConfigReport
+}
+
+func (m *mockSource) GetDesiredConfig(ctx context.Context) (json.RawMessage, error) {
+	m.fetches++
+	return json.RawMessage(m.document), m.err
+}
+
+func (m *mockSource) ReportConfig(ctx context.Context, report *client.ConfigReport) error {
+	m.reports = append(m.reports, report)
+	return nil
+}
+
+// mockStore keeps config versions in memory
+type mockStore struct {
+	versions map[int64]*database.ConfigVersion
+}
+
+func newMockStore() *mockStore {
+	return &mockStore{versions: make(map[int64]*database.ConfigVersion)}
+}
+
+func (m *mockStore) SaveConfigVersion(version *database.ConfigVersion) error {
+	stored := *version
+	m.versions[version.Version] = &stored
+	return nil
+}
+
+func (m *mockStore) GetConfigVersion(version int64) (*database.ConfigVersion, error) {
+	return m.versions[version], nil
+}
+
+func (m *mockStore) GetAppliedConfigVersion() (*database.ConfigVersion, error) {
+	var applied *database.ConfigVersion
+	for _, version := range m.versions {
+		if version.Status == database.ConfigVersionApplied && (applied == nil || version.AppliedAt.After(*applied.AppliedAt)) {
+			applied = version
+		}
+	}
+	return applied, nil
+}
+
+func (m *mockStore) PruneConfigVersions(keep int) (int64, error) {
+	var versions []int64
+	for version := range m.versions {
+		versions = append(versions, version)
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i] > versions[j] })
+
+	var deleted int64
+	for _, version := range versions[min(keep, len(versions)):] {
+		delete(m.versions, version)
+		deleted++
+	}
+	return deleted, nil
+}
+
+// mockApplier records applied configurations
+type mockApplier struct {
+	config    config.Config
+	schedules []ScheduleSpec
+	applied   []config.Config
+	applyErr  error
+	unhealthy []string
+}
+
+func newMockApplier() *mockApplier {
+	return &mockApplier{config: *config.DefaultConfig()}
+}
+
+func (m *mockApplier) CurrentConfig() config.Config {
+	return m.config
+}
+
+func (m *mockApplier) CurrentSchedules() ([]ScheduleSpec, []HolidaySpec, error) {
+	return m.schedules, []HolidaySpec{}, nil
+}
+
+func (m *mockApplier) Apply(ctx context.Context, cfg config.Config, schedules []ScheduleSpec, holidays []HolidaySpec) (*ApplyResult, error) {
+	m.applied = append(m.applied, cfg)
+	if len(m.applied) == 1 && m.applyErr != nil {
+		return nil, m.applyErr
+	}
+	m.config = cfg
+	if schedules != nil {
+		m.schedules = schedules
+	}
+	return &ApplyResult{ChangedAdapters: cfg.EnabledAdapters, RestartRequired: []string{"api"}}, nil
+}
+
+func (m *mockApplier) UnhealthyAdapters(names []string) []string {
+	return m.unhealthy
+}
+
+func newTestLogger() *logrus.Logger {
+	logger := logrus.New()
+	logger.SetLevel(logrus.ErrorLevel) // Reduce noise in tests
+	return logger
+}
+
+func TestSyncer_Apply(t *testing.T) {
+	source := &mockSource{document: validDocument}
+	store := newMockStore()
+	applier := newMockApplier()
+	syncer := NewSyncer(source, store, applier, Config{History: 5}, newTestLogger())
+
+	if err := syncer.Sync(context.Background(), 7); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+
+	if syncer.AppliedVersion() != 7 {
+		t.Errorf("expected version 7 applied, got %d", syncer.AppliedVersion())
+	}
+	if len(applier.config.EnabledAdapters) != 2 || len(applier.schedules) != 1 {
+		t.Errorf("unexpected applied configuration: %v, schedules %v", applier.config.EnabledAdapters, applier.schedules)
+	}
+	if record := store.versions[7]; record == nil || record.Status != database.ConfigVersionApplied || record.AppliedAt == nil {
+		t.Errorf("expected version 7 recorded as applied, got %+v", record)
+	}
+	if len(source.reports) != 1 {
+		t.Fatalf("expected one report, got %d", len(source.reports))
+	}
+	report := source.reports[0]
+	if report.Status != client.ConfigStatusApplied || report.AppliedVersion != 7 || len(report.RestartRequired) != 1 {
+		t.Errorf("unexpected report: %+v", report)
+	}
+
+	// The version in effect is not fetched again
+	if err := syncer.Sync(context.Background(), 7); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	if source.fetches != 1 {
+		t.Errorf("expected one fetch, got %d", source.fetches)
+	}
+
+	// Restore puts the version back into effect on the next start
+	restored := config.DefaultConfig()
+	version, err := Restore(store, restored)
+	if err != nil || version != 7 {
+		t.Fatalf("Restore() = %d, %v", version, err)
+	}
+	if len(restored.EnabledAdapters) != 2 || len(restored.Doors) != 2 {
+		t.Errorf("unexpected restored configuration: %+v", restored)
+	}
+}
+
+func TestSyncer_Invalid(t *testing.T) {
+	source := &mockSource{document: `{"version": 3, "adapters": [{"name": "teleporter"}]}`}
+	store := newMockStore()
+	applier := newMockApplier()
+	syncer := NewSyncer(source, store, applier, Config{}, newTestLogger())
+
+	if err := syncer.Sync(context.Background(), 3); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+
+	if len(applier.applied) != 0 {
+		t.Error("an invalid document must not be applied")
+	}
+	if record := store.versions[3]; record == nil || record.Status != database.ConfigVersionInvalid || record.Error == "" {
+		t.Errorf("expected version 3 recorded as invalid, got %+v", record)
+	}
+	report := source.reports[0]
+	if report.Status != client.ConfigStatusInvalid || len(report.Errors) != 1 || report.Errors[0].Field != "adapters[0].name" {
+		t.Errorf("unexpected report: %+v", report)
+	}
+
+	// A rejected version is not retried
+	if err := syncer.Sync(context.Background(), 3); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	if source.fetches != 1 {
+		t.Errorf("expected one fetch, got %d", source.fetches)
+	}
+
+	// Unparseable documents are rejected under the announced version
+	source.document = `{"version": 4, "adapters": "simulator"}`
+	if err := syncer.Sync(context.Background(), 4); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	if record := store.versions[4]; record == nil || record.Status != database.ConfigVersionInvalid {
+		t.Errorf("expected version 4 recorded as invalid, got %+v", record)
+	}
+	if syncer.AppliedVersion() != 0 {
+		t.Errorf("expected the local configuration in effect, got version %d", syncer.AppliedVersion())
+	}
+}
+
+func TestSyncer_Rollback(t *testing.T) {
+	tests := []struct {
+		name      string
+		applyErr  error
+		unhealthy []string
+		status    string
+	}{
+		{name: "unhealthy adapters", unhealthy: []string{"webhook"}, status: client.ConfigStatusRolledBack},
+		{name: "apply failure", applyErr: errors.New("failed to initialize adapter webhook"), status: client.ConfigStatusFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := &mockSource{document: validDocument}
+			store := newMockStore()
+			applier := newMockApplier()
+			applier.applyErr = tt.applyErr
+			applier.unhealthy = tt.unhealthy
+			syncer := NewSyncer(source, store, applier, Config{SettleTime: time.Millisecond}, newTestLogger())
+
+			if err := syncer.Sync(context.Background(), 7); err != nil {
+				t.Fatalf("Sync() error = %v", err)
+			}
+
+			if len(applier.applied) != 2 {
+				t.Fatalf("expected the version and its rollback to be applied, got %d applies", len(applier.applied))
+			}
+			if fmt.Sprint(applier.config.EnabledAdapters) != "[simulator]" {
+				t.Errorf("expected the previous configuration back in effect, got %v", applier.config.EnabledAdapters)
+			}
+			if record := store.versions[7]; record == nil || record.Status != tt.status {
+				t.Errorf("expected version 7 recorded as %s, got %+v", tt.status, record)
+			}
+			if report := source.reports[0]; report.Status != tt.status || report.AppliedVersion != 0 || report.Error == "" {
+				t.Errorf("unexpected report: %+v", report)
+			}
+			if syncer.AppliedVersion() != 0 {
+				t.Errorf("expected no version in effect, got %d", syncer.AppliedVersion())
+			}
+		})
+	}
+}
+
+func TestSyncer_Notify(t *testing.T) {
+	source := &mockSource{document: validDocument}
+	applier := newMockApplier()
+	syncer := NewSyncer(source, newMockStore(), applier, Config{}, newTestLogger())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go syncer.Start(ctx)
+
+	// Notifying never blocks, however often it is called
+	for i := 0; i < 10; i++ {
+		syncer.Notify(7)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for syncer.AppliedVersion() != 7 {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for version 7 to be applied")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if stats := syncer.GetStats(); stats.DesiredVersion != 7 || stats.TotalApplied != 1 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+}
@@ -0,0 +1,125 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// SetAntiPassbackState records a member's state in a zone, replacing the previous one
+func (db *DB) SetAntiPassbackState(state *AntiPassbackState) error {
+	if state == nil {
+		return fmt.Errorf("anti-passback state cannot be nil")
+	}
+	if state.InternalUserID == "" {
+		return fmt.Errorf("internal user ID cannot be empty")
+	}
+	if state.State != AntiPassbackIn && state.State != AntiPassbackOut {
+		return fmt.Errorf("invalid anti-passback state: %s", state.State)
+	}
+
+	query := `
+		INSERT OR REPLACE INTO anti_passback_state (internal_user_id, zone, state, door_id, updated_at)
+		VALUES (?, ?, ?, ?, ?)
+	`
+
+	if _, err := db.conn.Exec(query, state.InternalUserID, state.Zone, state.State, state.DoorID, state.UpdatedAt.UTC()); err != nil {
+		return fmt.Errorf("failed to set anti-passback state for %s: %w", state.InternalUserID, err)
+	}
+
+	return nil
+}
+
+// GetAntiPassbackState retrieves a member's state in a zone
+// Returns nil if the member has no recorded state in the zone
+func (db *DB) GetAntiPassbackState(internalUserID, zone string) (*AntiPassbackState, error) {
+	if internalUserID == "" {
+		return nil, fmt.Errorf("internal user ID cannot be empty")
+	}
+
+	query := `
+		SELECT internal_user_id, zone, state, door_id, updated_at
+		FROM anti_passback_state
+		WHERE internal_user_id = ? AND zone = ?
+	`
+
+	var state AntiPassbackState
+	err := db.conn.QueryRow(query, internalUserID, zone).Scan(
+		&state.InternalUserID,
+		&state.Zone,
+		&state.State,
+		&state.DoorID,
+		&state.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // No recorded state
+		}
+		return nil, fmt.Errorf("failed to get anti-passback state: %w", err)
+	}
+
+	return &state, nil
+}
+
+// ListAntiPassbackStates retrieves a member's state in every zone ordered by zone
+func (db *DB) ListAntiPassbackStates(internalUserID string) ([]AntiPassbackState, error) {
+	if internalUserID == "" {
+		return nil, fmt.Errorf("internal user ID cannot be empty")
+	}
+
+	query := `
+		SELECT internal_user_id, zone, state, door_id, updated_at
+		FROM anti_passback_state
+		WHERE internal_user_id = ?
+		ORDER BY zone
+	`
+
+	rows, err := db.conn.Query(query, internalUserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list anti-passback states: %w", err)
+	}
+	defer rows.Close()
+
+	var states []AntiPassbackState
+	for rows.Next() {
+		var state AntiPassbackState
+		if err := rows.Scan(
+			&state.InternalUserID,
+			&state.Zone,
+			&state.State,
+			&state.DoorID,
+			&state.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan anti-passback state: %w", err)
+		}
+		states = append(states, state)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating anti-passback states: %w", err)
+	}
+
+	return states, nil
+}
+
+// ResetAntiPassbackStates forgets a member's state in every zone, or every
+// member's state when internalUserID is empty, and returns the number of
+// states removed
+func (db *DB) ResetAntiPassbackStates(internalUserID string) (int64, error) {
+	var result sql.Result
+	var err error
+	if internalUserID == "" {
+		result, err = db.conn.Exec(`DELETE FROM anti_passback_state`)
+	} else {
+		result, err = db.conn.Exec(`DELETE FROM anti_passback_state WHERE internal_user_id = ?`, internalUserID)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to reset anti-passback states: %w", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	return deleted, nil
+}
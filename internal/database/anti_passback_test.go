@@ -0,0 +1,68 @@
+package database
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAntiPassbackState(t *testing.T) {
+	db := setupTestDB(t, TierNormal)
+
+	if state, err := db.GetAntiPassbackState("user_1", ""); err != nil || state != nil {
+		t.Fatalf("expected no state, got %+v (%v)", state, err)
+	}
+
+	enteredAt := time.Date(2026, 3, 8, 14, 30, 0, 0, time.UTC)
+	if err := db.SetAntiPassbackState(&AntiPassbackState{
+		InternalUserID: "user_1",
+		State:          AntiPassbackIn,
+		DoorID:         "front",
+		UpdatedAt:      enteredAt,
+	}); err != nil {
+		t.Fatalf("failed to set state: %v", err)
+	}
+	if err := db.SetAntiPassbackState(&AntiPassbackState{
+		InternalUserID: "user_1",
+		Zone:           "pool",
+		State:          AntiPassbackOut,
+		UpdatedAt:      enteredAt.Add(time.Hour),
+	}); err != nil {
+		t.Fatalf("failed to set pool state: %v", err)
+	}
+	if err := db.SetAntiPassbackState(&AntiPassbackState{InternalUserID: "user_2", State: AntiPassbackIn, UpdatedAt: enteredAt}); err != nil {
+		t.Fatalf("failed to set second member state: %v", err)
+	}
+
+	state, err := db.GetAntiPassbackState("user_1", "")
+	if err != nil {
+		t.Fatalf("failed to get state: %v", err)
+	}
+	if state == nil || state.State != AntiPassbackIn || state.DoorID != "front" || !state.UpdatedAt.Equal(enteredAt) {
+		t.Fatalf("unexpected state: %+v", state)
+	}
+
+	states, err := db.ListAntiPassbackStates("user_1")
+	if err != nil {
+		t.Fatalf("failed to list states: %v", err)
+	}
+	if len(states) != 2 || states[1].Zone != "pool" || states[1].State != AntiPassbackOut {
+		t.Errorf("unexpected states: %+v", states)
+	}
+
+	if err := db.SetAntiPassbackState(&AntiPassbackState{InternalUserID: "user_1", State: "sideways"}); err == nil {
+		t.Errorf("expected error for an invalid state")
+	}
+
+	removed, err := db.ResetAntiPassbackStates("user_1")
+	if err != nil || removed != 2 {
+		t.Errorf("expected 2 states removed, got %d (%v)", removed, err)
+	}
+	if state, _ := db.GetAntiPassbackState("user_2", ""); state == nil {
+		t.Errorf("resetting one member must keep other members")
+	}
+
+	removed, err = db.ResetAntiPassbackStates("")
+	if err != nil || removed != 1 {
+		t.Errorf("expected 1 state removed, got %d (%v)", removed, err)
+	}
+}
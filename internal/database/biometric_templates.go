@@ -0,0 +1,150 @@
+package database
+
+import (
+	"bytes"
+	"database/sql"
+	"fmt"
+)
+
+// SaveBiometricTemplate stores a template encrypted, replacing the template held
+// for the same user and finger. It reports whether the stored template changed.
+func (db *DB) SaveBiometricTemplate(template *BiometricTemplate) (bool, error) {
+	if template.DeviceUserID <= 0 {
+		return false, fmt.Errorf("device user ID must be positive")
+	}
+	if len(template.Data) == 0 {
+		return false, fmt.Errorf("template data cannot be empty")
+	}
+
+	existing, err := db.GetBiometricTemplate(template.DeviceUserID, template.FingerIndex)
+	if err != nil {
+		return false, err
+	}
+	if existing != nil && existing.Flag == template.Flag && bytes.Equal(existing.Data, template.Data) {
+		return false, nil
+	}
+
+	encrypted, err := db.Encrypt(template.Data)
+	if err != nil {
+		return false, fmt.Errorf("failed to encrypt template: %w", err)
+	}
+
+	_, err = db.conn.Exec(`
+		INSERT INTO biometric_templates
+			(device_user_id, finger_index, flag, template_data, source_terminal, captured_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+		ON CONFLICT(device_user_id, finger_index) DO UPDATE SET
+			flag = excluded.flag,
+			template_data = excluded.template_data,
+			source_terminal = excluded.source_terminal,
+			updated_at = CURRENT_TIMESTAMP
+	`, template.DeviceUserID, template.FingerIndex, template.Flag, encrypted, template.SourceTerminal)
+	if err != nil {
+		return false, fmt.Errorf("failed to save biometric template: %w", err)
+	}
+
+	return true, nil
+}
+
+// GetBiometricTemplate returns the decrypted template for a user and finger
+func (db *DB) GetBiometricTemplate(deviceUserID, fingerIndex int) (*BiometricTemplate, error) {
+	row := db.conn.QueryRow(`
+		SELECT device_user_id, finger_index, flag, template_data, source_terminal, captured_at, updated_at
+		FROM biometric_templates
+		WHERE device_user_id = ? AND finger_index = ?
+	`, deviceUserID, fingerIndex)
+
+	template, err := db.scanBiometricTemplate(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return template, nil
+}
+
+// ListBiometricTemplates returns every stored template, decrypted
+func (db *DB) ListBiometricTemplates() ([]BiometricTemplate, error) {
+	rows, err := db.conn.Query(`
+		SELECT device_user_id, finger_index, flag, template_data, source_terminal, captured_at, updated_at
+		FROM biometric_templates
+		ORDER BY device_user_id, finger_index
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list biometric templates: %w", err)
+	}
+	defer rows.Close()
+
+	var templates []BiometricTemplate
+	for rows.Next() {
+		template, err := db.scanBiometricTemplate(rows)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, *template)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating biometric templates: %w", err)
+	}
+
+	return templates, nil
+}
+
+// CountBiometricTemplates returns the number of stored templates
+func (db *DB) CountBiometricTemplates() (int64, error) {
+	var count int64
+	if err := db.conn.QueryRow(`SELECT COUNT(*) FROM biometric_templates`).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count biometric templates: %w", err)
+	}
+	return count, nil
+}
+
+// DeleteBiometricTemplates removes the templates of a device user
+func (db *DB) DeleteBiometricTemplates(deviceUserID int) (int64, error) {
+	result, err := db.conn.Exec(`DELETE FROM biometric_templates WHERE device_user_id = ?`, deviceUserID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete biometric templates: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// PurgeBiometricTemplates removes every stored template, used when the site
+// withdraws consent to template backup
+func (db *DB) PurgeBiometricTemplates() (int64, error) {
+	result, err := db.conn.Exec(`DELETE FROM biometric_templates`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge biometric templates: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// scanBiometricTemplate scans and decrypts a template row
+func (db *DB) scanBiometricTemplate(row rowScanner) (*BiometricTemplate, error) {
+	var template BiometricTemplate
+	var encrypted string
+	err := row.Scan(
+		&template.DeviceUserID,
+		&template.FingerIndex,
+		&template.Flag,
+		&encrypted,
+		&template.SourceTerminal,
+		&template.CapturedAt,
+		&template.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan biometric template: %w", err)
+	}
+
+	template.Data, err = db.Decrypt(encrypted)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt template for device user %d: %w", template.DeviceUserID, err)
+	}
+
+	return &template, nil
+}
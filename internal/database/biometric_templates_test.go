@@ -0,0 +1,58 @@
+package database
+
+import (
+	"bytes"
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+func TestBiometricTemplates(t *testing.T) {
+	db := setupTestDB(t, TierNormal)
+	data := []byte{0x4f, 0x43, 0x53, 0x53, 0x00, 0x01}
+
+	changed, err := db.SaveBiometricTemplate(&BiometricTemplate{DeviceUserID: 42, FingerIndex: 6, Flag: 1, Data: data, SourceTerminal: "front"})
+	if err != nil || !changed {
+		t.Fatalf("expected template to be saved, got changed=%v (%v)", changed, err)
+	}
+	changed, err = db.SaveBiometricTemplate(&BiometricTemplate{DeviceUserID: 42, FingerIndex: 6, Flag: 1, Data: data, SourceTerminal: "back"})
+	if err != nil || changed {
+		t.Errorf("expected identical template to be unchanged, got changed=%v (%v)", changed, err)
+	}
+	if _, err := db.SaveBiometricTemplate(&BiometricTemplate{DeviceUserID: 43, FingerIndex: 0, Flag: 1, Data: []byte{1}}); err != nil {
+		t.Fatalf("failed to save template: %v", err)
+	}
+
+	// Templates are only held encrypted
+	var stored string
+	if err := db.conn.QueryRow(`SELECT template_data FROM biometric_templates WHERE device_user_id = 42`).Scan(&stored); err != nil {
+		t.Fatalf("failed to read stored template: %v", err)
+	}
+	if strings.Contains(stored, string(data)) || stored == base64.StdEncoding.EncodeToString(data) {
+		t.Error("template stored without encryption")
+	}
+
+	template, err := db.GetBiometricTemplate(42, 6)
+	if err != nil || template == nil {
+		t.Fatalf("expected template, got %+v (%v)", template, err)
+	}
+	if !bytes.Equal(template.Data, data) || template.SourceTerminal != "front" {
+		t.Errorf("unexpected template: %+v", template)
+	}
+	if missing, err := db.GetBiometricTemplate(42, 1); err != nil || missing != nil {
+		t.Errorf("expected no template, got %+v (%v)", missing, err)
+	}
+
+	if deleted, err := db.DeleteBiometricTemplates(43); err != nil || deleted != 1 {
+		t.Errorf("expected 1 template deleted, got %d (%v)", deleted, err)
+	}
+	if templates, err := db.ListBiometricTemplates(); err != nil || len(templates) != 1 {
+		t.Errorf("unexpected templates: %+v (%v)", templates, err)
+	}
+	if purged, err := db.PurgeBiometricTemplates(); err != nil || purged != 1 {
+		t.Errorf("expected 1 template purged, got %d (%v)", purged, err)
+	}
+	if count, _ := db.CountBiometricTemplates(); count != 0 {
+		t.Errorf("expected no templates, got %d", count)
+	}
+}
@@ -0,0 +1,83 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// RecordProcessedCommand stores the outcome of a remote command
+func (db *DB) RecordProcessedCommand(command *ProcessedCommand) error {
+	if command == nil {
+		return fmt.Errorf("processed command cannot be nil")
+	}
+	if command.CommandID == "" {
+		return fmt.Errorf("command ID cannot be empty")
+	}
+
+	query := `
+		INSERT OR REPLACE INTO processed_commands (command_id, command_type, status, result, error, processed_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+
+	if _, err := db.conn.Exec(query,
+		command.CommandID,
+		command.CommandType,
+		command.Status,
+		command.Result,
+		command.Error,
+		command.ProcessedAt.UTC(),
+	); err != nil {
+		return fmt.Errorf("failed to record processed command %s: %w", command.CommandID, err)
+	}
+
+	return nil
+}
+
+// GetProcessedCommand retrieves the recorded outcome of a remote command
+// Returns nil if the command has not been processed
+func (db *DB) GetProcessedCommand(commandID string) (*ProcessedCommand, error) {
+	if commandID == "" {
+		return nil, fmt.Errorf("command ID cannot be empty")
+	}
+
+	query := `
+		SELECT command_id, command_type, status, result, error, processed_at
+		FROM processed_commands
+		WHERE command_id = ?
+	`
+
+	var command ProcessedCommand
+	err := db.conn.QueryRow(query, commandID).Scan(
+		&command.CommandID,
+		&command.CommandType,
+		&command.Status,
+		&command.Result,
+		&command.Error,
+		&command.ProcessedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // Not processed yet
+		}
+		return nil, fmt.Errorf("failed to get processed command: %w", err)
+	}
+
+	return &command, nil
+}
+
+// PurgeProcessedCommands removes command outcomes recorded before the given time
+// and returns the number removed
+func (db *DB) PurgeProcessedCommands(before time.Time) (int64, error) {
+	result, err := db.conn.Exec(`DELETE FROM processed_commands WHERE processed_at < ?`, before.UTC())
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge processed commands: %w", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	return deleted, nil
+}
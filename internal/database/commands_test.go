@@ -0,0 +1,57 @@
+package database
+
+import (
+	"testing"
+	"time"
+)
+
+func TestProcessedCommands(t *testing.T) {
+	db := setupTestDB(t, TierNormal)
+
+	if command, err := db.GetProcessedCommand("cmd_1"); err != nil || command != nil {
+		t.Fatalf("expected no processed command, got %+v (%v)", command, err)
+	}
+
+	processedAt := time.Date(2026, 3, 8, 14, 30, 0, 0, time.UTC)
+	if err := db.RecordProcessedCommand(&ProcessedCommand{
+		CommandID:   "cmd_1",
+		CommandType: "unlock_door",
+		Status:      "succeeded",
+		Result:      `{"doorId":"front"}`,
+		ProcessedAt: processedAt,
+	}); err != nil {
+		t.Fatalf("failed to record command: %v", err)
+	}
+	if err := db.RecordProcessedCommand(&ProcessedCommand{
+		CommandID:   "cmd_2",
+		CommandType: "restart_adapter",
+		Status:      "failed",
+		Error:       "adapter not found",
+		ProcessedAt: processedAt.Add(2 * time.Hour),
+	}); err != nil {
+		t.Fatalf("failed to record second command: %v", err)
+	}
+
+	command, err := db.GetProcessedCommand("cmd_1")
+	if err != nil {
+		t.Fatalf("failed to get command: %v", err)
+	}
+	if command == nil || command.Status != "succeeded" || command.Result != `{"doorId":"front"}` || !command.ProcessedAt.Equal(processedAt) {
+		t.Errorf("unexpected command: %+v", command)
+	}
+
+	purged, err := db.PurgeProcessedCommands(processedAt.Add(time.Hour))
+	if err != nil || purged != 1 {
+		t.Fatalf("expected 1 command purged, got %d (%v)", purged, err)
+	}
+	if command, _ := db.GetProcessedCommand("cmd_1"); command != nil {
+		t.Errorf("expected cmd_1 to be purged, got %+v", command)
+	}
+	if command, _ := db.GetProcessedCommand("cmd_2"); command == nil || command.Error != "adapter not found" {
+		t.Errorf("expected cmd_2 to remain, got %+v", command)
+	}
+
+	if err := db.RecordProcessedCommand(&ProcessedCommand{}); err == nil {
+		t.Error("expected error for empty command ID")
+	}
+}
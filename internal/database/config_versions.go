@@ -0,0 +1,183 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// SaveConfigVersion stores a configuration document and the outcome of applying
+// it, replacing an earlier record of the same version
+func (db *DB) SaveConfigVersion(version *ConfigVersion) error {
+	if version == nil {
+		return fmt.Errorf("config version cannot be nil")
+	}
+	if version.Version <= 0 {
+		return fmt.Errorf("config version must be positive")
+	}
+	if len(version.Document) == 0 {
+		return fmt.Errorf("config document cannot be empty")
+	}
+
+	encrypted, err := db.Encrypt(version.Document)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt config document: %w", err)
+	}
+
+	var appliedAt interface{}
+	if version.AppliedAt != nil {
+		appliedAt = version.AppliedAt.UTC()
+	}
+
+	query := `
+		INSERT OR REPLACE INTO config_versions (version, document, status, error, received_at, applied_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+
+	if _, err := db.conn.Exec(query,
+		version.Version,
+		encrypted,
+		version.Status,
+		version.Error,
+		version.ReceivedAt.UTC(),
+		appliedAt,
+	); err != nil {
+		return fmt.Errorf("failed to save config version %d: %w", version.Version, err)
+	}
+
+	return nil
+}
+
+// GetConfigVersion retrieves a configuration version
+// Returns nil if the version is not stored
+func (db *DB) GetConfigVersion(version int64) (*ConfigVersion, error) {
+	query := `
+		SELECT version, document, status, error, received_at, applied_at
+		FROM config_versions
+		WHERE version = ?
+	`
+
+	record, err := db.scanConfigVersion(db.conn.QueryRow(query, version))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // Version not stored
+		}
+		return nil, err
+	}
+
+	return record, nil
+}
+
+// GetAppliedConfigVersion retrieves the configuration version in effect: the
+// applied version that was put into effect last
+// Returns nil if no platform configuration has been applied
+func (db *DB) GetAppliedConfigVersion() (*ConfigVersion, error) {
+	query := `
+		SELECT version, document, status, error, received_at, applied_at
+		FROM config_versions
+		WHERE status = ?
+		ORDER BY applied_at DESC, version DESC
+		LIMIT 1
+	`
+
+	record, err := db.scanConfigVersion(db.conn.QueryRow(query, ConfigVersionApplied))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // Nothing applied yet
+		}
+		return nil, err
+	}
+
+	return record, nil
+}
+
+// ListConfigVersions retrieves all stored configuration versions, newest first
+func (db *DB) ListConfigVersions() ([]ConfigVersion, error) {
+	query := `
+		SELECT version, document, status, error, received_at, applied_at
+		FROM config_versions
+		ORDER BY version DESC
+	`
+
+	rows, err := db.conn.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list config versions: %w", err)
+	}
+	defer rows.Close()
+
+	var versions []ConfigVersion
+	for rows.Next() {
+		record, err := db.scanConfigVersion(rows)
+		if err != nil {
+			return nil, err
+		}
+		versions = append(versions, *record)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating config versions: %w", err)
+	}
+
+	return versions, nil
+}
+
+// PruneConfigVersions removes all but the newest keep versions and returns the
+// number removed. The version in effect is always kept.
+func (db *DB) PruneConfigVersions(keep int) (int64, error) {
+	if keep < 1 {
+		return 0, fmt.Errorf("at least one config version must be kept")
+	}
+
+	query := `
+		DELETE FROM config_versions
+		WHERE version NOT IN (SELECT version FROM config_versions ORDER BY version DESC LIMIT ?)
+		AND version NOT IN (
+			SELECT version FROM config_versions
+			WHERE status = ?
+			ORDER BY applied_at DESC, version DESC
+			LIMIT 1
+		)
+	`
+
+	result, err := db.conn.Exec(query, keep, ConfigVersionApplied)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune config versions: %w", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	return deleted, nil
+}
+
+// scanConfigVersion scans and decrypts a config_versions row
+func (db *DB) scanConfigVersion(row rowScanner) (*ConfigVersion, error) {
+	var record ConfigVersion
+	var encrypted string
+	var appliedAt sql.NullTime
+	err := row.Scan(
+		&record.Version,
+		&encrypted,
+		&record.Status,
+		&record.Error,
+		&record.ReceivedAt,
+		&appliedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan config version: %w", err)
+	}
+
+	if appliedAt.Valid {
+		record.AppliedAt = &appliedAt.Time
+	}
+	record.Document, err = db.Decrypt(encrypted)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt config version %d: %w", record.Version, err)
+	}
+
+	return &record, nil
+}
@@ -0,0 +1,98 @@
+package database
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConfigVersions(t *testing.T) {
+	db := setupTestDB(t, TierNormal)
+
+	if version, err := db.GetAppliedConfigVersion(); err != nil || version != nil {
+		t.Fatalf("expected no applied config version, got %+v (%v)", version, err)
+	}
+
+	receivedAt := time.Date(2026, 5, 4, 9, 0, 0, 0, time.UTC)
+	for i, status := range []string{ConfigVersionApplied, ConfigVersionApplied, ConfigVersionRolledBack, ConfigVersionInvalid} {
+		version := &ConfigVersion{
+			Version:    int64(i + 1),
+			Document:   []byte(`{"version":1,"adapters":[{"name":"webhook","settings":{"secret":"s3cret"}}]}`),
+			Status:     status,
+			ReceivedAt: receivedAt.Add(time.Duration(i) * time.Hour),
+		}
+		if status == ConfigVersionApplied || status == ConfigVersionRolledBack {
+			appliedAt := version.ReceivedAt.Add(time.Minute)
+			version.AppliedAt = &appliedAt
+		}
+		if status == ConfigVersionInvalid {
+			version.Error = "adapters[0].name: unknown adapter type"
+		}
+		if err := db.SaveConfigVersion(version); err != nil {
+			t.Fatalf("failed to save config version %d: %v", version.Version, err)
+		}
+	}
+
+	// Documents are encrypted at rest
+	var stored string
+	if err := db.conn.QueryRow(`SELECT document FROM config_versions WHERE version = 1`).Scan(&stored); err != nil {
+		t.Fatalf("failed to read stored document: %v", err)
+	}
+	if stored == "" || strings.Contains(stored, "s3cret") {
+		t.Errorf("config document should be encrypted, got %q", stored)
+	}
+
+	applied, err := db.GetAppliedConfigVersion()
+	if err != nil {
+		t.Fatalf("failed to get applied config version: %v", err)
+	}
+	if applied == nil || applied.Version != 2 || applied.AppliedAt == nil {
+		t.Fatalf("expected version 2 in effect, got %+v", applied)
+	}
+	if string(applied.Document) != `{"version":1,"adapters":[{"name":"webhook","settings":{"secret":"s3cret"}}]}` {
+		t.Errorf("unexpected document: %s", applied.Document)
+	}
+
+	invalid, err := db.GetConfigVersion(4)
+	if err != nil || invalid == nil || invalid.Status != ConfigVersionInvalid || invalid.Error == "" || invalid.AppliedAt != nil {
+		t.Errorf("unexpected invalid version: %+v (%v)", invalid, err)
+	}
+	if missing, err := db.GetConfigVersion(9); err != nil || missing != nil {
+		t.Errorf("expected no version 9, got %+v (%v)", missing, err)
+	}
+
+	// Re-applying an older version puts it back into effect
+	reapplied := receivedAt.Add(10 * time.Hour)
+	version1, _ := db.GetConfigVersion(1)
+	version1.AppliedAt = &reapplied
+	if err := db.SaveConfigVersion(version1); err != nil {
+		t.Fatalf("failed to re-apply version 1: %v", err)
+	}
+	if applied, _ := db.GetAppliedConfigVersion(); applied == nil || applied.Version != 1 {
+		t.Errorf("expected version 1 in effect, got %+v", applied)
+	}
+
+	// Pruning keeps the newest versions and the version in effect
+	deleted, err := db.PruneConfigVersions(2)
+	if err != nil {
+		t.Fatalf("failed to prune config versions: %v", err)
+	}
+	if deleted != 1 {
+		t.Errorf("expected 1 version pruned, got %d", deleted)
+	}
+	versions, err := db.ListConfigVersions()
+	if err != nil {
+		t.Fatalf("failed to list config versions: %v", err)
+	}
+	var kept []int64
+	for _, version := range versions {
+		kept = append(kept, version.Version)
+	}
+	if len(kept) != 3 || kept[0] != 4 || kept[1] != 3 || kept[2] != 1 {
+		t.Errorf("expected versions [4 3 1] kept, got %v", kept)
+	}
+
+	if _, err := db.PruneConfigVersions(0); err == nil {
+		t.Error("pruning every version should fail")
+	}
+}
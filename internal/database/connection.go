@@ -1,7 +1,6 @@
 package database
 
 import (
-	"crypto/aes"
 	"crypto/cipher"
 	"crypto/rand"
 	"database/sql"
@@ -10,6 +9,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"sync"
 
 	_ "github.com/mattn/go-sqlite3"
 )
@@ -25,15 +25,22 @@ const (
 
 // DB wraps the SQLite database connection with encryption capabilities
 type DB struct {
-	conn   *sql.DB
-	cipher cipher.AEAD
-	tier   PerformanceTier
+	conn     *sql.DB
+	cipher   cipher.AEAD         // Cipher of the active key
+	keyID    int                 // ID of the active key; 0 writes the unversioned format
+	ciphers  map[int]cipher.AEAD // Every key values may still be encrypted with
+	keys     *KeyRing
+	indexKey []byte // Key of blind indexes over encrypted columns
+	keyStore SecretStore
+	keyMu    sync.RWMutex
+	tier     PerformanceTier
 }
 
 // Config holds database configuration options
 type Config struct {
 	DatabasePath   string
 	EncryptionKey  []byte
+	Keys           *KeyRing // Managed data-encryption keys; replaces EncryptionKey when set
 	PerformanceTier PerformanceTier
 }
 
@@ -51,21 +58,26 @@ func NewDB(config Config) (*DB, error) {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
 
-	// Set up AES-GCM encryption
-	block, err := aes.NewCipher(config.EncryptionKey)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create cipher: %w", err)
-	}
-
-	gcm, err := cipher.NewGCM(block)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create GCM: %w", err)
+	db := &DB{
+		conn: conn,
+		tier: config.PerformanceTier,
 	}
 
-	db := &DB{
-		conn:   conn,
-		cipher: gcm,
-		tier:   config.PerformanceTier,
+	// Set up AES-GCM encryption
+	if config.Keys != nil {
+		if err := db.useKeys(config.Keys); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	} else {
+		gcm, err := newGCM(config.EncryptionKey)
+		if err != nil {
+			conn.Close()
+			return nil, err
+		}
+		db.cipher = gcm
+		db.ciphers = map[int]cipher.AEAD{0: gcm}
+		db.indexKey = deriveIndexKey(config.EncryptionKey)
 	}
 
 	// Configure tier-specific pragmas
@@ -116,31 +128,48 @@ func (db *DB) Close() error {
 	return db.conn.Close()
 }
 
-// Encrypt encrypts data using AES-GCM
+// Encrypt encrypts data using AES-GCM with the active key. The result is
+// prefixed with the key's ID so values survive key rotation.
 func (db *DB) Encrypt(plaintext []byte) (string, error) {
-	nonce := make([]byte, db.cipher.NonceSize())
+	db.keyMu.RLock()
+	gcm, keyID := db.cipher, db.keyID
+	db.keyMu.RUnlock()
+
+	nonce := make([]byte, gcm.NonceSize())
 	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
 		return "", fmt.Errorf("failed to generate nonce: %w", err)
 	}
 
-	ciphertext := db.cipher.Seal(nonce, nonce, plaintext, nil)
-	return base64.StdEncoding.EncodeToString(ciphertext), nil
+	ciphertext := gcm.Seal(nonce, nonce, plaintext, nil)
+	return versionPrefix(keyID) + base64.StdEncoding.EncodeToString(ciphertext), nil
 }
 
-// Decrypt decrypts data using AES-GCM
+// Decrypt decrypts data using AES-GCM with the key it was encrypted with
 func (db *DB) Decrypt(ciphertext string) ([]byte, error) {
-	data, err := base64.StdEncoding.DecodeString(ciphertext)
+	keyID, encoded, err := splitVersion(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+
+	db.keyMu.RLock()
+	gcm, ok := db.ciphers[keyID]
+	db.keyMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown encryption key %d", keyID)
+	}
+
+	data, err := base64.StdEncoding.DecodeString(encoded)
 	if err != nil {
 		return nil, fmt.Errorf("failed to decode base64: %w", err)
 	}
 
-	nonceSize := db.cipher.NonceSize()
+	nonceSize := gcm.NonceSize()
 	if len(data) < nonceSize {
 		return nil, fmt.Errorf("ciphertext too short")
 	}
 
 	nonce, ciphertext_bytes := data[:nonceSize], data[nonceSize:]
-	plaintext, err := db.cipher.Open(nil, nonce, ciphertext_bytes, nil)
+	plaintext, err := gcm.Open(nil, nonce, ciphertext_bytes, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to decrypt: %w", err)
 	}
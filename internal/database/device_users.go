@@ -0,0 +1,111 @@
+package database
+
+import (
+	"fmt"
+)
+
+// ListDeviceUserAssignments returns the terminal user IDs reserved for members
+func (db *DB) ListDeviceUserAssignments() ([]DeviceUserAssignment, error) {
+	rows, err := db.conn.Query(`
+		SELECT device_user_id, internal_user_id, created_at
+		FROM device_user_assignments
+		ORDER BY device_user_id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list device user assignments: %w", err)
+	}
+	defer rows.Close()
+
+	var assignments []DeviceUserAssignment
+	for rows.Next() {
+		var assignment DeviceUserAssignment
+		if err := rows.Scan(&assignment.DeviceUserID, &assignment.InternalUserID, &assignment.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan device user assignment: %w", err)
+		}
+		assignments = append(assignments, assignment)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating device user assignments: %w", err)
+	}
+
+	return assignments, nil
+}
+
+// CreateDeviceUserAssignment reserves a terminal user ID for a member
+func (db *DB) CreateDeviceUserAssignment(deviceUserID int, internalUserID string) (*DeviceUserAssignment, error) {
+	if deviceUserID <= 0 {
+		return nil, fmt.Errorf("device user ID must be positive")
+	}
+	if internalUserID == "" {
+		return nil, fmt.Errorf("internal user ID cannot be empty")
+	}
+
+	var assignment DeviceUserAssignment
+	err := db.conn.QueryRow(`
+		INSERT INTO device_user_assignments (device_user_id, internal_user_id, created_at)
+		VALUES (?, ?, CURRENT_TIMESTAMP)
+		RETURNING device_user_id, internal_user_id, created_at
+	`, deviceUserID, internalUserID).Scan(&assignment.DeviceUserID, &assignment.InternalUserID, &assignment.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create device user assignment: %w", err)
+	}
+
+	return &assignment, nil
+}
+
+// ReleaseDeviceUserAssignment frees a terminal user ID once the member has been
+// removed from every terminal, together with its provisioned mapping
+func (db *DB) ReleaseDeviceUserAssignment(deviceUserID int, externalUserID string) error {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM device_user_assignments WHERE device_user_id = ?`, deviceUserID); err != nil {
+		return fmt.Errorf("failed to delete device user assignment: %w", err)
+	}
+	if _, err := tx.Exec(`
+		DELETE FROM external_user_mappings WHERE external_user_id = ? AND source = ?
+	`, externalUserID, MappingSourceProvisioning); err != nil {
+		return fmt.Errorf("failed to delete provisioned mapping: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit device user release: %w", err)
+	}
+
+	return nil
+}
+
+// UpsertProvisionedMapping maps the external user ID reported by terminals for a
+// provisioned device user to its member, so that events resolve to the member
+func (db *DB) UpsertProvisionedMapping(externalUserID, internalUserID, userName string) error {
+	if externalUserID == "" {
+		return fmt.Errorf("external user ID cannot be empty")
+	}
+	if internalUserID == "" {
+		return fmt.Errorf("internal user ID cannot be empty")
+	}
+
+	_, err := db.conn.Exec(`
+		INSERT INTO external_user_mappings
+			(external_user_id, internal_user_id, user_name, notes, source, credential_type, created_at, updated_at)
+		VALUES (?, ?, ?, '', ?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+		ON CONFLICT(external_user_id) DO UPDATE SET
+			internal_user_id = excluded.internal_user_id,
+			user_name = excluded.user_name,
+			source = excluded.source,
+			credential_type = excluded.credential_type,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE internal_user_id != excluded.internal_user_id
+			OR user_name != excluded.user_name
+			OR source != excluded.source
+	`, externalUserID, internalUserID, userName, MappingSourceProvisioning, CredentialTypeDeviceUser)
+	if err != nil {
+		return fmt.Errorf("failed to upsert provisioned mapping: %w", err)
+	}
+
+	return nil
+}
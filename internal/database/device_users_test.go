@@ -0,0 +1,81 @@
+package database
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDeviceUserAssignments(t *testing.T) {
+	db := setupTestDB(t, TierNormal)
+
+	if _, err := db.CreateDeviceUserAssignment(42, "user_1"); err != nil {
+		t.Fatalf("failed to create assignment: %v", err)
+	}
+	if _, err := db.CreateDeviceUserAssignment(42, "user_2"); err == nil {
+		t.Error("expected error reusing a device user ID")
+	}
+	if _, err := db.CreateDeviceUserAssignment(43, "user_1"); err == nil {
+		t.Error("expected error assigning a second ID to a member")
+	}
+
+	if err := db.UpsertProvisionedMapping("device_42", "user_1", "Alex"); err != nil {
+		t.Fatalf("failed to upsert provisioned mapping: %v", err)
+	}
+	if err := db.UpsertProvisionedMapping("device_42", "user_1", "Alex B"); err != nil {
+		t.Fatalf("failed to update provisioned mapping: %v", err)
+	}
+	mapping, err := db.GetExternalUserMapping("device_42")
+	if err != nil || mapping == nil {
+		t.Fatalf("expected provisioned mapping, got %+v (%v)", mapping, err)
+	}
+	if mapping.InternalUserID != "user_1" || mapping.UserName != "Alex B" || mapping.Source != MappingSourceProvisioning {
+		t.Errorf("unexpected provisioned mapping: %+v", mapping)
+	}
+
+	assignments, err := db.ListDeviceUserAssignments()
+	if err != nil || len(assignments) != 1 || assignments[0].InternalUserID != "user_1" {
+		t.Fatalf("unexpected assignments: %+v (%v)", assignments, err)
+	}
+
+	if err := db.ReleaseDeviceUserAssignment(42, "device_42"); err != nil {
+		t.Fatalf("failed to release assignment: %v", err)
+	}
+	if assignments, _ := db.ListDeviceUserAssignments(); len(assignments) != 0 {
+		t.Errorf("expected no assignments, got %+v", assignments)
+	}
+	if mapping, _ := db.GetExternalUserMapping("device_42"); mapping != nil {
+		t.Errorf("expected provisioned mapping to be removed, got %+v", mapping)
+	}
+}
+
+func TestListRosterMembers(t *testing.T) {
+	db := setupTestDB(t, TierNormal)
+	now := time.Now()
+
+	if _, err := db.ApplyRosterMember(RosterMember{
+		InternalUserID: "user_1",
+		UserName:       "Alex",
+		Status:         "active",
+		Credentials: []RosterCredential{
+			{ExternalUserID: "100", Type: CredentialTypeCard},
+			{ExternalUserID: "42", Type: CredentialTypeDeviceUser},
+		},
+		UpdatedAt: now,
+	}, now); err != nil {
+		t.Fatalf("failed to apply roster member: %v", err)
+	}
+	if _, err := db.CreateExternalUserMapping("card_local", "user_2", "", ""); err != nil {
+		t.Fatalf("failed to create local mapping: %v", err)
+	}
+
+	members, err := db.ListRosterMembers()
+	if err != nil {
+		t.Fatalf("failed to list roster members: %v", err)
+	}
+	if len(members) != 1 {
+		t.Fatalf("expected only the platform member, got %+v", members)
+	}
+	if members[0].UserName != "Alex" || members[0].Status != "active" || len(members[0].Credentials) != 2 {
+		t.Errorf("unexpected roster member: %+v", members[0])
+	}
+}
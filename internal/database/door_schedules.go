@@ -0,0 +1,275 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// lockdownConfigKey is the device_config key holding the active lockdown
+const lockdownConfigKey = "door_lockdown"
+
+// UpsertDoorSchedule creates or updates a door schedule
+func (db *DB) UpsertDoorSchedule(schedule *DoorSchedule) error {
+	if schedule == nil {
+		return fmt.Errorf("schedule cannot be nil")
+	}
+	if schedule.ID == "" {
+		return fmt.Errorf("schedule ID cannot be empty")
+	}
+	if schedule.Windows == "" {
+		return fmt.Errorf("schedule windows cannot be empty")
+	}
+
+	doorIDs := schedule.DoorIDs
+	if doorIDs == nil {
+		doorIDs = []string{}
+	}
+	doorIDsJSON, err := json.Marshal(doorIDs)
+	if err != nil {
+		return fmt.Errorf("failed to marshal schedule door IDs: %w", err)
+	}
+
+	query := `
+		INSERT INTO door_schedules (id, name, door_ids, windows, enabled, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+		ON CONFLICT(id) DO UPDATE SET
+			name = excluded.name,
+			door_ids = excluded.door_ids,
+			windows = excluded.windows,
+			enabled = excluded.enabled,
+			updated_at = CURRENT_TIMESTAMP
+	`
+
+	if _, err := db.conn.Exec(query, schedule.ID, schedule.Name, string(doorIDsJSON), schedule.Windows, schedule.Enabled); err != nil {
+		return fmt.Errorf("failed to upsert door schedule %s: %w", schedule.ID, err)
+	}
+
+	return nil
+}
+
+// GetDoorSchedule retrieves a door schedule by ID
+// Returns nil if the schedule does not exist
+func (db *DB) GetDoorSchedule(id string) (*DoorSchedule, error) {
+	if id == "" {
+		return nil, fmt.Errorf("schedule ID cannot be empty")
+	}
+
+	query := `
+		SELECT id, name, door_ids, windows, enabled, created_at, updated_at
+		FROM door_schedules
+		WHERE id = ?
+	`
+
+	schedule, err := scanDoorSchedule(db.conn.QueryRow(query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // Schedule not found
+		}
+		return nil, fmt.Errorf("failed to get door schedule: %w", err)
+	}
+
+	return schedule, nil
+}
+
+// ListDoorSchedules retrieves all door schedules ordered by ID
+func (db *DB) ListDoorSchedules() ([]DoorSchedule, error) {
+	query := `
+		SELECT id, name, door_ids, windows, enabled, created_at, updated_at
+		FROM door_schedules
+		ORDER BY id
+	`
+
+	rows, err := db.conn.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list door schedules: %w", err)
+	}
+	defer rows.Close()
+
+	var schedules []DoorSchedule
+	for rows.Next() {
+		schedule, err := scanDoorSchedule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan door schedule: %w", err)
+		}
+		schedules = append(schedules, *schedule)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating door schedules: %w", err)
+	}
+
+	return schedules, nil
+}
+
+// DeleteDoorSchedule removes a door schedule
+// Returns false if the schedule did not exist
+func (db *DB) DeleteDoorSchedule(id string) (bool, error) {
+	if id == "" {
+		return false, fmt.Errorf("schedule ID cannot be empty")
+	}
+
+	result, err := db.conn.Exec(`DELETE FROM door_schedules WHERE id = ?`, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete door schedule: %w", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	return deleted > 0, nil
+}
+
+// scanDoorSchedule scans a single door_schedules row
+func scanDoorSchedule(row rowScanner) (*DoorSchedule, error) {
+	schedule := &DoorSchedule{}
+	var doorIDs string
+
+	if err := row.Scan(
+		&schedule.ID,
+		&schedule.Name,
+		&doorIDs,
+		&schedule.Windows,
+		&schedule.Enabled,
+		&schedule.CreatedAt,
+		&schedule.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(doorIDs), &schedule.DoorIDs); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal door IDs for schedule %s: %w", schedule.ID, err)
+	}
+
+	return schedule, nil
+}
+
+// UpsertDoorHoliday creates or updates a holiday. Holidays are unique by date.
+func (db *DB) UpsertDoorHoliday(holiday *DoorHoliday) error {
+	if holiday == nil {
+		return fmt.Errorf("holiday cannot be nil")
+	}
+	if holiday.ID == "" {
+		return fmt.Errorf("holiday ID cannot be empty")
+	}
+	if _, err := time.Parse("2006-01-02", holiday.Date); err != nil {
+		return fmt.Errorf("invalid holiday date %q: %w", holiday.Date, err)
+	}
+
+	query := `
+		INSERT INTO door_holidays (id, name, date, start_time, end_time, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+		ON CONFLICT(id) DO UPDATE SET
+			name = excluded.name,
+			date = excluded.date,
+			start_time = excluded.start_time,
+			end_time = excluded.end_time,
+			updated_at = CURRENT_TIMESTAMP
+	`
+
+	if _, err := db.conn.Exec(query, holiday.ID, holiday.Name, holiday.Date, holiday.StartTime, holiday.EndTime); err != nil {
+		return fmt.Errorf("failed to upsert door holiday %s: %w", holiday.ID, err)
+	}
+
+	return nil
+}
+
+// ListDoorHolidays retrieves all holidays ordered by date
+func (db *DB) ListDoorHolidays() ([]DoorHoliday, error) {
+	query := `
+		SELECT id, name, date, start_time, end_time, created_at, updated_at
+		FROM door_holidays
+		ORDER BY date
+	`
+
+	rows, err := db.conn.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list door holidays: %w", err)
+	}
+	defer rows.Close()
+
+	var holidays []DoorHoliday
+	for rows.Next() {
+		var holiday DoorHoliday
+		if err := rows.Scan(
+			&holiday.ID,
+			&holiday.Name,
+			&holiday.Date,
+			&holiday.StartTime,
+			&holiday.EndTime,
+			&holiday.CreatedAt,
+			&holiday.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan door holiday: %w", err)
+		}
+		holidays = append(holidays, holiday)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating door holidays: %w", err)
+	}
+
+	return holidays, nil
+}
+
+// DeleteDoorHoliday removes a holiday
+// Returns false if the holiday did not exist
+func (db *DB) DeleteDoorHoliday(id string) (bool, error) {
+	if id == "" {
+		return false, fmt.Errorf("holiday ID cannot be empty")
+	}
+
+	result, err := db.conn.Exec(`DELETE FROM door_holidays WHERE id = ?`, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete door holiday: %w", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	return deleted > 0, nil
+}
+
+// GetDoorLockdown retrieves the active lockdown
+// Returns nil if no lockdown is active
+func (db *DB) GetDoorLockdown() (*DoorLockdown, error) {
+	value, err := db.GetConfig(lockdownConfigKey)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil // No lockdown
+		}
+		return nil, err
+	}
+
+	var lockdown DoorLockdown
+	if err := json.Unmarshal([]byte(value), &lockdown); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal door lockdown: %w", err)
+	}
+
+	return &lockdown, nil
+}
+
+// SetDoorLockdown activates a lockdown, replacing any active one
+func (db *DB) SetDoorLockdown(lockdown *DoorLockdown) error {
+	if lockdown == nil {
+		return fmt.Errorf("lockdown cannot be nil")
+	}
+
+	value, err := json.Marshal(lockdown)
+	if err != nil {
+		return fmt.Errorf("failed to marshal door lockdown: %w", err)
+	}
+
+	return db.SetConfig(lockdownConfigKey, string(value))
+}
+
+// ClearDoorLockdown ends the active lockdown
+func (db *DB) ClearDoorLockdown() error {
+	return db.DeleteConfig(lockdownConfigKey)
+}
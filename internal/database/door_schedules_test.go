@@ -0,0 +1,121 @@
+package database
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDoorSchedules(t *testing.T) {
+	db := setupTestDB(t, TierNormal)
+
+	schedule := &DoorSchedule{
+		ID:      "staffed_hours",
+		Name:    "Staffed hours",
+		DoorIDs: []string{"front"},
+		Windows: `[{"days":[1,2,3,4,5],"start":"06:00","end":"22:00"}]`,
+		Enabled: true,
+	}
+	if err := db.UpsertDoorSchedule(schedule); err != nil {
+		t.Fatalf("failed to upsert schedule: %v", err)
+	}
+
+	stored, err := db.GetDoorSchedule("staffed_hours")
+	if err != nil {
+		t.Fatalf("failed to get schedule: %v", err)
+	}
+	if stored == nil || stored.Name != "Staffed hours" || !stored.Enabled || stored.Windows != schedule.Windows {
+		t.Fatalf("unexpected schedule: %+v", stored)
+	}
+	if len(stored.DoorIDs) != 1 || stored.DoorIDs[0] != "front" {
+		t.Errorf("unexpected door IDs: %v", stored.DoorIDs)
+	}
+
+	schedule.Enabled = false
+	schedule.DoorIDs = nil
+	if err := db.UpsertDoorSchedule(schedule); err != nil {
+		t.Fatalf("failed to update schedule: %v", err)
+	}
+	schedules, err := db.ListDoorSchedules()
+	if err != nil {
+		t.Fatalf("failed to list schedules: %v", err)
+	}
+	if len(schedules) != 1 || schedules[0].Enabled || len(schedules[0].DoorIDs) != 0 {
+		t.Errorf("unexpected schedules: %+v", schedules)
+	}
+
+	if err := db.UpsertDoorSchedule(&DoorSchedule{ID: "empty"}); err == nil {
+		t.Errorf("expected error for a schedule without windows")
+	}
+
+	deleted, err := db.DeleteDoorSchedule("staffed_hours")
+	if err != nil || !deleted {
+		t.Errorf("expected schedule to be deleted, got %v (%v)", deleted, err)
+	}
+	if deleted, _ := db.DeleteDoorSchedule("staffed_hours"); deleted {
+		t.Errorf("expected second delete to report a missing schedule")
+	}
+	if missing, _ := db.GetDoorSchedule("staffed_hours"); missing != nil {
+		t.Errorf("expected nil schedule after delete")
+	}
+}
+
+func TestDoorHolidays(t *testing.T) {
+	db := setupTestDB(t, TierNormal)
+
+	holidays := []DoorHoliday{
+		{ID: "christmas", Name: "Christmas Day", Date: "2026-12-25"},
+		{ID: "new_years_eve", Name: "New Year's Eve", Date: "2026-12-31", StartTime: "08:00", EndTime: "14:00"},
+	}
+	for i := range holidays {
+		if err := db.UpsertDoorHoliday(&holidays[i]); err != nil {
+			t.Fatalf("failed to upsert holiday: %v", err)
+		}
+	}
+
+	if err := db.UpsertDoorHoliday(&DoorHoliday{ID: "bad", Date: "25/12/2026"}); err == nil {
+		t.Errorf("expected error for an invalid date")
+	}
+	if err := db.UpsertDoorHoliday(&DoorHoliday{ID: "duplicate", Date: "2026-12-25"}); err == nil {
+		t.Errorf("expected error for a second holiday on the same date")
+	}
+
+	stored, err := db.ListDoorHolidays()
+	if err != nil {
+		t.Fatalf("failed to list holidays: %v", err)
+	}
+	if len(stored) != 2 || stored[0].ID != "christmas" || stored[1].StartTime != "08:00" {
+		t.Errorf("unexpected holidays: %+v", stored)
+	}
+
+	if deleted, err := db.DeleteDoorHoliday("christmas"); err != nil || !deleted {
+		t.Errorf("expected holiday to be deleted, got %v (%v)", deleted, err)
+	}
+}
+
+func TestDoorLockdown(t *testing.T) {
+	db := setupTestDB(t, TierNormal)
+
+	lockdown, err := db.GetDoorLockdown()
+	if err != nil || lockdown != nil {
+		t.Fatalf("expected no lockdown, got %+v (%v)", lockdown, err)
+	}
+
+	activatedAt := time.Date(2026, 3, 8, 9, 30, 0, 0, time.UTC)
+	if err := db.SetDoorLockdown(&DoorLockdown{Reason: "police incident", ActivatedAt: activatedAt}); err != nil {
+		t.Fatalf("failed to set lockdown: %v", err)
+	}
+	lockdown, err = db.GetDoorLockdown()
+	if err != nil || lockdown == nil {
+		t.Fatalf("expected an active lockdown, got %+v (%v)", lockdown, err)
+	}
+	if lockdown.Reason != "police incident" || !lockdown.ActivatedAt.Equal(activatedAt) {
+		t.Errorf("unexpected lockdown: %+v", lockdown)
+	}
+
+	if err := db.ClearDoorLockdown(); err != nil {
+		t.Fatalf("failed to clear lockdown: %v", err)
+	}
+	if lockdown, _ := db.GetDoorLockdown(); lockdown != nil {
+		t.Errorf("expected lockdown to be cleared")
+	}
+}
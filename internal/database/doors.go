@@ -0,0 +1,239 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+)
+
+// upsertDoorQuery inserts a door or updates it in place, keeping its creation time
+const upsertDoorQuery = `
+	INSERT INTO doors (id, name, zone, adapters, relay, unlock_duration_ms, created_at, updated_at)
+	VALUES (?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+	ON CONFLICT(id) DO UPDATE SET
+		name = excluded.name,
+		zone = excluded.zone,
+		adapters = excluded.adapters,
+		relay = excluded.relay,
+		unlock_duration_ms = excluded.unlock_duration_ms,
+		updated_at = CURRENT_TIMESTAMP
+`
+
+// UpsertDoor creates or updates a door
+func (db *DB) UpsertDoor(door *Door) error {
+	if door == nil {
+		return fmt.Errorf("door cannot be nil")
+	}
+	if door.ID == "" {
+		return fmt.Errorf("door ID cannot be empty")
+	}
+
+	adapters, relay, err := db.doorColumns(door)
+	if err != nil {
+		return err
+	}
+
+	if _, err := db.conn.Exec(upsertDoorQuery, door.ID, door.Name, door.Zone, adapters, relay, door.UnlockDurationMs); err != nil {
+		return fmt.Errorf("failed to upsert door %s: %w", door.ID, err)
+	}
+
+	return nil
+}
+
+// GetDoor retrieves a door by ID
+// Returns nil if the door does not exist
+func (db *DB) GetDoor(id string) (*Door, error) {
+	if id == "" {
+		return nil, fmt.Errorf("door ID cannot be empty")
+	}
+
+	query := `
+		SELECT id, name, zone, adapters, relay, unlock_duration_ms, created_at, updated_at
+		FROM doors
+		WHERE id = ?
+	`
+
+	door, err := db.scanDoor(db.conn.QueryRow(query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // Door not found
+		}
+		return nil, fmt.Errorf("failed to get door: %w", err)
+	}
+
+	return door, nil
+}
+
+// ListDoors retrieves all doors ordered by ID
+func (db *DB) ListDoors() ([]Door, error) {
+	query := `
+		SELECT id, name, zone, adapters, relay, unlock_duration_ms, created_at, updated_at
+		FROM doors
+		ORDER BY id
+	`
+
+	rows, err := db.conn.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list doors: %w", err)
+	}
+	defer rows.Close()
+
+	var doors []Door
+	for rows.Next() {
+		door, err := db.scanDoor(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan door: %w", err)
+		}
+		doors = append(doors, *door)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating doors: %w", err)
+	}
+
+	return doors, nil
+}
+
+// DeleteDoor removes a door
+func (db *DB) DeleteDoor(id string) error {
+	if id == "" {
+		return fmt.Errorf("door ID cannot be empty")
+	}
+
+	if _, err := db.conn.Exec(`DELETE FROM doors WHERE id = ?`, id); err != nil {
+		return fmt.Errorf("failed to delete door: %w", err)
+	}
+
+	return nil
+}
+
+// ReplaceDoors atomically replaces the stored doors with the configured set,
+// keeping the creation time of doors that already exist
+func (db *DB) ReplaceDoors(doors []Door) error {
+	seen := make(map[string]bool, len(doors))
+	for _, door := range doors {
+		if door.ID == "" {
+			return fmt.Errorf("door ID cannot be empty")
+		}
+		if seen[door.ID] {
+			return fmt.Errorf("duplicate door ID: %s", door.ID)
+		}
+		seen[door.ID] = true
+	}
+
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query(`SELECT id FROM doors`)
+	if err != nil {
+		return fmt.Errorf("failed to list doors: %w", err)
+	}
+	var stale []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan door ID: %w", err)
+		}
+		if !seen[id] {
+			stale = append(stale, id)
+		}
+	}
+	rows.Close()
+
+	for _, id := range stale {
+		if _, err := tx.Exec(`DELETE FROM doors WHERE id = ?`, id); err != nil {
+			return fmt.Errorf("failed to delete door %s: %w", id, err)
+		}
+	}
+
+	stmt, err := tx.Prepare(upsertDoorQuery)
+	if err != nil {
+		return fmt.Errorf("failed to prepare door upsert: %w", err)
+	}
+	defer stmt.Close()
+
+	for i := range doors {
+		door := &doors[i]
+		adapters, relay, err := db.doorColumns(door)
+		if err != nil {
+			return err
+		}
+		if _, err := stmt.Exec(door.ID, door.Name, door.Zone, adapters, relay, door.UnlockDurationMs); err != nil {
+			return fmt.Errorf("failed to upsert door %s: %w", door.ID, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit doors: %w", err)
+	}
+
+	return nil
+}
+
+// doorColumns serializes the adapter list and encrypts the relay settings of a door
+func (db *DB) doorColumns(door *Door) (string, sql.NullString, error) {
+	adapters := door.Adapters
+	if adapters == nil {
+		adapters = []string{}
+	}
+	adaptersJSON, err := json.Marshal(adapters)
+	if err != nil {
+		return "", sql.NullString{}, fmt.Errorf("failed to marshal door adapters: %w", err)
+	}
+
+	var relay sql.NullString
+	if len(door.Relay) > 0 {
+		relayJSON, err := json.Marshal(door.Relay)
+		if err != nil {
+			return "", sql.NullString{}, fmt.Errorf("failed to marshal door relay settings: %w", err)
+		}
+		// Relay settings may carry module URLs with credentials
+		encrypted, err := db.Encrypt(relayJSON)
+		if err != nil {
+			return "", sql.NullString{}, fmt.Errorf("failed to encrypt door relay settings: %w", err)
+		}
+		relay = sql.NullString{String: encrypted, Valid: true}
+	}
+
+	return string(adaptersJSON), relay, nil
+}
+
+// scanDoor scans a single doors row
+func (db *DB) scanDoor(row rowScanner) (*Door, error) {
+	door := &Door{}
+	var adapters string
+	var relay sql.NullString
+
+	if err := row.Scan(
+		&door.ID,
+		&door.Name,
+		&door.Zone,
+		&adapters,
+		&relay,
+		&door.UnlockDurationMs,
+		&door.CreatedAt,
+		&door.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(adapters), &door.Adapters); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal adapters for door %s: %w", door.ID, err)
+	}
+
+	if relay.Valid {
+		decrypted, err := db.Decrypt(relay.String)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt relay settings for door %s: %w", door.ID, err)
+		}
+		if err := json.Unmarshal(decrypted, &door.Relay); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal relay settings for door %s: %w", door.ID, err)
+		}
+	}
+
+	return door, nil
+}
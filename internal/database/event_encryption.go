@@ -0,0 +1,226 @@
+package database
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+)
+
+// eventIndexCheckKey is the device_config key recording which index key the
+// event_queue blind index was built with
+const eventIndexCheckKey = "event_index_check"
+
+// deriveIndexKey derives the blind index key from an encryption key, for
+// databases opened without a key ring
+func deriveIndexKey(encryptionKey []byte) []byte {
+	mac := hmac.New(sha256.New, encryptionKey)
+	mac.Write([]byte("gym-door-bridge blind index"))
+	return mac.Sum(nil)
+}
+
+// BlindIndex returns a keyed hash of value, so encrypted columns can be
+// searched for an exact value without decrypting them
+func (db *DB) BlindIndex(value string) string {
+	db.keyMu.RLock()
+	key := db.indexKey
+	db.keyMu.RUnlock()
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// indexCheck identifies the current index key without revealing it
+func (db *DB) indexCheck() string {
+	return db.BlindIndex("\x00index-check")
+}
+
+// DecryptEvent decrypts the user IDs and payload of an event read from
+// event_queue. Events stored before user IDs were encrypted have no hash and
+// may still hold their user ID and payload in the clear.
+func (db *DB) DecryptEvent(event *EventQueue) error {
+	if event.ExternalUserHash != "" && event.ExternalUserID != "" {
+		decrypted, err := db.Decrypt(event.ExternalUserID)
+		if err != nil {
+			return fmt.Errorf("failed to decrypt user ID for event %s: %w", event.EventID, err)
+		}
+		event.ExternalUserID = string(decrypted)
+	}
+
+	if event.InternalUserID != "" {
+		decrypted, err := db.Decrypt(event.InternalUserID)
+		if err != nil {
+			return fmt.Errorf("failed to decrypt internal user ID for event %s: %w", event.EventID, err)
+		}
+		event.InternalUserID = string(decrypted)
+	}
+
+	if event.RawData != "" && !isPlainPayload(event.ExternalUserHash, event.RawData) {
+		decrypted, err := db.Decrypt(event.RawData)
+		if err != nil {
+			return fmt.Errorf("failed to decrypt raw data for event %s: %w", event.EventID, err)
+		}
+		event.RawData = string(decrypted)
+	}
+
+	return nil
+}
+
+// isPlainPayload reports whether the raw data of an event is stored in the
+// clear. Only events without a user hash can be, and ciphertext is never JSON.
+func isPlainPayload(userHash, rawData string) bool {
+	return userHash == "" && json.Valid([]byte(rawData))
+}
+
+// EncryptEventQueue encrypts the user IDs and payloads of events stored in
+// the clear and rebuilds the user hashes when the index key has changed. Rows
+// are updated in batches of one transaction each, so an interrupted run is
+// picked up by the next one. It returns the number of events updated.
+func (db *DB) EncryptEventQueue(ctx context.Context) (int, error) {
+	check := db.indexCheck()
+
+	var stored string
+	err := db.conn.QueryRow("SELECT value FROM device_config WHERE key = ?", eventIndexCheckKey).Scan(&stored)
+	if err != nil && err != sql.ErrNoRows {
+		return 0, fmt.Errorf("failed to read event index check: %w", err)
+	}
+
+	// Only events not yet encrypted need work unless every hash is stale
+	condition := "external_user_hash = ''"
+	if stored != check {
+		condition = "1 = 1"
+	}
+
+	updated := 0
+	var lastID int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return updated, err
+		}
+
+		count, done, err := db.encryptEventBatch(condition, &lastID)
+		if err != nil {
+			return updated, err
+		}
+		updated += count
+		if done {
+			break
+		}
+	}
+
+	if stored != check {
+		query := `
+			INSERT OR REPLACE INTO device_config (key, value, updated_at)
+			VALUES (?, ?, CURRENT_TIMESTAMP)
+		`
+		if _, err := db.conn.Exec(query, eventIndexCheckKey, check); err != nil {
+			return updated, fmt.Errorf("failed to store event index check: %w", err)
+		}
+	}
+
+	return updated, nil
+}
+
+// encryptEventBatch encrypts and re-hashes the next batch of events after
+// lastID. It reports true when no events remain.
+func (db *DB) encryptEventBatch(condition string, lastID *int64) (int, bool, error) {
+	query := fmt.Sprintf(`
+		SELECT id, event_id, external_user_id, external_user_hash, raw_data
+		FROM event_queue
+		WHERE id > ? AND %s
+		ORDER BY id
+		LIMIT ?
+	`, condition)
+
+	rows, err := db.conn.Query(query, *lastID, reencryptBatchSize)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to query events to encrypt: %w", err)
+	}
+
+	type storedEvent struct {
+		id       int64
+		eventID  string
+		userID   string
+		userHash string
+		rawData  sql.NullString
+	}
+	var batch []storedEvent
+	for rows.Next() {
+		var event storedEvent
+		if err := rows.Scan(&event.id, &event.eventID, &event.userID, &event.userHash, &event.rawData); err != nil {
+			rows.Close()
+			return 0, false, fmt.Errorf("failed to scan event row: %w", err)
+		}
+		batch = append(batch, event)
+	}
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		return 0, false, fmt.Errorf("error iterating event rows: %w", err)
+	}
+	rows.Close()
+
+	if len(batch) == 0 {
+		return 0, true, nil
+	}
+	*lastID = batch[len(batch)-1].id
+
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	update := `
+		UPDATE event_queue
+		SET external_user_id = ?, external_user_hash = ?, raw_data = ?
+		WHERE id = ? AND external_user_id = ? AND external_user_hash = ?
+	`
+	updated := 0
+	for _, event := range batch {
+		userID, storedUserID := event.userID, event.userID
+		if event.userHash != "" && event.userID != "" {
+			decrypted, err := db.Decrypt(event.userID)
+			if err != nil {
+				// Left for key rotation to report; the hash can't be rebuilt
+				continue
+			}
+			userID = string(decrypted)
+		} else if event.userID != "" {
+			if storedUserID, err = db.Encrypt([]byte(event.userID)); err != nil {
+				return 0, false, fmt.Errorf("failed to encrypt user ID for event %s: %w", event.eventID, err)
+			}
+		}
+
+		rawData := event.rawData
+		if rawData.Valid && isPlainPayload(event.userHash, rawData.String) {
+			encrypted, err := db.Encrypt([]byte(rawData.String))
+			if err != nil {
+				return 0, false, fmt.Errorf("failed to encrypt raw data for event %s: %w", event.eventID, err)
+			}
+			rawData.String = encrypted
+		}
+
+		userHash := db.BlindIndex(userID)
+		if userHash == event.userHash {
+			continue
+		}
+
+		res, err := tx.Exec(update, storedUserID, userHash, rawData, event.id, event.userID, event.userHash)
+		if err != nil {
+			return 0, false, fmt.Errorf("failed to encrypt event %s: %w", event.eventID, err)
+		}
+		if n, _ := res.RowsAffected(); n > 0 {
+			updated++
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, false, fmt.Errorf("failed to commit event encryption batch: %w", err)
+	}
+
+	return updated, len(batch) < reencryptBatchSize, nil
+}
@@ -0,0 +1,178 @@
+package database
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestInsertEventEncryptsUserID(t *testing.T) {
+	db := setupTestDB(t, TierNormal)
+	now := time.Now()
+
+	if err := db.InsertEvent(&EventQueue{
+		EventID:        "evt-1",
+		ExternalUserID: "member-42",
+		Timestamp:      now,
+		EventType:      EventTypeEntry,
+		RawData:        `{"card":"1234"}`,
+	}); err != nil {
+		t.Fatalf("Failed to insert event: %v", err)
+	}
+
+	var storedUserID, storedHash string
+	if err := db.QueryRow("SELECT external_user_id, external_user_hash FROM event_queue").Scan(&storedUserID, &storedHash); err != nil {
+		t.Fatalf("Failed to read event: %v", err)
+	}
+	if storedUserID == "member-42" {
+		t.Error("User ID should not be stored in the clear")
+	}
+	if storedHash != db.BlindIndex("member-42") {
+		t.Errorf("Expected blind index of the user ID, got %q", storedHash)
+	}
+
+	events, err := db.GetUnsentEvents(10)
+	if err != nil || len(events) != 1 {
+		t.Fatalf("Unexpected events: %+v (%v)", events, err)
+	}
+	if events[0].ExternalUserID != "member-42" || events[0].RawData != `{"card":"1234"}` {
+		t.Errorf("Unexpected decrypted event: %+v", events[0])
+	}
+
+	similar, err := db.HasSimilarEvent("member-42", EventTypeEntry, now.Add(-time.Minute), now.Add(time.Minute))
+	if err != nil || !similar {
+		t.Errorf("Expected a similar event for member-42: %v (%v)", similar, err)
+	}
+	similar, err = db.HasSimilarEvent("member-43", EventTypeEntry, now.Add(-time.Minute), now.Add(time.Minute))
+	if err != nil || similar {
+		t.Errorf("Expected no similar event for member-43: %v (%v)", similar, err)
+	}
+}
+
+func TestInsertEventEncryptsInternalUserID(t *testing.T) {
+	db := setupTestDB(t, TierNormal)
+
+	if err := db.InsertEvent(&EventQueue{
+		EventID:        "evt-1",
+		ExternalUserID: "member-42",
+		InternalUserID: "user-7",
+		Timestamp:      time.Now(),
+		EventType:      EventTypeEntry,
+	}); err != nil {
+		t.Fatalf("Failed to insert event: %v", err)
+	}
+
+	var stored string
+	if err := db.QueryRow("SELECT internal_user_id FROM event_queue").Scan(&stored); err != nil {
+		t.Fatalf("Failed to read event: %v", err)
+	}
+	if stored == "" || stored == "user-7" {
+		t.Errorf("Internal user ID should be stored encrypted, got %q", stored)
+	}
+
+	events, err := db.GetUnsentEvents(10)
+	if err != nil || len(events) != 1 {
+		t.Fatalf("Unexpected events: %+v (%v)", events, err)
+	}
+	if events[0].InternalUserID != "user-7" {
+		t.Errorf("Expected decrypted internal user ID, got %q", events[0].InternalUserID)
+	}
+}
+
+func TestEncryptEventQueue(t *testing.T) {
+	db := setupTestDB(t, TierNormal)
+	now := time.Now()
+
+	// Events stored by an older bridge, with the user ID and payload in the clear
+	for _, eventID := range []string{"old-1", "old-2"} {
+		if _, err := db.Exec(`INSERT INTO event_queue (event_id, external_user_id, timestamp, event_type, raw_data) VALUES (?, 'member-42', ?, 'entry', '{"card":"1234"}')`,
+			eventID, now); err != nil {
+			t.Fatalf("Failed to insert legacy event: %v", err)
+		}
+	}
+	// Alarms carry no user
+	if _, err := db.Exec(`INSERT INTO event_queue (event_id, external_user_id, timestamp, event_type) VALUES ('old-3', '', ?, 'door_forced_open')`, now); err != nil {
+		t.Fatalf("Failed to insert legacy event: %v", err)
+	}
+
+	// Unmigrated events are still readable
+	events, err := db.GetUnsentEvents(10)
+	if err != nil || len(events) != 3 || events[0].ExternalUserID != "member-42" {
+		t.Fatalf("Unexpected events before migration: %+v (%v)", events, err)
+	}
+
+	updated, err := db.EncryptEventQueue(context.Background())
+	if err != nil {
+		t.Fatalf("EncryptEventQueue() error = %v", err)
+	}
+	if updated != 3 {
+		t.Errorf("Expected 3 events updated, got %d", updated)
+	}
+
+	var plain int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM event_queue WHERE external_user_id = 'member-42' OR raw_data LIKE '{%'`).Scan(&plain); err != nil || plain != 0 {
+		t.Errorf("Expected no values left in the clear, got %d (%v)", plain, err)
+	}
+
+	events, err = db.GetUnsentEvents(10)
+	if err != nil || len(events) != 3 {
+		t.Fatalf("Unexpected events after migration: %+v (%v)", events, err)
+	}
+	for _, event := range events {
+		if event.EventID != "old-3" && (event.ExternalUserID != "member-42" || event.RawData != `{"card":"1234"}`) {
+			t.Errorf("Unexpected migrated event: %+v", event)
+		}
+	}
+
+	similar, err := db.HasSimilarEvent("member-42", EventTypeEntry, now.Add(-time.Minute), now.Add(time.Minute))
+	if err != nil || !similar {
+		t.Errorf("Expected migrated events to be found by user: %v (%v)", similar, err)
+	}
+
+	// Nothing is left to do on the next run
+	updated, err = db.EncryptEventQueue(context.Background())
+	if err != nil || updated != 0 {
+		t.Errorf("Expected no events updated on the second run, got %d (%v)", updated, err)
+	}
+}
+
+func TestEncryptEventQueueRebuildsIndex(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "bridge.db")
+	now := time.Now()
+
+	// Hashes written under the shared legacy key
+	legacy, err := OpenWithLegacyKey(Config{DatabasePath: dbPath, PerformanceTier: TierNormal})
+	if err != nil {
+		t.Fatalf("OpenWithLegacyKey() error = %v", err)
+	}
+	if _, err := legacy.EncryptEventQueue(context.Background()); err != nil {
+		t.Fatalf("EncryptEventQueue() error = %v", err)
+	}
+	if err := legacy.InsertEvent(&EventQueue{EventID: "evt-1", ExternalUserID: "member-42", Timestamp: now, EventType: EventTypeEntry}); err != nil {
+		t.Fatalf("Failed to insert event: %v", err)
+	}
+	legacy.Close()
+
+	db, err := OpenWithKeyStore(Config{DatabasePath: dbPath, PerformanceTier: TierNormal}, newMemorySecretStore())
+	if err != nil {
+		t.Fatalf("OpenWithKeyStore() error = %v", err)
+	}
+	defer db.Close()
+
+	if _, err := db.CompleteKeyRotation(context.Background()); err != nil {
+		t.Fatalf("CompleteKeyRotation() error = %v", err)
+	}
+	updated, err := db.EncryptEventQueue(context.Background())
+	if err != nil {
+		t.Fatalf("EncryptEventQueue() error = %v", err)
+	}
+	if updated != 1 {
+		t.Errorf("Expected the hash to be rebuilt with the new index key, got %d updated", updated)
+	}
+
+	similar, err := db.HasSimilarEvent("member-42", EventTypeEntry, now.Add(-time.Minute), now.Add(time.Minute))
+	if err != nil || !similar {
+		t.Errorf("Expected the event to be found with the new index key: %v (%v)", similar, err)
+	}
+}
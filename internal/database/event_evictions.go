@@ -0,0 +1,231 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// eventPriorityClass maps an event_queue row to its priority class
+const eventPriorityClass = `CASE
+		WHEN is_simulated THEN 'simulated'
+		WHEN event_type IN ('door_forced_open', 'door_held_open') THEN 'security'
+		WHEN event_type = 'denied' THEN 'denied'
+		ELSE 'access'
+	END`
+
+// eventPriorityRank orders event_queue rows from the first to the last evicted
+const eventPriorityRank = `CASE
+		WHEN is_simulated THEN 0
+		WHEN event_type IN ('door_forced_open', 'door_held_open') THEN 3
+		WHEN event_type = 'denied' THEN 2
+		ELSE 1
+	END`
+
+// evictionDeleteChunk bounds the number of IDs in one DELETE statement
+const evictionDeleteChunk = 500
+
+// EvictEventsByPriority removes the specified number of unsent events,
+// evicting the lowest priority class first and the oldest events within a
+// class. Security alarms go last, after denied, entry/exit and simulated events.
+func (db *DB) EvictEventsByPriority(count int) error {
+	if count <= 0 {
+		return nil
+	}
+
+	// The eviction summary is logged and reported with the next heartbeat
+	_, err := db.evictEvents(count, eventPriorityRank+", timestamp ASC")
+	return err
+}
+
+// evictEvents deletes up to count unsent events in the given order and adds
+// them to the eviction summary, in one transaction
+func (db *DB) evictEvents(count int, orderBy string) (int, error) {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := fmt.Sprintf(`
+		SELECT id, %s, timestamp
+		FROM event_queue
+		WHERE sent_at IS NULL
+		ORDER BY %s
+		LIMIT ?
+	`, eventPriorityClass, orderBy)
+
+	rows, err := tx.Query(query, count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to select events to evict: %w", err)
+	}
+
+	var ids []interface{}
+	evictions := make(map[string]*EventEviction)
+	for rows.Next() {
+		var id int64
+		var class string
+		var timestamp time.Time
+		if err := rows.Scan(&id, &class, &timestamp); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan event to evict: %w", err)
+		}
+		ids = append(ids, id)
+
+		eviction, ok := evictions[class]
+		if !ok {
+			eviction = &EventEviction{PriorityClass: class, OldestEventAt: timestamp, NewestEventAt: timestamp}
+			evictions[class] = eviction
+		}
+		eviction.EventCount++
+		if timestamp.Before(eviction.OldestEventAt) {
+			eviction.OldestEventAt = timestamp
+		}
+		if timestamp.After(eviction.NewestEventAt) {
+			eviction.NewestEventAt = timestamp
+		}
+	}
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		return 0, fmt.Errorf("error iterating events to evict: %w", err)
+	}
+	rows.Close()
+
+	for start := 0; start < len(ids); start += evictionDeleteChunk {
+		end := start + evictionDeleteChunk
+		if end > len(ids) {
+			end = len(ids)
+		}
+
+		query := fmt.Sprintf(`DELETE FROM event_queue WHERE id IN (%s)`, generatePlaceholders(end-start))
+		if _, err := tx.Exec(query, ids[start:end]...); err != nil {
+			return 0, fmt.Errorf("failed to evict events: %w", err)
+		}
+	}
+
+	classes := make([]string, 0, len(evictions))
+	for class := range evictions {
+		classes = append(classes, class)
+	}
+	sort.Strings(classes)
+	for _, class := range classes {
+		if err := recordEviction(tx, evictions[class]); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit eviction: %w", err)
+	}
+
+	return len(ids), nil
+}
+
+// recordEviction adds evicted events to the summary of their priority class
+func recordEviction(tx *sql.Tx, eviction *EventEviction) error {
+	var existing EventEviction
+	err := tx.QueryRow(`
+		SELECT id, event_count, oldest_event_at, newest_event_at
+		FROM event_evictions
+		WHERE priority_class = ?
+	`, eviction.PriorityClass).Scan(&existing.ID, &existing.EventCount, &existing.OldestEventAt, &existing.NewestEventAt)
+
+	if err == sql.ErrNoRows {
+		_, err := tx.Exec(`
+			INSERT INTO event_evictions (priority_class, event_count, oldest_event_at, newest_event_at, last_evicted_at)
+			VALUES (?, ?, ?, ?, CURRENT_TIMESTAMP)
+		`, eviction.PriorityClass, eviction.EventCount, eviction.OldestEventAt, eviction.NewestEventAt)
+		if err != nil {
+			return fmt.Errorf("failed to record eviction: %w", err)
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get eviction summary: %w", err)
+	}
+
+	if eviction.OldestEventAt.Before(existing.OldestEventAt) {
+		existing.OldestEventAt = eviction.OldestEventAt
+	}
+	if eviction.NewestEventAt.After(existing.NewestEventAt) {
+		existing.NewestEventAt = eviction.NewestEventAt
+	}
+
+	_, err = tx.Exec(`
+		UPDATE event_evictions
+		SET event_count = ?, oldest_event_at = ?, newest_event_at = ?, last_evicted_at = CURRENT_TIMESTAMP
+		WHERE id = ?
+	`, existing.EventCount+eviction.EventCount, existing.OldestEventAt, existing.NewestEventAt, existing.ID)
+	if err != nil {
+		return fmt.Errorf("failed to record eviction: %w", err)
+	}
+
+	return nil
+}
+
+// GetEventEvictions returns the evictions not yet acknowledged by the platform,
+// one summary per priority class
+func (db *DB) GetEventEvictions() ([]EventEviction, error) {
+	rows, err := db.conn.Query(`
+		SELECT id, priority_class, event_count, oldest_event_at, newest_event_at, last_evicted_at
+		FROM event_evictions
+		ORDER BY id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query event evictions: %w", err)
+	}
+	defer rows.Close()
+
+	var evictions []EventEviction
+	for rows.Next() {
+		var eviction EventEviction
+		if err := rows.Scan(
+			&eviction.ID,
+			&eviction.PriorityClass,
+			&eviction.EventCount,
+			&eviction.OldestEventAt,
+			&eviction.NewestEventAt,
+			&eviction.LastEvictedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan event eviction: %w", err)
+		}
+		evictions = append(evictions, eviction)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating event evictions: %w", err)
+	}
+
+	return evictions, nil
+}
+
+// AcknowledgeEventEvictions removes evictions the platform has been told
+// about. Events evicted after the summaries were read stay recorded, though
+// their time range may overlap the acknowledged one.
+func (db *DB) AcknowledgeEventEvictions(evictions []EventEviction) error {
+	if len(evictions) == 0 {
+		return nil
+	}
+
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, eviction := range evictions {
+		if _, err := tx.Exec(`UPDATE event_evictions SET event_count = event_count - ? WHERE id = ?`, eviction.EventCount, eviction.ID); err != nil {
+			return fmt.Errorf("failed to acknowledge eviction: %w", err)
+		}
+	}
+	if _, err := tx.Exec(`DELETE FROM event_evictions WHERE event_count <= 0`); err != nil {
+		return fmt.Errorf("failed to remove acknowledged evictions: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit eviction acknowledgement: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,130 @@
+package database
+
+import (
+	"testing"
+	"time"
+)
+
+func insertEvictionTestEvent(t *testing.T, db *DB, eventID, eventType string, simulated bool, timestamp time.Time) {
+	t.Helper()
+	if err := db.InsertEvent(&EventQueue{
+		EventID:        eventID,
+		ExternalUserID: "member-" + eventID,
+		Timestamp:      timestamp,
+		EventType:      eventType,
+		IsSimulated:    simulated,
+	}); err != nil {
+		t.Fatalf("Failed to insert event %s: %v", eventID, err)
+	}
+}
+
+func TestEvictEventsByPriority(t *testing.T) {
+	db := setupTestDB(t, TierNormal)
+	base := time.Now().Add(-time.Hour).Truncate(time.Second)
+
+	// The alarm is the oldest event but must outlive everything else
+	insertEvictionTestEvent(t, db, "alarm", EventTypeDoorForcedOpen, false, base)
+	insertEvictionTestEvent(t, db, "denied", EventTypeDenied, false, base.Add(1*time.Minute))
+	insertEvictionTestEvent(t, db, "entry-1", EventTypeEntry, false, base.Add(2*time.Minute))
+	insertEvictionTestEvent(t, db, "entry-2", EventTypeExit, false, base.Add(3*time.Minute))
+	insertEvictionTestEvent(t, db, "simulated", EventTypeEntry, true, base.Add(4*time.Minute))
+
+	if err := db.EvictEventsByPriority(3); err != nil {
+		t.Fatalf("EvictEventsByPriority() error = %v", err)
+	}
+
+	events, err := db.GetUnsentEvents(10)
+	if err != nil {
+		t.Fatalf("Failed to get unsent events: %v", err)
+	}
+	remaining := make(map[string]bool)
+	for _, event := range events {
+		remaining[event.EventID] = true
+	}
+	if len(events) != 2 || !remaining["alarm"] || !remaining["denied"] {
+		t.Errorf("Expected the alarm and denied events to remain, got %v", remaining)
+	}
+
+	evictions, err := db.GetEventEvictions()
+	if err != nil {
+		t.Fatalf("GetEventEvictions() error = %v", err)
+	}
+	byClass := make(map[string]EventEviction)
+	for _, eviction := range evictions {
+		byClass[eviction.PriorityClass] = eviction
+	}
+	if len(byClass) != 2 {
+		t.Fatalf("Expected evictions for 2 classes, got %+v", evictions)
+	}
+	if byClass[PriorityClassSimulated].EventCount != 1 {
+		t.Errorf("Expected 1 simulated event evicted, got %+v", byClass[PriorityClassSimulated])
+	}
+	access := byClass[PriorityClassAccess]
+	if access.EventCount != 2 {
+		t.Errorf("Expected 2 access events evicted, got %d", access.EventCount)
+	}
+	if !access.OldestEventAt.Equal(base.Add(2*time.Minute)) || !access.NewestEventAt.Equal(base.Add(3*time.Minute)) {
+		t.Errorf("Unexpected access eviction range: %v - %v", access.OldestEventAt, access.NewestEventAt)
+	}
+}
+
+func TestEvictOldestEventsRecordsEvictions(t *testing.T) {
+	db := setupTestDB(t, TierNormal)
+	base := time.Now().Add(-time.Hour).Truncate(time.Second)
+
+	insertEvictionTestEvent(t, db, "alarm", EventTypeDoorHeldOpen, false, base)
+	insertEvictionTestEvent(t, db, "entry", EventTypeEntry, false, base.Add(time.Minute))
+
+	if err := db.EvictOldestEventsDirect(1); err != nil {
+		t.Fatalf("EvictOldestEventsDirect() error = %v", err)
+	}
+
+	evictions, err := db.GetEventEvictions()
+	if err != nil {
+		t.Fatalf("GetEventEvictions() error = %v", err)
+	}
+	if len(evictions) != 1 || evictions[0].PriorityClass != PriorityClassSecurity || evictions[0].EventCount != 1 {
+		t.Errorf("Expected the FIFO eviction of the alarm to be recorded, got %+v", evictions)
+	}
+}
+
+func TestAcknowledgeEventEvictions(t *testing.T) {
+	db := setupTestDB(t, TierNormal)
+	base := time.Now().Add(-time.Hour).Truncate(time.Second)
+
+	for i, eventID := range []string{"entry-1", "entry-2", "entry-3"} {
+		insertEvictionTestEvent(t, db, eventID, EventTypeEntry, false, base.Add(time.Duration(i)*time.Minute))
+	}
+
+	if err := db.EvictEventsByPriority(1); err != nil {
+		t.Fatalf("EvictEventsByPriority() error = %v", err)
+	}
+	reported, err := db.GetEventEvictions()
+	if err != nil || len(reported) != 1 {
+		t.Fatalf("Unexpected evictions: %+v (%v)", reported, err)
+	}
+
+	// An eviction between reading and acknowledging the summary
+	if err := db.EvictEventsByPriority(1); err != nil {
+		t.Fatalf("EvictEventsByPriority() error = %v", err)
+	}
+
+	if err := db.AcknowledgeEventEvictions(reported); err != nil {
+		t.Fatalf("AcknowledgeEventEvictions() error = %v", err)
+	}
+	evictions, err := db.GetEventEvictions()
+	if err != nil {
+		t.Fatalf("GetEventEvictions() error = %v", err)
+	}
+	if len(evictions) != 1 || evictions[0].EventCount != 1 {
+		t.Fatalf("Expected the unreported eviction to remain, got %+v", evictions)
+	}
+
+	if err := db.AcknowledgeEventEvictions(evictions); err != nil {
+		t.Fatalf("AcknowledgeEventEvictions() error = %v", err)
+	}
+	evictions, err = db.GetEventEvictions()
+	if err != nil || len(evictions) != 0 {
+		t.Errorf("Expected no evictions left, got %+v (%v)", evictions, err)
+	}
+}
@@ -6,8 +6,28 @@ import (
 	"time"
 )
 
-// InsertEvent adds a new event to the queue
+// InsertEvent adds a new event to the queue. The user ID and raw data are
+// encrypted, and the user ID is indexed by a keyed hash for lookups.
 func (db *DB) InsertEvent(event *EventQueue) error {
+	storedUserID := event.ExternalUserID
+	if storedUserID != "" {
+		encrypted, err := db.Encrypt([]byte(event.ExternalUserID))
+		if err != nil {
+			return fmt.Errorf("failed to encrypt user ID: %w", err)
+		}
+		storedUserID = encrypted
+	}
+	userHash := db.BlindIndex(event.ExternalUserID)
+
+	var storedInternalUserID string
+	if event.InternalUserID != "" {
+		encrypted, err := db.Encrypt([]byte(event.InternalUserID))
+		if err != nil {
+			return fmt.Errorf("failed to encrypt internal user ID: %w", err)
+		}
+		storedInternalUserID = encrypted
+	}
+
 	// Encrypt raw data if present
 	var encryptedRawData sql.NullString
 	if event.RawData != "" {
@@ -19,17 +39,25 @@ func (db *DB) InsertEvent(event *EventQueue) error {
 	}
 
 	query := `
-		INSERT INTO event_queue (event_id, external_user_id, timestamp, event_type, is_simulated, device_id, raw_data)
-		VALUES (?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO event_queue (event_id, external_user_id, external_user_hash, timestamp, event_type, is_simulated, device_id, door_id,
+		                         internal_user_id, adapter_name, verify_mode, access_reason, schema_version, raw_data)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
 	result, err := db.conn.Exec(query, 
 		event.EventID, 
-		event.ExternalUserID, 
+		storedUserID, 
+		userHash, 
 		event.Timestamp, 
 		event.EventType, 
 		event.IsSimulated, 
 		event.DeviceID,
+		event.DoorID,
+		storedInternalUserID,
+		event.AdapterName,
+		event.VerifyMode,
+		event.AccessReason,
+		EventSchemaVersion,
 		encryptedRawData,
 	)
 	if err != nil {
@@ -42,14 +70,17 @@ func (db *DB) InsertEvent(event *EventQueue) error {
 	}
 
 	event.ID = id
+	event.ExternalUserHash = userHash
+	event.SchemaVersion = EventSchemaVersion
 	return nil
 }
 
 // GetUnsentEvents retrieves all events that haven't been sent to the cloud
 func (db *DB) GetUnsentEvents(limit int) ([]*EventQueue, error) {
 	query := `
-		SELECT id, event_id, external_user_id, timestamp, event_type, is_simulated, 
-		       device_id, raw_data, created_at, sent_at, retry_count
+		SELECT id, event_id, external_user_id, external_user_hash, timestamp, event_type, is_simulated, 
+		       device_id, door_id, internal_user_id, adapter_name, verify_mode, access_reason, schema_version,
+		       raw_data, created_at, sent_at, retry_count
 		FROM event_queue 
 		WHERE sent_at IS NULL 
 		ORDER BY timestamp ASC 
@@ -71,10 +102,17 @@ func (db *DB) GetUnsentEvents(limit int) ([]*EventQueue, error) {
 			&event.ID,
 			&event.EventID,
 			&event.ExternalUserID,
+			&event.ExternalUserHash,
 			&event.Timestamp,
 			&event.EventType,
 			&event.IsSimulated,
 			&event.DeviceID,
+			&event.DoorID,
+			&event.InternalUserID,
+			&event.AdapterName,
+			&event.VerifyMode,
+			&event.AccessReason,
+			&event.SchemaVersion,
 			&rawData,
 			&event.CreatedAt,
 			&event.SentAt,
@@ -84,13 +122,9 @@ func (db *DB) GetUnsentEvents(limit int) ([]*EventQueue, error) {
 			return nil, fmt.Errorf("failed to scan event row: %w", err)
 		}
 
-		// Decrypt raw data if present
-		if rawData.Valid {
-			decrypted, err := db.Decrypt(rawData.String)
-			if err != nil {
-				return nil, fmt.Errorf("failed to decrypt raw data for event %s: %w", event.EventID, err)
-			}
-			event.RawData = string(decrypted)
+		event.RawData = rawData.String
+		if err := db.DecryptEvent(event); err != nil {
+			return nil, err
 		}
 
 		events = append(events, event)
@@ -164,6 +198,20 @@ func (db *DB) GetQueueDepth() (int, error) {
 	return count, nil
 }
 
+// GetOldestPendingEventTime returns the timestamp of the oldest unsent event,
+// or nil when the queue is empty
+func (db *DB) GetOldestPendingEventTime() (*time.Time, error) {
+	var timestamp time.Time
+	err := db.conn.QueryRow("SELECT timestamp FROM event_queue WHERE sent_at IS NULL ORDER BY timestamp ASC LIMIT 1").Scan(&timestamp)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get oldest pending event: %w", err)
+	}
+	return &timestamp, nil
+}
+
 // CleanupOldEvents removes old sent events to prevent database growth
 func (db *DB) CleanupOldEvents(olderThan time.Duration) error {
 	cutoff := time.Now().Add(-olderThan)
@@ -229,19 +277,20 @@ func generatePlaceholders(count int) string {
 	return result
 }
 
-// HasSimilarEvent checks if a similar event exists within the specified time window
+// HasSimilarEvent checks if a similar event exists within the specified time
+// window, matching the user by its blind index
 func (db *DB) HasSimilarEvent(externalUserID, eventType string, windowStart, windowEnd time.Time) (bool, error) {
 	query := `
 		SELECT COUNT(*) 
 		FROM event_queue 
-		WHERE external_user_id = ? 
+		WHERE external_user_hash = ? 
 		  AND event_type = ? 
 		  AND timestamp BETWEEN ? AND ?
 		LIMIT 1
 	`
 
 	var count int
-	err := db.conn.QueryRow(query, externalUserID, eventType, windowStart, windowEnd).Scan(&count)
+	err := db.conn.QueryRow(query, db.BlindIndex(externalUserID), eventType, windowStart, windowEnd).Scan(&count)
 	if err != nil {
 		return false, fmt.Errorf("failed to check for similar events: %w", err)
 	}
@@ -250,30 +299,16 @@ func (db *DB) HasSimilarEvent(externalUserID, eventType string, windowStart, win
 }
 
 // EvictOldestEventsDirect removes the specified number of oldest unsent events
+// and records them in the eviction summary
 func (db *DB) EvictOldestEventsDirect(count int) error {
 	if count <= 0 {
 		return nil
 	}
 	
-	query := `
-		DELETE FROM event_queue 
-		WHERE id IN (
-			SELECT id FROM event_queue 
-			WHERE sent_at IS NULL 
-			ORDER BY timestamp ASC 
-			LIMIT ?
-		)
-	`
-
-	result, err := db.conn.Exec(query, count)
-	if err != nil {
+	// The eviction summary is logged and reported with the next heartbeat
+	if _, err := db.evictEvents(count, "timestamp ASC"); err != nil {
 		return fmt.Errorf("failed to evict oldest events: %w", err)
 	}
 
-	rowsAffected, _ := result.RowsAffected()
-	if rowsAffected > 0 {
-		fmt.Printf("Evicted %d oldest events\n", rowsAffected)
-	}
-
 	return nil
 }
\ No newline at end of file
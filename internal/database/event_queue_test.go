@@ -1,7 +1,10 @@
 package database
 
 import (
+	"crypto/rand"
+	"database/sql"
 	"fmt"
+	"path/filepath"
 	"testing"
 	"time"
 )
@@ -195,6 +198,45 @@ func TestGetQueueDepth(t *testing.T) {
 	}
 }
 
+func TestGetOldestPendingEventTime(t *testing.T) {
+	db := setupTestDB(t, TierNormal)
+
+	// Empty queue has no oldest event
+	oldest, err := db.GetOldestPendingEventTime()
+	if err != nil {
+		t.Fatalf("Failed to get oldest pending event: %v", err)
+	}
+	if oldest != nil {
+		t.Errorf("Expected no oldest pending event, got %v", oldest)
+	}
+
+	base := time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC)
+	for i := 0; i < 3; i++ {
+		event := &EventQueue{
+			EventID:        fmt.Sprintf("event-%d", i),
+			ExternalUserID: "user123",
+			Timestamp:      base.Add(time.Duration(i) * time.Minute),
+			EventType:      EventTypeEntry,
+		}
+		if err := db.InsertEvent(event); err != nil {
+			t.Fatalf("Failed to insert event: %v", err)
+		}
+	}
+
+	// Sent events no longer count
+	if err := db.MarkEventsSent([]string{"event-0"}); err != nil {
+		t.Fatalf("Failed to mark event as sent: %v", err)
+	}
+
+	oldest, err = db.GetOldestPendingEventTime()
+	if err != nil {
+		t.Fatalf("Failed to get oldest pending event: %v", err)
+	}
+	if oldest == nil || !oldest.Equal(base.Add(time.Minute)) {
+		t.Errorf("Expected oldest pending event at %v, got %v", base.Add(time.Minute), oldest)
+	}
+}
+
 func TestCleanupOldEvents(t *testing.T) {
 	db := setupTestDB(t, TierNormal)
 
@@ -285,4 +327,149 @@ func TestEvictOldestEvents(t *testing.T) {
 			t.Errorf("Expected event %s at position %d, got %s", expectedEvents[i], i, event.EventID)
 		}
 	}
-}
\ No newline at end of file
+}
+func TestMigrateEventTypeConstraint(t *testing.T) {
+	tempDir := t.TempDir()
+	path := filepath.Join(tempDir, "legacy.db")
+
+	// A database created before door alarm event types existed
+	legacy, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatalf("Failed to open legacy database: %v", err)
+	}
+	statements := []string{
+		`CREATE TABLE event_queue (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			event_id TEXT UNIQUE NOT NULL,
+			external_user_id TEXT NOT NULL,
+			timestamp DATETIME NOT NULL,
+			event_type TEXT NOT NULL CHECK (event_type IN ('entry', 'exit', 'denied')),
+			is_simulated BOOLEAN DEFAULT FALSE,
+			device_id TEXT NOT NULL DEFAULT '',
+			raw_data TEXT,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			sent_at DATETIME NULL,
+			retry_count INTEGER DEFAULT 0
+		)`,
+		`CREATE INDEX idx_event_queue_timestamp ON event_queue(timestamp)`,
+		`INSERT INTO event_queue (event_id, external_user_id, timestamp, event_type) VALUES ('legacy-1', 'user1', CURRENT_TIMESTAMP, 'entry')`,
+	}
+	for _, statement := range statements {
+		if _, err := legacy.Exec(statement); err != nil {
+			t.Fatalf("Failed to create legacy schema: %v", err)
+		}
+	}
+	legacy.Close()
+
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatalf("Failed to generate encryption key: %v", err)
+	}
+	db, err := NewDB(Config{DatabasePath: path, EncryptionKey: key, PerformanceTier: TierNormal})
+	if err != nil {
+		t.Fatalf("Failed to migrate legacy database: %v", err)
+	}
+	defer db.Close()
+
+	alarm := &EventQueue{
+		EventID:   "alarm-1",
+		Timestamp: time.Now(),
+		EventType: EventTypeDoorForcedOpen,
+		DoorID:    "main",
+	}
+	if err := db.InsertEvent(alarm); err != nil {
+		t.Fatalf("Failed to insert door alarm after migration: %v", err)
+	}
+
+	events, err := db.GetUnsentEvents(10)
+	if err != nil {
+		t.Fatalf("Failed to get events: %v", err)
+	}
+	if len(events) != 2 || events[0].EventID != "legacy-1" || events[1].EventType != EventTypeDoorForcedOpen {
+		t.Errorf("Expected the legacy event and the alarm, got %+v", events)
+	}
+
+	// Migrating again is a no-op
+	if err := db.migrate(); err != nil {
+		t.Errorf("Expected repeated migration to succeed, got %v", err)
+	}
+}
+
+func TestMigrateEventQueueSchemaV2(t *testing.T) {
+	tempDir := t.TempDir()
+	path := filepath.Join(tempDir, "v1.db")
+
+	// A database queued before the internal user ID, adapter, verify mode and access reason were kept
+	legacy, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatalf("Failed to open legacy database: %v", err)
+	}
+	statements := []string{
+		`CREATE TABLE event_queue (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			event_id TEXT UNIQUE NOT NULL,
+			external_user_id TEXT NOT NULL,
+			external_user_hash TEXT NOT NULL DEFAULT '',
+			timestamp DATETIME NOT NULL,
+			event_type TEXT NOT NULL CHECK (event_type IN ('entry', 'exit', 'denied', 'door_forced_open', 'door_held_open')),
+			is_simulated BOOLEAN DEFAULT FALSE,
+			device_id TEXT NOT NULL DEFAULT '',
+			door_id TEXT NOT NULL DEFAULT '',
+			raw_data TEXT,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			sent_at DATETIME NULL,
+			retry_count INTEGER DEFAULT 0
+		)`,
+		`INSERT INTO event_queue (event_id, external_user_id, timestamp, event_type) VALUES ('v1-1', '', CURRENT_TIMESTAMP, 'door_held_open')`,
+	}
+	for _, statement := range statements {
+		if _, err := legacy.Exec(statement); err != nil {
+			t.Fatalf("Failed to create legacy schema: %v", err)
+		}
+	}
+	legacy.Close()
+
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatalf("Failed to generate encryption key: %v", err)
+	}
+	db, err := NewDB(Config{DatabasePath: path, EncryptionKey: key, PerformanceTier: TierNormal})
+	if err != nil {
+		t.Fatalf("Failed to migrate legacy database: %v", err)
+	}
+	defer db.Close()
+
+	if err := db.InsertEvent(&EventQueue{
+		EventID:        "v2-1",
+		ExternalUserID: "member-42",
+		InternalUserID: "user-7",
+		Timestamp:      time.Now().Add(time.Second),
+		EventType:      EventTypeEntry,
+		AdapterName:    "zkteco",
+		VerifyMode:     "fingerprint",
+		AccessReason:   "allowed",
+	}); err != nil {
+		t.Fatalf("Failed to insert event after migration: %v", err)
+	}
+
+	events, err := db.GetUnsentEvents(10)
+	if err != nil {
+		t.Fatalf("Failed to get events: %v", err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("Expected 2 events, got %+v", events)
+	}
+	if events[0].EventID != "v1-1" || events[0].SchemaVersion != 1 || events[0].InternalUserID != "" {
+		t.Errorf("Expected the queued event to keep schema version 1, got %+v", events[0])
+	}
+	v2 := events[1]
+	if v2.SchemaVersion != EventSchemaVersion || v2.InternalUserID != "user-7" || v2.AdapterName != "zkteco" ||
+		v2.VerifyMode != "fingerprint" || v2.AccessReason != "allowed" {
+		t.Errorf("Unexpected event after migration: %+v", v2)
+	}
+
+	// Migrating again is a no-op
+	if err := db.migrate(); err != nil {
+		t.Errorf("Expected repeated migration to succeed, got %v", err)
+	}
+}
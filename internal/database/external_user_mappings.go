@@ -17,7 +17,7 @@ func (db *DB) CreateExternalUserMapping(externalUserID, internalUserID, userName
 	query := `
 		INSERT INTO external_user_mappings (external_user_id, internal_user_id, user_name, notes, created_at, updated_at)
 		VALUES (?, ?, ?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
-		RETURNING id, external_user_id, internal_user_id, user_name, notes, created_at, updated_at
+		RETURNING id, external_user_id, internal_user_id, user_name, notes, source, credential_type, member_status, created_at, updated_at
 	`
 
 	var mapping ExternalUserMapping
@@ -27,6 +27,9 @@ func (db *DB) CreateExternalUserMapping(externalUserID, internalUserID, userName
 		&mapping.InternalUserID,
 		&mapping.UserName,
 		&mapping.Notes,
+		&mapping.Source,
+		&mapping.CredentialType,
+		&mapping.MemberStatus,
 		&mapping.CreatedAt,
 		&mapping.UpdatedAt,
 	)
@@ -45,7 +48,7 @@ func (db *DB) GetExternalUserMapping(externalUserID string) (*ExternalUserMappin
 	}
 
 	query := `
-		SELECT id, external_user_id, internal_user_id, user_name, notes, created_at, updated_at
+		SELECT id, external_user_id, internal_user_id, user_name, notes, source, credential_type, member_status, created_at, updated_at
 		FROM external_user_mappings
 		WHERE external_user_id = ?
 	`
@@ -57,6 +60,9 @@ func (db *DB) GetExternalUserMapping(externalUserID string) (*ExternalUserMappin
 		&mapping.InternalUserID,
 		&mapping.UserName,
 		&mapping.Notes,
+		&mapping.Source,
+		&mapping.CredentialType,
+		&mapping.MemberStatus,
 		&mapping.CreatedAt,
 		&mapping.UpdatedAt,
 	)
@@ -78,7 +84,7 @@ func (db *DB) GetExternalUserMappingByInternalID(internalUserID string) (*Extern
 	}
 
 	query := `
-		SELECT id, external_user_id, internal_user_id, user_name, notes, created_at, updated_at
+		SELECT id, external_user_id, internal_user_id, user_name, notes, source, credential_type, member_status, created_at, updated_at
 		FROM external_user_mappings
 		WHERE internal_user_id = ?
 	`
@@ -90,6 +96,9 @@ func (db *DB) GetExternalUserMappingByInternalID(internalUserID string) (*Extern
 		&mapping.InternalUserID,
 		&mapping.UserName,
 		&mapping.Notes,
+		&mapping.Source,
+		&mapping.CredentialType,
+		&mapping.MemberStatus,
 		&mapping.CreatedAt,
 		&mapping.UpdatedAt,
 	)
@@ -104,7 +113,9 @@ func (db *DB) GetExternalUserMappingByInternalID(internalUserID string) (*Extern
 	return &mapping, nil
 }
 
-// UpdateExternalUserMapping updates an existing external user mapping
+// UpdateExternalUserMapping updates an existing external user mapping. An edited
+// mapping becomes a local override that roster syncs only replace with newer
+// platform data.
 func (db *DB) UpdateExternalUserMapping(externalUserID, internalUserID, userName, notes string) (*ExternalUserMapping, error) {
 	if externalUserID == "" {
 		return nil, fmt.Errorf("external user ID cannot be empty")
@@ -115,9 +126,9 @@ func (db *DB) UpdateExternalUserMapping(externalUserID, internalUserID, userName
 
 	query := `
 		UPDATE external_user_mappings
-		SET internal_user_id = ?, user_name = ?, notes = ?, updated_at = CURRENT_TIMESTAMP
+		SET internal_user_id = ?, user_name = ?, notes = ?, source = 'local', updated_at = CURRENT_TIMESTAMP
 		WHERE external_user_id = ?
-		RETURNING id, external_user_id, internal_user_id, user_name, notes, created_at, updated_at
+		RETURNING id, external_user_id, internal_user_id, user_name, notes, source, credential_type, member_status, created_at, updated_at
 	`
 
 	var mapping ExternalUserMapping
@@ -127,6 +138,9 @@ func (db *DB) UpdateExternalUserMapping(externalUserID, internalUserID, userName
 		&mapping.InternalUserID,
 		&mapping.UserName,
 		&mapping.Notes,
+		&mapping.Source,
+		&mapping.CredentialType,
+		&mapping.MemberStatus,
 		&mapping.CreatedAt,
 		&mapping.UpdatedAt,
 	)
@@ -175,7 +189,7 @@ func (db *DB) ListExternalUserMappings(limit, offset int) ([]ExternalUserMapping
 	}
 
 	query := `
-		SELECT id, external_user_id, internal_user_id, user_name, notes, created_at, updated_at
+		SELECT id, external_user_id, internal_user_id, user_name, notes, source, credential_type, member_status, created_at, updated_at
 		FROM external_user_mappings
 		ORDER BY created_at DESC
 		LIMIT ? OFFSET ?
@@ -196,6 +210,9 @@ func (db *DB) ListExternalUserMappings(limit, offset int) ([]ExternalUserMapping
 			&mapping.InternalUserID,
 			&mapping.UserName,
 			&mapping.Notes,
+			&mapping.Source,
+			&mapping.CredentialType,
+			&mapping.MemberStatus,
 			&mapping.CreatedAt,
 			&mapping.UpdatedAt,
 		)
@@ -0,0 +1,529 @@
+package database
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// legacyEncryptionKey is the key every installation shared before keys were
+// generated per device. Values written with it carry no key ID and are
+// re-encrypted with the device's key on startup.
+const legacyEncryptionKey = "bridge-default-encryption-key-32"
+
+// keyRingSecretName names the key ring in the secret store
+const keyRingSecretName = "database-keys"
+
+// reencryptBatchSize is the number of values re-encrypted per transaction
+const reencryptBatchSize = 100
+
+// SecretStore persists named secrets, normally in the platform credential store
+type SecretStore interface {
+	StoreSecret(name string, secret []byte) error
+	GetSecret(name string) ([]byte, error) // Returns nil, nil when the secret doesn't exist
+}
+
+// KeyRing holds the device's data-encryption keys. New values are encrypted
+// with the active key; older keys are kept until no value uses them. The
+// index key for blind indexes is not rotated, so lookups keep working while
+// values are re-encrypted.
+type KeyRing struct {
+	ActiveID int            `json:"activeId"`
+	Keys     map[int][]byte `json:"keys"`
+	IndexKey []byte         `json:"indexKey,omitempty"`
+}
+
+// ReencryptionResult describes the outcome of re-encrypting the database
+type ReencryptionResult struct {
+	Reencrypted int `json:"reencrypted"`
+	Failed      int `json:"failed"`  // Values that could not be decrypted with any known key
+	Pending     int `json:"pending"` // Values still using an older key
+	RetiredKeys int `json:"retiredKeys"`
+}
+
+// encryptedColumn is a column holding values encrypted by DB.Encrypt
+type encryptedColumn struct {
+	table  string
+	column string
+	filter string // Additional condition selecting encrypted rows
+}
+
+// encryptedColumns lists every encrypted column, so key rotation covers them all
+var encryptedColumns = []encryptedColumn{
+	{table: "event_queue", column: "raw_data"},
+	{table: "event_queue", column: "external_user_id", filter: "external_user_hash != ''"},
+	{table: "event_queue", column: "internal_user_id", filter: "internal_user_id != ''"},
+	{table: "device_config", column: "value", filter: sensitiveKeysFilter()},
+	{table: "doors", column: "relay"},
+	{table: "biometric_templates", column: "template_data"},
+	{table: "config_versions", column: "document"},
+}
+
+// sensitiveKeysFilter selects the device_config rows holding encrypted values
+func sensitiveKeysFilter() string {
+	keys := make([]string, 0, len(sensitiveKeys))
+	for key := range sensitiveKeys {
+		keys = append(keys, "'"+key+"'")
+	}
+	sort.Strings(keys)
+	return "lower(key) IN (" + strings.Join(keys, ", ") + ")"
+}
+
+// OpenWithKeyStore opens the database with the data-encryption keys held by
+// store. On first run a key is generated for the device. Values still using
+// an older key, including the legacy shared key, are re-encrypted by
+// CompleteKeyRotation.
+func OpenWithKeyStore(config Config, store SecretStore) (*DB, error) {
+	ring, err := loadKeyRing(store)
+	if err != nil {
+		return nil, err
+	}
+
+	created := ring == nil
+	if created {
+		ring = &KeyRing{Keys: make(map[int][]byte)}
+		if err := ring.addKey(); err != nil {
+			return nil, err
+		}
+	}
+	addedIndexKey := ring.IndexKey == nil
+	if addedIndexKey {
+		if ring.IndexKey, err = generateKey(); err != nil {
+			return nil, err
+		}
+	}
+
+	config.Keys = ring
+	db, err := NewDB(config)
+	if err != nil {
+		return nil, err
+	}
+	db.keyStore = store
+
+	if created {
+		// Values under a device key with no key ring mean the store lost it.
+		// Replacing the ring would make them unreadable for good.
+		count, err := db.countVersionedValues()
+		if err != nil {
+			db.Close()
+			return nil, err
+		}
+		if count > 0 {
+			db.Close()
+			return nil, fmt.Errorf("database key ring is missing from the credential store but %d values are encrypted with it", count)
+		}
+	}
+
+	if created || addedIndexKey {
+		if err := saveKeyRing(store, ring); err != nil {
+			db.Close()
+			return nil, err
+		}
+	}
+
+	return db, nil
+}
+
+// OpenWithLegacyKey opens the database with the shared legacy key, for
+// platforms without a secret store. Databases already using a device key are
+// refused rather than partly unreadable.
+func OpenWithLegacyKey(config Config) (*DB, error) {
+	config.EncryptionKey = []byte(legacyEncryptionKey)
+	config.Keys = nil
+	db, err := NewDB(config)
+	if err != nil {
+		return nil, err
+	}
+
+	count, err := db.countVersionedValues()
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	if count > 0 {
+		db.Close()
+		return nil, fmt.Errorf("database has %d values encrypted with a device key, which requires the credential store", count)
+	}
+
+	return db, nil
+}
+
+// CompleteKeyRotation re-encrypts values still using an older key and, once
+// none remain, removes the older keys from the key ring. It is safe to run
+// again after an interruption.
+func (db *DB) CompleteKeyRotation(ctx context.Context) (*ReencryptionResult, error) {
+	if db.keyStore == nil {
+		return nil, fmt.Errorf("database keys are not managed by a key store")
+	}
+
+	result, err := db.Reencrypt(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if result.Pending > 0 {
+		return result, nil
+	}
+
+	db.keyMu.Lock()
+	defer db.keyMu.Unlock()
+
+	for id := range db.keys.Keys {
+		if id != db.keys.ActiveID {
+			delete(db.keys.Keys, id)
+			result.RetiredKeys++
+		}
+	}
+	if result.RetiredKeys > 0 {
+		if err := saveKeyRing(db.keyStore, db.keys); err != nil {
+			return nil, err
+		}
+		if err := db.useKeys(db.keys); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// RotateKey generates a new active key and re-encrypts every encrypted value
+// with it. The key ring is saved before any value uses the new key, so an
+// interrupted rotation is resumed by CompleteKeyRotation. The previous key is
+// retired on the next startup, once no running bridge can still be using it.
+func (db *DB) RotateKey(ctx context.Context) (*ReencryptionResult, error) {
+	if db.keyStore == nil {
+		return nil, fmt.Errorf("database keys are not managed by a key store")
+	}
+
+	db.keyMu.Lock()
+	if err := db.keys.addKey(); err != nil {
+		db.keyMu.Unlock()
+		return nil, err
+	}
+	if err := saveKeyRing(db.keyStore, db.keys); err != nil {
+		delete(db.keys.Keys, db.keys.ActiveID)
+		db.keys.ActiveID = db.keyID
+		db.keyMu.Unlock()
+		return nil, err
+	}
+	err := db.useKeys(db.keys)
+	db.keyMu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	return db.Reencrypt(ctx)
+}
+
+// ActiveKeyID returns the ID of the key new values are encrypted with; 0 is
+// the unversioned format
+func (db *DB) ActiveKeyID() int {
+	db.keyMu.RLock()
+	defer db.keyMu.RUnlock()
+
+	return db.keyID
+}
+
+// Reencrypt re-encrypts every value not using the active key, in batches of
+// one transaction each. A value is only replaced if it is unchanged since it
+// was read, so concurrent writes are never overwritten.
+func (db *DB) Reencrypt(ctx context.Context) (*ReencryptionResult, error) {
+	result := &ReencryptionResult{}
+
+	for _, column := range encryptedColumns {
+		var lastRowID int64
+		for {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+
+			done, err := db.reencryptBatch(column, &lastRowID, result)
+			if err != nil {
+				return nil, err
+			}
+			if done {
+				break
+			}
+		}
+	}
+
+	pending, err := db.countStaleValues()
+	if err != nil {
+		return nil, err
+	}
+	result.Pending = pending
+
+	return result, nil
+}
+
+// reencryptBatch re-encrypts the next batch of stale values of a column after
+// lastRowID. It reports true when the column has no more stale values.
+func (db *DB) reencryptBatch(column encryptedColumn, lastRowID *int64, result *ReencryptionResult) (bool, error) {
+	prefix := db.keyPrefix()
+
+	query := fmt.Sprintf(`SELECT rowid, %[2]s FROM %[1]s WHERE rowid > ? AND %[3]s ORDER BY rowid LIMIT ?`,
+		column.table, column.column, db.staleCondition(column, prefix))
+	rows, err := db.conn.Query(query, *lastRowID, reencryptBatchSize)
+	if err != nil {
+		return false, fmt.Errorf("failed to query %s.%s: %w", column.table, column.column, err)
+	}
+
+	type staleValue struct {
+		rowID int64
+		value string
+	}
+	var batch []staleValue
+	for rows.Next() {
+		var value staleValue
+		if err := rows.Scan(&value.rowID, &value.value); err != nil {
+			rows.Close()
+			return false, fmt.Errorf("failed to scan %s.%s: %w", column.table, column.column, err)
+		}
+		batch = append(batch, value)
+	}
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		return false, fmt.Errorf("error iterating %s.%s: %w", column.table, column.column, err)
+	}
+	rows.Close()
+
+	if len(batch) == 0 {
+		return true, nil
+	}
+	*lastRowID = batch[len(batch)-1].rowID
+
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	update := fmt.Sprintf(`UPDATE %[1]s SET %[2]s = ? WHERE rowid = ? AND %[2]s = ?`, column.table, column.column)
+	reencrypted := 0
+	for _, value := range batch {
+		plaintext, err := db.Decrypt(value.value)
+		if err != nil {
+			result.Failed++
+			continue
+		}
+		encrypted, err := db.Encrypt(plaintext)
+		if err != nil {
+			return false, err
+		}
+
+		res, err := tx.Exec(update, encrypted, value.rowID, value.value)
+		if err != nil {
+			return false, fmt.Errorf("failed to update %s.%s: %w", column.table, column.column, err)
+		}
+		if n, _ := res.RowsAffected(); n > 0 {
+			reencrypted++
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit re-encryption batch: %w", err)
+	}
+	result.Reencrypted += reencrypted
+
+	return len(batch) < reencryptBatchSize, nil
+}
+
+// countStaleValues counts encrypted values not using the active key
+func (db *DB) countStaleValues() (int, error) {
+	prefix := db.keyPrefix()
+
+	total := 0
+	for _, column := range encryptedColumns {
+		var count int
+		query := fmt.Sprintf(`SELECT COUNT(*) FROM %s WHERE %s`, column.table, db.staleCondition(column, prefix))
+		if err := db.conn.QueryRow(query).Scan(&count); err != nil {
+			return 0, fmt.Errorf("failed to count %s.%s: %w", column.table, column.column, err)
+		}
+		total += count
+	}
+	return total, nil
+}
+
+// countVersionedValues counts encrypted values carrying a key ID
+func (db *DB) countVersionedValues() (int, error) {
+	total := 0
+	for _, column := range encryptedColumns {
+		condition := fmt.Sprintf(`%s LIKE 'k%%:%%'`, column.column)
+		if column.filter != "" {
+			condition += " AND " + column.filter
+		}
+
+		var count int
+		query := fmt.Sprintf(`SELECT COUNT(*) FROM %s WHERE %s`, column.table, condition)
+		if err := db.conn.QueryRow(query).Scan(&count); err != nil {
+			return 0, fmt.Errorf("failed to count %s.%s: %w", column.table, column.column, err)
+		}
+		total += count
+	}
+	return total, nil
+}
+
+// staleCondition selects the encrypted values of a column not written with
+// the key whose prefix is given
+func (db *DB) staleCondition(column encryptedColumn, prefix string) string {
+	condition := fmt.Sprintf(`%[1]s IS NOT NULL AND %[1]s != ''`, column.column)
+	if prefix == "" {
+		condition += fmt.Sprintf(` AND %s LIKE 'k%%:%%'`, column.column)
+	} else {
+		condition += fmt.Sprintf(` AND substr(%s, 1, %d) != '%s'`, column.column, len(prefix), prefix)
+	}
+	if column.filter != "" {
+		condition += " AND " + column.filter
+	}
+	return condition
+}
+
+// keyPrefix returns the prefix of values encrypted with the active key
+func (db *DB) keyPrefix() string {
+	db.keyMu.RLock()
+	defer db.keyMu.RUnlock()
+
+	return versionPrefix(db.keyID)
+}
+
+// versionPrefix returns the prefix identifying the key of an encrypted value.
+// Key 0 is the unversioned format.
+func versionPrefix(keyID int) string {
+	if keyID == 0 {
+		return ""
+	}
+	return "k" + strconv.Itoa(keyID) + ":"
+}
+
+// splitVersion separates the key ID from an encrypted value. Base64 never
+// contains ':', so unversioned values can't be mistaken for versioned ones.
+func splitVersion(value string) (int, string, error) {
+	if !strings.HasPrefix(value, "k") {
+		return 0, value, nil
+	}
+
+	idx := strings.IndexByte(value, ':')
+	if idx < 0 {
+		return 0, value, nil
+	}
+
+	keyID, err := strconv.Atoi(value[1:idx])
+	if err != nil || keyID <= 0 {
+		return 0, "", fmt.Errorf("invalid encryption key ID")
+	}
+	return keyID, value[idx+1:], nil
+}
+
+// useKeys builds the ciphers for a key ring. Callers hold keyMu.
+func (db *DB) useKeys(ring *KeyRing) error {
+	if _, ok := ring.Keys[ring.ActiveID]; !ok {
+		return fmt.Errorf("active encryption key %d is missing from the key ring", ring.ActiveID)
+	}
+	if ring.IndexKey == nil {
+		return fmt.Errorf("key ring has no index key")
+	}
+
+	ciphers := make(map[int]cipher.AEAD, len(ring.Keys)+1)
+
+	// Values written before keys were managed use the legacy shared key
+	legacy, err := newGCM([]byte(legacyEncryptionKey))
+	if err != nil {
+		return err
+	}
+	ciphers[0] = legacy
+
+	for id, key := range ring.Keys {
+		gcm, err := newGCM(key)
+		if err != nil {
+			return fmt.Errorf("invalid encryption key %d: %w", id, err)
+		}
+		ciphers[id] = gcm
+	}
+
+	db.keys = ring
+	db.indexKey = ring.IndexKey
+	db.ciphers = ciphers
+	db.keyID = ring.ActiveID
+	db.cipher = ciphers[ring.ActiveID]
+	return nil
+}
+
+// addKey generates a new key and makes it active
+func (k *KeyRing) addKey() error {
+	key, err := generateKey()
+	if err != nil {
+		return err
+	}
+
+	id := 1
+	for existing := range k.Keys {
+		if existing >= id {
+			id = existing + 1
+		}
+	}
+
+	k.Keys[id] = key
+	k.ActiveID = id
+	return nil
+}
+
+// generateKey generates a random 256-bit key
+func generateKey() ([]byte, error) {
+	key := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return nil, fmt.Errorf("failed to generate encryption key: %w", err)
+	}
+	return key, nil
+}
+
+// loadKeyRing reads the key ring from the store, returning nil when none exists
+func loadKeyRing(store SecretStore) (*KeyRing, error) {
+	data, err := store.GetSecret(keyRingSecretName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read database key ring: %w", err)
+	}
+	if data == nil {
+		return nil, nil
+	}
+
+	var ring KeyRing
+	if err := json.Unmarshal(data, &ring); err != nil {
+		return nil, fmt.Errorf("failed to parse database key ring: %w", err)
+	}
+	if _, ok := ring.Keys[ring.ActiveID]; !ok {
+		return nil, fmt.Errorf("database key ring has no active key")
+	}
+	return &ring, nil
+}
+
+// saveKeyRing writes the key ring to the store
+func saveKeyRing(store SecretStore, ring *KeyRing) error {
+	data, err := json.Marshal(ring)
+	if err != nil {
+		return fmt.Errorf("failed to marshal database key ring: %w", err)
+	}
+	if err := store.StoreSecret(keyRingSecretName, data); err != nil {
+		return fmt.Errorf("failed to store database key ring: %w", err)
+	}
+	return nil
+}
+
+// newGCM creates an AES-GCM cipher
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GCM: %w", err)
+	}
+	return gcm, nil
+}
@@ -0,0 +1,295 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// memorySecretStore keeps secrets in memory
+type memorySecretStore struct {
+	secrets map[string][]byte
+	failSet bool
+}
+
+func newMemorySecretStore() *memorySecretStore {
+	return &memorySecretStore{secrets: make(map[string][]byte)}
+}
+
+func (s *memorySecretStore) StoreSecret(name string, secret []byte) error {
+	if s.failSet {
+		return fmt.Errorf("store unavailable")
+	}
+	s.secrets[name] = append([]byte(nil), secret...)
+	return nil
+}
+
+func (s *memorySecretStore) GetSecret(name string) ([]byte, error) {
+	return s.secrets[name], nil
+}
+
+// seedEncryptedValues writes a value to every encrypted column
+func seedEncryptedValues(t *testing.T, db *DB) {
+	t.Helper()
+
+	if err := db.InsertEvent(&EventQueue{
+		EventID:        "evt-1",
+		ExternalUserID: "user-1",
+		Timestamp:      time.Now(),
+		EventType:      EventTypeEntry,
+		RawData:        `{"card":"1234"}`,
+	}); err != nil {
+		t.Fatalf("Failed to insert event: %v", err)
+	}
+	if err := db.SetConfig("device_key", "secret-device-key"); err != nil {
+		t.Fatalf("Failed to set config: %v", err)
+	}
+	if err := db.SetConfig("site_name", "Downtown"); err != nil {
+		t.Fatalf("Failed to set config: %v", err)
+	}
+	if err := db.UpsertDoor(&Door{ID: "front", Name: "Front", Relay: map[string]interface{}{"url": "http://admin:pw@relay"}}); err != nil {
+		t.Fatalf("Failed to upsert door: %v", err)
+	}
+	if _, err := db.SaveBiometricTemplate(&BiometricTemplate{DeviceUserID: 1, FingerIndex: 0, Flag: 1, Data: []byte{1, 2, 3}}); err != nil {
+		t.Fatalf("Failed to save template: %v", err)
+	}
+}
+
+// checkEncryptedValues verifies every seeded value can be read
+func checkEncryptedValues(t *testing.T, db *DB) {
+	t.Helper()
+
+	events, err := db.GetUnsentEvents(10)
+	if err != nil || len(events) != 1 || events[0].RawData != `{"card":"1234"}` {
+		t.Errorf("Unexpected events: %+v (%v)", events, err)
+	}
+	if value, err := db.GetConfig("device_key"); err != nil || value != "secret-device-key" {
+		t.Errorf("Unexpected device_key: %q (%v)", value, err)
+	}
+	if value, err := db.GetConfig("site_name"); err != nil || value != "Downtown" {
+		t.Errorf("Unexpected site_name: %q (%v)", value, err)
+	}
+	if door, err := db.GetDoor("front"); err != nil || door == nil || door.Relay["url"] != "http://admin:pw@relay" {
+		t.Errorf("Unexpected door: %+v (%v)", door, err)
+	}
+	if template, err := db.GetBiometricTemplate(1, 0); err != nil || template == nil || string(template.Data) != "\x01\x02\x03" {
+		t.Errorf("Unexpected template: %+v (%v)", template, err)
+	}
+}
+
+func TestOpenWithKeyStoreUpgradesLegacyKey(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "bridge.db")
+
+	// A database written by an older bridge with the shared key
+	legacy, err := NewDB(Config{DatabasePath: dbPath, EncryptionKey: []byte(legacyEncryptionKey), PerformanceTier: TierNormal})
+	if err != nil {
+		t.Fatalf("Failed to create legacy database: %v", err)
+	}
+	seedEncryptedValues(t, legacy)
+	legacy.Close()
+
+	store := newMemorySecretStore()
+	db, err := OpenWithKeyStore(Config{DatabasePath: dbPath, PerformanceTier: TierNormal}, store)
+	if err != nil {
+		t.Fatalf("OpenWithKeyStore() error = %v", err)
+	}
+	defer db.Close()
+
+	if store.secrets[keyRingSecretName] == nil {
+		t.Fatal("Expected a key ring to be stored on first run")
+	}
+	if db.ActiveKeyID() != 1 {
+		t.Errorf("Expected active key 1, got %d", db.ActiveKeyID())
+	}
+
+	// Legacy values stay readable before the upgrade completes
+	checkEncryptedValues(t, db)
+
+	result, err := db.CompleteKeyRotation(context.Background())
+	if err != nil {
+		t.Fatalf("CompleteKeyRotation() error = %v", err)
+	}
+	if result.Reencrypted != 5 || result.Pending != 0 || result.Failed != 0 {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+	checkEncryptedValues(t, db)
+
+	var plain string
+	if err := db.QueryRow("SELECT value FROM device_config WHERE key = 'site_name'").Scan(&plain); err != nil || plain != "Downtown" {
+		t.Errorf("Plain config values must not be touched: %q (%v)", plain, err)
+	}
+
+	// The shared key no longer opens the database
+	var raw string
+	if err := db.QueryRow("SELECT raw_data FROM event_queue").Scan(&raw); err != nil {
+		t.Fatalf("Failed to read raw_data: %v", err)
+	}
+	if !strings.HasPrefix(raw, "k1:") {
+		t.Errorf("Expected value encrypted with key 1, got %q", raw)
+	}
+}
+
+func TestRotateKey(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "bridge.db")
+	store := newMemorySecretStore()
+
+	db, err := OpenWithKeyStore(Config{DatabasePath: dbPath, PerformanceTier: TierNormal}, store)
+	if err != nil {
+		t.Fatalf("OpenWithKeyStore() error = %v", err)
+	}
+	seedEncryptedValues(t, db)
+
+	result, err := db.RotateKey(context.Background())
+	if err != nil {
+		t.Fatalf("RotateKey() error = %v", err)
+	}
+	if db.ActiveKeyID() != 2 || result.Reencrypted != 5 || result.Pending != 0 {
+		t.Errorf("Unexpected rotation: key %d, %+v", db.ActiveKeyID(), result)
+	}
+	checkEncryptedValues(t, db)
+	db.Close()
+
+	// The previous key is kept until the next startup retires it
+	ring, err := loadKeyRing(store)
+	if err != nil || ring.ActiveID != 2 || len(ring.Keys) != 2 {
+		t.Fatalf("Unexpected key ring after rotation: %+v (%v)", ring, err)
+	}
+
+	db, err = OpenWithKeyStore(Config{DatabasePath: dbPath, PerformanceTier: TierNormal}, store)
+	if err != nil {
+		t.Fatalf("OpenWithKeyStore() error = %v", err)
+	}
+	defer db.Close()
+
+	result, err = db.CompleteKeyRotation(context.Background())
+	if err != nil {
+		t.Fatalf("CompleteKeyRotation() error = %v", err)
+	}
+	if result.RetiredKeys != 1 {
+		t.Errorf("Expected the previous key to be retired, got %+v", result)
+	}
+	ring, _ = loadKeyRing(store)
+	if len(ring.Keys) != 1 || ring.Keys[2] == nil {
+		t.Errorf("Expected only key 2 to remain, got %+v", ring)
+	}
+	checkEncryptedValues(t, db)
+}
+
+func TestRotateKeyResumesAfterInterruption(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "bridge.db")
+	store := newMemorySecretStore()
+
+	db, err := OpenWithKeyStore(Config{DatabasePath: dbPath, PerformanceTier: TierNormal}, store)
+	if err != nil {
+		t.Fatalf("OpenWithKeyStore() error = %v", err)
+	}
+	seedEncryptedValues(t, db)
+
+	// Interrupt the rotation before any value is re-encrypted
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := db.RotateKey(ctx); err == nil {
+		t.Fatal("Expected the cancelled rotation to fail")
+	}
+	db.Close()
+
+	db, err = OpenWithKeyStore(Config{DatabasePath: dbPath, PerformanceTier: TierNormal}, store)
+	if err != nil {
+		t.Fatalf("OpenWithKeyStore() error = %v", err)
+	}
+	defer db.Close()
+
+	// Values under the previous key are still readable and get migrated
+	checkEncryptedValues(t, db)
+	result, err := db.CompleteKeyRotation(context.Background())
+	if err != nil {
+		t.Fatalf("CompleteKeyRotation() error = %v", err)
+	}
+	if result.Reencrypted != 5 || result.RetiredKeys != 1 {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+	checkEncryptedValues(t, db)
+}
+
+func TestRotateKeyKeepsKeyWhenStoreFails(t *testing.T) {
+	store := newMemorySecretStore()
+	db, err := OpenWithKeyStore(Config{DatabasePath: filepath.Join(t.TempDir(), "bridge.db"), PerformanceTier: TierNormal}, store)
+	if err != nil {
+		t.Fatalf("OpenWithKeyStore() error = %v", err)
+	}
+	defer db.Close()
+	seedEncryptedValues(t, db)
+
+	store.failSet = true
+	if _, err := db.RotateKey(context.Background()); err == nil {
+		t.Fatal("Expected rotation to fail when the key ring can't be saved")
+	}
+	if db.ActiveKeyID() != 1 {
+		t.Errorf("Expected key 1 to stay active, got %d", db.ActiveKeyID())
+	}
+	checkEncryptedValues(t, db)
+}
+
+func TestOpenWithKeyStoreRefusesLostKeyRing(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "bridge.db")
+	store := newMemorySecretStore()
+
+	db, err := OpenWithKeyStore(Config{DatabasePath: dbPath, PerformanceTier: TierNormal}, store)
+	if err != nil {
+		t.Fatalf("OpenWithKeyStore() error = %v", err)
+	}
+	seedEncryptedValues(t, db)
+	db.Close()
+
+	// A store that lost the key ring must not be given a new one
+	if _, err := OpenWithKeyStore(Config{DatabasePath: dbPath, PerformanceTier: TierNormal}, newMemorySecretStore()); err == nil {
+		t.Fatal("Expected an error when the key ring is missing")
+	}
+}
+
+func TestSplitVersion(t *testing.T) {
+	keyID, value, err := splitVersion("k12:c2VjcmV0")
+	if err != nil || keyID != 12 || value != "c2VjcmV0" {
+		t.Errorf("Unexpected split: %d %q %v", keyID, value, err)
+	}
+
+	// Base64 values starting with k are unversioned
+	keyID, value, err = splitVersion("kZm9v")
+	if err != nil || keyID != 0 || value != "kZm9v" {
+		t.Errorf("Unexpected split: %d %q %v", keyID, value, err)
+	}
+
+	if _, _, err := splitVersion("kx:abc"); err == nil {
+		t.Error("Expected error for an invalid key ID")
+	}
+}
+
+func TestOpenWithLegacyKey(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "bridge.db")
+
+	db, err := OpenWithLegacyKey(Config{DatabasePath: dbPath, PerformanceTier: TierNormal})
+	if err != nil {
+		t.Fatalf("OpenWithLegacyKey() error = %v", err)
+	}
+	seedEncryptedValues(t, db)
+	checkEncryptedValues(t, db)
+	db.Close()
+
+	// Once upgraded to a device key the legacy key can't open the database
+	store := newMemorySecretStore()
+	db, err = OpenWithKeyStore(Config{DatabasePath: dbPath, PerformanceTier: TierNormal}, store)
+	if err != nil {
+		t.Fatalf("OpenWithKeyStore() error = %v", err)
+	}
+	if _, err := db.CompleteKeyRotation(context.Background()); err != nil {
+		t.Fatalf("CompleteKeyRotation() error = %v", err)
+	}
+	db.Close()
+
+	if _, err := OpenWithLegacyKey(Config{DatabasePath: dbPath, PerformanceTier: TierNormal}); err == nil {
+		t.Error("Expected the legacy key to be refused for an upgraded database")
+	}
+}
@@ -0,0 +1,230 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// isValidMembershipStatus checks if the provided membership status is valid
+func isValidMembershipStatus(status string) bool {
+	switch status {
+	case MembershipStatusActive, MembershipStatusSuspended, MembershipStatusExpired, MembershipStatusCancelled:
+		return true
+	default:
+		return false
+	}
+}
+
+// UpsertMemberEntitlement creates or replaces the cached entitlement for a member
+func (db *DB) UpsertMemberEntitlement(entitlement *MemberEntitlement) error {
+	if entitlement == nil {
+		return fmt.Errorf("entitlement cannot be nil")
+	}
+	if entitlement.InternalUserID == "" {
+		return fmt.Errorf("internal user ID cannot be empty")
+	}
+	if !isValidMembershipStatus(entitlement.Status) {
+		return fmt.Errorf("invalid membership status: %s", entitlement.Status)
+	}
+
+	query := `
+		INSERT OR REPLACE INTO member_entitlements (internal_user_id, status, membership_expires_at, allowed_hours, synced_at, updated_at)
+		VALUES (?, ?, ?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+	`
+
+	expiresAt, allowedHours := entitlementNullables(entitlement)
+	_, err := db.conn.Exec(query,
+		entitlement.InternalUserID,
+		entitlement.Status,
+		expiresAt,
+		allowedHours,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to upsert member entitlement for %s: %w", entitlement.InternalUserID, err)
+	}
+
+	return nil
+}
+
+// GetMemberEntitlement retrieves the cached entitlement for a member
+// Returns nil if no entitlement is cached for the member
+func (db *DB) GetMemberEntitlement(internalUserID string) (*MemberEntitlement, error) {
+	if internalUserID == "" {
+		return nil, fmt.Errorf("internal user ID cannot be empty")
+	}
+
+	query := `
+		SELECT internal_user_id, status, membership_expires_at, allowed_hours, synced_at, updated_at
+		FROM member_entitlements
+		WHERE internal_user_id = ?
+	`
+
+	entitlement, err := scanMemberEntitlement(db.conn.QueryRow(query, internalUserID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // No entitlement cached
+		}
+		return nil, fmt.Errorf("failed to get member entitlement: %w", err)
+	}
+
+	return entitlement, nil
+}
+
+// DeleteMemberEntitlement removes the cached entitlement for a member
+func (db *DB) DeleteMemberEntitlement(internalUserID string) error {
+	if internalUserID == "" {
+		return fmt.Errorf("internal user ID cannot be empty")
+	}
+
+	query := `DELETE FROM member_entitlements WHERE internal_user_id = ?`
+	if _, err := db.conn.Exec(query, internalUserID); err != nil {
+		return fmt.Errorf("failed to delete member entitlement: %w", err)
+	}
+
+	return nil
+}
+
+// ListMemberEntitlements retrieves cached entitlements with optional pagination
+func (db *DB) ListMemberEntitlements(limit, offset int) ([]MemberEntitlement, error) {
+	if limit <= 0 {
+		limit = 100 // Default limit
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	query := `
+		SELECT internal_user_id, status, membership_expires_at, allowed_hours, synced_at, updated_at
+		FROM member_entitlements
+		ORDER BY internal_user_id
+		LIMIT ? OFFSET ?
+	`
+
+	rows, err := db.conn.Query(query, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list member entitlements: %w", err)
+	}
+	defer rows.Close()
+
+	var entitlements []MemberEntitlement
+	for rows.Next() {
+		entitlement, err := scanMemberEntitlement(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan member entitlement: %w", err)
+		}
+		entitlements = append(entitlements, *entitlement)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating member entitlements: %w", err)
+	}
+
+	return entitlements, nil
+}
+
+// CountMemberEntitlements returns the total number of cached entitlements
+func (db *DB) CountMemberEntitlements() (int64, error) {
+	var count int64
+	if err := db.conn.QueryRow(`SELECT COUNT(*) FROM member_entitlements`).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count member entitlements: %w", err)
+	}
+	return count, nil
+}
+
+// ReplaceMemberEntitlements atomically replaces the whole entitlement cache
+// with a full snapshot received from the platform
+func (db *DB) ReplaceMemberEntitlements(entitlements []MemberEntitlement) error {
+	for _, entitlement := range entitlements {
+		if entitlement.InternalUserID == "" {
+			return fmt.Errorf("internal user ID cannot be empty")
+		}
+		if !isValidMembershipStatus(entitlement.Status) {
+			return fmt.Errorf("invalid membership status for %s: %s", entitlement.InternalUserID, entitlement.Status)
+		}
+	}
+
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM member_entitlements`); err != nil {
+		return fmt.Errorf("failed to clear member entitlements: %w", err)
+	}
+
+	stmt, err := tx.Prepare(`
+		INSERT INTO member_entitlements (internal_user_id, status, membership_expires_at, allowed_hours, synced_at, updated_at)
+		VALUES (?, ?, ?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare entitlement insert: %w", err)
+	}
+	defer stmt.Close()
+
+	for i := range entitlements {
+		entitlement := &entitlements[i]
+		expiresAt, allowedHours := entitlementNullables(entitlement)
+		if _, err := stmt.Exec(
+			entitlement.InternalUserID,
+			entitlement.Status,
+			expiresAt,
+			allowedHours,
+		); err != nil {
+			return fmt.Errorf("failed to insert member entitlement for %s: %w", entitlement.InternalUserID, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit member entitlements: %w", err)
+	}
+
+	return nil
+}
+
+// entitlementNullables converts the optional entitlement fields to nullable SQL values
+func entitlementNullables(entitlement *MemberEntitlement) (sql.NullTime, sql.NullString) {
+	var expiresAt sql.NullTime
+	if entitlement.MembershipExpiresAt != nil {
+		expiresAt = sql.NullTime{Time: *entitlement.MembershipExpiresAt, Valid: true}
+	}
+
+	var allowedHours sql.NullString
+	if entitlement.AllowedHours != "" {
+		allowedHours = sql.NullString{String: entitlement.AllowedHours, Valid: true}
+	}
+
+	return expiresAt, allowedHours
+}
+
+// rowScanner is implemented by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanMemberEntitlement scans a single member_entitlements row
+func scanMemberEntitlement(row rowScanner) (*MemberEntitlement, error) {
+	entitlement := &MemberEntitlement{}
+	var expiresAt sql.NullTime
+	var allowedHours sql.NullString
+
+	if err := row.Scan(
+		&entitlement.InternalUserID,
+		&entitlement.Status,
+		&expiresAt,
+		&allowedHours,
+		&entitlement.SyncedAt,
+		&entitlement.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	if expiresAt.Valid {
+		entitlement.MembershipExpiresAt = &expiresAt.Time
+	}
+	if allowedHours.Valid {
+		entitlement.AllowedHours = allowedHours.String
+	}
+
+	return entitlement, nil
+}
@@ -0,0 +1,213 @@
+package database
+
+import (
+	"testing"
+	"time"
+)
+
+func TestUpsertMemberEntitlement(t *testing.T) {
+	db := setupTestDB(t, TierNormal)
+
+	expiresAt := time.Now().Add(30 * 24 * time.Hour).UTC().Truncate(time.Second)
+
+	tests := []struct {
+		name        string
+		entitlement *MemberEntitlement
+		expectError bool
+	}{
+		{
+			name: "active member with expiry and hours",
+			entitlement: &MemberEntitlement{
+				InternalUserID:      "user_abc123",
+				Status:              MembershipStatusActive,
+				MembershipExpiresAt: &expiresAt,
+				AllowedHours:        `[{"days":[1,2,3,4,5],"start":"06:00","end":"22:00"}]`,
+			},
+			expectError: false,
+		},
+		{
+			name: "suspended member without optional fields",
+			entitlement: &MemberEntitlement{
+				InternalUserID: "user_def456",
+				Status:         MembershipStatusSuspended,
+			},
+			expectError: false,
+		},
+		{
+			name: "empty internal user ID",
+			entitlement: &MemberEntitlement{
+				Status: MembershipStatusActive,
+			},
+			expectError: true,
+		},
+		{
+			name: "invalid status",
+			entitlement: &MemberEntitlement{
+				InternalUserID: "user_ghi789",
+				Status:         "pending",
+			},
+			expectError: true,
+		},
+		{
+			name:        "nil entitlement",
+			entitlement: nil,
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := db.UpsertMemberEntitlement(tt.entitlement)
+
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("expected error but got none")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			stored, err := db.GetMemberEntitlement(tt.entitlement.InternalUserID)
+			if err != nil {
+				t.Fatalf("failed to get entitlement: %v", err)
+			}
+			if stored == nil {
+				t.Fatalf("expected entitlement but got nil")
+			}
+
+			if stored.Status != tt.entitlement.Status {
+				t.Errorf("expected status %s, got %s", tt.entitlement.Status, stored.Status)
+			}
+
+			if stored.AllowedHours != tt.entitlement.AllowedHours {
+				t.Errorf("expected allowed hours %q, got %q", tt.entitlement.AllowedHours, stored.AllowedHours)
+			}
+
+			if tt.entitlement.MembershipExpiresAt == nil {
+				if stored.MembershipExpiresAt != nil {
+					t.Errorf("expected no expiry, got %v", stored.MembershipExpiresAt)
+				}
+			} else if stored.MembershipExpiresAt == nil || !stored.MembershipExpiresAt.Equal(*tt.entitlement.MembershipExpiresAt) {
+				t.Errorf("expected expiry %v, got %v", tt.entitlement.MembershipExpiresAt, stored.MembershipExpiresAt)
+			}
+		})
+	}
+}
+
+func TestUpsertMemberEntitlement_Overwrite(t *testing.T) {
+	db := setupTestDB(t, TierNormal)
+
+	if err := db.UpsertMemberEntitlement(&MemberEntitlement{InternalUserID: "user_1", Status: MembershipStatusActive}); err != nil {
+		t.Fatalf("failed to insert entitlement: %v", err)
+	}
+	if err := db.UpsertMemberEntitlement(&MemberEntitlement{InternalUserID: "user_1", Status: MembershipStatusCancelled}); err != nil {
+		t.Fatalf("failed to update entitlement: %v", err)
+	}
+
+	stored, err := db.GetMemberEntitlement("user_1")
+	if err != nil {
+		t.Fatalf("failed to get entitlement: %v", err)
+	}
+	if stored.Status != MembershipStatusCancelled {
+		t.Errorf("expected status %s, got %s", MembershipStatusCancelled, stored.Status)
+	}
+
+	count, err := db.CountMemberEntitlements()
+	if err != nil {
+		t.Fatalf("failed to count entitlements: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("expected 1 entitlement, got %d", count)
+	}
+}
+
+func TestGetMemberEntitlement_NotFound(t *testing.T) {
+	db := setupTestDB(t, TierNormal)
+
+	entitlement, err := db.GetMemberEntitlement("missing_user")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if entitlement != nil {
+		t.Errorf("expected nil entitlement, got %+v", entitlement)
+	}
+
+	if _, err := db.GetMemberEntitlement(""); err == nil {
+		t.Errorf("expected error for empty internal user ID")
+	}
+}
+
+func TestDeleteMemberEntitlement(t *testing.T) {
+	db := setupTestDB(t, TierNormal)
+
+	if err := db.UpsertMemberEntitlement(&MemberEntitlement{InternalUserID: "user_1", Status: MembershipStatusActive}); err != nil {
+		t.Fatalf("failed to insert entitlement: %v", err)
+	}
+
+	if err := db.DeleteMemberEntitlement("user_1"); err != nil {
+		t.Fatalf("failed to delete entitlement: %v", err)
+	}
+
+	entitlement, err := db.GetMemberEntitlement("user_1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if entitlement != nil {
+		t.Errorf("expected entitlement to be deleted")
+	}
+}
+
+func TestReplaceMemberEntitlements(t *testing.T) {
+	db := setupTestDB(t, TierNormal)
+
+	if err := db.UpsertMemberEntitlement(&MemberEntitlement{InternalUserID: "stale_user", Status: MembershipStatusActive}); err != nil {
+		t.Fatalf("failed to insert entitlement: %v", err)
+	}
+
+	snapshot := []MemberEntitlement{
+		{InternalUserID: "user_b", Status: MembershipStatusActive},
+		{InternalUserID: "user_a", Status: MembershipStatusExpired},
+	}
+	if err := db.ReplaceMemberEntitlements(snapshot); err != nil {
+		t.Fatalf("failed to replace entitlements: %v", err)
+	}
+
+	entitlements, err := db.ListMemberEntitlements(10, 0)
+	if err != nil {
+		t.Fatalf("failed to list entitlements: %v", err)
+	}
+	if len(entitlements) != 2 {
+		t.Fatalf("expected 2 entitlements, got %d", len(entitlements))
+	}
+	if entitlements[0].InternalUserID != "user_a" || entitlements[1].InternalUserID != "user_b" {
+		t.Errorf("unexpected entitlement order: %s, %s", entitlements[0].InternalUserID, entitlements[1].InternalUserID)
+	}
+
+	stale, err := db.GetMemberEntitlement("stale_user")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stale != nil {
+		t.Errorf("expected stale entitlement to be removed by replace")
+	}
+
+	// An invalid snapshot must leave the existing cache untouched
+	invalid := []MemberEntitlement{
+		{InternalUserID: "user_c", Status: MembershipStatusActive},
+		{InternalUserID: "user_d", Status: "unknown"},
+	}
+	if err := db.ReplaceMemberEntitlements(invalid); err == nil {
+		t.Fatalf("expected error for invalid snapshot")
+	}
+
+	count, err := db.CountMemberEntitlements()
+	if err != nil {
+		t.Fatalf("failed to count entitlements: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("expected cache to keep 2 entitlements, got %d", count)
+	}
+}
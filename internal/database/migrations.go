@@ -2,6 +2,7 @@ package database
 
 import (
 	"fmt"
+	"strings"
 )
 
 // migrate runs database migrations to create the required schema
@@ -11,6 +12,16 @@ func (db *DB) migrate() error {
 		createDeviceConfigTable,
 		createAdapterStatusTable,
 		createExternalUserMappingsTable,
+		createMemberEntitlementsTable,
+		createDoorsTable,
+		createDoorSchedulesTable,
+		createDoorHolidaysTable,
+		createAntiPassbackStateTable,
+		createProcessedCommandsTable,
+		createDeviceUserAssignmentsTable,
+		createBiometricTemplatesTable,
+		createEventEvictionsTable,
+		createConfigVersionsTable,
 		createIndexes,
 	}
 	
@@ -26,6 +37,90 @@ func (db *DB) migrate() error {
 		return fmt.Errorf("device_id column migration failed: %w", err)
 	}
 	
+	if err := db.addColumnIfMissing("event_queue", "door_id", addDoorIdToEventQueue); err != nil {
+		return fmt.Errorf("door_id column migration failed: %w", err)
+	}
+	if err := db.addColumnIfMissing("event_queue", "external_user_hash", addExternalUserHashToEventQueue); err != nil {
+		return fmt.Errorf("external_user_hash column migration failed: %w", err)
+	}
+	for _, column := range eventQueueSchemaV2Columns {
+		if err := db.addColumnIfMissing("event_queue", column.name, column.statement); err != nil {
+			return fmt.Errorf("%s column migration failed: %w", column.name, err)
+		}
+	}
+	for _, column := range externalUserMappingRosterColumns {
+		if err := db.addColumnIfMissing("external_user_mappings", column.name, column.statement); err != nil {
+			return fmt.Errorf("%s column migration failed: %w", column.name, err)
+		}
+	}
+	if _, err := db.conn.Exec(createRosterIndexes); err != nil {
+		return fmt.Errorf("migration failed: %w", err)
+	}
+	if err := db.migrateEventTypeConstraint(); err != nil {
+		return fmt.Errorf("event_type constraint migration failed: %w", err)
+	}
+	if _, err := db.conn.Exec(createDoorIdIndex); err != nil {
+		return fmt.Errorf("migration failed: %w", err)
+	}
+	if _, err := db.conn.Exec(createExternalUserHashIndex); err != nil {
+		return fmt.Errorf("migration failed: %w", err)
+	}
+	
+	return nil
+}
+
+// migrateEventTypeConstraint rebuilds event_queue on databases created before
+// door alarm event types existed, since SQLite cannot alter a CHECK constraint
+func (db *DB) migrateEventTypeConstraint() error {
+	var schema string
+	query := `SELECT sql FROM sqlite_master WHERE type = 'table' AND name = 'event_queue'`
+	if err := db.conn.QueryRow(query).Scan(&schema); err != nil {
+		return fmt.Errorf("failed to read event_queue schema: %w", err)
+	}
+	if strings.Contains(schema, EventTypeDoorForcedOpen) {
+		return nil
+	}
+	
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+	
+	statements := []string{
+		`ALTER TABLE event_queue RENAME TO event_queue_old`,
+		createEventQueueTable,
+		`INSERT INTO event_queue (` + eventQueueColumns + `) SELECT ` + eventQueueColumns + ` FROM event_queue_old`,
+		`DROP TABLE event_queue_old`,
+		createIndexes,
+	}
+	for _, statement := range statements {
+		if _, err := tx.Exec(statement); err != nil {
+			return fmt.Errorf("failed to rebuild event_queue: %w", err)
+		}
+	}
+	
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit event_queue rebuild: %w", err)
+	}
+	
+	return nil
+}
+
+// addColumnIfMissing runs the ALTER TABLE statement when the column does not exist yet
+func (db *DB) addColumnIfMissing(table, column, statement string) error {
+	var count int
+	query := `SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?`
+	if err := db.conn.QueryRow(query, table, column).Scan(&count); err != nil {
+		return fmt.Errorf("failed to check %s column existence: %w", column, err)
+	}
+	
+	if count == 0 {
+		if _, err := db.conn.Exec(statement); err != nil {
+			return fmt.Errorf("failed to add %s column: %w", column, err)
+		}
+	}
+	
 	return nil
 }
 
@@ -54,17 +149,27 @@ const createEventQueueTable = `
 CREATE TABLE IF NOT EXISTS event_queue (
     id INTEGER PRIMARY KEY AUTOINCREMENT,
     event_id TEXT UNIQUE NOT NULL,
-    external_user_id TEXT NOT NULL,
+    external_user_id TEXT NOT NULL, -- Encrypted once external_user_hash is set
+    external_user_hash TEXT NOT NULL DEFAULT '', -- Blind index of external_user_id
     timestamp DATETIME NOT NULL,
-    event_type TEXT NOT NULL CHECK (event_type IN ('entry', 'exit', 'denied')),
+    event_type TEXT NOT NULL CHECK (event_type IN ('entry', 'exit', 'denied', 'door_forced_open', 'door_held_open')),
     is_simulated BOOLEAN DEFAULT FALSE,
     device_id TEXT NOT NULL DEFAULT '',
+    door_id TEXT NOT NULL DEFAULT '',
+    internal_user_id TEXT NOT NULL DEFAULT '', -- Encrypted
+    adapter_name TEXT NOT NULL DEFAULT '',
+    verify_mode TEXT NOT NULL DEFAULT '',
+    access_reason TEXT NOT NULL DEFAULT '',
+    schema_version INTEGER NOT NULL DEFAULT 1,
     raw_data TEXT, -- Encrypted JSON
     created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
     sent_at DATETIME NULL,
     retry_count INTEGER DEFAULT 0
 );`
 
+// eventQueueColumns lists the event_queue columns copied when the table is rebuilt
+const eventQueueColumns = `id, event_id, external_user_id, external_user_hash, timestamp, event_type, is_simulated, device_id, door_id, internal_user_id, adapter_name, verify_mode, access_reason, schema_version, raw_data, created_at, sent_at, retry_count`
+
 const createDeviceConfigTable = `
 CREATE TABLE IF NOT EXISTS device_config (
     key TEXT PRIMARY KEY,
@@ -92,6 +197,109 @@ CREATE TABLE IF NOT EXISTS external_user_mappings (
     updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
 );`
 
+const createMemberEntitlementsTable = `
+CREATE TABLE IF NOT EXISTS member_entitlements (
+    internal_user_id TEXT PRIMARY KEY,
+    status TEXT NOT NULL CHECK (status IN ('active', 'suspended', 'expired', 'cancelled')),
+    membership_expires_at DATETIME NULL,
+    allowed_hours TEXT, -- JSON array of weekly access windows, NULL means any time
+    synced_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);`
+
+const createDoorsTable = `
+CREATE TABLE IF NOT EXISTS doors (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL DEFAULT '',
+    zone TEXT NOT NULL DEFAULT '',
+    adapters TEXT NOT NULL DEFAULT '[]', -- JSON array of reader adapter names bound to the door
+    relay TEXT, -- Encrypted JSON relay settings, NULL when the door has no relay of its own
+    unlock_duration_ms INTEGER NOT NULL DEFAULT 0,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);`
+
+const createDoorSchedulesTable = `
+CREATE TABLE IF NOT EXISTS door_schedules (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL DEFAULT '',
+    door_ids TEXT NOT NULL DEFAULT '[]', -- JSON array, empty for every door
+    windows TEXT NOT NULL, -- JSON array of weekly free-access windows
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);`
+
+const createDoorHolidaysTable = `
+CREATE TABLE IF NOT EXISTS door_holidays (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL DEFAULT '',
+    date TEXT NOT NULL UNIQUE, -- YYYY-MM-DD in the site time zone
+    start_time TEXT NOT NULL DEFAULT '',
+    end_time TEXT NOT NULL DEFAULT '',
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);`
+
+const createAntiPassbackStateTable = `
+CREATE TABLE IF NOT EXISTS anti_passback_state (
+    internal_user_id TEXT NOT NULL,
+    zone TEXT NOT NULL DEFAULT '',
+    state TEXT NOT NULL CHECK (state IN ('in', 'out')),
+    door_id TEXT NOT NULL DEFAULT '',
+    updated_at DATETIME NOT NULL, -- time of the entry or exit event
+    PRIMARY KEY (internal_user_id, zone)
+);`
+
+const createProcessedCommandsTable = `
+CREATE TABLE IF NOT EXISTS processed_commands (
+    command_id TEXT PRIMARY KEY,
+    command_type TEXT NOT NULL,
+    status TEXT NOT NULL,
+    result TEXT NOT NULL DEFAULT '', -- JSON result returned in the acknowledgement
+    error TEXT NOT NULL DEFAULT '',
+    processed_at DATETIME NOT NULL
+);`
+
+const createDeviceUserAssignmentsTable = `
+CREATE TABLE IF NOT EXISTS device_user_assignments (
+    device_user_id INTEGER PRIMARY KEY,
+    internal_user_id TEXT NOT NULL UNIQUE,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);`
+
+const createBiometricTemplatesTable = `
+CREATE TABLE IF NOT EXISTS biometric_templates (
+    device_user_id INTEGER NOT NULL,
+    finger_index INTEGER NOT NULL,
+    flag INTEGER NOT NULL DEFAULT 1,
+    template_data TEXT NOT NULL,
+    source_terminal TEXT NOT NULL DEFAULT '',
+    captured_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (device_user_id, finger_index)
+);`
+
+const createEventEvictionsTable = `
+CREATE TABLE IF NOT EXISTS event_evictions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    priority_class TEXT NOT NULL,
+    event_count INTEGER NOT NULL,
+    oldest_event_at DATETIME NOT NULL,
+    newest_event_at DATETIME NOT NULL,
+    last_evicted_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);`
+
+const createConfigVersionsTable = `
+CREATE TABLE IF NOT EXISTS config_versions (
+    version INTEGER PRIMARY KEY,
+    document TEXT NOT NULL, -- Encrypted JSON config document
+    status TEXT NOT NULL CHECK (status IN ('applied', 'invalid', 'rolled_back', 'failed')),
+    error TEXT NOT NULL DEFAULT '',
+    received_at DATETIME NOT NULL,
+    applied_at DATETIME NULL
+);`
+
 const createIndexes = `
 CREATE INDEX IF NOT EXISTS idx_event_queue_timestamp ON event_queue(timestamp);
 CREATE INDEX IF NOT EXISTS idx_event_queue_sent_at ON event_queue(sent_at);
@@ -100,7 +308,50 @@ CREATE INDEX IF NOT EXISTS idx_event_queue_device_id ON event_queue(device_id);
 CREATE INDEX IF NOT EXISTS idx_adapter_status_updated_at ON adapter_status(updated_at);
 CREATE INDEX IF NOT EXISTS idx_external_user_mappings_external_id ON external_user_mappings(external_user_id);
 CREATE INDEX IF NOT EXISTS idx_external_user_mappings_internal_id ON external_user_mappings(internal_user_id);
+CREATE INDEX IF NOT EXISTS idx_member_entitlements_status ON member_entitlements(status);
+CREATE INDEX IF NOT EXISTS idx_doors_zone ON doors(zone);
+CREATE INDEX IF NOT EXISTS idx_processed_commands_processed_at ON processed_commands(processed_at);
 `
 
 const addDeviceIdToEventQueue = `
-ALTER TABLE event_queue ADD COLUMN device_id TEXT NOT NULL DEFAULT '';`
\ No newline at end of file
+ALTER TABLE event_queue ADD COLUMN device_id TEXT NOT NULL DEFAULT '';`
+
+const addDoorIdToEventQueue = `
+ALTER TABLE event_queue ADD COLUMN door_id TEXT NOT NULL DEFAULT '';`
+
+// externalUserMappingRosterColumns track where a mapping came from, added for
+// roster sync from the platform
+var externalUserMappingRosterColumns = []struct {
+	name      string
+	statement string
+}{
+	{"source", `ALTER TABLE external_user_mappings ADD COLUMN source TEXT NOT NULL DEFAULT 'local';`},
+	{"credential_type", `ALTER TABLE external_user_mappings ADD COLUMN credential_type TEXT NOT NULL DEFAULT '';`},
+	{"member_status", `ALTER TABLE external_user_mappings ADD COLUMN member_status TEXT NOT NULL DEFAULT '';`},
+	{"synced_at", `ALTER TABLE external_user_mappings ADD COLUMN synced_at DATETIME;`},
+}
+
+const createRosterIndexes = `
+CREATE INDEX IF NOT EXISTS idx_external_user_mappings_source ON external_user_mappings(source, synced_at);`
+
+const createDoorIdIndex = `
+CREATE INDEX IF NOT EXISTS idx_event_queue_door_id ON event_queue(door_id);`
+
+const addExternalUserHashToEventQueue = `
+ALTER TABLE event_queue ADD COLUMN external_user_hash TEXT NOT NULL DEFAULT '';`
+
+// eventQueueSchemaV2Columns keep the event fields added in EventSchemaVersion 2.
+// Existing rows default to schema version 1.
+var eventQueueSchemaV2Columns = []struct {
+	name      string
+	statement string
+}{
+	{"internal_user_id", `ALTER TABLE event_queue ADD COLUMN internal_user_id TEXT NOT NULL DEFAULT '';`},
+	{"adapter_name", `ALTER TABLE event_queue ADD COLUMN adapter_name TEXT NOT NULL DEFAULT '';`},
+	{"verify_mode", `ALTER TABLE event_queue ADD COLUMN verify_mode TEXT NOT NULL DEFAULT '';`},
+	{"access_reason", `ALTER TABLE event_queue ADD COLUMN access_reason TEXT NOT NULL DEFAULT '';`},
+	{"schema_version", `ALTER TABLE event_queue ADD COLUMN schema_version INTEGER NOT NULL DEFAULT 1;`},
+}
+
+const createExternalUserHashIndex = `
+CREATE INDEX IF NOT EXISTS idx_event_queue_external_user_hash ON event_queue(external_user_hash, event_type, timestamp);`
@@ -9,16 +9,28 @@ type EventQueue struct {
 	ID             int64     `json:"id"`
 	EventID        string    `json:"event_id"`
 	ExternalUserID string    `json:"external_user_id"`
+	ExternalUserHash string  `json:"-"` // Blind index of ExternalUserID, empty for events stored before encryption
 	Timestamp      time.Time `json:"timestamp"`
 	EventType      string    `json:"event_type"`
 	IsSimulated    bool      `json:"is_simulated"`
 	DeviceID       string    `json:"device_id"`
+	DoorID         string    `json:"door_id,omitempty"`
+	InternalUserID string    `json:"internal_user_id,omitempty"` // Encrypted at rest
+	AdapterName    string    `json:"adapter_name,omitempty"`
+	VerifyMode     string    `json:"verify_mode,omitempty"`
+	AccessReason   string    `json:"access_reason,omitempty"`
+	SchemaVersion  int       `json:"schema_version"` // EventSchemaVersion the event was queued with
 	RawData        string    `json:"raw_data,omitempty"` // Encrypted JSON
 	CreatedAt      time.Time `json:"created_at"`
 	SentAt         *time.Time `json:"sent_at,omitempty"`
 	RetryCount     int       `json:"retry_count"`
 }
 
+// EventSchemaVersion is the version of the event fields stored in event_queue.
+// Version 1 events were queued before the internal user ID, adapter, verify
+// mode and access reason were kept, so those fields are empty.
+const EventSchemaVersion = 2
+
 // DeviceConfig represents a configuration key-value pair
 type DeviceConfig struct {
 	Key       string    `json:"key"`
@@ -40,8 +52,30 @@ const (
 	EventTypeEntry  = "entry"
 	EventTypeExit   = "exit"
 	EventTypeDenied = "denied"
+
+	EventTypeDoorForcedOpen = "door_forced_open"
+	EventTypeDoorHeldOpen   = "door_held_open"
 )
 
+// Event priority classes, from the first to the last evicted when the queue is full
+const (
+	PriorityClassSimulated = "simulated"
+	PriorityClassAccess    = "access" // Entry and exit
+	PriorityClassDenied    = "denied"
+	PriorityClassSecurity  = "security" // Door alarms
+)
+
+// EventEviction summarises the unsent events of one priority class evicted
+// from the full queue since the platform was last told
+type EventEviction struct {
+	ID            int64     `json:"id"`
+	PriorityClass string    `json:"priority_class"`
+	EventCount    int       `json:"event_count"`
+	OldestEventAt time.Time `json:"oldest_event_at"`
+	NewestEventAt time.Time `json:"newest_event_at"`
+	LastEvictedAt time.Time `json:"last_evicted_at"`
+}
+
 // AdapterStatusType constants
 const (
 	AdapterStatusActive   = "active"
@@ -56,6 +90,173 @@ type ExternalUserMapping struct {
 	InternalUserID string    `json:"internal_user_id"`
 	UserName       string    `json:"user_name,omitempty"`       // Optional display name
 	Notes          string    `json:"notes,omitempty"`           // Optional notes
+	Source         string    `json:"source"`                    // "local", "platform" or "provisioning"
+	CredentialType string    `json:"credential_type,omitempty"` // Roster credential type for platform mappings
+	MemberStatus   string    `json:"member_status,omitempty"`   // Member status from the roster
 	CreatedAt      time.Time `json:"created_at"`
 	UpdatedAt      time.Time `json:"updated_at"`
-}
\ No newline at end of file
+}
+
+// Mapping sources
+const (
+	MappingSourceLocal        = "local"        // Created or edited on the bridge
+	MappingSourcePlatform     = "platform"     // Pulled from the platform member roster
+	MappingSourceProvisioning = "provisioning" // Device user provisioned on biometric terminals
+)
+
+// Roster credential types
+const (
+	CredentialTypeCard        = "card"
+	CredentialTypeFingerprint = "fingerprint"
+	CredentialTypeDeviceUser  = "device_user"
+)
+
+// RosterMember is a member record from the platform roster. Each credential
+// becomes an external user mapping to the member.
+type RosterMember struct {
+	InternalUserID string
+	UserName       string
+	Status         string
+	Credentials    []RosterCredential
+	UpdatedAt      time.Time // When the platform last changed the member
+	Deleted        bool      // Tombstone: the member was removed from the roster
+}
+
+// RosterCredential identifies a member on a device
+type RosterCredential struct {
+	ExternalUserID string
+	Type           string
+}
+
+// RosterApplyResult counts the mapping changes made by applying roster members
+type RosterApplyResult struct {
+	Created   int `json:"created"`
+	Updated   int `json:"updated"`
+	Deleted   int `json:"deleted"`
+	Conflicts int `json:"conflicts"` // Newer local mappings kept over platform data
+}
+
+// MemberEntitlement represents the locally cached access entitlement of a member
+type MemberEntitlement struct {
+	InternalUserID      string     `json:"internal_user_id"`
+	Status              string     `json:"status"`
+	MembershipExpiresAt *time.Time `json:"membership_expires_at,omitempty"`
+	AllowedHours        string     `json:"allowed_hours,omitempty"` // JSON array of weekly access windows
+	SyncedAt            time.Time  `json:"synced_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
+}
+
+// MembershipStatus constants
+const (
+	MembershipStatusActive    = "active"
+	MembershipStatusSuspended = "suspended"
+	MembershipStatusExpired   = "expired"
+	MembershipStatusCancelled = "cancelled"
+)
+
+// Door represents a physical door (entrance, staff door, turnstile) controlled by the bridge
+type Door struct {
+	ID               string                 `json:"id"`
+	Name             string                 `json:"name"`
+	Zone             string                 `json:"zone,omitempty"`
+	Adapters         []string               `json:"adapters"`        // Reader adapters whose events belong to this door
+	Relay            map[string]interface{} `json:"relay,omitempty"` // Relay settings, encrypted at rest
+	UnlockDurationMs int                    `json:"unlock_duration_ms"`
+	CreatedAt        time.Time              `json:"created_at"`
+	UpdatedAt        time.Time              `json:"updated_at"`
+}
+
+// DoorSchedule is a weekly plan of free-access windows during which doors stay unlocked
+type DoorSchedule struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	DoorIDs   []string  `json:"door_ids"` // Doors the schedule applies to, empty for every door
+	Windows   string    `json:"windows"`  // JSON encoded weekly windows, same format as allowed hours
+	Enabled   bool      `json:"enabled"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// DoorHoliday replaces the weekly schedules on a calendar date
+type DoorHoliday struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Date      string    `json:"date"`                 // YYYY-MM-DD in the site time zone
+	StartTime string    `json:"start_time,omitempty"` // Free-access hours on the day ("HH:MM"),
+	EndTime   string    `json:"end_time,omitempty"`   // both empty to keep doors locked all day
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// AntiPassbackState records whether a member is inside or outside a zone
+type AntiPassbackState struct {
+	InternalUserID string    `json:"internal_user_id"`
+	Zone           string    `json:"zone"` // Empty for doors without a zone
+	State          string    `json:"state"`
+	DoorID         string    `json:"door_id,omitempty"` // Door of the event that set the state
+	UpdatedAt      time.Time `json:"updated_at"`        // Time of the event that set the state
+}
+
+// AntiPassbackState constants
+const (
+	AntiPassbackIn  = "in"
+	AntiPassbackOut = "out"
+)
+
+// ProcessedCommand records the outcome of a remote command so that redelivered
+// commands are acknowledged again instead of being executed twice
+type ProcessedCommand struct {
+	CommandID   string    `json:"command_id"`
+	CommandType string    `json:"command_type"`
+	Status      string    `json:"status"`
+	Result      string    `json:"result,omitempty"` // JSON
+	Error       string    `json:"error,omitempty"`
+	ProcessedAt time.Time `json:"processed_at"`
+}
+
+// ConfigVersion status constants
+const (
+	ConfigVersionApplied    = "applied"
+	ConfigVersionInvalid    = "invalid"
+	ConfigVersionRolledBack = "rolled_back"
+	ConfigVersionFailed     = "failed"
+)
+
+// ConfigVersion is a configuration document published by the platform and the
+// outcome of applying it. The document is encrypted at rest, as adapter
+// settings can hold device credentials.
+type ConfigVersion struct {
+	Version    int64      `json:"version"`
+	Document   []byte     `json:"-"` // JSON document as received
+	Status     string     `json:"status"`
+	Error      string     `json:"error,omitempty"`
+	ReceivedAt time.Time  `json:"received_at"`
+	AppliedAt  *time.Time `json:"applied_at,omitempty"` // Last time the version was put into effect
+}
+
+// DoorLockdown records an active lockdown, which blocks every unlock until cleared
+type DoorLockdown struct {
+	Reason      string    `json:"reason,omitempty"`
+	ActivatedBy string    `json:"activated_by,omitempty"`
+	ActivatedAt time.Time `json:"activated_at"`
+}
+
+// BiometricTemplate is a fingerprint template backed up from a terminal. The
+// template is encrypted at rest and only decrypted to write it to a terminal.
+type BiometricTemplate struct {
+	DeviceUserID   int       `json:"device_user_id"`
+	FingerIndex    int       `json:"finger_index"`
+	Flag           int       `json:"flag"`
+	Data           []byte    `json:"-"`
+	SourceTerminal string    `json:"source_terminal"` // Terminal the template was read from
+	CapturedAt     time.Time `json:"captured_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// DeviceUserAssignment reserves a biometric terminal user ID for a platform member.
+// The same ID is used on every terminal so events resolve to the same member.
+type DeviceUserAssignment struct {
+	DeviceUserID   int       `json:"device_user_id"`
+	InternalUserID string    `json:"internal_user_id"`
+	CreatedAt      time.Time `json:"created_at"`
+}
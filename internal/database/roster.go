@@ -0,0 +1,178 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// ApplyRosterMember brings the external user mappings of a roster member in line
+// with the platform. Mappings created on the bridge are kept when they were
+// changed after the platform record, otherwise the platform wins. A tombstone
+// removes the member's platform mappings; local mappings are never deleted.
+func (db *DB) ApplyRosterMember(member RosterMember, syncedAt time.Time) (RosterApplyResult, error) {
+	var result RosterApplyResult
+	if member.InternalUserID == "" {
+		return result, fmt.Errorf("internal user ID cannot be empty")
+	}
+
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return result, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	keep := make(map[string]bool, len(member.Credentials))
+	if !member.Deleted {
+		for _, credential := range member.Credentials {
+			if credential.ExternalUserID == "" {
+				continue
+			}
+			keep[credential.ExternalUserID] = true
+
+			if err := applyRosterCredential(tx, member, credential, syncedAt, &result); err != nil {
+				return result, err
+			}
+		}
+	}
+
+	// Remove platform mappings for credentials the member no longer has
+	rows, err := tx.Query(`
+		SELECT external_user_id FROM external_user_mappings
+		WHERE internal_user_id = ? AND source = ?
+	`, member.InternalUserID, MappingSourcePlatform)
+	if err != nil {
+		return result, fmt.Errorf("failed to list roster mappings: %w", err)
+	}
+	var stale []string
+	for rows.Next() {
+		var externalUserID string
+		if err := rows.Scan(&externalUserID); err != nil {
+			rows.Close()
+			return result, fmt.Errorf("failed to scan roster mapping: %w", err)
+		}
+		if !keep[externalUserID] {
+			stale = append(stale, externalUserID)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return result, fmt.Errorf("error iterating roster mappings: %w", err)
+	}
+
+	for _, externalUserID := range stale {
+		if _, err := tx.Exec(`DELETE FROM external_user_mappings WHERE external_user_id = ?`, externalUserID); err != nil {
+			return result, fmt.Errorf("failed to delete roster mapping: %w", err)
+		}
+		result.Deleted++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return result, fmt.Errorf("failed to commit roster member: %w", err)
+	}
+
+	return result, nil
+}
+
+// applyRosterCredential creates or updates the mapping for one credential of a member
+func applyRosterCredential(tx *sql.Tx, member RosterMember, credential RosterCredential, syncedAt time.Time, result *RosterApplyResult) error {
+	var internalUserID, source string
+	var updatedAt time.Time
+	err := tx.QueryRow(`
+		SELECT internal_user_id, source, updated_at FROM external_user_mappings
+		WHERE external_user_id = ?
+	`, credential.ExternalUserID).Scan(&internalUserID, &source, &updatedAt)
+
+	switch {
+	case err == sql.ErrNoRows:
+		if _, err := tx.Exec(`
+			INSERT INTO external_user_mappings
+				(external_user_id, internal_user_id, user_name, notes, source, credential_type, member_status, synced_at, created_at, updated_at)
+			VALUES (?, ?, ?, '', ?, ?, ?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+		`, credential.ExternalUserID, member.InternalUserID, member.UserName, MappingSourcePlatform,
+			credential.Type, member.Status, syncedAt.UTC()); err != nil {
+			return fmt.Errorf("failed to create roster mapping: %w", err)
+		}
+		result.Created++
+		return nil
+	case err != nil:
+		return fmt.Errorf("failed to get roster mapping: %w", err)
+	}
+
+	// A local mapping pointing at another member wins when it is newer
+	if source == MappingSourceLocal && internalUserID != member.InternalUserID && updatedAt.After(member.UpdatedAt) {
+		result.Conflicts++
+		return nil
+	}
+
+	if _, err := tx.Exec(`
+		UPDATE external_user_mappings
+		SET internal_user_id = ?, user_name = ?, source = ?, credential_type = ?, member_status = ?,
+			synced_at = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE external_user_id = ?
+	`, member.InternalUserID, member.UserName, MappingSourcePlatform, credential.Type, member.Status,
+		syncedAt.UTC(), credential.ExternalUserID); err != nil {
+		return fmt.Errorf("failed to update roster mapping: %w", err)
+	}
+	result.Updated++
+	return nil
+}
+
+// PurgeStaleRosterMappings removes platform mappings not seen by a roster sync
+// since the given time, after a full roster pull has completed
+func (db *DB) PurgeStaleRosterMappings(syncedBefore time.Time) (int64, error) {
+	result, err := db.conn.Exec(`
+		DELETE FROM external_user_mappings
+		WHERE source = ? AND (synced_at IS NULL OR synced_at < ?)
+	`, MappingSourcePlatform, syncedBefore.UTC())
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge stale roster mappings: %w", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	return deleted, nil
+}
+
+// ListRosterMembers returns the members known from the platform roster with
+// their credentials, built from the platform mappings
+func (db *DB) ListRosterMembers() ([]RosterMember, error) {
+	rows, err := db.conn.Query(`
+		SELECT internal_user_id, user_name, member_status, external_user_id, credential_type
+		FROM external_user_mappings
+		WHERE source = ?
+		ORDER BY internal_user_id, id
+	`, MappingSourcePlatform)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list roster members: %w", err)
+	}
+	defer rows.Close()
+
+	var members []RosterMember
+	for rows.Next() {
+		var internalUserID, userName, status string
+		var credential RosterCredential
+		if err := rows.Scan(&internalUserID, &userName, &status, &credential.ExternalUserID, &credential.Type); err != nil {
+			return nil, fmt.Errorf("failed to scan roster member: %w", err)
+		}
+
+		if len(members) == 0 || members[len(members)-1].InternalUserID != internalUserID {
+			members = append(members, RosterMember{
+				InternalUserID: internalUserID,
+				UserName:       userName,
+				Status:         status,
+			})
+		}
+		member := &members[len(members)-1]
+		member.Credentials = append(member.Credentials, credential)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating roster members: %w", err)
+	}
+
+	return members, nil
+}
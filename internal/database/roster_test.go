@@ -0,0 +1,152 @@
+package database
+
+import (
+	"testing"
+	"time"
+)
+
+func TestApplyRosterMember(t *testing.T) {
+	db := setupTestDB(t, TierNormal)
+	updatedAt := time.Now().Add(-time.Hour)
+	firstSync := time.Now()
+
+	result, err := db.ApplyRosterMember(RosterMember{
+		InternalUserID: "user_1",
+		UserName:       "Alex",
+		Status:         "active",
+		Credentials: []RosterCredential{
+			{ExternalUserID: "card_100", Type: CredentialTypeCard},
+			{ExternalUserID: "fp_7", Type: CredentialTypeFingerprint},
+		},
+		UpdatedAt: updatedAt,
+	}, firstSync)
+	if err != nil {
+		t.Fatalf("failed to apply roster member: %v", err)
+	}
+	if result.Created != 2 {
+		t.Errorf("expected 2 mappings created, got %+v", result)
+	}
+
+	mapping, err := db.GetExternalUserMapping("card_100")
+	if err != nil || mapping == nil {
+		t.Fatalf("expected card mapping, got %+v (%v)", mapping, err)
+	}
+	if mapping.InternalUserID != "user_1" || mapping.Source != MappingSourcePlatform || mapping.CredentialType != CredentialTypeCard || mapping.MemberStatus != "active" {
+		t.Errorf("unexpected mapping: %+v", mapping)
+	}
+
+	// The fingerprint is removed from the member and the card is suspended
+	result, err = db.ApplyRosterMember(RosterMember{
+		InternalUserID: "user_1",
+		Status:         "suspended",
+		Credentials:    []RosterCredential{{ExternalUserID: "card_100", Type: CredentialTypeCard}},
+		UpdatedAt:      updatedAt.Add(time.Minute),
+	}, firstSync)
+	if err != nil {
+		t.Fatalf("failed to apply roster update: %v", err)
+	}
+	if result.Updated != 1 || result.Deleted != 1 {
+		t.Errorf("expected 1 update and 1 deletion, got %+v", result)
+	}
+	if mapping, _ := db.GetExternalUserMapping("fp_7"); mapping != nil {
+		t.Errorf("expected fingerprint mapping to be removed, got %+v", mapping)
+	}
+	if mapping, _ := db.GetExternalUserMapping("card_100"); mapping == nil || mapping.MemberStatus != "suspended" {
+		t.Errorf("expected suspended card mapping, got %+v", mapping)
+	}
+
+	// Tombstones remove the member's platform mappings
+	result, err = db.ApplyRosterMember(RosterMember{InternalUserID: "user_1", Deleted: true}, firstSync)
+	if err != nil || result.Deleted != 1 {
+		t.Fatalf("expected tombstone to delete 1 mapping, got %+v (%v)", result, err)
+	}
+	if count, _ := db.CountExternalUserMappings(); count != 0 {
+		t.Errorf("expected no mappings, got %d", count)
+	}
+}
+
+func TestApplyRosterMember_LocalConflicts(t *testing.T) {
+	db := setupTestDB(t, TierNormal)
+	now := time.Now()
+
+	if _, err := db.CreateExternalUserMapping("card_200", "user_local", "Front desk", "assigned on site"); err != nil {
+		t.Fatalf("failed to create local mapping: %v", err)
+	}
+	if _, err := db.CreateExternalUserMapping("card_300", "user_old", "", ""); err != nil {
+		t.Fatalf("failed to create local mapping: %v", err)
+	}
+	if _, err := db.CreateExternalUserMapping("card_400", "user_2", "", ""); err != nil {
+		t.Fatalf("failed to create local mapping: %v", err)
+	}
+
+	// card_200 was assigned locally after the platform last changed the member,
+	// card_300 before; card_400 agrees with the platform and is adopted
+	result, err := db.ApplyRosterMember(RosterMember{
+		InternalUserID: "user_2",
+		Status:         "active",
+		Credentials: []RosterCredential{
+			{ExternalUserID: "card_200", Type: CredentialTypeCard},
+			{ExternalUserID: "card_400", Type: CredentialTypeCard},
+		},
+		UpdatedAt: now.Add(-24 * time.Hour),
+	}, now)
+	if err != nil {
+		t.Fatalf("failed to apply roster member: %v", err)
+	}
+	if result.Conflicts != 1 || result.Updated != 1 {
+		t.Errorf("expected 1 conflict and 1 update, got %+v", result)
+	}
+	if mapping, _ := db.GetExternalUserMapping("card_200"); mapping.InternalUserID != "user_local" || mapping.Source != MappingSourceLocal {
+		t.Errorf("expected newer local mapping to be kept, got %+v", mapping)
+	}
+	if mapping, _ := db.GetExternalUserMapping("card_400"); mapping.Source != MappingSourcePlatform {
+		t.Errorf("expected matching local mapping to be adopted, got %+v", mapping)
+	}
+
+	result, err = db.ApplyRosterMember(RosterMember{
+		InternalUserID: "user_3",
+		Credentials:    []RosterCredential{{ExternalUserID: "card_300", Type: CredentialTypeCard}},
+		UpdatedAt:      now.Add(time.Hour),
+	}, now)
+	if err != nil || result.Updated != 1 {
+		t.Fatalf("expected older local mapping to be replaced, got %+v (%v)", result, err)
+	}
+	if mapping, _ := db.GetExternalUserMapping("card_300"); mapping.InternalUserID != "user_3" {
+		t.Errorf("expected platform mapping, got %+v", mapping)
+	}
+
+	// Tombstones and purges leave local mappings alone
+	if _, err := db.ApplyRosterMember(RosterMember{InternalUserID: "user_local", Deleted: true}, now); err != nil {
+		t.Fatalf("failed to apply tombstone: %v", err)
+	}
+	purged, err := db.PurgeStaleRosterMappings(now.Add(time.Minute))
+	if err != nil {
+		t.Fatalf("failed to purge stale mappings: %v", err)
+	}
+	if purged != 2 {
+		t.Errorf("expected the 2 platform mappings to be purged, got %d", purged)
+	}
+	if mapping, _ := db.GetExternalUserMapping("card_200"); mapping == nil {
+		t.Error("expected local mapping to survive")
+	}
+}
+
+func TestUpdateExternalUserMapping_BecomesLocal(t *testing.T) {
+	db := setupTestDB(t, TierNormal)
+
+	if _, err := db.ApplyRosterMember(RosterMember{
+		InternalUserID: "user_1",
+		Credentials:    []RosterCredential{{ExternalUserID: "card_100", Type: CredentialTypeCard}},
+		UpdatedAt:      time.Now(),
+	}, time.Now()); err != nil {
+		t.Fatalf("failed to apply roster member: %v", err)
+	}
+
+	mapping, err := db.UpdateExternalUserMapping("card_100", "user_2", "", "reassigned")
+	if err != nil {
+		t.Fatalf("failed to update mapping: %v", err)
+	}
+	if mapping.Source != MappingSourceLocal {
+		t.Errorf("expected edited mapping to become local, got %+v", mapping)
+	}
+}
@@ -429,7 +429,9 @@ func (d *DeviceDiscovery) GenerateAdapterConfig() map[string]interface{} {
 
 		adapterName := fmt.Sprintf("%s_%s_%d", device.Type, strings.ReplaceAll(device.IP, ".", "_"), device.Port)
 		
+		// Each terminal runs as its own instance of the biometric adapter
 		adapters[adapterName] = map[string]interface{}{
+			"type":          "biometric",
 			"device_type":   device.Type,
 			"connection":    "tcp",
 			"device_config": device.Config,
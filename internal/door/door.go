@@ -3,6 +3,7 @@ package door
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -13,6 +14,7 @@ import (
 
 	"gym-door-bridge/internal/adapters"
 	"gym-door-bridge/internal/config"
+	"gym-door-bridge/internal/database"
 )
 
 // DoorControlConfig holds configuration for the door control system
@@ -39,6 +41,7 @@ func DefaultDoorControlConfig() DoorControlConfig {
 type UnlockRequest struct {
 	DurationMs int    `json:"durationMs,omitempty"` // Duration in milliseconds (optional)
 	Adapter    string `json:"adapter,omitempty"`    // Specific adapter to use (optional)
+	DoorID     string `json:"doorId,omitempty"`     // Specific door to unlock (optional)
 }
 
 // UnlockResponse represents a door unlock response
@@ -47,6 +50,7 @@ type UnlockResponse struct {
 	Message   string `json:"message"`
 	Duration  int    `json:"duration"`  // Actual duration used
 	Adapter   string `json:"adapter"`   // Adapter that performed the unlock
+	DoorID    string `json:"doorId,omitempty"` // Door that was unlocked
 	Timestamp string `json:"timestamp"` // When the unlock was performed
 }
 
@@ -64,6 +68,69 @@ type AdapterRegistry interface {
 	GetActiveAdapters() []adapters.HardwareAdapter
 }
 
+// DoorRelay drives the door lock output directly, without going through an adapter
+type DoorRelay interface {
+	Pulse(ctx context.Context, durationMs int) error
+	Unlock(ctx context.Context) error // Holds the door unlocked until Lock
+	Lock(ctx context.Context) error
+	Status() map[string]interface{}
+}
+
+// Door describes a physical door: the reader adapters mounted at it and the
+// output that releases its lock
+type Door struct {
+	ID               string
+	Name             string
+	Zone             string
+	Adapters         []string  // Reader adapters whose events belong to this door
+	Relay            DoorRelay // Lock output; when nil the door's adapters unlock it
+	UnlockDurationMs int       // Default unlock duration; 0 uses the controller default
+
+	// Door position monitoring
+	Contact         DoorInput     // Door contact, active while the door is open; nil when not fitted
+	Rex             DoorInput     // Request-to-exit button; nil when not fitted
+	RexUnlocks      bool          // Pressing the exit button also unlocks the door
+	HeldOpenTimeout time.Duration // Raise a held-open alarm after the door is open this long; 0 disables
+}
+
+// DoorStatus reports the state and statistics of a single door
+type DoorStatus struct {
+	ID             string                 `json:"id"`
+	Name           string                 `json:"name"`
+	Zone           string                 `json:"zone,omitempty"`
+	Adapters       []string               `json:"adapters"`
+	Unlocked       bool                   `json:"unlocked"`
+	State          string                 `json:"state"` // Door position, see DoorState constants
+	FreeAccess     bool                   `json:"freeAccess"` // Held unlocked by a schedule
+	UnlockCount    int64                  `json:"unlockCount"`
+	FailureCount   int64                  `json:"failureCount"`
+	LastUnlockTime time.Time              `json:"lastUnlockTime"`
+	Relay          map[string]interface{} `json:"relay,omitempty"`
+}
+
+// doorState tracks a configured door and its statistics
+type doorState struct {
+	Door
+	unlockCount    int64
+	failureCount   int64
+	lastUnlockTime time.Time
+	unlockedUntil  time.Time // Estimated relock time for doors unlocked through an adapter
+
+	// Position monitoring
+	state           string
+	openedAt        time.Time
+	authorizedUntil time.Time // The door may be opened without an alarm until then
+	rexActive       bool
+	rexFailed       bool
+
+	// Schedules
+	freeAccess     bool // Held unlocked by a free-access schedule
+	scheduleWarned bool // Logged that the door cannot follow schedules
+}
+
+// relayAdapterName is reported as the adapter when the door relay performs an unlock
+const relayAdapterName = "relay"
+
 // DoorController manages door control operations and HTTP endpoints
 type DoorController struct {
 	mu              sync.RWMutex
@@ -71,7 +138,22 @@ type DoorController struct {
 	globalConfig    *config.Config
 	logger          *logrus.Logger
 	adapterRegistry AdapterRegistry
+	relay           DoorRelay
+	doors           []*doorState
+	doorsByID       map[string]*doorState
+	doorsByAdapter  map[string]*doorState
 	httpServer      *http.Server
+	alarmHandler    AlarmHandler
+	monitorInterval time.Duration
+	stopMonitor     context.CancelFunc
+	monitorDone     chan struct{}
+	scheduleStore   ScheduleStore
+	location        *time.Location
+	schedules       []doorSchedule
+	holidays        map[string]database.DoorHoliday // By date
+	lockdown        *database.DoorLockdown
+	stopScheduler   context.CancelFunc
+	schedulerDone   chan struct{}
 	
 	// Statistics
 	unlockCount     int64
@@ -89,6 +171,31 @@ func WithLogger(logger *logrus.Logger) DoorControllerOption {
 	}
 }
 
+// WithRelay sets the door relay used when an unlock does not name an adapter
+func WithRelay(relay DoorRelay) DoorControllerOption {
+	return func(d *DoorController) {
+		d.relay = relay
+	}
+}
+
+// WithDoors sets the doors managed by the controller. The first door is the
+// default used when an unlock names neither a door nor an adapter.
+func WithDoors(doors []Door) DoorControllerOption {
+	return func(d *DoorController) {
+		d.doors = nil
+		d.doorsByID = make(map[string]*doorState, len(doors))
+		d.doorsByAdapter = make(map[string]*doorState)
+		for _, door := range doors {
+			state := &doorState{Door: door, state: DoorStateUnknown}
+			d.doors = append(d.doors, state)
+			d.doorsByID[door.ID] = state
+			for _, adapterName := range door.Adapters {
+				d.doorsByAdapter[adapterName] = state
+			}
+		}
+	}
+}
+
 // NewDoorController creates a new door controller
 func NewDoorController(
 	config DoorControlConfig,
@@ -133,6 +240,12 @@ func (d *DoorController) Start(ctx context.Context) error {
 	
 	d.logger.Info("Door control endpoint started", "url", fmt.Sprintf("http://localhost:%d%s", d.config.Port, d.config.Path))
 	
+	// Watch door contacts and exit buttons
+	d.startMonitor()
+	
+	// Follow free-access schedules
+	d.startScheduler(ctx)
+	
 	return nil
 }
 
@@ -140,6 +253,9 @@ func (d *DoorController) Start(ctx context.Context) error {
 func (d *DoorController) Stop(ctx context.Context) error {
 	d.logger.Info("Stopping door controller")
 	
+	d.stopMonitorLoop()
+	d.stopSchedulerLoop()
+	
 	// Stop HTTP server
 	if d.httpServer != nil {
 		if err := d.httpServer.Shutdown(ctx); err != nil {
@@ -151,11 +267,16 @@ func (d *DoorController) Stop(ctx context.Context) error {
 	return nil
 }
 
-// UnlockDoor unlocks the door using the specified adapter or the first available adapter
+// UnlockDoor unlocks the door using the specified adapter, the door relay if one is
+// configured, or the first available adapter
 func (d *DoorController) UnlockDoor(ctx context.Context, adapterName string, durationMs int) error {
 	d.mu.Lock()
 	defer d.mu.Unlock()
 	
+	if d.lockdown != nil {
+		return ErrLockdown
+	}
+	
 	// Validate duration
 	if durationMs <= 0 {
 		durationMs = d.config.DefaultUnlockDuration
@@ -165,6 +286,16 @@ func (d *DoorController) UnlockDoor(ctx context.Context, adapterName string, dur
 		return fmt.Errorf("unlock duration %d exceeds maximum allowed %d", durationMs, d.config.MaxUnlockDuration)
 	}
 	
+	// Drive the door relay directly unless a specific adapter was requested
+	if adapterName == "" && d.relay != nil {
+		return d.unlockWithRelay(ctx, durationMs)
+	}
+	
+	// Otherwise unlock the default door when doors are configured
+	if adapterName == "" && len(d.doors) > 0 {
+		return d.unlockDoor(ctx, d.doors[0], durationMs)
+	}
+	
 	// Get the adapter to use
 	var adapter adapters.HardwareAdapter
 	var err error
@@ -217,16 +348,266 @@ func (d *DoorController) UnlockDoor(ctx context.Context, adapterName string, dur
 	return nil
 }
 
+// unlockWithRelay pulses the door relay; the caller holds the mutex
+func (d *DoorController) unlockWithRelay(ctx context.Context, durationMs int) error {
+	d.logger.Info("Unlocking door", "adapter", relayAdapterName, "durationMs", durationMs)
+	
+	if err := d.relay.Pulse(ctx, durationMs); err != nil {
+		d.failureCount++
+		d.logger.WithError(err).Error("Failed to unlock door", "adapter", relayAdapterName, "durationMs", durationMs)
+		return fmt.Errorf("failed to unlock door with relay: %w", err)
+	}
+	
+	d.unlockCount++
+	d.lastUnlockTime = time.Now()
+	
+	d.logger.Info("Door unlocked successfully",
+		"adapter", relayAdapterName,
+		"durationMs", durationMs,
+		"totalUnlocks", d.unlockCount)
+	
+	return nil
+}
+
+// UnlockDoorByID unlocks a configured door through its relay or one of its adapters.
+// A duration of 0 uses the door's configured unlock duration.
+func (d *DoorController) UnlockDoorByID(ctx context.Context, doorID string, durationMs int) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	
+	door, ok := d.doorsByID[doorID]
+	if !ok {
+		d.failureCount++
+		return fmt.Errorf("door %s not found", doorID)
+	}
+	if d.lockdown != nil {
+		return ErrLockdown
+	}
+	
+	if durationMs <= 0 {
+		durationMs = door.UnlockDurationMs
+	}
+	if durationMs <= 0 {
+		durationMs = d.config.DefaultUnlockDuration
+	}
+	if durationMs > d.config.MaxUnlockDuration {
+		d.failureCount++
+		door.failureCount++
+		return fmt.Errorf("unlock duration %d exceeds maximum allowed %d", durationMs, d.config.MaxUnlockDuration)
+	}
+	
+	return d.unlockDoor(ctx, door, durationMs)
+}
+
+// LockDoorByID relocks a door immediately. Only doors with a relay output can be
+// locked early; doors unlocked through an adapter relock when the reader's pulse ends.
+func (d *DoorController) LockDoorByID(ctx context.Context, doorID string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	
+	door, ok := d.doorsByID[doorID]
+	if !ok {
+		return fmt.Errorf("door %s not found", doorID)
+	}
+	if door.Relay == nil {
+		return fmt.Errorf("door %s has no relay output to lock", doorID)
+	}
+	
+	d.logger.Info("Locking door", "doorId", doorID)
+	
+	if err := door.Relay.Lock(ctx); err != nil {
+		door.failureCount++
+		d.failureCount++
+		return fmt.Errorf("failed to lock door %s: %w", doorID, err)
+	}
+	return nil
+}
+
+// unlockDoor unlocks a door through its relay, or through the first healthy adapter
+// bound to it; the caller holds the mutex
+func (d *DoorController) unlockDoor(ctx context.Context, door *doorState, durationMs int) error {
+	adapterName := relayAdapterName
+	var err error
+	
+	if door.Relay != nil {
+		err = door.Relay.Pulse(ctx, durationMs)
+	} else {
+		adapterName, err = d.unlockWithDoorAdapter(ctx, door, durationMs)
+	}
+	
+	if err != nil {
+		d.failureCount++
+		door.failureCount++
+		d.logger.WithError(err).Error("Failed to unlock door",
+			"doorId", door.ID,
+			"adapter", adapterName,
+			"durationMs", durationMs)
+		return fmt.Errorf("failed to unlock door %s: %w", door.ID, err)
+	}
+	
+	now := time.Now()
+	d.unlockCount++
+	d.lastUnlockTime = now
+	door.unlockCount++
+	door.lastUnlockTime = now
+	door.unlockedUntil = now.Add(time.Duration(durationMs) * time.Millisecond)
+	door.authorizedUntil = door.unlockedUntil
+	
+	d.logger.Info("Door unlocked successfully",
+		"doorId", door.ID,
+		"adapter", adapterName,
+		"durationMs", durationMs,
+		"totalUnlocks", d.unlockCount)
+	
+	return nil
+}
+
+// unlockWithDoorAdapter unlocks a door with the first healthy adapter bound to it
+func (d *DoorController) unlockWithDoorAdapter(ctx context.Context, door *doorState, durationMs int) (string, error) {
+	for _, name := range door.Adapters {
+		adapter, err := d.adapterRegistry.GetAdapter(name)
+		if err != nil || adapter == nil || !adapter.IsHealthy() {
+			continue
+		}
+		return name, adapter.UnlockDoor(ctx, durationMs)
+	}
+	return "", fmt.Errorf("door has no relay and no healthy adapter")
+}
+
+// UpdateDoors changes the name, zone, reader adapters, unlock duration and exit
+// button and held-open settings of configured doors while they keep running.
+// The relay and inputs of a door are hardware and stay as they are, so Relay,
+// Contact and Rex of the given doors are ignored.
+func (d *DoorController) UpdateDoors(doors []Door) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	
+	for _, door := range doors {
+		if _, ok := d.doorsByID[door.ID]; !ok {
+			return fmt.Errorf("door %s is not configured", door.ID)
+		}
+	}
+	
+	for _, door := range doors {
+		state := d.doorsByID[door.ID]
+		state.Name = door.Name
+		state.Zone = door.Zone
+		state.Adapters = door.Adapters
+		state.UnlockDurationMs = door.UnlockDurationMs
+		state.RexUnlocks = door.RexUnlocks && state.Rex != nil
+		state.HeldOpenTimeout = door.HeldOpenTimeout
+	}
+	
+	d.doorsByAdapter = make(map[string]*doorState)
+	for _, state := range d.doors {
+		for _, adapterName := range state.Adapters {
+			d.doorsByAdapter[adapterName] = state
+		}
+	}
+	
+	d.logger.WithField("doors", len(doors)).Info("Door settings updated")
+	return nil
+}
+
+// DoorForAdapter returns the ID of the door an adapter is mounted at, or an
+// empty string when the adapter is not bound to a door
+func (d *DoorController) DoorForAdapter(adapterName string) string {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	
+	if door, ok := d.doorsByAdapter[adapterName]; ok {
+		return door.ID
+	}
+	return ""
+}
+
+// ZoneForDoor returns the zone a door leads into, or an empty string when the
+// door has no zone or does not exist
+func (d *DoorController) ZoneForDoor(doorID string) string {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	
+	if door, ok := d.doorsByID[doorID]; ok {
+		return door.Zone
+	}
+	return ""
+}
+
+// ListDoors returns the status of all configured doors in configuration order
+func (d *DoorController) ListDoors() []DoorStatus {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	
+	statuses := make([]DoorStatus, 0, len(d.doors))
+	for _, door := range d.doors {
+		statuses = append(statuses, door.status())
+	}
+	return statuses
+}
+
+// GetDoorStatus returns the status of a door
+// Returns nil if the door does not exist
+func (d *DoorController) GetDoorStatus(doorID string) *DoorStatus {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	
+	door, ok := d.doorsByID[doorID]
+	if !ok {
+		return nil
+	}
+	status := door.status()
+	return &status
+}
+
+// status builds the door's status; the caller holds the controller mutex
+func (s *doorState) status() DoorStatus {
+	status := DoorStatus{
+		ID:             s.ID,
+		Name:           s.Name,
+		Zone:           s.Zone,
+		Adapters:       append([]string{}, s.Adapters...),
+		UnlockCount:    s.unlockCount,
+		FailureCount:   s.failureCount,
+		LastUnlockTime: s.lastUnlockTime,
+		State:          s.state,
+		FreeAccess:     s.freeAccess,
+	}
+	if s.Contact == nil || status.State == "" {
+		status.State = DoorStateUnknown
+	}
+	if s.Relay != nil {
+		status.Relay = s.Relay.Status()
+		status.Unlocked, _ = status.Relay["unlocked"].(bool)
+	} else {
+		status.Unlocked = time.Now().Before(s.unlockedUntil)
+	}
+	return status
+}
+
 // GetStats returns door control statistics
 func (d *DoorController) GetStats() map[string]interface{} {
 	d.mu.RLock()
 	defer d.mu.RUnlock()
 	
-	return map[string]interface{}{
+	stats := map[string]interface{}{
 		"unlockCount":    d.unlockCount,
 		"failureCount":   d.failureCount,
 		"lastUnlockTime": d.lastUnlockTime,
 	}
+	if d.relay != nil {
+		stats["relay"] = d.relay.Status()
+	}
+	if d.lockdown != nil {
+		stats["lockdown"] = *d.lockdown
+	}
+	if len(d.doors) > 0 {
+		doors := make(map[string]interface{}, len(d.doors))
+		for _, door := range d.doors {
+			doors[door.ID] = door.status()
+		}
+		stats["doors"] = doors
+	}
+	return stats
 }
 
 // handleDoorUnlock handles HTTP door unlock requests
@@ -258,6 +639,14 @@ func (d *DoorController) handleDoorUnlock(w http.ResponseWriter, r *http.Request
 		if adapter := r.URL.Query().Get("adapter"); adapter != "" {
 			req.Adapter = adapter
 		}
+		if doorID := r.URL.Query().Get("doorId"); doorID != "" {
+			req.DoorID = doorID
+		}
+	}
+	
+	if req.DoorID != "" {
+		d.handleDoorUnlockByID(w, r, req)
+		return
 	}
 	
 	// Use default duration if not specified
@@ -272,13 +661,24 @@ func (d *DoorController) handleDoorUnlock(w http.ResponseWriter, r *http.Request
 	err := d.UnlockDoor(ctx, req.Adapter, req.DurationMs)
 	if err != nil {
 		d.logger.WithError(err).Error("Door unlock request failed")
+		if errors.Is(err, ErrLockdown) {
+			d.writeErrorResponse(w, http.StatusLocked, "DOOR_LOCKDOWN", err.Error())
+			return
+		}
 		d.writeErrorResponse(w, http.StatusInternalServerError, "UNLOCK_FAILED", err.Error())
 		return
 	}
 	
 	// Determine which adapter was used
 	adapterName := req.Adapter
-	if adapterName == "" {
+	if adapterName == "" && d.relay != nil {
+		adapterName = relayAdapterName
+	} else if adapterName == "" && len(d.doors) > 0 {
+		adapterName = relayAdapterName
+		if d.doors[0].Relay == nil && len(d.doors[0].Adapters) > 0 {
+			adapterName = d.doors[0].Adapters[0]
+		}
+	} else if adapterName == "" {
 		if activeAdapters := d.adapterRegistry.GetActiveAdapters(); len(activeAdapters) > 0 {
 			adapterName = activeAdapters[0].Name()
 		}
@@ -306,6 +706,58 @@ func (d *DoorController) handleDoorUnlock(w http.ResponseWriter, r *http.Request
 		"remoteAddr", r.RemoteAddr)
 }
 
+// handleDoorUnlockByID completes an unlock request that names a door
+func (d *DoorController) handleDoorUnlockByID(w http.ResponseWriter, r *http.Request, req UnlockRequest) {
+	if d.GetDoorStatus(req.DoorID) == nil {
+		d.writeErrorResponse(w, http.StatusNotFound, "DOOR_NOT_FOUND", fmt.Sprintf("Door %s not found", req.DoorID))
+		return
+	}
+	
+	if err := d.UnlockDoorByID(r.Context(), req.DoorID, req.DurationMs); err != nil {
+		d.logger.WithError(err).Error("Door unlock request failed")
+		if errors.Is(err, ErrLockdown) {
+			d.writeErrorResponse(w, http.StatusLocked, "DOOR_LOCKDOWN", err.Error())
+			return
+		}
+		d.writeErrorResponse(w, http.StatusInternalServerError, "UNLOCK_FAILED", err.Error())
+		return
+	}
+	
+	response := UnlockResponse{
+		Success:   true,
+		Message:   "Door unlocked successfully",
+		Duration:  req.DurationMs,
+		Adapter:   relayAdapterName,
+		DoorID:    req.DoorID,
+		Timestamp: time.Now().Format(time.RFC3339),
+	}
+	
+	d.mu.RLock()
+	door := d.doorsByID[req.DoorID]
+	if door.Relay == nil && len(door.Adapters) > 0 {
+		response.Adapter = door.Adapters[0]
+	}
+	if response.Duration <= 0 {
+		response.Duration = door.UnlockDurationMs
+		if response.Duration <= 0 {
+			response.Duration = d.config.DefaultUnlockDuration
+		}
+	}
+	d.mu.RUnlock()
+	
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		d.logger.WithError(err).Error("Failed to encode unlock response")
+	}
+	
+	d.logger.Info("Door unlock request completed successfully",
+		"doorId", req.DoorID,
+		"duration", response.Duration,
+		"remoteAddr", r.RemoteAddr)
+}
+
 // writeErrorResponse writes an error response to the HTTP response writer
 func (d *DoorController) writeErrorResponse(w http.ResponseWriter, statusCode int, errorCode, message string) {
 	response := ErrorResponse{
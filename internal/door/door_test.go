@@ -1,6 +1,8 @@
 package door
 
 import (
+	"context"
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -40,3 +42,135 @@ type mockRegistry struct{}
 func (m *mockRegistry) GetAllAdapters() []adapters.HardwareAdapter { return nil }
 func (m *mockRegistry) GetAdapter(name string) (adapters.HardwareAdapter, error) { return nil, nil }
 func (m *mockRegistry) GetActiveAdapters() []adapters.HardwareAdapter { return nil }
+
+// fakeRelay records pulses sent to the door relay
+type fakeRelay struct {
+	pulses []int
+	locks  int
+	held   bool
+	err    error
+}
+
+func (f *fakeRelay) Pulse(ctx context.Context, durationMs int) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.pulses = append(f.pulses, durationMs)
+	return nil
+}
+
+func (f *fakeRelay) Unlock(ctx context.Context) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.held = true
+	return nil
+}
+
+func (f *fakeRelay) Lock(ctx context.Context) error {
+	f.locks++
+	if f.err != nil {
+		return f.err
+	}
+	f.held = false
+	return nil
+}
+
+func (f *fakeRelay) Status() map[string]interface{} {
+	return map[string]interface{}{"unlocked": f.held || len(f.pulses) > f.locks}
+}
+
+func TestDoorController_UnlockDoorRelay(t *testing.T) {
+	relay := &fakeRelay{}
+	controller := NewDoorController(DefaultDoorControlConfig(), &config.Config{}, &mockRegistry{}, WithRelay(relay))
+
+	// No adapters are active, so the unlock must go through the relay
+	err := controller.UnlockDoor(context.Background(), "", 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{3000}, relay.pulses)
+
+	err = controller.UnlockDoor(context.Background(), "", 60000)
+	assert.Error(t, err)
+	assert.Len(t, relay.pulses, 1)
+
+	relay.err = errors.New("relay offline")
+	err = controller.UnlockDoor(context.Background(), "", 1000)
+	assert.Error(t, err)
+
+	stats := controller.GetStats()
+	assert.Equal(t, int64(1), stats["unlockCount"])
+	assert.Equal(t, int64(2), stats["failureCount"])
+	assert.NotNil(t, stats["relay"])
+}
+
+func TestDoorController_Doors(t *testing.T) {
+	front := &fakeRelay{}
+	back := &fakeRelay{}
+	controller := NewDoorController(DefaultDoorControlConfig(), &config.Config{}, &mockRegistry{}, WithDoors([]Door{
+		{ID: "front", Name: "Front Door", Zone: "lobby", Adapters: []string{"zkteco"}, Relay: front, UnlockDurationMs: 5000},
+		{ID: "back", Name: "Back Door", Adapters: []string{"rfid"}, Relay: back},
+	}))
+
+	assert.Equal(t, "front", controller.DoorForAdapter("zkteco"))
+	assert.Equal(t, "back", controller.DoorForAdapter("rfid"))
+	assert.Equal(t, "", controller.DoorForAdapter("simulator"))
+
+	// Per-door duration, then the controller default
+	assert.NoError(t, controller.UnlockDoorByID(context.Background(), "front", 0))
+	assert.NoError(t, controller.UnlockDoorByID(context.Background(), "back", 0))
+	assert.Equal(t, []int{5000}, front.pulses)
+	assert.Equal(t, []int{3000}, back.pulses)
+
+	// The first door is the default for unnamed unlocks
+	assert.NoError(t, controller.UnlockDoor(context.Background(), "", 1000))
+	assert.Equal(t, []int{5000, 1000}, front.pulses)
+
+	assert.Error(t, controller.UnlockDoorByID(context.Background(), "side", 0))
+	assert.Error(t, controller.UnlockDoorByID(context.Background(), "back", 60000))
+
+	status := controller.GetDoorStatus("front")
+	if assert.NotNil(t, status) {
+		assert.Equal(t, "lobby", status.Zone)
+		assert.True(t, status.Unlocked)
+		assert.Equal(t, int64(2), status.UnlockCount)
+	}
+	assert.Nil(t, controller.GetDoorStatus("side"))
+
+	assert.NoError(t, controller.LockDoorByID(context.Background(), "back"))
+	assert.Equal(t, 1, back.locks)
+
+	doors := controller.ListDoors()
+	if assert.Len(t, doors, 2) {
+		assert.Equal(t, "front", doors[0].ID)
+		assert.Equal(t, int64(1), doors[1].FailureCount)
+	}
+}
+
+func TestDoorController_UpdateDoors(t *testing.T) {
+	front := &fakeRelay{}
+	controller := NewDoorController(DefaultDoorControlConfig(), &config.Config{}, &mockRegistry{}, WithDoors([]Door{
+		{ID: "front", Name: "Front Door", Adapters: []string{"zkteco"}, Relay: front},
+		{ID: "back", Name: "Back Door", Adapters: []string{"rfid"}, Relay: &fakeRelay{}},
+	}))
+	assert.NoError(t, controller.UnlockDoorByID(context.Background(), "front", 0))
+
+	// Readers move between doors and settings change without touching the relay
+	assert.NoError(t, controller.UpdateDoors([]Door{
+		{ID: "front", Name: "Main Entrance", Zone: "lobby", Adapters: []string{"zkteco", "rfid"}, UnlockDurationMs: 4000, RexUnlocks: true},
+		{ID: "back", Name: "Back Door"},
+	}))
+	assert.Equal(t, "front", controller.DoorForAdapter("rfid"))
+	assert.Equal(t, "lobby", controller.ZoneForDoor("front"))
+
+	assert.NoError(t, controller.UnlockDoorByID(context.Background(), "front", 0))
+	assert.Equal(t, []int{3000, 4000}, front.pulses)
+
+	status := controller.GetDoorStatus("front")
+	if assert.NotNil(t, status) {
+		assert.Equal(t, "Main Entrance", status.Name)
+		assert.Equal(t, int64(2), status.UnlockCount)
+	}
+
+	// Doors cannot be added this way
+	assert.Error(t, controller.UpdateDoors([]Door{{ID: "side"}}))
+}
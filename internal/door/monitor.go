@@ -0,0 +1,278 @@
+package door
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"gym-door-bridge/internal/types"
+)
+
+// Door position states
+const (
+	DoorStateUnknown    = "unknown" // No contact sensor, or the sensor cannot be read
+	DoorStateClosed     = "closed"
+	DoorStateOpen       = "open"        // Open after an access grant or exit request
+	DoorStateHeldOpen   = "held_open"   // Open longer than the held-open timeout
+	DoorStateForcedOpen = "forced_open" // Opened without an access grant or exit request
+)
+
+const (
+	// defaultMonitorInterval is how often door inputs are polled
+	defaultMonitorInterval = 100 * time.Millisecond
+
+	// openGracePeriod is how long after the lock relocks the door may still be
+	// opened without raising a forced-open alarm
+	openGracePeriod = 2 * time.Second
+)
+
+// DoorInput reads a door contact or request-to-exit input
+type DoorInput interface {
+	// Active reports whether the input is asserted: the door is open, or the
+	// exit button is pressed
+	Active(ctx context.Context) (bool, error)
+}
+
+// DoorAlarm is raised when a door is forced open or held open, and again with
+// Cleared set once the door closes
+type DoorAlarm struct {
+	DoorID    string        `json:"doorId"`
+	DoorName  string        `json:"doorName"`
+	Zone      string        `json:"zone,omitempty"`
+	Type      string        `json:"type"` // types.EventTypeDoorForcedOpen or types.EventTypeDoorHeldOpen
+	OpenedAt  time.Time     `json:"openedAt"`
+	OpenFor   time.Duration `json:"openFor"`
+	Cleared   bool          `json:"cleared"`
+	Timestamp time.Time     `json:"timestamp"`
+}
+
+// AlarmHandler receives door alarms; it is called without the controller lock held
+type AlarmHandler func(alarm DoorAlarm)
+
+// WithAlarmHandler sets the handler called when a door is forced or held open
+func WithAlarmHandler(handler AlarmHandler) DoorControllerOption {
+	return func(d *DoorController) {
+		d.alarmHandler = handler
+	}
+}
+
+// WithMonitorInterval sets how often door contact and exit inputs are polled
+func WithMonitorInterval(interval time.Duration) DoorControllerOption {
+	return func(d *DoorController) {
+		d.monitorInterval = interval
+	}
+}
+
+// startMonitor starts polling door inputs when any door has a contact or exit input
+func (d *DoorController) startMonitor() {
+	monitored := false
+	for _, door := range d.doors {
+		if door.Contact != nil || door.Rex != nil {
+			monitored = true
+			break
+		}
+	}
+	if !monitored {
+		return
+	}
+
+	interval := d.monitorInterval
+	if interval <= 0 {
+		interval = defaultMonitorInterval
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	d.stopMonitor = cancel
+	d.monitorDone = make(chan struct{})
+
+	go func() {
+		defer close(d.monitorDone)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				d.checkDoors(ctx, now)
+			}
+		}
+	}()
+}
+
+// stopMonitorLoop stops polling door inputs and waits for the poll loop to exit
+func (d *DoorController) stopMonitorLoop() {
+	if d.stopMonitor == nil {
+		return
+	}
+	d.stopMonitor()
+	<-d.monitorDone
+	d.stopMonitor = nil
+}
+
+// inputReading is the result of reading a door's inputs
+type inputReading struct {
+	open       bool
+	contactErr error
+	rexActive  bool
+	rexErr     error
+}
+
+// checkDoors reads the inputs of every monitored door and updates door states,
+// raising alarms for doors forced or held open
+func (d *DoorController) checkDoors(ctx context.Context, now time.Time) {
+	d.mu.RLock()
+	doors := append([]*doorState{}, d.doors...)
+	d.mu.RUnlock()
+
+	// Inputs may be on the network; read them without holding the lock
+	readings := make(map[*doorState]inputReading, len(doors))
+	for _, door := range doors {
+		var reading inputReading
+		if door.Contact != nil {
+			reading.open, reading.contactErr = door.Contact.Active(ctx)
+		}
+		if door.Rex != nil {
+			reading.rexActive, reading.rexErr = door.Rex.Active(ctx)
+		}
+		readings[door] = reading
+	}
+
+	var alarms []DoorAlarm
+	d.mu.Lock()
+	for _, door := range doors {
+		reading := readings[door]
+		if door.Rex != nil {
+			d.updateRex(ctx, door, reading, now)
+		}
+		if door.Contact != nil {
+			if alarm := d.updatePosition(door, reading, now); alarm != nil {
+				alarms = append(alarms, *alarm)
+			}
+		}
+	}
+	handler := d.alarmHandler
+	d.mu.Unlock()
+
+	for _, alarm := range alarms {
+		if handler != nil {
+			handler(alarm)
+		}
+	}
+}
+
+// updateRex authorizes the door to open when the exit button is pressed, and
+// unlocks it when the door is configured to; the caller holds the mutex
+func (d *DoorController) updateRex(ctx context.Context, door *doorState, reading inputReading, now time.Time) {
+	if reading.rexErr != nil {
+		if !door.rexFailed {
+			d.logger.WithError(reading.rexErr).WithField("doorId", door.ID).Warn("Failed to read door exit button")
+		}
+		door.rexFailed = true
+		return
+	}
+	door.rexFailed = false
+
+	pressed := reading.rexActive && !door.rexActive
+	door.rexActive = reading.rexActive
+	if !pressed {
+		return
+	}
+
+	durationMs := door.UnlockDurationMs
+	if durationMs <= 0 {
+		durationMs = d.config.DefaultUnlockDuration
+	}
+	d.logger.WithField("doorId", door.ID).Info("Door exit requested")
+
+	if door.RexUnlocks && d.lockdown == nil {
+		// unlockDoor authorizes the opening on success
+		if err := d.unlockDoor(ctx, door, durationMs); err == nil {
+			return
+		}
+	}
+	door.authorizedUntil = now.Add(time.Duration(durationMs) * time.Millisecond)
+}
+
+// updatePosition applies a contact reading to the door state and returns the
+// alarm to raise, if any; the caller holds the mutex
+func (d *DoorController) updatePosition(door *doorState, reading inputReading, now time.Time) *DoorAlarm {
+	if reading.contactErr != nil {
+		if door.state != DoorStateUnknown {
+			d.logger.WithError(reading.contactErr).WithField("doorId", door.ID).Warn("Failed to read door contact")
+		}
+		door.state = DoorStateUnknown
+		return nil
+	}
+
+	previous := door.state
+	switch {
+	case !reading.open:
+		var alarm *DoorAlarm
+		if previous == DoorStateHeldOpen || previous == DoorStateForcedOpen {
+			d.logger.WithField("doorId", door.ID).Info("Door closed, alarm cleared")
+			alarm = door.alarm(previous, now)
+			alarm.Cleared = true
+		}
+		door.state = DoorStateClosed
+		door.openedAt = time.Time{}
+		return alarm
+
+	case previous == DoorStateUnknown || previous == "":
+		// Open when monitoring starts, or when the contact recovers; there is
+		// no edge to judge, so do not raise a forced-open alarm
+		door.state = DoorStateOpen
+		door.openedAt = now
+
+	case previous == DoorStateClosed:
+		door.openedAt = now
+		if door.freeAccess || now.Before(door.authorizedUntil.Add(openGracePeriod)) {
+			door.state = DoorStateOpen
+			return nil
+		}
+		door.state = DoorStateForcedOpen
+		d.logger.WithFields(logrus.Fields{
+			"doorId": door.ID,
+			"zone":   door.Zone,
+		}).Warn("Door forced open")
+		return door.alarm(DoorStateForcedOpen, now)
+
+	case previous == DoorStateOpen:
+		// Doors held unlocked by a schedule are expected to stand open
+		if door.HeldOpenTimeout > 0 && !door.freeAccess && now.Sub(door.openedAt) >= door.HeldOpenTimeout {
+			door.state = DoorStateHeldOpen
+			d.logger.WithFields(logrus.Fields{
+				"doorId":  door.ID,
+				"zone":    door.Zone,
+				"openFor": now.Sub(door.openedAt).String(),
+			}).Warn("Door held open")
+			return door.alarm(DoorStateHeldOpen, now)
+		}
+	}
+
+	return nil
+}
+
+// alarm builds the alarm for the given door state
+func (s *doorState) alarm(state string, now time.Time) *DoorAlarm {
+	alarmType := types.EventTypeDoorHeldOpen
+	if state == DoorStateForcedOpen {
+		alarmType = types.EventTypeDoorForcedOpen
+	}
+
+	alarm := &DoorAlarm{
+		DoorID:    s.ID,
+		DoorName:  s.Name,
+		Zone:      s.Zone,
+		Type:      alarmType,
+		OpenedAt:  s.openedAt,
+		Timestamp: now,
+	}
+	if !s.openedAt.IsZero() {
+		alarm.OpenFor = now.Sub(s.openedAt)
+	}
+	return alarm
+}
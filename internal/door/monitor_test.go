@@ -0,0 +1,127 @@
+package door
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"gym-door-bridge/internal/config"
+	"gym-door-bridge/internal/types"
+)
+
+// fakeInput is a contact or exit button input the test switches
+type fakeInput struct {
+	active bool
+	err    error
+}
+
+func (f *fakeInput) Active(ctx context.Context) (bool, error) {
+	return f.active, f.err
+}
+
+func newMonitoredController(door Door) (*DoorController, *[]DoorAlarm) {
+	alarms := &[]DoorAlarm{}
+	controller := NewDoorController(DefaultDoorControlConfig(), &config.Config{}, &mockRegistry{},
+		WithDoors([]Door{door}),
+		WithAlarmHandler(func(alarm DoorAlarm) { *alarms = append(*alarms, alarm) }),
+	)
+	return controller, alarms
+}
+
+func TestDoorMonitor_ForcedOpen(t *testing.T) {
+	ctx := context.Background()
+	contact := &fakeInput{}
+	controller, alarms := newMonitoredController(Door{ID: "front", Name: "Front Door", Relay: &fakeRelay{}, Contact: contact})
+
+	now := time.Now()
+	controller.checkDoors(ctx, now)
+	assert.Equal(t, DoorStateClosed, controller.GetDoorStatus("front").State)
+
+	// Opened without a grant
+	contact.active = true
+	controller.checkDoors(ctx, now.Add(time.Second))
+	assert.Equal(t, DoorStateForcedOpen, controller.GetDoorStatus("front").State)
+	if assert.Len(t, *alarms, 1) {
+		assert.Equal(t, types.EventTypeDoorForcedOpen, (*alarms)[0].Type)
+		assert.Equal(t, "front", (*alarms)[0].DoorID)
+		assert.False(t, (*alarms)[0].Cleared)
+	}
+
+	// Raised once per incident
+	controller.checkDoors(ctx, now.Add(2*time.Second))
+	assert.Len(t, *alarms, 1)
+
+	contact.active = false
+	controller.checkDoors(ctx, now.Add(5*time.Second))
+	assert.Equal(t, DoorStateClosed, controller.GetDoorStatus("front").State)
+	if assert.Len(t, *alarms, 2) {
+		assert.True(t, (*alarms)[1].Cleared)
+		assert.Equal(t, 4*time.Second, (*alarms)[1].OpenFor)
+	}
+}
+
+func TestDoorMonitor_GrantedThenHeldOpen(t *testing.T) {
+	ctx := context.Background()
+	contact := &fakeInput{}
+	controller, alarms := newMonitoredController(Door{ID: "front", Relay: &fakeRelay{}, Contact: contact, HeldOpenTimeout: 30 * time.Second})
+
+	now := time.Now()
+	controller.checkDoors(ctx, now)
+	assert.NoError(t, controller.UnlockDoorByID(ctx, "front", 0))
+
+	contact.active = true
+	controller.checkDoors(ctx, now.Add(time.Second))
+	assert.Equal(t, DoorStateOpen, controller.GetDoorStatus("front").State)
+	assert.Empty(t, *alarms)
+
+	controller.checkDoors(ctx, now.Add(32*time.Second))
+	assert.Equal(t, DoorStateHeldOpen, controller.GetDoorStatus("front").State)
+	if assert.Len(t, *alarms, 1) {
+		assert.Equal(t, types.EventTypeDoorHeldOpen, (*alarms)[0].Type)
+	}
+}
+
+func TestDoorMonitor_ExitRequest(t *testing.T) {
+	ctx := context.Background()
+	contact := &fakeInput{}
+	rex := &fakeInput{}
+	relay := &fakeRelay{}
+	controller, alarms := newMonitoredController(Door{ID: "front", Relay: relay, Contact: contact, Rex: rex, RexUnlocks: true})
+
+	now := time.Now()
+	controller.checkDoors(ctx, now)
+
+	rex.active = true
+	controller.checkDoors(ctx, now.Add(100*time.Millisecond))
+	assert.Equal(t, []int{3000}, relay.pulses)
+
+	// Holding the button does not pulse again
+	contact.active = true
+	controller.checkDoors(ctx, now.Add(200*time.Millisecond))
+	assert.Len(t, relay.pulses, 1)
+	assert.Equal(t, DoorStateOpen, controller.GetDoorStatus("front").State)
+	assert.Empty(t, *alarms)
+}
+
+func TestDoorMonitor_ContactFailure(t *testing.T) {
+	ctx := context.Background()
+	contact := &fakeInput{active: true}
+	controller, alarms := newMonitoredController(Door{ID: "front", Relay: &fakeRelay{}, Contact: contact})
+
+	// Open at startup is not judged as forced
+	now := time.Now()
+	controller.checkDoors(ctx, now)
+	assert.Equal(t, DoorStateOpen, controller.GetDoorStatus("front").State)
+
+	contact.err = errors.New("module offline")
+	controller.checkDoors(ctx, now.Add(time.Second))
+	assert.Equal(t, DoorStateUnknown, controller.GetDoorStatus("front").State)
+	assert.Empty(t, *alarms)
+
+	// Doors without a contact have no known position
+	controller, _ = newMonitoredController(Door{ID: "back", Relay: &fakeRelay{}})
+	assert.Equal(t, DoorStateUnknown, controller.GetDoorStatus("back").State)
+}
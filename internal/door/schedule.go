@@ -0,0 +1,273 @@
+package door
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"gym-door-bridge/internal/access"
+	"gym-door-bridge/internal/database"
+)
+
+// scheduleInterval is how often door schedules are re-evaluated
+const scheduleInterval = 30 * time.Second
+
+// ErrLockdown is returned when an unlock is refused because a lockdown is active
+var ErrLockdown = errors.New("doors are in lockdown")
+
+// ScheduleStore loads door schedules, holidays and the lockdown state
+type ScheduleStore interface {
+	ListDoorSchedules() ([]database.DoorSchedule, error)
+	ListDoorHolidays() ([]database.DoorHoliday, error)
+	GetDoorLockdown() (*database.DoorLockdown, error)
+}
+
+// doorSchedule is an enabled schedule with its windows parsed
+type doorSchedule struct {
+	id      string
+	doors   map[string]bool // Doors the schedule applies to; empty applies to all doors
+	windows []access.AccessWindow
+}
+
+// appliesTo reports whether the schedule covers the door
+func (s doorSchedule) appliesTo(doorID string) bool {
+	return len(s.doors) == 0 || s.doors[doorID]
+}
+
+// WithSchedules enables free-access schedules, holidays and lockdown. Schedules are
+// evaluated in the given location so that they follow the site's local time,
+// including daylight saving changes.
+func WithSchedules(store ScheduleStore, location *time.Location) DoorControllerOption {
+	return func(d *DoorController) {
+		d.scheduleStore = store
+		d.location = location
+		if d.location == nil {
+			d.location = time.Local
+		}
+	}
+}
+
+// ReloadSchedules reloads schedules, holidays and the lockdown state from the
+// store and applies them to the doors immediately
+func (d *DoorController) ReloadSchedules(ctx context.Context) error {
+	if d.scheduleStore == nil {
+		return fmt.Errorf("door schedules are not configured")
+	}
+
+	records, err := d.scheduleStore.ListDoorSchedules()
+	if err != nil {
+		return fmt.Errorf("failed to load door schedules: %w", err)
+	}
+	holidays, err := d.scheduleStore.ListDoorHolidays()
+	if err != nil {
+		return fmt.Errorf("failed to load door holidays: %w", err)
+	}
+	lockdown, err := d.scheduleStore.GetDoorLockdown()
+	if err != nil {
+		return fmt.Errorf("failed to load door lockdown: %w", err)
+	}
+
+	var schedules []doorSchedule
+	for _, record := range records {
+		if !record.Enabled {
+			continue
+		}
+		windows, err := access.ParseAllowedHours(record.Windows)
+		if err != nil || len(windows) == 0 {
+			d.logger.WithError(err).WithField("scheduleId", record.ID).Warn("Ignoring door schedule with invalid windows")
+			continue
+		}
+		schedule := doorSchedule{id: record.ID, windows: windows, doors: make(map[string]bool, len(record.DoorIDs))}
+		for _, doorID := range record.DoorIDs {
+			schedule.doors[doorID] = true
+		}
+		schedules = append(schedules, schedule)
+	}
+
+	holidaysByDate := make(map[string]database.DoorHoliday, len(holidays))
+	for _, holiday := range holidays {
+		holidaysByDate[holiday.Date] = holiday
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.schedules = schedules
+	d.holidays = holidaysByDate
+	d.setLockdown(ctx, lockdown)
+	d.applySchedules(ctx, time.Now())
+	return nil
+}
+
+// InLockdown reports whether a lockdown is active
+func (d *DoorController) InLockdown() bool {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	return d.lockdown != nil
+}
+
+// Lockdown returns the active lockdown
+// Returns nil if no lockdown is active
+func (d *DoorController) Lockdown() *database.DoorLockdown {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	if d.lockdown == nil {
+		return nil
+	}
+	lockdown := *d.lockdown
+	return &lockdown
+}
+
+// setLockdown records the lockdown state, locking every door when a lockdown
+// starts; the caller holds the mutex
+func (d *DoorController) setLockdown(ctx context.Context, lockdown *database.DoorLockdown) {
+	starting := lockdown != nil && d.lockdown == nil
+	ending := lockdown == nil && d.lockdown != nil
+	d.lockdown = lockdown
+
+	if ending {
+		d.logger.Info("Door lockdown ended")
+		return
+	}
+	if !starting {
+		return
+	}
+
+	d.logger.WithFields(logrus.Fields{
+		"reason":      lockdown.Reason,
+		"activatedBy": lockdown.ActivatedBy,
+	}).Warn("Door lockdown activated")
+
+	if d.relay != nil {
+		if err := d.relay.Lock(ctx); err != nil {
+			d.logger.WithError(err).Error("Failed to lock door relay for lockdown")
+		}
+	}
+	for _, door := range d.doors {
+		door.authorizedUntil = time.Time{}
+		if door.Relay == nil {
+			continue
+		}
+		if err := door.Relay.Lock(ctx); err != nil {
+			door.failureCount++
+			d.failureCount++
+			d.logger.WithError(err).WithField("doorId", door.ID).Error("Failed to lock door for lockdown")
+			continue
+		}
+		door.freeAccess = false
+	}
+}
+
+// startScheduler loads the schedules and re-evaluates them periodically
+func (d *DoorController) startScheduler(ctx context.Context) {
+	if d.scheduleStore == nil {
+		return
+	}
+
+	if err := d.ReloadSchedules(ctx); err != nil {
+		d.logger.WithError(err).Error("Failed to load door schedules")
+	}
+
+	schedulerCtx, cancel := context.WithCancel(context.Background())
+	d.stopScheduler = cancel
+	d.schedulerDone = make(chan struct{})
+
+	go func() {
+		defer close(d.schedulerDone)
+
+		ticker := time.NewTicker(scheduleInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-schedulerCtx.Done():
+				return
+			case now := <-ticker.C:
+				d.mu.Lock()
+				d.applySchedules(schedulerCtx, now)
+				d.mu.Unlock()
+			}
+		}
+	}()
+}
+
+// stopSchedulerLoop stops re-evaluating schedules and waits for the loop to exit
+func (d *DoorController) stopSchedulerLoop() {
+	if d.stopScheduler == nil {
+		return
+	}
+	d.stopScheduler()
+	<-d.schedulerDone
+	d.stopScheduler = nil
+}
+
+// applySchedules holds doors unlocked while they are in a free-access window and
+// relocks them when the window ends; the caller holds the mutex
+func (d *DoorController) applySchedules(ctx context.Context, now time.Time) {
+	local := now.In(d.location)
+
+	for _, door := range d.doors {
+		free := d.lockdown == nil && d.freeAccessAt(door.ID, local)
+		if free == door.freeAccess {
+			continue
+		}
+
+		if door.Relay == nil {
+			if free && !door.scheduleWarned {
+				d.logger.WithField("doorId", door.ID).Warn("Door has no relay output; free access schedule ignored")
+				door.scheduleWarned = true
+			}
+			continue
+		}
+
+		var err error
+		if free {
+			err = door.Relay.Unlock(ctx)
+		} else {
+			err = door.Relay.Lock(ctx)
+		}
+		if err != nil {
+			// Retried on the next evaluation
+			door.failureCount++
+			d.failureCount++
+			d.logger.WithError(err).WithField("doorId", door.ID).Error("Failed to apply door schedule")
+			continue
+		}
+
+		door.freeAccess = free
+		if free {
+			d.logger.WithField("doorId", door.ID).Info("Door free access started")
+		} else {
+			d.logger.WithField("doorId", door.ID).Info("Door free access ended")
+		}
+	}
+}
+
+// freeAccessAt reports whether the door is scheduled for free access at the
+// given local time. A holiday on that date replaces the weekly schedules; the
+// caller holds the mutex.
+func (d *DoorController) freeAccessAt(doorID string, local time.Time) bool {
+	if holiday, ok := d.holidays[local.Format("2006-01-02")]; ok {
+		if holiday.StartTime == "" || holiday.EndTime == "" {
+			return false // Closed all day
+		}
+		window := access.AccessWindow{
+			Days:  []int{int(local.Weekday())},
+			Start: holiday.StartTime,
+			End:   holiday.EndTime,
+		}
+		return access.WithinAllowedHours([]access.AccessWindow{window}, local)
+	}
+
+	for _, schedule := range d.schedules {
+		if schedule.appliesTo(doorID) && access.WithinAllowedHours(schedule.windows, local) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,191 @@
+package door
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gym-door-bridge/internal/config"
+	"gym-door-bridge/internal/database"
+)
+
+// fakeScheduleStore serves schedules, holidays and the lockdown from memory
+type fakeScheduleStore struct {
+	schedules []database.DoorSchedule
+	holidays  []database.DoorHoliday
+	lockdown  *database.DoorLockdown
+}
+
+func (f *fakeScheduleStore) ListDoorSchedules() ([]database.DoorSchedule, error) {
+	return f.schedules, nil
+}
+
+func (f *fakeScheduleStore) ListDoorHolidays() ([]database.DoorHoliday, error) {
+	return f.holidays, nil
+}
+
+func (f *fakeScheduleStore) GetDoorLockdown() (*database.DoorLockdown, error) {
+	return f.lockdown, nil
+}
+
+func newScheduledController(t *testing.T, store *fakeScheduleStore, doors ...Door) *DoorController {
+	location, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	controller := NewDoorController(DefaultDoorControlConfig(), &config.Config{}, &mockRegistry{},
+		WithDoors(doors),
+		WithSchedules(store, location),
+	)
+	require.NoError(t, controller.ReloadSchedules(context.Background()))
+	return controller
+}
+
+// applyAt evaluates the schedules at the given time
+func applyAt(controller *DoorController, now time.Time) {
+	controller.mu.Lock()
+	defer controller.mu.Unlock()
+	controller.applySchedules(context.Background(), now)
+}
+
+func TestDoorSchedule_FreeAccess(t *testing.T) {
+	front := &fakeRelay{}
+	back := &fakeRelay{}
+	store := &fakeScheduleStore{schedules: []database.DoorSchedule{
+		// Sundays 06:00 to 22:00 at the front door only
+		{ID: "staffed", DoorIDs: []string{"front"}, Windows: `[{"days":[0],"start":"06:00","end":"22:00"}]`, Enabled: true},
+		{ID: "disabled", Windows: `[{"days":[0,1,2,3,4,5,6],"start":"00:00","end":"24:00"}]`, Enabled: false},
+	}}
+	controller := newScheduledController(t, store,
+		Door{ID: "front", Relay: front},
+		Door{ID: "back", Relay: back},
+	)
+
+	// Daylight saving starts in New York on 2026-03-08; 10:30 UTC is 06:30 local
+	// time that day but would be 05:30 under standard time
+	applyAt(controller, time.Date(2026, 3, 8, 10, 30, 0, 0, time.UTC))
+	assert.True(t, front.held)
+	assert.False(t, back.held)
+	assert.True(t, controller.GetDoorStatus("front").FreeAccess)
+	assert.True(t, controller.GetDoorStatus("front").Unlocked)
+
+	// A week earlier, 10:30 UTC is 05:30 standard time, before opening
+	applyAt(controller, time.Date(2026, 3, 1, 10, 30, 0, 0, time.UTC))
+	assert.False(t, front.held)
+	assert.False(t, controller.GetDoorStatus("front").FreeAccess)
+
+	// 01:30 UTC Monday is still Sunday evening in New York
+	applyAt(controller, time.Date(2026, 3, 9, 1, 30, 0, 0, time.UTC))
+	assert.True(t, front.held)
+	applyAt(controller, time.Date(2026, 3, 9, 2, 30, 0, 0, time.UTC))
+	assert.False(t, front.held)
+}
+
+func TestDoorSchedule_Holidays(t *testing.T) {
+	relay := &fakeRelay{}
+	store := &fakeScheduleStore{
+		schedules: []database.DoorSchedule{
+			{ID: "weekdays", Windows: `[{"days":[1,2,3,4,5],"start":"06:00","end":"22:00"}]`, Enabled: true},
+		},
+		holidays: []database.DoorHoliday{
+			{ID: "christmas", Date: "2026-12-25"},
+			{ID: "new-year", Date: "2027-01-01", StartTime: "10:00", EndTime: "14:00"},
+		},
+	}
+	controller := newScheduledController(t, store, Door{ID: "front", Relay: relay})
+
+	// Friday 2026-12-18 at noon local time follows the weekly plan
+	applyAt(controller, time.Date(2026, 12, 18, 17, 0, 0, 0, time.UTC))
+	assert.True(t, relay.held)
+
+	// Christmas is a Friday, but the holiday closes the site all day
+	applyAt(controller, time.Date(2026, 12, 25, 17, 0, 0, 0, time.UTC))
+	assert.False(t, relay.held)
+
+	// New Year's Day opens with reduced hours
+	applyAt(controller, time.Date(2027, 1, 1, 14, 0, 0, 0, time.UTC)) // 09:00 local
+	assert.False(t, relay.held)
+	applyAt(controller, time.Date(2027, 1, 1, 16, 0, 0, 0, time.UTC)) // 11:00 local
+	assert.True(t, relay.held)
+	applyAt(controller, time.Date(2027, 1, 1, 20, 0, 0, 0, time.UTC)) // 15:00 local
+	assert.False(t, relay.held)
+}
+
+func TestDoorSchedule_Lockdown(t *testing.T) {
+	ctx := context.Background()
+	relay := &fakeRelay{}
+	store := &fakeScheduleStore{schedules: []database.DoorSchedule{
+		{ID: "always", Windows: `[{"days":[0,1,2,3,4,5,6],"start":"00:00","end":"24:00"}]`, Enabled: true},
+	}}
+	controller := newScheduledController(t, store, Door{ID: "front", Relay: relay})
+	sunday := time.Date(2026, 3, 8, 16, 0, 0, 0, time.UTC)
+
+	applyAt(controller, sunday)
+	assert.True(t, relay.held)
+	assert.False(t, controller.InLockdown())
+
+	store.lockdown = &database.DoorLockdown{Reason: "incident", ActivatedBy: "admin", ActivatedAt: sunday}
+	require.NoError(t, controller.ReloadSchedules(ctx))
+	assert.True(t, controller.InLockdown())
+	assert.Equal(t, "incident", controller.Lockdown().Reason)
+	assert.False(t, relay.held, "lockdown must relock doors held open by a schedule")
+
+	// Scheduled and manual unlocks are refused
+	applyAt(controller, sunday)
+	assert.False(t, relay.held)
+	assert.ErrorIs(t, controller.UnlockDoorByID(ctx, "front", 0), ErrLockdown)
+	assert.ErrorIs(t, controller.UnlockDoor(ctx, "", 0), ErrLockdown)
+	assert.Empty(t, relay.pulses)
+	assert.NotNil(t, controller.GetStats()["lockdown"])
+
+	// The standalone endpoint reports the lockdown
+	req := httptest.NewRequest(http.MethodPost, "/open-door?doorId=front", nil)
+	w := httptest.NewRecorder()
+	controller.handleDoorUnlock(w, req)
+	assert.Equal(t, http.StatusLocked, w.Code)
+
+	store.lockdown = nil
+	require.NoError(t, controller.ReloadSchedules(ctx))
+	assert.False(t, controller.InLockdown())
+	assert.Nil(t, controller.Lockdown())
+	applyAt(controller, sunday)
+	assert.True(t, relay.held)
+	assert.NoError(t, controller.UnlockDoorByID(ctx, "front", 0))
+}
+
+func TestDoorSchedule_FreeAccessSuppressesForcedOpen(t *testing.T) {
+	ctx := context.Background()
+	contact := &fakeInput{}
+	alarms := 0
+	store := &fakeScheduleStore{schedules: []database.DoorSchedule{
+		{ID: "always", Windows: `[{"days":[0,1,2,3,4,5,6],"start":"00:00","end":"24:00"}]`, Enabled: true},
+	}}
+	location, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+	controller := NewDoorController(DefaultDoorControlConfig(), &config.Config{}, &mockRegistry{},
+		WithDoors([]Door{{ID: "front", Relay: &fakeRelay{}, Contact: contact, HeldOpenTimeout: time.Second}}),
+		WithSchedules(store, location),
+		WithAlarmHandler(func(alarm DoorAlarm) { alarms++ }),
+	)
+	require.NoError(t, controller.ReloadSchedules(ctx))
+
+	now := time.Now()
+	controller.checkDoors(ctx, now)
+	contact.active = true
+	controller.checkDoors(ctx, now.Add(time.Minute))
+	controller.checkDoors(ctx, now.Add(time.Hour))
+	assert.Equal(t, DoorStateOpen, controller.GetDoorStatus("front").State)
+	assert.Zero(t, alarms)
+}
+
+func TestDoorSchedule_ReloadWithoutStore(t *testing.T) {
+	controller := NewDoorController(DefaultDoorControlConfig(), &config.Config{}, &mockRegistry{})
+	err := controller.ReloadSchedules(context.Background())
+	assert.Error(t, err)
+	assert.False(t, errors.Is(err, ErrLockdown))
+}
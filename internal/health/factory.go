@@ -40,6 +40,7 @@ func NewHealthSystem(
 	tierDetector TierDetector,
 	httpClient HTTPClient,
 	logger *logrus.Logger,
+	heartbeatOpts ...HeartbeatManagerOption,
 ) (*HealthSystem, error) {
 	// Create adapter registry
 	adapterRegistry := NewSimpleAdapterRegistry()
@@ -72,7 +73,7 @@ func NewHealthSystem(
 		config.Heartbeat,
 		httpClient,
 		healthMonitor,
-		WithHeartbeatLogger(logger),
+		append([]HeartbeatManagerOption{WithHeartbeatLogger(logger)}, heartbeatOpts...)...,
 	)
 	
 	return &HealthSystem{
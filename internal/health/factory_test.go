@@ -144,7 +144,7 @@ func TestHealthSystem_StartStop(t *testing.T) {
 	mockQueue.On("GetStats", mock.Anything).Return(queue.QueueStats{
 		QueueDepth: 5,
 	}, nil).Maybe()
-	mockHTTPClient.On("SendHeartbeat", mock.Anything, mock.Anything).Return(nil).Maybe()
+	mockHTTPClient.On("SendHeartbeat", mock.Anything, mock.Anything).Return(nil, nil).Maybe()
 	
 	// Test start
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -39,6 +39,7 @@ type SystemHealth struct {
 	Resources     tier.SystemResources   `json:"resources"`
 	Tier          tier.Tier              `json:"tier"`
 	LastEventTime *time.Time             `json:"lastEventTime,omitempty"`
+	OldestPendingEvent *time.Time        `json:"oldestPendingEvent,omitempty"` // Timestamp of the oldest unsent event
 	Uptime        time.Duration          `json:"uptime"`
 	Version       string                 `json:"version"`
 	DeviceID      string                 `json:"deviceId,omitempty"`
@@ -303,12 +304,15 @@ func (h *HealthMonitor) updateHealthStatus(ctx context.Context) error {
 	// Determine overall health status
 	overallStatus := h.determineOverallHealth(queueDepth, adapterStatuses, resources)
 	
-	// Get last event time (if available)
-	var lastEventTime *time.Time
+	// Get last event time and oldest pending event (if available)
+	var lastEventTime, oldestPendingEvent *time.Time
 	if stats, err := h.queueManager.GetStats(ctx); err == nil {
 		if !stats.LastSentAt.IsZero() {
 			lastEventTime = &stats.LastSentAt
 		}
+		if !stats.OldestEventTime.IsZero() {
+			oldestPendingEvent = &stats.OldestEventTime
+		}
 	}
 	
 	// Update current health
@@ -320,6 +324,7 @@ func (h *HealthMonitor) updateHealthStatus(ctx context.Context) error {
 		Resources:     resources,
 		Tier:          currentTier,
 		LastEventTime: lastEventTime,
+		OldestPendingEvent: oldestPendingEvent,
 		Uptime:        now.Sub(h.startTime),
 		Version:       h.version,
 		DeviceID:      h.deviceID,
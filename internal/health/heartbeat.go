@@ -2,6 +2,7 @@ package health
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
 	"time"
@@ -9,12 +10,24 @@ import (
 	"github.com/sirupsen/logrus"
 
 	"gym-door-bridge/internal/client"
+	"gym-door-bridge/internal/database"
 	"gym-door-bridge/internal/tier"
 )
 
 // HTTPClient interface for sending heartbeats
 type HTTPClient interface {
-	SendHeartbeat(ctx context.Context, heartbeat *client.HeartbeatRequest) error
+	SendHeartbeat(ctx context.Context, heartbeat *client.HeartbeatRequest) (*client.HeartbeatResponse, error)
+}
+
+// DoorStateProvider provides the door states reported in heartbeats
+type DoorStateProvider interface {
+	GetDoorStates() []client.DoorHeartbeat
+}
+
+// EvictionStore holds the summaries of events evicted from the full queue
+type EvictionStore interface {
+	GetEventEvictions() ([]database.EventEviction, error)
+	AcknowledgeEventEvictions(evictions []database.EventEviction) error
 }
 
 // HeartbeatConfig holds configuration for the heartbeat manager
@@ -46,6 +59,14 @@ type HeartbeatManager struct {
 	logger       *logrus.Logger
 	httpClient   HTTPClient
 	healthMonitor *HealthMonitor
+	evictionStore EvictionStore
+	doorStates    DoorStateProvider
+	configVersion func() int64
+	
+	// Handlers for what the platform asks for in heartbeat responses
+	onConfigVersion   func(version int64)
+	onPendingCommands func(count int)
+	onUpdateAvailable func()
 	
 	// State
 	isRunning    bool
@@ -53,6 +74,10 @@ type HeartbeatManager struct {
 	lastError    error
 	sendCount    int64
 	errorCount   int64
+	clockOffset          *time.Duration // Platform clock minus local clock
+	backoffUntil         time.Time      // No heartbeats before this time, as requested by the platform
+	desiredConfigVersion int64
+	updateAvailable      bool
 	
 	// Control
 	stopCh       chan struct{}
@@ -69,6 +94,52 @@ func WithHeartbeatLogger(logger *logrus.Logger) HeartbeatManagerOption {
 	}
 }
 
+// WithEvictionStore reports events evicted from the full queue in heartbeats
+func WithEvictionStore(store EvictionStore) HeartbeatManagerOption {
+	return func(h *HeartbeatManager) {
+		h.evictionStore = store
+	}
+}
+
+// WithDoorStates reports door states in heartbeats
+func WithDoorStates(provider DoorStateProvider) HeartbeatManagerOption {
+	return func(h *HeartbeatManager) {
+		h.doorStates = provider
+	}
+}
+
+// WithConfigVersion reports the platform config version in effect in heartbeats
+func WithConfigVersion(version func() int64) HeartbeatManagerOption {
+	return func(h *HeartbeatManager) {
+		h.configVersion = version
+	}
+}
+
+// WithConfigVersionHandler is called with the config version the platform wants
+// the bridge to run, after every heartbeat whose response includes one. The
+// handler must not block.
+func WithConfigVersionHandler(handler func(version int64)) HeartbeatManagerOption {
+	return func(h *HeartbeatManager) {
+		h.onConfigVersion = handler
+	}
+}
+
+// WithPendingCommandsHandler is called when the platform reports commands waiting
+// for the bridge. The handler must not block.
+func WithPendingCommandsHandler(handler func(count int)) HeartbeatManagerOption {
+	return func(h *HeartbeatManager) {
+		h.onPendingCommands = handler
+	}
+}
+
+// WithUpdateAvailableHandler is called when the platform starts reporting an
+// available bridge release. The handler must not block.
+func WithUpdateAvailableHandler(handler func()) HeartbeatManagerOption {
+	return func(h *HeartbeatManager) {
+		h.onUpdateAvailable = handler
+	}
+}
+
 // NewHeartbeatManager creates a new heartbeat manager
 func NewHeartbeatManager(
 	config HeartbeatConfig,
@@ -153,14 +224,22 @@ func (h *HeartbeatManager) GetStats() HeartbeatStats {
 	h.mu.RLock()
 	defer h.mu.RUnlock()
 	
-	return HeartbeatStats{
+	stats := HeartbeatStats{
 		IsRunning:   h.isRunning,
 		LastSent:    h.lastSent,
 		LastError:   h.lastError,
 		SendCount:   h.sendCount,
 		ErrorCount:  h.errorCount,
 		Interval:    h.config.Interval,
+		DesiredConfigVersion: h.desiredConfigVersion,
+	}
+	if h.clockOffset != nil {
+		stats.ClockOffset = *h.clockOffset
+	}
+	if h.backoffUntil.After(time.Now()) {
+		stats.BackoffUntil = h.backoffUntil
 	}
+	return stats
 }
 
 // HeartbeatStats contains statistics about heartbeat operations
@@ -171,14 +250,17 @@ type HeartbeatStats struct {
 	SendCount   int64         `json:"sendCount"`
 	ErrorCount  int64         `json:"errorCount"`
 	Interval    time.Duration `json:"interval"`
+	ClockOffset time.Duration `json:"clockOffset"`            // Platform clock minus local clock
+	BackoffUntil time.Time    `json:"backoffUntil,omitempty"` // Set while the platform has asked for fewer heartbeats
+	DesiredConfigVersion int64 `json:"desiredConfigVersion,omitempty"`
 }
 
 // heartbeatLoop runs the main heartbeat loop
 func (h *HeartbeatManager) heartbeatLoop(ctx context.Context) {
 	defer close(h.stoppedCh)
 	
-	ticker := time.NewTicker(h.config.Interval)
-	defer ticker.Stop()
+	timer := time.NewTimer(h.nextHeartbeatDelay())
+	defer timer.Stop()
 	
 	for {
 		select {
@@ -188,7 +270,7 @@ func (h *HeartbeatManager) heartbeatLoop(ctx context.Context) {
 		case <-h.stopCh:
 			h.logger.Info("Heartbeat loop stopped")
 			return
-		case <-ticker.C:
+		case <-timer.C:
 			if err := h.sendHeartbeat(ctx); err != nil {
 				h.mu.Lock()
 				h.lastError = err
@@ -205,13 +287,30 @@ func (h *HeartbeatManager) heartbeatLoop(ctx context.Context) {
 				
 				h.logger.Debug("Heartbeat sent successfully")
 			}
+			timer.Reset(h.nextHeartbeatDelay())
 		}
 	}
 }
 
+// nextHeartbeatDelay returns the heartbeat interval, extended to the end of a
+// backoff requested by the platform
+func (h *HeartbeatManager) nextHeartbeatDelay() time.Duration {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	
+	delay := h.config.Interval
+	if wait := time.Until(h.backoffUntil); wait > delay {
+		delay = wait
+	}
+	return delay
+}
+
 // sendHeartbeat sends a single heartbeat to the cloud
 func (h *HeartbeatManager) sendHeartbeat(ctx context.Context) error {
-	// Get current health status
+	// Refresh health status; it is otherwise only updated when the health endpoint is queried
+	if err := h.healthMonitor.UpdateHealth(ctx); err != nil {
+		h.logger.WithError(err).Warn("Failed to update health status for heartbeat")
+	}
 	health := h.healthMonitor.GetCurrentHealth()
 	
 	// Build heartbeat request
@@ -219,12 +318,41 @@ func (h *HeartbeatManager) sendHeartbeat(ctx context.Context) error {
 		Status:     health.Status.String(),
 		Tier:       health.Tier.String(),
 		QueueDepth: health.QueueDepth,
+		Version:    health.Version,
 	}
 	
 	// Add last event time if available
 	if health.LastEventTime != nil {
 		heartbeat.LastEventTime = health.LastEventTime.Format(time.RFC3339)
 	}
+	if health.OldestPendingEvent != nil {
+		heartbeat.OldestPendingAge = int64(time.Since(*health.OldestPendingEvent).Seconds())
+	}
+	
+	for _, status := range health.AdapterStatus {
+		adapter := client.AdapterHeartbeat{
+			Name:   status.Name,
+			Status: status.Status,
+			Error:  status.ErrorMessage,
+		}
+		if !status.LastEvent.IsZero() {
+			adapter.LastEvent = status.LastEvent.UTC().Format(time.RFC3339)
+		}
+		heartbeat.Adapters = append(heartbeat.Adapters, adapter)
+	}
+	if h.doorStates != nil {
+		heartbeat.Doors = h.doorStates.GetDoorStates()
+	}
+	if h.configVersion != nil {
+		heartbeat.ConfigVersion = h.configVersion()
+	}
+	
+	h.mu.RLock()
+	if h.clockOffset != nil {
+		offsetMs := h.clockOffset.Milliseconds()
+		heartbeat.ClockOffsetMs = &offsetMs
+	}
+	h.mu.RUnlock()
 	
 	// Add system info if enabled
 	if h.config.EnableSystemInfo {
@@ -235,12 +363,110 @@ func (h *HeartbeatManager) sendHeartbeat(ctx context.Context) error {
 		}
 	}
 	
+	// Report events evicted from the full queue since the last heartbeat
+	var evictions []database.EventEviction
+	if h.evictionStore != nil {
+		var err error
+		if evictions, err = h.evictionStore.GetEventEvictions(); err != nil {
+			h.logger.WithError(err).Warn("Failed to get queue evictions for heartbeat")
+		}
+		for _, eviction := range evictions {
+			h.logger.WithFields(logrus.Fields{
+				"priority_class": eviction.PriorityClass,
+				"event_count":    eviction.EventCount,
+				"oldest_event":   eviction.OldestEventAt,
+				"newest_event":   eviction.NewestEventAt,
+			}).Warn("Events were evicted from the full offline queue")
+			heartbeat.QueueEvictions = append(heartbeat.QueueEvictions, client.QueueEviction{
+				PriorityClass: eviction.PriorityClass,
+				EventCount:    eviction.EventCount,
+				OldestEvent:   eviction.OldestEventAt.UTC().Format(time.RFC3339),
+				NewestEvent:   eviction.NewestEventAt.UTC().Format(time.RFC3339),
+			})
+		}
+	}
+	
 	// Send heartbeat with retries
-	return h.sendHeartbeatWithRetries(ctx, heartbeat)
+	resp, err := h.sendHeartbeatWithRetries(ctx, heartbeat)
+	if err != nil {
+		return err
+	}
+	
+	// Evictions are only forgotten once the platform has received them
+	if len(evictions) > 0 {
+		if err := h.evictionStore.AcknowledgeEventEvictions(evictions); err != nil {
+			h.logger.WithError(err).Warn("Failed to acknowledge reported queue evictions")
+		}
+	}
+	
+	h.handleResponse(resp)
+	return nil
+}
+
+// handleResponse acts on the platform's reply to a heartbeat
+func (h *HeartbeatManager) handleResponse(resp *client.HeartbeatResponse) {
+	if resp == nil {
+		return
+	}
+	
+	if resp.BackoffSeconds > 0 {
+		h.backOff(time.Duration(resp.BackoffSeconds) * time.Second)
+	}
+	
+	h.mu.Lock()
+	if resp.DesiredConfigVersion != h.desiredConfigVersion {
+		h.logger.WithField("version", resp.DesiredConfigVersion).Info("Platform reported desired config version")
+	}
+	h.desiredConfigVersion = resp.DesiredConfigVersion
+	updateAnnounced := resp.UpdateAvailable && !h.updateAvailable
+	h.updateAvailable = resp.UpdateAvailable
+	h.mu.Unlock()
+	
+	if resp.DesiredConfigVersion > 0 && h.onConfigVersion != nil {
+		h.onConfigVersion(resp.DesiredConfigVersion)
+	}
+	if resp.PendingCommands > 0 && h.onPendingCommands != nil {
+		h.onPendingCommands(resp.PendingCommands)
+	}
+	// Only act when the update first appears; the updater checks regularly anyway
+	if updateAnnounced && h.onUpdateAvailable != nil {
+		h.logger.Info("Platform reported an available update")
+		h.onUpdateAvailable()
+	}
+}
+
+// backOff holds off heartbeats for the given duration
+func (h *HeartbeatManager) backOff(d time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	
+	until := time.Now().Add(d)
+	if until.After(h.backoffUntil) {
+		h.backoffUntil = until
+		h.logger.WithField("until", until).Warn("Platform requested heartbeat backoff")
+	}
+}
+
+// recordClockOffset estimates the platform clock offset from the server time in
+// a response, assuming the platform handled the request halfway through the round trip
+func (h *HeartbeatManager) recordClockOffset(serverTime string, sentAt, receivedAt time.Time) {
+	if serverTime == "" {
+		return
+	}
+	platformTime, err := time.Parse(time.RFC3339Nano, serverTime)
+	if err != nil {
+		h.logger.WithError(err).Debug("Ignoring invalid server time in heartbeat response")
+		return
+	}
+	offset := platformTime.Sub(sentAt.Add(receivedAt.Sub(sentAt) / 2))
+	
+	h.mu.Lock()
+	h.clockOffset = &offset
+	h.mu.Unlock()
 }
 
 // sendHeartbeatWithRetries sends a heartbeat with retry logic
-func (h *HeartbeatManager) sendHeartbeatWithRetries(ctx context.Context, heartbeat *client.HeartbeatRequest) error {
+func (h *HeartbeatManager) sendHeartbeatWithRetries(ctx context.Context, heartbeat *client.HeartbeatRequest) (*client.HeartbeatResponse, error) {
 	var lastErr error
 	
 	for attempt := 0; attempt <= h.config.MaxRetries; attempt++ {
@@ -248,15 +474,26 @@ func (h *HeartbeatManager) sendHeartbeatWithRetries(ctx context.Context, heartbe
 		timeoutCtx, cancel := context.WithTimeout(ctx, h.config.Timeout)
 		
 		// Send heartbeat
-		err := h.httpClient.SendHeartbeat(timeoutCtx, heartbeat)
+		sentAt := time.Now()
+		resp, err := h.httpClient.SendHeartbeat(timeoutCtx, heartbeat)
 		cancel()
 		
 		if err == nil {
-			return nil // Success
+			if resp != nil {
+				h.recordClockOffset(resp.ServerTime, sentAt, time.Now())
+			}
+			return resp, nil // Success
 		}
 		
 		lastErr = err
 		
+		// The platform is overloaded; wait as long as it asked before trying again
+		var backoffErr *client.BackoffError
+		if errors.As(err, &backoffErr) {
+			h.backOff(backoffErr.RetryAfter)
+			return nil, fmt.Errorf("heartbeat deferred by platform: %w", err)
+		}
+		
 		// Don't retry on the last attempt
 		if attempt == h.config.MaxRetries {
 			break
@@ -267,15 +504,15 @@ func (h *HeartbeatManager) sendHeartbeatWithRetries(ctx context.Context, heartbe
 		
 		select {
 		case <-ctx.Done():
-			return ctx.Err()
+			return nil, ctx.Err()
 		case <-h.stopCh:
-			return fmt.Errorf("heartbeat manager stopped")
+			return nil, fmt.Errorf("heartbeat manager stopped")
 		case <-time.After(h.config.RetryBackoff):
 			// Continue to next attempt
 		}
 	}
 	
-	return fmt.Errorf("heartbeat failed after %d attempts: %w", h.config.MaxRetries+1, lastErr)
+	return nil, fmt.Errorf("heartbeat failed after %d attempts: %w", h.config.MaxRetries+1, lastErr)
 }
 
 // UpdateConfig updates the heartbeat configuration
@@ -2,16 +2,21 @@ package health
 
 import (
 	"context"
+	"fmt"
 	"testing"
 	"time"
 
 	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
 	"gym-door-bridge/internal/client"
+	"gym-door-bridge/internal/database"
+	"gym-door-bridge/internal/queue"
 	"gym-door-bridge/internal/tier"
+	"gym-door-bridge/internal/types"
 )
 
 
@@ -37,7 +42,7 @@ func TestHeartbeatManager_Start_Stop(t *testing.T) {
 	)
 	
 	// Mock expectations - expect at least one heartbeat call
-	mockClient.On("SendHeartbeat", mock.Anything, mock.AnythingOfType("*client.HeartbeatRequest")).Return(nil).Maybe()
+	mockClient.On("SendHeartbeat", mock.Anything, mock.AnythingOfType("*client.HeartbeatRequest")).Return(nil, nil).Maybe()
 	
 	// Test start
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -93,7 +98,7 @@ func TestHeartbeatManager_SendHeartbeat(t *testing.T) {
 			hb.Tier == expectedHeartbeat.Tier &&
 			hb.QueueDepth == expectedHeartbeat.QueueDepth &&
 			hb.SystemInfo != nil
-	})).Return(nil)
+	})).Return(nil, nil)
 	
 	// Test sending heartbeat
 	ctx := context.Background()
@@ -119,8 +124,8 @@ func TestHeartbeatManager_SendHeartbeat_WithRetries(t *testing.T) {
 	manager := NewHeartbeatManager(config, mockClient, mockHealthMonitor)
 	
 	// Mock expectations - fail first two attempts, succeed on third
-	mockClient.On("SendHeartbeat", mock.Anything, mock.Anything).Return(assert.AnError).Twice()
-	mockClient.On("SendHeartbeat", mock.Anything, mock.Anything).Return(nil).Once()
+	mockClient.On("SendHeartbeat", mock.Anything, mock.Anything).Return(nil, assert.AnError).Twice()
+	mockClient.On("SendHeartbeat", mock.Anything, mock.Anything).Return(nil, nil).Once()
 	
 	// Test sending heartbeat with retries
 	ctx := context.Background()
@@ -146,7 +151,7 @@ func TestHeartbeatManager_SendHeartbeat_AllRetriesFail(t *testing.T) {
 	manager := NewHeartbeatManager(config, mockClient, mockHealthMonitor)
 	
 	// Mock expectations - all attempts fail
-	mockClient.On("SendHeartbeat", mock.Anything, mock.Anything).Return(assert.AnError).Times(3) // Initial + 2 retries
+	mockClient.On("SendHeartbeat", mock.Anything, mock.Anything).Return(nil, assert.AnError).Times(3) // Initial + 2 retries
 	
 	// Test sending heartbeat with all retries failing
 	ctx := context.Background()
@@ -157,6 +162,189 @@ func TestHeartbeatManager_SendHeartbeat_AllRetriesFail(t *testing.T) {
 	mockClient.AssertExpectations(t)
 }
 
+func TestHeartbeatManager_SendHeartbeat_QueueEvictions(t *testing.T) {
+	// Setup
+	mockClient := &MockHTTPClient{}
+	mockHealthMonitor := createMockHealthMonitor()
+	mockStore := &MockEvictionStore{}
+	
+	config := HeartbeatConfig{
+		Interval:         1 * time.Hour,
+		Timeout:          1 * time.Second,
+		MaxRetries:       0,
+		RetryBackoff:     10 * time.Millisecond,
+		EnableSystemInfo: false,
+	}
+	
+	logger, logs := test.NewNullLogger()
+	manager := NewHeartbeatManager(config, mockClient, mockHealthMonitor, WithEvictionStore(mockStore), WithHeartbeatLogger(logger))
+	
+	oldest := time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC)
+	evictions := []database.EventEviction{
+		{ID: 1, PriorityClass: database.PriorityClassAccess, EventCount: 12, OldestEventAt: oldest, NewestEventAt: oldest.Add(time.Hour)},
+	}
+	
+	// Mock expectations - evictions are only acknowledged after a successful send
+	mockStore.On("GetEventEvictions").Return(evictions, nil).Twice()
+	mockClient.On("SendHeartbeat", mock.Anything, mock.Anything).Return(nil, assert.AnError).Once()
+	mockClient.On("SendHeartbeat", mock.Anything, mock.MatchedBy(func(hb *client.HeartbeatRequest) bool {
+		return len(hb.QueueEvictions) == 1 &&
+			hb.QueueEvictions[0].PriorityClass == "access" &&
+			hb.QueueEvictions[0].EventCount == 12 &&
+			hb.QueueEvictions[0].OldestEvent == "2024-01-01T08:00:00Z" &&
+			hb.QueueEvictions[0].NewestEvent == "2024-01-01T09:00:00Z"
+	})).Return(nil, nil).Once()
+	mockStore.On("AcknowledgeEventEvictions", evictions).Return(nil).Once()
+	
+	ctx := context.Background()
+	require.Error(t, manager.sendHeartbeat(ctx))
+	require.NoError(t, manager.sendHeartbeat(ctx))
+	
+	mockClient.AssertExpectations(t)
+	mockStore.AssertExpectations(t)
+	
+	// Evictions are logged for the operator as well as reported
+	entry := logs.LastEntry()
+	require.NotNil(t, entry)
+	assert.Equal(t, logrus.WarnLevel, entry.Level)
+	assert.Equal(t, "access", entry.Data["priority_class"])
+	assert.Equal(t, 12, entry.Data["event_count"])
+}
+
+type fakeDoorStates []client.DoorHeartbeat
+
+func (f fakeDoorStates) GetDoorStates() []client.DoorHeartbeat {
+	return f
+}
+
+func TestHeartbeatManager_SendHeartbeat_Payload(t *testing.T) {
+	// Setup
+	mockClient := &MockHTTPClient{}
+	mockHealthMonitor := createMockHealthMonitor()
+	mockHealthMonitor.version = "1.4.0"
+	mockHealthMonitor.adapterRegistry.(*SimpleAdapterRegistry).RegisterAdapter(&MockHardwareAdapter{
+		name: "zkteco",
+		status: types.AdapterStatus{
+			Name:         "zkteco",
+			Status:       types.StatusError,
+			ErrorMessage: "connection refused",
+		},
+	})
+	oldest := time.Now().Add(-10 * time.Minute)
+	mockQueue := mockHealthMonitor.queueManager.(*MockQueueManager)
+	mockQueue.ExpectedCalls = nil
+	mockQueue.On("GetQueueDepth", mock.Anything).Return(3, nil)
+	mockQueue.On("GetStats", mock.Anything).Return(queue.QueueStats{QueueDepth: 3, OldestEventTime: oldest}, nil)
+	
+	config := HeartbeatConfig{
+		Interval:   1 * time.Hour,
+		Timeout:    1 * time.Second,
+		MaxRetries: 0,
+	}
+	doors := fakeDoorStates{{ID: "front", State: "closed", Unlocked: true}}
+	
+	manager := NewHeartbeatManager(config, mockClient, mockHealthMonitor,
+		WithDoorStates(doors),
+		WithConfigVersion(func() int64 { return 12 }),
+	)
+	
+	// The first heartbeat measures the clock offset reported by the second
+	serverTime := time.Now().Add(90 * time.Second).UTC().Format(time.RFC3339Nano)
+	var sent []*client.HeartbeatRequest
+	mockClient.On("SendHeartbeat", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		sent = append(sent, args.Get(1).(*client.HeartbeatRequest))
+	}).Return(&client.HeartbeatResponse{ServerTime: serverTime}, nil)
+	
+	ctx := context.Background()
+	require.NoError(t, manager.sendHeartbeat(ctx))
+	require.NoError(t, manager.sendHeartbeat(ctx))
+	require.Len(t, sent, 2)
+	
+	hb := sent[0]
+	assert.Equal(t, "1.4.0", hb.Version)
+	assert.Equal(t, 3, hb.QueueDepth)
+	assert.InDelta(t, 600, hb.OldestPendingAge, 5)
+	require.Len(t, hb.Adapters, 1)
+	assert.Equal(t, client.AdapterHeartbeat{Name: "zkteco", Status: "error", Error: "connection refused"}, hb.Adapters[0])
+	assert.Equal(t, []client.DoorHeartbeat(doors), hb.Doors)
+	assert.Nil(t, hb.ClockOffsetMs)
+	assert.Equal(t, int64(12), hb.ConfigVersion)
+	
+	require.NotNil(t, sent[1].ClockOffsetMs)
+	assert.InDelta(t, 90000, *sent[1].ClockOffsetMs, 1000)
+	assert.InDelta(t, float64(90*time.Second), float64(manager.GetStats().ClockOffset), float64(time.Second))
+}
+
+func TestHeartbeatManager_HandlesResponse(t *testing.T) {
+	// Setup
+	mockClient := &MockHTTPClient{}
+	mockHealthMonitor := createMockHealthMonitor()
+	
+	config := HeartbeatConfig{
+		Interval:   1 * time.Minute,
+		Timeout:    1 * time.Second,
+		MaxRetries: 0,
+	}
+	
+	var configVersions []int64
+	var pendingCommands []int
+	updates := 0
+	manager := NewHeartbeatManager(config, mockClient, mockHealthMonitor,
+		WithConfigVersionHandler(func(version int64) { configVersions = append(configVersions, version) }),
+		WithPendingCommandsHandler(func(count int) { pendingCommands = append(pendingCommands, count) }),
+		WithUpdateAvailableHandler(func() { updates++ }),
+	)
+	
+	mockClient.On("SendHeartbeat", mock.Anything, mock.Anything).Return(&client.HeartbeatResponse{
+		DesiredConfigVersion: 7,
+		PendingCommands:      2,
+		UpdateAvailable:      true,
+		BackoffSeconds:       600,
+	}, nil).Twice()
+	mockClient.On("SendHeartbeat", mock.Anything, mock.Anything).Return(&client.HeartbeatResponse{}, nil).Once()
+	
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		require.NoError(t, manager.sendHeartbeat(ctx))
+	}
+	
+	assert.Equal(t, []int64{7, 7}, configVersions)
+	assert.Equal(t, []int{2, 2}, pendingCommands)
+	assert.Equal(t, 1, updates, "an update is only announced when it first appears")
+	
+	// The requested backoff stretches the heartbeat interval
+	stats := manager.GetStats()
+	assert.WithinDuration(t, time.Now().Add(10*time.Minute), stats.BackoffUntil, 5*time.Second)
+	assert.Greater(t, manager.nextHeartbeatDelay(), 9*time.Minute)
+	
+	mockClient.AssertExpectations(t)
+}
+
+func TestHeartbeatManager_SendHeartbeat_ServerBackoff(t *testing.T) {
+	// Setup
+	mockClient := &MockHTTPClient{}
+	mockHealthMonitor := createMockHealthMonitor()
+	
+	config := HeartbeatConfig{
+		Interval:     1 * time.Minute,
+		Timeout:      1 * time.Second,
+		MaxRetries:   3,
+		RetryBackoff: 10 * time.Millisecond,
+	}
+	
+	manager := NewHeartbeatManager(config, mockClient, mockHealthMonitor)
+	
+	// An overloaded platform is not retried until the requested time has passed
+	backoffErr := &client.BackoffError{RetryAfter: 5 * time.Minute, Err: assert.AnError}
+	mockClient.On("SendHeartbeat", mock.Anything, mock.Anything).Return(nil, fmt.Errorf("heartbeat failed: %w", backoffErr)).Once()
+	
+	err := manager.sendHeartbeat(context.Background())
+	require.Error(t, err)
+	assert.Greater(t, manager.nextHeartbeatDelay(), 4*time.Minute)
+	
+	mockClient.AssertExpectations(t)
+}
+
 func TestHeartbeatManager_UpdateConfig(t *testing.T) {
 	// Setup
 	mockClient := &MockHTTPClient{}
@@ -223,7 +411,7 @@ func TestHeartbeatManager_ContextCancellation(t *testing.T) {
 	manager := NewHeartbeatManager(config, mockClient, mockHealthMonitor)
 	
 	// Mock expectations - allow heartbeat calls
-	mockClient.On("SendHeartbeat", mock.Anything, mock.Anything).Return(nil).Maybe()
+	mockClient.On("SendHeartbeat", mock.Anything, mock.Anything).Return(nil, nil).Maybe()
 	
 	// Test with context cancellation
 	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
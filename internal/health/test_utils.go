@@ -7,6 +7,7 @@ import (
 	"github.com/stretchr/testify/mock"
 
 	"gym-door-bridge/internal/client"
+	"gym-door-bridge/internal/database"
 	"gym-door-bridge/internal/queue"
 	"gym-door-bridge/internal/tier"
 	"gym-door-bridge/internal/types"
@@ -100,8 +101,23 @@ type MockHTTPClient struct {
 	mock.Mock
 }
 
-func (m *MockHTTPClient) SendHeartbeat(ctx context.Context, heartbeat *client.HeartbeatRequest) error {
+func (m *MockHTTPClient) SendHeartbeat(ctx context.Context, heartbeat *client.HeartbeatRequest) (*client.HeartbeatResponse, error) {
 	args := m.Called(ctx, heartbeat)
+	resp, _ := args.Get(0).(*client.HeartbeatResponse)
+	return resp, args.Error(1)
+}
+
+type MockEvictionStore struct {
+	mock.Mock
+}
+
+func (m *MockEvictionStore) GetEventEvictions() ([]database.EventEviction, error) {
+	args := m.Called()
+	return args.Get(0).([]database.EventEviction), args.Error(1)
+}
+
+func (m *MockEvictionStore) AcknowledgeEventEvictions(evictions []database.EventEviction) error {
+	args := m.Called(evictions)
 	return args.Error(0)
 }
 
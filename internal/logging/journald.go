@@ -0,0 +1,62 @@
+package logging
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// JournaldFormatter writes entries for the systemd journal. The journal adds its
+// own timestamps, so each entry is a single line starting with the syslog
+// priority prefix that journald maps to the entry priority, followed by the
+// message and the fields as key=value pairs.
+type JournaldFormatter struct{}
+
+// journaldPriorities maps logrus levels to syslog priorities
+var journaldPriorities = map[logrus.Level]int{
+	logrus.PanicLevel: 2, // crit
+	logrus.FatalLevel: 2, // crit
+	logrus.ErrorLevel: 3, // err
+	logrus.WarnLevel:  4, // warning
+	logrus.InfoLevel:  6, // info
+	logrus.DebugLevel: 7, // debug
+	logrus.TraceLevel: 7, // debug
+}
+
+// Format renders a single journal line for the entry
+func (f *JournaldFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "<%d>%s", journaldPriorities[entry.Level], singleLine(entry.Message))
+
+	keys := make([]string, 0, len(entry.Data))
+	for key := range entry.Data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		value := fmt.Sprint(entry.Data[key])
+		if err, ok := entry.Data[key].(error); ok {
+			value = err.Error()
+		}
+		if value == "" || strings.ContainsAny(value, " \t\n\"=") {
+			value = strconv.Quote(value)
+		}
+		b.WriteString(" ")
+		b.WriteString(key)
+		b.WriteString("=")
+		b.WriteString(value)
+	}
+
+	b.WriteString("\n")
+	return b.Bytes(), nil
+}
+
+// singleLine keeps multi-line messages in one journal entry
+func singleLine(message string) string {
+	return strings.ReplaceAll(message, "\n", `\n`)
+}
@@ -0,0 +1,32 @@
+package logging
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// isJournalStream reports whether the file is the stream systemd connected to
+// the journal, as announced in JOURNAL_STREAM
+func isJournalStream(file *os.File) bool {
+	stream := os.Getenv("JOURNAL_STREAM")
+	if stream == "" {
+		return false
+	}
+
+	var device, inode uint64
+	if _, err := fmt.Sscanf(stream, "%d:%d", &device, &inode); err != nil {
+		return false
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		return false
+	}
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return false
+	}
+
+	return uint64(stat.Dev) == device && uint64(stat.Ino) == inode
+}
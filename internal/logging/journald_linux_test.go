@@ -0,0 +1,31 @@
+package logging
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsJournalStream(t *testing.T) {
+	file, err := os.Create(filepath.Join(t.TempDir(), "stream"))
+	require.NoError(t, err)
+	defer file.Close()
+
+	t.Setenv("JOURNAL_STREAM", "")
+	assert.False(t, isJournalStream(file))
+
+	info, err := file.Stat()
+	require.NoError(t, err)
+	stat := info.Sys().(*syscall.Stat_t)
+
+	t.Setenv("JOURNAL_STREAM", fmt.Sprintf("%d:%d", stat.Dev, stat.Ino))
+	assert.True(t, isJournalStream(file))
+
+	t.Setenv("JOURNAL_STREAM", fmt.Sprintf("%d:%d", stat.Dev, stat.Ino+1))
+	assert.False(t, isJournalStream(file), "stdout inherited from a journal-connected parent")
+}
@@ -0,0 +1,10 @@
+//go:build !linux
+
+package logging
+
+import "os"
+
+// isJournalStream always reports false; the systemd journal only exists on Linux
+func isJournalStream(file *os.File) bool {
+	return false
+}
@@ -0,0 +1,34 @@
+package logging
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJournaldFormatter(t *testing.T) {
+	logger := logrus.New()
+	formatter := &JournaldFormatter{}
+
+	entry := logrus.NewEntry(logger).WithFields(logrus.Fields{
+		"service": "roster-sync",
+		"error":   errors.New("connection refused"),
+		"count":   3,
+	})
+	entry.Level = logrus.WarnLevel
+	entry.Message = "Roster sync failed"
+
+	line, err := formatter.Format(entry)
+	require.NoError(t, err)
+	assert.Equal(t, "<4>Roster sync failed count=3 error=\"connection refused\" service=roster-sync\n", string(line))
+
+	entry = logrus.NewEntry(logger)
+	entry.Level = logrus.ErrorLevel
+	entry.Message = "first line\nsecond line"
+	line, err = formatter.Format(entry)
+	require.NoError(t, err)
+	assert.Equal(t, "<3>first line\\nsecond line\n", string(line))
+}
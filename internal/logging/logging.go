@@ -25,15 +25,20 @@ func Initialize(logLevel string) *logrus.Logger {
 	}
 	logger.SetLevel(level)
 	
-	// Set JSON formatter for structured logging
-	logger.SetFormatter(&logrus.JSONFormatter{
-		TimestampFormat: "2006-01-02T15:04:05.000Z07:00",
-		FieldMap: logrus.FieldMap{
-			logrus.FieldKeyTime:  "timestamp",
-			logrus.FieldKeyLevel: "level",
-			logrus.FieldKeyMsg:   "message",
-		},
-	})
+	// Set JSON formatter for structured logging, or write journal entries
+	// when running as a systemd service
+	if isJournalStream(os.Stdout) {
+		logger.SetFormatter(&JournaldFormatter{})
+	} else {
+		logger.SetFormatter(&logrus.JSONFormatter{
+			TimestampFormat: "2006-01-02T15:04:05.000Z07:00",
+			FieldMap: logrus.FieldMap{
+				logrus.FieldKeyTime:  "timestamp",
+				logrus.FieldKeyLevel: "level",
+				logrus.FieldKeyMsg:   "message",
+			},
+		})
+	}
 	
 	// Default to stdout
 	logger.SetOutput(os.Stdout)
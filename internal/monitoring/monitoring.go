@@ -21,6 +21,8 @@ const (
 	AlertTypeSecurityEvent     AlertType = "security_event"
 	AlertTypePerformanceDegradation AlertType = "performance_degradation"
 	AlertTypeCriticalError     AlertType = "critical_error"
+	AlertTypeDoorForcedOpen    AlertType = "door_forced_open"
+	AlertTypeDoorHeldOpen      AlertType = "door_held_open"
 )
 
 // AlertSeverity represents the severity level of an alert
@@ -350,6 +352,66 @@ func (m *MonitoringSystem) LogSecurityEvent(ctx context.Context, eventType, desc
 	return nil
 }
 
+// RaiseDoorAlarm generates an alert for a door forced or held open. The alert is
+// keyed by type and door, so it is raised once until ResolveDoorAlarms clears it.
+func (m *MonitoringSystem) RaiseDoorAlarm(ctx context.Context, alertType AlertType, doorID, doorName string, metadata map[string]interface{}) error {
+	var severity AlertSeverity
+	var title string
+	switch alertType {
+	case AlertTypeDoorForcedOpen:
+		severity = AlertSeverityCritical
+		title = fmt.Sprintf("Door Forced Open: %s", doorName)
+	case AlertTypeDoorHeldOpen:
+		severity = AlertSeverityHigh
+		title = fmt.Sprintf("Door Held Open: %s", doorName)
+	default:
+		return fmt.Errorf("not a door alarm type: %s", alertType)
+	}
+	
+	alertID := fmt.Sprintf("%s_%s", alertType, doorID)
+	
+	m.mu.RLock()
+	existing, exists := m.activeAlerts[alertID]
+	m.mu.RUnlock()
+	if exists && !existing.Resolved {
+		return nil
+	}
+	
+	if metadata == nil {
+		metadata = make(map[string]interface{})
+	}
+	metadata["door_id"] = doorID
+	
+	alert := Alert{
+		ID:          alertID,
+		Type:        alertType,
+		Severity:    severity,
+		Title:       title,
+		Description: fmt.Sprintf("Door %s (%s) reported %s", doorName, doorID, alertType),
+		Timestamp:   time.Now(),
+		DeviceID:    m.deviceID,
+		Metadata:    metadata,
+	}
+	
+	return m.generateAlert(ctx, alert)
+}
+
+// ResolveDoorAlarms resolves the forced-open and held-open alerts of a door once it closes
+func (m *MonitoringSystem) ResolveDoorAlarms(ctx context.Context, doorID string) {
+	now := time.Now()
+	for _, alertType := range []AlertType{AlertTypeDoorForcedOpen, AlertTypeDoorHeldOpen} {
+		alertID := fmt.Sprintf("%s_%s", alertType, doorID)
+		
+		m.mu.RLock()
+		alert, exists := m.activeAlerts[alertID]
+		m.mu.RUnlock()
+		
+		if exists && !alert.Resolved {
+			m.resolveAlert(ctx, alertID, now)
+		}
+	}
+}
+
 // GetCurrentMetrics returns the most recent performance metrics
 func (m *MonitoringSystem) GetCurrentMetrics() *PerformanceMetrics {
 	m.mu.RLock()
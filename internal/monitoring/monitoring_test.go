@@ -249,6 +249,41 @@ func TestMonitoringSystem_AlertGeneration(t *testing.T) {
 	mockAlertHandler.AssertExpectations(t)
 }
 
+func TestMonitoringSystem_DoorAlarms(t *testing.T) {
+	mockAlertHandler := &MockAlertHandler{}
+	system := NewMonitoringSystem(
+		DefaultMonitoringConfig(),
+		&MockHealthMonitor{},
+		&MockQueueManager{},
+		&MockTierDetector{},
+		WithLogger(logrus.New()),
+		WithDeviceID("test-device"),
+	)
+	system.AddAlertHandler(mockAlertHandler)
+	mockAlertHandler.On("HandleAlert", mock.Anything, mock.AnythingOfType("Alert")).Return(nil)
+	
+	ctx := context.Background()
+	require.NoError(t, system.RaiseDoorAlarm(ctx, AlertTypeDoorForcedOpen, "front", "Front Door", nil))
+	
+	// Raised once while the door stays open
+	require.NoError(t, system.RaiseDoorAlarm(ctx, AlertTypeDoorForcedOpen, "front", "Front Door", nil))
+	handledAlerts := mockAlertHandler.GetHandledAlerts()
+	require.Len(t, handledAlerts, 1)
+	assert.Equal(t, AlertSeverityCritical, handledAlerts[0].Severity)
+	assert.Equal(t, "front", handledAlerts[0].Metadata["door_id"])
+	
+	assert.Error(t, system.RaiseDoorAlarm(ctx, AlertTypeQueueThreshold, "front", "Front Door", nil))
+	
+	system.ResolveDoorAlarms(ctx, "front")
+	activeAlerts := system.GetActiveAlerts()
+	require.Len(t, activeAlerts, 1)
+	assert.True(t, activeAlerts[0].Resolved)
+	
+	// A new incident after the door closed raises again
+	require.NoError(t, system.RaiseDoorAlarm(ctx, AlertTypeDoorForcedOpen, "front", "Front Door", nil))
+	assert.Len(t, mockAlertHandler.GetHandledAlerts(), 2)
+}
+
 func TestMonitoringSystem_QueueThresholdAlert(t *testing.T) {
 	logger := logrus.New()
 	config := DefaultMonitoringConfig()
@@ -72,8 +72,10 @@ func (p *EventProcessorImpl) ProcessEvent(ctx context.Context, rawEvent types.Ra
 		}, nil
 	}
 
-	// Check for duplicates if deduplication is enabled
-	if p.config.EnableDeduplication {
+	// Check for duplicates if deduplication is enabled; door alarms are raised
+	// once per incident by the door monitor and are never duplicates
+	alarm := types.IsDoorAlarmEventType(rawEvent.EventType)
+	if p.config.EnableDeduplication && !alarm {
 		isDuplicate, err := p.IsEventDuplicate(ctx, rawEvent)
 		if err != nil {
 			return ProcessingResult{}, fmt.Errorf("failed to check for duplicates: %w", err)
@@ -92,14 +94,18 @@ func (p *EventProcessorImpl) ProcessEvent(ctx context.Context, rawEvent types.Ra
 	eventID := p.GenerateEventID(rawEvent)
 
 	// Resolve external user ID to internal user ID
-	internalUserID, err := p.resolveUserMapping(ctx, rawEvent.ExternalUserID)
-	if err != nil {
-		p.logger.WithFields(logrus.Fields{
-			"external_user_id": rawEvent.ExternalUserID,
-			"event_id":         eventID,
-			"error":            err.Error(),
-		}).Error("Failed to resolve external user ID mapping")
-		// Continue processing even if mapping resolution fails
+	var internalUserID string
+	if !alarm {
+		var err error
+		internalUserID, err = p.resolveUserMapping(ctx, rawEvent.ExternalUserID)
+		if err != nil {
+			p.logger.WithFields(logrus.Fields{
+				"external_user_id": rawEvent.ExternalUserID,
+				"event_id":         eventID,
+				"error":            err.Error(),
+			}).Error("Failed to resolve external user ID mapping")
+			// Continue processing even if mapping resolution fails
+		}
 	}
 
 	// Create standard event with metadata enrichment
@@ -111,6 +117,9 @@ func (p *EventProcessorImpl) ProcessEvent(ctx context.Context, rawEvent types.Ra
 		EventType:      rawEvent.EventType,
 		IsSimulated:    p.isSimulatedEvent(rawEvent),
 		DeviceID:       p.config.DeviceID,
+		DoorID:         rawEvent.DoorID,
+		AdapterName:    rawEvent.AdapterName,
+		VerifyMode:     rawEvent.VerifyMode,
 		RawData:        rawEvent.RawData,
 	}
 
@@ -126,8 +135,8 @@ func (p *EventProcessorImpl) ProcessEvent(ctx context.Context, rawEvent types.Ra
 
 // ValidateEvent checks if a raw event is valid for processing
 func (p *EventProcessorImpl) ValidateEvent(rawEvent types.RawHardwareEvent) error {
-	// Check external user ID
-	if strings.TrimSpace(rawEvent.ExternalUserID) == "" {
+	// Check external user ID; door alarms have no user
+	if strings.TrimSpace(rawEvent.ExternalUserID) == "" && !types.IsDoorAlarmEventType(rawEvent.EventType) {
 		return ValidationError{
 			Field:   "externalUserId",
 			Message: "external user ID cannot be empty",
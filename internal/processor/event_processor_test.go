@@ -104,6 +104,15 @@ func TestEventProcessorImpl_ValidateEvent(t *testing.T) {
 			wantErr:  true,
 			errField: "externalUserId",
 		},
+		{
+			name: "door alarm without user",
+			rawEvent: types.RawHardwareEvent{
+				Timestamp: now,
+				EventType: types.EventTypeDoorForcedOpen,
+				DoorID:    "main",
+			},
+			wantErr: false,
+		},
 		{
 			name: "zero timestamp",
 			rawEvent: types.RawHardwareEvent{
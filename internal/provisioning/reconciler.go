@@ -0,0 +1,478 @@
+package provisioning
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gym-door-bridge/internal/adapters/biometric"
+	"gym-door-bridge/internal/database"
+	"gym-door-bridge/internal/logging"
+)
+
+// Action types
+const (
+	ActionCreate  = "create"
+	ActionUpdate  = "update"
+	ActionDisable = "disable"
+	ActionDelete  = "delete"
+)
+
+// Inactive member handling
+const (
+	InactiveDisable = "disable" // Keep the user and their fingerprints, but refuse verification
+	InactiveDelete  = "delete"  // Remove the user and their fingerprints
+)
+
+// memberStatusActive is the roster status of members allowed on the terminals
+const memberStatusActive = "active"
+
+// Terminal is a biometric terminal whose users are provisioned from the roster
+type Terminal interface {
+	Name() string
+	GetUsers() ([]biometric.DeviceUser, error)
+	WriteUser(user biometric.DeviceUser) error
+	DeleteUser(deviceUserID int) error
+}
+
+// TerminalSource lists the terminals to provision
+type TerminalSource interface {
+	Terminals() []Terminal
+}
+
+// Store defines the database methods needed by the reconciler
+type Store interface {
+	ListRosterMembers() ([]database.RosterMember, error)
+	ListDeviceUserAssignments() ([]database.DeviceUserAssignment, error)
+	CreateDeviceUserAssignment(deviceUserID int, internalUserID string) (*database.DeviceUserAssignment, error)
+	ReleaseDeviceUserAssignment(deviceUserID int, externalUserID string) error
+	UpsertProvisionedMapping(externalUserID, internalUserID, userName string) error
+}
+
+// Config holds reconciler configuration
+type Config struct {
+	Interval         time.Duration
+	InactiveAction   string // InactiveDisable or InactiveDelete
+	DefaultPrivilege int    // Privilege of created terminal users
+}
+
+// Action is a change to a terminal user planned or made by a reconciliation
+type Action struct {
+	Terminal       string `json:"terminal"`
+	Type           string `json:"type"` // create, update, disable, delete
+	DeviceUserID   int    `json:"deviceUserId"`
+	InternalUserID string `json:"internalUserId,omitempty"`
+	Name           string `json:"name,omitempty"`
+	Reason         string `json:"reason"`
+	Error          string `json:"error,omitempty"`
+}
+
+// Report describes the outcome of a reconciliation
+type Report struct {
+	DryRun      bool      `json:"dryRun"`
+	StartedAt   time.Time `json:"startedAt"`
+	CompletedAt time.Time `json:"completedAt"`
+	Members     int       `json:"members"`
+	Terminals   int       `json:"terminals"`
+	Actions     []Action  `json:"actions"`
+	Applied     int       `json:"applied"`
+	Failed      int       `json:"failed"`
+	Unmanaged   int       `json:"unmanaged"` // Terminal users not provisioned by the bridge, left untouched
+	Errors      []string  `json:"errors,omitempty"`
+}
+
+// Stats contains statistics about provisioning
+type Stats struct {
+	TotalRuns     int64  `json:"totalRuns"`
+	TotalFailures int64  `json:"totalFailures"`
+	TotalApplied  int64  `json:"totalApplied"`
+	LastRunAt     int64  `json:"lastRunAt"` // Unix timestamp
+	LastActions   int    `json:"lastActions"`
+	LastFailed    int    `json:"lastFailed"`
+	LastError     string `json:"lastError,omitempty"`
+}
+
+// Reconciler makes the users on biometric terminals match the platform roster.
+// Active members are created or updated, inactive members are disabled or
+// deleted and members removed from the roster are deleted. Terminal users that
+// the bridge did not provision, such as administrators, are left alone.
+type Reconciler struct {
+	config    Config
+	terminals TerminalSource
+	store     Store
+	logger    *logrus.Entry
+	runMu     sync.Mutex // Serializes scheduled and on-demand runs
+	stats     Stats
+	mutex     sync.RWMutex
+}
+
+// desiredUser is the terminal user a member should have
+type desiredUser struct {
+	member       database.RosterMember
+	deviceUserID int
+	cardNumber   string
+	active       bool
+	newID        bool // The device user ID is not assigned yet
+}
+
+// NewReconciler creates a new provisioning reconciler
+func NewReconciler(config Config, terminals TerminalSource, store Store, logger *logrus.Logger) *Reconciler {
+	if config.Interval <= 0 {
+		config.Interval = 15 * time.Minute
+	}
+	if config.InactiveAction == "" {
+		config.InactiveAction = InactiveDisable
+	}
+
+	return &Reconciler{
+		config:    config,
+		terminals: terminals,
+		store:     store,
+		logger:    logging.NewServiceLogger(logger, "provisioning"),
+	}
+}
+
+// Start reconciles periodically until the context is cancelled
+func (r *Reconciler) Start(ctx context.Context) {
+	r.logger.WithField("interval", r.config.Interval).Info("Starting terminal user provisioning")
+
+	ticker := time.NewTicker(r.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			r.logger.Info("Terminal user provisioning stopped")
+			return
+		case <-ticker.C:
+			if _, err := r.Reconcile(ctx, false); err != nil {
+				r.logger.WithError(err).Warn("Terminal user provisioning failed")
+			}
+		}
+	}
+}
+
+// Reconcile compares the roster with the users on each terminal and applies the
+// differences. With dryRun the planned changes are reported without touching
+// the terminals or the device user assignments.
+func (r *Reconciler) Reconcile(ctx context.Context, dryRun bool) (*Report, error) {
+	r.runMu.Lock()
+	defer r.runMu.Unlock()
+
+	report := &Report{DryRun: dryRun, StartedAt: time.Now().UTC(), Actions: []Action{}}
+
+	members, err := r.store.ListRosterMembers()
+	if err != nil {
+		r.recordFailure(err)
+		return nil, fmt.Errorf("failed to list roster members: %w", err)
+	}
+	assignments, err := r.store.ListDeviceUserAssignments()
+	if err != nil {
+		r.recordFailure(err)
+		return nil, fmt.Errorf("failed to list device user assignments: %w", err)
+	}
+
+	// Read every terminal first so new IDs avoid users created on the terminals
+	terminals := r.terminals.Terminals()
+	terminalUsers := make(map[string]map[int]biometric.DeviceUser, len(terminals))
+	takenIDs := make(map[int]bool)
+	for _, terminal := range terminals {
+		users, err := terminal.GetUsers()
+		if err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("%s: %v", terminal.Name(), err))
+			continue
+		}
+		byID := make(map[int]biometric.DeviceUser, len(users))
+		for _, user := range users {
+			byID[user.DeviceUserID] = user
+			takenIDs[user.DeviceUserID] = true
+		}
+		terminalUsers[terminal.Name()] = byID
+	}
+	report.Members = len(members)
+	report.Terminals = len(terminals)
+
+	desired, removed := r.plan(members, assignments, takenIDs)
+
+	// Reserve IDs for new members before writing them to the terminals
+	if !dryRun {
+		for _, user := range desired {
+			if user.newID {
+				if _, err := r.store.CreateDeviceUserAssignment(user.deviceUserID, user.member.InternalUserID); err != nil {
+					r.recordFailure(err)
+					return nil, err
+				}
+			}
+			externalUserID := biometric.ExternalUserID(user.deviceUserID)
+			if err := r.store.UpsertProvisionedMapping(externalUserID, user.member.InternalUserID, user.member.UserName); err != nil {
+				r.recordFailure(err)
+				return nil, err
+			}
+		}
+	}
+
+	failedRemovals := make(map[int]bool)
+	for _, terminal := range terminals {
+		users, ok := terminalUsers[terminal.Name()]
+		if !ok {
+			continue
+		}
+
+		managed := make(map[int]bool)
+		for _, user := range desired {
+			managed[user.deviceUserID] = true
+			existing, exists := users[user.deviceUserID]
+			if action, ok := r.diff(terminal, user, existing, exists); ok {
+				r.apply(terminal, action, r.deviceUser(user, existing, exists, action.Type == ActionDisable), dryRun, report)
+			}
+		}
+
+		for _, assignment := range removed {
+			managed[assignment.DeviceUserID] = true
+			if _, ok := users[assignment.DeviceUserID]; !ok {
+				continue
+			}
+			action := Action{
+				Terminal:       terminal.Name(),
+				Type:           ActionDelete,
+				DeviceUserID:   assignment.DeviceUserID,
+				InternalUserID: assignment.InternalUserID,
+				Reason:         "member removed from roster",
+			}
+			if !r.apply(terminal, action, biometric.DeviceUser{}, dryRun, report) {
+				failedRemovals[assignment.DeviceUserID] = true
+			}
+		}
+
+		for id := range users {
+			if !managed[id] {
+				report.Unmanaged++
+			}
+		}
+	}
+
+	// Free the IDs of removed members once no terminal can still hold them
+	if !dryRun && len(report.Errors) == 0 {
+		for _, assignment := range removed {
+			if failedRemovals[assignment.DeviceUserID] {
+				continue
+			}
+			if err := r.store.ReleaseDeviceUserAssignment(assignment.DeviceUserID, biometric.ExternalUserID(assignment.DeviceUserID)); err != nil {
+				report.Errors = append(report.Errors, err.Error())
+			}
+		}
+	}
+
+	report.CompletedAt = time.Now().UTC()
+	r.recordRun(report)
+
+	r.logger.WithFields(logrus.Fields{
+		"dryRun":    dryRun,
+		"actions":   len(report.Actions),
+		"applied":   report.Applied,
+		"failed":    report.Failed,
+		"terminals": report.Terminals,
+	}).Info("Terminal user reconciliation completed")
+
+	return report, nil
+}
+
+// GetStats returns provisioning statistics
+func (r *Reconciler) GetStats() Stats {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	return r.stats
+}
+
+// plan works out the terminal user every roster member should have and the
+// assignments of members that left the roster. Members get the device user ID
+// from the roster when it is free, otherwise the next unused ID.
+func (r *Reconciler) plan(members []database.RosterMember, assignments []database.DeviceUserAssignment, takenIDs map[int]bool) ([]desiredUser, []database.DeviceUserAssignment) {
+	byMember := make(map[string]database.DeviceUserAssignment, len(assignments))
+	assignedIDs := make(map[int]bool, len(assignments))
+	maxID := 0
+	for _, assignment := range assignments {
+		byMember[assignment.InternalUserID] = assignment
+		assignedIDs[assignment.DeviceUserID] = true
+		if assignment.DeviceUserID > maxID {
+			maxID = assignment.DeviceUserID
+		}
+	}
+	for id := range takenIDs {
+		if id > maxID {
+			maxID = id
+		}
+	}
+
+	inRoster := make(map[string]bool, len(members))
+	var desired []desiredUser
+	for _, member := range members {
+		inRoster[member.InternalUserID] = true
+
+		user := desiredUser{member: member, active: member.Status == memberStatusActive}
+		var preferredID int
+		for _, credential := range member.Credentials {
+			switch credential.Type {
+			case database.CredentialTypeCard:
+				if user.cardNumber == "" {
+					user.cardNumber = credential.ExternalUserID
+				}
+			case database.CredentialTypeDeviceUser:
+				if id, err := strconv.Atoi(credential.ExternalUserID); err == nil && id > 0 && preferredID == 0 {
+					preferredID = id
+				}
+			}
+		}
+
+		if assignment, ok := byMember[member.InternalUserID]; ok {
+			user.deviceUserID = assignment.DeviceUserID
+		} else if !user.active {
+			continue // Inactive members are never created
+		} else if preferredID > 0 && !assignedIDs[preferredID] {
+			// The terminal may already hold this ID, e.g. enrolled on site; it is adopted
+			user.deviceUserID = preferredID
+			user.newID = true
+		} else {
+			maxID++
+			user.deviceUserID = maxID
+			user.newID = true
+		}
+		assignedIDs[user.deviceUserID] = true
+		if user.deviceUserID > maxID {
+			maxID = user.deviceUserID
+		}
+
+		desired = append(desired, user)
+	}
+
+	var removed []database.DeviceUserAssignment
+	for _, assignment := range assignments {
+		if !inRoster[assignment.InternalUserID] {
+			removed = append(removed, assignment)
+		}
+	}
+
+	return desired, removed
+}
+
+// diff returns the action that brings a member's user on the terminal in line
+// with the roster, if any
+func (r *Reconciler) diff(terminal Terminal, user desiredUser, existing biometric.DeviceUser, exists bool) (Action, bool) {
+	action := Action{
+		Terminal:       terminal.Name(),
+		DeviceUserID:   user.deviceUserID,
+		InternalUserID: user.member.InternalUserID,
+		Name:           user.member.UserName,
+	}
+
+	if !user.active {
+		if !exists {
+			return action, false
+		}
+		action.Reason = fmt.Sprintf("member is %s", user.member.Status)
+		if r.config.InactiveAction == InactiveDelete {
+			action.Type = ActionDelete
+			return action, true
+		}
+		if existing.Disabled {
+			return action, false
+		}
+		action.Type = ActionDisable
+		return action, true
+	}
+
+	switch {
+	case !exists:
+		action.Type = ActionCreate
+		action.Reason = "missing on terminal"
+	case existing.Disabled:
+		action.Type = ActionUpdate
+		action.Reason = "member is active again"
+	case existing.Name != user.member.UserName:
+		action.Type = ActionUpdate
+		action.Reason = "name changed"
+	case existing.CardNumber != user.cardNumber:
+		action.Type = ActionUpdate
+		action.Reason = "card number changed"
+	default:
+		return action, false
+	}
+	return action, true
+}
+
+// deviceUser builds the terminal user record for a member. Privileges granted
+// on the terminal are kept.
+func (r *Reconciler) deviceUser(user desiredUser, existing biometric.DeviceUser, exists, disabled bool) biometric.DeviceUser {
+	privilege := r.config.DefaultPrivilege
+	if exists {
+		privilege = existing.Privilege
+	}
+
+	return biometric.DeviceUser{
+		DeviceUserID:   user.deviceUserID,
+		PlatformUserID: user.member.InternalUserID,
+		Name:           user.member.UserName,
+		Privilege:      privilege,
+		CardNumber:     user.cardNumber,
+		Disabled:       disabled,
+	}
+}
+
+// apply records the action in the report and carries it out unless this is a
+// dry run. It returns false if the action failed.
+func (r *Reconciler) apply(terminal Terminal, action Action, deviceUser biometric.DeviceUser, dryRun bool, report *Report) bool {
+	if dryRun {
+		report.Actions = append(report.Actions, action)
+		return true
+	}
+
+	var err error
+	if action.Type == ActionDelete {
+		err = terminal.DeleteUser(action.DeviceUserID)
+	} else {
+		err = terminal.WriteUser(deviceUser)
+	}
+
+	if err != nil {
+		action.Error = err.Error()
+		report.Failed++
+		r.logger.WithError(err).WithFields(logrus.Fields{
+			"terminal":     action.Terminal,
+			"action":       action.Type,
+			"deviceUserId": action.DeviceUserID,
+		}).Warn("Failed to provision terminal user")
+	} else {
+		report.Applied++
+	}
+	report.Actions = append(report.Actions, action)
+	return err == nil
+}
+
+// recordRun updates statistics after a reconciliation
+func (r *Reconciler) recordRun(report *Report) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.stats.TotalRuns++
+	r.stats.TotalApplied += int64(report.Applied)
+	r.stats.LastRunAt = report.CompletedAt.Unix()
+	r.stats.LastActions = len(report.Actions)
+	r.stats.LastFailed = report.Failed
+	r.stats.LastError = ""
+	if len(report.Errors) > 0 {
+		r.stats.LastError = report.Errors[0]
+	}
+}
+
+// recordFailure updates failure statistics
+func (r *Reconciler) recordFailure(err error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.stats.TotalFailures++
+	r.stats.LastError = err.Error()
+}
@@ -0,0 +1,216 @@
+package provisioning
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gym-door-bridge/internal/adapters/biometric"
+	"gym-door-bridge/internal/database"
+)
+
+// fakeTerminal keeps terminal users in memory
+type fakeTerminal struct {
+	name      string
+	users     map[int]biometric.DeviceUser
+	templates []biometric.FingerprintTemplate
+	readErr   error
+	writes    int
+	deletes   int
+}
+
+func newFakeTerminal(name string, users ...biometric.DeviceUser) *fakeTerminal {
+	terminal := &fakeTerminal{name: name, users: make(map[int]biometric.DeviceUser)}
+	for _, user := range users {
+		terminal.users[user.DeviceUserID] = user
+	}
+	return terminal
+}
+
+func (t *fakeTerminal) Name() string { return t.name }
+
+func (t *fakeTerminal) GetUsers() ([]biometric.DeviceUser, error) {
+	if t.readErr != nil {
+		return nil, t.readErr
+	}
+	users := make([]biometric.DeviceUser, 0, len(t.users))
+	for _, user := range t.users {
+		users = append(users, user)
+	}
+	return users, nil
+}
+
+func (t *fakeTerminal) WriteUser(user biometric.DeviceUser) error {
+	t.writes++
+	t.users[user.DeviceUserID] = user
+	return nil
+}
+
+func (t *fakeTerminal) DeleteUser(deviceUserID int) error {
+	t.deletes++
+	delete(t.users, deviceUserID)
+	return nil
+}
+
+type fakeTerminals []Terminal
+
+func (f fakeTerminals) Terminals() []Terminal { return f }
+
+// memoryStore keeps the roster, assignments and provisioned mappings in memory
+type memoryStore struct {
+	members     []database.RosterMember
+	assignments map[int]string
+	mappings    map[string]string
+}
+
+func newMemoryStore(members ...database.RosterMember) *memoryStore {
+	return &memoryStore{members: members, assignments: make(map[int]string), mappings: make(map[string]string)}
+}
+
+func (s *memoryStore) ListRosterMembers() ([]database.RosterMember, error) {
+	return s.members, nil
+}
+
+func (s *memoryStore) ListDeviceUserAssignments() ([]database.DeviceUserAssignment, error) {
+	var assignments []database.DeviceUserAssignment
+	for id, member := range s.assignments {
+		assignments = append(assignments, database.DeviceUserAssignment{DeviceUserID: id, InternalUserID: member})
+	}
+	sort.Slice(assignments, func(i, j int) bool { return assignments[i].DeviceUserID < assignments[j].DeviceUserID })
+	return assignments, nil
+}
+
+func (s *memoryStore) CreateDeviceUserAssignment(deviceUserID int, internalUserID string) (*database.DeviceUserAssignment, error) {
+	if _, ok := s.assignments[deviceUserID]; ok {
+		return nil, errors.New("device user ID already assigned")
+	}
+	s.assignments[deviceUserID] = internalUserID
+	return &database.DeviceUserAssignment{DeviceUserID: deviceUserID, InternalUserID: internalUserID}, nil
+}
+
+func (s *memoryStore) ReleaseDeviceUserAssignment(deviceUserID int, externalUserID string) error {
+	delete(s.assignments, deviceUserID)
+	delete(s.mappings, externalUserID)
+	return nil
+}
+
+func (s *memoryStore) UpsertProvisionedMapping(externalUserID, internalUserID, userName string) error {
+	s.mappings[externalUserID] = internalUserID
+	return nil
+}
+
+func member(id, name, status string, credentials ...database.RosterCredential) database.RosterMember {
+	return database.RosterMember{InternalUserID: id, UserName: name, Status: status, Credentials: credentials}
+}
+
+func newTestReconciler(store Store, inactiveAction string, terminals ...Terminal) *Reconciler {
+	logger := logrus.New()
+	logger.SetLevel(logrus.ErrorLevel)
+	return NewReconciler(Config{InactiveAction: inactiveAction}, fakeTerminals(terminals), store, logger)
+}
+
+func actionTypes(report *Report) map[int]string {
+	types := make(map[int]string)
+	for _, action := range report.Actions {
+		types[action.DeviceUserID] = action.Type
+	}
+	return types
+}
+
+func TestReconciler_ProvisionsRoster(t *testing.T) {
+	ctx := context.Background()
+	admin := biometric.DeviceUser{DeviceUserID: 1, Name: "Manager", Privilege: biometric.PrivilegeAdmin}
+	terminal := newFakeTerminal("front",
+		admin,
+		biometric.DeviceUser{DeviceUserID: 42, Name: "Alex (enrolled on site)"},
+	)
+	store := newMemoryStore(
+		member("user_1", "Alex", "active",
+			database.RosterCredential{ExternalUserID: "42", Type: database.CredentialTypeDeviceUser},
+			database.RosterCredential{ExternalUserID: "100", Type: database.CredentialTypeCard}),
+		member("user_2", "Sam", "active"),
+		member("user_3", "Jo", "expired"),
+	)
+	reconciler := newTestReconciler(store, InactiveDisable, terminal)
+
+	// A dry run plans the changes without making them
+	report, err := reconciler.Reconcile(ctx, true)
+	require.NoError(t, err)
+	assert.True(t, report.DryRun)
+	assert.Equal(t, map[int]string{42: ActionUpdate, 43: ActionCreate}, actionTypes(report))
+	assert.Equal(t, 1, report.Unmanaged)
+	assert.Zero(t, terminal.writes)
+	assert.Empty(t, store.assignments)
+
+	report, err = reconciler.Reconcile(ctx, false)
+	require.NoError(t, err)
+	assert.Equal(t, 2, report.Applied)
+	assert.Equal(t, map[int]string{42: "user_1", 43: "user_2"}, store.assignments)
+	assert.Equal(t, "user_1", store.mappings["device_42"], "events from the terminal resolve to the member")
+
+	adopted := terminal.users[42]
+	assert.Equal(t, "Alex", adopted.Name)
+	assert.Equal(t, "100", adopted.CardNumber)
+	assert.Equal(t, "Sam", terminal.users[43].Name)
+	assert.Equal(t, admin, terminal.users[1], "unmanaged users are left alone")
+	assert.NotContains(t, terminal.users, 44, "inactive members are not created")
+
+	// Nothing left to do
+	report, err = reconciler.Reconcile(ctx, false)
+	require.NoError(t, err)
+	assert.Empty(t, report.Actions)
+}
+
+func TestReconciler_InactiveAndRemovedMembers(t *testing.T) {
+	ctx := context.Background()
+	front := newFakeTerminal("front",
+		biometric.DeviceUser{DeviceUserID: 10, Name: "Alex", Privilege: 2},
+		biometric.DeviceUser{DeviceUserID: 11, Name: "Sam"},
+		biometric.DeviceUser{DeviceUserID: 12, Name: "Jo"},
+	)
+	store := newMemoryStore(
+		member("user_1", "Alex", "suspended"),
+		member("user_2", "Sam", "cancelled"),
+	)
+	store.assignments = map[int]string{10: "user_1", 11: "user_2", 12: "user_3"}
+
+	report, err := newTestReconciler(store, InactiveDisable, front).Reconcile(ctx, false)
+	require.NoError(t, err)
+	assert.Equal(t, map[int]string{10: ActionDisable, 11: ActionDisable, 12: ActionDelete}, actionTypes(report))
+	assert.True(t, front.users[10].Disabled)
+	assert.Equal(t, 2, front.users[10].Privilege, "privileges set on the terminal are kept")
+	assert.NotContains(t, front.users, 12)
+	assert.NotContains(t, store.assignments, 12, "the ID of a removed member is released")
+	assert.Contains(t, store.assignments, 10, "inactive members keep their ID")
+
+	// Reactivated members are enabled again
+	store.members[0].Status = "active"
+	report, err = newTestReconciler(store, InactiveDelete, front).Reconcile(ctx, false)
+	require.NoError(t, err)
+	assert.Equal(t, map[int]string{10: ActionUpdate, 11: ActionDelete}, actionTypes(report))
+	assert.False(t, front.users[10].Disabled)
+	assert.NotContains(t, front.users, 11)
+}
+
+func TestReconciler_UnreachableTerminal(t *testing.T) {
+	ctx := context.Background()
+	front := newFakeTerminal("front", biometric.DeviceUser{DeviceUserID: 5, Name: "Jo"})
+	back := newFakeTerminal("back")
+	back.readErr = errors.New("connection refused")
+
+	store := newMemoryStore(member("user_1", "Alex", "active"))
+	store.assignments = map[int]string{5: "user_3"}
+
+	report, err := newTestReconciler(store, InactiveDisable, front, back).Reconcile(ctx, false)
+	require.NoError(t, err)
+	require.Len(t, report.Errors, 1)
+	assert.Contains(t, report.Errors[0], "back")
+	assert.NotContains(t, front.users, 5)
+	assert.Contains(t, front.users, 6)
+	assert.Contains(t, store.assignments, 5, "the ID is kept until every terminal has dropped the user")
+}
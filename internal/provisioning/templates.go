@@ -0,0 +1,312 @@
+package provisioning
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gym-door-bridge/internal/adapters/biometric"
+	"gym-door-bridge/internal/database"
+	"gym-door-bridge/internal/logging"
+)
+
+// TemplateTerminal is a biometric terminal whose fingerprint templates can be
+// backed up and written
+type TemplateTerminal interface {
+	Name() string
+	GetUsers() ([]biometric.DeviceUser, error)
+	GetTemplates() ([]biometric.FingerprintTemplate, error)
+	WriteTemplate(template biometric.FingerprintTemplate) error
+}
+
+// TemplateStore defines the database methods needed by the template syncer
+type TemplateStore interface {
+	ListBiometricTemplates() ([]database.BiometricTemplate, error)
+	SaveBiometricTemplate(template *database.BiometricTemplate) (bool, error)
+}
+
+// TemplateReport describes the outcome of a template sync. It never contains
+// template data.
+type TemplateReport struct {
+	StartedAt   time.Time `json:"startedAt"`
+	CompletedAt time.Time `json:"completedAt"`
+	Terminals   int       `json:"terminals"`
+	Stored      int       `json:"stored"`   // Templates held by the bridge after the sync
+	BackedUp    int       `json:"backedUp"` // Templates stored or updated from the terminals
+	Written     int       `json:"written"`  // Templates written to terminals missing them
+	Skipped     int       `json:"skipped"`  // Templates for users not on a terminal
+	Failed      int       `json:"failed"`
+	Errors      []string  `json:"errors,omitempty"`
+}
+
+// TemplateStats contains statistics about template syncs
+type TemplateStats struct {
+	TotalRuns     int64  `json:"totalRuns"`
+	TotalFailures int64  `json:"totalFailures"`
+	TotalBackedUp int64  `json:"totalBackedUp"`
+	TotalWritten  int64  `json:"totalWritten"`
+	LastRunAt     int64  `json:"lastRunAt"` // Unix timestamp
+	Stored        int    `json:"stored"`
+	LastError     string `json:"lastError,omitempty"`
+}
+
+// templateKey identifies a finger of a device user
+type templateKey struct {
+	deviceUserID int
+	fingerIndex  int
+}
+
+// TemplateSyncer backs up the fingerprint templates enrolled on the site's
+// terminals and writes them to the terminals that are missing them, so members
+// enrolled once can use every terminal and a replacement terminal can be
+// restored. Templates are only held encrypted by the store and are never
+// reported or sent to the platform.
+//
+// A stored template is only updated from the terminal it was first read from.
+// Templates already on a terminal are never overwritten.
+type TemplateSyncer struct {
+	interval  time.Duration
+	terminals TerminalSource
+	store     TemplateStore
+	logger    *logrus.Entry
+	runMu     sync.Mutex // Serializes scheduled and on-demand runs
+	stats     TemplateStats
+	mutex     sync.RWMutex
+}
+
+// NewTemplateSyncer creates a new template syncer
+func NewTemplateSyncer(interval time.Duration, terminals TerminalSource, store TemplateStore, logger *logrus.Logger) *TemplateSyncer {
+	if interval <= 0 {
+		interval = time.Hour
+	}
+
+	return &TemplateSyncer{
+		interval:  interval,
+		terminals: terminals,
+		store:     store,
+		logger:    logging.NewServiceLogger(logger, "template-sync"),
+	}
+}
+
+// Start syncs templates periodically until the context is cancelled
+func (s *TemplateSyncer) Start(ctx context.Context) {
+	s.logger.WithField("interval", s.interval).Info("Starting fingerprint template sync")
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.logger.Info("Fingerprint template sync stopped")
+			return
+		case <-ticker.C:
+			if _, err := s.Sync(ctx); err != nil {
+				s.logger.WithError(err).Warn("Fingerprint template sync failed")
+			}
+		}
+	}
+}
+
+// Sync backs up the templates of every terminal and writes stored templates to
+// the terminals whose users are missing them
+func (s *TemplateSyncer) Sync(ctx context.Context) (*TemplateReport, error) {
+	return s.run(ctx, "")
+}
+
+// Restore writes the stored templates to a single terminal, typically one that
+// replaced a failed device. The terminal's users must already exist.
+func (s *TemplateSyncer) Restore(ctx context.Context, terminalName string) (*TemplateReport, error) {
+	return s.run(ctx, terminalName)
+}
+
+// GetStats returns template sync statistics
+func (s *TemplateSyncer) GetStats() TemplateStats {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return s.stats
+}
+
+// run backs up templates from every terminal and writes the stored templates to
+// the target terminal, or to all terminals when target is empty
+func (s *TemplateSyncer) run(ctx context.Context, target string) (*TemplateReport, error) {
+	s.runMu.Lock()
+	defer s.runMu.Unlock()
+
+	report := &TemplateReport{StartedAt: time.Now().UTC()}
+
+	var terminals []TemplateTerminal
+	found := target == ""
+	for _, terminal := range s.terminals.Terminals() {
+		if templateTerminal, ok := terminal.(TemplateTerminal); ok {
+			terminals = append(terminals, templateTerminal)
+			found = found || terminal.Name() == target
+		}
+	}
+	if !found {
+		err := fmt.Errorf("terminal %s does not support template transfer", target)
+		s.recordFailure(err)
+		return nil, err
+	}
+	report.Terminals = len(terminals)
+
+	stored, err := s.loadStored()
+	if err != nil {
+		s.recordFailure(err)
+		return nil, err
+	}
+
+	// Back up first so templates enrolled since the last run reach the other terminals
+	onTerminal := make(map[string]map[templateKey]bool, len(terminals))
+	users := make(map[string]map[int]bool, len(terminals))
+	for _, terminal := range terminals {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		keys, userIDs, err := s.backup(terminal, stored, report)
+		if err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("%s: %v", terminal.Name(), err))
+			continue
+		}
+		onTerminal[terminal.Name()] = keys
+		users[terminal.Name()] = userIDs
+	}
+	report.Stored = len(stored)
+
+	for _, terminal := range terminals {
+		if target != "" && terminal.Name() != target {
+			continue
+		}
+		keys, ok := onTerminal[terminal.Name()]
+		if !ok {
+			continue // Unreadable, reported above
+		}
+
+		for key, template := range stored {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			if keys[key] {
+				continue
+			}
+			if !users[terminal.Name()][key.deviceUserID] {
+				report.Skipped++
+				continue
+			}
+
+			err := terminal.WriteTemplate(biometric.FingerprintTemplate{
+				DeviceUserID: template.DeviceUserID,
+				FingerIndex:  template.FingerIndex,
+				Flag:         template.Flag,
+				Data:         template.Data,
+			})
+			if err != nil {
+				report.Failed++
+				report.Errors = append(report.Errors, fmt.Sprintf("%s: %v", terminal.Name(), err))
+				continue
+			}
+			report.Written++
+		}
+	}
+
+	report.CompletedAt = time.Now().UTC()
+
+	s.logger.WithFields(logrus.Fields{
+		"target":   target,
+		"stored":   report.Stored,
+		"backedUp": report.BackedUp,
+		"written":  report.Written,
+		"skipped":  report.Skipped,
+		"failed":   report.Failed,
+	}).Info("Fingerprint template sync completed")
+
+	s.mutex.Lock()
+	s.stats.TotalRuns++
+	s.stats.TotalBackedUp += int64(report.BackedUp)
+	s.stats.TotalWritten += int64(report.Written)
+	s.stats.LastRunAt = report.CompletedAt.Unix()
+	s.stats.Stored = report.Stored
+	if len(report.Errors) > 0 {
+		s.stats.LastError = report.Errors[0]
+	} else {
+		s.stats.LastError = ""
+	}
+	s.mutex.Unlock()
+
+	return report, nil
+}
+
+// backup stores the templates read from a terminal and returns the fingers and
+// users present on it
+func (s *TemplateSyncer) backup(terminal TemplateTerminal, stored map[templateKey]*database.BiometricTemplate, report *TemplateReport) (map[templateKey]bool, map[int]bool, error) {
+	deviceUsers, err := terminal.GetUsers()
+	if err != nil {
+		return nil, nil, err
+	}
+	templates, err := terminal.GetTemplates()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	userIDs := make(map[int]bool, len(deviceUsers))
+	for _, user := range deviceUsers {
+		userIDs[user.DeviceUserID] = true
+	}
+
+	keys := make(map[templateKey]bool, len(templates))
+	for _, template := range templates {
+		key := templateKey{template.DeviceUserID, template.FingerIndex}
+		keys[key] = true
+
+		existing := stored[key]
+		if existing != nil && existing.SourceTerminal != terminal.Name() {
+			continue
+		}
+
+		record := &database.BiometricTemplate{
+			DeviceUserID:   template.DeviceUserID,
+			FingerIndex:    template.FingerIndex,
+			Flag:           template.Flag,
+			Data:           template.Data,
+			SourceTerminal: terminal.Name(),
+		}
+		changed, err := s.store.SaveBiometricTemplate(record)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to store template: %w", err)
+		}
+		if changed {
+			report.BackedUp++
+		}
+		stored[key] = record
+	}
+
+	return keys, userIDs, nil
+}
+
+// loadStored reads the stored templates keyed by user and finger
+func (s *TemplateSyncer) loadStored() (map[templateKey]*database.BiometricTemplate, error) {
+	templates, err := s.store.ListBiometricTemplates()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stored templates: %w", err)
+	}
+
+	stored := make(map[templateKey]*database.BiometricTemplate, len(templates))
+	for i := range templates {
+		template := &templates[i]
+		stored[templateKey{template.DeviceUserID, template.FingerIndex}] = template
+	}
+	return stored, nil
+}
+
+// recordFailure updates failure statistics
+func (s *TemplateSyncer) recordFailure(err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.stats.TotalFailures++
+	s.stats.LastError = err.Error()
+}
@@ -0,0 +1,125 @@
+package provisioning
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gym-door-bridge/internal/adapters/biometric"
+	"gym-door-bridge/internal/database"
+)
+
+func (t *fakeTerminal) GetTemplates() ([]biometric.FingerprintTemplate, error) {
+	if t.readErr != nil {
+		return nil, t.readErr
+	}
+	return t.templates, nil
+}
+
+func (t *fakeTerminal) WriteTemplate(template biometric.FingerprintTemplate) error {
+	if _, ok := t.users[template.DeviceUserID]; !ok {
+		return fmt.Errorf("user with device ID %d not found", template.DeviceUserID)
+	}
+	t.templates = append(t.templates, template)
+	return nil
+}
+
+func (t *fakeTerminal) template(deviceUserID, fingerIndex int) []byte {
+	for _, template := range t.templates {
+		if template.DeviceUserID == deviceUserID && template.FingerIndex == fingerIndex {
+			return template.Data
+		}
+	}
+	return nil
+}
+
+// memoryTemplateStore keeps stored templates in memory
+type memoryTemplateStore struct {
+	templates []database.BiometricTemplate
+}
+
+func (s *memoryTemplateStore) ListBiometricTemplates() ([]database.BiometricTemplate, error) {
+	return append([]database.BiometricTemplate(nil), s.templates...), nil
+}
+
+func (s *memoryTemplateStore) SaveBiometricTemplate(template *database.BiometricTemplate) (bool, error) {
+	for i, existing := range s.templates {
+		if existing.DeviceUserID == template.DeviceUserID && existing.FingerIndex == template.FingerIndex {
+			if bytes.Equal(existing.Data, template.Data) {
+				return false, nil
+			}
+			s.templates[i] = *template
+			return true, nil
+		}
+	}
+	s.templates = append(s.templates, *template)
+	return true, nil
+}
+
+func newTestTemplateSyncer(store TemplateStore, terminals ...Terminal) *TemplateSyncer {
+	logger := logrus.New()
+	logger.SetLevel(logrus.ErrorLevel)
+	return NewTemplateSyncer(0, fakeTerminals(terminals), store, logger)
+}
+
+func fingerprint(deviceUserID, fingerIndex int, data string) biometric.FingerprintTemplate {
+	return biometric.FingerprintTemplate{DeviceUserID: deviceUserID, FingerIndex: fingerIndex, Flag: 1, Data: []byte(data)}
+}
+
+func TestTemplateSyncer_CopiesBetweenTerminals(t *testing.T) {
+	ctx := context.Background()
+	alex := biometric.DeviceUser{DeviceUserID: 10, Name: "Alex"}
+	sam := biometric.DeviceUser{DeviceUserID: 11, Name: "Sam"}
+
+	front := newFakeTerminal("front", alex, sam)
+	front.templates = []biometric.FingerprintTemplate{fingerprint(10, 0, "alex-0"), fingerprint(11, 6, "sam-6")}
+	back := newFakeTerminal("back", alex)
+	back.templates = []biometric.FingerprintTemplate{fingerprint(10, 1, "alex-1")}
+	store := &memoryTemplateStore{}
+
+	report, err := newTestTemplateSyncer(store, front, back).Sync(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 3, report.BackedUp)
+	assert.Equal(t, 3, report.Stored)
+	assert.Equal(t, 2, report.Written)
+	assert.Equal(t, 1, report.Skipped, "Sam is not on the back terminal")
+	assert.Equal(t, []byte("alex-0"), back.template(10, 0))
+	assert.Equal(t, []byte("alex-1"), front.template(10, 1))
+	assert.Nil(t, back.template(11, 6))
+
+	// A re-enrollment on the source terminal updates the backup but templates
+	// already on other terminals are not overwritten
+	front.templates[0].Data = []byte("alex-0-new")
+	report, err = newTestTemplateSyncer(store, front, back).Sync(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.BackedUp)
+	assert.Zero(t, report.Written)
+	assert.Equal(t, []byte("alex-0"), back.template(10, 0))
+}
+
+func TestTemplateSyncer_RestoresReplacementTerminal(t *testing.T) {
+	ctx := context.Background()
+	alex := biometric.DeviceUser{DeviceUserID: 10, Name: "Alex"}
+	store := &memoryTemplateStore{templates: []database.BiometricTemplate{
+		{DeviceUserID: 10, FingerIndex: 0, Flag: 1, Data: []byte("alex-0"), SourceTerminal: "front"},
+	}}
+
+	// The failed terminal was swapped for a new one with the users provisioned but no templates
+	front := newFakeTerminal("front", alex)
+	other := newFakeTerminal("side", alex)
+	other.readErr = fmt.Errorf("connection refused")
+
+	report, err := newTestTemplateSyncer(store, front, other).Restore(ctx, "front")
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Written)
+	assert.Len(t, report.Errors, 1)
+	assert.Equal(t, []byte("alex-0"), front.template(10, 0))
+
+	_, err = newTestTemplateSyncer(store, front).Restore(ctx, "missing")
+	assert.Error(t, err)
+}
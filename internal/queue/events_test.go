@@ -716,4 +716,59 @@ func TestSQLiteQueueManager_ClearEvents_EmptyDatabase(t *testing.T) {
 	
 	assert.NoError(t, err)
 	assert.Equal(t, int64(0), deletedCount)
-}
\ No newline at end of file
+}
+func TestSQLiteQueueManager_EventFidelity(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	
+	queueManager := NewSQLiteQueueManager(db)
+	ctx := context.Background()
+	
+	config := QueueConfig{
+		MaxSize:         1000,
+		BatchSize:       10,
+		RetryInterval:   30 * time.Second,
+		MaxRetries:      3,
+		RetentionPolicy: RetentionPolicyFIFO,
+	}
+	require.NoError(t, queueManager.Initialize(ctx, config))
+	
+	event := types.StandardEvent{
+		EventID:        "event1",
+		ExternalUserID: "user1",
+		InternalUserID: "member-42",
+		Timestamp:      time.Now(),
+		EventType:      types.EventTypeEntry,
+		DeviceID:       "device1",
+		DoorID:         "front",
+		AccessReason:   "allowed",
+		AdapterName:    "zkteco",
+		VerifyMode:     types.VerifyModeFingerprint,
+		RawData:        map[string]interface{}{"work_code": "0"},
+	}
+	require.NoError(t, queueManager.Enqueue(ctx, event))
+	
+	// The internal user ID is encrypted at rest
+	var stored string
+	require.NoError(t, db.QueryRow("SELECT internal_user_id FROM event_queue").Scan(&stored))
+	assert.NotEqual(t, "member-42", stored)
+	
+	assertFidelity := func(got types.StandardEvent) {
+		assert.Equal(t, "member-42", got.InternalUserID)
+		assert.Equal(t, "front", got.DoorID)
+		assert.Equal(t, "allowed", got.AccessReason)
+		assert.Equal(t, "zkteco", got.AdapterName)
+		assert.Equal(t, types.VerifyModeFingerprint, got.VerifyMode)
+		assert.Equal(t, event.RawData, got.RawData)
+	}
+	
+	pending, err := queueManager.GetPendingEvents(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assertFidelity(pending[0].Event)
+	
+	events, total, err := queueManager.QueryEvents(ctx, EventQueryFilter{UserID: "user1", Limit: 10, SortBy: "timestamp", SortOrder: "desc"})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), total)
+	assertFidelity(events[0].Event)
+}
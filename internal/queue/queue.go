@@ -18,7 +18,7 @@ type QueueConfig struct {
 	BatchSize       int           `json:"batchSize"`       // Number of events to send in each batch
 	RetryInterval   time.Duration `json:"retryInterval"`   // Time between retry attempts
 	MaxRetries      int           `json:"maxRetries"`      // Maximum number of retry attempts
-	EncryptionKey   string        `json:"encryptionKey"`   // Key for encrypting sensitive payloads
+	EncryptionKey   string        `json:"encryptionKey"`   // Unused; user IDs and payloads are encrypted with the database's managed keys
 	RetentionPolicy string        `json:"retentionPolicy"` // "fifo" or "priority"
 }
 
@@ -39,11 +39,12 @@ type EventQueryFilter struct {
 	EndTime      *time.Time `json:"endTime,omitempty"`
 	EventType    string     `json:"eventType,omitempty"`
 	UserID       string     `json:"userId,omitempty"`
+	DoorID       string     `json:"doorId,omitempty"`
 	IsSimulated  *bool      `json:"isSimulated,omitempty"`
 	SentStatus   string     `json:"sentStatus,omitempty"` // "all", "sent", "pending", "failed"
 	Limit        int        `json:"limit"`
 	Offset       int        `json:"offset"`
-	SortBy       string     `json:"sortBy"`     // "timestamp", "event_type", "external_user_id"
+	SortBy       string     `json:"sortBy"`     // "timestamp", "event_type", "external_user_id" (groups events by user; user IDs are encrypted)
 	SortOrder    string     `json:"sortOrder"`  // "asc", "desc"
 }
 
@@ -134,7 +135,9 @@ type QueueManager interface {
 	Close(ctx context.Context) error
 }
 
-// RetentionPolicy constants
+// RetentionPolicy constants. When the queue is full, FIFO evicts the oldest
+// events; priority evicts simulated events first, then entry/exit, then
+// denied, and door alarms last, oldest first within each class.
 const (
 	RetentionPolicyFIFO     = "fifo"
 	RetentionPolicyPriority = "priority"
@@ -172,8 +175,12 @@ func (q *sqliteQueueManager) Initialize(ctx context.Context, config QueueConfig)
 	}
 	
 	// Set default retention policy if not specified
-	if config.RetentionPolicy == "" {
+	switch config.RetentionPolicy {
+	case "":
 		q.config.RetentionPolicy = RetentionPolicyFIFO
+	case RetentionPolicyFIFO, RetentionPolicyPriority:
+	default:
+		return fmt.Errorf("retentionPolicy must be %q or %q, got %q", RetentionPolicyFIFO, RetentionPolicyPriority, config.RetentionPolicy)
 	}
 	
 	return nil
@@ -292,6 +299,14 @@ func (q *sqliteQueueManager) GetStats(ctx context.Context) (QueueStats, error) {
 	stats.QueueDepth = depth
 	stats.PendingEvents = depth // All unsent events are pending
 	
+	oldest, err := q.db.GetOldestPendingEventTime()
+	if err != nil {
+		return stats, fmt.Errorf("failed to get oldest pending event: %w", err)
+	}
+	if oldest != nil {
+		stats.OldestEventTime = *oldest
+	}
+	
 	// TODO: Implement additional statistics
 	// This would require additional database queries or schema changes
 	// For now, we provide basic stats
@@ -348,7 +363,11 @@ func (q *sqliteQueueManager) evictOldestEvents(ctx context.Context) error {
 		return nil
 	}
 	
-	// Use a simpler approach: delete the oldest events directly
+	// The priority policy keeps alarms and member entries longest; both
+	// policies record what was evicted for the platform
+	if q.config.RetentionPolicy == RetentionPolicyPriority {
+		return q.db.EvictEventsByPriority(evictCount)
+	}
 	return q.db.EvictOldestEventsDirect(evictCount)
 }
 
@@ -371,6 +390,11 @@ func (q *sqliteQueueManager) standardEventToDBEvent(event types.StandardEvent) (
 		EventType:      event.EventType,
 		IsSimulated:    event.IsSimulated,
 		DeviceID:       event.DeviceID,
+		DoorID:         event.DoorID,
+		InternalUserID: event.InternalUserID,
+		AdapterName:    event.AdapterName,
+		VerifyMode:     event.VerifyMode,
+		AccessReason:   event.AccessReason,
 		RawData:        rawDataJSON,
 		CreatedAt:      time.Now(),
 		RetryCount:     0,
@@ -387,6 +411,11 @@ func (q *sqliteQueueManager) dbEventToQueuedEvent(dbEvent *database.EventQueue)
 		EventType:      dbEvent.EventType,
 		IsSimulated:    dbEvent.IsSimulated,
 		DeviceID:       dbEvent.DeviceID,
+		DoorID:         dbEvent.DoorID,
+		InternalUserID: dbEvent.InternalUserID,
+		AccessReason:   dbEvent.AccessReason,
+		AdapterName:    dbEvent.AdapterName,
+		VerifyMode:     dbEvent.VerifyMode,
 	}
 	
 	// Deserialize raw data if present
@@ -404,7 +433,7 @@ func (q *sqliteQueueManager) dbEventToQueuedEvent(dbEvent *database.EventQueue)
 		CreatedAt:   dbEvent.CreatedAt,
 		SentAt:      dbEvent.SentAt,
 		RetryCount:  dbEvent.RetryCount,
-		IsEncrypted: dbEvent.RawData != "", // Assume encrypted if raw data exists
+		IsEncrypted: dbEvent.ExternalUserHash != "", // Set once the user ID and payload are stored encrypted
 	}, nil
 }
 
@@ -490,8 +519,14 @@ func (q *sqliteQueueManager) QueryEvents(ctx context.Context, filter EventQueryF
 	}
 	
 	if filter.UserID != "" {
-		conditions = append(conditions, "external_user_id = ?")
-		args = append(args, filter.UserID)
+		// User IDs are encrypted, so match on their blind index
+		conditions = append(conditions, "external_user_hash = ?")
+		args = append(args, q.db.BlindIndex(filter.UserID))
+	}
+	
+	if filter.DoorID != "" {
+		conditions = append(conditions, "door_id = ?")
+		args = append(args, filter.DoorID)
 	}
 	
 	if filter.IsSimulated != nil {
@@ -525,13 +560,19 @@ func (q *sqliteQueueManager) QueryEvents(ctx context.Context, filter EventQueryF
 		return nil, 0, fmt.Errorf("failed to get event count: %w", err)
 	}
 	
-	// Build ORDER BY clause
-	orderBy := fmt.Sprintf("ORDER BY %s %s", filter.SortBy, strings.ToUpper(filter.SortOrder))
+	// Build ORDER BY clause. Encrypted user IDs can't be ordered, so sorting by
+	// user groups each user's events together instead.
+	sortBy := filter.SortBy
+	if sortBy == "external_user_id" {
+		sortBy = "external_user_hash"
+	}
+	orderBy := fmt.Sprintf("ORDER BY %s %s", sortBy, strings.ToUpper(filter.SortOrder))
 	
 	// Build main query with pagination
 	query := fmt.Sprintf(`
-		SELECT id, event_id, external_user_id, timestamp, event_type, is_simulated, 
-		       device_id, raw_data, created_at, sent_at, retry_count
+		SELECT id, event_id, external_user_id, external_user_hash, timestamp, event_type, is_simulated, 
+		       device_id, door_id, internal_user_id, adapter_name, verify_mode, access_reason, schema_version,
+		       raw_data, created_at, sent_at, retry_count
 		FROM event_queue %s %s
 		LIMIT ? OFFSET ?
 	`, whereClause, orderBy)
@@ -554,10 +595,17 @@ func (q *sqliteQueueManager) QueryEvents(ctx context.Context, filter EventQueryF
 			&dbEvent.ID,
 			&dbEvent.EventID,
 			&dbEvent.ExternalUserID,
+			&dbEvent.ExternalUserHash,
 			&dbEvent.Timestamp,
 			&dbEvent.EventType,
 			&dbEvent.IsSimulated,
 			&dbEvent.DeviceID,
+			&dbEvent.DoorID,
+			&dbEvent.InternalUserID,
+			&dbEvent.AdapterName,
+			&dbEvent.VerifyMode,
+			&dbEvent.AccessReason,
+			&dbEvent.SchemaVersion,
 			&rawData,
 			&dbEvent.CreatedAt,
 			&sentAt,
@@ -567,12 +615,13 @@ func (q *sqliteQueueManager) QueryEvents(ctx context.Context, filter EventQueryF
 			return nil, 0, fmt.Errorf("failed to scan event row: %w", err)
 		}
 		
-		if rawData.Valid {
-			dbEvent.RawData = rawData.String
-		}
+		dbEvent.RawData = rawData.String
 		if sentAt.Valid {
 			dbEvent.SentAt = &sentAt.Time
 		}
+		if err := q.db.DecryptEvent(&dbEvent); err != nil {
+			return nil, 0, err
+		}
 		
 		queuedEvent, err := q.dbEventToQueuedEvent(&dbEvent)
 		if err != nil {
@@ -636,7 +685,7 @@ func (q *sqliteQueueManager) GetEventStats(ctx context.Context) (EventStatistics
 	}
 	
 	// Get unique users count
-	err = q.db.QueryRow("SELECT COUNT(DISTINCT external_user_id) FROM event_queue").Scan(&stats.UniqueUsers)
+	err = q.db.QueryRow("SELECT COUNT(DISTINCT external_user_hash) FROM event_queue").Scan(&stats.UniqueUsers)
 	if err != nil {
 		return stats, fmt.Errorf("failed to get unique users count: %w", err)
 	}
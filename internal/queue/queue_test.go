@@ -429,6 +429,98 @@ func TestQueueManager_FIFOEviction(t *testing.T) {
 	}
 }
 
+func TestQueueManager_PriorityEviction(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	
+	qm := NewSQLiteQueueManager(db)
+	ctx := context.Background()
+	
+	config := QueueConfig{
+		MaxSize:         2,
+		BatchSize:       50,
+		RetryInterval:   30 * time.Second,
+		MaxRetries:      5,
+		RetentionPolicy: RetentionPolicyPriority,
+	}
+	
+	err := qm.Initialize(ctx, config)
+	if err != nil {
+		t.Fatalf("Failed to initialize queue manager: %v", err)
+	}
+	
+	// The alarm is the oldest event but outranks the entry
+	events := []types.StandardEvent{
+		{
+			EventID:   "alarm",
+			Timestamp: time.Now().Add(-2 * time.Minute),
+			EventType: types.EventTypeDoorForcedOpen,
+			DeviceID:  "device1",
+		},
+		{
+			EventID:        "entry",
+			ExternalUserID: "user1",
+			Timestamp:      time.Now().Add(-1 * time.Minute),
+			EventType:      types.EventTypeEntry,
+			DeviceID:       "device1",
+		},
+		{
+			EventID:        "denied",
+			ExternalUserID: "user2",
+			Timestamp:      time.Now(),
+			EventType:      types.EventTypeDenied,
+			DeviceID:       "device1",
+		},
+	}
+	
+	for _, event := range events {
+		if err := qm.Enqueue(ctx, event); err != nil {
+			t.Fatalf("Failed to enqueue event %s: %v", event.EventID, err)
+		}
+	}
+	
+	pendingEvents, err := qm.GetPendingEvents(ctx, 10)
+	if err != nil {
+		t.Fatalf("Failed to get pending events: %v", err)
+	}
+	
+	eventIDs := make(map[string]bool)
+	for _, event := range pendingEvents {
+		eventIDs[event.Event.EventID] = true
+	}
+	
+	if len(pendingEvents) != 2 || !eventIDs["alarm"] || !eventIDs["denied"] {
+		t.Errorf("Expected the entry event to be evicted, got %v", eventIDs)
+	}
+	
+	evictions, err := db.GetEventEvictions()
+	if err != nil {
+		t.Fatalf("Failed to get evictions: %v", err)
+	}
+	if len(evictions) != 1 || evictions[0].PriorityClass != database.PriorityClassAccess || evictions[0].EventCount != 1 {
+		t.Errorf("Expected one access event eviction to be recorded, got %+v", evictions)
+	}
+}
+
+func TestQueueManager_InvalidRetentionPolicy(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	
+	qm := NewSQLiteQueueManager(db)
+	
+	config := QueueConfig{
+		MaxSize:         100,
+		BatchSize:       50,
+		RetryInterval:   30 * time.Second,
+		MaxRetries:      5,
+		RetentionPolicy: "random",
+	}
+	
+	if err := qm.Initialize(context.Background(), config); err == nil {
+		t.Error("Expected an error for an unknown retention policy")
+	}
+}
+
 func TestQueueManager_GetStats(t *testing.T) {
 	db := setupTestDB(t)
 	defer db.Close()
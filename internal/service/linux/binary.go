@@ -0,0 +1,194 @@
+package linux
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// The service runs the bridge through a symlink in the data directory that
+// points at one of the installed versions:
+//
+//	/var/lib/gym-door-bridge/bin/gym-door-bridge -> versions/1.2.0/gym-door-bridge
+//	/var/lib/gym-door-bridge/bin/versions/1.2.0/gym-door-bridge
+//
+// The data directory is owned by the service user and is one of the unit's
+// ReadWritePaths, so the updater installs a release, or rolls it back, by
+// replacing the symlink without writing outside the service's sandbox.
+const (
+	binDirName      = "bin"
+	versionsDirName = "versions"
+
+	// InitialVersion names the version directory of the binary the service was installed from
+	InitialVersion = "installed"
+)
+
+// BinaryDir returns the directory holding the service's versioned binaries
+func BinaryDir(config *ServiceConfig) string {
+	return filepath.Join(config.DataDirectory, binDirName)
+}
+
+// BinaryLink returns the path of the symlink the service runs
+func BinaryLink(config *ServiceConfig, execPath string) string {
+	return filepath.Join(BinaryDir(config), filepath.Base(execPath))
+}
+
+// installServiceBinary installs the binary the service is installed from as
+// its first version and hands it to the service user, which replaces it on
+// updates
+func installServiceBinary(config *ServiceConfig, execPath string) error {
+	uid, gid, err := lookupServiceUser(config)
+	if err != nil {
+		return err
+	}
+
+	binDir := BinaryDir(config)
+	if _, err := InstallBinary(binDir, filepath.Base(execPath), InitialVersion, execPath); err != nil {
+		return fmt.Errorf("failed to install binary: %w", err)
+	}
+	if err := chownTree(binDir, uid, gid); err != nil {
+		return fmt.Errorf("failed to set owner of installed binary: %w", err)
+	}
+	return nil
+}
+
+// InstallBinary copies a binary into its version directory under binDir as
+// name and points the symlink of that name at it. Versions other than the new
+// one and the one it replaces, which a rollback returns to, are removed. It
+// returns the path of the installed binary.
+func InstallBinary(binDir, name, version, src string) (string, error) {
+	if version == "" || version == "." || version == ".." || filepath.Base(version) != version {
+		return "", fmt.Errorf("invalid version directory name: %q", version)
+	}
+
+	versionDir := filepath.Join(binDir, versionsDirName, version)
+	if err := os.MkdirAll(versionDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create version directory: %w", err)
+	}
+
+	// Write through a temporary file in the same directory, so the rename
+	// never crosses file systems and a running binary is never overwritten
+	binaryPath := filepath.Join(versionDir, name)
+	tmpPath := binaryPath + ".tmp"
+	if err := copyExecutable(src, tmpPath); err != nil {
+		os.Remove(tmpPath)
+		return "", err
+	}
+	if err := os.Rename(tmpPath, binaryPath); err != nil {
+		os.Remove(tmpPath)
+		return "", fmt.Errorf("failed to install binary: %w", err)
+	}
+
+	previous := linkedBinary(binDir, name)
+	if err := ActivateBinary(binDir, binaryPath); err != nil {
+		return "", err
+	}
+
+	pruneVersions(binDir, binaryPath, previous)
+	return binaryPath, nil
+}
+
+// ActivateBinary points the symlink in binDir at an installed binary. The
+// symlink is replaced with a rename, so the service never finds it missing.
+func ActivateBinary(binDir, binaryPath string) error {
+	target, err := filepath.Rel(binDir, binaryPath)
+	if err != nil {
+		return fmt.Errorf("failed to resolve binary path: %w", err)
+	}
+
+	link := filepath.Join(binDir, filepath.Base(binaryPath))
+	tmpLink := filepath.Join(binDir, "."+filepath.Base(binaryPath)+".tmp")
+	os.Remove(tmpLink)
+	if err := os.Symlink(target, tmpLink); err != nil {
+		return fmt.Errorf("failed to create binary link: %w", err)
+	}
+	if err := os.Rename(tmpLink, link); err != nil {
+		os.Remove(tmpLink)
+		return fmt.Errorf("failed to switch binary link: %w", err)
+	}
+	return nil
+}
+
+// InstalledBinaryDir returns the directory holding the symlink when the
+// executable is a version installed by InstallBinary
+func InstalledBinaryDir(execPath string) (string, bool) {
+	resolved, err := filepath.EvalSymlinks(execPath)
+	if err != nil {
+		return "", false
+	}
+
+	versionsDir := filepath.Dir(filepath.Dir(resolved))
+	if filepath.Base(versionsDir) != versionsDirName {
+		return "", false
+	}
+
+	binDir := filepath.Dir(versionsDir)
+	info, err := os.Lstat(filepath.Join(binDir, filepath.Base(resolved)))
+	if err != nil || info.Mode()&os.ModeSymlink == 0 {
+		return "", false
+	}
+	return binDir, true
+}
+
+// linkedBinary returns the binary the symlink in binDir points at, or "" when
+// there is none
+func linkedBinary(binDir, name string) string {
+	target, err := os.Readlink(filepath.Join(binDir, name))
+	if err != nil {
+		return ""
+	}
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(binDir, target)
+	}
+	return filepath.Clean(target)
+}
+
+// pruneVersions removes installed versions other than the given binaries'
+func pruneVersions(binDir string, keep ...string) {
+	keepDirs := make(map[string]bool, len(keep))
+	for _, binaryPath := range keep {
+		if binaryPath != "" {
+			keepDirs[filepath.Dir(binaryPath)] = true
+		}
+	}
+
+	versionsDir := filepath.Join(binDir, versionsDirName)
+	entries, err := os.ReadDir(versionsDir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		dir := filepath.Join(versionsDir, entry.Name())
+		if entry.IsDir() && !keepDirs[dir] {
+			os.RemoveAll(dir)
+		}
+	}
+}
+
+// copyExecutable copies a binary and makes the copy executable
+func copyExecutable(src, dst string) error {
+	sourceFile, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("failed to open binary: %w", err)
+	}
+	defer sourceFile.Close()
+
+	destFile, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0755)
+	if err != nil {
+		return fmt.Errorf("failed to create binary: %w", err)
+	}
+
+	if _, err := io.Copy(destFile, sourceFile); err != nil {
+		destFile.Close()
+		return fmt.Errorf("failed to copy binary: %w", err)
+	}
+	if err := destFile.Sync(); err != nil {
+		destFile.Close()
+		return fmt.Errorf("failed to flush binary: %w", err)
+	}
+	if err := destFile.Close(); err != nil {
+		return fmt.Errorf("failed to write binary: %w", err)
+	}
+	return os.Chmod(dst, 0755)
+}
@@ -0,0 +1,66 @@
+package linux
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstallBinary(t *testing.T) {
+	binDir := filepath.Join(t.TempDir(), "bin")
+	link := filepath.Join(binDir, "gym-door-bridge")
+
+	install := func(version, content string) string {
+		t.Helper()
+		src := filepath.Join(t.TempDir(), "bridge_"+version)
+		require.NoError(t, os.WriteFile(src, []byte(content), 0644))
+		binaryPath, err := InstallBinary(binDir, "gym-door-bridge", version, src)
+		require.NoError(t, err)
+		return binaryPath
+	}
+	linked := func() string {
+		t.Helper()
+		content, err := os.ReadFile(link)
+		require.NoError(t, err)
+		return string(content)
+	}
+
+	first := install(InitialVersion, "first")
+	assert.Equal(t, "first", linked())
+	info, err := os.Stat(first)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0755), info.Mode().Perm())
+
+	dir, ok := InstalledBinaryDir(first)
+	assert.True(t, ok)
+	assert.Equal(t, binDir, dir)
+	dir, ok = InstalledBinaryDir(link)
+	assert.True(t, ok, "the symlink resolves to an installed version")
+	assert.Equal(t, binDir, dir)
+
+	second := install("1.1.0", "second")
+	assert.Equal(t, "second", linked())
+
+	// Switching back needs the version that was replaced
+	require.NoError(t, ActivateBinary(binDir, first))
+	assert.Equal(t, "first", linked())
+	require.NoError(t, ActivateBinary(binDir, second))
+
+	// Only the new version and the one it replaces are kept
+	install("1.2.0", "third")
+	assert.Equal(t, "third", linked())
+	assert.FileExists(t, second)
+	assert.NoFileExists(t, first)
+
+	_, err = InstallBinary(binDir, "gym-door-bridge", "../escape", first)
+	assert.Error(t, err)
+
+	// A binary outside the layout is not a versioned install
+	plain := filepath.Join(t.TempDir(), "gym-door-bridge")
+	require.NoError(t, os.WriteFile(plain, []byte("plain"), 0755))
+	_, ok = InstalledBinaryDir(plain)
+	assert.False(t, ok)
+}
@@ -0,0 +1,247 @@
+package linux
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/spf13/cobra"
+)
+
+// AddServiceCommands adds systemd service management commands to the root command
+func AddServiceCommands(rootCmd *cobra.Command) {
+	if runtime.GOOS != "linux" {
+		return // Only add systemd service commands on Linux
+	}
+
+	serviceCmd := &cobra.Command{
+		Use:   "service",
+		Short: "systemd service management commands",
+		Long:  "Manage the Gym Door Bridge as a systemd service",
+	}
+
+	// Install command
+	installCmd := &cobra.Command{
+		Use:   "install",
+		Short: "Install the bridge as a systemd service",
+		Long:  "Install the Gym Door Bridge as a hardened systemd service running as a dedicated user, with automatic startup",
+		RunE:  runInstallService,
+	}
+
+	// Uninstall command
+	uninstallCmd := &cobra.Command{
+		Use:   "uninstall",
+		Short: "Uninstall the bridge systemd service",
+		Long:  "Remove the Gym Door Bridge systemd service",
+		RunE:  runUninstallService,
+	}
+
+	// Start command
+	startCmd := &cobra.Command{
+		Use:   "start",
+		Short: "Start the bridge systemd service",
+		Long:  "Start the Gym Door Bridge systemd service",
+		RunE:  runStartService,
+	}
+
+	// Stop command
+	stopCmd := &cobra.Command{
+		Use:   "stop",
+		Short: "Stop the bridge systemd service",
+		Long:  "Stop the Gym Door Bridge systemd service",
+		RunE:  runStopService,
+	}
+
+	// Restart command
+	restartCmd := &cobra.Command{
+		Use:   "restart",
+		Short: "Restart the bridge systemd service",
+		Long:  "Restart the Gym Door Bridge systemd service",
+		RunE:  runRestartService,
+	}
+
+	// Status command
+	statusCmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show bridge systemd service status",
+		Long:  "Display the current status of the Gym Door Bridge systemd service",
+		RunE:  runServiceStatus,
+	}
+
+	// Add flags for install command
+	defaults := DefaultServiceConfig()
+	installCmd.Flags().String("config", defaults.ConfigPath, "Path to configuration file")
+	installCmd.Flags().String("log-level", defaults.LogLevel, "Log level (debug, info, warn, error)")
+	installCmd.Flags().String("data-dir", defaults.DataDirectory, "Data directory path")
+	installCmd.Flags().String("user", defaults.User, "User account the service runs as (created if missing)")
+	installCmd.Flags().Int("watchdog-sec", defaults.WatchdogSec, "Watchdog timeout in seconds (0 disables)")
+
+	// Add commands to service command
+	serviceCmd.AddCommand(installCmd, uninstallCmd, startCmd, stopCmd, restartCmd, statusCmd)
+
+	// Add service command to root
+	rootCmd.AddCommand(serviceCmd)
+}
+
+func runInstallService(cmd *cobra.Command, args []string) error {
+	// Check if running as root
+	if !isRunningAsRoot() {
+		return fmt.Errorf("installing systemd service requires root privileges (use sudo)")
+	}
+
+	// Get executable path
+	execPath, err := GetExecutablePath()
+	if err != nil {
+		return fmt.Errorf("failed to get executable path: %w", err)
+	}
+
+	// Create service manager
+	sm, err := NewServiceManager()
+	if err != nil {
+		return fmt.Errorf("failed to create service manager: %w", err)
+	}
+
+	// Check if service is already installed
+	installed, err := sm.IsServiceInstalled()
+	if err != nil {
+		return fmt.Errorf("failed to check service installation status: %w", err)
+	}
+
+	if installed {
+		return fmt.Errorf("service is already installed")
+	}
+
+	// Build service configuration from the flags
+	config := DefaultServiceConfig()
+	config.ConfigPath, _ = cmd.Flags().GetString("config")
+	config.LogLevel, _ = cmd.Flags().GetString("log-level")
+	config.DataDirectory, _ = cmd.Flags().GetString("data-dir")
+	config.User, _ = cmd.Flags().GetString("user")
+	config.Group = config.User
+	config.WatchdogSec, _ = cmd.Flags().GetInt("watchdog-sec")
+
+	// Validate configuration
+	if err := ValidateServiceConfig(config); err != nil {
+		return fmt.Errorf("invalid service configuration: %w", err)
+	}
+
+	// Install the service
+	if err := sm.InstallService(execPath, config); err != nil {
+		return fmt.Errorf("failed to install service: %w", err)
+	}
+
+	fmt.Printf("Service installed successfully!\n")
+	fmt.Printf("Configuration file: %s\n", config.ConfigPath)
+	fmt.Printf("Data directory: %s\n", config.DataDirectory)
+	fmt.Printf("Service user: %s\n", config.User)
+	fmt.Printf("\nThe service will start automatically on boot.\n")
+	fmt.Printf("Use 'gym-door-bridge service start' to start the service now.\n")
+	fmt.Printf("Use 'journalctl -u %s' to view its logs.\n", ServiceName)
+
+	return nil
+}
+
+func runUninstallService(cmd *cobra.Command, args []string) error {
+	// Check if running as root
+	if !isRunningAsRoot() {
+		return fmt.Errorf("uninstalling systemd service requires root privileges (use sudo)")
+	}
+
+	// Create service manager
+	sm, err := NewServiceManager()
+	if err != nil {
+		return fmt.Errorf("failed to create service manager: %w", err)
+	}
+
+	// Check if service is installed
+	installed, err := sm.IsServiceInstalled()
+	if err != nil {
+		return fmt.Errorf("failed to check service installation status: %w", err)
+	}
+
+	if !installed {
+		return fmt.Errorf("service is not installed")
+	}
+
+	// Uninstall the service
+	if err := sm.UninstallService(); err != nil {
+		return fmt.Errorf("failed to uninstall service: %w", err)
+	}
+
+	defaults := DefaultServiceConfig()
+	fmt.Printf("\nNote: Configuration files, data and the service user have been preserved.\n")
+	fmt.Printf("To remove them manually:\n")
+	fmt.Printf("  sudo rm -rf %s\n", defaults.DataDirectory)
+	fmt.Printf("  sudo rm -rf %s\n", filepath.Dir(defaults.ConfigPath))
+	fmt.Printf("  sudo userdel %s\n", defaults.User)
+
+	return nil
+}
+
+func runStartService(cmd *cobra.Command, args []string) error {
+	// Check if running as root
+	if !isRunningAsRoot() {
+		return fmt.Errorf("managing systemd service requires root privileges (use sudo)")
+	}
+
+	sm, err := NewServiceManager()
+	if err != nil {
+		return fmt.Errorf("failed to create service manager: %w", err)
+	}
+
+	return sm.StartService()
+}
+
+func runStopService(cmd *cobra.Command, args []string) error {
+	// Check if running as root
+	if !isRunningAsRoot() {
+		return fmt.Errorf("managing systemd service requires root privileges (use sudo)")
+	}
+
+	sm, err := NewServiceManager()
+	if err != nil {
+		return fmt.Errorf("failed to create service manager: %w", err)
+	}
+
+	return sm.StopService()
+}
+
+func runRestartService(cmd *cobra.Command, args []string) error {
+	// Check if running as root
+	if !isRunningAsRoot() {
+		return fmt.Errorf("managing systemd service requires root privileges (use sudo)")
+	}
+
+	sm, err := NewServiceManager()
+	if err != nil {
+		return fmt.Errorf("failed to create service manager: %w", err)
+	}
+
+	return sm.RestartService()
+}
+
+func runServiceStatus(cmd *cobra.Command, args []string) error {
+	sm, err := NewServiceManager()
+	if err != nil {
+		return fmt.Errorf("failed to create service manager: %w", err)
+	}
+
+	status, err := sm.GetServiceStatus()
+	if err != nil {
+		return fmt.Errorf("failed to get service status: %w", err)
+	}
+
+	fmt.Printf("Service Name: %s\n", ServiceDisplayName)
+	fmt.Printf("Service Status: %s\n", status)
+	if status != "Not Installed" {
+		fmt.Printf("\nUse 'systemctl status %s' for details.\n", ServiceName)
+	}
+
+	return nil
+}
+
+// isRunningAsRoot checks if the current process is running with root privileges
+func isRunningAsRoot() bool {
+	return os.Geteuid() == 0
+}
@@ -0,0 +1,178 @@
+package linux
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ServiceConfig represents systemd service configuration
+type ServiceConfig struct {
+	ConfigPath    string
+	LogLevel      string
+	DataDirectory string
+	User          string // Dedicated account the service runs as
+	Group         string
+	RestartSec    int // Delay before systemd restarts a failed service
+	WatchdogSec   int // Keep-alive timeout; 0 disables the watchdog
+}
+
+// DefaultServiceConfig returns default service configuration for Linux
+func DefaultServiceConfig() *ServiceConfig {
+	return &ServiceConfig{
+		ConfigPath:    "/etc/gym-door-bridge/config.yaml",
+		LogLevel:      "info",
+		DataDirectory: "/var/lib/gym-door-bridge",
+		User:          "gymdoorbridge",
+		Group:         "gymdoorbridge",
+		RestartSec:    5,
+		WatchdogSec:   60,
+	}
+}
+
+// ValidateServiceConfig validates service configuration
+func ValidateServiceConfig(config *ServiceConfig) error {
+	if config.ConfigPath == "" {
+		return fmt.Errorf("config path cannot be empty")
+	}
+
+	if !filepath.IsAbs(config.ConfigPath) || !filepath.IsAbs(config.DataDirectory) {
+		return fmt.Errorf("config path and data directory must be absolute")
+	}
+
+	if config.User == "" || config.Group == "" {
+		return fmt.Errorf("service user and group cannot be empty")
+	}
+
+	if config.User == "root" {
+		return fmt.Errorf("service must not run as root")
+	}
+
+	if config.RestartSec <= 0 {
+		return fmt.Errorf("restart delay must be positive")
+	}
+
+	if config.WatchdogSec < 0 {
+		return fmt.Errorf("watchdog timeout cannot be negative")
+	}
+
+	validLogLevels := []string{"debug", "info", "warn", "error"}
+	isValidLogLevel := false
+	for _, level := range validLogLevels {
+		if config.LogLevel == level {
+			isValidLogLevel = true
+			break
+		}
+	}
+
+	if !isValidLogLevel {
+		return fmt.Errorf("invalid log level: %s (must be one of: %s)",
+			config.LogLevel, strings.Join(validLogLevels, ", "))
+	}
+
+	return nil
+}
+
+// CreateServiceDirectories creates the data and config directories and hands
+// them to the service user, which needs to write both. Files already in the
+// data directory, such as credentials stored by pairing as root, are handed
+// over too.
+func CreateServiceDirectories(config *ServiceConfig) error {
+	uid, gid, err := lookupServiceUser(config)
+	if err != nil {
+		return err
+	}
+
+	directories := []string{
+		config.DataDirectory,
+		filepath.Dir(config.ConfigPath),
+	}
+
+	for _, dir := range directories {
+		if err := os.MkdirAll(dir, 0750); err != nil {
+			return fmt.Errorf("failed to create directory %s: %w", dir, err)
+		}
+		if err := os.Chown(dir, uid, gid); err != nil {
+			return fmt.Errorf("failed to set owner of directory %s: %w", dir, err)
+		}
+	}
+
+	if err := chownTree(config.DataDirectory, uid, gid); err != nil {
+		return fmt.Errorf("failed to set owner of data directory contents: %w", err)
+	}
+
+	return nil
+}
+
+// chownTree hands a directory and everything in it to the service user
+func chownTree(dir string, uid, gid int) error {
+	return filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		return os.Lchown(path, uid, gid)
+	})
+}
+
+// CreateDefaultConfigFile creates a default configuration file owned by the
+// service user if it doesn't exist
+func CreateDefaultConfigFile(config *ServiceConfig) error {
+	// Check if config file already exists
+	if _, err := os.Stat(config.ConfigPath); err == nil {
+		return nil // File already exists
+	}
+
+	uid, gid, err := lookupServiceUser(config)
+	if err != nil {
+		return err
+	}
+
+	defaultConfig := fmt.Sprintf(`# Gym Door Bridge Configuration
+# This is the default configuration file for the systemd service
+
+log_level: %s
+database_path: %s
+
+# Hardware adapter configuration
+enabled_adapters:
+  - simulator
+`, config.LogLevel, filepath.Join(config.DataDirectory, "bridge.db"))
+
+	// The file may hold API secrets, so keep it private
+	if err := os.WriteFile(config.ConfigPath, []byte(defaultConfig), 0640); err != nil {
+		return fmt.Errorf("failed to write default config file: %w", err)
+	}
+	if err := os.Chown(config.ConfigPath, uid, gid); err != nil {
+		return fmt.Errorf("failed to set owner of config file: %w", err)
+	}
+
+	fmt.Printf("Created default configuration file: %s\n", config.ConfigPath)
+	return nil
+}
+
+// lookupServiceUser resolves the numeric IDs of the service user and group
+func lookupServiceUser(config *ServiceConfig) (int, int, error) {
+	u, err := user.Lookup(config.User)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to look up service user %s: %w", config.User, err)
+	}
+	g, err := user.LookupGroup(config.Group)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to look up service group %s: %w", config.Group, err)
+	}
+
+	uid, err := strconv.Atoi(u.Uid)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid uid for service user %s: %w", config.User, err)
+	}
+	gid, err := strconv.Atoi(g.Gid)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid gid for service group %s: %w", config.Group, err)
+	}
+
+	return uid, gid, nil
+}
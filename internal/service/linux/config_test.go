@@ -0,0 +1,44 @@
+package linux
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultServiceConfig(t *testing.T) {
+	config := DefaultServiceConfig()
+
+	assert.Equal(t, "/etc/gym-door-bridge/config.yaml", config.ConfigPath)
+	assert.Equal(t, "/var/lib/gym-door-bridge", config.DataDirectory)
+	assert.Equal(t, "gymdoorbridge", config.User)
+	assert.Equal(t, 60, config.WatchdogSec)
+	assert.NoError(t, ValidateServiceConfig(config))
+}
+
+func TestValidateServiceConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*ServiceConfig)
+	}{
+		{"empty config path", func(c *ServiceConfig) { c.ConfigPath = "" }},
+		{"relative data directory", func(c *ServiceConfig) { c.DataDirectory = "data" }},
+		{"empty user", func(c *ServiceConfig) { c.User = "" }},
+		{"root user", func(c *ServiceConfig) { c.User = "root" }},
+		{"zero restart delay", func(c *ServiceConfig) { c.RestartSec = 0 }},
+		{"negative watchdog", func(c *ServiceConfig) { c.WatchdogSec = -1 }},
+		{"invalid log level", func(c *ServiceConfig) { c.LogLevel = "verbose" }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultServiceConfig()
+			tt.modify(config)
+			assert.Error(t, ValidateServiceConfig(config))
+		})
+	}
+
+	config := DefaultServiceConfig()
+	config.WatchdogSec = 0
+	assert.NoError(t, ValidateServiceConfig(config), "watchdog can be disabled")
+}
@@ -0,0 +1,264 @@
+package linux
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"strings"
+)
+
+// ServiceManager handles systemd service lifecycle operations
+type ServiceManager struct {
+	unitPath string
+	run      func(name string, args ...string) ([]byte, error) // Runs system commands; replaced in tests
+}
+
+// NewServiceManager creates a new service manager instance
+func NewServiceManager() (*ServiceManager, error) {
+	return &ServiceManager{
+		unitPath: filepath.Join("/etc/systemd/system", ServiceName+".service"),
+		run:      runCommand,
+	}, nil
+}
+
+// InstallService creates the service user and directories, installs the
+// binary into the data directory, where the updater can replace it, writes
+// the unit file and enables the service to start on boot
+func (sm *ServiceManager) InstallService(execPath string, config *ServiceConfig) error {
+	// Get absolute path
+	absExecPath, err := filepath.Abs(execPath)
+	if err != nil {
+		return fmt.Errorf("failed to get absolute executable path: %w", err)
+	}
+
+	unitContent, err := GenerateUnitFile(config, BinaryLink(config, absExecPath))
+	if err != nil {
+		return fmt.Errorf("failed to generate unit file: %w", err)
+	}
+
+	if err := sm.ensureServiceUser(config); err != nil {
+		return err
+	}
+
+	if err := CreateServiceDirectories(config); err != nil {
+		return fmt.Errorf("failed to create service directories: %w", err)
+	}
+
+	if err := installServiceBinary(config, absExecPath); err != nil {
+		return err
+	}
+
+	if err := CreateDefaultConfigFile(config); err != nil {
+		return fmt.Errorf("failed to create default config file: %w", err)
+	}
+
+	if err := os.WriteFile(sm.unitPath, []byte(unitContent), 0644); err != nil {
+		return fmt.Errorf("failed to write unit file: %w", err)
+	}
+
+	if err := sm.systemctl("daemon-reload"); err != nil {
+		os.Remove(sm.unitPath)
+		return err
+	}
+
+	if err := sm.systemctl("enable", ServiceName); err != nil {
+		// Clean up unit file if enable fails
+		os.Remove(sm.unitPath)
+		sm.systemctl("daemon-reload")
+		return err
+	}
+
+	fmt.Printf("Service '%s' installed successfully\n", ServiceDisplayName)
+	fmt.Printf("Unit file: %s\n", sm.unitPath)
+	return nil
+}
+
+// UninstallService stops, disables and removes the service. The service user,
+// configuration and data are preserved.
+func (sm *ServiceManager) UninstallService() error {
+	// Stop the service first if it's running
+	if err := sm.StopService(); err != nil {
+		fmt.Printf("Warning: Failed to stop service before uninstall: %v\n", err)
+	}
+
+	if err := sm.systemctl("disable", ServiceName); err != nil {
+		fmt.Printf("Warning: Failed to disable service: %v\n", err)
+	}
+
+	// Remove unit file
+	if err := os.Remove(sm.unitPath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove unit file: %w", err)
+	}
+
+	if err := sm.systemctl("daemon-reload"); err != nil {
+		return err
+	}
+
+	fmt.Printf("Service '%s' uninstalled successfully\n", ServiceDisplayName)
+	return nil
+}
+
+// StartService starts the service. systemctl waits until the bridge reports
+// readiness, so a failed start is reported here.
+func (sm *ServiceManager) StartService() error {
+	if err := sm.requireInstalled(); err != nil {
+		return err
+	}
+
+	if err := sm.systemctl("start", ServiceName); err != nil {
+		return err
+	}
+
+	fmt.Printf("Service '%s' started successfully\n", ServiceDisplayName)
+	return nil
+}
+
+// StopService stops the service
+func (sm *ServiceManager) StopService() error {
+	installed, err := sm.IsServiceInstalled()
+	if err != nil {
+		return err
+	}
+
+	if !installed {
+		fmt.Printf("Service '%s' is not installed\n", ServiceDisplayName)
+		return nil
+	}
+
+	status, err := sm.GetServiceStatus()
+	if err != nil {
+		return err
+	}
+
+	if status == "Stopped" {
+		fmt.Printf("Service '%s' is already stopped\n", ServiceDisplayName)
+		return nil
+	}
+
+	if err := sm.systemctl("stop", ServiceName); err != nil {
+		return err
+	}
+
+	fmt.Printf("Service '%s' stopped successfully\n", ServiceDisplayName)
+	return nil
+}
+
+// RestartService restarts the service
+func (sm *ServiceManager) RestartService() error {
+	if err := sm.requireInstalled(); err != nil {
+		return err
+	}
+
+	if err := sm.systemctl("restart", ServiceName); err != nil {
+		return err
+	}
+
+	fmt.Printf("Service '%s' restarted successfully\n", ServiceDisplayName)
+	return nil
+}
+
+// GetServiceStatus returns the current status of the service
+func (sm *ServiceManager) GetServiceStatus() (string, error) {
+	installed, err := sm.IsServiceInstalled()
+	if err != nil {
+		return "", err
+	}
+
+	if !installed {
+		return "Not Installed", nil
+	}
+
+	// is-active exits non-zero for every state but active, so only its output matters
+	output, _ := sm.run("systemctl", "is-active", ServiceName)
+
+	switch state := strings.TrimSpace(string(output)); state {
+	case "active", "reloading":
+		return "Running", nil
+	case "activating":
+		return "Starting", nil
+	case "deactivating":
+		return "Stopping", nil
+	case "failed":
+		return "Failed", nil
+	case "inactive":
+		return "Stopped", nil
+	default:
+		return "", fmt.Errorf("unexpected service state: %q", state)
+	}
+}
+
+// IsServiceInstalled checks if the unit file is installed
+func (sm *ServiceManager) IsServiceInstalled() (bool, error) {
+	_, err := os.Stat(sm.unitPath)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to check unit file: %w", err)
+	}
+
+	return true, nil
+}
+
+// ensureServiceUser creates the dedicated system account the service runs as
+func (sm *ServiceManager) ensureServiceUser(config *ServiceConfig) error {
+	if _, err := user.Lookup(config.User); err == nil {
+		return nil
+	}
+
+	args := []string{"--system", "--no-create-home", "--home-dir", config.DataDirectory, "--shell", "/usr/sbin/nologin"}
+	if config.Group == config.User {
+		args = append(args, "--user-group")
+	} else {
+		args = append(args, "--gid", config.Group)
+	}
+	args = append(args, config.User)
+
+	if output, err := sm.run("useradd", args...); err != nil {
+		return fmt.Errorf("failed to create service user %s: %w (output: %s)", config.User, err, string(output))
+	}
+	return nil
+}
+
+// requireInstalled returns an error when the unit file is not installed
+func (sm *ServiceManager) requireInstalled() error {
+	installed, err := sm.IsServiceInstalled()
+	if err != nil {
+		return err
+	}
+	if !installed {
+		return fmt.Errorf("service is not installed")
+	}
+	return nil
+}
+
+// systemctl runs a systemctl command
+func (sm *ServiceManager) systemctl(args ...string) error {
+	if output, err := sm.run("systemctl", args...); err != nil {
+		return fmt.Errorf("systemctl %s failed: %w (output: %s)", strings.Join(args, " "), err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// runCommand runs a command and returns its combined output
+func runCommand(name string, args ...string) ([]byte, error) {
+	return exec.Command(name, args...).CombinedOutput()
+}
+
+// GetExecutablePath returns the current executable path
+func GetExecutablePath() (string, error) {
+	execPath, err := os.Executable()
+	if err != nil {
+		return "", fmt.Errorf("failed to get executable path: %w", err)
+	}
+
+	// Resolve any symlinks
+	execPath, err = filepath.EvalSymlinks(execPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve executable path: %w", err)
+	}
+
+	return execPath, nil
+}
@@ -0,0 +1,132 @@
+package linux
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRunner records commands and returns canned output per command line
+type fakeRunner struct {
+	commands []string
+	outputs  map[string]string
+	failures map[string]bool
+}
+
+func (f *fakeRunner) run(name string, args ...string) ([]byte, error) {
+	command := strings.Join(append([]string{name}, args...), " ")
+	f.commands = append(f.commands, command)
+	if f.failures[command] {
+		return []byte(f.outputs[command]), errors.New("exit status 1")
+	}
+	return []byte(f.outputs[command]), nil
+}
+
+func newTestManager(t *testing.T, runner *fakeRunner) *ServiceManager {
+	return &ServiceManager{
+		unitPath: filepath.Join(t.TempDir(), ServiceName+".service"),
+		run:      runner.run,
+	}
+}
+
+func TestNewServiceManager(t *testing.T) {
+	sm, err := NewServiceManager()
+	require.NoError(t, err)
+	assert.Equal(t, "/etc/systemd/system/gym-door-bridge.service", sm.unitPath)
+}
+
+func TestServiceManagerStatus(t *testing.T) {
+	runner := &fakeRunner{outputs: map[string]string{}, failures: map[string]bool{}}
+	sm := newTestManager(t, runner)
+
+	status, err := sm.GetServiceStatus()
+	require.NoError(t, err)
+	assert.Equal(t, "Not Installed", status)
+	assert.Empty(t, runner.commands)
+
+	require.NoError(t, os.WriteFile(sm.unitPath, []byte("[Unit]\n"), 0644))
+
+	states := map[string]string{
+		"active":     "Running",
+		"inactive":   "Stopped",
+		"failed":     "Failed",
+		"activating": "Starting",
+	}
+	for state, expected := range states {
+		runner.outputs["systemctl is-active gym-door-bridge"] = state + "\n"
+		// is-active exits non-zero for anything but active
+		runner.failures["systemctl is-active gym-door-bridge"] = state != "active"
+
+		status, err := sm.GetServiceStatus()
+		require.NoError(t, err)
+		assert.Equal(t, expected, status, state)
+	}
+}
+
+func TestServiceManagerLifecycle(t *testing.T) {
+	runner := &fakeRunner{
+		outputs:  map[string]string{"systemctl is-active gym-door-bridge": "active\n"},
+		failures: map[string]bool{},
+	}
+	sm := newTestManager(t, runner)
+
+	assert.Error(t, sm.StartService(), "not installed")
+	assert.NoError(t, sm.StopService(), "stopping a missing service is not an error")
+	assert.Empty(t, runner.commands)
+
+	require.NoError(t, os.WriteFile(sm.unitPath, []byte("[Unit]\n"), 0644))
+
+	require.NoError(t, sm.StartService())
+	require.NoError(t, sm.RestartService())
+	require.NoError(t, sm.StopService())
+	assert.Equal(t, []string{
+		"systemctl start gym-door-bridge",
+		"systemctl restart gym-door-bridge",
+		"systemctl is-active gym-door-bridge",
+		"systemctl stop gym-door-bridge",
+	}, runner.commands)
+
+	runner.commands = nil
+	require.NoError(t, sm.UninstallService())
+	assert.Equal(t, []string{
+		"systemctl is-active gym-door-bridge",
+		"systemctl stop gym-door-bridge",
+		"systemctl disable gym-door-bridge",
+		"systemctl daemon-reload",
+	}, runner.commands)
+
+	installed, err := sm.IsServiceInstalled()
+	require.NoError(t, err)
+	assert.False(t, installed)
+}
+
+func TestServiceManagerReportsSystemctlFailure(t *testing.T) {
+	runner := &fakeRunner{
+		outputs:  map[string]string{"systemctl start gym-door-bridge": "Job for gym-door-bridge.service failed.\n"},
+		failures: map[string]bool{"systemctl start gym-door-bridge": true},
+	}
+	sm := newTestManager(t, runner)
+	require.NoError(t, os.WriteFile(sm.unitPath, []byte("[Unit]\n"), 0644))
+
+	err := sm.StartService()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Job for gym-door-bridge.service failed.")
+}
+
+func TestEnsureServiceUser(t *testing.T) {
+	runner := &fakeRunner{outputs: map[string]string{}, failures: map[string]bool{}}
+	sm := newTestManager(t, runner)
+
+	config := DefaultServiceConfig()
+	config.User = "gdb-test-missing-user"
+	config.Group = config.User
+	require.NoError(t, sm.ensureServiceUser(config))
+	assert.Equal(t, []string{
+		"useradd --system --no-create-home --home-dir /var/lib/gym-door-bridge --shell /usr/sbin/nologin --user-group gdb-test-missing-user",
+	}, runner.commands)
+}
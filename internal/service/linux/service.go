@@ -0,0 +1,200 @@
+package linux
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"gym-door-bridge/internal/config"
+	"gym-door-bridge/internal/logging"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	ServiceName        = "gym-door-bridge"
+	ServiceDisplayName = "Gym Door Access Bridge"
+	ServiceDescription = "Connects gym door access hardware to SaaS platform"
+)
+
+// Bridge is the bridge run by the service
+type Bridge interface {
+	// Start runs the bridge until ctx is cancelled
+	Start(ctx context.Context) error
+
+	// Ready is closed once the bridge has started
+	Ready() <-chan struct{}
+
+	// CheckAlive returns an error when the bridge does not respond before ctx is done
+	CheckAlive(ctx context.Context) error
+
+	// ReloadConfig applies the configuration file to the running bridge
+	ReloadConfig(ctx context.Context) error
+}
+
+// BridgeFactory creates the bridge for a configuration
+type BridgeFactory func(cfg *config.Config) (Bridge, error)
+
+// Service represents the systemd service wrapper
+type Service struct {
+	config    *config.Config
+	logger    *logrus.Logger
+	ctx       context.Context
+	cancel    context.CancelFunc
+	newBridge BridgeFactory
+}
+
+// NewService creates a new systemd service instance
+func NewService(cfg *config.Config, newBridge BridgeFactory) *Service {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &Service{
+		config:    cfg,
+		logger:    logging.Initialize(cfg.LogLevel),
+		ctx:       ctx,
+		cancel:    cancel,
+		newBridge: newBridge,
+	}
+}
+
+// Run executes the service. Readiness is reported to systemd once the bridge
+// has started, watchdog keep-alives only while the bridge passes its liveness
+// check, SIGHUP reloads the configuration and SIGTERM stops gracefully.
+func (s *Service) Run() error {
+	s.logger.WithField("service", ServiceDisplayName).Info("Starting systemd service")
+
+	// Set up signal handling for graceful shutdown
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+	defer signal.Stop(sigChan)
+
+	// Create and start the bridge in a goroutine
+	bridges := make(chan Bridge, 1)
+	errChan := make(chan error, 1)
+	go func() {
+		bridge, err := s.newBridge(s.config)
+		if err != nil {
+			errChan <- err
+			return
+		}
+		bridges <- bridge
+		errChan <- bridge.Start(s.ctx)
+	}()
+
+	var bridge Bridge
+	var ready <-chan struct{} // Set while the bridge is starting
+	started := false
+
+	// Service control loop
+	for {
+		select {
+		case bridge = <-bridges:
+			ready = bridge.Ready()
+
+		case <-ready:
+			ready = nil
+			started = true
+			if _, err := Notify(NotifyReady); err != nil {
+				s.logger.WithError(err).Warn("Failed to notify systemd of readiness")
+			}
+			if interval, ok := WatchdogInterval(); ok {
+				go s.watchdog(bridge, interval/2)
+			}
+			s.logger.WithField("service", ServiceDisplayName).Info("systemd service started successfully")
+
+		case sig := <-sigChan:
+			switch sig {
+			case syscall.SIGINT, syscall.SIGTERM:
+				s.logger.WithField("signal", sig.String()).Info("Received shutdown signal")
+				Notify(NotifyStopping)
+				s.cancel() // Cancel the context to stop bridge operations and the watchdog
+
+				// Wait for graceful shutdown with timeout
+				select {
+				case <-time.After(30 * time.Second):
+					s.logger.Warning("Service shutdown timeout reached")
+				case err := <-errChan:
+					if err != nil && err != context.Canceled {
+						s.logger.WithError(err).Error("Bridge stopped with error")
+						return err
+					}
+				}
+
+				s.logger.WithField("service", ServiceDisplayName).Info("systemd service stopped")
+				return nil
+
+			case syscall.SIGHUP:
+				if !started {
+					s.logger.Warn("Received SIGHUP before the bridge started, ignoring")
+					continue
+				}
+				s.reload(bridge)
+			}
+
+		case err := <-errChan:
+			s.cancel()
+			if err != nil && err != context.Canceled {
+				s.logger.WithError(err).Error("Bridge error")
+				return err
+			}
+			return nil
+		}
+	}
+}
+
+// reload applies the configuration file to the running bridge, telling
+// systemd the service is reloading until it is done
+func (s *Service) reload(bridge Bridge) {
+	s.logger.Info("Received SIGHUP - reloading configuration")
+	Notify(NotifyReloading)
+	defer Notify(NotifyReady)
+
+	if err := bridge.ReloadConfig(s.ctx); err != nil {
+		s.logger.WithError(err).Error("Failed to reload configuration")
+		return
+	}
+	s.logger.Info("Configuration reloaded")
+}
+
+// watchdog sends keep-alives to systemd while the bridge passes its liveness
+// check, so systemd restarts a bridge that hangs
+func (s *Service) watchdog(bridge Bridge, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(s.ctx, interval)
+			err := bridge.CheckAlive(ctx)
+			cancel()
+			if err != nil {
+				if s.ctx.Err() == nil {
+					s.logger.WithError(err).Error("Bridge failed its liveness check, withholding watchdog keep-alive")
+				}
+				continue
+			}
+
+			if _, err := Notify(NotifyWatchdog); err != nil {
+				s.logger.WithError(err).Warn("Failed to send watchdog keep-alive to systemd")
+			}
+		}
+	}
+}
+
+// RunService runs the application as a systemd service
+func RunService(cfg *config.Config, newBridge BridgeFactory) error {
+	service := NewService(cfg, newBridge)
+	return service.Run()
+}
+
+// IsSystemdService checks if the application was started by systemd.
+// systemd sets INVOCATION_ID for every unit it starts, and NOTIFY_SOCKET for
+// Type=notify units.
+func IsSystemdService() bool {
+	return os.Getenv("NOTIFY_SOCKET") != "" || os.Getenv("INVOCATION_ID") != ""
+}
@@ -0,0 +1,131 @@
+package linux
+
+import (
+	"context"
+	"errors"
+	"net"
+	"path/filepath"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gym-door-bridge/internal/config"
+)
+
+// fakeBridge is a bridge whose startup and liveness the test controls
+type fakeBridge struct {
+	ready   chan struct{}
+	alive   atomic.Bool
+	reloads atomic.Int32
+}
+
+func (b *fakeBridge) Start(ctx context.Context) error {
+	<-ctx.Done()
+	return nil
+}
+
+func (b *fakeBridge) Ready() <-chan struct{} {
+	return b.ready
+}
+
+func (b *fakeBridge) CheckAlive(ctx context.Context) error {
+	if !b.alive.Load() {
+		return errors.New("event loop stalled")
+	}
+	return nil
+}
+
+func (b *fakeBridge) ReloadConfig(ctx context.Context) error {
+	b.reloads.Add(1)
+	return nil
+}
+
+// notifications receives the states the service sends to systemd
+type notifications struct {
+	t    *testing.T
+	conn *net.UnixConn
+}
+
+func listenNotifications(t *testing.T) *notifications {
+	t.Helper()
+	socketPath := filepath.Join(t.TempDir(), "notify.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: socketPath, Net: "unixgram"})
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	t.Setenv("NOTIFY_SOCKET", socketPath)
+	return &notifications{t: t, conn: conn}
+}
+
+// next returns the next state sent within the timeout, or "" when none was
+func (n *notifications) next(timeout time.Duration) string {
+	buf := make([]byte, 64)
+	require.NoError(n.t, n.conn.SetReadDeadline(time.Now().Add(timeout)))
+	count, err := n.conn.Read(buf)
+	if err != nil {
+		return ""
+	}
+	return string(buf[:count])
+}
+
+// drain discards the states sent so far
+func (n *notifications) drain() {
+	for n.next(50*time.Millisecond) != "" {
+	}
+}
+
+func TestServiceRun(t *testing.T) {
+	notified := listenNotifications(t)
+	t.Setenv("WATCHDOG_USEC", "100000")
+	t.Setenv("WATCHDOG_PID", "")
+
+	bridge := &fakeBridge{ready: make(chan struct{})}
+	bridge.alive.Store(true)
+	service := NewService(&config.Config{LogLevel: "error"}, func(*config.Config) (Bridge, error) {
+		return bridge, nil
+	})
+
+	done := make(chan error, 1)
+	go func() { done <- service.Run() }()
+
+	// Nothing is reported until the bridge is up
+	assert.Empty(t, notified.next(200*time.Millisecond), "readiness reported before the bridge started")
+
+	close(bridge.ready)
+	assert.Equal(t, NotifyReady, notified.next(time.Second))
+	assert.Equal(t, NotifyWatchdog, notified.next(time.Second))
+
+	// A bridge failing its liveness check gets no keep-alives, so systemd restarts it
+	bridge.alive.Store(false)
+	notified.drain()
+	assert.Empty(t, notified.next(300*time.Millisecond), "keep-alive sent for a hung bridge")
+
+	// SIGHUP reloads the configuration
+	require.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGHUP))
+	assert.Equal(t, NotifyReloading, notified.next(time.Second))
+	assert.Equal(t, NotifyReady, notified.next(time.Second))
+	assert.Equal(t, int32(1), bridge.reloads.Load())
+
+	require.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGTERM))
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("service did not stop")
+	}
+	assert.Equal(t, NotifyStopping, notified.next(time.Second))
+}
+
+func TestServiceRunReportsStartupFailure(t *testing.T) {
+	notified := listenNotifications(t)
+
+	service := NewService(&config.Config{LogLevel: "error"}, func(*config.Config) (Bridge, error) {
+		return nil, errors.New("database is locked")
+	})
+
+	assert.EqualError(t, service.Run(), "database is locked")
+	assert.Empty(t, notified.next(100*time.Millisecond), "readiness reported for a bridge that failed to start")
+}
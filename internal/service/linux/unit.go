@@ -0,0 +1,141 @@
+package linux
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// unitTemplate is the systemd unit for the bridge. The service runs as a
+// dedicated unprivileged user with a read-only view of the system, and may
+// only write its data and config directories. Access to serial adapters is
+// granted through the dialout group.
+var unitTemplate = template.Must(template.New("unit").Parse(`[Unit]
+Description={{.Description}}
+Documentation=https://github.com/repset/gym-door-bridge
+Wants=network-online.target
+After=network-online.target
+StartLimitIntervalSec=300
+StartLimitBurst=5
+
+[Service]
+Type=notify
+NotifyAccess=main
+ExecStart={{.ExecStart}}
+ExecReload=/bin/kill -HUP $MAINPID
+WorkingDirectory={{.DataDirectory}}
+User={{.User}}
+Group={{.Group}}
+SupplementaryGroups=dialout
+Restart=always
+RestartSec={{.RestartSec}}
+{{- if .WatchdogSec}}
+WatchdogSec={{.WatchdogSec}}
+{{- end}}
+TimeoutStopSec=30
+KillMode=mixed
+StandardOutput=journal
+StandardError=journal
+SyslogIdentifier={{.Name}}
+
+# Security settings
+NoNewPrivileges=true
+ProtectSystem=strict
+ProtectHome=true
+PrivateTmp=true
+ReadWritePaths={{.ReadWritePaths}}
+ProtectKernelTunables=true
+ProtectKernelModules=true
+ProtectKernelLogs=true
+ProtectControlGroups=true
+ProtectClock=true
+ProtectHostname=true
+RestrictAddressFamilies=AF_UNIX AF_INET AF_INET6 AF_NETLINK
+RestrictNamespaces=true
+RestrictRealtime=true
+RestrictSUIDSGID=true
+LockPersonality=true
+MemoryDenyWriteExecute=true
+SystemCallArchitectures=native
+CapabilityBoundingSet=
+AmbientCapabilities=
+UMask=0027
+
+[Install]
+WantedBy=multi-user.target
+`))
+
+// unitData holds the values substituted into the unit template
+type unitData struct {
+	Name           string
+	Description    string
+	ExecStart      string
+	DataDirectory  string
+	ReadWritePaths string
+	User           string
+	Group          string
+	RestartSec     int
+	WatchdogSec    int
+}
+
+// GenerateUnitFile generates the systemd unit file content for the service
+func GenerateUnitFile(config *ServiceConfig, execPath string) (string, error) {
+	if err := ValidateServiceConfig(config); err != nil {
+		return "", fmt.Errorf("invalid service configuration: %w", err)
+	}
+	if !filepath.IsAbs(execPath) {
+		return "", fmt.Errorf("executable path must be absolute: %s", execPath)
+	}
+
+	args := []string{execPath, "--config", config.ConfigPath, "--log-level", config.LogLevel}
+	for i, arg := range args {
+		args[i] = quoteUnitArg(arg)
+	}
+
+	// Directory settings are not quoted or expanded the way ExecStart is
+	configDir := filepath.Dir(config.ConfigPath)
+	for _, dir := range []string{config.DataDirectory, configDir} {
+		if strings.ContainsAny(dir, " \t\"'\\%$") {
+			return "", fmt.Errorf("directory path contains characters not allowed in a unit file: %s", dir)
+		}
+	}
+
+	readWritePaths := []string{config.DataDirectory}
+	if configDir != config.DataDirectory {
+		readWritePaths = append(readWritePaths, configDir)
+	}
+
+	data := unitData{
+		Name:           ServiceName,
+		Description:    ServiceDisplayName + " - " + ServiceDescription,
+		ExecStart:      strings.Join(args, " "),
+		DataDirectory:  config.DataDirectory,
+		ReadWritePaths: strings.Join(readWritePaths, " "),
+		User:           config.User,
+		Group:          config.Group,
+		RestartSec:     config.RestartSec,
+		WatchdogSec:    config.WatchdogSec,
+	}
+
+	var buf bytes.Buffer
+	if err := unitTemplate.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render unit file: %w", err)
+	}
+
+	return buf.String(), nil
+}
+
+// quoteUnitArg quotes a command line argument for systemd when it contains
+// whitespace or quotes, and escapes '%' specifiers and '$' expansion
+func quoteUnitArg(arg string) string {
+	arg = strings.ReplaceAll(arg, "%", "%%")
+	arg = strings.ReplaceAll(arg, "$", "$$")
+	if !strings.ContainsAny(arg, " \t\"'\\") {
+		return arg
+	}
+
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+	return `"` + replacer.Replace(arg) + `"`
+}
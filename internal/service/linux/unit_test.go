@@ -0,0 +1,84 @@
+package linux
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+func TestGenerateUnitFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		golden   string
+		execPath string
+		config   func(*ServiceConfig)
+	}{
+		{
+			name:     "default configuration",
+			golden:   "default.service",
+			execPath: "/usr/local/bin/gym-door-bridge",
+			config:   func(*ServiceConfig) {},
+		},
+		{
+			name:     "custom paths without watchdog",
+			golden:   "custom.service",
+			execPath: "/opt/gym door bridge/gym-door-bridge",
+			config: func(c *ServiceConfig) {
+				c.ConfigPath = "/srv/bridge/config.yaml"
+				c.DataDirectory = "/srv/bridge"
+				c.LogLevel = "debug"
+				c.User = "bridge"
+				c.Group = "gpio"
+				c.RestartSec = 10
+				c.WatchdogSec = 0
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultServiceConfig()
+			tt.config(config)
+
+			unit, err := GenerateUnitFile(config, tt.execPath)
+			require.NoError(t, err)
+
+			goldenPath := filepath.Join("testdata", tt.golden)
+			if *update {
+				require.NoError(t, os.WriteFile(goldenPath, []byte(unit), 0644))
+			}
+
+			expected, err := os.ReadFile(goldenPath)
+			require.NoError(t, err)
+			assert.Equal(t, string(expected), unit)
+		})
+	}
+}
+
+func TestGenerateUnitFileRejectsInvalidInput(t *testing.T) {
+	_, err := GenerateUnitFile(DefaultServiceConfig(), "gym-door-bridge")
+	assert.Error(t, err, "relative executable path")
+
+	config := DefaultServiceConfig()
+	config.User = "root"
+	_, err = GenerateUnitFile(config, "/usr/local/bin/gym-door-bridge")
+	assert.Error(t, err)
+
+	config = DefaultServiceConfig()
+	config.DataDirectory = "/var/lib/gym bridge"
+	_, err = GenerateUnitFile(config, "/usr/local/bin/gym-door-bridge")
+	assert.Error(t, err, "directories are not quoted in the unit")
+}
+
+func TestQuoteUnitArg(t *testing.T) {
+	assert.Equal(t, "/usr/bin/bridge", quoteUnitArg("/usr/bin/bridge"))
+	assert.Equal(t, `"/opt/my bridge"`, quoteUnitArg("/opt/my bridge"))
+	assert.Equal(t, `"/opt/a\"b"`, quoteUnitArg(`/opt/a"b`))
+	assert.Equal(t, "/opt/100%%/$$HOME", quoteUnitArg("/opt/100%/$HOME"))
+}
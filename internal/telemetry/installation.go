@@ -8,7 +8,6 @@ import (
 	"time"
 
 	"gym-door-bridge/internal/config"
-	"gym-door-bridge/internal/service/windows"
 
 	"github.com/sirupsen/logrus"
 )
@@ -98,54 +97,6 @@ func (it *InstallationTelemetry) GenerateInstallationStatusReport(ctx context.Co
 	return report, nil
 }
 
-// getServiceHealthReport gets Windows service health information
-func (it *InstallationTelemetry) getServiceHealthReport(ctx context.Context) (*ServiceHealthReport, error) {
-	serviceManager, err := windows.NewServiceManager()
-	if err != nil {
-		return nil, fmt.Errorf("failed to create service manager: %w", err)
-	}
-	defer serviceManager.Close()
-
-	health, err := serviceManager.GetServiceHealth()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get service health: %w", err)
-	}
-
-	report := &ServiceHealthReport{
-		ServiceName:     health.ServiceName,
-		Status:          health.Status,
-		ProcessID:       health.ProcessID,
-		Win32ExitCode:   health.Win32ExitCode,
-		ServiceExitCode: health.ServiceExitCode,
-		StartType:       health.StartType,
-		ServiceType:     health.ServiceType,
-	}
-
-	// Try to get additional monitoring information
-	healthMonitor, err := windows.NewServiceHealthMonitor(it.logger, windows.DefaultServiceHealthMonitorConfig())
-	if err == nil {
-		defer healthMonitor.Close()
-		
-		summary := healthMonitor.GetHealthSummary()
-		if monitored, ok := summary["is_monitoring"].(bool); ok {
-			report.IsMonitored = monitored
-		}
-		if attempts, ok := summary["recovery_attempts"].(int); ok {
-			report.RecoveryAttempts = attempts
-		}
-		if lastRecovery, ok := summary["last_recovery_time"].(time.Time); ok && !lastRecovery.IsZero() {
-			report.LastRecoveryTime = lastRecovery
-		}
-
-		// Get service uptime
-		if uptime, err := healthMonitor.GetServiceUptime(); err == nil {
-			report.Uptime = uptime.String()
-		}
-	}
-
-	return report, nil
-}
-
 // LogInstallationStatus logs installation status information
 func (it *InstallationTelemetry) LogInstallationStatus(ctx context.Context) {
 	report, err := it.GenerateInstallationStatusReport(ctx)
@@ -272,18 +223,7 @@ func (it *InstallationTelemetry) ValidateInstallationIntegrity() (*InstallationI
 
 	// On Windows, check service installation
 	if runtime.GOOS == "windows" {
-		serviceManager, err := windows.NewServiceManager()
-		if err == nil {
-			defer serviceManager.Close()
-			
-			if installed, err := serviceManager.IsServiceInstalled(); err != nil {
-				report.Issues = append(report.Issues, fmt.Sprintf("Failed to check service installation: %v", err))
-				report.Valid = false
-			} else if !installed {
-				report.Issues = append(report.Issues, "Windows service not installed")
-				report.Valid = false
-			}
-		}
+		it.checkServiceInstallation(report)
 	}
 
 	return report, nil
@@ -0,0 +1,17 @@
+//go:build !windows
+
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+)
+
+// getServiceHealthReport is only implemented for the Windows service
+func (it *InstallationTelemetry) getServiceHealthReport(ctx context.Context) (*ServiceHealthReport, error) {
+	return nil, fmt.Errorf("service health reporting is not supported on %s", runtime.GOOS)
+}
+
+// checkServiceInstallation is a no-op; only the Windows service is checked
+func (it *InstallationTelemetry) checkServiceInstallation(report *InstallationIntegrityReport) {}
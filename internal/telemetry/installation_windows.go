@@ -0,0 +1,75 @@
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gym-door-bridge/internal/service/windows"
+)
+
+// getServiceHealthReport gets Windows service health information
+func (it *InstallationTelemetry) getServiceHealthReport(ctx context.Context) (*ServiceHealthReport, error) {
+	serviceManager, err := windows.NewServiceManager()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create service manager: %w", err)
+	}
+	defer serviceManager.Close()
+
+	health, err := serviceManager.GetServiceHealth()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get service health: %w", err)
+	}
+
+	report := &ServiceHealthReport{
+		ServiceName:     health.ServiceName,
+		Status:          health.Status,
+		ProcessID:       health.ProcessID,
+		Win32ExitCode:   health.Win32ExitCode,
+		ServiceExitCode: health.ServiceExitCode,
+		StartType:       health.StartType,
+		ServiceType:     health.ServiceType,
+	}
+
+	// Try to get additional monitoring information
+	healthMonitor, err := windows.NewServiceHealthMonitor(it.logger, windows.DefaultServiceHealthMonitorConfig())
+	if err == nil {
+		defer healthMonitor.Close()
+
+		summary := healthMonitor.GetHealthSummary()
+		if monitored, ok := summary["is_monitoring"].(bool); ok {
+			report.IsMonitored = monitored
+		}
+		if attempts, ok := summary["recovery_attempts"].(int); ok {
+			report.RecoveryAttempts = attempts
+		}
+		if lastRecovery, ok := summary["last_recovery_time"].(time.Time); ok && !lastRecovery.IsZero() {
+			report.LastRecoveryTime = lastRecovery
+		}
+
+		// Get service uptime
+		if uptime, err := healthMonitor.GetServiceUptime(); err == nil {
+			report.Uptime = uptime.String()
+		}
+	}
+
+	return report, nil
+}
+
+// checkServiceInstallation records an integrity issue when the Windows
+// service is not installed
+func (it *InstallationTelemetry) checkServiceInstallation(report *InstallationIntegrityReport) {
+	serviceManager, err := windows.NewServiceManager()
+	if err != nil {
+		return
+	}
+	defer serviceManager.Close()
+
+	if installed, err := serviceManager.IsServiceInstalled(); err != nil {
+		report.Issues = append(report.Issues, fmt.Sprintf("Failed to check service installation: %v", err))
+		report.Valid = false
+	} else if !installed {
+		report.Issues = append(report.Issues, "Windows service not installed")
+		report.Valid = false
+	}
+}
@@ -24,8 +24,9 @@ func (m *SystemResourceMonitor) getTotalMemoryDarwin() (uint64, error) {
 	}
 	
 	return memBytes, nil
-}// m
-acOS-specific disk usage detection
+}
+
+// macOS-specific disk usage detection
 func (m *SystemResourceMonitor) getDiskUsagePlatform(path string) (float64, error) {
 	cmd := exec.Command("df", "-k", path)
 	output, err := cmd.Output()
@@ -64,8 +65,9 @@ func (m *SystemResourceMonitor) getDiskUsagePlatform(path string) (float64, erro
 	
 	usage := (float64(used) / float64(total)) * 100
 	return usage, nil
-}/
-/ Stub methods for other platforms
+}
+
+// Stub methods for other platforms
 func (m *SystemResourceMonitor) getTotalMemoryWindows() (uint64, error) {
 	return 0, fmt.Errorf("Windows memory detection not supported on macOS")
 }
@@ -40,8 +40,9 @@ func (m *SystemResourceMonitor) getTotalMemoryLinux() (uint64, error) {
 	}
 	
 	return 0, fmt.Errorf("could not find MemTotal in /proc/meminfo")
-}// Linux
--specific disk usage detection
+}
+
+// Linux-specific disk usage detection
 func (m *SystemResourceMonitor) getDiskUsagePlatform(path string) (float64, error) {
 	var stat syscall.Statfs_t
 	err := syscall.Statfs(path, &stat)
@@ -60,8 +61,9 @@ func (m *SystemResourceMonitor) getDiskUsagePlatform(path string) (float64, erro
 	
 	usage := (float64(used) / float64(total)) * 100
 	return usage, nil
-}// Stub
- methods for other platforms
+}
+
+// Stub methods for other platforms
 func (m *SystemResourceMonitor) getTotalMemoryWindows() (uint64, error) {
 	return 0, fmt.Errorf("Windows memory detection not supported on Linux")
 }
@@ -10,17 +10,24 @@ type RawHardwareEvent struct {
 	Timestamp      time.Time              `json:"timestamp"`
 	EventType      string                 `json:"eventType"` // "entry", "exit", "denied"
 	RawData        map[string]interface{} `json:"rawData,omitempty"`
+	AdapterName    string                 `json:"adapterName,omitempty"` // Set by the adapter manager
+	DoorID         string                 `json:"doorId,omitempty"`      // Set by adapters that know the door, otherwise from the adapter's door binding
+	VerifyMode     string                 `json:"verifyMode,omitempty"`  // How the member was identified, see the VerifyMode constants
 }
 
 // StandardEvent represents the normalized event format for cloud submission
 type StandardEvent struct {
-	EventID        string    `json:"eventId"`
-	ExternalUserID string    `json:"externalUserId"`
-	InternalUserID string    `json:"internalUserId,omitempty"` // Resolved from external user mapping
-	Timestamp      time.Time `json:"timestamp"`
-	EventType      string    `json:"eventType"` // "entry", "exit", "denied"
-	IsSimulated    bool      `json:"isSimulated"`
-	DeviceID       string    `json:"deviceId"`
+	EventID        string                 `json:"eventId"`
+	ExternalUserID string                 `json:"externalUserId"`
+	InternalUserID string                 `json:"internalUserId,omitempty"` // Resolved from external user mapping
+	Timestamp      time.Time              `json:"timestamp"`
+	EventType      string                 `json:"eventType"` // "entry", "exit", "denied"
+	IsSimulated    bool                   `json:"isSimulated"`
+	DeviceID       string                 `json:"deviceId"`
+	DoorID         string                 `json:"doorId,omitempty"`       // Door the event happened at
+	AccessReason   string                 `json:"accessReason,omitempty"` // Reason code from the local access decision
+	AdapterName    string                 `json:"adapterName,omitempty"`  // Adapter that reported the event
+	VerifyMode     string                 `json:"verifyMode,omitempty"`   // How the member was identified
 	RawData        map[string]interface{} `json:"rawData,omitempty"`
 }
 
@@ -29,25 +36,52 @@ const (
 	EventTypeEntry  = "entry"
 	EventTypeExit   = "exit"
 	EventTypeDenied = "denied"
+
+	// Door alarms raised by the door position sensor; they carry no user
+	EventTypeDoorForcedOpen = "door_forced_open"
+	EventTypeDoorHeldOpen   = "door_held_open"
+)
+
+// VerifyMode constants for how a member was identified
+const (
+	VerifyModeFingerprint = "fingerprint"
+	VerifyModePassword    = "password"
+	VerifyModeCard        = "card"
+	VerifyModeFace        = "face"
 )
 
 // IsValidEventType checks if the provided event type is valid
 func IsValidEventType(eventType string) bool {
 	switch eventType {
-	case EventTypeEntry, EventTypeExit, EventTypeDenied:
+	case EventTypeEntry, EventTypeExit, EventTypeDenied, EventTypeDoorForcedOpen, EventTypeDoorHeldOpen:
 		return true
 	default:
 		return false
 	}
 }
 
-// AdapterConfig holds configuration for hardware adapters
+// IsDoorAlarmEventType reports whether the event type is a door alarm rather than a member check-in
+func IsDoorAlarmEventType(eventType string) bool {
+	return eventType == EventTypeDoorForcedOpen || eventType == EventTypeDoorHeldOpen
+}
+
+// AdapterConfig holds configuration for hardware adapters. The name identifies
+// the adapter instance, so several terminals of one type can run side by side.
 type AdapterConfig struct {
 	Name     string                 `json:"name"`
+	Type     string                 `json:"type,omitempty"` // Registered adapter type; empty means the name is the type
 	Enabled  bool                   `json:"enabled"`
 	Settings map[string]interface{} `json:"settings"`
 }
 
+// AdapterType returns the registered type of the adapter
+func (c AdapterConfig) AdapterType() string {
+	if c.Type != "" {
+		return c.Type
+	}
+	return c.Name
+}
+
 // AdapterStatus represents the current status of a hardware adapter
 type AdapterStatus struct {
 	Name         string    `json:"name"`
@@ -66,4 +100,4 @@ const (
 )
 
 // EventCallback defines the function signature for event callbacks
-type EventCallback func(event RawHardwareEvent)
\ No newline at end of file
+type EventCallback func(event RawHardwareEvent)
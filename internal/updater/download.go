@@ -0,0 +1,137 @@
+package updater
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// downloadAttemptTimeout bounds a single download request; an interrupted
+// download continues where it stopped on the next attempt
+const downloadAttemptTimeout = 10 * time.Minute
+
+// maxDownloadAttempts is how often a dropped download is resumed before giving
+// up until the next update check
+const maxDownloadAttempts = 5
+
+// downloadFile downloads a file from URL to the specified path. The download
+// is written to a ".part" file and resumed with an HTTP Range request when the
+// connection drops, also across update checks.
+func (u *Updater) downloadFile(ctx context.Context, fileURL, path string) error {
+	partPath := path + ".part"
+
+	var err error
+	for attempt := 1; attempt <= maxDownloadAttempts; attempt++ {
+		var retry bool
+		if retry, err = u.downloadPart(ctx, fileURL, partPath); err == nil {
+			if err := os.Rename(partPath, path); err != nil {
+				return fmt.Errorf("failed to move download into place: %w", err)
+			}
+			return nil
+		}
+		if !retry || attempt == maxDownloadAttempts {
+			break
+		}
+
+		u.logger.WithError(err).WithField("attempt", attempt).Warn("Download interrupted, resuming")
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(u.retryDelay * time.Duration(attempt)):
+		}
+	}
+	return err
+}
+
+// downloadPart continues downloading fileURL into partPath from its current size.
+// retry reports whether the error was a dropped connection worth resuming.
+func (u *Updater) downloadPart(ctx context.Context, fileURL, partPath string) (retry bool, err error) {
+	var offset int64
+	if info, err := os.Stat(partPath); err == nil {
+		offset = info.Size()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", fileURL, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to create request: %w", err)
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+
+	resp, err := u.downloadClient.Do(req)
+	if err != nil {
+		return ctx.Err() == nil && isDroppedConnection(err), fmt.Errorf("failed to download file: %w", err)
+	}
+	defer resp.Body.Close()
+
+	flags := os.O_CREATE | os.O_WRONLY
+	switch resp.StatusCode {
+	case http.StatusOK:
+		// The server sent the whole file
+		flags |= os.O_TRUNC
+	case http.StatusPartialContent:
+		if start, ok := contentRangeStart(resp.Header.Get("Content-Range")); !ok || start != offset {
+			// Not the range asked for; start over on the next attempt
+			os.Remove(partPath)
+			return true, fmt.Errorf("unexpected content range %q", resp.Header.Get("Content-Range"))
+		}
+		flags |= os.O_APPEND
+	case http.StatusRequestedRangeNotSatisfiable:
+		if offset > 0 {
+			// The previous attempt already received the whole file
+			return false, nil
+		}
+		return false, fmt.Errorf("download failed with status: %d", resp.StatusCode)
+	default:
+		return false, fmt.Errorf("download failed with status: %d", resp.StatusCode)
+	}
+
+	file, err := os.OpenFile(partPath, flags, 0644)
+	if err != nil {
+		return false, fmt.Errorf("failed to create file: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := io.Copy(file, resp.Body); err != nil {
+		return ctx.Err() == nil && isDroppedConnection(err), fmt.Errorf("failed to write file: %w", err)
+	}
+
+	return false, nil
+}
+
+// isDroppedConnection reports whether a download failed on the network, as
+// opposed to a bad URL or a local write error
+func isDroppedConnection(err error) bool {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		err = urlErr.Err
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF)
+}
+
+// contentRangeStart returns the first byte of a "bytes start-end/size" Content-Range
+func contentRangeStart(contentRange string) (int64, bool) {
+	rangeSpec, ok := strings.CutPrefix(contentRange, "bytes ")
+	if !ok {
+		return 0, false
+	}
+	start, _, ok := strings.Cut(rangeSpec, "-")
+	if !ok {
+		return 0, false
+	}
+	offset, err := strconv.ParseInt(start, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return offset, true
+}
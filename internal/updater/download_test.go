@@ -0,0 +1,62 @@
+package updater
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdater_DownloadResumesAfterDroppedConnection(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789abcdef"), 4096)
+
+	var ranges []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ranges = append(ranges, r.Header.Get("Range"))
+		if len(ranges) == 1 {
+			// Send half of the file, then drop the connection
+			w.Header().Set("Content-Length", "65536")
+			w.WriteHeader(http.StatusOK)
+			w.Write(content[:len(content)/2])
+			conn, _, err := w.(http.Hijacker).Hijack()
+			require.NoError(t, err)
+			conn.Close()
+			return
+		}
+		http.ServeContent(w, r, "bridge", time.Time{}, bytes.NewReader(content))
+	}))
+	defer server.Close()
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.ErrorLevel)
+	updater := NewUpdater(&UpdaterConfig{}, logger)
+	updater.retryDelay = time.Millisecond
+
+	path := filepath.Join(t.TempDir(), "bridge")
+	require.NoError(t, updater.downloadFile(context.Background(), server.URL, path))
+
+	downloaded, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, content, downloaded)
+	require.Len(t, ranges, 2)
+	assert.Equal(t, "", ranges[0])
+	assert.Equal(t, "bytes=32768-", ranges[1])
+	assert.NoFileExists(t, path+".part")
+}
+
+func TestContentRangeStart(t *testing.T) {
+	start, ok := contentRangeStart("bytes 32768-65535/65536")
+	assert.True(t, ok)
+	assert.Equal(t, int64(32768), start)
+
+	_, ok = contentRangeStart("items 1-2/3")
+	assert.False(t, ok)
+}
@@ -23,7 +23,7 @@ func NewFactory(logger *logrus.Logger) *Factory {
 }
 
 // CreateUpdater creates a new updater instance from configuration
-func (f *Factory) CreateUpdater(cfg *config.Config) (*Updater, error) {
+func (f *Factory) CreateUpdater(cfg *config.Config, opts ...UpdaterOption) (*Updater, error) {
 	// Check if updates are enabled
 	if !cfg.UpdatesEnabled {
 		return nil, fmt.Errorf("updates are disabled in configuration")
@@ -41,40 +41,64 @@ func (f *Factory) CreateUpdater(cfg *config.Config) (*Updater, error) {
 		publicKey = getPublicKey() // Fallback to embedded key
 	}
 	
+	checkInterval := 24 * time.Hour // Check for updates daily
+	if cfg.UpdateCheckInterval > 0 {
+		checkInterval = time.Duration(cfg.UpdateCheckInterval) * time.Second
+	}
+	
+	// Installs wait for the maintenance window in the site's timezone
+	window, err := ParseMaintenanceWindow(cfg.UpdateWindowStart, cfg.UpdateWindowEnd, cfg.Location())
+	if err != nil {
+		return nil, fmt.Errorf("invalid update window: %w", err)
+	}
+	
 	// Create updater configuration
 	updaterConfig := &UpdaterConfig{
-		ManifestURL:    manifestURL,
-		PublicKey:      publicKey,
-		CheckInterval:  24 * time.Hour, // Check for updates daily
-		DeviceID:       cfg.DeviceID,
-		CurrentVersion: getCurrentVersion(),
-		UpdateDir:      getUpdateDir(),
-		BackupDir:      getBackupDir(),
+		ManifestURL:       manifestURL,
+		PublicKey:         publicKey,
+		CheckInterval:     checkInterval,
+		DeviceID:          cfg.DeviceID,
+		CurrentVersion:    getCurrentVersion(),
+		UpdateDir:         getUpdateDir(cfg),
+		BackupDir:         getBackupDir(cfg),
+		Channel:           cfg.UpdateChannel,
+		PinnedVersion:     cfg.UpdatePinnedVersion,
+		MaintenanceWindow: window,
 	}
 	
+	updater := NewUpdater(updaterConfig, f.logger, opts...)
+	
 	// Validate configuration
-	if err := f.validateUpdaterConfig(updaterConfig); err != nil {
+	if err := f.validateUpdaterConfig(updater.config); err != nil {
 		return nil, fmt.Errorf("invalid updater configuration: %w", err)
 	}
 	
-	return NewUpdater(updaterConfig, f.logger), nil
+	return updater, nil
 }
 
 // CreateHealthChecker creates a health checker instance
 func (f *Factory) CreateHealthChecker(cfg *config.Config) (*HealthChecker, error) {
 	updaterConfig := &UpdaterConfig{
-		UpdateDir: getUpdateDir(),
-		BackupDir: getBackupDir(),
+		UpdateDir: getUpdateDir(cfg),
+		BackupDir: getBackupDir(cfg),
 	}
 	
-	healthURL := "http://localhost:8080/health" // Default health endpoint
+	// Probe the bridge's own API; without it there is nothing to probe
+	healthURL := ""
+	if cfg.APIServer.Enabled {
+		scheme := "http"
+		if cfg.APIServer.TLSEnabled {
+			scheme = "https"
+		}
+		healthURL = fmt.Sprintf("%s://localhost:%d/api/v1/health", scheme, cfg.APIServer.Port)
+	}
 	
 	return NewHealthChecker(updaterConfig, f.logger, healthURL), nil
 }
 
 // CreateQueuePreserver creates a queue preserver instance
 func (f *Factory) CreateQueuePreserver(cfg *config.Config) (*QueuePreserver, error) {
-	return NewQueuePreserver(f.logger, cfg.DatabasePath, getBackupDir()), nil
+	return NewQueuePreserver(f.logger, cfg.DatabasePath, getBackupDir(cfg)), nil
 }
 
 // validateUpdaterConfig validates the updater configuration
@@ -99,6 +123,16 @@ func (f *Factory) validateUpdaterConfig(config *UpdaterConfig) error {
 		return fmt.Errorf("backup directory is required")
 	}
 	
+	switch config.Channel {
+	case "", ChannelStable, ChannelBeta:
+	case ChannelPinned:
+		if config.PinnedVersion == "" {
+			return fmt.Errorf("pinned version is required on the pinned channel")
+		}
+	default:
+		return fmt.Errorf("unknown release channel: %s", config.Channel)
+	}
+	
 	return nil
 }
 
@@ -109,6 +143,13 @@ func getPublicKey() string {
 	return "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
 }
 
+// WithCurrentVersion sets the version the running bridge was built as
+func WithCurrentVersion(version string) UpdaterOption {
+	return func(u *Updater) {
+		u.config.CurrentVersion = version
+	}
+}
+
 // getCurrentVersion returns the current version of the bridge
 // This should be set during build time using ldflags
 var Version = "dev" // Set by build process
@@ -117,12 +158,12 @@ func getCurrentVersion() string {
 	return Version
 }
 
-// getUpdateDir returns the directory for storing updates
-func getUpdateDir() string {
-	return filepath.Join(".", "updates")
+// getUpdateDir returns the directory for storing updates, next to the database
+func getUpdateDir(cfg *config.Config) string {
+	return filepath.Join(filepath.Dir(cfg.DatabasePath), "updates")
 }
 
-// getBackupDir returns the directory for storing backups
-func getBackupDir() string {
-	return filepath.Join(".", "backups")
+// getBackupDir returns the directory for storing backups, next to the database
+func getBackupDir(cfg *config.Config) string {
+	return filepath.Join(filepath.Dir(cfg.DatabasePath), "backups")
 }
\ No newline at end of file
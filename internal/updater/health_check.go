@@ -2,72 +2,109 @@ package updater
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"time"
 
 	"github.com/sirupsen/logrus"
+
+	"gym-door-bridge/internal/service/linux"
 )
 
 // HealthChecker performs health checks after updates
 type HealthChecker struct {
-	config     *UpdaterConfig
-	logger     *logrus.Logger
-	client     *http.Client
-	healthURL  string
-	maxRetries int
-	retryDelay time.Duration
+	config       *UpdaterConfig
+	logger       *logrus.Logger
+	client       *http.Client
+	healthURL    string // Empty when the local API is disabled and there is nothing to probe
+	maxRetries   int
+	retryDelay   time.Duration
+	startupDelay time.Duration
+	executable   func() (string, error) // Path of the running binary; replaced in tests
 }
 
 // NewHealthChecker creates a new health checker
 func NewHealthChecker(config *UpdaterConfig, logger *logrus.Logger, healthURL string) *HealthChecker {
+	client := &http.Client{Timeout: 10 * time.Second}
+	if strings.HasPrefix(healthURL, "https://localhost") {
+		// The bridge's own API server usually has a self-signed certificate
+		client.Transport = &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		}
+	}
+	
 	return &HealthChecker{
-		config:     config,
-		logger:     logger,
-		client:     &http.Client{Timeout: 10 * time.Second},
-		healthURL:  healthURL,
-		maxRetries: 5,
-		retryDelay: 30 * time.Second,
+		config:       config,
+		logger:       logger,
+		client:       client,
+		healthURL:    healthURL,
+		maxRetries:   5,
+		retryDelay:   30 * time.Second,
+		startupDelay: 10 * time.Second,
+		executable:   os.Executable,
 	}
 }
 
 // CheckHealthAfterUpdate performs health checks after an update and rolls back if needed
 func (hc *HealthChecker) CheckHealthAfterUpdate(ctx context.Context) error {
+	if err := hc.checkHealth(ctx); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		hc.logger.Error("All health checks failed, initiating rollback")
+		return hc.rollback()
+	}
+	
+	hc.logger.Info("Health check passed, update successful")
+	return hc.cleanupOldBackups()
+}
+
+// checkHealth waits for the service to start and probes its health endpoint,
+// returning the last error when every attempt fails
+func (hc *HealthChecker) checkHealth(ctx context.Context) error {
 	hc.logger.Info("Starting post-update health check")
 	
 	// Wait a bit for the service to fully start
-	time.Sleep(10 * time.Second)
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(hc.startupDelay):
+	}
+	
+	if hc.healthURL == "" {
+		hc.logger.Warn("No health endpoint to probe, the update is considered healthy once the service has started")
+		return nil
+	}
 	
 	// Perform health checks with retries
+	var err error
 	for attempt := 1; attempt <= hc.maxRetries; attempt++ {
 		hc.logger.WithField("attempt", attempt).Debug("Performing health check")
 		
-		if err := hc.performHealthCheck(ctx); err != nil {
-			hc.logger.WithError(err).WithField("attempt", attempt).Warn("Health check failed")
-			
-			if attempt == hc.maxRetries {
-				hc.logger.Error("All health checks failed, initiating rollback")
-				return hc.rollback()
-			}
-			
-			// Wait before next attempt
-			select {
-			case <-ctx.Done():
-				return ctx.Err()
-			case <-time.After(hc.retryDelay):
-				continue
-			}
-		} else {
-			hc.logger.Info("Health check passed, update successful")
-			return hc.cleanupOldBackups()
+		if err = hc.performHealthCheck(ctx); err == nil {
+			return nil
+		}
+		hc.logger.WithError(err).WithField("attempt", attempt).Warn("Health check failed")
+		
+		if attempt == hc.maxRetries {
+			break
+		}
+		
+		// Wait before next attempt
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(hc.retryDelay):
 		}
 	}
 	
-	return nil
+	return err
 }
 
 // performHealthCheck performs a single health check
@@ -100,8 +137,22 @@ func (hc *HealthChecker) rollback() error {
 		return fmt.Errorf("failed to find backup for rollback: %w", err)
 	}
 	
+	return hc.rollbackTo(backupPath)
+}
+
+// rollbackTo restores the executable from a backup and schedules a restart
+func (hc *HealthChecker) rollbackTo(backupPath string) error {
+	// A versioned install keeps the previous version; switch back to it
+	if binDir, ok := linux.InstalledBinaryDir(backupPath); ok {
+		if err := linux.ActivateBinary(binDir, backupPath); err != nil {
+			return fmt.Errorf("failed to restore previous version: %w", err)
+		}
+		hc.logger.WithField("backup_path", backupPath).Info("Rollback completed successfully")
+		return hc.scheduleRestart()
+	}
+	
 	// Get current executable path
-	currentExe, err := os.Executable()
+	currentExe, err := hc.executable()
 	if err != nil {
 		return fmt.Errorf("failed to get current executable path: %w", err)
 	}
@@ -185,8 +236,8 @@ func (hc *HealthChecker) replaceExecutable(currentPath, backupPath string) error
 		return hc.replaceExecutableWindows(currentPath, backupPath)
 	}
 	
-	// On Unix-like systems, we can usually replace the file directly
-	return os.Rename(backupPath, currentPath)
+	// The backup is kept, so the rollback can be repeated
+	return replaceFile(currentPath, backupPath)
 }
 
 // replaceExecutableWindows handles executable replacement on Windows
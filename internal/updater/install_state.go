@@ -0,0 +1,212 @@
+package updater
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gym-door-bridge/internal/client"
+
+	"github.com/sirupsen/logrus"
+)
+
+// installStateFile records the last installed update so the restarted bridge
+// can verify it
+const installStateFile = "update_state.json"
+
+// Install states
+const (
+	installStatusInstalled  = "installed"   // Waiting for the post-restart health check
+	installStatusRolledBack = "rolled_back" // Failed its health check; not installed again
+)
+
+// reportTimeout bounds reporting an update outcome to the platform
+const reportTimeout = 30 * time.Second
+
+// installState is the update installed before the last restart
+type installState struct {
+	FromVersion     string    `json:"from_version"`
+	ToVersion       string    `json:"to_version"`
+	Channel         string    `json:"channel,omitempty"`
+	Status          string    `json:"status"`
+	Error           string    `json:"error,omitempty"`
+	BackupPath      string    `json:"backup_path"`                 // Binary restored on rollback
+	QueueBackupPath string    `json:"queue_backup_path,omitempty"` // Event queue snapshot taken before the install
+	InstalledAt     time.Time `json:"installed_at"`
+	Reported        bool      `json:"reported"`
+}
+
+// verifyInstalledUpdate checks the health of an update installed before the
+// restart, rolls it back when the bridge is unhealthy and reports the outcome
+// to the platform
+func (u *Updater) verifyInstalledUpdate(ctx context.Context) {
+	state, err := u.loadInstallState()
+	if err != nil {
+		u.logger.WithError(err).Warn("Failed to read installed update state")
+		return
+	}
+	if state == nil {
+		return
+	}
+
+	logger := u.logger.WithFields(logrus.Fields{
+		"from_version": state.FromVersion,
+		"to_version":   state.ToVersion,
+	})
+
+	switch state.Status {
+	case installStatusRolledBack:
+		// Reported by the previous version if it could reach the platform; the
+		// state is kept so the release is not installed again
+		if !state.Reported {
+			state.Reported = u.report(ctx, state, client.UpdateStatusRolledBack) == nil
+			if err := u.saveInstallState(state); err != nil {
+				logger.WithError(err).Warn("Failed to record rolled back update")
+			}
+		}
+		return
+
+	case installStatusInstalled:
+	default:
+		logger.WithField("status", state.Status).Warn("Unknown installed update state")
+		u.removeInstallState()
+		return
+	}
+
+	if state.ToVersion != u.config.CurrentVersion {
+		// The service manager started another binary than the one installed
+		state.Error = fmt.Sprintf("running version %s after installing %s", u.config.CurrentVersion, state.ToVersion)
+		logger.Error("Installed update is not running")
+		u.report(ctx, state, client.UpdateStatusFailed)
+		u.removeInstallState()
+		return
+	}
+
+	var healthErr error
+	if u.healthChecker != nil {
+		healthErr = u.healthChecker.checkHealth(ctx)
+	}
+	if ctx.Err() != nil {
+		// Shutting down; verify again after the next start
+		return
+	}
+
+	if healthErr == nil {
+		logger.Info("Update verified healthy")
+		u.report(ctx, state, client.UpdateStatusSucceeded)
+		u.removeInstallState()
+		if u.healthChecker != nil {
+			if err := u.healthChecker.cleanupOldBackups(); err != nil {
+				logger.WithError(err).Warn("Failed to clean up old backups")
+			}
+		}
+		return
+	}
+
+	logger.WithError(healthErr).Error("Update failed its health check, rolling back")
+	state.Error = healthErr.Error()
+
+	if err := u.healthChecker.rollbackTo(state.BackupPath); err != nil {
+		state.Error = fmt.Sprintf("%s; rollback failed: %v", state.Error, err)
+		logger.WithError(err).Error("Failed to roll back update")
+		u.report(ctx, state, client.UpdateStatusFailed)
+		u.removeInstallState()
+		return
+	}
+
+	state.Status = installStatusRolledBack
+	state.Reported = u.report(ctx, state, client.UpdateStatusRolledBack) == nil
+	if err := u.saveInstallState(state); err != nil {
+		logger.WithError(err).Warn("Failed to record rolled back update")
+	}
+
+	if err := u.restart(); err != nil {
+		logger.WithError(err).Error("Failed to restart after rollback")
+	}
+}
+
+// isRolledBack reports whether the version was rolled back on this device
+func (u *Updater) isRolledBack(version string) bool {
+	state, err := u.loadInstallState()
+	if err != nil || state == nil {
+		return false
+	}
+	return state.Status == installStatusRolledBack && state.ToVersion == version
+}
+
+// report sends an update outcome to the platform
+func (u *Updater) report(ctx context.Context, state *installState, status string) error {
+	if u.reporter == nil {
+		return nil
+	}
+
+	reportCtx, cancel := context.WithTimeout(ctx, reportTimeout)
+	defer cancel()
+
+	err := u.reporter.ReportUpdate(reportCtx, &client.UpdateReport{
+		FromVersion: state.FromVersion,
+		ToVersion:   state.ToVersion,
+		Channel:     state.Channel,
+		Status:      status,
+		Error:       state.Error,
+		InstalledAt: state.InstalledAt.Format(time.RFC3339),
+		Timestamp:   u.now().UTC().Format(time.RFC3339),
+	})
+	if err != nil {
+		u.logger.WithError(err).WithField("status", status).Warn("Failed to report update outcome")
+	}
+	return err
+}
+
+// loadInstallState reads the installed update state, returning nil when
+// there is none
+func (u *Updater) loadInstallState() (*installState, error) {
+	data, err := os.ReadFile(u.installStatePath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read install state: %w", err)
+	}
+
+	var state installState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse install state: %w", err)
+	}
+	return &state, nil
+}
+
+// saveInstallState writes the installed update state
+func (u *Updater) saveInstallState(state *installState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode install state: %w", err)
+	}
+
+	// Write through a temporary file so a crash never leaves a partial state
+	path := u.installStatePath()
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write install state: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to write install state: %w", err)
+	}
+	return nil
+}
+
+// removeInstallState removes the installed update state
+func (u *Updater) removeInstallState() {
+	if err := os.Remove(u.installStatePath()); err != nil && !os.IsNotExist(err) {
+		u.logger.WithError(err).Warn("Failed to remove installed update state")
+	}
+}
+
+// installStatePath returns the path of the installed update state
+func (u *Updater) installStatePath() string {
+	return filepath.Join(u.config.UpdateDir, installStateFile)
+}
@@ -0,0 +1,114 @@
+package updater
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gym-door-bridge/internal/client"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeReporter records the reported update outcomes
+type fakeReporter struct {
+	reports []*client.UpdateReport
+}
+
+func (r *fakeReporter) ReportUpdate(ctx context.Context, report *client.UpdateReport) error {
+	r.reports = append(r.reports, report)
+	return nil
+}
+
+// installTestUpdate records an install of version 1.1.0 over 1.0.0 whose
+// backup holds the previous binary, and returns a health checker probing the
+// given status
+func installTestUpdate(t *testing.T, updater *Updater, executable string, healthStatus int) *HealthChecker {
+	t.Helper()
+
+	backupPath := filepath.Join(updater.config.BackupDir, "bridge_backup_1")
+	require.NoError(t, os.WriteFile(backupPath, []byte("previous binary"), 0755))
+	require.NoError(t, os.WriteFile(executable, []byte("new binary"), 0755))
+	require.NoError(t, updater.saveInstallState(&installState{
+		FromVersion: "1.0.0",
+		ToVersion:   "1.1.0",
+		Status:      installStatusInstalled,
+		BackupPath:  backupPath,
+		InstalledAt: time.Now().UTC(),
+	}))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(healthStatus)
+	}))
+	t.Cleanup(server.Close)
+
+	healthChecker := NewHealthChecker(updater.config, updater.logger, server.URL+"/api/v1/health")
+	healthChecker.startupDelay = 0
+	healthChecker.maxRetries = 2
+	healthChecker.retryDelay = 10 * time.Millisecond
+	healthChecker.executable = updater.executable
+	return healthChecker
+}
+
+func TestUpdater_VerifyInstalledUpdateHealthy(t *testing.T) {
+	release := newTestRelease(t, "1.1.0", []byte("new binary"))
+	reporter := &fakeReporter{}
+	restarter := &fakeRestarter{}
+	updater, executable := newTestUpdater(t, release, "1.1.0", WithUpdateReporter(reporter), WithRestarter(restarter))
+	updater.healthChecker = installTestUpdate(t, updater, executable, http.StatusOK)
+
+	updater.verifyInstalledUpdate(context.Background())
+
+	require.Len(t, reporter.reports, 1)
+	assert.Equal(t, client.UpdateStatusSucceeded, reporter.reports[0].Status)
+	assert.Equal(t, "1.0.0", reporter.reports[0].FromVersion)
+	assert.Equal(t, "1.1.0", reporter.reports[0].ToVersion)
+	assert.Equal(t, 0, restarter.restarts)
+
+	state, err := updater.loadInstallState()
+	require.NoError(t, err)
+	assert.Nil(t, state)
+	content, _ := os.ReadFile(executable)
+	assert.Equal(t, "new binary", string(content))
+}
+
+func TestUpdater_VerifyInstalledUpdateRollsBack(t *testing.T) {
+	release := newTestRelease(t, "1.1.0", []byte("new binary"))
+	reporter := &fakeReporter{}
+	restarter := &fakeRestarter{}
+	updater, executable := newTestUpdater(t, release, "1.1.0", WithUpdateReporter(reporter), WithRestarter(restarter))
+	updater.healthChecker = installTestUpdate(t, updater, executable, http.StatusServiceUnavailable)
+
+	updater.verifyInstalledUpdate(context.Background())
+
+	// The previous binary is restored and started again
+	content, _ := os.ReadFile(executable)
+	assert.Equal(t, "previous binary", string(content))
+	assert.Equal(t, 1, restarter.restarts)
+
+	require.Len(t, reporter.reports, 1)
+	assert.Equal(t, client.UpdateStatusRolledBack, reporter.reports[0].Status)
+	assert.Contains(t, reporter.reports[0].Error, "503")
+
+	state, err := updater.loadInstallState()
+	require.NoError(t, err)
+	require.NotNil(t, state)
+	assert.Equal(t, installStatusRolledBack, state.Status)
+	assert.True(t, state.Reported)
+
+	// The rolled back release is not installed again
+	updater.config.CurrentVersion = "1.0.0"
+	require.NoError(t, updater.checkForUpdates(context.Background()))
+	assert.Nil(t, updater.pending)
+	content, _ = os.ReadFile(executable)
+	assert.Equal(t, "previous binary", string(content))
+
+	// Starting the previous version again reports nothing new
+	updater.verifyInstalledUpdate(context.Background())
+	assert.Len(t, reporter.reports, 1)
+}
@@ -0,0 +1,168 @@
+package updater
+
+import (
+	"bytes"
+	"compress/bzip2"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// bsdiffMagic starts every patch in the BSDIFF40 format written by bsdiff
+const bsdiffMagic = "BSDIFF40"
+
+// maxPatchedSize bounds the output size a patch header may claim
+const maxPatchedSize = 512 << 20
+
+// patchFor returns the patch from the running version, or nil when there is
+// none. The patched binary can only be verified against the full binary's
+// checksum, so releases without one are always downloaded in full.
+func (u *Updater) patchFor(binary Binary) *Patch {
+	if binary.Checksum == "" {
+		return nil
+	}
+	for i := range binary.Patches {
+		if binary.Patches[i].FromVersion == u.config.CurrentVersion {
+			return &binary.Patches[i]
+		}
+	}
+	return nil
+}
+
+// downloadAndApplyPatch downloads and verifies a patch, applies it to a copy
+// of the running binary and checks the result against the full binary's
+// checksum and signature before writing it to binaryPath
+func (u *Updater) downloadAndApplyPatch(ctx context.Context, binary Binary, patch *Patch, binaryPath string) error {
+	patchPath := binaryPath + ".patch"
+	defer os.Remove(patchPath)
+
+	if err := u.downloadFile(ctx, patch.URL, patchPath); err != nil {
+		return fmt.Errorf("failed to download patch: %w", err)
+	}
+	if err := u.verifySignature(patchPath, patch.Signature); err != nil {
+		return fmt.Errorf("patch signature verification failed: %w", err)
+	}
+	patchData, err := os.ReadFile(patchPath)
+	if err != nil {
+		return fmt.Errorf("failed to read patch: %w", err)
+	}
+
+	// The running binary is only read; the patched copy is written separately
+	currentExe, err := u.executable()
+	if err != nil {
+		return fmt.Errorf("failed to get current executable path: %w", err)
+	}
+	current, err := os.ReadFile(currentExe)
+	if err != nil {
+		return fmt.Errorf("failed to read current executable: %w", err)
+	}
+	if patch.FromChecksum != "" && !checksumMatches(current, patch.FromChecksum) {
+		return fmt.Errorf("current executable does not match the patch source")
+	}
+
+	patched, err := applyPatch(current, patchData)
+	if err != nil {
+		return fmt.Errorf("failed to apply patch: %w", err)
+	}
+	if !checksumMatches(patched, binary.Checksum) {
+		return fmt.Errorf("patched binary checksum mismatch")
+	}
+
+	if err := os.MkdirAll(filepath.Dir(binaryPath), 0755); err != nil {
+		return fmt.Errorf("failed to create update directory: %w", err)
+	}
+	if err := os.WriteFile(binaryPath, patched, 0755); err != nil {
+		return fmt.Errorf("failed to write patched binary: %w", err)
+	}
+	if err := u.verifySignature(binaryPath, binary.Signature); err != nil {
+		os.Remove(binaryPath)
+		return fmt.Errorf("signature verification failed: %w", err)
+	}
+	return nil
+}
+
+// checksumMatches reports whether data has the hex SHA256 checksum
+func checksumMatches(data []byte, checksum string) bool {
+	sum := sha256.Sum256(data)
+	return strings.EqualFold(hex.EncodeToString(sum[:]), checksum)
+}
+
+// applyPatch applies a BSDIFF40 patch to old and returns the new file.
+//
+// The patch is a 32 byte header (magic, control block length, diff block
+// length, new file size) followed by three bzip2 streams. Each control entry
+// adds diff bytes to old bytes, copies extra bytes, then seeks in old.
+func applyPatch(old, patch []byte) ([]byte, error) {
+	if len(patch) < 32 || string(patch[:8]) != bsdiffMagic {
+		return nil, fmt.Errorf("not a BSDIFF40 patch")
+	}
+	ctrlLen := offtin(patch[8:16])
+	diffLen := offtin(patch[16:24])
+	newSize := offtin(patch[24:32])
+	if ctrlLen < 0 || diffLen < 0 || newSize < 0 || newSize > maxPatchedSize ||
+		ctrlLen > int64(len(patch))-32 || diffLen > int64(len(patch))-32-ctrlLen {
+		return nil, fmt.Errorf("corrupt patch header")
+	}
+
+	body := patch[32:]
+	ctrl := bzip2.NewReader(bytes.NewReader(body[:ctrlLen]))
+	diff := bzip2.NewReader(bytes.NewReader(body[ctrlLen : ctrlLen+diffLen]))
+	extra := bzip2.NewReader(bytes.NewReader(body[ctrlLen+diffLen:]))
+
+	newData := make([]byte, newSize)
+	oldSize := int64(len(old))
+	var oldPos, newPos int64
+	var buf [8]byte
+	for newPos < newSize {
+		var entry [3]int64
+		for i := range entry {
+			if _, err := io.ReadFull(ctrl, buf[:]); err != nil {
+				return nil, fmt.Errorf("corrupt patch control block: %w", err)
+			}
+			entry[i] = offtin(buf[:])
+		}
+		diffCount, extraCount, seek := entry[0], entry[1], entry[2]
+		if diffCount < 0 || extraCount < 0 || diffCount > newSize-newPos {
+			return nil, fmt.Errorf("corrupt patch control entry")
+		}
+
+		if _, err := io.ReadFull(diff, newData[newPos:newPos+diffCount]); err != nil {
+			return nil, fmt.Errorf("corrupt patch diff block: %w", err)
+		}
+		for i := int64(0); i < diffCount; i++ {
+			if pos := oldPos + i; pos >= 0 && pos < oldSize {
+				newData[newPos+i] += old[pos]
+			}
+		}
+		newPos += diffCount
+		oldPos += diffCount
+
+		if extraCount > newSize-newPos {
+			return nil, fmt.Errorf("corrupt patch control entry")
+		}
+		if _, err := io.ReadFull(extra, newData[newPos:newPos+extraCount]); err != nil {
+			return nil, fmt.Errorf("corrupt patch extra block: %w", err)
+		}
+		newPos += extraCount
+		oldPos += seek
+	}
+
+	return newData, nil
+}
+
+// offtin decodes a bsdiff offset: a little-endian sign-magnitude 64 bit integer
+func offtin(buf []byte) int64 {
+	y := int64(buf[7] & 0x7f)
+	for i := 6; i >= 0; i-- {
+		y = y<<8 | int64(buf[i])
+	}
+	if buf[7]&0x80 != 0 {
+		y = -y
+	}
+	return y
+}
@@ -0,0 +1,117 @@
+package updater
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readPatchFixtures returns the 1.0.0 and 1.1.0 binaries and the bsdiff patch between them
+func readPatchFixtures(t *testing.T) (old, new, patch []byte) {
+	t.Helper()
+
+	var err error
+	old, err = os.ReadFile(filepath.Join("testdata", "bridge_1.0.0"))
+	require.NoError(t, err)
+	new, err = os.ReadFile(filepath.Join("testdata", "bridge_1.1.0"))
+	require.NoError(t, err)
+	patch, err = os.ReadFile(filepath.Join("testdata", "bridge_1.0.0_to_1.1.0.bsdiff"))
+	require.NoError(t, err)
+	return old, new, patch
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func TestApplyPatch(t *testing.T) {
+	old, new, patch := readPatchFixtures(t)
+
+	patched, err := applyPatch(old, patch)
+	require.NoError(t, err)
+	assert.Equal(t, new, patched)
+
+	_, err = applyPatch(old, patch[:len(patch)-20])
+	assert.Error(t, err, "truncated patch")
+
+	_, err = applyPatch(old, append([]byte("BSDIFF39"), patch[8:]...))
+	assert.Error(t, err, "unknown patch format")
+}
+
+func TestOfftin(t *testing.T) {
+	assert.Equal(t, int64(0), offtin([]byte{0, 0, 0, 0, 0, 0, 0, 0}))
+	assert.Equal(t, int64(500), offtin([]byte{0xf4, 0x01, 0, 0, 0, 0, 0, 0}))
+	assert.Equal(t, int64(-500), offtin([]byte{0xf4, 0x01, 0, 0, 0, 0, 0, 0x80}))
+}
+
+// newPatchRelease serves release 1.1.0 with a patch from 1.0.0
+func newPatchRelease(t *testing.T, patch []byte) *testRelease {
+	t.Helper()
+
+	_, new, _ := readPatchFixtures(t)
+	release := newTestRelease(t, "1.1.0", new)
+	release.files["/patch_1.0.0"] = patch
+
+	platform := runtime.GOOS + "_" + runtime.GOARCH
+	binary := release.manifest.Binaries[platform]
+	binary.Checksum = sha256Hex(new)
+	binary.Patches = []Patch{{
+		FromVersion: "1.0.0",
+		URL:         release.server.URL + "/patch_1.0.0",
+		Signature:   release.sign(t, patch),
+		Size:        int64(len(patch)),
+	}}
+	release.manifest.Binaries[platform] = binary
+	return release
+}
+
+func TestUpdater_InstallsFromPatch(t *testing.T) {
+	old, new, patch := readPatchFixtures(t)
+	release := newPatchRelease(t, patch)
+
+	updater, executable := newTestUpdater(t, release, "1.0.0")
+	require.NoError(t, os.WriteFile(executable, old, 0755))
+
+	require.NoError(t, updater.checkForUpdates(context.Background()))
+
+	content, _ := os.ReadFile(executable)
+	assert.Equal(t, new, content)
+	assert.Equal(t, 1, release.requests["/patch_1.0.0"])
+	assert.Zero(t, release.requests["/release_binary"], "the full binary should not be downloaded")
+}
+
+func TestUpdater_PatchFallsBackToFullDownload(t *testing.T) {
+	old, new, patch := readPatchFixtures(t)
+
+	tests := []struct {
+		name    string
+		patch   []byte
+		current []byte
+	}{
+		{"corrupt patch", patch[:len(patch)-20], old},
+		{"modified running binary", patch, append([]byte{0x42}, old[1:]...)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			release := newPatchRelease(t, tt.patch)
+
+			updater, executable := newTestUpdater(t, release, "1.0.0")
+			require.NoError(t, os.WriteFile(executable, tt.current, 0755))
+
+			require.NoError(t, updater.checkForUpdates(context.Background()))
+
+			content, _ := os.ReadFile(executable)
+			assert.Equal(t, new, content)
+			assert.Equal(t, 1, release.requests["/release_binary"])
+		})
+	}
+}
@@ -51,7 +51,16 @@ func (qp *QueuePreserver) PreserveQueue(ctx context.Context) (string, error) {
 	if err := qp.copyFile(qp.databasePath, backupPath); err != nil {
 		return "", fmt.Errorf("failed to backup queue database: %w", err)
 	}
-	
+
+	// Recent events may only be in the write-ahead log; SQLite replays the
+	// copy when the backup is opened
+	walPath := qp.databasePath + "-wal"
+	if _, err := os.Stat(walPath); err == nil {
+		if err := qp.copyFile(walPath, backupPath+"-wal"); err != nil {
+			return "", fmt.Errorf("failed to backup queue write-ahead log: %w", err)
+		}
+	}
+
 	qp.logger.WithField("backup_path", backupPath).Info("Queue database backed up successfully")
 	return backupPath, nil
 }
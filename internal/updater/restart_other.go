@@ -0,0 +1,29 @@
+//go:build !windows
+
+package updater
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+
+	"gym-door-bridge/internal/service/linux"
+	"gym-door-bridge/internal/service/macos"
+)
+
+// serviceRestarter restarts the bridge through systemd or launchd
+type serviceRestarter struct{}
+
+// NewServiceRestarter returns a Restarter for the platform's service manager
+func NewServiceRestarter() Restarter {
+	return serviceRestarter{}
+}
+
+// Restart stops the bridge gracefully. The systemd unit (Restart=always) and
+// the launchd daemon (KeepAlive) start it again from the installed binary.
+func (serviceRestarter) Restart() error {
+	if !linux.IsSystemdService() && !macos.IsMacOSDaemon() {
+		return fmt.Errorf("not running under systemd or launchd")
+	}
+	return syscall.Kill(os.Getpid(), syscall.SIGTERM)
+}
@@ -0,0 +1,44 @@
+//go:build windows
+
+package updater
+
+import (
+	"fmt"
+	"os/exec"
+	"syscall"
+
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/svc"
+
+	winservice "gym-door-bridge/internal/service/windows"
+)
+
+// serviceRestarter restarts the bridge through the Service Control Manager
+type serviceRestarter struct{}
+
+// NewServiceRestarter returns a Restarter for the platform's service manager
+func NewServiceRestarter() Restarter {
+	return serviceRestarter{}
+}
+
+// Restart restarts the bridge service. A service cannot wait for its own
+// stop, so a detached helper stops and starts it.
+func (serviceRestarter) Restart() error {
+	isService, err := svc.IsWindowsService()
+	if err != nil {
+		return fmt.Errorf("failed to determine if running as a service: %w", err)
+	}
+	if !isService {
+		return fmt.Errorf("not running as a Windows service")
+	}
+
+	name := winservice.ServiceName
+	cmd := exec.Command("cmd.exe", "/C", "net stop "+name+" & net start "+name)
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		CreationFlags: windows.DETACHED_PROCESS | windows.CREATE_NEW_PROCESS_GROUP,
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start service restart: %w", err)
+	}
+	return cmd.Process.Release()
+}
@@ -0,0 +1,127 @@
+package updater
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gym-door-bridge/internal/service/linux"
+)
+
+// TestUpdater_InstallWithinServiceSandbox installs an update and rolls it back
+// in the layout the systemd unit runs, checking nothing is written outside the
+// unit's ReadWritePaths
+func TestUpdater_InstallWithinServiceSandbox(t *testing.T) {
+	root := t.TempDir()
+	service := linux.DefaultServiceConfig()
+	service.DataDirectory = filepath.Join(root, "var/lib/gym-door-bridge")
+	service.ConfigPath = filepath.Join(root, "etc/gym-door-bridge/config.yaml")
+
+	// The bridge is installed from a binary in a directory the service cannot write
+	original := filepath.Join(root, "usr/local/bin/gym-door-bridge")
+	require.NoError(t, os.MkdirAll(filepath.Dir(original), 0755))
+	require.NoError(t, os.WriteFile(original, []byte("current binary"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Dir(service.ConfigPath), 0750))
+	_, err := linux.InstallBinary(linux.BinaryDir(service), "gym-door-bridge", linux.InitialVersion, original)
+	require.NoError(t, err)
+
+	unit, err := linux.GenerateUnitFile(service, linux.BinaryLink(service, original))
+	require.NoError(t, err)
+	execStart := strings.Fields(unitSetting(t, unit, "ExecStart"))[0]
+	readWritePaths := strings.Fields(unitSetting(t, unit, "ReadWritePaths"))
+
+	// The database, and the update and backup directories next to it, are in the data directory
+	release := newTestRelease(t, "1.1.0", []byte("new binary"))
+	config := &UpdaterConfig{
+		ManifestURL:    release.server.URL + "/manifest.json",
+		PublicKey:      release.publicKey,
+		CheckInterval:  time.Hour,
+		DeviceID:       "test_device",
+		CurrentVersion: "1.0.0",
+		UpdateDir:      filepath.Join(service.DataDirectory, "updates"),
+		BackupDir:      filepath.Join(service.DataDirectory, "backups"),
+	}
+	logger := logrus.New()
+	logger.SetLevel(logrus.ErrorLevel)
+	restarter := &fakeRestarter{}
+	updater := NewUpdater(config, logger, WithRestarter(restarter))
+	// Like /proc/self/exe, the running binary is the resolved version
+	updater.executable = func() (string, error) { return filepath.EvalSymlinks(execStart) }
+	require.NoError(t, updater.createDirectories())
+
+	outside := filesOutside(t, root, readWritePaths)
+
+	require.NoError(t, updater.checkForUpdates(context.Background()))
+	content, err := os.ReadFile(execStart)
+	require.NoError(t, err)
+	assert.Equal(t, "new binary", string(content))
+	assert.Equal(t, 1, restarter.restarts)
+
+	// The restarted version fails its health check and is rolled back
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+	healthChecker := NewHealthChecker(config, logger, server.URL+"/api/v1/health")
+	healthChecker.startupDelay = 0
+	healthChecker.maxRetries = 1
+	healthChecker.retryDelay = time.Millisecond
+	healthChecker.executable = updater.executable
+	updater.healthChecker = healthChecker
+	config.CurrentVersion = "1.1.0"
+
+	updater.verifyInstalledUpdate(context.Background())
+	content, err = os.ReadFile(execStart)
+	require.NoError(t, err)
+	assert.Equal(t, "current binary", string(content))
+	assert.Equal(t, 2, restarter.restarts)
+
+	assert.Equal(t, outside, filesOutside(t, root, readWritePaths), "files outside ReadWritePaths changed")
+}
+
+// unitSetting returns the value of a setting in a unit file
+func unitSetting(t *testing.T, unit, key string) string {
+	t.Helper()
+	for _, line := range strings.Split(unit, "\n") {
+		if strings.HasPrefix(line, key+"=") {
+			return strings.TrimPrefix(line, key+"=")
+		}
+	}
+	t.Fatalf("unit has no %s setting", key)
+	return ""
+}
+
+// filesOutside returns the content of the files under root outside the writable paths
+func filesOutside(t *testing.T, root string, writable []string) map[string]string {
+	t.Helper()
+	files := make(map[string]string)
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		for _, dir := range writable {
+			if path == dir || strings.HasPrefix(path, dir+string(filepath.Separator)) {
+				return nil
+			}
+		}
+		if !info.IsDir() {
+			content, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			files[path] = string(content)
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	return files
+}
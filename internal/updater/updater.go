@@ -9,11 +9,15 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
 	"runtime"
 	"time"
 
+	"gym-door-bridge/internal/client"
+	"gym-door-bridge/internal/service/linux"
+
 	"github.com/sirupsen/logrus"
 )
 
@@ -23,15 +27,27 @@ type Manifest struct {
 	ReleaseDate time.Time         `json:"release_date"`
 	Binaries    map[string]Binary `json:"binaries"`
 	Rollout     RolloutConfig     `json:"rollout"`
-	MinVersion  string            `json:"min_version,omitempty"`
+	MinVersion  string            `json:"min_version,omitempty"` // Oldest version that may update straight to this release
+	Channel     string            `json:"channel,omitempty"`
 }
 
 // Binary represents a platform-specific binary
 type Binary struct {
-	URL       string `json:"url"`
-	Signature string `json:"signature"` // Ed25519 signature in hex
-	Size      int64  `json:"size"`
-	Checksum  string `json:"checksum"` // SHA256 checksum in hex
+	URL       string  `json:"url"`
+	Signature string  `json:"signature"` // Ed25519 signature in hex
+	Size      int64   `json:"size"`
+	Checksum  string  `json:"checksum"`          // SHA256 checksum in hex
+	Patches   []Patch `json:"patches,omitempty"` // Binary diffs from recent previous versions
+}
+
+// Patch is a BSDIFF40 binary diff that turns a previous version's binary into
+// this release's binary
+type Patch struct {
+	FromVersion  string `json:"from_version"`
+	URL          string `json:"url"`
+	Signature    string `json:"signature"` // Ed25519 signature of the patch in hex
+	Size         int64  `json:"size"`
+	FromChecksum string `json:"from_checksum,omitempty"` // SHA256 checksum of the binary the patch applies to
 }
 
 // RolloutConfig controls staged rollout
@@ -41,6 +57,17 @@ type RolloutConfig struct {
 	DeviceIDs  []string `json:"device_ids,omitempty"`
 }
 
+// Release channels
+const (
+	ChannelStable = "stable"
+	ChannelBeta   = "beta"
+	ChannelPinned = "pinned" // Stay on PinnedVersion
+)
+
+// installRetryInterval is how often an update that has been downloaded but
+// could not be installed yet is retried
+const installRetryInterval = time.Minute
+
 // UpdaterConfig holds updater configuration
 type UpdaterConfig struct {
 	ManifestURL   string        `json:"manifest_url"`
@@ -50,6 +77,32 @@ type UpdaterConfig struct {
 	CurrentVersion string       `json:"current_version"`
 	UpdateDir     string        `json:"update_dir"`     // Directory for downloaded updates
 	BackupDir     string        `json:"backup_dir"`     // Directory for backup binaries
+	Channel       string        `json:"channel"`        // stable, beta or pinned; empty is stable
+	PinnedVersion string        `json:"pinned_version"` // Version installed on the pinned channel
+	
+	// Downloaded updates are only installed inside this window; nil installs at any time
+	MaintenanceWindow *MaintenanceWindow `json:"-"`
+}
+
+// InstallGate reports why a downloaded update cannot be installed right now,
+// for example because a door is unlocked. It returns nil when installing is safe.
+type InstallGate func() error
+
+// Restarter restarts the bridge through the platform's service manager so
+// the installed binary starts running
+type Restarter interface {
+	Restart() error
+}
+
+// UpdateReporter reports the outcome of an installed update to the platform
+type UpdateReporter interface {
+	ReportUpdate(ctx context.Context, report *client.UpdateReport) error
+}
+
+// stagedUpdate is a verified binary waiting to be installed
+type stagedUpdate struct {
+	Version string
+	Path    string
 }
 
 // Updater manages automatic updates
@@ -57,17 +110,87 @@ type Updater struct {
 	config *UpdaterConfig
 	logger *logrus.Logger
 	client *http.Client
+	
+	installGate    InstallGate
+	queuePreserver *QueuePreserver
+	healthChecker  *HealthChecker
+	restarter      Restarter
+	reporter       UpdateReporter
+	now            func() time.Time
+	executable     func() (string, error) // Path of the running binary; replaced in tests
+	
+	// Downloads are resumed after a dropped connection
+	downloadClient *http.Client
+	retryDelay     time.Duration
+	
+	// Downloaded update waiting for the maintenance window
+	pending *stagedUpdate
+	
+	// Requests an update check ahead of the check interval
+	checkNow chan struct{}
+}
+
+// UpdaterOption is a functional option for configuring the Updater
+type UpdaterOption func(*Updater)
+
+// WithInstallGate sets the check that must pass before an update is installed
+func WithInstallGate(gate InstallGate) UpdaterOption {
+	return func(u *Updater) {
+		u.installGate = gate
+	}
+}
+
+// WithQueuePreserver snapshots the event queue before each install
+func WithQueuePreserver(preserver *QueuePreserver) UpdaterOption {
+	return func(u *Updater) {
+		u.queuePreserver = preserver
+	}
+}
+
+// WithHealthChecker sets the health checker that verifies an installed update
+// after the restart and rolls it back when the bridge is unhealthy
+func WithHealthChecker(healthChecker *HealthChecker) UpdaterOption {
+	return func(u *Updater) {
+		u.healthChecker = healthChecker
+	}
+}
+
+// WithRestarter sets how the bridge is restarted after an install or rollback
+func WithRestarter(restarter Restarter) UpdaterOption {
+	return func(u *Updater) {
+		u.restarter = restarter
+	}
+}
+
+// WithUpdateReporter sets where update outcomes are reported
+func WithUpdateReporter(reporter UpdateReporter) UpdaterOption {
+	return func(u *Updater) {
+		u.reporter = reporter
+	}
 }
 
 // NewUpdater creates a new updater instance
-func NewUpdater(config *UpdaterConfig, logger *logrus.Logger) *Updater {
-	return &Updater{
+func NewUpdater(config *UpdaterConfig, logger *logrus.Logger, opts ...UpdaterOption) *Updater {
+	u := &Updater{
 		config: config,
 		logger: logger,
 		client: &http.Client{
 			Timeout: 30 * time.Second,
 		},
+		now:        time.Now,
+		executable: os.Executable,
+		downloadClient: &http.Client{
+			Timeout: downloadAttemptTimeout,
+		},
+		retryDelay: 5 * time.Second,
+		checkNow:   make(chan struct{}, 1),
+	}
+	
+	for _, opt := range opts {
+		opt(u)
 	}
+	
+	return u
 }
 
 // Start begins the update checking process
@@ -79,6 +202,9 @@ func (u *Updater) Start(ctx context.Context) error {
 		return fmt.Errorf("failed to create directories: %w", err)
 	}
 	
+	// Finish an update installed before the last restart
+	u.verifyInstalledUpdate(ctx)
+	
 	// Check for updates immediately on startup
 	if err := u.checkForUpdates(ctx); err != nil {
 		u.logger.WithError(err).Warn("Initial update check failed")
@@ -88,6 +214,10 @@ func (u *Updater) Start(ctx context.Context) error {
 	ticker := time.NewTicker(u.config.CheckInterval)
 	defer ticker.Stop()
 	
+	// Retry installing a downloaded update until the window opens and the doors are locked
+	installTicker := time.NewTicker(installRetryInterval)
+	defer installTicker.Stop()
+	
 	for {
 		select {
 		case <-ctx.Done():
@@ -97,10 +227,28 @@ func (u *Updater) Start(ctx context.Context) error {
 			if err := u.checkForUpdates(ctx); err != nil {
 				u.logger.WithError(err).Warn("Update check failed")
 			}
+		case <-u.checkNow:
+			if err := u.checkForUpdates(ctx); err != nil {
+				u.logger.WithError(err).Warn("Update check failed")
+			}
+		case <-installTicker.C:
+			if err := u.installPending(ctx); err != nil {
+				u.logger.WithError(err).Warn("Update install failed")
+			}
 		}
 	}
 }
 
+// CheckNow asks the running updater to check for updates without waiting for
+// the check interval, for example when the platform announces a release
+func (u *Updater) CheckNow() {
+	select {
+	case u.checkNow <- struct{}{}:
+	default:
+		// A check is already requested
+	}
+}
+
 // checkForUpdates checks for and applies available updates
 func (u *Updater) checkForUpdates(ctx context.Context) error {
 	u.logger.Debug("Checking for updates")
@@ -111,38 +259,144 @@ func (u *Updater) checkForUpdates(ctx context.Context) error {
 		return fmt.Errorf("failed to download manifest: %w", err)
 	}
 	
+	// Check the release is the one this device follows
+	if !u.isReleaseSelected(manifest) {
+		return nil
+	}
+	
 	// Check if update is needed
 	if !u.isUpdateNeeded(manifest) {
 		u.logger.Debug("No update needed")
 		return nil
 	}
 	
+	// Don't reinstall a release that failed its health check here
+	if u.isRolledBack(manifest.Version) {
+		u.logger.WithField("version", manifest.Version).Warn("Skipping update that was rolled back on this device")
+		return nil
+	}
+	
+	if !u.meetsMinVersion(manifest) {
+		u.logger.WithFields(logrus.Fields{
+			"version":     manifest.Version,
+			"min_version": manifest.MinVersion,
+			"current":     u.config.CurrentVersion,
+		}).Warn("Current version is too old to update straight to this release")
+		return nil
+	}
+	
 	// Check rollout eligibility
 	if !u.isEligibleForRollout(manifest.Rollout) {
 		u.logger.Info("Device not eligible for rollout yet")
 		return nil
 	}
 	
-	u.logger.WithField("version", manifest.Version).Info("Update available")
+	// Download and verify the update ahead of the maintenance window
+	if u.pending == nil || u.pending.Version != manifest.Version {
+		u.logger.WithField("version", manifest.Version).Info("Update available")
+		
+		binaryPath, err := u.downloadAndVerifyBinary(ctx, manifest)
+		if err != nil {
+			return fmt.Errorf("failed to download and verify binary: %w", err)
+		}
+		u.pending = &stagedUpdate{Version: manifest.Version, Path: binaryPath}
+	}
 	
-	// Download and verify update
-	binaryPath, err := u.downloadAndVerifyBinary(ctx, manifest)
-	if err != nil {
-		return fmt.Errorf("failed to download and verify binary: %w", err)
+	return u.installPending(ctx)
+}
+
+// installPending installs the downloaded update when the maintenance window is
+// open and nothing blocks it, then restarts the bridge
+func (u *Updater) installPending(ctx context.Context) error {
+	if u.pending == nil {
+		return nil
+	}
+	update := u.pending
+	
+	if err := u.installBlocked(); err != nil {
+		u.logger.WithError(err).WithField("version", update.Version).Debug("Update downloaded, install deferred")
+		return nil
+	}
+	
+	state := &installState{
+		FromVersion: u.config.CurrentVersion,
+		ToVersion:   update.Version,
+		Channel:     u.channel(),
+		Status:      installStatusInstalled,
+		InstalledAt: u.now().UTC(),
+	}
+	
+	// Keep a copy of the unsent events in case the new version damages the queue
+	if u.queuePreserver != nil {
+		queueBackup, err := u.queuePreserver.PreserveQueue(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to preserve event queue: %w", err)
+		}
+		state.QueueBackupPath = queueBackup
 	}
 	
 	// Apply update
-	if err := u.applyUpdate(binaryPath); err != nil {
+	u.pending = nil
+	backupPath, err := u.applyUpdate(update)
+	if err != nil {
 		return fmt.Errorf("failed to apply update: %w", err)
 	}
+	state.BackupPath = backupPath
+	
+	// The restarted bridge checks its health against this state and rolls back if needed
+	if err := u.saveInstallState(state); err != nil {
+		u.logger.WithError(err).Error("Failed to record installed update, it will not be verified after the restart")
+	}
+	
+	u.logger.WithField("version", update.Version).Info("Update applied successfully")
+	
+	if err := u.scheduleRestart(); err != nil {
+		return err
+	}
+	return u.restart()
+}
+
+// installBlocked returns why a downloaded update cannot be installed now
+func (u *Updater) installBlocked() error {
+	if window := u.config.MaintenanceWindow; !window.Contains(u.now()) {
+		return fmt.Errorf("outside the maintenance window %s", window)
+	}
+	if u.installGate != nil {
+		return u.installGate()
+	}
+	return nil
+}
+
+// restart restarts the bridge through the service manager
+func (u *Updater) restart() error {
+	if u.restarter == nil {
+		u.logger.Warn("Restart the bridge to start running the update")
+		return nil
+	}
 	
-	u.logger.WithField("version", manifest.Version).Info("Update applied successfully")
+	u.logger.Info("Restarting the bridge service")
+	if err := u.restarter.Restart(); err != nil {
+		return fmt.Errorf("failed to restart service, restart the bridge to finish the update: %w", err)
+	}
 	return nil
 }
 
+// channel returns the configured release channel
+func (u *Updater) channel() string {
+	if u.config.Channel == "" {
+		return ChannelStable
+	}
+	return u.config.Channel
+}
+
 // downloadManifest downloads and parses the update manifest
 func (u *Updater) downloadManifest(ctx context.Context) (*Manifest, error) {
-	req, err := http.NewRequestWithContext(ctx, "GET", u.config.ManifestURL, nil)
+	manifestURL, err := u.manifestURL()
+	if err != nil {
+		return nil, err
+	}
+	
+	req, err := http.NewRequestWithContext(ctx, "GET", manifestURL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -165,6 +419,56 @@ func (u *Updater) downloadManifest(ctx context.Context) (*Manifest, error) {
 	return &manifest, nil
 }
 
+// manifestURL returns the manifest URL for the configured channel
+func (u *Updater) manifestURL() (string, error) {
+	manifestURL, err := url.Parse(u.config.ManifestURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid manifest URL: %w", err)
+	}
+	
+	query := manifestURL.Query()
+	query.Set("channel", u.channel())
+	if u.channel() == ChannelPinned {
+		query.Set("version", u.config.PinnedVersion)
+	}
+	manifestURL.RawQuery = query.Encode()
+	
+	return manifestURL.String(), nil
+}
+
+// isReleaseSelected checks the manifest is for the channel this device follows
+func (u *Updater) isReleaseSelected(manifest *Manifest) bool {
+	if u.channel() == ChannelPinned {
+		if manifest.Version != u.config.PinnedVersion {
+			u.logger.WithFields(logrus.Fields{
+				"version": manifest.Version,
+				"pinned":  u.config.PinnedVersion,
+			}).Debug("Ignoring release other than the pinned version")
+			return false
+		}
+		return true
+	}
+	
+	if manifest.Channel != "" && manifest.Channel != u.channel() {
+		u.logger.WithFields(logrus.Fields{
+			"version":         manifest.Version,
+			"release_channel": manifest.Channel,
+			"channel":         u.channel(),
+		}).Debug("Ignoring release from another channel")
+		return false
+	}
+	return true
+}
+
+// meetsMinVersion checks the current version may update straight to the release
+func (u *Updater) meetsMinVersion(manifest *Manifest) bool {
+	if manifest.MinVersion == "" {
+		return true
+	}
+	cmp, ok := compareVersions(u.config.CurrentVersion, manifest.MinVersion)
+	return ok && cmp >= 0
+}
+
 // isUpdateNeeded checks if an update is needed
 func (u *Updater) isUpdateNeeded(manifest *Manifest) bool {
 	// Simple version comparison - in production, use semantic versioning
@@ -209,12 +513,22 @@ func (u *Updater) downloadAndVerifyBinary(ctx context.Context, manifest *Manifes
 		return "", fmt.Errorf("no binary available for platform: %s", platform)
 	}
 	
-	// Download binary
 	binaryPath := filepath.Join(u.config.UpdateDir, fmt.Sprintf("bridge_%s", manifest.Version))
 	if runtime.GOOS == "windows" {
 		binaryPath += ".exe"
 	}
 	
+	// A patch from the running version is much smaller than the full binary
+	if patch := u.patchFor(binary); patch != nil {
+		err := u.downloadAndApplyPatch(ctx, binary, patch, binaryPath)
+		if err == nil {
+			u.logger.WithField("from_version", patch.FromVersion).Info("Binary patched and verified successfully")
+			return binaryPath, nil
+		}
+		u.logger.WithError(err).WithField("from_version", patch.FromVersion).Warn("Patch update failed, downloading the full binary")
+	}
+	
+	// Download binary
 	if err := u.downloadFile(ctx, binary.URL, binaryPath); err != nil {
 		return "", fmt.Errorf("failed to download binary: %w", err)
 	}
@@ -229,37 +543,6 @@ func (u *Updater) downloadAndVerifyBinary(ctx context.Context, manifest *Manifes
 	return binaryPath, nil
 }
 
-// downloadFile downloads a file from URL to the specified path
-func (u *Updater) downloadFile(ctx context.Context, url, filepath string) error {
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
-	}
-	
-	resp, err := u.client.Do(req)
-	if err != nil {
-		return fmt.Errorf("failed to download file: %w", err)
-	}
-	defer resp.Body.Close()
-	
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("download failed with status: %d", resp.StatusCode)
-	}
-	
-	file, err := os.Create(filepath)
-	if err != nil {
-		return fmt.Errorf("failed to create file: %w", err)
-	}
-	defer file.Close()
-	
-	_, err = io.Copy(file, resp.Body)
-	if err != nil {
-		return fmt.Errorf("failed to write file: %w", err)
-	}
-	
-	return nil
-}
-
 // verifySignature verifies the Ed25519 signature of a file
 func (u *Updater) verifySignature(filePath, signatureHex string) error {
 	// Parse public key
@@ -294,12 +577,21 @@ func (u *Updater) verifySignature(filePath, signatureHex string) error {
 	return nil
 }
 
-// applyUpdate applies the downloaded update
-func (u *Updater) applyUpdate(newBinaryPath string) error {
+// applyUpdate replaces the running executable with the downloaded update and
+// returns the path of the backup of the current binary
+func (u *Updater) applyUpdate(update *stagedUpdate) (string, error) {
+	newBinaryPath := update.Path
+	
 	// Get current executable path
-	currentExe, err := os.Executable()
+	currentExe, err := u.executable()
 	if err != nil {
-		return fmt.Errorf("failed to get current executable path: %w", err)
+		return "", fmt.Errorf("failed to get current executable path: %w", err)
+	}
+	
+	// The systemd service runs a symlink to versioned binaries in its data
+	// directory, the only place its sandbox lets it write
+	if binDir, ok := linux.InstalledBinaryDir(currentExe); ok {
+		return u.installVersion(binDir, currentExe, update)
 	}
 	
 	// Create backup
@@ -309,7 +601,7 @@ func (u *Updater) applyUpdate(newBinaryPath string) error {
 	}
 	
 	if err := u.copyFile(currentExe, backupPath); err != nil {
-		return fmt.Errorf("failed to create backup: %w", err)
+		return "", fmt.Errorf("failed to create backup: %w", err)
 	}
 	
 	u.logger.WithField("backup_path", backupPath).Info("Created backup of current binary")
@@ -320,13 +612,33 @@ func (u *Updater) applyUpdate(newBinaryPath string) error {
 		if restoreErr := u.copyFile(backupPath, currentExe); restoreErr != nil {
 			u.logger.WithError(restoreErr).Error("Failed to restore backup after update failure")
 		}
-		return fmt.Errorf("failed to replace executable: %w", err)
+		return "", fmt.Errorf("failed to replace executable: %w", err)
 	}
 	
 	u.logger.Info("Binary replaced successfully")
+	return backupPath, nil
+}
+
+// installVersion installs the update next to the running version and switches
+// the service's symlink to it. The running version stays installed and is
+// returned as the backup a rollback switches back to.
+func (u *Updater) installVersion(binDir, currentExe string, update *stagedUpdate) (string, error) {
+	previous, err := filepath.EvalSymlinks(currentExe)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve current executable: %w", err)
+	}
+	
+	installed, err := linux.InstallBinary(binDir, filepath.Base(previous), update.Version, update.Path)
+	if err != nil {
+		return "", fmt.Errorf("failed to install binary: %w", err)
+	}
+	os.Remove(update.Path)
 	
-	// Schedule restart
-	return u.scheduleRestart()
+	u.logger.WithFields(logrus.Fields{
+		"binary":      installed,
+		"backup_path": previous,
+	}).Info("Installed new version")
+	return previous, nil
 }
 
 // copyFile copies a file from src to dst
@@ -364,8 +676,46 @@ func (u *Updater) replaceExecutable(currentPath, newPath string) error {
 		return u.replaceExecutableWindows(currentPath, newPath)
 	}
 	
-	// On Unix-like systems, we can usually replace the file directly
-	return os.Rename(newPath, currentPath)
+	if err := replaceFile(currentPath, newPath); err != nil {
+		return err
+	}
+	os.Remove(newPath)
+	return nil
+}
+
+// replaceFile replaces dst with a copy of src. The copy is staged in dst's
+// directory, so the rename over dst never crosses file systems and a running
+// binary is never written to.
+func replaceFile(dst, src string) error {
+	sourceFile, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("failed to open source file: %w", err)
+	}
+	defer sourceFile.Close()
+	
+	tmpPath := dst + ".new"
+	tmpFile, err := os.OpenFile(tmpPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0755)
+	if err != nil {
+		return fmt.Errorf("failed to create staged file: %w", err)
+	}
+	_, err = io.Copy(tmpFile, sourceFile)
+	if err == nil {
+		err = tmpFile.Sync()
+	}
+	if closeErr := tmpFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tmpPath, 0755)
+	}
+	if err == nil {
+		err = os.Rename(tmpPath, dst)
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to replace %s: %w", dst, err)
+	}
+	return nil
 }
 
 // replaceExecutableWindows handles executable replacement on Windows
@@ -1,6 +1,7 @@
 package updater
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -9,6 +10,7 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -348,4 +350,250 @@ func TestUpdater_IsUpdateNeeded(t *testing.T) {
 			assert.Equal(t, tt.expected, result)
 		})
 	}
-}
\ No newline at end of file
+}
+// testRelease serves a signed manifest and binary for the running platform
+type testRelease struct {
+	server     *httptest.Server
+	publicKey  string
+	privateKey string
+	manifest   *Manifest
+	files      map[string][]byte // Served by path
+	requests   map[string]int    // Requests by path
+	queries    []string          // Query strings of the manifest requests
+}
+
+func newTestRelease(t *testing.T, version string, content []byte) *testRelease {
+	t.Helper()
+	
+	publicKey, privateKey, err := GenerateKeyPair()
+	require.NoError(t, err)
+	
+	release := &testRelease{
+		publicKey:  publicKey,
+		privateKey: privateKey,
+		files:      map[string][]byte{"/release_binary": content},
+		requests:   make(map[string]int),
+	}
+	release.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		release.requests[r.URL.Path]++
+		if r.URL.Path == "/manifest.json" {
+			release.queries = append(release.queries, r.URL.RawQuery)
+			json.NewEncoder(w).Encode(release.manifest)
+			return
+		}
+		content, ok := release.files[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		http.ServeContent(w, r, r.URL.Path, time.Time{}, bytes.NewReader(content))
+	}))
+	t.Cleanup(release.server.Close)
+	
+	release.manifest = &Manifest{
+		Version: version,
+		Binaries: map[string]Binary{
+			runtime.GOOS + "_" + runtime.GOARCH: {
+				URL:       release.server.URL + "/release_binary",
+				Signature: release.sign(t, content),
+				Size:      int64(len(content)),
+			},
+		},
+		Rollout: RolloutConfig{Percentage: 100},
+	}
+	return release
+}
+
+// sign returns the release signature of content
+func (r *testRelease) sign(t *testing.T, content []byte) string {
+	t.Helper()
+	
+	path := filepath.Join(t.TempDir(), "signed")
+	require.NoError(t, os.WriteFile(path, content, 0644))
+	signature, err := SignFile(path, r.privateKey)
+	require.NoError(t, err)
+	return signature
+}
+
+// fakeRestarter counts restart requests
+type fakeRestarter struct {
+	restarts int
+}
+
+func (r *fakeRestarter) Restart() error {
+	r.restarts++
+	return nil
+}
+
+// newTestUpdater creates an updater for the release whose running binary is a
+// file in a temporary directory
+func newTestUpdater(t *testing.T, release *testRelease, currentVersion string, opts ...UpdaterOption) (*Updater, string) {
+	t.Helper()
+	
+	tempDir := t.TempDir()
+	executable := filepath.Join(tempDir, "bridge")
+	require.NoError(t, os.WriteFile(executable, []byte("current binary"), 0755))
+	
+	config := &UpdaterConfig{
+		ManifestURL:    release.server.URL + "/manifest.json",
+		PublicKey:      release.publicKey,
+		CheckInterval:  time.Hour,
+		DeviceID:       "test_device",
+		CurrentVersion: currentVersion,
+		UpdateDir:      filepath.Join(tempDir, "updates"),
+		BackupDir:      filepath.Join(tempDir, "backups"),
+	}
+	
+	logger := logrus.New()
+	logger.SetLevel(logrus.ErrorLevel)
+	updater := NewUpdater(config, logger, opts...)
+	updater.executable = func() (string, error) { return executable, nil }
+	require.NoError(t, updater.createDirectories())
+	
+	return updater, executable
+}
+
+func TestUpdater_InstallWaitsForMaintenanceWindowAndLockedDoors(t *testing.T) {
+	release := newTestRelease(t, "1.1.0", []byte("new binary"))
+	restarter := &fakeRestarter{}
+	
+	var doorUnlocked bool
+	gate := func() error {
+		if doorUnlocked {
+			return fmt.Errorf("door main is unlocked")
+		}
+		return nil
+	}
+	
+	updater, executable := newTestUpdater(t, release, "1.0.0", WithInstallGate(gate), WithRestarter(restarter))
+	window, err := ParseMaintenanceWindow("02:00", "05:00", time.UTC)
+	require.NoError(t, err)
+	updater.config.MaintenanceWindow = window
+	
+	// The update is downloaded outside the window but not installed
+	updater.now = func() time.Time { return time.Date(2025, 6, 4, 12, 0, 0, 0, time.UTC) }
+	require.NoError(t, updater.checkForUpdates(context.Background()))
+	require.NotNil(t, updater.pending)
+	assert.Equal(t, "1.1.0", updater.pending.Version)
+	content, _ := os.ReadFile(executable)
+	assert.Equal(t, "current binary", string(content))
+	
+	// Inside the window an unlocked door still holds the install back
+	updater.now = func() time.Time { return time.Date(2025, 6, 4, 3, 0, 0, 0, time.UTC) }
+	doorUnlocked = true
+	require.NoError(t, updater.installPending(context.Background()))
+	content, _ = os.ReadFile(executable)
+	assert.Equal(t, "current binary", string(content))
+	assert.Equal(t, 0, restarter.restarts)
+	
+	doorUnlocked = false
+	require.NoError(t, updater.installPending(context.Background()))
+	content, _ = os.ReadFile(executable)
+	assert.Equal(t, "new binary", string(content))
+	assert.Equal(t, 1, restarter.restarts)
+	assert.Nil(t, updater.pending)
+	
+	// The restarted bridge verifies the update against the recorded state
+	state, err := updater.loadInstallState()
+	require.NoError(t, err)
+	require.NotNil(t, state)
+	assert.Equal(t, installStatusInstalled, state.Status)
+	assert.Equal(t, "1.0.0", state.FromVersion)
+	assert.Equal(t, "1.1.0", state.ToVersion)
+	backup, err := os.ReadFile(state.BackupPath)
+	require.NoError(t, err)
+	assert.Equal(t, "current binary", string(backup))
+}
+
+func TestUpdater_Channels(t *testing.T) {
+	release := newTestRelease(t, "1.2.0-beta.1", []byte("beta binary"))
+	release.manifest.Channel = ChannelBeta
+	
+	// A stable device ignores a beta release
+	updater, executable := newTestUpdater(t, release, "1.1.0")
+	require.NoError(t, updater.checkForUpdates(context.Background()))
+	assert.Equal(t, "channel=stable", release.queries[0])
+	content, _ := os.ReadFile(executable)
+	assert.Equal(t, "current binary", string(content))
+	
+	updater.config.Channel = ChannelBeta
+	require.NoError(t, updater.checkForUpdates(context.Background()))
+	assert.Equal(t, "channel=beta", release.queries[1])
+	content, _ = os.ReadFile(executable)
+	assert.Equal(t, "beta binary", string(content))
+	
+	// A pinned device only installs its pinned version
+	pinned, executable := newTestUpdater(t, release, "1.1.0")
+	pinned.config.Channel = ChannelPinned
+	pinned.config.PinnedVersion = "1.1.5"
+	require.NoError(t, pinned.checkForUpdates(context.Background()))
+	assert.Equal(t, "channel=pinned&version=1.1.5", release.queries[2])
+	content, _ = os.ReadFile(executable)
+	assert.Equal(t, "current binary", string(content))
+}
+
+func TestUpdater_RespectsMinVersion(t *testing.T) {
+	release := newTestRelease(t, "2.0.0", []byte("new binary"))
+	release.manifest.MinVersion = "1.5.0"
+	
+	for _, tt := range []struct {
+		currentVersion string
+		expectUpdate   bool
+	}{
+		{"1.4.9", false},
+		{"1.5.0", true},
+		{"dev", false},
+	} {
+		t.Run(tt.currentVersion, func(t *testing.T) {
+			updater, executable := newTestUpdater(t, release, tt.currentVersion)
+			require.NoError(t, updater.checkForUpdates(context.Background()))
+			
+			content, _ := os.ReadFile(executable)
+			assert.Equal(t, tt.expectUpdate, string(content) == "new binary")
+		})
+	}
+}
+
+func TestUpdater_CheckNow(t *testing.T) {
+	var checks atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		checks.Add(1)
+		json.NewEncoder(w).Encode(&Manifest{Version: "1.0.0"})
+	}))
+	defer server.Close()
+	
+	release := newTestRelease(t, "1.0.0", []byte("new binary"))
+	updater, _ := newTestUpdater(t, release, "1.0.0")
+	updater.config.ManifestURL = server.URL + "/manifest.json"
+	
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go updater.Start(ctx)
+	
+	// The startup check runs immediately; the next one only when asked for
+	require.Eventually(t, func() bool { return checks.Load() == 1 }, 5*time.Second, 10*time.Millisecond)
+	updater.CheckNow()
+	require.Eventually(t, func() bool { return checks.Load() == 2 }, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+		ok       bool
+	}{
+		{"1.0.0", "1.0.0", 0, true},
+		{"v1.2.0", "1.10.0", -1, true},
+		{"2.0", "1.9.9", 1, true},
+		{"1.2.0-beta.1", "1.2.0", -1, true},
+		{"1.2.0-beta.2", "1.2.0-beta.1", 1, true},
+		{"1.2.0+build.5", "1.2.0", 0, true},
+		{"dev", "1.0.0", 0, false},
+	}
+	
+	for _, tt := range tests {
+		result, ok := compareVersions(tt.a, tt.b)
+		assert.Equal(t, tt.ok, ok, "%s vs %s", tt.a, tt.b)
+		assert.Equal(t, tt.expected, result, "%s vs %s", tt.a, tt.b)
+	}
+}
@@ -0,0 +1,74 @@
+package updater
+
+import (
+	"strconv"
+	"strings"
+)
+
+// semanticVersion is a parsed MAJOR.MINOR.PATCH[-PRERELEASE] version
+type semanticVersion struct {
+	parts      [3]int
+	prerelease string
+}
+
+// parseVersion parses a semantic version with an optional "v" prefix; build
+// metadata after "+" is ignored
+func parseVersion(version string) (semanticVersion, bool) {
+	var parsed semanticVersion
+
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	if i := strings.IndexByte(version, '+'); i >= 0 {
+		version = version[:i]
+	}
+	if i := strings.IndexByte(version, '-'); i >= 0 {
+		parsed.prerelease = version[i+1:]
+		version = version[:i]
+	}
+
+	fields := strings.Split(version, ".")
+	if len(fields) == 0 || len(fields) > 3 {
+		return parsed, false
+	}
+	for i, field := range fields {
+		n, err := strconv.Atoi(field)
+		if err != nil || n < 0 {
+			return parsed, false
+		}
+		parsed.parts[i] = n
+	}
+	return parsed, true
+}
+
+// compareVersions returns -1, 0 or 1 when a is older than, the same as or
+// newer than b. ok is false when either version is not a semantic version,
+// such as the "dev" version of local builds.
+func compareVersions(a, b string) (result int, ok bool) {
+	va, okA := parseVersion(a)
+	vb, okB := parseVersion(b)
+	if !okA || !okB {
+		return 0, false
+	}
+
+	for i := range va.parts {
+		if va.parts[i] != vb.parts[i] {
+			if va.parts[i] < vb.parts[i] {
+				return -1, true
+			}
+			return 1, true
+		}
+	}
+
+	// A pre-release sorts before the release it leads up to
+	switch {
+	case va.prerelease == vb.prerelease:
+		return 0, true
+	case va.prerelease == "":
+		return 1, true
+	case vb.prerelease == "":
+		return -1, true
+	case va.prerelease < vb.prerelease:
+		return -1, true
+	default:
+		return 1, true
+	}
+}
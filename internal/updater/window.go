@@ -0,0 +1,82 @@
+package updater
+
+import (
+	"fmt"
+	"time"
+)
+
+// MaintenanceWindow is the daily period in which downloaded updates may be
+// installed. A window whose end is before its start spans midnight.
+type MaintenanceWindow struct {
+	Start    time.Duration // Offset from local midnight
+	End      time.Duration // Offset from local midnight
+	Location *time.Location
+}
+
+// ParseMaintenanceWindow parses a window from "HH:MM" start and end times in
+// the given location. Empty start and end times return nil, which allows
+// installing at any time.
+func ParseMaintenanceWindow(start, end string, loc *time.Location) (*MaintenanceWindow, error) {
+	if start == "" && end == "" {
+		return nil, nil
+	}
+	if start == "" || end == "" {
+		return nil, fmt.Errorf("maintenance window needs both a start and an end time")
+	}
+
+	startOffset, err := parseClockOffset(start)
+	if err != nil {
+		return nil, fmt.Errorf("invalid maintenance window start: %w", err)
+	}
+	endOffset, err := parseClockOffset(end)
+	if err != nil {
+		return nil, fmt.Errorf("invalid maintenance window end: %w", err)
+	}
+	if startOffset == endOffset {
+		return nil, fmt.Errorf("maintenance window start and end must differ")
+	}
+
+	if loc == nil {
+		loc = time.Local
+	}
+	return &MaintenanceWindow{Start: startOffset, End: endOffset, Location: loc}, nil
+}
+
+// Contains reports whether t falls inside the window. A nil window contains
+// every time.
+func (w *MaintenanceWindow) Contains(t time.Time) bool {
+	if w == nil {
+		return true
+	}
+
+	local := t.In(w.Location)
+	offset := time.Duration(local.Hour())*time.Hour + time.Duration(local.Minute())*time.Minute +
+		time.Duration(local.Second())*time.Second
+
+	if w.Start < w.End {
+		return offset >= w.Start && offset < w.End
+	}
+	return offset >= w.Start || offset < w.End
+}
+
+// String formats the window as "HH:MM-HH:MM"
+func (w *MaintenanceWindow) String() string {
+	if w == nil {
+		return "any time"
+	}
+	return formatClockOffset(w.Start) + "-" + formatClockOffset(w.End)
+}
+
+// parseClockOffset parses "HH:MM" into an offset from midnight
+func parseClockOffset(clock string) (time.Duration, error) {
+	parsed, err := time.Parse("15:04", clock)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not in HH:MM format", clock)
+	}
+	return time.Duration(parsed.Hour())*time.Hour + time.Duration(parsed.Minute())*time.Minute, nil
+}
+
+// formatClockOffset formats an offset from midnight as "HH:MM"
+func formatClockOffset(offset time.Duration) string {
+	return fmt.Sprintf("%02d:%02d", int(offset.Hours()), int(offset.Minutes())%60)
+}
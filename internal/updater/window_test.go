@@ -0,0 +1,51 @@
+package updater
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaintenanceWindow_Contains(t *testing.T) {
+	day := func(hour, minute int) time.Time {
+		return time.Date(2025, 6, 4, hour, minute, 0, 0, time.UTC)
+	}
+
+	window, err := ParseMaintenanceWindow("02:00", "05:00", time.UTC)
+	require.NoError(t, err)
+	assert.True(t, window.Contains(day(2, 0)))
+	assert.True(t, window.Contains(day(4, 59)))
+	assert.False(t, window.Contains(day(5, 0)))
+	assert.False(t, window.Contains(day(1, 59)))
+
+	// A window spanning midnight
+	overnight, err := ParseMaintenanceWindow("23:00", "01:30", time.UTC)
+	require.NoError(t, err)
+	assert.True(t, overnight.Contains(day(23, 30)))
+	assert.True(t, overnight.Contains(day(1, 0)))
+	assert.False(t, overnight.Contains(day(12, 0)))
+
+	// The window follows the site timezone
+	kolkata, err := time.LoadLocation("Asia/Kolkata")
+	require.NoError(t, err)
+	local, err := ParseMaintenanceWindow("02:00", "05:00", kolkata)
+	require.NoError(t, err)
+	assert.True(t, local.Contains(day(21, 0))) // 02:30 in Kolkata
+	assert.False(t, local.Contains(day(2, 0)))
+}
+
+func TestParseMaintenanceWindow(t *testing.T) {
+	window, err := ParseMaintenanceWindow("", "", time.UTC)
+	require.NoError(t, err)
+	assert.Nil(t, window)
+	assert.True(t, window.Contains(time.Now()), "no window allows installing at any time")
+
+	_, err = ParseMaintenanceWindow("02:00", "", time.UTC)
+	assert.Error(t, err)
+	_, err = ParseMaintenanceWindow("2am", "05:00", time.UTC)
+	assert.Error(t, err)
+	_, err = ParseMaintenanceWindow("02:00", "02:00", time.UTC)
+	assert.Error(t, err)
+}